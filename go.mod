@@ -1,3 +1,5 @@
 module redis-go
 
 go 1.24.1
+
+require github.com/yuin/gopher-lua v1.1.2
@@ -0,0 +1,486 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"redis-go/internal/storage"
+)
+
+// DefaultAclUserName est l'utilisateur utilisé par AUTH <password> et par les connexions sans authentification
+const DefaultAclUserName = "default"
+
+// authenticationExemptCommandNames liste les commandes autorisées avant authentification
+// et jamais soumises aux permissions ACL
+var authenticationExemptCommandNames = map[string]bool{
+	"AUTH": true, "HELLO": true, "QUIT": true,
+}
+
+// IsAuthenticationExemptCommand indique si une commande est accessible sans être authentifié (AUTH, HELLO, QUIT)
+func IsAuthenticationExemptCommand(commandName string) bool {
+	return authenticationExemptCommandNames[strings.ToUpper(commandName)]
+}
+
+// RedisAclUser représente un utilisateur ACL : mots de passe, commandes et clés autorisées
+// Les champs sont protégés par le verrou de la liste ACL qui le contient
+type RedisAclUser struct {
+	userName           string
+	isEnabled          bool
+	requiresNoPassword bool
+	// passwordHashes contient les SHA-256 (hexadécimal) des mots de passe acceptés
+	passwordHashes []string
+	// allowAllCommands est la permission par défaut, commandOverrides les exceptions (+cmd, -@cat...)
+	allowAllCommands bool
+	commandOverrides map[string]bool
+	// commandRules conserve les règles de commandes appliquées, dans l'ordre (ACL LIST, ACL GETUSER)
+	commandRules []string
+	allowAllKeys bool
+	keyPatterns  []string
+}
+
+// GetUserName retourne le nom de l'utilisateur
+func (aclUser *RedisAclUser) GetUserName() string {
+	return aclUser.userName
+}
+
+// newDisabledAclUser crée un utilisateur sans aucun droit (état initial d'ACL SETUSER)
+func newDisabledAclUser(userName string) *RedisAclUser {
+	return &RedisAclUser{
+		userName:         userName,
+		commandOverrides: make(map[string]bool),
+	}
+}
+
+// cloneAclUser copie un utilisateur pour lui appliquer des règles sans modifier l'original
+func (aclUser *RedisAclUser) cloneAclUser() *RedisAclUser {
+	clonedUser := *aclUser
+	clonedUser.passwordHashes = append([]string(nil), aclUser.passwordHashes...)
+	clonedUser.commandRules = append([]string(nil), aclUser.commandRules...)
+	clonedUser.keyPatterns = append([]string(nil), aclUser.keyPatterns...)
+	clonedUser.commandOverrides = make(map[string]bool, len(aclUser.commandOverrides))
+	for commandName, isAllowed := range aclUser.commandOverrides {
+		clonedUser.commandOverrides[commandName] = isAllowed
+	}
+	return &clonedUser
+}
+
+// RedisAclUserDescription décrit un utilisateur (ACL GETUSER)
+type RedisAclUserDescription struct {
+	Flags          []string
+	PasswordHashes []string
+	CommandRules   string
+	KeyPatterns    string
+}
+
+// RedisAccessControlList contient les utilisateurs ACL du serveur
+type RedisAccessControlList struct {
+	aclMutex        sync.RWMutex
+	registeredUsers map[string]*RedisAclUser
+	// isKnownCommand valide les règles +commande / -commande
+	isKnownCommand func(upperCommandName string) bool
+}
+
+// newRedisAccessControlList crée la liste ACL avec l'utilisateur default (actif, sans mot de passe, tous les droits)
+func newRedisAccessControlList(isKnownCommand func(upperCommandName string) bool) *RedisAccessControlList {
+	defaultUser := newDisabledAclUser(DefaultAclUserName)
+	defaultUser.isEnabled = true
+	defaultUser.requiresNoPassword = true
+	defaultUser.allowAllCommands = true
+	defaultUser.commandRules = []string{"+@all"}
+	defaultUser.allowAllKeys = true
+
+	return &RedisAccessControlList{
+		registeredUsers: map[string]*RedisAclUser{DefaultAclUserName: defaultUser},
+		isKnownCommand:  isKnownCommand,
+	}
+}
+
+// SetDefaultUserPassword remplace les mots de passe de l'utilisateur default (requirepass)
+func (accessControlList *RedisAccessControlList) SetDefaultUserPassword(password string) {
+	accessControlList.aclMutex.Lock()
+	defer accessControlList.aclMutex.Unlock()
+
+	defaultUser := accessControlList.registeredUsers[DefaultAclUserName]
+	defaultUser.requiresNoPassword = false
+	defaultUser.passwordHashes = []string{hashAclPassword(password)}
+}
+
+// GetDefaultUserWithoutPassword retourne l'utilisateur default s'il est actif et sans mot de passe
+// (les nouvelles connexions sont alors authentifiées automatiquement), nil sinon
+func (accessControlList *RedisAccessControlList) GetDefaultUserWithoutPassword() *RedisAclUser {
+	accessControlList.aclMutex.RLock()
+	defer accessControlList.aclMutex.RUnlock()
+
+	defaultUser, userExists := accessControlList.registeredUsers[DefaultAclUserName]
+	if userExists && defaultUser.isEnabled && defaultUser.requiresNoPassword {
+		return defaultUser
+	}
+	return nil
+}
+
+// AuthenticateUser vérifie le mot de passe d'un utilisateur actif (AUTH, HELLO AUTH)
+func (accessControlList *RedisAccessControlList) AuthenticateUser(userName string, password string) (*RedisAclUser, bool) {
+	accessControlList.aclMutex.RLock()
+	defer accessControlList.aclMutex.RUnlock()
+
+	aclUser, userExists := accessControlList.registeredUsers[userName]
+	if !userExists || !aclUser.isEnabled {
+		return nil, false
+	}
+	if aclUser.requiresNoPassword {
+		return aclUser, true
+	}
+
+	passwordHash := hashAclPassword(password)
+	for _, acceptedHash := range aclUser.passwordHashes {
+		if acceptedHash == passwordHash {
+			return aclUser, true
+		}
+	}
+	return nil, false
+}
+
+// CheckCommandPermission vérifie qu'un utilisateur peut exécuter une commande sur ses clés
+// Retourne un message d'erreur NOPERM, ou une chaîne vide si la commande est autorisée
+// Un utilisateur nil représente le serveur lui-même (rejeu de l'AOF) : tout est autorisé
+func (accessControlList *RedisAccessControlList) CheckCommandPermission(aclUser *RedisAclUser, commandName string, commandArguments []string) string {
+	upperCommandName := strings.ToUpper(commandName)
+	if aclUser == nil || authenticationExemptCommandNames[upperCommandName] {
+		return ""
+	}
+
+	accessControlList.aclMutex.RLock()
+	defer accessControlList.aclMutex.RUnlock()
+
+	if !aclUser.isCommandAllowed(upperCommandName) {
+		return fmt.Sprintf("NOPERM l'utilisateur '%s' n'a pas la permission d'exécuter la commande '%s'", aclUser.userName, strings.ToLower(upperCommandName))
+	}
+	for _, commandKey := range extractCommandKeys(upperCommandName, commandArguments) {
+		if !aclUser.isKeyAllowed(commandKey) {
+			return fmt.Sprintf("NOPERM l'utilisateur '%s' n'a pas accès à une des clés utilisées par la commande '%s'", aclUser.userName, strings.ToLower(upperCommandName))
+		}
+	}
+	return ""
+}
+
+// isCommandAllowed indique si l'utilisateur peut exécuter une commande (appelant détient aclMutex)
+func (aclUser *RedisAclUser) isCommandAllowed(upperCommandName string) bool {
+	if isAllowed, hasOverride := aclUser.commandOverrides[upperCommandName]; hasOverride {
+		return isAllowed
+	}
+	return aclUser.allowAllCommands
+}
+
+// isKeyAllowed indique si une clé correspond à un des motifs de l'utilisateur (appelant détient aclMutex)
+func (aclUser *RedisAclUser) isKeyAllowed(commandKey string) bool {
+	if aclUser.allowAllKeys {
+		return true
+	}
+	for _, keyPattern := range aclUser.keyPatterns {
+		if storage.MatchesGlobPattern(keyPattern, commandKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetUserRules crée ou modifie un utilisateur (ACL SETUSER)
+// Les règles sont appliquées dans l'ordre ; si l'une est invalide l'utilisateur n'est pas modifié
+// Retourne un message d'erreur vide en cas de succès
+func (accessControlList *RedisAccessControlList) SetUserRules(userName string, aclRules []string) string {
+	accessControlList.aclMutex.Lock()
+	defer accessControlList.aclMutex.Unlock()
+
+	existingUser, userExists := accessControlList.registeredUsers[userName]
+	modifiedUser := newDisabledAclUser(userName)
+	if userExists {
+		modifiedUser = existingUser.cloneAclUser()
+	}
+
+	for _, aclRule := range aclRules {
+		if errorMessage := accessControlList.applyAclRule(modifiedUser, aclRule); errorMessage != "" {
+			return fmt.Sprintf("ERREUR : erreur dans la règle ACL SETUSER '%s': %s", aclRule, errorMessage)
+		}
+	}
+
+	// Les connexions authentifiées avec cet utilisateur voient immédiatement ses nouveaux droits
+	if userExists {
+		*existingUser = *modifiedUser
+	} else {
+		accessControlList.registeredUsers[userName] = modifiedUser
+	}
+	return ""
+}
+
+// applyAclRule applique une règle ACL à un utilisateur et retourne un message d'erreur vide si elle est valide
+func (accessControlList *RedisAccessControlList) applyAclRule(aclUser *RedisAclUser, aclRule string) string {
+	switch strings.ToLower(aclRule) {
+	case "on":
+		aclUser.isEnabled = true
+		return ""
+	case "off":
+		aclUser.isEnabled = false
+		return ""
+	case "nopass":
+		aclUser.requiresNoPassword = true
+		aclUser.passwordHashes = nil
+		return ""
+	case "resetpass":
+		aclUser.requiresNoPassword = false
+		aclUser.passwordHashes = nil
+		return ""
+	case "allkeys":
+		return applyKeyPatternRule(aclUser, "*")
+	case "resetkeys":
+		aclUser.allowAllKeys = false
+		aclUser.keyPatterns = nil
+		return ""
+	case "allcommands":
+		return accessControlList.applyCommandRule(aclUser, "+@all")
+	case "nocommands":
+		return accessControlList.applyCommandRule(aclUser, "-@all")
+	case "reset":
+		for _, resetRule := range []string{"off", "resetpass", "resetkeys", "nocommands"} {
+			accessControlList.applyAclRule(aclUser, resetRule)
+		}
+		return ""
+	}
+
+	if aclRule == "" {
+		return "règle vide"
+	}
+	switch aclRule[0] {
+	case '>':
+		passwordHash := hashAclPassword(aclRule[1:])
+		aclUser.requiresNoPassword = false
+		if !containsString(aclUser.passwordHashes, passwordHash) {
+			aclUser.passwordHashes = append(aclUser.passwordHashes, passwordHash)
+		}
+		return ""
+	case '#':
+		passwordHash := strings.ToLower(aclRule[1:])
+		if !isValidPasswordHash(passwordHash) {
+			return "le hash doit contenir 64 caractères hexadécimaux (SHA-256)"
+		}
+		aclUser.requiresNoPassword = false
+		if !containsString(aclUser.passwordHashes, passwordHash) {
+			aclUser.passwordHashes = append(aclUser.passwordHashes, passwordHash)
+		}
+		return ""
+	case '<', '!':
+		passwordHash := strings.ToLower(aclRule[1:])
+		if aclRule[0] == '<' {
+			passwordHash = hashAclPassword(aclRule[1:])
+		}
+		remainingHashes := removeString(aclUser.passwordHashes, passwordHash)
+		if len(remainingHashes) == len(aclUser.passwordHashes) {
+			return "mot de passe inconnu pour cet utilisateur"
+		}
+		aclUser.passwordHashes = remainingHashes
+		return ""
+	case '~':
+		return applyKeyPatternRule(aclUser, aclRule[1:])
+	case '+', '-':
+		return accessControlList.applyCommandRule(aclUser, aclRule)
+	}
+
+	return "règle inconnue"
+}
+
+// applyKeyPatternRule ajoute un motif de clés (~pattern), * autorise toutes les clés
+func applyKeyPatternRule(aclUser *RedisAclUser, keyPattern string) string {
+	if keyPattern == "*" {
+		aclUser.allowAllKeys = true
+		aclUser.keyPatterns = nil
+		return ""
+	}
+	if aclUser.allowAllKeys {
+		return "toutes les clés sont déjà autorisées, utilisez resetkeys avant d'ajouter un motif"
+	}
+	if !containsString(aclUser.keyPatterns, keyPattern) {
+		aclUser.keyPatterns = append(aclUser.keyPatterns, keyPattern)
+	}
+	return ""
+}
+
+// applyCommandRule applique une règle +commande, -commande, +@catégorie ou -@catégorie
+func (accessControlList *RedisAccessControlList) applyCommandRule(aclUser *RedisAclUser, commandRule string) string {
+	isAllowed := commandRule[0] == '+'
+	ruleTarget := commandRule[1:]
+
+	if strings.HasPrefix(ruleTarget, "@") {
+		categoryName := strings.ToLower(ruleTarget[1:])
+		if categoryName == "all" {
+			// +@all / -@all remplacent toutes les règles précédentes
+			aclUser.allowAllCommands = isAllowed
+			aclUser.commandOverrides = make(map[string]bool)
+			aclUser.commandRules = []string{commandRule[:1] + "@all"}
+			return ""
+		}
+		categoryCommands := getCommandCategoryMembers(categoryName)
+		if categoryCommands == nil {
+			return fmt.Sprintf("catégorie inconnue '%s'", categoryName)
+		}
+		for _, commandName := range categoryCommands {
+			aclUser.commandOverrides[commandName] = isAllowed
+		}
+		aclUser.commandRules = append(aclUser.commandRules, commandRule[:1]+"@"+categoryName)
+		return ""
+	}
+
+	upperCommandName := strings.ToUpper(ruleTarget)
+	if !accessControlList.isKnownCommand(upperCommandName) {
+		return fmt.Sprintf("commande inconnue '%s'", ruleTarget)
+	}
+	aclUser.commandOverrides[upperCommandName] = isAllowed
+	aclUser.commandRules = append(aclUser.commandRules, commandRule[:1]+strings.ToLower(upperCommandName))
+	return ""
+}
+
+// GetUserDescription décrit un utilisateur (ACL GETUSER), false s'il n'existe pas
+func (accessControlList *RedisAccessControlList) GetUserDescription(userName string) (RedisAclUserDescription, bool) {
+	accessControlList.aclMutex.RLock()
+	defer accessControlList.aclMutex.RUnlock()
+
+	aclUser, userExists := accessControlList.registeredUsers[userName]
+	if !userExists {
+		return RedisAclUserDescription{}, false
+	}
+
+	userFlags := []string{"off"}
+	if aclUser.isEnabled {
+		userFlags = []string{"on"}
+	}
+	if aclUser.requiresNoPassword {
+		userFlags = append(userFlags, "nopass")
+	}
+
+	return RedisAclUserDescription{
+		Flags:          userFlags,
+		PasswordHashes: append([]string(nil), aclUser.passwordHashes...),
+		CommandRules:   aclUser.describeCommandRules(),
+		KeyPatterns:    aclUser.describeKeyPatterns(),
+	}, true
+}
+
+// ListUserRules retourne la description de chaque utilisateur sous forme de règles (ACL LIST)
+func (accessControlList *RedisAccessControlList) ListUserRules() []string {
+	accessControlList.aclMutex.RLock()
+	defer accessControlList.aclMutex.RUnlock()
+
+	userNames := make([]string, 0, len(accessControlList.registeredUsers))
+	for userName := range accessControlList.registeredUsers {
+		userNames = append(userNames, userName)
+	}
+	sort.Strings(userNames)
+
+	userRules := make([]string, 0, len(userNames))
+	for _, userName := range userNames {
+		aclUser := accessControlList.registeredUsers[userName]
+		ruleParts := []string{"user", userName, "off"}
+		if aclUser.isEnabled {
+			ruleParts[2] = "on"
+		}
+		if aclUser.requiresNoPassword {
+			ruleParts = append(ruleParts, "nopass")
+		}
+		for _, passwordHash := range aclUser.passwordHashes {
+			ruleParts = append(ruleParts, "#"+passwordHash)
+		}
+		if keyPatterns := aclUser.describeKeyPatterns(); keyPatterns != "" {
+			ruleParts = append(ruleParts, keyPatterns)
+		} else {
+			ruleParts = append(ruleParts, "resetkeys")
+		}
+		ruleParts = append(ruleParts, aclUser.describeCommandRules())
+		userRules = append(userRules, strings.Join(ruleParts, " "))
+	}
+	return userRules
+}
+
+// describeCommandRules retourne les règles de commandes de l'utilisateur (-@all si aucune)
+func (aclUser *RedisAclUser) describeCommandRules() string {
+	if len(aclUser.commandRules) == 0 {
+		return "-@all"
+	}
+	if aclUser.commandRules[0] != "+@all" && aclUser.commandRules[0] != "-@all" {
+		return "-@all " + strings.Join(aclUser.commandRules, " ")
+	}
+	return strings.Join(aclUser.commandRules, " ")
+}
+
+// describeKeyPatterns retourne les motifs de clés de l'utilisateur (~pattern séparés par des espaces)
+func (aclUser *RedisAclUser) describeKeyPatterns() string {
+	if aclUser.allowAllKeys {
+		return "~*"
+	}
+	describedPatterns := make([]string, len(aclUser.keyPatterns))
+	for patternIndex, keyPattern := range aclUser.keyPatterns {
+		describedPatterns[patternIndex] = "~" + keyPattern
+	}
+	return strings.Join(describedPatterns, " ")
+}
+
+// GetCategoryNames retourne les catégories ACL disponibles (ACL CAT)
+func (accessControlList *RedisAccessControlList) GetCategoryNames() []string {
+	categoryNames := []string{"read", "write"}
+	for categoryName := range commandCategoryMembers {
+		categoryNames = append(categoryNames, categoryName)
+	}
+	sort.Strings(categoryNames)
+	return categoryNames
+}
+
+// GetCategoryCommands retourne les commandes d'une catégorie en minuscules (ACL CAT catégorie)
+func (accessControlList *RedisAccessControlList) GetCategoryCommands(categoryName string) ([]string, bool) {
+	categoryCommands := getCommandCategoryMembers(strings.ToLower(categoryName))
+	if categoryCommands == nil {
+		return nil, false
+	}
+	lowerCommandNames := make([]string, len(categoryCommands))
+	for commandIndex, commandName := range categoryCommands {
+		lowerCommandNames[commandIndex] = strings.ToLower(commandName)
+	}
+	sort.Strings(lowerCommandNames)
+	return lowerCommandNames, true
+}
+
+// hashAclPassword retourne le SHA-256 hexadécimal d'un mot de passe (les mots de passe ne sont jamais conservés en clair)
+func hashAclPassword(password string) string {
+	passwordHash := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(passwordHash[:])
+}
+
+// isValidPasswordHash vérifie qu'une chaîne est un SHA-256 hexadécimal
+func isValidPasswordHash(passwordHash string) bool {
+	if len(passwordHash) != sha256.Size*2 {
+		return false
+	}
+	_, decodeError := hex.DecodeString(passwordHash)
+	return decodeError == nil
+}
+
+// containsString indique si une valeur est présente dans une liste
+func containsString(stringList []string, searchedValue string) bool {
+	for _, listValue := range stringList {
+		if listValue == searchedValue {
+			return true
+		}
+	}
+	return false
+}
+
+// removeString retourne une copie de la liste sans la valeur indiquée
+func removeString(stringList []string, removedValue string) []string {
+	var remainingValues []string
+	for _, listValue := range stringList {
+		if listValue != removedValue {
+			remainingValues = append(remainingValues, listValue)
+		}
+	}
+	return remainingValues
+}
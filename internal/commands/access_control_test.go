@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"redis-go/internal/storage"
+)
+
+// newTestAccessControlList crée la liste ACL d'un registre neuf (les règles +commande sont validées par le registre)
+func newTestAccessControlList() *RedisAccessControlList {
+	return NewRedisCommandRegistry(storage.NewRedisDatabaseStorages(1)).GetAccessControlList()
+}
+
+func TestAclCommandPermissions(t *testing.T) {
+	noCommandMessage := "NOPERM l'utilisateur 'app' n'a pas la permission d'exécuter la commande "
+	noKeyMessage := "NOPERM l'utilisateur 'app' n'a pas accès à une des clés utilisées par la commande "
+
+	testCases := []struct {
+		name            string
+		aclRules        []string
+		checkedCommands map[string]string
+	}{
+		{
+			name:     "utilisateur créé sans aucun droit",
+			aclRules: []string{"on", "nopass"},
+			checkedCommands: map[string]string{
+				"GET k":  noCommandMessage + "'get'",
+				"PING":   noCommandMessage + "'ping'",
+				"AUTH x": "",
+				"QUIT":   "",
+			},
+		},
+		{
+			name:     "catégorie lecture sur un motif de clés",
+			aclRules: []string{"on", "nopass", "+@read", "~app:*"},
+			checkedCommands: map[string]string{
+				"GET app:1":             "",
+				"MGET app:1 app:2":      "",
+				"MGET app:1 autre":      noKeyMessage + "'mget'",
+				"GET autre":             noKeyMessage + "'get'",
+				"SET app:1 v":           noCommandMessage + "'set'",
+				"HGETALL app:h":         "",
+				"ZRANGE app:z 0 -1":     "",
+				"EVAL return 1 1 autre": noCommandMessage + "'eval'",
+			},
+		},
+		{
+			name:     "les exceptions suivent l'ordre des règles",
+			aclRules: []string{"on", "nopass", "allkeys", "+@all", "-@write", "+set", "-flushall"},
+			checkedCommands: map[string]string{
+				"SET k v":  "",
+				"DEL k":    noCommandMessage + "'del'",
+				"GET k":    "",
+				"FLUSHALL": noCommandMessage + "'flushall'",
+			},
+		},
+		{
+			name:     "clés de EVAL selon numkeys",
+			aclRules: []string{"on", "nopass", "+eval", "~a*"},
+			checkedCommands: map[string]string{
+				"EVAL s 1 a1 other":    "",
+				"EVAL s 2 a1 other":    noKeyMessage + "'eval'",
+				"EVAL s 0 other":       "",
+				"LMOVE a1 b LEFT LEFT": noCommandMessage + "'lmove'",
+			},
+		},
+		{
+			name:     "resetkeys retire l'accès à toutes les clés",
+			aclRules: []string{"on", "nopass", "allcommands", "allkeys", "resetkeys", "~k"},
+			checkedCommands: map[string]string{
+				"GET k":     "",
+				"GET other": noKeyMessage + "'get'",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			accessControlList := newTestAccessControlList()
+			if errorMessage := accessControlList.SetUserRules("app", testCase.aclRules); errorMessage != "" {
+				t.Fatalf("ACL SETUSER app %v: %s", testCase.aclRules, errorMessage)
+			}
+			aclUser, isAuthenticated := accessControlList.AuthenticateUser("app", "")
+			if !isAuthenticated {
+				t.Fatalf("authentification de 'app' impossible")
+			}
+			for commandLine, expectedMessage := range testCase.checkedCommands {
+				commandFields := strings.Fields(commandLine)
+				if permissionMessage := accessControlList.CheckCommandPermission(aclUser, commandFields[0], commandFields[1:]); permissionMessage != expectedMessage {
+					t.Fatalf("%s: %q, attendu %q", commandLine, permissionMessage, expectedMessage)
+				}
+			}
+		})
+	}
+}
+
+func TestAclUserRules(t *testing.T) {
+	testCases := []struct {
+		name               string
+		aclRules           []string
+		expectedError      string
+		expectedListedRule string
+		acceptedPasswords  []string
+		rejectedPasswords  []string
+	}{
+		{
+			name:               "mots de passe multiples et retrait",
+			aclRules:           []string{"on", ">secret", ">autre", "<autre", "~cache:*", "+get"},
+			expectedListedRule: "user app on #" + hashAclPassword("secret") + " ~cache:* -@all +get",
+			acceptedPasswords:  []string{"secret"},
+			rejectedPasswords:  []string{"autre", ""},
+		},
+		{
+			name:               "mot de passe par son hash",
+			aclRules:           []string{"on", "#" + strings.ToUpper(hashAclPassword("haché")), "allkeys", "allcommands", "-@dangerous"},
+			expectedListedRule: "user app on #" + hashAclPassword("haché") + " ~* +@all -@dangerous",
+			acceptedPasswords:  []string{"haché"},
+		},
+		{
+			name:               "utilisateur désactivé",
+			aclRules:           []string{"off", "nopass"},
+			expectedListedRule: "user app off nopass resetkeys -@all",
+			rejectedPasswords:  []string{""},
+		},
+		{
+			name:               "reset retire tout",
+			aclRules:           []string{"on", ">secret", "allkeys", "allcommands", "reset"},
+			expectedListedRule: "user app off resetkeys -@all",
+			rejectedPasswords:  []string{"secret"},
+		},
+		{name: "règle inconnue", aclRules: []string{"on", "everything"}, expectedError: "ERREUR : erreur dans la règle ACL SETUSER 'everything': règle inconnue"},
+		{name: "commande inconnue", aclRules: []string{"+nosuchcommand"}, expectedError: "ERREUR : erreur dans la règle ACL SETUSER '+nosuchcommand': commande inconnue 'nosuchcommand'"},
+		{name: "catégorie inconnue", aclRules: []string{"+@nosuch"}, expectedError: "ERREUR : erreur dans la règle ACL SETUSER '+@nosuch': catégorie inconnue 'nosuch'"},
+		{name: "hash invalide", aclRules: []string{"#1234"}, expectedError: "ERREUR : erreur dans la règle ACL SETUSER '#1234': le hash doit contenir 64 caractères hexadécimaux (SHA-256)"},
+		{name: "motif après allkeys", aclRules: []string{"allkeys", "~k"}, expectedError: "ERREUR : erreur dans la règle ACL SETUSER '~k': toutes les clés sont déjà autorisées, utilisez resetkeys avant d'ajouter un motif"},
+		{name: "retrait d'un mot de passe inconnu", aclRules: []string{"<absent"}, expectedError: "ERREUR : erreur dans la règle ACL SETUSER '<absent': mot de passe inconnu pour cet utilisateur"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			accessControlList := newTestAccessControlList()
+			errorMessage := accessControlList.SetUserRules("app", testCase.aclRules)
+			if errorMessage != testCase.expectedError {
+				t.Fatalf("ACL SETUSER app %v: %q, attendu %q", testCase.aclRules, errorMessage, testCase.expectedError)
+			}
+			if testCase.expectedError != "" {
+				// Une règle invalide n'applique aucune des règles de la commande
+				if _, userExists := accessControlList.GetUserDescription("app"); userExists {
+					t.Fatalf("l'utilisateur a été créé malgré l'erreur")
+				}
+				return
+			}
+
+			listedRules := accessControlList.ListUserRules()
+			expectedRules := []string{testCase.expectedListedRule, "user default on nopass ~* +@all"}
+			if !slices.Equal(listedRules, expectedRules) {
+				t.Fatalf("ACL LIST %q, attendu %q", listedRules, expectedRules)
+			}
+			for _, acceptedPassword := range testCase.acceptedPasswords {
+				if _, isAuthenticated := accessControlList.AuthenticateUser("app", acceptedPassword); !isAuthenticated {
+					t.Fatalf("mot de passe %q refusé", acceptedPassword)
+				}
+			}
+			for _, rejectedPassword := range testCase.rejectedPasswords {
+				if _, isAuthenticated := accessControlList.AuthenticateUser("app", rejectedPassword); isAuthenticated {
+					t.Fatalf("mot de passe %q accepté", rejectedPassword)
+				}
+			}
+		})
+	}
+}
+
+func TestAclPermissionsAppliedToScriptCalls(t *testing.T) {
+	testFixture := newCommandTestFixture()
+	accessControlList := testFixture.commandRegistry.GetAccessControlList()
+	accessControlList.SetUserRules("app", []string{"on", "nopass", "+eval", "+get", "+exists", "~app:*"})
+	aclUser, _ := accessControlList.AuthenticateUser("app", "")
+
+	for _, testStep := range []commandTestStep{
+		evalStep("return redis.pcall('SET', KEYS[1], 'v')", []string{"1", "app:1"}, "-NOPERM l'utilisateur 'app' n'a pas la permission d'exécuter la commande 'set'\r\n"),
+		evalStep("return redis.pcall('GET', 'autre')", []string{"0"}, "-NOPERM l'utilisateur 'app' n'a pas accès à une des clés utilisées par la commande 'get'\r\n"),
+		evalStep("return redis.call('EXISTS', KEYS[1])", []string{"1", "app:1"}, ":0\r\n"),
+		step("SET app:1 v", "-NOPERM l'utilisateur 'app' n'a pas la permission d'exécuter la commande 'set'\r\n"),
+		step("GET autre", "-NOPERM l'utilisateur 'app' n'a pas accès à une des clés utilisées par la commande 'get'\r\n"),
+	} {
+		if actualReply := testFixture.executeAsUser(t, aclUser, 0, testStep.commandArguments...); actualReply != testStep.expectedReply {
+			t.Fatalf("%v: réponse %q, attendu %q", testStep.commandArguments, actualReply, testStep.expectedReply)
+		}
+	}
+	testFixture.expectPropagated(t)
+}
@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// blockingListCommandNames liste les commandes qui peuvent bloquer le client en attendant un élément
+var blockingListCommandNames = map[string]bool{
+	"BLPOP": true, "BRPOP": true, "BLMOVE": true, "BRPOPLPUSH": true,
+}
+
+// isBlockingListCommand indique si une commande (en majuscules) attend un élément de liste
+func isBlockingListCommand(upperCommandName string) bool {
+	return blockingListCommandNames[upperCommandName]
+}
+
+// IsBlockingListCommand indique si une commande (casse indifférente) peut bloquer le client (BLPOP...)
+func IsBlockingListCommand(commandName string) bool {
+	return isBlockingListCommand(strings.ToUpper(commandName))
+}
+
+// parseBlockingListArguments construit la demande d'un client bloquant et lit son timeout
+// BLPOP|BRPOP key [key ...] timeout, BRPOPLPUSH source destination timeout,
+// BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout
+func parseBlockingListArguments(upperCommandName string, commandArguments []string) (*storage.ListWaiter, time.Duration, string) {
+	blockingTimeout, errorMessage := parseBlockingTimeout(commandArguments[len(commandArguments)-1])
+	if errorMessage != "" {
+		return nil, 0, errorMessage
+	}
+
+	switch upperCommandName {
+	case "BLPOP", "BRPOP":
+		watchedListKeys := commandArguments[:len(commandArguments)-1]
+		return storage.NewListWaiter(watchedListKeys, upperCommandName == "BLPOP", "", false), blockingTimeout, ""
+	case "BRPOPLPUSH":
+		return storage.NewListWaiter(commandArguments[:1], false, commandArguments[1], true), blockingTimeout, ""
+	default:
+		popFromLeft, sourceSideValid := parseListSide(commandArguments[2])
+		pushToLeft, destinationSideValid := parseListSide(commandArguments[3])
+		if !sourceSideValid || !destinationSideValid {
+			return nil, 0, "ERREUR : erreur de syntaxe (attendu: BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout)"
+		}
+		return storage.NewListWaiter(commandArguments[:1], popFromLeft, commandArguments[1], pushToLeft), blockingTimeout, ""
+	}
+}
+
+// parseListSide lit LEFT ou RIGHT (true pour LEFT)
+func parseListSide(sideArgument string) (bool, bool) {
+	switch strings.ToUpper(sideArgument) {
+	case "LEFT":
+		return true, true
+	case "RIGHT":
+		return false, true
+	}
+	return false, false
+}
+
+// formatListSide retourne LEFT ou RIGHT (argument de LMOVE)
+func formatListSide(isLeftSide bool) string {
+	if isLeftSide {
+		return "LEFT"
+	}
+	return "RIGHT"
+}
+
+// parseBlockingTimeout lit un timeout en secondes (décimales acceptées), 0 pour attendre indéfiniment
+func parseBlockingTimeout(timeoutArgument string) (time.Duration, string) {
+	timeoutSeconds, parseError := strconv.ParseFloat(timeoutArgument, 64)
+	if parseError != nil || math.IsNaN(timeoutSeconds) || math.IsInf(timeoutSeconds, 0) {
+		return 0, "ERREUR : le timeout n'est pas un nombre valide"
+	}
+	if timeoutSeconds < 0 {
+		return 0, "ERREUR : le timeout est négatif"
+	}
+	return time.Duration(timeoutSeconds * float64(time.Second)), ""
+}
+
+// WriteBlockingListReply écrit la réponse d'une commande bloquante une fois servie (servedElement nil : timeout)
+// BLPOP/BRPOP : [liste, élément] ou array null ; BLMOVE/BRPOPLPUSH : élément ou bulk string null
+func WriteBlockingListReply(protocolEncoder *protocol.RedisSerializationProtocolEncoder, listWaiter *storage.ListWaiter, servedElement *storage.ServedListElement) error {
+	if listWaiter.IsMoveRequest() {
+		if servedElement == nil {
+			return protocolEncoder.WriteNullBulkStringResponse()
+		}
+		return protocolEncoder.WriteBulkStringResponse(servedElement.Element)
+	}
+	if servedElement == nil {
+		return protocolEncoder.WriteNullArrayResponse()
+	}
+	return protocolEncoder.WriteArrayResponse([]string{servedElement.SourceListKey, servedElement.Element})
+}
+
+// popForBlockingListCommand sert la commande bloquante si une liste contient un élément
+// Sans élément, le client est inscrit comme waiter si registerIfEmpty, sinon la réponse de timeout est écrite
+// Retourne le waiter inscrit (nil si une réponse a été écrite) et la forme propagée du retrait s'il a eu lieu
+func popForBlockingListCommand(upperCommandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, registerIfEmpty bool) (*storage.ListWaiter, time.Duration, []propagatedCommand, error) {
+	listWaiter, blockingTimeout, errorMessage := parseBlockingListArguments(upperCommandName, commandArguments)
+	if errorMessage != "" {
+		return nil, 0, nil, protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	servedElement, popError := redisStorage.PopOrRegisterListWaiter(listWaiter, registerIfEmpty)
+	if popError != nil {
+		return nil, 0, nil, protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	if servedElement == nil {
+		if registerIfEmpty {
+			return listWaiter, blockingTimeout, nil, nil
+		}
+		return nil, 0, nil, WriteBlockingListReply(protocolEncoder, listWaiter, nil)
+	}
+	return nil, 0, []propagatedCommand{propagateServedListElement(*servedElement)}, WriteBlockingListReply(protocolEncoder, listWaiter, servedElement)
+}
+
+// ExecuteBlockingListCommand exécute BLPOP, BRPOP, BLMOVE ou BRPOPLPUSH pour un client hors transaction
+// Si une liste contient un élément la réponse est écrite immédiatement ; sinon le client est inscrit
+// dans la file d'attente des listes et le waiter est retourné avec le timeout, sans réponse écrite
+// L'attente elle-même (hors verrou) et la réponse finale sont à la charge de l'appelant
+func (commandRegistry *RedisCommandRegistry) ExecuteBlockingListCommand(commandUser *RedisAclUser, commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) (*storage.ListWaiter, time.Duration, error) {
+	if validationError := commandRegistry.ValidateCommandSyntax(commandUser, commandName, commandArguments); validationError != "" {
+		return nil, 0, protocolEncoder.WriteErrorResponse(validationError)
+	}
+
+	commandRegistry.commandExecutionMutex.RLock()
+	defer commandRegistry.commandExecutionMutex.RUnlock()
+	defer redisStorage.LockWriteCommands()()
+	// Un déplacement (BLMOVE) peut remplir une liste attendue par d'autres clients
+	defer commandRegistry.serveReadyListWaiters(redisStorage)
+
+	upperCommandName := strings.ToUpper(commandName)
+	var registeredWaiter *storage.ListWaiter
+	var blockingTimeout time.Duration
+	blockingHandler := func(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+		var servedPop []propagatedCommand
+		var popError error
+		registeredWaiter, blockingTimeout, servedPop, popError = popForBlockingListCommand(upperCommandName, commandArguments, redisStorage, protocolEncoder, true)
+		return servedPop, popError
+	}
+	executionError := commandRegistry.executeRegisteredCommand(upperCommandName, blockingHandler, commandArguments, redisStorage, protocolEncoder)
+	return registeredWaiter, blockingTimeout, executionError
+}
+
+// RestoreServedListElement remet dans sa liste source, du côté où il a été retiré, l'élément servi à un client
+// bloqué (BLPOP, BRPOP) qui n'a pas pu le recevoir (déconnexion, échec d'écriture de la réponse)
+// Un élément déplacé (BLMOVE, BRPOPLPUSH) n'est pas perdu : il reste dans sa liste de destination
+func (commandRegistry *RedisCommandRegistry) RestoreServedListElement(servedElement *storage.ServedListElement, redisStorage *storage.RedisInMemoryStorage) error {
+	listWaiter := servedElement.ListWaiter
+	if listWaiter.IsMoveRequest() {
+		return nil
+	}
+	pushCommandName := "RPUSH"
+	if listWaiter.PopFromLeft {
+		pushCommandName = "LPUSH"
+	}
+	discardEncoder := protocol.NewRedisSerializationProtocolEncoder(io.Discard)
+	return commandRegistry.ExecuteCommand(nil, pushCommandName, []string{servedElement.SourceListKey, servedElement.Element}, redisStorage, discardEncoder)
+}
+
+// handleBlockingLeftPopCommand implémente BLPOP key [key ...] timeout dans une transaction ou un script :
+// comme dans Redis, la commande ne bloque pas et retourne null si toutes les listes sont vides
+func (commandRegistry *RedisCommandRegistry) handleBlockingLeftPopCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	_, _, servedPop, popError := popForBlockingListCommand("BLPOP", commandArguments, redisStorage, protocolEncoder, false)
+	return servedPop, popError
+}
+
+// handleBlockingRightPopCommand implémente BRPOP key [key ...] timeout sans blocage (transaction, script)
+func (commandRegistry *RedisCommandRegistry) handleBlockingRightPopCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	_, _, servedPop, popError := popForBlockingListCommand("BRPOP", commandArguments, redisStorage, protocolEncoder, false)
+	return servedPop, popError
+}
+
+// handleBlockingMoveCommand implémente BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout sans blocage
+func (commandRegistry *RedisCommandRegistry) handleBlockingMoveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	_, _, servedPop, popError := popForBlockingListCommand("BLMOVE", commandArguments, redisStorage, protocolEncoder, false)
+	return servedPop, popError
+}
+
+// handleBlockingRightPopLeftPushCommand implémente BRPOPLPUSH source destination timeout sans blocage
+func (commandRegistry *RedisCommandRegistry) handleBlockingRightPopLeftPushCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	_, _, servedPop, popError := popForBlockingListCommand("BRPOPLPUSH", commandArguments, redisStorage, protocolEncoder, false)
+	return servedPop, popError
+}
+
+// propagateServedListElement retourne la forme propagée d'un élément servi à un client bloqué :
+// LPOP/RPOP de la liste source, ou LMOVE pour un déplacement
+func propagateServedListElement(servedElement storage.ServedListElement) propagatedCommand {
+	listWaiter := servedElement.ListWaiter
+	if listWaiter.IsMoveRequest() {
+		return propagatedCommand{commandName: "LMOVE", commandArguments: []string{servedElement.SourceListKey, listWaiter.DestinationKey,
+			formatListSide(listWaiter.PopFromLeft), formatListSide(listWaiter.PushToLeft)}}
+	}
+	if listWaiter.PopFromLeft {
+		return propagatedCommand{commandName: "LPOP", commandArguments: []string{servedElement.SourceListKey}}
+	}
+	return propagatedCommand{commandName: "RPOP", commandArguments: []string{servedElement.SourceListKey}}
+}
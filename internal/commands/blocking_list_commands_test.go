@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"redis-go/internal/protocol"
+)
+
+func TestBlockingListCommandsWithoutWaiting(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "élément disponible : propagé comme LPOP, RPOP ou LMOVE",
+			testSteps: []commandTestStep{
+				step("RPUSH l a b c d", ":4\r\n"),
+				step("BLPOP vide l 0", array("l", "a")),
+				step("BRPOP l 0.5", array("l", "d")),
+				step("BLMOVE l dest LEFT RIGHT 1", bulk("b")),
+				step("BRPOPLPUSH l dest 0", bulk("c")),
+				step("LRANGE dest 0 -1", array("c", "b")),
+				step("EXISTS l", ":0\r\n"),
+			},
+			expectedPropagated: []string{"0 RPUSH l a b c d", "0 LPOP l", "0 RPOP l", "0 LMOVE l dest LEFT RIGHT", "0 LMOVE l dest RIGHT LEFT"},
+		},
+		{
+			// Dans une transaction ou un script, les commandes bloquantes retournent null au lieu d'attendre
+			name: "listes vides : réponse null sans attente",
+			testSteps: []commandTestStep{
+				step("BLPOP vide 0", "*-1\r\n"),
+				step("BRPOP vide autre 1", "*-1\r\n"),
+				step("BLMOVE vide dest LEFT LEFT 0", "$-1\r\n"),
+				step("BRPOPLPUSH vide dest 0", "$-1\r\n"),
+				step("EVAL return(redis.call('BLPOP','vide',0)) 0", "$-1\r\n"),
+			},
+		},
+		{
+			name: "timeouts, syntaxe et types invalides",
+			testSteps: []commandTestStep{
+				step("SET chaîne v", "+OK\r\n"),
+				step("BLPOP l -1", "-ERREUR : le timeout est négatif\r\n"),
+				step("BLPOP l abc", "-ERREUR : le timeout n'est pas un nombre valide\r\n"),
+				step("BLPOP l inf", "-ERREUR : le timeout n'est pas un nombre valide\r\n"),
+				step("BLMOVE l dest UP LEFT 0", "-ERREUR : erreur de syntaxe (attendu: BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout)\r\n"),
+				step("BLPOP chaîne 0", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("RPUSH l a", ":1\r\n"),
+				step("BLMOVE l chaîne LEFT LEFT 0", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+			},
+			expectedPropagated: []string{"0 SET chaîne v", "0 RPUSH l a"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			testFixture.expectPropagated(t, testCase.expectedPropagated...)
+		})
+	}
+}
+
+func TestExecuteBlockingListCommand(t *testing.T) {
+	testCases := []struct {
+		name                string
+		blockingCommand     []string
+		expectedTimeout     time.Duration
+		expectedReply       string
+		expectedPropagated  []string
+		expectedDestination []string
+	}{
+		{
+			name:               "BRPOP servi par un RPUSH",
+			blockingCommand:    []string{"BRPOP", "vide", "l", "2.5"},
+			expectedTimeout:    2500 * time.Millisecond,
+			expectedReply:      array("l", "b"),
+			expectedPropagated: []string{"0 RPUSH l a b", "0 RPOP l"},
+		},
+		{
+			name:                "BLMOVE servi par un RPUSH",
+			blockingCommand:     []string{"BLMOVE", "l", "dest", "LEFT", "LEFT", "0"},
+			expectedReply:       bulk("a"),
+			expectedPropagated:  []string{"0 RPUSH l a b", "0 LMOVE l dest LEFT LEFT"},
+			expectedDestination: []string{"a"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			var replyBuffer bytes.Buffer
+			protocolEncoder := protocol.NewRedisSerializationProtocolEncoder(&replyBuffer)
+			listWaiter, blockingTimeout, executionError := testFixture.commandRegistry.ExecuteBlockingListCommand(nil, testCase.blockingCommand[0], testCase.blockingCommand[1:], testFixture.databaseStorages[0], protocolEncoder)
+			if executionError != nil || listWaiter == nil || replyBuffer.Len() != 0 {
+				t.Fatalf("le client doit être inscrit sans réponse: %v %v %q", listWaiter, executionError, replyBuffer.String())
+			}
+			if blockingTimeout != testCase.expectedTimeout {
+				t.Fatalf("timeout %v, attendu %v", blockingTimeout, testCase.expectedTimeout)
+			}
+
+			testFixture.execute(t, "RPUSH", "l", "a", "b")
+			select {
+			case servedElement := <-listWaiter.ServedElement():
+				if writeError := WriteBlockingListReply(protocolEncoder, listWaiter, &servedElement); writeError != nil || replyBuffer.String() != testCase.expectedReply {
+					t.Fatalf("réponse %q (%v), attendu %q", replyBuffer.String(), writeError, testCase.expectedReply)
+				}
+			default:
+				t.Fatalf("le client bloqué doit être servi par RPUSH")
+			}
+			testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			if testCase.expectedDestination != nil {
+				testFixture.runSteps(t, []commandTestStep{step("LRANGE dest 0 -1", array(testCase.expectedDestination...))})
+			}
+		})
+	}
+}
+
+func TestRestoreServedListElement(t *testing.T) {
+	testFixture := newCommandTestFixture()
+	var replyBuffer bytes.Buffer
+	protocolEncoder := protocol.NewRedisSerializationProtocolEncoder(&replyBuffer)
+	listWaiter, _, _ := testFixture.commandRegistry.ExecuteBlockingListCommand(nil, "BLPOP", []string{"l", "0"}, testFixture.databaseStorages[0], protocolEncoder)
+	testFixture.execute(t, "RPUSH", "l", "a", "b")
+	servedElement := <-listWaiter.ServedElement()
+
+	// Le client s'est déconnecté avant de recevoir a : l'élément retrouve sa place en tête de liste
+	if restoreError := testFixture.commandRegistry.RestoreServedListElement(&servedElement, testFixture.databaseStorages[0]); restoreError != nil {
+		t.Fatalf("restauration impossible: %v", restoreError)
+	}
+	testFixture.runSteps(t, []commandTestStep{step("LRANGE l 0 -1", array("a", "b"))})
+	testFixture.expectPropagated(t, "0 RPUSH l a b", "0 LPOP l", "0 LPUSH l a")
+}
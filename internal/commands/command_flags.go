@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+)
+
+// writeCommandNames liste les commandes qui modifient le dataset
+var writeCommandNames = map[string]bool{
+	"SET": true, "SETNX": true, "SETEX": true, "PSETEX": true, "GETSET": true, "GETDEL": true, "GETEX": true,
+	"APPEND": true, "SETRANGE": true, "MSET": true, "MSETNX": true,
+	"DEL": true, "INCR": true, "DECR": true, "INCRBY": true, "DECRBY": true, "INCRBYFLOAT": true,
+	"LPUSH": true, "RPUSH": true, "LPOP": true, "RPOP": true, "LPUSHX": true, "RPUSHX": true,
+	"LSET": true, "LINSERT": true, "LREM": true, "LTRIM": true, "LMOVE": true, "RPOPLPUSH": true,
+	"BLPOP": true, "BRPOP": true, "BLMOVE": true, "BRPOPLPUSH": true,
+	"SADD": true, "SREM": true, "SPOP": true, "SMOVE": true, "SINTERSTORE": true, "SUNIONSTORE": true, "SDIFFSTORE": true,
+	"HSET": true, "HDEL": true, "HINCRBY": true, "HINCRBYFLOAT": true, "HSETNX": true,
+	"HEXPIRE": true, "HPEXPIRE": true, "HEXPIREAT": true, "HPEXPIREAT": true, "HPERSIST": true,
+	"ZADD": true, "ZREM": true, "ZINCRBY": true,
+	"EXPIRE": true, "PEXPIRE": true, "EXPIREAT": true, "PEXPIREAT": true, "PERSIST": true,
+	"FLUSHALL": true, "FLUSHDB": true, "MOVE": true, "SWAPDB": true,
+}
+
+// isWriteCommand indique si une commande (en majuscules) modifie le dataset
+func isWriteCommand(upperCommandName string) bool {
+	return writeCommandNames[upperCommandName]
+}
+
+// IsWriteCommand indique si une commande (casse indifférente) modifie le dataset
+func IsWriteCommand(commandName string) bool {
+	return isWriteCommand(strings.ToUpper(commandName))
+}
+
+// exclusiveCommandNames liste les commandes exécutées sous accès exclusif : les scripts, et les commandes
+// qui modifient plusieurs bases (leur propagation reste ainsi ordonnée avec les écritures de chaque base)
+var exclusiveCommandNames = map[string]bool{
+	"EVAL": true, "EVALSHA": true,
+	"MOVE": true, "SWAPDB": true, "FLUSHALL": true,
+}
+
+// isExclusiveCommand indique si une commande (en majuscules) doit s'exécuter seule
+func isExclusiveCommand(upperCommandName string) bool {
+	return exclusiveCommandNames[upperCommandName]
+}
+
+// conditionalWriteCommandNames liste les écritures qui n'ont rien modifié quand elles ne propagent aucune commande
+// (MSETNX sur une clé existante, SMOVE d'un membre absent...) : elles ne comptent alors pas comme une écriture
+var conditionalWriteCommandNames = map[string]bool{
+	"MSETNX": true, "SMOVE": true,
+}
+
+// writesOnlyWhenPropagated indique si une commande (en majuscules) n'écrit que lorsqu'elle propage une commande
+func writesOnlyWhenPropagated(upperCommandName string) bool {
+	return isBlockingListCommand(upperCommandName) || conditionalWriteCommandNames[upperCommandName]
+}
+
+// commandArities indique le nombre d'arguments attendu, nom de la commande inclus (convention Redis)
+// Une valeur positive est un nombre exact, une valeur négative un minimum
+var commandArities = map[string]int{
+	"SET": -3, "SETNX": 3, "SETEX": 4, "PSETEX": 4, "GET": 2, "GETSET": 3, "GETDEL": 2, "GETEX": -2,
+	"APPEND": 3, "STRLEN": 2, "GETRANGE": 4, "SETRANGE": 4, "MGET": -2, "MSET": -3, "MSETNX": -3,
+	"DEL": -2, "EXISTS": -2, "KEYS": 2, "TYPE": 2, "SCAN": -2,
+	"INCR": 2, "DECR": 2, "INCRBY": 3, "DECRBY": 3, "INCRBYFLOAT": 3,
+	"LPUSH": -3, "RPUSH": -3, "LPOP": -2, "RPOP": -2, "LLEN": 2, "LRANGE": 4,
+	"LPUSHX": -3, "RPUSHX": -3, "LINDEX": 3, "LSET": 4, "LINSERT": 5, "LREM": 4, "LTRIM": 4, "LPOS": -3,
+	"LMOVE": 5, "RPOPLPUSH": 3,
+	"BLPOP": -3, "BRPOP": -3, "BLMOVE": 6, "BRPOPLPUSH": 4,
+	"SADD": -3, "SMEMBERS": 2, "SISMEMBER": 3, "SSCAN": -3, "SREM": -3, "SCARD": 2, "SPOP": -2, "SRANDMEMBER": -2,
+	"SMOVE": 4, "SMISMEMBER": -3, "SINTERCARD": -3, "SINTER": -2, "SUNION": -2, "SDIFF": -2,
+	"SINTERSTORE": -3, "SUNIONSTORE": -3, "SDIFFSTORE": -3,
+	"HSET": -4, "HGET": 3, "HGETALL": 2, "HSCAN": -3, "HDEL": -3, "HEXISTS": 3, "HLEN": 2, "HKEYS": 2, "HVALS": 2,
+	"HMGET": -3, "HINCRBY": 4, "HINCRBYFLOAT": 4, "HSETNX": 4, "HSTRLEN": 3, "HRANDFIELD": -2,
+	"HEXPIRE": -6, "HPEXPIRE": -6, "HEXPIREAT": -6, "HPEXPIREAT": -6, "HTTL": -5, "HPTTL": -5, "HPERSIST": -5,
+	"ZADD": -4, "ZREM": -3, "ZSCORE": 3, "ZINCRBY": 4, "ZCARD": 2, "ZRANK": -3, "ZREVRANK": -3,
+	"ZRANGE": -4, "ZREVRANGE": -4, "ZRANGEBYSCORE": -4, "ZREVRANGEBYSCORE": -4, "ZCOUNT": 4, "ZSCAN": -3,
+	"EXPIRE": -3, "PEXPIRE": -3, "EXPIREAT": -3, "PEXPIREAT": -3,
+	"TTL": 2, "PTTL": 2, "EXPIRETIME": 2, "PEXPIRETIME": 2, "PERSIST": 2,
+	"PING": -1, "ECHO": 2, "DBSIZE": 1, "FLUSHALL": -1, "FLUSHDB": -1, "ALAIDE": -1,
+	"MOVE": 3, "SWAPDB": 3,
+	"SAVE": 1, "BGSAVE": -1, "LASTSAVE": 1, "BGREWRITEAOF": 1,
+	"PUBLISH": 3, "PUBSUB": -2,
+	"EVAL": -3, "EVALSHA": -3, "SCRIPT": -2,
+	"REPLICAOF": 3, "SLAVEOF": 3, "ROLE": 1, "INFO": -1,
+}
+
+// hasValidArity vérifie le nombre d'arguments d'une commande (sans compter son nom)
+// Les commandes absentes de la table sont validées par leur handler
+func hasValidArity(upperCommandName string, argumentCount int) bool {
+	expectedArity, arityKnown := commandArities[upperCommandName]
+	if !arityKnown {
+		return true
+	}
+	if expectedArity < 0 {
+		return argumentCount+1 >= -expectedArity
+	}
+	return argumentCount+1 == expectedArity
+}
+
+// commandCategoryMembers associe chaque catégorie ACL (sans @) à ses commandes
+// Les catégories read et write sont déduites de writeCommandNames et commandKeySpecifications
+var commandCategoryMembers = map[string][]string{
+	"keyspace": {"DEL", "EXISTS", "KEYS", "SCAN", "TYPE", "EXPIRE", "PEXPIRE", "EXPIREAT", "PEXPIREAT", "TTL", "PTTL",
+		"EXPIRETIME", "PEXPIRETIME", "PERSIST", "DBSIZE", "FLUSHDB", "FLUSHALL", "MOVE", "SWAPDB", "SELECT"},
+	"string": {"SET", "SETNX", "SETEX", "PSETEX", "GET", "GETSET", "GETDEL", "GETEX", "INCR", "DECR", "INCRBY", "DECRBY",
+		"INCRBYFLOAT", "APPEND", "STRLEN", "GETRANGE", "SETRANGE", "MGET", "MSET", "MSETNX"},
+	"list": {"LPUSH", "RPUSH", "LPOP", "RPOP", "LLEN", "LRANGE", "LPUSHX", "RPUSHX", "LINDEX", "LSET", "LINSERT",
+		"LREM", "LTRIM", "LPOS", "LMOVE", "RPOPLPUSH", "BLPOP", "BRPOP", "BLMOVE", "BRPOPLPUSH"},
+	"blocking": {"BLPOP", "BRPOP", "BLMOVE", "BRPOPLPUSH"},
+	"set": {"SADD", "SMEMBERS", "SISMEMBER", "SSCAN", "SREM", "SCARD", "SPOP", "SRANDMEMBER", "SMOVE", "SMISMEMBER",
+		"SINTERCARD", "SINTER", "SUNION", "SDIFF", "SINTERSTORE", "SUNIONSTORE", "SDIFFSTORE"},
+	"hash": {"HSET", "HGET", "HGETALL", "HSCAN", "HDEL", "HEXISTS", "HLEN", "HKEYS", "HVALS", "HMGET", "HINCRBY",
+		"HINCRBYFLOAT", "HSETNX", "HSTRLEN", "HRANDFIELD", "HEXPIRE", "HPEXPIRE", "HEXPIREAT", "HPEXPIREAT", "HTTL", "HPTTL",
+		"HPERSIST"},
+	"sortedset":   {"ZADD", "ZREM", "ZSCORE", "ZINCRBY", "ZCARD", "ZRANK", "ZREVRANK", "ZRANGE", "ZREVRANGE", "ZRANGEBYSCORE", "ZREVRANGEBYSCORE", "ZCOUNT", "ZSCAN"},
+	"pubsub":      {"SUBSCRIBE", "UNSUBSCRIBE", "PSUBSCRIBE", "PUNSUBSCRIBE", "PUBLISH", "PUBSUB"},
+	"transaction": {"MULTI", "EXEC", "DISCARD", "WATCH", "UNWATCH"},
+	"scripting":   {"EVAL", "EVALSHA", "SCRIPT"},
+	"connection":  {"PING", "ECHO", "HELLO", "AUTH", "SELECT", "QUIT", "ALAIDE", "CLIENT"},
+	"admin": {"SAVE", "BGSAVE", "LASTSAVE", "BGREWRITEAOF", "ACL",
+		"REPLICAOF", "SLAVEOF", "PSYNC", "SYNC", "REPLCONF", "CLIENT"},
+	"dangerous": {"KEYS", "FLUSHDB", "FLUSHALL", "SWAPDB", "SAVE", "BGSAVE", "LASTSAVE", "BGREWRITEAOF", "ACL",
+		"REPLICAOF", "SLAVEOF", "PSYNC", "SYNC", "REPLCONF", "ROLE", "INFO", "CLIENT"},
+}
+
+// getCommandCategoryMembers retourne les commandes d'une catégorie ACL (nil si la catégorie est inconnue)
+func getCommandCategoryMembers(categoryName string) []string {
+	switch categoryName {
+	case "write":
+		var writeCommands []string
+		for commandName := range writeCommandNames {
+			writeCommands = append(writeCommands, commandName)
+		}
+		return writeCommands
+	case "read":
+		var readCommands []string
+		for commandName := range commandKeySpecifications {
+			if !isWriteCommand(commandName) {
+				readCommands = append(readCommands, commandName)
+			}
+		}
+		for commandName := range numberedKeyCommands {
+			if !isWriteCommand(commandName) && !isExclusiveCommand(commandName) {
+				readCommands = append(readCommands, commandName)
+			}
+		}
+		return readCommands
+	default:
+		return commandCategoryMembers[categoryName]
+	}
+}
+
+// commandKeySpecification indique la position des clés dans les arguments d'une commande (nom exclu)
+// Un index négatif est compté depuis la fin (-1 = dernier argument)
+type commandKeySpecification struct {
+	firstKeyIndex int
+	lastKeyIndex  int
+	keyStep       int
+}
+
+// commandKeySpecifications liste les commandes qui accèdent à des clés (vérifiées par les motifs ACL)
+// Les commandes dont les clés dépendent de numkeys (EVAL, SINTERCARD...) sont listées dans numberedKeyCommands
+var commandKeySpecifications = map[string]commandKeySpecification{
+	"SET": {0, 0, 1}, "SETNX": {0, 0, 1}, "SETEX": {0, 0, 1}, "PSETEX": {0, 0, 1}, "GET": {0, 0, 1},
+	"GETSET": {0, 0, 1}, "GETDEL": {0, 0, 1}, "GETEX": {0, 0, 1},
+	"APPEND": {0, 0, 1}, "STRLEN": {0, 0, 1}, "GETRANGE": {0, 0, 1}, "SETRANGE": {0, 0, 1},
+	"MGET": {0, -1, 1}, "MSET": {0, -1, 2}, "MSETNX": {0, -1, 2},
+	"INCR": {0, 0, 1}, "DECR": {0, 0, 1}, "INCRBY": {0, 0, 1}, "DECRBY": {0, 0, 1}, "INCRBYFLOAT": {0, 0, 1},
+	"DEL": {0, -1, 1}, "EXISTS": {0, -1, 1}, "TYPE": {0, 0, 1}, "MOVE": {0, 0, 1},
+	"LPUSH": {0, 0, 1}, "RPUSH": {0, 0, 1}, "LPOP": {0, 0, 1}, "RPOP": {0, 0, 1}, "LLEN": {0, 0, 1}, "LRANGE": {0, 0, 1},
+	"LPUSHX": {0, 0, 1}, "RPUSHX": {0, 0, 1}, "LINDEX": {0, 0, 1}, "LSET": {0, 0, 1}, "LINSERT": {0, 0, 1},
+	"LREM": {0, 0, 1}, "LTRIM": {0, 0, 1}, "LPOS": {0, 0, 1}, "LMOVE": {0, 1, 1}, "RPOPLPUSH": {0, 1, 1},
+	"BLPOP": {0, -2, 1}, "BRPOP": {0, -2, 1}, "BLMOVE": {0, 1, 1}, "BRPOPLPUSH": {0, 1, 1},
+	"SADD": {0, 0, 1}, "SMEMBERS": {0, 0, 1}, "SISMEMBER": {0, 0, 1}, "SSCAN": {0, 0, 1}, "SREM": {0, 0, 1},
+	"SCARD": {0, 0, 1}, "SPOP": {0, 0, 1}, "SRANDMEMBER": {0, 0, 1}, "SMOVE": {0, 1, 1}, "SMISMEMBER": {0, 0, 1},
+	"SINTER": {0, -1, 1}, "SUNION": {0, -1, 1}, "SDIFF": {0, -1, 1},
+	"SINTERSTORE": {0, -1, 1}, "SUNIONSTORE": {0, -1, 1}, "SDIFFSTORE": {0, -1, 1},
+	"HSET": {0, 0, 1}, "HGET": {0, 0, 1}, "HGETALL": {0, 0, 1}, "HSCAN": {0, 0, 1}, "HDEL": {0, 0, 1},
+	"HEXISTS": {0, 0, 1}, "HLEN": {0, 0, 1}, "HKEYS": {0, 0, 1}, "HVALS": {0, 0, 1}, "HMGET": {0, 0, 1},
+	"HINCRBY": {0, 0, 1}, "HINCRBYFLOAT": {0, 0, 1}, "HSETNX": {0, 0, 1}, "HSTRLEN": {0, 0, 1}, "HRANDFIELD": {0, 0, 1},
+	"HEXPIRE": {0, 0, 1}, "HPEXPIRE": {0, 0, 1}, "HEXPIREAT": {0, 0, 1}, "HPEXPIREAT": {0, 0, 1},
+	"HTTL": {0, 0, 1}, "HPTTL": {0, 0, 1}, "HPERSIST": {0, 0, 1},
+	"ZADD": {0, 0, 1}, "ZREM": {0, 0, 1}, "ZSCORE": {0, 0, 1}, "ZINCRBY": {0, 0, 1}, "ZCARD": {0, 0, 1},
+	"ZRANK": {0, 0, 1}, "ZREVRANK": {0, 0, 1}, "ZRANGE": {0, 0, 1}, "ZREVRANGE": {0, 0, 1},
+	"ZRANGEBYSCORE": {0, 0, 1}, "ZREVRANGEBYSCORE": {0, 0, 1}, "ZCOUNT": {0, 0, 1}, "ZSCAN": {0, 0, 1},
+	"EXPIRE": {0, 0, 1}, "PEXPIRE": {0, 0, 1}, "EXPIREAT": {0, 0, 1}, "PEXPIREAT": {0, 0, 1},
+	"TTL": {0, 0, 1}, "PTTL": {0, 0, 1}, "EXPIRETIME": {0, 0, 1}, "PEXPIRETIME": {0, 0, 1}, "PERSIST": {0, 0, 1},
+	"WATCH": {0, -1, 1},
+}
+
+// numberedKeyCommands associe les commandes dont les clés suivent un argument numkeys à la position de celui-ci
+var numberedKeyCommands = map[string]int{
+	"EVAL": 1, "EVALSHA": 1, "SINTERCARD": 0,
+}
+
+// extractCommandKeys retourne les clés accédées par une commande (nom en majuscules)
+func extractCommandKeys(upperCommandName string, commandArguments []string) []string {
+	if keyCountIndex, hasNumberedKeys := numberedKeyCommands[upperCommandName]; hasNumberedKeys {
+		if len(commandArguments) <= keyCountIndex {
+			return nil
+		}
+		firstKeyIndex := keyCountIndex + 1
+		keyCount, parseError := strconv.Atoi(commandArguments[keyCountIndex])
+		if parseError != nil || keyCount < 0 || keyCount > len(commandArguments)-firstKeyIndex {
+			return nil
+		}
+		return commandArguments[firstKeyIndex : firstKeyIndex+keyCount]
+	}
+
+	keySpecification, hasKeys := commandKeySpecifications[upperCommandName]
+	if !hasKeys || len(commandArguments) == 0 {
+		return nil
+	}
+	lastKeyIndex := keySpecification.lastKeyIndex
+	if lastKeyIndex < 0 {
+		lastKeyIndex += len(commandArguments)
+	}
+
+	var commandKeys []string
+	for keyIndex := keySpecification.firstKeyIndex; keyIndex <= lastKeyIndex && keyIndex < len(commandArguments); keyIndex += keySpecification.keyStep {
+		commandKeys = append(commandKeys, commandArguments[keyIndex])
+	}
+	return commandKeys
+}
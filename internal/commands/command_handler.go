@@ -3,6 +3,8 @@ package commands
 import (
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"redis-go/internal/protocol"
 	"redis-go/internal/storage"
@@ -11,16 +13,73 @@ import (
 // RedisCommandHandler représente une fonction qui traite une commande Redis
 type RedisCommandHandler func(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error
 
+// propagatedCommand est la forme sous laquelle une commande d'écriture est propagée (AOF, réplicas)
+type propagatedCommand struct {
+	commandName      string
+	commandArguments []string
+}
+
+// redisPropagatingCommandHandler traite une commande et retourne les commandes qui reproduisent son effet
+// lors d'un rejeu (date d'expiration absolue, valeur calculée, membres tirés au hasard...)
+// Aucune commande retournée : l'écriture n'a eu aucun effet à propager
+type redisPropagatingCommandHandler func(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error)
+
+// propagatedAsExecuted adapte un handler dont la commande est propagée telle qu'elle a été reçue
+func propagatedAsExecuted(upperCommandName string, commandHandler RedisCommandHandler) redisPropagatingCommandHandler {
+	return func(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+		if executionError := commandHandler(commandArguments, redisStorage, protocolEncoder); executionError != nil {
+			return nil, executionError
+		}
+		return []propagatedCommand{{commandName: upperCommandName, commandArguments: commandArguments}}, nil
+	}
+}
+
+// deferredPropagatedCommand est une écriture d'une exécution atomique (transaction, script) en attente de propagation
+type deferredPropagatedCommand struct {
+	databaseIndex int
+	propagatedCommand
+}
+
+// RedisWriteCommandListener est notifié après chaque commande d'écriture exécutée avec succès
+// databaseIndex est la base sur laquelle la commande a été exécutée (SELECT)
+type RedisWriteCommandListener func(databaseIndex int, commandName string, commandArguments []string)
+
+// RedisQueuedCommand représente une commande mise en file par MULTI
+type RedisQueuedCommand struct {
+	CommandName      string
+	CommandArguments []string
+}
+
 // RedisCommandRegistry contient toutes les commandes supportées
 type RedisCommandRegistry struct {
-	registeredCommands map[string]RedisCommandHandler
+	registeredCommands map[string]redisPropagatingCommandHandler
+	// databaseStorages contient les bases logiques dont les clients bloqués sont servis après les commandes atomiques
+	databaseStorages      []*storage.RedisInMemoryStorage
+	writeCommandCount     atomic.Int64
+	writeCommandListeners []RedisWriteCommandListener
+	// isPropagationDeferred met de côté dans deferredPropagation les écritures d'une exécution atomique,
+	// propagées ensemble à sa fin (protégés par l'accès exclusif à commandExecutionMutex)
+	isPropagationDeferred bool
+	deferredPropagation   []deferredPropagatedCommand
+	// commandExecutionMutex est partagé par les commandes et exclusif pour les opérations atomiques globales
+	commandExecutionMutex sync.RWMutex
+	scriptingEngine       *redisScriptingEngine
+	accessControlList     *RedisAccessControlList
+	// readOnlyReplica refuse les écritures des clients (réplica en lecture seule)
+	readOnlyReplica atomic.Bool
+	// keyspaceHitCount et keyspaceMissCount comptent les clés trouvées ou absentes lors des lectures (INFO stats)
+	keyspaceHitCount  atomic.Int64
+	keyspaceMissCount atomic.Int64
 }
 
-// NewRedisCommandRegistry crée un nouveau registre de commandes
-func NewRedisCommandRegistry() *RedisCommandRegistry {
+// NewRedisCommandRegistry crée un nouveau registre de commandes pour les bases logiques du serveur
+func NewRedisCommandRegistry(databaseStorages []*storage.RedisInMemoryStorage) *RedisCommandRegistry {
 	commandRegistry := &RedisCommandRegistry{
-		registeredCommands: make(map[string]RedisCommandHandler),
+		registeredCommands: make(map[string]redisPropagatingCommandHandler),
+		databaseStorages:   databaseStorages,
 	}
+	commandRegistry.scriptingEngine = newRedisScriptingEngine(commandRegistry)
+	commandRegistry.accessControlList = newRedisAccessControlList(commandRegistry.isKnownCommand)
 
 	// Enregistrement des commandes
 	commandRegistry.registerAllCommands()
@@ -32,62 +91,445 @@ func NewRedisCommandRegistry() *RedisCommandRegistry {
 func (commandRegistry *RedisCommandRegistry) registerAllCommands() {
 	commands := map[string]RedisCommandHandler{
 		// Commandes String
-		"SET":    commandRegistry.handleSetCommand,
-		"GET":    commandRegistry.handleGetCommand,
-		"DEL":    commandRegistry.handleDeleteCommand,
-		"EXISTS": commandRegistry.handleExistsCommand,
-		"KEYS":   commandRegistry.handleKeysCommand,
-		"TYPE":   commandRegistry.handleTypeCommand,
-		"INCR":   commandRegistry.handleIncrementCommand,
-		"DECR":   commandRegistry.handleDecrementCommand,
-		"INCRBY": commandRegistry.handleIncrementByCommand,
-		"DECRBY": commandRegistry.handleDecrementByCommand,
+		"SETNX":    commandRegistry.handleSetIfNotExistsCommand,
+		"GETSET":   commandRegistry.handleGetSetCommand,
+		"GET":      commandRegistry.handleGetCommand,
+		"APPEND":   commandRegistry.handleAppendCommand,
+		"STRLEN":   commandRegistry.handleStringLengthCommand,
+		"GETRANGE": commandRegistry.handleGetRangeCommand,
+		"SETRANGE": commandRegistry.handleSetRangeCommand,
+		"MGET":     commandRegistry.handleMultipleGetCommand,
+		"MSET":     commandRegistry.handleMultipleSetCommand,
+		"DEL":      commandRegistry.handleDeleteCommand,
+		"EXISTS":   commandRegistry.handleExistsCommand,
+		"KEYS":     commandRegistry.handleKeysCommand,
+		"SCAN":     commandRegistry.handleScanCommand,
+		"TYPE":     commandRegistry.handleTypeCommand,
+		"INCR":     commandRegistry.handleIncrementCommand,
+		"DECR":     commandRegistry.handleDecrementCommand,
+		"INCRBY":   commandRegistry.handleIncrementByCommand,
+		"DECRBY":   commandRegistry.handleDecrementByCommand,
 
 		// Commandes List
-		"LPUSH":  commandRegistry.handleLeftPushCommand,
-		"RPUSH":  commandRegistry.handleRightPushCommand,
-		"LPOP":   commandRegistry.handleLeftPopCommand,
-		"RPOP":   commandRegistry.handleRightPopCommand,
-		"LLEN":   commandRegistry.handleListLengthCommand,
-		"LRANGE": commandRegistry.handleListRangeCommand,
+		"LPUSH":     commandRegistry.handleLeftPushCommand,
+		"RPUSH":     commandRegistry.handleRightPushCommand,
+		"LPOP":      commandRegistry.handleLeftPopCommand,
+		"RPOP":      commandRegistry.handleRightPopCommand,
+		"LLEN":      commandRegistry.handleListLengthCommand,
+		"LRANGE":    commandRegistry.handleListRangeCommand,
+		"LPUSHX":    commandRegistry.handleLeftPushExistingCommand,
+		"RPUSHX":    commandRegistry.handleRightPushExistingCommand,
+		"LINDEX":    commandRegistry.handleListIndexCommand,
+		"LSET":      commandRegistry.handleListSetCommand,
+		"LINSERT":   commandRegistry.handleListInsertCommand,
+		"LREM":      commandRegistry.handleListRemoveCommand,
+		"LTRIM":     commandRegistry.handleListTrimCommand,
+		"LPOS":      commandRegistry.handleListPositionCommand,
+		"LMOVE":     commandRegistry.handleListMoveCommand,
+		"RPOPLPUSH": commandRegistry.handleRightPopLeftPushCommand,
 
 		// Commandes Set
-		"SADD":      commandRegistry.handleSetAddCommand,
-		"SMEMBERS":  commandRegistry.handleSetMembersCommand,
-		"SISMEMBER": commandRegistry.handleSetIsMemberCommand,
+		"SADD":        commandRegistry.handleSetAddCommand,
+		"SMEMBERS":    commandRegistry.handleSetMembersCommand,
+		"SISMEMBER":   commandRegistry.handleSetIsMemberCommand,
+		"SSCAN":       commandRegistry.handleSetScanCommand,
+		"SREM":        commandRegistry.handleSetRemoveCommand,
+		"SCARD":       commandRegistry.handleSetCardinalityCommand,
+		"SRANDMEMBER": commandRegistry.handleSetRandomMemberCommand,
+		"SMISMEMBER":  commandRegistry.handleSetMultipleIsMemberCommand,
+		"SINTERCARD":  commandRegistry.handleSetIntersectionCardinalityCommand,
+		"SINTER":      commandRegistry.handleSetIntersectionCommand,
+		"SUNION":      commandRegistry.handleSetUnionCommand,
+		"SDIFF":       commandRegistry.handleSetDifferenceCommand,
+		"SINTERSTORE": commandRegistry.handleSetIntersectionStoreCommand,
+		"SUNIONSTORE": commandRegistry.handleSetUnionStoreCommand,
+		"SDIFFSTORE":  commandRegistry.handleSetDifferenceStoreCommand,
 
 		// Commandes Hash
-		"HSET":    commandRegistry.handleHashSetCommand,
-		"HGET":    commandRegistry.handleHashGetCommand,
-		"HGETALL": commandRegistry.handleHashGetAllCommand,
+		"HSET":       commandRegistry.handleHashSetCommand,
+		"HGET":       commandRegistry.handleHashGetCommand,
+		"HGETALL":    commandRegistry.handleHashGetAllCommand,
+		"HSCAN":      commandRegistry.handleHashScanCommand,
+		"HDEL":       commandRegistry.handleHashDeleteCommand,
+		"HEXISTS":    commandRegistry.handleHashExistsCommand,
+		"HLEN":       commandRegistry.handleHashLengthCommand,
+		"HKEYS":      commandRegistry.handleHashKeysCommand,
+		"HVALS":      commandRegistry.handleHashValuesCommand,
+		"HMGET":      commandRegistry.handleHashMultipleGetCommand,
+		"HINCRBY":    commandRegistry.handleHashIncrementByCommand,
+		"HSETNX":     commandRegistry.handleHashSetIfNotExistsCommand,
+		"HSTRLEN":    commandRegistry.handleHashStringLengthCommand,
+		"HRANDFIELD": commandRegistry.handleHashRandomFieldCommand,
+		"HTTL":       commandRegistry.handleHashFieldTimeToLiveCommand,
+		"HPTTL":      commandRegistry.handleHashFieldPreciseTimeToLiveCommand,
+		"HPERSIST":   commandRegistry.handleHashFieldPersistCommand,
+
+		// Commandes Sorted Set
+		"ZADD":             commandRegistry.handleSortedSetAddCommand,
+		"ZREM":             commandRegistry.handleSortedSetRemoveCommand,
+		"ZSCORE":           commandRegistry.handleSortedSetScoreCommand,
+		"ZINCRBY":          commandRegistry.handleSortedSetIncrementByCommand,
+		"ZCARD":            commandRegistry.handleSortedSetCardinalityCommand,
+		"ZRANK":            commandRegistry.handleSortedSetRankCommand,
+		"ZREVRANK":         commandRegistry.handleSortedSetReverseRankCommand,
+		"ZRANGE":           commandRegistry.handleSortedSetRangeCommand,
+		"ZREVRANGE":        commandRegistry.handleSortedSetReverseRangeCommand,
+		"ZRANGEBYSCORE":    commandRegistry.handleSortedSetRangeByScoreCommand,
+		"ZREVRANGEBYSCORE": commandRegistry.handleSortedSetReverseRangeByScoreCommand,
+		"ZCOUNT":           commandRegistry.handleSortedSetCountCommand,
+		"ZSCAN":            commandRegistry.handleSortedSetScanCommand,
+
+		// Commandes d'expiration
+		"TTL":         commandRegistry.handleTimeToLiveCommand,
+		"PTTL":        commandRegistry.handlePreciseTimeToLiveCommand,
+		"EXPIRETIME":  commandRegistry.handleExpireTimeCommand,
+		"PEXPIRETIME": commandRegistry.handlePreciseExpireTimeCommand,
+		"PERSIST":     commandRegistry.handlePersistCommand,
+
+		// Commandes de script
+		"EVAL":    commandRegistry.handleEvalCommand,
+		"EVALSHA": commandRegistry.handleEvalShaCommand,
+		"SCRIPT":  commandRegistry.handleScriptCommand,
 
 		// Commandes utilitaires
-		"PING":     commandRegistry.handlePingCommand,
-		"ECHO":     commandRegistry.handleEchoCommand,
-		"DBSIZE":   commandRegistry.handleDatabaseSizeCommand,
-		"FLUSHALL": commandRegistry.handleFlushAllCommand,
-		"ALAIDE":   commandRegistry.handleHelpCommand,
+		"PING":    commandRegistry.handlePingCommand,
+		"ECHO":    commandRegistry.handleEchoCommand,
+		"DBSIZE":  commandRegistry.handleDatabaseSizeCommand,
+		"FLUSHDB": commandRegistry.handleFlushDatabaseCommand,
+		"ALAIDE":  commandRegistry.handleHelpCommand,
+	}
+
+	// Commandes dont la forme propagée dépend de leur exécution
+	propagatingCommands := map[string]redisPropagatingCommandHandler{
+		"SET":          commandRegistry.handleSetCommand,
+		"SETEX":        commandRegistry.handleSetWithExpirationCommand,
+		"PSETEX":       commandRegistry.handlePreciseSetWithExpirationCommand,
+		"GETDEL":       commandRegistry.handleGetDeleteCommand,
+		"GETEX":        commandRegistry.handleGetExpirationCommand,
+		"EXPIRE":       commandRegistry.handleExpireCommand,
+		"PEXPIRE":      commandRegistry.handlePreciseExpireCommand,
+		"EXPIREAT":     commandRegistry.handleExpireAtCommand,
+		"PEXPIREAT":    commandRegistry.handlePreciseExpireAtCommand,
+		"INCRBYFLOAT":  commandRegistry.handleIncrementByFloatCommand,
+		"MSETNX":       commandRegistry.handleMultipleSetIfNotExistsCommand,
+		"SPOP":         commandRegistry.handleSetPopCommand,
+		"SMOVE":        commandRegistry.handleSetMoveCommand,
+		"HINCRBYFLOAT": commandRegistry.handleHashIncrementByFloatCommand,
+		"HEXPIRE":      commandRegistry.handleHashFieldExpireCommand,
+		"HPEXPIRE":     commandRegistry.handleHashFieldPreciseExpireCommand,
+		"HEXPIREAT":    commandRegistry.handleHashFieldExpireAtCommand,
+		"HPEXPIREAT":   commandRegistry.handleHashFieldPreciseExpireAtCommand,
+
+		// Commandes List bloquantes (sans blocage dans une transaction ou un script)
+		"BLPOP":      commandRegistry.handleBlockingLeftPopCommand,
+		"BRPOP":      commandRegistry.handleBlockingRightPopCommand,
+		"BLMOVE":     commandRegistry.handleBlockingMoveCommand,
+		"BRPOPLPUSH": commandRegistry.handleBlockingRightPopLeftPushCommand,
 	}
 
 	for commandName, handler := range commands {
+		commandRegistry.registeredCommands[commandName] = propagatedAsExecuted(commandName, handler)
+	}
+	for commandName, handler := range propagatingCommands {
 		commandRegistry.registeredCommands[commandName] = handler
 	}
 }
 
-// ExecuteCommand exécute une commande donnée
-func (commandRegistry *RedisCommandRegistry) ExecuteCommand(commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+// RegisterCommand enregistre une commande fournie par un autre composant (ex: commandes serveur)
+func (commandRegistry *RedisCommandRegistry) RegisterCommand(commandName string, commandHandler RedisCommandHandler) {
+	upperCommandName := strings.ToUpper(commandName)
+	commandRegistry.registeredCommands[upperCommandName] = propagatedAsExecuted(upperCommandName, commandHandler)
+}
+
+// GetAccessControlList retourne les utilisateurs ACL utilisés pour vérifier les permissions
+func (commandRegistry *RedisCommandRegistry) GetAccessControlList() *RedisAccessControlList {
+	return commandRegistry.accessControlList
+}
+
+// SetReadOnlyReplica active ou désactive le refus des écritures des clients (réplica en lecture seule)
+// Les commandes exécutées sans utilisateur (flux de réplication, rejeu de l'AOF) restent autorisées
+func (commandRegistry *RedisCommandRegistry) SetReadOnlyReplica(readOnlyReplica bool) {
+	commandRegistry.readOnlyReplica.Store(readOnlyReplica)
+}
+
+// checkCommandAuthorization vérifie les permissions ACL puis le mode lecture seule d'un réplica
+// Retourne un message d'erreur, ou une chaîne vide si la commande peut être exécutée
+func (commandRegistry *RedisCommandRegistry) checkCommandAuthorization(commandUser *RedisAclUser, upperCommandName string, commandArguments []string) string {
+	if permissionError := commandRegistry.accessControlList.CheckCommandPermission(commandUser, upperCommandName, commandArguments); permissionError != "" {
+		return permissionError
+	}
+	if commandUser != nil && isWriteCommand(upperCommandName) && commandRegistry.readOnlyReplica.Load() {
+		return "READONLY Vous ne pouvez pas écrire sur un réplica en lecture seule"
+	}
+	return ""
+}
+
+// isKnownCommand indique si une commande existe (registre ou commandes propres à la connexion)
+func (commandRegistry *RedisCommandRegistry) isKnownCommand(upperCommandName string) bool {
+	if _, commandExists := commandRegistry.registeredCommands[upperCommandName]; commandExists {
+		return true
+	}
+	for _, categoryCommands := range commandCategoryMembers {
+		if containsString(categoryCommands, upperCommandName) {
+			return true
+		}
+	}
+	return false
+}
+
+// AddWriteCommandListener ajoute un observateur des commandes d'écriture (AOF, réplication...)
+// Doit être appelé avant que le serveur n'accepte des clients
+func (commandRegistry *RedisCommandRegistry) AddWriteCommandListener(writeCommandListener RedisWriteCommandListener) {
+	commandRegistry.writeCommandListeners = append(commandRegistry.writeCommandListeners, writeCommandListener)
+}
+
+// RedisDatabaseCommand est une commande à exécuter sur une base donnée (transaction du flux de réplication)
+type RedisDatabaseCommand struct {
+	DatabaseStorage  *storage.RedisInMemoryStorage
+	CommandName      string
+	CommandArguments []string
+}
+
+// notifyWriteCommandListeners propage une écriture, ou la met de côté pendant une exécution atomique
+func (commandRegistry *RedisCommandRegistry) notifyWriteCommandListeners(databaseIndex int, commandName string, commandArguments []string) {
+	if commandRegistry.isPropagationDeferred {
+		commandRegistry.deferredPropagation = append(commandRegistry.deferredPropagation, deferredPropagatedCommand{
+			databaseIndex:     databaseIndex,
+			propagatedCommand: propagatedCommand{commandName: commandName, commandArguments: commandArguments},
+		})
+		return
+	}
+	for _, writeCommandListener := range commandRegistry.writeCommandListeners {
+		writeCommandListener(databaseIndex, commandName, commandArguments)
+	}
+}
+
+// executeWithAtomicPropagation exécute une transaction ou un script dont les écritures sont propagées ensemble à la fin,
+// dans un MULTI ... EXEC si elles sont plusieurs (comme Redis) : l'AOF et les réplicas les appliquent atomiquement
+// L'appelant doit détenir commandExecutionMutex en exclusif
+func (commandRegistry *RedisCommandRegistry) executeWithAtomicPropagation(atomicAction func() error) error {
+	commandRegistry.isPropagationDeferred = true
+	defer func() {
+		deferredPropagation := commandRegistry.deferredPropagation
+		commandRegistry.isPropagationDeferred = false
+		commandRegistry.deferredPropagation = nil
+
+		if len(deferredPropagation) > 1 {
+			commandRegistry.notifyWriteCommandListeners(deferredPropagation[0].databaseIndex, "MULTI", nil)
+		}
+		for _, deferredCommand := range deferredPropagation {
+			commandRegistry.notifyWriteCommandListeners(deferredCommand.databaseIndex, deferredCommand.commandName, deferredCommand.commandArguments)
+		}
+		if len(deferredPropagation) > 1 {
+			commandRegistry.notifyWriteCommandListeners(deferredPropagation[len(deferredPropagation)-1].databaseIndex, "EXEC", nil)
+		}
+	}()
+	return atomicAction()
+}
+
+// ExecuteReplicatedTransaction exécute sans qu'aucune autre commande ne s'intercale une transaction
+// reçue du primaire (MULTI ... EXEC), chaque commande sur sa base ; les réponses sont écrites dans protocolEncoder
+func (commandRegistry *RedisCommandRegistry) ExecuteReplicatedTransaction(transactionCommands []RedisDatabaseCommand, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	commandRegistry.commandExecutionMutex.Lock()
+	defer commandRegistry.commandExecutionMutex.Unlock()
+	defer commandRegistry.serveReadyListWaiters(commandRegistry.databaseStorages...)
+
+	return commandRegistry.executeWithAtomicPropagation(func() error {
+		for _, transactionCommand := range transactionCommands {
+			upperCommandName := strings.ToUpper(transactionCommand.CommandName)
+			commandHandler, commandExists := commandRegistry.registeredCommands[upperCommandName]
+			if !commandExists {
+				if writeError := protocolEncoder.WriteErrorResponse(commandRegistry.buildUnknownCommandMessage(transactionCommand.CommandName)); writeError != nil {
+					return writeError
+				}
+				continue
+			}
+			if executionError := commandRegistry.executeRegisteredCommand(upperCommandName, commandHandler, transactionCommand.CommandArguments, transactionCommand.DatabaseStorage, protocolEncoder); executionError != nil {
+				return executionError
+			}
+		}
+		return nil
+	})
+}
+
+// ExecuteWithExclusiveAccess exécute une action pendant qu'aucune commande n'est en cours
+func (commandRegistry *RedisCommandRegistry) ExecuteWithExclusiveAccess(exclusiveAction func()) {
+	commandRegistry.commandExecutionMutex.Lock()
+	defer commandRegistry.commandExecutionMutex.Unlock()
+	exclusiveAction()
+}
+
+// GetWriteCommandCount retourne le nombre de commandes d'écriture exécutées avec succès
+func (commandRegistry *RedisCommandRegistry) GetWriteCommandCount() int64 {
+	return commandRegistry.writeCommandCount.Load()
+}
+
+// GetKeyspaceLookupCounts retourne le nombre de clés trouvées et absentes lors des commandes de lecture
+func (commandRegistry *RedisCommandRegistry) GetKeyspaceLookupCounts() (int64, int64) {
+	return commandRegistry.keyspaceHitCount.Load(), commandRegistry.keyspaceMissCount.Load()
+}
+
+// ExecuteCommand exécute une commande donnée avec les permissions ACL de commandUser
+// commandUser vaut nil pour les commandes émises par le serveur lui-même (rejeu de l'AOF)
+func (commandRegistry *RedisCommandRegistry) ExecuteCommand(commandUser *RedisAclUser, commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
 	upperCommandName := strings.ToUpper(commandName)
 	commandHandler, commandExists := commandRegistry.registeredCommands[upperCommandName]
 
 	if !commandExists {
-		suggestion := commandRegistry.findSimilarCommand(upperCommandName)
-		if suggestion != "" {
-			return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : commande inconnue '%s'. Vouliez-vous dire '%s' ?", commandName, suggestion))
+		return protocolEncoder.WriteErrorResponse(commandRegistry.buildUnknownCommandMessage(commandName))
+	}
+	if authorizationError := commandRegistry.checkCommandAuthorization(commandUser, upperCommandName, commandArguments); authorizationError != "" {
+		return protocolEncoder.WriteErrorResponse(authorizationError)
+	}
+
+	// Les scripts et les commandes multi-bases s'exécutent de manière atomique : aucune autre commande ne s'intercale
+	if isExclusiveCommand(upperCommandName) {
+		commandRegistry.commandExecutionMutex.Lock()
+		defer commandRegistry.commandExecutionMutex.Unlock()
+		defer commandRegistry.serveReadyListWaiters(commandRegistry.databaseStorages...)
+		// Les commandes appelées par le script sont vérifiées avec les permissions de l'appelant
+		commandRegistry.scriptingEngine.activeUser = commandUser
+		defer func() { commandRegistry.scriptingEngine.activeUser = nil }()
+		return commandRegistry.executeWithAtomicPropagation(func() error {
+			return commandRegistry.executeRegisteredCommand(upperCommandName, commandHandler, commandArguments, redisStorage, protocolEncoder)
+		})
+	} else {
+		commandRegistry.commandExecutionMutex.RLock()
+		defer commandRegistry.commandExecutionMutex.RUnlock()
+		// Deux écritures concurrentes sur une base sont propagées dans l'ordre où elles ont été appliquées
+		if isWriteCommand(upperCommandName) {
+			defer redisStorage.LockWriteCommands()()
+			defer commandRegistry.serveReadyListWaiters(redisStorage)
 		}
-		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : commande inconnue '%s'", commandName))
 	}
 
-	return commandHandler(commandArguments, redisStorage, protocolEncoder)
+	return commandRegistry.executeRegisteredCommand(upperCommandName, commandHandler, commandArguments, redisStorage, protocolEncoder)
+}
+
+// ValidateCommandSyntax vérifie qu'une commande existe, reçoit un nombre d'arguments valide
+// et est autorisée pour commandUser
+// Retourne un message d'erreur vide si la commande peut être mise en file (MULTI)
+func (commandRegistry *RedisCommandRegistry) ValidateCommandSyntax(commandUser *RedisAclUser, commandName string, commandArguments []string) string {
+	upperCommandName := strings.ToUpper(commandName)
+	if _, commandExists := commandRegistry.registeredCommands[upperCommandName]; !commandExists {
+		return commandRegistry.buildUnknownCommandMessage(commandName)
+	}
+
+	if !hasValidArity(upperCommandName, len(commandArguments)) {
+		return fmt.Sprintf("ERREUR : nombre d'arguments incorrect pour '%s'", upperCommandName)
+	}
+	return commandRegistry.checkCommandAuthorization(commandUser, upperCommandName, commandArguments)
+}
+
+// IsRegisteredCommand indique si une commande est exécutée par le registre (ExecuteCommand)
+func (commandRegistry *RedisCommandRegistry) IsRegisteredCommand(commandName string) bool {
+	_, commandExists := commandRegistry.registeredCommands[strings.ToUpper(commandName)]
+	return commandExists
+}
+
+// ExecuteTransaction exécute les commandes d'une transaction sans qu'aucune autre commande ne s'intercale
+// canExecute est évalué sous le même verrou (WATCH) : s'il retourne false rien n'est exécuté ni écrit
+// Les permissions de commandUser sont vérifiées à nouveau pour chaque commande (ACL modifiées depuis MULTI)
+// Un SELECT mis en file est appliqué à son tour par selectDatabase, qui répond et retourne la base des commandes suivantes
+func (commandRegistry *RedisCommandRegistry) ExecuteTransaction(commandUser *RedisAclUser, queuedCommands []RedisQueuedCommand, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, canExecute func() bool, selectDatabase func(commandArguments []string) (*storage.RedisInMemoryStorage, error)) (bool, error) {
+	commandRegistry.commandExecutionMutex.Lock()
+	defer commandRegistry.commandExecutionMutex.Unlock()
+	commandRegistry.scriptingEngine.activeUser = commandUser
+	defer func() { commandRegistry.scriptingEngine.activeUser = nil }()
+
+	if !canExecute() {
+		return false, nil
+	}
+	defer commandRegistry.serveReadyListWaiters(commandRegistry.databaseStorages...)
+
+	if writeError := protocolEncoder.WriteArrayHeader(len(queuedCommands)); writeError != nil {
+		return true, writeError
+	}
+
+	// Une erreur d'exécution n'interrompt pas la transaction, elle devient la réponse de la commande
+	return true, commandRegistry.executeWithAtomicPropagation(func() error {
+		for _, queuedCommand := range queuedCommands {
+			upperCommandName := strings.ToUpper(queuedCommand.CommandName)
+			if authorizationError := commandRegistry.checkCommandAuthorization(commandUser, upperCommandName, queuedCommand.CommandArguments); authorizationError != "" {
+				if writeError := protocolEncoder.WriteErrorResponse(authorizationError); writeError != nil {
+					return writeError
+				}
+				continue
+			}
+			if upperCommandName == "SELECT" {
+				var selectError error
+				if redisStorage, selectError = selectDatabase(queuedCommand.CommandArguments); selectError != nil {
+					return selectError
+				}
+				continue
+			}
+			commandHandler := commandRegistry.registeredCommands[upperCommandName]
+			if executionError := commandRegistry.executeRegisteredCommand(upperCommandName, commandHandler, queuedCommand.CommandArguments, redisStorage, protocolEncoder); executionError != nil {
+				return executionError
+			}
+		}
+		return nil
+	})
+}
+
+// executeRegisteredCommand exécute le handler puis propage les commandes qu'il a retournées
+// L'appelant doit détenir commandExecutionMutex, exclusif ou partagé avec la réservation de la base (LockWriteCommands)
+func (commandRegistry *RedisCommandRegistry) executeRegisteredCommand(upperCommandName string, commandHandler redisPropagatingCommandHandler, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if !isWriteCommand(upperCommandName) && !isExclusiveCommand(upperCommandName) {
+		commandRegistry.recordKeyspaceLookups(upperCommandName, commandArguments, redisStorage)
+	}
+
+	errorCountBeforeExecution := protocolEncoder.GetWrittenErrorCount()
+	propagatedCommands, executionError := commandHandler(commandArguments, redisStorage, protocolEncoder)
+	if executionError != nil {
+		return executionError
+	}
+
+	// Propager les écritures réussies (politique de sauvegarde, AOF...)
+	// Une commande bloquante n'écrit que si elle a retiré un élément, propagé en LPOP, RPOP ou LMOVE
+	hasWritten := !writesOnlyWhenPropagated(upperCommandName) || len(propagatedCommands) > 0
+	if isWriteCommand(upperCommandName) && hasWritten && protocolEncoder.GetWrittenErrorCount() == errorCountBeforeExecution {
+		commandRegistry.writeCommandCount.Add(1)
+		for _, commandToPropagate := range propagatedCommands {
+			commandRegistry.notifyWriteCommandListeners(redisStorage.GetDatabaseIndex(), commandToPropagate.commandName, commandToPropagate.commandArguments)
+		}
+	}
+
+	return nil
+}
+
+// serveReadyListWaiters sert, une fois la commande terminée, les clients bloqués sur les listes qu'elle a remplies
+// et propage chaque retrait après elle (l'appelant détient les mêmes verrous que pour la commande)
+func (commandRegistry *RedisCommandRegistry) serveReadyListWaiters(databaseStorages ...*storage.RedisInMemoryStorage) {
+	for _, databaseStorage := range databaseStorages {
+		for _, servedElement := range databaseStorage.ServeReadyListWaiters() {
+			servedPop := propagateServedListElement(servedElement)
+			commandRegistry.writeCommandCount.Add(1)
+			commandRegistry.notifyWriteCommandListeners(databaseStorage.GetDatabaseIndex(), servedPop.commandName, servedPop.commandArguments)
+		}
+	}
+}
+
+// recordKeyspaceLookups compte les clés lues par une commande selon qu'elles existent ou non (comme Redis,
+// une clé existante est un hit même si le champ ou le membre demandé est absent)
+// Les commandes d'un script sont comptées individuellement, pas le script lui-même
+func (commandRegistry *RedisCommandRegistry) recordKeyspaceLookups(upperCommandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage) {
+	for _, commandKey := range extractCommandKeys(upperCommandName, commandArguments) {
+		if redisStorage.CheckKeyExists(commandKey) {
+			commandRegistry.keyspaceHitCount.Add(1)
+		} else {
+			commandRegistry.keyspaceMissCount.Add(1)
+		}
+	}
+}
+
+// buildUnknownCommandMessage construit le message d'erreur d'une commande inconnue avec suggestion
+func (commandRegistry *RedisCommandRegistry) buildUnknownCommandMessage(commandName string) string {
+	suggestion := commandRegistry.findSimilarCommand(strings.ToUpper(commandName))
+	if suggestion != "" {
+		return fmt.Sprintf("ERREUR : commande inconnue '%s'. Vouliez-vous dire '%s' ?", commandName, suggestion)
+	}
+	return fmt.Sprintf("ERREUR : commande inconnue '%s'", commandName)
 }
 
 // findSimilarCommand trouve la commande la plus similaire en utilisant la distance de Levenshtein
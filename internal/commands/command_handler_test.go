@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"bytes"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// commandTestStep est une commande envoyée au registre et sa réponse RESP attendue
+type commandTestStep struct {
+	commandArguments []string
+	expectedReply    string
+}
+
+// commandTestFixture exécute des commandes sur un registre neuf et enregistre les écritures propagées
+type commandTestFixture struct {
+	commandRegistry    *RedisCommandRegistry
+	databaseStorages   []*storage.RedisInMemoryStorage
+	propagatedCommands []string
+}
+
+// newCommandTestFixture crée un registre avec 16 bases vides ; chaque écriture propagée est notée "db COMMANDE args..."
+func newCommandTestFixture() *commandTestFixture {
+	databaseStorages := storage.NewRedisDatabaseStorages(16)
+	testFixture := &commandTestFixture{
+		commandRegistry:  NewRedisCommandRegistry(databaseStorages),
+		databaseStorages: databaseStorages,
+	}
+	testFixture.commandRegistry.AddWriteCommandListener(func(databaseIndex int, commandName string, commandArguments []string) {
+		propagatedCommand := strconv.Itoa(databaseIndex) + " " + strings.Join(append([]string{commandName}, commandArguments...), " ")
+		testFixture.propagatedCommands = append(testFixture.propagatedCommands, propagatedCommand)
+	})
+	return testFixture
+}
+
+// execute exécute une commande sur la base 0 et retourne sa réponse RESP brute
+func (testFixture *commandTestFixture) execute(t *testing.T, commandArguments ...string) string {
+	t.Helper()
+	return testFixture.executeOnDatabase(t, 0, commandArguments...)
+}
+
+// executeOnDatabase exécute une commande sur une base donnée et retourne sa réponse RESP brute
+func (testFixture *commandTestFixture) executeOnDatabase(t *testing.T, databaseIndex int, commandArguments ...string) string {
+	t.Helper()
+	return testFixture.executeAsUser(t, nil, databaseIndex, commandArguments...)
+}
+
+// executeAsUser exécute une commande avec les permissions d'un utilisateur ACL (nil pour le serveur)
+func (testFixture *commandTestFixture) executeAsUser(t *testing.T, aclUser *RedisAclUser, databaseIndex int, commandArguments ...string) string {
+	t.Helper()
+	var replyBuffer bytes.Buffer
+	protocolEncoder := protocol.NewRedisSerializationProtocolEncoder(&replyBuffer)
+	if executionError := testFixture.commandRegistry.ExecuteCommand(aclUser, commandArguments[0], commandArguments[1:], testFixture.databaseStorages[databaseIndex], protocolEncoder); executionError != nil {
+		t.Fatalf("%v: erreur d'exécution %v", commandArguments, executionError)
+	}
+	return replyBuffer.String()
+}
+
+// runSteps exécute les étapes dans l'ordre sur la base 0 et vérifie chaque réponse
+// Une réponse attendue se terminant par "*" n'est comparée que sur son préfixe (messages d'erreur)
+func (testFixture *commandTestFixture) runSteps(t *testing.T, testSteps []commandTestStep) {
+	t.Helper()
+	for _, testStep := range testSteps {
+		actualReply := testFixture.execute(t, testStep.commandArguments...)
+		if expectedPrefix, isPrefix := strings.CutSuffix(testStep.expectedReply, "*"); isPrefix {
+			if !strings.HasPrefix(actualReply, expectedPrefix) {
+				t.Fatalf("%v: réponse %q, attendu le préfixe %q", testStep.commandArguments, actualReply, expectedPrefix)
+			}
+			continue
+		}
+		if actualReply != testStep.expectedReply {
+			t.Fatalf("%v: réponse %q, attendu %q", testStep.commandArguments, actualReply, testStep.expectedReply)
+		}
+	}
+}
+
+// expectPropagated vérifie les écritures propagées depuis la création de la fixture ("db COMMANDE args...")
+func (testFixture *commandTestFixture) expectPropagated(t *testing.T, expectedCommands ...string) {
+	t.Helper()
+	if !slices.Equal(testFixture.propagatedCommands, expectedCommands) {
+		t.Fatalf("commandes propagées %q, attendu %q", testFixture.propagatedCommands, expectedCommands)
+	}
+}
+
+// step construit une étape à partir de la commande découpée sur les espaces
+func step(commandLine string, expectedReply string) commandTestStep {
+	return commandTestStep{commandArguments: strings.Fields(commandLine), expectedReply: expectedReply}
+}
+
+// bulk retourne l'encodage RESP d'une bulk string
+func bulk(bulkString string) string {
+	return "$" + strconv.Itoa(len(bulkString)) + "\r\n" + bulkString + "\r\n"
+}
+
+// array retourne l'encodage RESP d'un tableau de bulk strings
+func array(arrayElements ...string) string {
+	encodedArray := "*" + strconv.Itoa(len(arrayElements)) + "\r\n"
+	for _, arrayElement := range arrayElements {
+		encodedArray += bulk(arrayElement)
+	}
+	return encodedArray
+}
+
+func TestExecuteTransaction(t *testing.T) {
+	testCases := []struct {
+		name               string
+		queuedCommands     []string
+		expectedReply      string
+		expectedPropagated []string
+	}{
+		{
+			name:               "les écritures sont propagées entre MULTI et EXEC",
+			queuedCommands:     []string{"SET k v", "GET k", "RPUSH l a"},
+			expectedReply:      "*3\r\n+OK\r\n" + bulk("v") + ":1\r\n",
+			expectedPropagated: []string{"0 MULTI", "0 SET k v", "0 RPUSH l a", "0 EXEC"},
+		},
+		{
+			name:               "SELECT change la base des commandes suivantes et de leur propagation",
+			queuedCommands:     []string{"SET k base-0", "SELECT 3", "SET k base-3", "SELECT 99", "GET k"},
+			expectedReply:      "*5\r\n+OK\r\n+OK\r\n+OK\r\n-ERREUR : index de base invalide\r\n" + bulk("base-3"),
+			expectedPropagated: []string{"0 MULTI", "0 SET k base-0", "3 SET k base-3", "3 EXEC"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			var queuedCommands []RedisQueuedCommand
+			for _, queuedLine := range testCase.queuedCommands {
+				queuedFields := strings.Fields(queuedLine)
+				queuedCommands = append(queuedCommands, RedisQueuedCommand{CommandName: queuedFields[0], CommandArguments: queuedFields[1:]})
+			}
+
+			var replyBuffer bytes.Buffer
+			protocolEncoder := protocol.NewRedisSerializationProtocolEncoder(&replyBuffer)
+			selectedStorage := testFixture.databaseStorages[0]
+			transactionExecuted, executionError := testFixture.commandRegistry.ExecuteTransaction(nil, queuedCommands, selectedStorage, protocolEncoder,
+				func() bool { return true },
+				func(commandArguments []string) (*storage.RedisInMemoryStorage, error) {
+					databaseIndex, parseError := strconv.Atoi(commandArguments[0])
+					if parseError != nil || databaseIndex < 0 || databaseIndex >= len(testFixture.databaseStorages) {
+						return selectedStorage, protocolEncoder.WriteErrorResponse("ERREUR : index de base invalide")
+					}
+					selectedStorage = testFixture.databaseStorages[databaseIndex]
+					return selectedStorage, protocolEncoder.WriteSimpleStringResponse("OK")
+				})
+			if !transactionExecuted || executionError != nil {
+				t.Fatalf("transaction non exécutée (%v)", executionError)
+			}
+			if replyBuffer.String() != testCase.expectedReply {
+				t.Fatalf("réponse %q, attendu %q", replyBuffer.String(), testCase.expectedReply)
+			}
+			testFixture.expectPropagated(t, testCase.expectedPropagated...)
+		})
+	}
+}
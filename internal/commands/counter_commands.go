@@ -120,3 +120,28 @@ func (commandRegistry *RedisCommandRegistry) handleDecrementByCommand(commandArg
 	redisStorage.SetKeyValue(counterKey, strconv.FormatInt(currentCounterValue, 10), storage.RedisStringType, nil)
 	return protocolEncoder.WriteIntegerResponse(currentCounterValue)
 }
+
+// handleIncrementByFloatCommand implémente INCRBYFLOAT key increment
+// La nouvelle valeur est retournée sous forme de chaîne, formatée comme Redis (sans exposant ni zéros superflus),
+// et propagée en SET ... KEEPTTL pour que l'AOF et les réplicas ne dépendent pas des arrondis de l'addition
+func (commandRegistry *RedisCommandRegistry) handleIncrementByFloatCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	floatIncrement, parseError := storage.ParseFloatValue(commandArguments[1])
+	if parseError != nil {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : l'incrément n'est pas un nombre décimal valide")
+	}
+
+	incrementedValue, incrementError := redisStorage.IncrementStringFloat(commandArguments[0], floatIncrement)
+	switch incrementError {
+	case nil:
+		incrementPropagation := propagatedCommand{commandName: "SET", commandArguments: []string{commandArguments[0], incrementedValue, "KEEPTTL"}}
+		return []propagatedCommand{incrementPropagation}, protocolEncoder.WriteBulkStringResponse(incrementedValue)
+	case storage.ErrWrongDataType:
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une chaîne de caractères")
+	case storage.ErrValueNotFloat:
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : la valeur n'est pas un nombre décimal valide")
+	case storage.ErrIncrementNotFinite:
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : l'incrément produirait NaN ou l'infini")
+	default:
+		return nil, incrementError
+	}
+}
@@ -0,0 +1,64 @@
+package commands
+
+import "testing"
+
+func TestIncrementByFloatCommand(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "résultat formaté sans exposant ni zéros superflus",
+			testSteps: []commandTestStep{
+				step("SET k 10.50", "+OK\r\n"),
+				step("INCRBYFLOAT k 0.1", bulk("10.6")),
+				step("INCRBYFLOAT k -5", bulk("5.6")),
+				step("SET k 5.0e3", "+OK\r\n"),
+				step("INCRBYFLOAT k 2.0e2", bulk("5200")),
+				step("INCRBYFLOAT nouveau 1e20", bulk("100000000000000000000")),
+				step("INCRBYFLOAT somme 0.1", bulk("0.1")),
+				step("INCRBYFLOAT somme 0.2", bulk("0.3")),
+				step("SET n 3", "+OK\r\n"),
+				step("INCRBYFLOAT n 2", bulk("5")),
+				step("INCRBY n 1", ":6\r\n"),
+			},
+		},
+		{
+			name: "propagé en SET KEEPTTL de la valeur obtenue",
+			testSteps: []commandTestStep{
+				step("SET k 1.5 PXAT 4102444800000", "+OK\r\n"),
+				step("INCRBYFLOAT k 1", bulk("2.5")),
+				step("PEXPIRETIME k", ":4102444800000\r\n"),
+				step("GET k", bulk("2.5")),
+				step("INCRBYFLOAT k abc", "-ERREUR : l'incrément n'est pas un nombre décimal valide\r\n"),
+			},
+			expectedPropagated: []string{"0 SET k 1.5 PXAT 4102444800000", "0 SET k 2.5 KEEPTTL"},
+		},
+		{
+			name: "valeurs et incréments refusés",
+			testSteps: []commandTestStep{
+				step("SET texte abc", "+OK\r\n"),
+				step("SET grand 1e4932", "+OK\r\n"),
+				step("RPUSH liste a", ":1\r\n"),
+				step("INCRBYFLOAT texte 1", "-ERREUR : la valeur n'est pas un nombre décimal valide\r\n"),
+				step("INCRBYFLOAT k nan", "-ERREUR : l'incrément n'est pas un nombre décimal valide\r\n"),
+				step("INCRBYFLOAT k inf", "-ERREUR : l'incrément n'est pas un nombre décimal valide\r\n"),
+				step("INCRBYFLOAT grand 1e4932", "-ERREUR : l'incrément produirait NaN ou l'infini\r\n"),
+				step("INCRBYFLOAT liste 1", "-ERREUR : cette clé ne contient pas une chaîne de caractères\r\n"),
+				step("EXISTS k", ":0\r\n"),
+				step("GET texte", bulk("abc")),
+			},
+			expectedPropagated: []string{"0 SET texte abc", "0 SET grand 1e4932", "0 RPUSH liste a"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+		})
+	}
+}
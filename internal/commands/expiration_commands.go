@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// handleExpireCommand implémente EXPIRE key seconds [NX|XX|GT|LT]
+func (commandRegistry *RedisCommandRegistry) handleExpireCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return commandRegistry.applyKeyExpiration("EXPIRE", commandArguments, redisStorage, protocolEncoder, func(expirationValue int64) (time.Time, bool) {
+		return relativeExpirationTime(expirationValue, 1000)
+	})
+}
+
+// handlePreciseExpireCommand implémente PEXPIRE key milliseconds [NX|XX|GT|LT]
+func (commandRegistry *RedisCommandRegistry) handlePreciseExpireCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return commandRegistry.applyKeyExpiration("PEXPIRE", commandArguments, redisStorage, protocolEncoder, func(expirationValue int64) (time.Time, bool) {
+		return relativeExpirationTime(expirationValue, 1)
+	})
+}
+
+// handleExpireAtCommand implémente EXPIREAT key unix-time-seconds [NX|XX|GT|LT]
+func (commandRegistry *RedisCommandRegistry) handleExpireAtCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return commandRegistry.applyKeyExpiration("EXPIREAT", commandArguments, redisStorage, protocolEncoder, func(expirationValue int64) (time.Time, bool) {
+		return absoluteExpirationTime(expirationValue, 1000)
+	})
+}
+
+// handlePreciseExpireAtCommand implémente PEXPIREAT key unix-time-milliseconds [NX|XX|GT|LT]
+func (commandRegistry *RedisCommandRegistry) handlePreciseExpireAtCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return commandRegistry.applyKeyExpiration("PEXPIREAT", commandArguments, redisStorage, protocolEncoder, func(expirationValue int64) (time.Time, bool) {
+		return absoluteExpirationTime(expirationValue, 1)
+	})
+}
+
+// absoluteExpirationTime convertit une date en secondes ou millisecondes depuis l'epoch (millisecondsPerUnit vaut 1000 ou 1)
+// Retourne false si la date en millisecondes ne tient pas dans un int64, limite au-delà de laquelle Redis la refuse
+func absoluteExpirationTime(expirationValue int64, millisecondsPerUnit int64) (time.Time, bool) {
+	if expirationValue > math.MaxInt64/millisecondsPerUnit || expirationValue < math.MinInt64/millisecondsPerUnit {
+		return time.Time{}, false
+	}
+	return time.UnixMilli(expirationValue * millisecondsPerUnit), true
+}
+
+// relativeExpirationTime ajoute un délai en secondes ou millisecondes à l'heure courante
+// Le calcul ne passe pas par un time.Duration, limité à 292 ans, et retourne false si la date
+// obtenue en millisecondes depuis l'epoch ne tient pas dans un int64
+func relativeExpirationTime(expirationValue int64, millisecondsPerUnit int64) (time.Time, bool) {
+	if expirationValue > math.MaxInt64/millisecondsPerUnit || expirationValue < math.MinInt64/millisecondsPerUnit {
+		return time.Time{}, false
+	}
+	expirationMilliseconds := expirationValue * millisecondsPerUnit
+	currentTime := time.Now()
+	if expirationMilliseconds > math.MaxInt64-currentTime.UnixMilli() {
+		return time.Time{}, false
+	}
+	return time.Unix(currentTime.Unix()+expirationMilliseconds/1000, int64(currentTime.Nanosecond())+expirationMilliseconds%1000*int64(time.Millisecond)), true
+}
+
+// applyKeyExpiration factorise la famille EXPIRE : parsing, conditions puis application
+// L'expiration appliquée est propagée en PEXPIREAT absolu (ou DEL si elle a supprimé la clé)
+// pour que le rejeu ne dépende pas de l'heure à laquelle il a lieu
+func (commandRegistry *RedisCommandRegistry) applyKeyExpiration(commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, computeExpirationTime func(int64) (time.Time, bool)) ([]propagatedCommand, error) {
+	if len(commandArguments) < 2 {
+		return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : nombre d'arguments incorrect pour '%s' (attendu: %s clé valeur [NX|XX|GT|LT])", commandName, commandName))
+	}
+
+	expirationValue, parseError := strconv.ParseInt(commandArguments[1], 10, 64)
+	if parseError != nil {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : la valeur d'expiration doit être un nombre entier")
+	}
+
+	var expirationOptions storage.KeyExpirationOptions
+	for _, expirationOption := range commandArguments[2:] {
+		switch strings.ToUpper(expirationOption) {
+		case "NX":
+			expirationOptions.OnlyIfNoExpiration = true
+		case "XX":
+			expirationOptions.OnlyIfHasExpiration = true
+		case "GT":
+			expirationOptions.OnlyIfGreater = true
+		case "LT":
+			expirationOptions.OnlyIfLess = true
+		default:
+			return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : option inconnue '%s' pour %s", expirationOption, commandName))
+		}
+	}
+
+	if expirationOptions.OnlyIfNoExpiration && (expirationOptions.OnlyIfHasExpiration || expirationOptions.OnlyIfGreater || expirationOptions.OnlyIfLess) {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : NX et les options XX, GT ou LT sont incompatibles")
+	}
+	if expirationOptions.OnlyIfGreater && expirationOptions.OnlyIfLess {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : les options GT et LT sont incompatibles")
+	}
+
+	expirationTime, expirationValid := computeExpirationTime(expirationValue)
+	if !expirationValid {
+		return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : valeur d'expiration invalide pour '%s'", commandName))
+	}
+	expirationApplied, keyDeleted := redisStorage.SetKeyExpiration(commandArguments[0], expirationTime, expirationOptions)
+	if !expirationApplied {
+		return nil, protocolEncoder.WriteIntegerResponse(0)
+	}
+	return propagateKeyExpiration(commandArguments[0], expirationTime, keyDeleted), protocolEncoder.WriteIntegerResponse(1)
+}
+
+// propagateKeyExpiration retourne la forme propagée d'une expiration appliquée à une clé :
+// PEXPIREAT avec la date absolue, ou DEL si la date était passée et a supprimé la clé
+func propagateKeyExpiration(storageKey string, expirationTime time.Time, keyDeleted bool) []propagatedCommand {
+	if keyDeleted {
+		return []propagatedCommand{{commandName: "DEL", commandArguments: []string{storageKey}}}
+	}
+	return []propagatedCommand{{commandName: "PEXPIREAT", commandArguments: []string{storageKey, strconv.FormatInt(expirationTime.UnixMilli(), 10)}}}
+}
+
+// handleTimeToLiveCommand implémente TTL key
+func (commandRegistry *RedisCommandRegistry) handleTimeToLiveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeKeyExpirationInfo("TTL", commandArguments, redisStorage, protocolEncoder, func(expirationTime time.Time) int64 {
+		// Arrondi à la seconde supérieure comme Redis
+		return (remainingMilliseconds(expirationTime) + 500) / 1000
+	})
+}
+
+// handlePreciseTimeToLiveCommand implémente PTTL key
+func (commandRegistry *RedisCommandRegistry) handlePreciseTimeToLiveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeKeyExpirationInfo("PTTL", commandArguments, redisStorage, protocolEncoder, func(expirationTime time.Time) int64 {
+		return remainingMilliseconds(expirationTime)
+	})
+}
+
+// remainingMilliseconds retourne le temps restant avant une expiration, calculé en millisecondes
+// depuis l'epoch car time.Until plafonne à 292 ans
+func remainingMilliseconds(expirationTime time.Time) int64 {
+	return expirationTime.UnixMilli() - time.Now().UnixMilli()
+}
+
+// handleExpireTimeCommand implémente EXPIRETIME key
+func (commandRegistry *RedisCommandRegistry) handleExpireTimeCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeKeyExpirationInfo("EXPIRETIME", commandArguments, redisStorage, protocolEncoder, func(expirationTime time.Time) int64 {
+		return expirationTime.Unix()
+	})
+}
+
+// handlePreciseExpireTimeCommand implémente PEXPIRETIME key
+func (commandRegistry *RedisCommandRegistry) handlePreciseExpireTimeCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeKeyExpirationInfo("PEXPIRETIME", commandArguments, redisStorage, protocolEncoder, func(expirationTime time.Time) int64 {
+		return expirationTime.UnixMilli()
+	})
+}
+
+// writeKeyExpirationInfo factorise TTL/PTTL/EXPIRETIME/PEXPIRETIME (-2 = clé absente, -1 = pas d'expiration)
+func writeKeyExpirationInfo(commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, formatExpiration func(time.Time) int64) error {
+	if len(commandArguments) != 1 {
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : nombre d'arguments incorrect pour '%s' (attendu: %s clé)", commandName, commandName))
+	}
+
+	expirationTime, keyExists := redisStorage.GetKeyExpiration(commandArguments[0])
+	if !keyExists {
+		return protocolEncoder.WriteIntegerResponse(-2)
+	}
+	if expirationTime == nil {
+		return protocolEncoder.WriteIntegerResponse(-1)
+	}
+
+	return protocolEncoder.WriteIntegerResponse(formatExpiration(*expirationTime))
+}
+
+// handlePersistCommand implémente PERSIST key
+func (commandRegistry *RedisCommandRegistry) handlePersistCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 1 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'PERSIST' (attendu: PERSIST clé)")
+	}
+
+	if redisStorage.PersistKey(commandArguments[0]) {
+		return protocolEncoder.WriteIntegerResponse(1)
+	}
+	return protocolEncoder.WriteIntegerResponse(0)
+}
@@ -0,0 +1,130 @@
+package commands
+
+import "testing"
+
+func TestKeyExpirationCommands(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "EXPIRE TTL PERSIST sur chaque type",
+			testSteps: []commandTestStep{
+				step("RPUSH l a", ":1\r\n"),
+				step("SADD s a", ":1\r\n"),
+				step("HSET h f v", ":1\r\n"),
+				step("ZADD z 1 a", ":1\r\n"),
+				step("TTL l", ":-1\r\n"),
+				step("TTL absent", ":-2\r\n"),
+				step("EXPIRE l 100", ":1\r\n"),
+				step("EXPIRE s 100", ":1\r\n"),
+				step("EXPIRE h 100", ":1\r\n"),
+				step("EXPIRE z 100", ":1\r\n"),
+				step("TTL l", ":100\r\n"),
+				step("TTL z", ":100\r\n"),
+				step("PERSIST h", ":1\r\n"),
+				step("PERSIST h", ":0\r\n"),
+				step("TTL h", ":-1\r\n"),
+				step("EXPIRE absent 100", ":0\r\n"),
+				step("PERSIST absent", ":0\r\n"),
+			},
+		},
+		{
+			name: "dates absolues et propagation en PEXPIREAT",
+			testSteps: []commandTestStep{
+				step("SET k v", "+OK\r\n"),
+				step("EXPIREAT k 4102444800", ":1\r\n"),
+				step("EXPIRETIME k", ":4102444800\r\n"),
+				step("PEXPIRETIME k", ":4102444800000\r\n"),
+				step("PEXPIREAT k 4102444800123", ":1\r\n"),
+				step("PEXPIRETIME k", ":4102444800123\r\n"),
+				step("EXPIRETIME k", ":4102444800\r\n"),
+				step("EXPIRETIME absent", ":-2\r\n"),
+			},
+			expectedPropagated: []string{"0 SET k v", "0 PEXPIREAT k 4102444800000", "0 PEXPIREAT k 4102444800123"},
+		},
+		{
+			name: "une date passée supprime la clé et se propage en DEL",
+			testSteps: []commandTestStep{
+				step("SET k v", "+OK\r\n"),
+				step("EXPIRE k -1", ":1\r\n"),
+				step("EXISTS k", ":0\r\n"),
+				step("SET k v", "+OK\r\n"),
+				step("PEXPIREAT k 1000", ":1\r\n"),
+				step("TTL k", ":-2\r\n"),
+			},
+			expectedPropagated: []string{"0 SET k v", "0 DEL k", "0 SET k v", "0 DEL k"},
+		},
+		{
+			name: "conditions NX XX GT LT",
+			testSteps: []commandTestStep{
+				step("SET k v", "+OK\r\n"),
+				step("EXPIRE k 100 XX", ":0\r\n"),
+				step("EXPIRE k 100 GT", ":0\r\n"),
+				step("EXPIRE k 100 NX", ":1\r\n"),
+				step("EXPIRE k 200 NX", ":0\r\n"),
+				step("EXPIRE k 50 GT", ":0\r\n"),
+				step("EXPIRE k 200 GT", ":1\r\n"),
+				step("EXPIRE k 300 LT", ":0\r\n"),
+				step("EXPIRE k 150 LT XX", ":1\r\n"),
+				step("TTL k", ":150\r\n"),
+				step("PERSIST k", ":1\r\n"),
+				step("EXPIRE k 100 LT", ":1\r\n"),
+				step("EXPIRE k 100 NX XX", "-ERREUR : NX et les options XX, GT ou LT sont incompatibles\r\n"),
+				step("EXPIRE k 100 GT LT", "-ERREUR : les options GT et LT sont incompatibles\r\n"),
+				step("EXPIRE k 100 KEEPTTL", "-ERREUR : option inconnue 'KEEPTTL' pour EXPIRE\r\n"),
+				step("EXPIRE k dix", "-ERREUR : la valeur d'expiration doit être un nombre entier\r\n"),
+			},
+		},
+		{
+			name: "valeurs hors de la plage représentable refusées sans propagation",
+			testSteps: []commandTestStep{
+				step("SET k v", "+OK\r\n"),
+				step("EXPIRE k 9223372036854775807", "-ERREUR : valeur d'expiration invalide pour 'EXPIRE'\r\n"),
+				step("EXPIRE k 9223372036854776", "-ERREUR : valeur d'expiration invalide pour 'EXPIRE'\r\n"),
+				step("EXPIRE k -9223372036854776", "-ERREUR : valeur d'expiration invalide pour 'EXPIRE'\r\n"),
+				step("PEXPIRE k 9223372036854775807", "-ERREUR : valeur d'expiration invalide pour 'PEXPIRE'\r\n"),
+				step("EXPIREAT k 9223372036854776", "-ERREUR : valeur d'expiration invalide pour 'EXPIREAT'\r\n"),
+				step("TTL k", ":-1\r\n"),
+				step("EXPIREAT k 9223372036854775", ":1\r\n"),
+				step("EXPIRETIME k", ":9223372036854775\r\n"),
+				step("PEXPIREAT k 9223372036854775807", ":1\r\n"),
+				step("PEXPIRETIME k", ":9223372036854775807\r\n"),
+				step("GET k", bulk("v")),
+			},
+			expectedPropagated: []string{"0 SET k v", "0 PEXPIREAT k 9223372036854775000", "0 PEXPIREAT k 9223372036854775807"},
+		},
+		{
+			name: "délai relatif au-delà de 292 ans",
+			testSteps: []commandTestStep{
+				step("SET k v", "+OK\r\n"),
+				step("EXPIRE k 10000000000", ":1\r\n"),
+				step("TTL k", ":10000000000\r\n"),
+				step("PEXPIRE k 10000000000000", ":1\r\n"),
+				step("TTL k", ":10000000000\r\n"),
+				step("GET k", bulk("v")),
+			},
+		},
+		{
+			name: "une clé expirée disparaît pour toutes les commandes",
+			testSteps: []commandTestStep{
+				step("RPUSH l a", ":1\r\n"),
+				step("PEXPIREAT l 4102444800000", ":1\r\n"),
+				step("PEXPIREAT l 1", ":1\r\n"),
+				step("LLEN l", ":0\r\n"),
+				step("TYPE l", "+none\r\n"),
+				step("PTTL l", ":-2\r\n"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+		})
+	}
+}
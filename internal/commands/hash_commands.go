@@ -1,6 +1,9 @@
 package commands
 
 import (
+	"strconv"
+	"strings"
+
 	"redis-go/internal/protocol"
 	"redis-go/internal/storage"
 )
@@ -39,7 +42,7 @@ func (commandRegistry *RedisCommandRegistry) handleHashGetCommand(commandArgumen
 
 	fieldValue, fieldExists := redisStorage.GetHashField(hashKey, fieldName)
 	if !fieldExists {
-		return protocolEncoder.WriteBulkStringResponse("(nil)")
+		return protocolEncoder.WriteNullBulkStringResponse()
 	}
 
 	return protocolEncoder.WriteBulkStringResponse(fieldValue)
@@ -57,11 +60,211 @@ func (commandRegistry *RedisCommandRegistry) handleHashGetAllCommand(commandArgu
 		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un hash")
 	}
 
-	// Convertir en array alternant field/value
+	// Map field/value en RESP3, array alternant field/value en RESP2
 	responseArray := make([]string, 0, len(hashFields)*2)
 	for fieldName, fieldValue := range hashFields {
 		responseArray = append(responseArray, fieldName, fieldValue)
 	}
 
+	return protocolEncoder.WriteMapResponse(responseArray)
+}
+
+// handleHashDeleteCommand implémente HDEL key field [field ...] (la clé est supprimée avec son dernier field)
+func (commandRegistry *RedisCommandRegistry) handleHashDeleteCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	deletedFieldCount, deleteError := redisStorage.DeleteHashFields(commandArguments[0], commandArguments[1:])
+	if deleteError != nil {
+		return writeHashStorageError(protocolEncoder, deleteError)
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(deletedFieldCount))
+}
+
+// handleHashExistsCommand implémente HEXISTS key field (1 si le field existe, 0 sinon)
+func (commandRegistry *RedisCommandRegistry) handleHashExistsCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	_, fieldsFound, lookupError := redisStorage.GetHashFieldValues(commandArguments[0], commandArguments[1:])
+	if lookupError != nil {
+		return writeHashStorageError(protocolEncoder, lookupError)
+	}
+	if fieldsFound[0] {
+		return protocolEncoder.WriteIntegerResponse(1)
+	}
+	return protocolEncoder.WriteIntegerResponse(0)
+}
+
+// handleHashLengthCommand implémente HLEN key
+func (commandRegistry *RedisCommandRegistry) handleHashLengthCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	hashLength, lengthError := redisStorage.GetHashLength(commandArguments[0])
+	if lengthError != nil {
+		return writeHashStorageError(protocolEncoder, lengthError)
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(hashLength))
+}
+
+// handleHashKeysCommand implémente HKEYS key
+func (commandRegistry *RedisCommandRegistry) handleHashKeysCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	hashFields := redisStorage.GetAllHashFields(commandArguments[0])
+	if hashFields == nil {
+		return writeHashStorageError(protocolEncoder, storage.ErrWrongDataType)
+	}
+
+	fieldNames := make([]string, 0, len(hashFields))
+	for fieldName := range hashFields {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	return protocolEncoder.WriteArrayResponse(fieldNames)
+}
+
+// handleHashValuesCommand implémente HVALS key
+func (commandRegistry *RedisCommandRegistry) handleHashValuesCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	hashFields := redisStorage.GetAllHashFields(commandArguments[0])
+	if hashFields == nil {
+		return writeHashStorageError(protocolEncoder, storage.ErrWrongDataType)
+	}
+
+	fieldValues := make([]string, 0, len(hashFields))
+	for _, fieldValue := range hashFields {
+		fieldValues = append(fieldValues, fieldValue)
+	}
+	return protocolEncoder.WriteArrayResponse(fieldValues)
+}
+
+// handleHashMultipleGetCommand implémente HMGET key field [field ...] (null pour chaque field absent)
+func (commandRegistry *RedisCommandRegistry) handleHashMultipleGetCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	fieldValues, fieldsFound, lookupError := redisStorage.GetHashFieldValues(commandArguments[0], commandArguments[1:])
+	if lookupError != nil {
+		return writeHashStorageError(protocolEncoder, lookupError)
+	}
+
+	protocolEncoder.WriteArrayHeader(len(fieldValues))
+	for fieldIndex, fieldValue := range fieldValues {
+		if fieldsFound[fieldIndex] {
+			protocolEncoder.WriteBulkStringResponse(fieldValue)
+		} else {
+			protocolEncoder.WriteNullBulkStringResponse()
+		}
+	}
+	return nil
+}
+
+// handleHashIncrementByCommand implémente HINCRBY key field increment
+func (commandRegistry *RedisCommandRegistry) handleHashIncrementByCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	integerIncrement, parseError := strconv.ParseInt(commandArguments[2], 10, 64)
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'incrément n'est pas un nombre entier ou est hors limites")
+	}
+
+	incrementedValue, incrementError := redisStorage.IncrementHashFieldInteger(commandArguments[0], commandArguments[1], integerIncrement)
+	if incrementError != nil {
+		return writeHashStorageError(protocolEncoder, incrementError)
+	}
+	return protocolEncoder.WriteIntegerResponse(incrementedValue)
+}
+
+// handleHashIncrementByFloatCommand implémente HINCRBYFLOAT key field increment
+// La nouvelle valeur est retournée sous forme de chaîne, comme Redis
+// La valeur obtenue est propagée en HSET, pour que l'AOF et les réplicas ne dépendent pas des arrondis de l'addition ;
+// un field ayant un TTL garde HINCRBYFLOAT, HSET retirant le TTL
+func (commandRegistry *RedisCommandRegistry) handleHashIncrementByFloatCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	floatIncrement, parseError := storage.ParseFloatValue(commandArguments[2])
+	if parseError != nil {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : l'incrément n'est pas un nombre décimal valide")
+	}
+
+	incrementedValue, fieldHasExpiration, incrementError := redisStorage.IncrementHashFieldFloat(commandArguments[0], commandArguments[1], floatIncrement)
+	if incrementError != nil {
+		return nil, writeHashStorageError(protocolEncoder, incrementError)
+	}
+
+	incrementPropagation := propagatedCommand{commandName: "HSET", commandArguments: []string{commandArguments[0], commandArguments[1], incrementedValue}}
+	if fieldHasExpiration {
+		incrementPropagation = propagatedCommand{commandName: "HINCRBYFLOAT", commandArguments: commandArguments}
+	}
+	return []propagatedCommand{incrementPropagation}, protocolEncoder.WriteBulkStringResponse(incrementedValue)
+}
+
+// handleHashSetIfNotExistsCommand implémente HSETNX key field value (1 si le field a été créé, 0 s'il existait)
+func (commandRegistry *RedisCommandRegistry) handleHashSetIfNotExistsCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	fieldCreated, setError := redisStorage.SetHashFieldIfNotExists(commandArguments[0], commandArguments[1], commandArguments[2])
+	if setError != nil {
+		return writeHashStorageError(protocolEncoder, setError)
+	}
+	if fieldCreated {
+		return protocolEncoder.WriteIntegerResponse(1)
+	}
+	return protocolEncoder.WriteIntegerResponse(0)
+}
+
+// handleHashStringLengthCommand implémente HSTRLEN key field (0 si le field n'existe pas)
+func (commandRegistry *RedisCommandRegistry) handleHashStringLengthCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	fieldValues, _, lookupError := redisStorage.GetHashFieldValues(commandArguments[0], commandArguments[1:])
+	if lookupError != nil {
+		return writeHashStorageError(protocolEncoder, lookupError)
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(len(fieldValues[0])))
+}
+
+// handleHashRandomFieldCommand implémente HRANDFIELD key [count [WITHVALUES]] sans modifier le hash
+// count positif : au plus count fields distincts ; count négatif : |count| fields pouvant se répéter
+func (commandRegistry *RedisCommandRegistry) handleHashRandomFieldCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	withValues := len(commandArguments) == 3 && strings.EqualFold(commandArguments[2], "WITHVALUES")
+	if len(commandArguments) > 3 || (len(commandArguments) == 3 && !withValues) {
+		return protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe (attendu: HRANDFIELD clé [nombre [WITHVALUES]])")
+	}
+
+	fieldCount, allowRepetitions := 1, false
+	if len(commandArguments) >= 2 {
+		parsedCount, parseError := strconv.Atoi(commandArguments[1])
+		if parseError != nil {
+			return protocolEncoder.WriteErrorResponse("ERREUR : le nombre de champs doit être un nombre entier")
+		}
+		fieldCount, allowRepetitions = parsedCount, parsedCount < 0
+		if allowRepetitions {
+			fieldCount = -parsedCount
+		}
+	}
+
+	randomFields, randomValues, randomError := redisStorage.GetRandomHashFields(commandArguments[0], fieldCount, allowRepetitions)
+	if randomError != nil {
+		return writeHashStorageError(protocolEncoder, randomError)
+	}
+	if len(commandArguments) == 1 {
+		if len(randomFields) == 0 {
+			return protocolEncoder.WriteNullBulkStringResponse()
+		}
+		return protocolEncoder.WriteBulkStringResponse(randomFields[0])
+	}
+	if !withValues {
+		return protocolEncoder.WriteArrayResponse(randomFields)
+	}
+
+	// Paires [field, valeur] en RESP3, array alternant field/valeur en RESP2
+	if protocolEncoder.IsResp3() {
+		protocolEncoder.WriteArrayHeader(len(randomFields))
+		for fieldIndex, randomField := range randomFields {
+			protocolEncoder.WriteArrayResponse([]string{randomField, randomValues[fieldIndex]})
+		}
+		return nil
+	}
+	responseArray := make([]string, 0, len(randomFields)*2)
+	for fieldIndex, randomField := range randomFields {
+		responseArray = append(responseArray, randomField, randomValues[fieldIndex])
+	}
 	return protocolEncoder.WriteArrayResponse(responseArray)
 }
+
+// writeHashStorageError traduit les erreurs de stockage des hashes en réponse RESP
+func writeHashStorageError(protocolEncoder *protocol.RedisSerializationProtocolEncoder, storageError error) error {
+	switch storageError {
+	case storage.ErrWrongDataType:
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un hash")
+	case storage.ErrValueNotInteger:
+		return protocolEncoder.WriteErrorResponse("ERREUR : la valeur du champ n'est pas un nombre entier")
+	case storage.ErrValueNotFloat:
+		return protocolEncoder.WriteErrorResponse("ERREUR : la valeur du champ n'est pas un nombre décimal valide")
+	case storage.ErrIncrementOverflow:
+		return protocolEncoder.WriteErrorResponse("ERREUR : incrément ou décrément hors limites")
+	case storage.ErrIncrementNotFinite:
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'incrément produirait NaN ou l'infini")
+	default:
+		return storageError
+	}
+}
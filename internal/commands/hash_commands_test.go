@@ -0,0 +1,125 @@
+package commands
+
+import "testing"
+
+func TestHashCommands(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "HDEL supprime la clé avec son dernier field",
+			testSteps: []commandTestStep{
+				step("HSET h nom alice age 30", ":2\r\n"),
+				step("HDEL h age inconnu", ":1\r\n"),
+				step("HLEN h", ":1\r\n"),
+				step("HDEL absente nom", ":0\r\n"),
+				step("HDEL h nom", ":1\r\n"),
+				step("EXISTS h", ":0\r\n"),
+				step("HLEN h", ":0\r\n"),
+			},
+			expectedPropagated: []string{"0 HSET h nom alice age 30", "0 HDEL h age inconnu", "0 HDEL absente nom", "0 HDEL h nom"},
+		},
+		{
+			name: "lectures partielles",
+			testSteps: []commandTestStep{
+				step("HSET h nom alice", ":1\r\n"),
+				step("HEXISTS h nom", ":1\r\n"),
+				step("HEXISTS h age", ":0\r\n"),
+				step("HEXISTS absente nom", ":0\r\n"),
+				step("HMGET h nom age", "*2\r\n"+bulk("alice")+"$-1\r\n"),
+				step("HMGET absente nom", "*1\r\n$-1\r\n"),
+				step("HKEYS h", array("nom")),
+				step("HVALS h", array("alice")),
+				step("HKEYS absente", "*0\r\n"),
+				step("HSTRLEN h nom", ":5\r\n"),
+				step("HSTRLEN h age", ":0\r\n"),
+			},
+		},
+		{
+			name: "HSETNX ne remplace pas un field existant",
+			testSteps: []commandTestStep{
+				step("HSETNX h nom alice", ":1\r\n"),
+				step("HSETNX h nom bob", ":0\r\n"),
+				step("HMGET h nom", array("alice")),
+			},
+			expectedPropagated: []string{"0 HSETNX h nom alice", "0 HSETNX h nom bob"},
+		},
+		{
+			name: "compteurs entiers avec HINCRBY",
+			testSteps: []commandTestStep{
+				step("HINCRBY h visites 5", ":5\r\n"),
+				step("HINCRBY h visites -7", ":-2\r\n"),
+				step("HSET h max 9223372036854775807 nom alice", ":2\r\n"),
+				step("HINCRBY h max 1", "-ERREUR : incrément ou décrément hors limites\r\n"),
+				step("HINCRBY h nom 1", "-ERREUR : la valeur du champ n'est pas un nombre entier\r\n"),
+				step("HINCRBY h visites 1.5", "-ERREUR : l'incrément n'est pas un nombre entier ou est hors limites\r\n"),
+				step("HINCRBY vide champ x", "-ERREUR : l'incrément n'est pas un nombre entier ou est hors limites\r\n"),
+				step("HMGET h visites max", array("-2", "9223372036854775807")),
+			},
+			expectedPropagated: []string{"0 HINCRBY h visites 5", "0 HINCRBY h visites -7", "0 HSET h max 9223372036854775807 nom alice"},
+		},
+		{
+			name: "HINCRBYFLOAT propagé sous forme de HSET",
+			testSteps: []commandTestStep{
+				step("HINCRBYFLOAT h prix 10.5", bulk("10.5")),
+				step("HINCRBYFLOAT h prix 0.1", bulk("10.6")),
+				step("HINCRBYFLOAT h prix -0.6", bulk("10")),
+				step("HINCRBYFLOAT h stock 5.0e3", bulk("5000")),
+				step("HSET h nom alice", ":1\r\n"),
+				step("HINCRBYFLOAT h nom 1", "-ERREUR : la valeur du champ n'est pas un nombre décimal valide\r\n"),
+				step("HINCRBYFLOAT h prix abc", "-ERREUR : l'incrément n'est pas un nombre décimal valide\r\n"),
+				step("HINCRBYFLOAT h prix inf", "-ERREUR : l'incrément n'est pas un nombre décimal valide\r\n"),
+				step("HINCRBYFLOAT vide champ abc", "-ERREUR : l'incrément n'est pas un nombre décimal valide\r\n"),
+				step("EXISTS vide", ":0\r\n"),
+			},
+			expectedPropagated: []string{"0 HSET h prix 10.5", "0 HSET h prix 10.6", "0 HSET h prix 10", "0 HSET h stock 5000", "0 HSET h nom alice"},
+		},
+		{
+			name: "HRANDFIELD sans modifier le hash",
+			testSteps: []commandTestStep{
+				step("HSET h nom alice", ":1\r\n"),
+				step("HRANDFIELD h", bulk("nom")),
+				step("HRANDFIELD h 5", array("nom")),
+				step("HRANDFIELD h -2", array("nom", "nom")),
+				step("HRANDFIELD h 1 WITHVALUES", array("nom", "alice")),
+				step("HRANDFIELD h -2 withvalues", array("nom", "alice", "nom", "alice")),
+				step("HRANDFIELD h 0", "*0\r\n"),
+				step("HRANDFIELD absente", "$-1\r\n"),
+				step("HRANDFIELD absente 3", "*0\r\n"),
+				step("HRANDFIELD h un", "-ERREUR : le nombre de champs doit être un nombre entier\r\n"),
+				step("HRANDFIELD h 1 VALUES", "-ERREUR : erreur de syntaxe*"),
+				step("HLEN h", ":1\r\n"),
+			},
+			expectedPropagated: []string{"0 HSET h nom alice"},
+		},
+		{
+			name: "clé d'un autre type",
+			testSteps: []commandTestStep{
+				step("SET chaîne v", "+OK\r\n"),
+				step("HDEL chaîne f", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HEXISTS chaîne f", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HLEN chaîne", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HKEYS chaîne", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HVALS chaîne", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HMGET chaîne f", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HINCRBY chaîne f 1", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HINCRBYFLOAT chaîne f 1", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HSETNX chaîne f v", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HSTRLEN chaîne f", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HRANDFIELD chaîne", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("TYPE chaîne", "+string\r\n"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// maximumFieldExpirationMilliseconds est la date d'expiration maximale d'un field, en ms depuis l'epoch (limite de Redis)
+const maximumFieldExpirationMilliseconds = 1<<48 - 1
+
+// handleHashFieldExpireCommand implémente HEXPIRE key seconds [NX|XX|GT|LT] FIELDS numfields field [field ...]
+func (commandRegistry *RedisCommandRegistry) handleHashFieldExpireCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return applyHashFieldExpiration("HEXPIRE", commandArguments, redisStorage, protocolEncoder, 1000, func(expirationMilliseconds int64) time.Time {
+		return time.UnixMilli(time.Now().UnixMilli() + expirationMilliseconds)
+	})
+}
+
+// handleHashFieldPreciseExpireCommand implémente HPEXPIRE key milliseconds [NX|XX|GT|LT] FIELDS numfields field [field ...]
+func (commandRegistry *RedisCommandRegistry) handleHashFieldPreciseExpireCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return applyHashFieldExpiration("HPEXPIRE", commandArguments, redisStorage, protocolEncoder, 1, func(expirationMilliseconds int64) time.Time {
+		return time.UnixMilli(time.Now().UnixMilli() + expirationMilliseconds)
+	})
+}
+
+// handleHashFieldExpireAtCommand implémente HEXPIREAT key unix-time-seconds [NX|XX|GT|LT] FIELDS numfields field [field ...]
+func (commandRegistry *RedisCommandRegistry) handleHashFieldExpireAtCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return applyHashFieldExpiration("HEXPIREAT", commandArguments, redisStorage, protocolEncoder, 1000, func(expirationMilliseconds int64) time.Time {
+		return time.UnixMilli(expirationMilliseconds)
+	})
+}
+
+// handleHashFieldPreciseExpireAtCommand implémente HPEXPIREAT key unix-time-milliseconds [NX|XX|GT|LT] FIELDS numfields field [field ...]
+func (commandRegistry *RedisCommandRegistry) handleHashFieldPreciseExpireAtCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return applyHashFieldExpiration("HPEXPIREAT", commandArguments, redisStorage, protocolEncoder, 1, func(expirationMilliseconds int64) time.Time {
+		return time.UnixMilli(expirationMilliseconds)
+	})
+}
+
+// applyHashFieldExpiration factorise la famille HEXPIRE : parsing, condition puis application field par field
+// millisecondsPerUnit convertit la valeur reçue en millisecondes (1000 pour des secondes, 1 pour des millisecondes)
+// L'expiration est propagée en HPEXPIREAT absolu, limité aux fields effectivement modifiés
+func applyHashFieldExpiration(commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, millisecondsPerUnit int64, computeExpirationTime func(int64) time.Time) ([]propagatedCommand, error) {
+	expirationValue, parseError := strconv.ParseInt(commandArguments[1], 10, 64)
+	if parseError != nil {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : la valeur d'expiration doit être un nombre entier")
+	}
+	if expirationValue < 0 || expirationValue > maximumFieldExpirationMilliseconds/millisecondsPerUnit {
+		return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : valeur d'expiration invalide pour '%s'", commandName))
+	}
+
+	var expirationOptions storage.KeyExpirationOptions
+	fieldsArgumentIndex := 2
+	if fieldsArgumentIndex < len(commandArguments) && !strings.EqualFold(commandArguments[fieldsArgumentIndex], "FIELDS") {
+		switch strings.ToUpper(commandArguments[fieldsArgumentIndex]) {
+		case "NX":
+			expirationOptions.OnlyIfNoExpiration = true
+		case "XX":
+			expirationOptions.OnlyIfHasExpiration = true
+		case "GT":
+			expirationOptions.OnlyIfGreater = true
+		case "LT":
+			expirationOptions.OnlyIfLess = true
+		default:
+			return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : option inconnue '%s' pour %s", commandArguments[fieldsArgumentIndex], commandName))
+		}
+		fieldsArgumentIndex++
+	}
+
+	fieldNames, errorMessage := parseHashFieldsArgument(commandName, commandArguments[fieldsArgumentIndex:])
+	if errorMessage != "" {
+		return nil, protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	expirationTime := computeExpirationTime(expirationValue * millisecondsPerUnit)
+	if expirationTime.UnixMilli() > maximumFieldExpirationMilliseconds {
+		return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : valeur d'expiration invalide pour '%s'", commandName))
+	}
+
+	expirationResults, expirationError := redisStorage.SetHashFieldsExpiration(commandArguments[0], fieldNames, expirationTime, expirationOptions)
+	if expirationError != nil {
+		return nil, writeHashStorageError(protocolEncoder, expirationError)
+	}
+	return propagateHashFieldExpiration(commandArguments[0], fieldNames, expirationResults, expirationTime), writeHashFieldResults(protocolEncoder, expirationResults)
+}
+
+// propagateHashFieldExpiration retourne HPEXPIREAT key unix-time-milliseconds FIELDS numfields field [field ...]
+// pour les seuls fields dont l'expiration a été appliquée (rien si aucun ne l'a été)
+func propagateHashFieldExpiration(hashKey string, fieldNames []string, expirationResults []int, expirationTime time.Time) []propagatedCommand {
+	var appliedFieldNames []string
+	for fieldIndex, expirationResult := range expirationResults {
+		if expirationResult == storage.HashFieldExpirationUpdated || expirationResult == storage.HashFieldDeleted {
+			appliedFieldNames = append(appliedFieldNames, fieldNames[fieldIndex])
+		}
+	}
+	if len(appliedFieldNames) == 0 {
+		return nil
+	}
+
+	propagatedArguments := []string{
+		hashKey,
+		strconv.FormatInt(expirationTime.UnixMilli(), 10),
+		"FIELDS",
+		strconv.Itoa(len(appliedFieldNames)),
+	}
+	return []propagatedCommand{{commandName: "HPEXPIREAT", commandArguments: append(propagatedArguments, appliedFieldNames...)}}
+}
+
+// parseHashFieldsArgument lit la clause FIELDS numfields field [field ...] qui termine les commandes HEXPIRE, HTTL et HPERSIST
+// Retourne un message d'erreur non vide si la clause est invalide
+func parseHashFieldsArgument(commandName string, fieldsArguments []string) ([]string, string) {
+	if len(fieldsArguments) < 2 || !strings.EqualFold(fieldsArguments[0], "FIELDS") {
+		return nil, fmt.Sprintf("ERREUR : argument FIELDS manquant pour '%s'", commandName)
+	}
+	fieldCount, parseError := strconv.Atoi(fieldsArguments[1])
+	if parseError != nil || fieldCount <= 0 {
+		return nil, "ERREUR : numfields doit être un entier strictement positif"
+	}
+	if fieldCount != len(fieldsArguments)-2 {
+		return nil, "ERREUR : numfields ne correspond pas au nombre de champs fournis"
+	}
+	return fieldsArguments[2:], ""
+}
+
+// writeHashFieldResults écrit un code entier par field (HEXPIRE, HPERSIST)
+func writeHashFieldResults(protocolEncoder *protocol.RedisSerializationProtocolEncoder, fieldResults []int) error {
+	protocolEncoder.WriteArrayHeader(len(fieldResults))
+	for _, fieldResult := range fieldResults {
+		if writeError := protocolEncoder.WriteIntegerResponse(int64(fieldResult)); writeError != nil {
+			return writeError
+		}
+	}
+	return nil
+}
+
+// handleHashFieldTimeToLiveCommand implémente HTTL key FIELDS numfields field [field ...]
+func (commandRegistry *RedisCommandRegistry) handleHashFieldTimeToLiveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeHashFieldExpirationInfo("HTTL", commandArguments, redisStorage, protocolEncoder, func(expirationTime time.Time) int64 {
+		// Arrondi à la seconde la plus proche, comme TTL
+		return (time.Until(expirationTime).Milliseconds() + 500) / 1000
+	})
+}
+
+// handleHashFieldPreciseTimeToLiveCommand implémente HPTTL key FIELDS numfields field [field ...]
+func (commandRegistry *RedisCommandRegistry) handleHashFieldPreciseTimeToLiveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeHashFieldExpirationInfo("HPTTL", commandArguments, redisStorage, protocolEncoder, func(expirationTime time.Time) int64 {
+		return time.Until(expirationTime).Milliseconds()
+	})
+}
+
+// writeHashFieldExpirationInfo factorise HTTL/HPTTL : une valeur par field (-2 = field absent, -1 = pas de TTL)
+func writeHashFieldExpirationInfo(commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, formatExpiration func(time.Time) int64) error {
+	fieldNames, errorMessage := parseHashFieldsArgument(commandName, commandArguments[1:])
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	expirationTimes, fieldsFound, lookupError := redisStorage.GetHashFieldsExpiration(commandArguments[0], fieldNames)
+	if lookupError != nil {
+		return writeHashStorageError(protocolEncoder, lookupError)
+	}
+
+	protocolEncoder.WriteArrayHeader(len(fieldNames))
+	for fieldIndex, expirationTime := range expirationTimes {
+		switch {
+		case !fieldsFound[fieldIndex]:
+			protocolEncoder.WriteIntegerResponse(storage.HashFieldNotFound)
+		case expirationTime.IsZero():
+			protocolEncoder.WriteIntegerResponse(storage.HashFieldWithoutExpiration)
+		default:
+			protocolEncoder.WriteIntegerResponse(formatExpiration(expirationTime))
+		}
+	}
+	return nil
+}
+
+// handleHashFieldPersistCommand implémente HPERSIST key FIELDS numfields field [field ...]
+func (commandRegistry *RedisCommandRegistry) handleHashFieldPersistCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	fieldNames, errorMessage := parseHashFieldsArgument("HPERSIST", commandArguments[1:])
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	persistResults, persistError := redisStorage.PersistHashFields(commandArguments[0], fieldNames)
+	if persistError != nil {
+		return writeHashStorageError(protocolEncoder, persistError)
+	}
+	return writeHashFieldResults(protocolEncoder, persistResults)
+}
@@ -0,0 +1,99 @@
+package commands
+
+import "testing"
+
+func TestHashFieldExpirationCommands(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "HEXPIRE HTTL HPERSIST",
+			testSteps: []commandTestStep{
+				step("HSET h a 1 b 2", ":2\r\n"),
+				step("HEXPIRE h 100 FIELDS 2 a inconnu", "*2\r\n:1\r\n:-2\r\n"),
+				step("HTTL h FIELDS 3 a b inconnu", "*3\r\n:100\r\n:-1\r\n:-2\r\n"),
+				step("HPEXPIRE h 50000 FIELDS 1 b", "*1\r\n:1\r\n"),
+				step("HTTL h FIELDS 1 b", "*1\r\n:50\r\n"),
+				step("HPERSIST h FIELDS 3 a b inconnu", "*3\r\n:1\r\n:1\r\n:-2\r\n"),
+				step("HPERSIST h FIELDS 1 a", "*1\r\n:-1\r\n"),
+				step("HTTL absente FIELDS 1 a", "*1\r\n:-2\r\n"),
+				step("TTL h", ":-1\r\n"),
+			},
+		},
+		{
+			name: "dates absolues propagées en HPEXPIREAT pour les fields modifiés",
+			testSteps: []commandTestStep{
+				step("HSET h a 1 b 2", ":2\r\n"),
+				step("HEXPIREAT h 4102444800 FIELDS 2 a inconnu", "*2\r\n:1\r\n:-2\r\n"),
+				step("HPEXPIREAT h 4102444800123 NX FIELDS 2 a b", "*2\r\n:0\r\n:1\r\n"),
+				step("HEXPIREAT h 4102444800 NX FIELDS 1 a", "*1\r\n:0\r\n"),
+				step("HPTTL h FIELDS 1 inconnu", "*1\r\n:-2\r\n"),
+			},
+			expectedPropagated: []string{"0 HSET h a 1 b 2", "0 HPEXPIREAT h 4102444800000 FIELDS 1 a", "0 HPEXPIREAT h 4102444800123 FIELDS 1 b"},
+		},
+		{
+			name: "conditions NX XX GT LT",
+			testSteps: []commandTestStep{
+				step("HSET h a 1", ":1\r\n"),
+				step("HEXPIRE h 100 XX FIELDS 1 a", "*1\r\n:0\r\n"),
+				step("HEXPIRE h 100 GT FIELDS 1 a", "*1\r\n:0\r\n"),
+				step("HEXPIRE h 100 LT FIELDS 1 a", "*1\r\n:1\r\n"),
+				step("HEXPIRE h 50 GT FIELDS 1 a", "*1\r\n:0\r\n"),
+				step("HEXPIRE h 200 gt FIELDS 1 a", "*1\r\n:1\r\n"),
+				step("HEXPIRE h 150 XX FIELDS 1 a", "*1\r\n:1\r\n"),
+				step("HTTL h FIELDS 1 a", "*1\r\n:150\r\n"),
+			},
+		},
+		{
+			name: "une date passée supprime les fields puis la clé",
+			testSteps: []commandTestStep{
+				step("HSET h a 1 b 2", ":2\r\n"),
+				step("HEXPIREAT h 1 FIELDS 1 a", "*1\r\n:2\r\n"),
+				step("HLEN h", ":1\r\n"),
+				step("HPEXPIREAT h 1000 FIELDS 1 b", "*1\r\n:2\r\n"),
+				step("EXISTS h", ":0\r\n"),
+			},
+			expectedPropagated: []string{"0 HSET h a 1 b 2", "0 HPEXPIREAT h 1000 FIELDS 1 a", "0 HPEXPIREAT h 1000 FIELDS 1 b"},
+		},
+		{
+			name: "HSET retire le TTL et HINCRBYFLOAT le conserve",
+			testSteps: []commandTestStep{
+				step("HSET h a 1 b 2", ":2\r\n"),
+				step("HEXPIREAT h 4102444800 FIELDS 2 a b", "*2\r\n:1\r\n:1\r\n"),
+				step("HSET h a 3", ":0\r\n"),
+				step("HINCRBYFLOAT h b 0.5", bulk("2.5")),
+				step("HTTL h FIELDS 2 a b", "*2\r\n:-1\r\n*"),
+			},
+			expectedPropagated: []string{"0 HSET h a 1 b 2", "0 HPEXPIREAT h 4102444800000 FIELDS 2 a b", "0 HSET h a 3", "0 HINCRBYFLOAT h b 0.5"},
+		},
+		{
+			name: "erreurs",
+			testSteps: []commandTestStep{
+				step("HSET h a 1", ":1\r\n"),
+				step("SET chaîne v", "+OK\r\n"),
+				step("HEXPIRE h dix FIELDS 1 a", "-ERREUR : la valeur d'expiration doit être un nombre entier\r\n"),
+				step("HEXPIRE h -1 FIELDS 1 a", "-ERREUR : valeur d'expiration invalide pour 'HEXPIRE'\r\n"),
+				step("HPEXPIREAT h 281474976710656 FIELDS 1 a", "-ERREUR : valeur d'expiration invalide pour 'HPEXPIREAT'\r\n"),
+				step("HEXPIRE h 10 KEEPTTL FIELDS 1 a", "-ERREUR : option inconnue 'KEEPTTL' pour HEXPIRE\r\n"),
+				step("HEXPIRE h 10 NX a", "-ERREUR : argument FIELDS manquant pour 'HEXPIRE'\r\n"),
+				step("HTTL h FIELDS 0 a", "-ERREUR : numfields doit être un entier strictement positif\r\n"),
+				step("HPERSIST h FIELDS 2 a", "-ERREUR : numfields ne correspond pas au nombre de champs fournis\r\n"),
+				step("HEXPIRE chaîne 10 FIELDS 1 a", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HTTL chaîne FIELDS 1 a", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("HTTL h FIELDS 1 a", "*1\r\n:-1\r\n"),
+			},
+			expectedPropagated: []string{"0 HSET h a 1", "0 SET chaîne v"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+		})
+	}
+}
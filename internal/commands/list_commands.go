@@ -2,6 +2,7 @@ package commands
 
 import (
 	"strconv"
+	"strings"
 
 	"redis-go/internal/protocol"
 	"redis-go/internal/storage"
@@ -41,31 +42,37 @@ func (commandRegistry *RedisCommandRegistry) handleRightPushCommand(commandArgum
 	return protocolEncoder.WriteIntegerResponse(int64(listLength))
 }
 
-// handleLeftPopCommand implémente LPOP key
+// handleLeftPopCommand implémente LPOP key [count]
 func (commandRegistry *RedisCommandRegistry) handleLeftPopCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
-	if len(commandArguments) != 1 {
-		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'LPOP' (attendu: LPOP clé)")
+	if len(commandArguments) != 1 && len(commandArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'LPOP' (attendu: LPOP clé [nombre])")
 	}
 
 	listKey := commandArguments[0]
+	if len(commandArguments) == 2 {
+		return writePoppedListElements(commandArguments[1], listKey, true, redisStorage, protocolEncoder)
+	}
 	poppedElement, elementExists := redisStorage.PopElementFromList(listKey, true) // true = left
 	if !elementExists {
-		return protocolEncoder.WriteBulkStringResponse("(nil)")
+		return protocolEncoder.WriteNullBulkStringResponse()
 	}
 
 	return protocolEncoder.WriteBulkStringResponse(poppedElement)
 }
 
-// handleRightPopCommand implémente RPOP key
+// handleRightPopCommand implémente RPOP key [count]
 func (commandRegistry *RedisCommandRegistry) handleRightPopCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
-	if len(commandArguments) != 1 {
-		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'RPOP' (attendu: RPOP clé)")
+	if len(commandArguments) != 1 && len(commandArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'RPOP' (attendu: RPOP clé [nombre])")
 	}
 
 	listKey := commandArguments[0]
+	if len(commandArguments) == 2 {
+		return writePoppedListElements(commandArguments[1], listKey, false, redisStorage, protocolEncoder)
+	}
 	poppedElement, elementExists := redisStorage.PopElementFromList(listKey, false) // false = right
 	if !elementExists {
-		return protocolEncoder.WriteBulkStringResponse("(nil)")
+		return protocolEncoder.WriteNullBulkStringResponse()
 	}
 
 	return protocolEncoder.WriteBulkStringResponse(poppedElement)
@@ -110,3 +117,213 @@ func (commandRegistry *RedisCommandRegistry) handleListRangeCommand(commandArgum
 
 	return protocolEncoder.WriteArrayResponse(listElements)
 }
+
+// writePoppedListElements implémente la forme LPOP/RPOP key count : un tableau d'au plus count éléments,
+// ou un tableau null si la clé n'existe pas
+func writePoppedListElements(countArgument string, listKey string, popFromLeft bool, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	elementCount, parseError := strconv.Atoi(countArgument)
+	if parseError != nil || elementCount < 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : le nombre d'éléments doit être un entier positif")
+	}
+
+	poppedElements, popError := redisStorage.PopElementsFromList(listKey, popFromLeft, elementCount)
+	if popError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	if poppedElements == nil {
+		return protocolEncoder.WriteNullArrayResponse()
+	}
+	return protocolEncoder.WriteArrayResponse(poppedElements)
+}
+
+// handleLeftPushExistingCommand implémente LPUSHX key element [element ...] : n'ajoute qu'à une liste existante
+func (commandRegistry *RedisCommandRegistry) handleLeftPushExistingCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	listLength := redisStorage.PushElementsToExistingList(commandArguments[0], commandArguments[1:], true)
+	if listLength == -1 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(listLength))
+}
+
+// handleRightPushExistingCommand implémente RPUSHX key element [element ...] : n'ajoute qu'à une liste existante
+func (commandRegistry *RedisCommandRegistry) handleRightPushExistingCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	listLength := redisStorage.PushElementsToExistingList(commandArguments[0], commandArguments[1:], false)
+	if listLength == -1 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(listLength))
+}
+
+// handleListIndexCommand implémente LINDEX key index (index négatif compté depuis la fin)
+func (commandRegistry *RedisCommandRegistry) handleListIndexCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	elementIndex, parseError := strconv.Atoi(commandArguments[1])
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'index doit être un nombre entier")
+	}
+
+	listElement, elementExists, indexError := redisStorage.GetListElementAtIndex(commandArguments[0], elementIndex)
+	if indexError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	if !elementExists {
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+	return protocolEncoder.WriteBulkStringResponse(listElement)
+}
+
+// handleListSetCommand implémente LSET key index element
+func (commandRegistry *RedisCommandRegistry) handleListSetCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	elementIndex, parseError := strconv.Atoi(commandArguments[1])
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'index doit être un nombre entier")
+	}
+
+	switch redisStorage.SetListElementAtIndex(commandArguments[0], elementIndex, commandArguments[2]) {
+	case nil:
+		return protocolEncoder.WriteSimpleStringResponse("OK")
+	case storage.ErrKeyNotFound:
+		return protocolEncoder.WriteErrorResponse("ERREUR : la clé n'existe pas")
+	case storage.ErrIndexOutOfRange:
+		return protocolEncoder.WriteErrorResponse("ERREUR : index hors limites")
+	default:
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+}
+
+// handleListInsertCommand implémente LINSERT key BEFORE|AFTER pivot element
+// Retourne la nouvelle longueur, 0 si la clé n'existe pas, -1 si le pivot est absent
+func (commandRegistry *RedisCommandRegistry) handleListInsertCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	var insertBefore bool
+	switch strings.ToUpper(commandArguments[1]) {
+	case "BEFORE":
+		insertBefore = true
+	case "AFTER":
+		insertBefore = false
+	default:
+		return protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe (attendu: LINSERT clé BEFORE|AFTER pivot élément)")
+	}
+
+	listLength, insertError := redisStorage.InsertListElement(commandArguments[0], insertBefore, commandArguments[2], commandArguments[3])
+	if insertError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(listLength))
+}
+
+// handleListRemoveCommand implémente LREM key count element
+// count > 0 : depuis le début, count < 0 : depuis la fin, count = 0 : toutes les occurrences
+func (commandRegistry *RedisCommandRegistry) handleListRemoveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	removalCount, parseError := strconv.Atoi(commandArguments[1])
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : le nombre d'occurrences doit être un nombre entier")
+	}
+
+	removedCount, removeError := redisStorage.RemoveListElements(commandArguments[0], removalCount, commandArguments[2])
+	if removeError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(removedCount))
+}
+
+// handleListTrimCommand implémente LTRIM key start stop (utile pour les listes plafonnées : LPUSH puis LTRIM)
+func (commandRegistry *RedisCommandRegistry) handleListTrimCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	startIndex, parseError := strconv.Atoi(commandArguments[1])
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'index de début doit être un nombre entier")
+	}
+	stopIndex, parseError := strconv.Atoi(commandArguments[2])
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'index de fin doit être un nombre entier")
+	}
+
+	if trimError := redisStorage.TrimList(commandArguments[0], startIndex, stopIndex); trimError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handleListPositionCommand implémente LPOS key element [RANK rank] [COUNT num-matches] [MAXLEN len]
+// Sans COUNT, retourne la position de la correspondance ou null ; avec COUNT, un tableau de positions
+func (commandRegistry *RedisCommandRegistry) handleListPositionCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	positionQuery := storage.ListPositionQuery{SearchedElement: commandArguments[1], MatchRank: 1}
+	countRequested := false
+
+	optionArguments := commandArguments[2:]
+	for optionIndex := 0; optionIndex < len(optionArguments); optionIndex += 2 {
+		if optionIndex+1 >= len(optionArguments) {
+			return protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe (attendu: LPOS clé élément [RANK rang] [COUNT nombre] [MAXLEN longueur])")
+		}
+		optionValue, parseError := strconv.Atoi(optionArguments[optionIndex+1])
+		if parseError != nil {
+			return protocolEncoder.WriteErrorResponse("ERREUR : la valeur de l'option doit être un nombre entier")
+		}
+
+		switch strings.ToUpper(optionArguments[optionIndex]) {
+		case "RANK":
+			if optionValue == 0 {
+				return protocolEncoder.WriteErrorResponse("ERREUR : RANK ne peut pas être 0 (1 pour la première correspondance, -1 pour la dernière)")
+			}
+			positionQuery.MatchRank = optionValue
+		case "COUNT":
+			if optionValue < 0 {
+				return protocolEncoder.WriteErrorResponse("ERREUR : COUNT ne peut pas être négatif")
+			}
+			positionQuery.MatchCount = optionValue
+			countRequested = true
+		case "MAXLEN":
+			if optionValue < 0 {
+				return protocolEncoder.WriteErrorResponse("ERREUR : MAXLEN ne peut pas être négatif")
+			}
+			positionQuery.MaximumComparisons = optionValue
+		default:
+			return protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe (attendu: LPOS clé élément [RANK rang] [COUNT nombre] [MAXLEN longueur])")
+		}
+	}
+	if !countRequested {
+		positionQuery.MatchCount = 1
+	}
+
+	matchPositions, positionError := redisStorage.FindListElementPositions(commandArguments[0], positionQuery)
+	if positionError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	if countRequested {
+		protocolEncoder.WriteArrayHeader(len(matchPositions))
+		for _, matchPosition := range matchPositions {
+			protocolEncoder.WriteIntegerResponse(int64(matchPosition))
+		}
+		return nil
+	}
+	if len(matchPositions) == 0 {
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(matchPositions[0]))
+}
+
+// handleListMoveCommand implémente LMOVE source destination LEFT|RIGHT LEFT|RIGHT
+// Retourne l'élément déplacé, ou null si la source est vide
+func (commandRegistry *RedisCommandRegistry) handleListMoveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	popFromLeft, sourceSideValid := parseListSide(commandArguments[2])
+	pushToLeft, destinationSideValid := parseListSide(commandArguments[3])
+	if !sourceSideValid || !destinationSideValid {
+		return protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe (attendu: LMOVE source destination LEFT|RIGHT LEFT|RIGHT)")
+	}
+	return writeMovedListElement(commandArguments[0], commandArguments[1], popFromLeft, pushToLeft, redisStorage, protocolEncoder)
+}
+
+// handleRightPopLeftPushCommand implémente RPOPLPUSH source destination (équivalent à LMOVE source destination RIGHT LEFT)
+func (commandRegistry *RedisCommandRegistry) handleRightPopLeftPushCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeMovedListElement(commandArguments[0], commandArguments[1], false, true, redisStorage, protocolEncoder)
+}
+
+// writeMovedListElement déplace un élément entre deux listes et écrit la réponse de LMOVE / RPOPLPUSH
+func writeMovedListElement(sourceListKey string, destinationListKey string, popFromLeft bool, pushToLeft bool, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	movedElement, elementMoved, moveError := redisStorage.MoveListElement(sourceListKey, destinationListKey, popFromLeft, pushToLeft)
+	if moveError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une liste")
+	}
+	if !elementMoved {
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+	return protocolEncoder.WriteBulkStringResponse(movedElement)
+}
@@ -0,0 +1,156 @@
+package commands
+
+import "testing"
+
+func TestListCommands(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "LPUSH et LPUSHX insèrent les éléments un par un",
+			testSteps: []commandTestStep{
+				step("LPUSH l a b c", ":3\r\n"),
+				step("LRANGE l 0 -1", array("c", "b", "a")),
+				step("LPUSHX l d e", ":5\r\n"),
+				step("RPUSHX l f g", ":7\r\n"),
+				step("LRANGE l 0 -1", array("e", "d", "c", "b", "a", "f", "g")),
+				step("LPUSHX absente a", ":0\r\n"),
+				step("RPUSHX absente a", ":0\r\n"),
+				step("EXISTS absente", ":0\r\n"),
+			},
+			expectedPropagated: []string{"0 LPUSH l a b c", "0 LPUSHX l d e", "0 RPUSHX l f g", "0 LPUSHX absente a", "0 RPUSHX absente a"},
+		},
+		{
+			name: "LINDEX et LSET avec index négatifs",
+			testSteps: []commandTestStep{
+				step("RPUSH l a b c", ":3\r\n"),
+				step("LINDEX l 0", bulk("a")),
+				step("LINDEX l -1", bulk("c")),
+				step("LINDEX l -3", bulk("a")),
+				step("LINDEX l 3", "$-1\r\n"),
+				step("LINDEX l -4", "$-1\r\n"),
+				step("LSET l -1 z", "+OK\r\n"),
+				step("LSET l 1 y", "+OK\r\n"),
+				step("LRANGE l 0 -1", array("a", "y", "z")),
+				step("LSET l 3 x", "-ERREUR : index hors limites\r\n"),
+				step("LSET absente 0 x", "-ERREUR : la clé n'existe pas\r\n"),
+				step("LINDEX l un", "-ERREUR : l'index doit être un nombre entier\r\n"),
+			},
+			expectedPropagated: []string{"0 RPUSH l a b c", "0 LSET l -1 z", "0 LSET l 1 y"},
+		},
+		{
+			name: "LINSERT autour d'un pivot",
+			testSteps: []commandTestStep{
+				step("RPUSH l a c", ":2\r\n"),
+				step("LINSERT l BEFORE c b", ":3\r\n"),
+				step("LINSERT l after c d", ":4\r\n"),
+				step("LINSERT l BEFORE absent x", ":-1\r\n"),
+				step("LINSERT absente BEFORE a x", ":0\r\n"),
+				step("LRANGE l 0 -1", array("a", "b", "c", "d")),
+				step("LINSERT l AROUND a x", "-ERREUR : erreur de syntaxe*"),
+			},
+			expectedPropagated: []string{"0 RPUSH l a c", "0 LINSERT l BEFORE c b", "0 LINSERT l after c d", "0 LINSERT l BEFORE absent x", "0 LINSERT absente BEFORE a x"},
+		},
+		{
+			name: "LREM depuis la tête, la queue ou partout",
+			testSteps: []commandTestStep{
+				step("RPUSH l x a x b x", ":5\r\n"),
+				step("LREM l 1 x", ":1\r\n"),
+				step("LRANGE l 0 -1", array("a", "x", "b", "x")),
+				step("LREM l -1 x", ":1\r\n"),
+				step("LRANGE l 0 -1", array("a", "x", "b")),
+				step("LREM l 0 x", ":1\r\n"),
+				step("LREM l 0 absent", ":0\r\n"),
+				step("LREM l 0 a", ":1\r\n"),
+				step("LREM l 0 b", ":1\r\n"),
+				step("EXISTS l", ":0\r\n"),
+				step("LREM l beaucoup x", "-ERREUR : le nombre d'occurrences doit être un nombre entier\r\n"),
+			},
+			expectedPropagated: []string{"0 RPUSH l x a x b x", "0 LREM l 1 x", "0 LREM l -1 x", "0 LREM l 0 x", "0 LREM l 0 absent", "0 LREM l 0 a", "0 LREM l 0 b"},
+		},
+		{
+			name: "LTRIM pour plafonner une liste",
+			testSteps: []commandTestStep{
+				step("RPUSH l a b c d e", ":5\r\n"),
+				step("LTRIM l 1 -2", "+OK\r\n"),
+				step("LRANGE l 0 -1", array("b", "c", "d")),
+				step("LTRIM l -100 100", "+OK\r\n"),
+				step("LLEN l", ":3\r\n"),
+				step("LTRIM l 2 1", "+OK\r\n"),
+				step("EXISTS l", ":0\r\n"),
+				step("LTRIM l a 1", "-ERREUR : l'index de début doit être un nombre entier\r\n"),
+			},
+		},
+		{
+			name: "LPOS avec RANK, COUNT et MAXLEN",
+			testSteps: []commandTestStep{
+				step("RPUSH l a b c b b", ":5\r\n"),
+				step("LPOS l b", ":1\r\n"),
+				step("LPOS l b RANK 2", ":3\r\n"),
+				step("LPOS l b RANK -1", ":4\r\n"),
+				step("LPOS l b COUNT 0", "*3\r\n:1\r\n:3\r\n:4\r\n"),
+				step("LPOS l b COUNT 2 RANK -1", "*2\r\n:4\r\n:3\r\n"),
+				step("LPOS l b COUNT 0 MAXLEN 2", "*1\r\n:1\r\n"),
+				step("LPOS l z", "$-1\r\n"),
+				step("LPOS l z COUNT 1", "*0\r\n"),
+				step("LPOS l b RANK 0", "-ERREUR : RANK ne peut pas être 0*"),
+				step("LPOS l b COUNT -1", "-ERREUR : COUNT ne peut pas être négatif\r\n"),
+				step("LPOS l b MAXLEN -1", "-ERREUR : MAXLEN ne peut pas être négatif\r\n"),
+				step("LPOS l b RANK", "-ERREUR : erreur de syntaxe*"),
+			},
+		},
+		{
+			name: "LMOVE et RPOPLPUSH",
+			testSteps: []commandTestStep{
+				step("RPUSH source a b c", ":3\r\n"),
+				step("LMOVE source dest LEFT RIGHT", bulk("a")),
+				step("RPOPLPUSH source dest", bulk("c")),
+				step("LRANGE dest 0 -1", array("c", "a")),
+				step("LMOVE source source LEFT RIGHT", bulk("b")),
+				step("LMOVE vide dest LEFT LEFT", "$-1\r\n"),
+				step("LMOVE source dest UP LEFT", "-ERREUR : erreur de syntaxe*"),
+			},
+			expectedPropagated: []string{"0 RPUSH source a b c", "0 LMOVE source dest LEFT RIGHT", "0 RPOPLPUSH source dest", "0 LMOVE source source LEFT RIGHT", "0 LMOVE vide dest LEFT LEFT"},
+		},
+		{
+			name: "LPOP et RPOP avec un nombre d'éléments",
+			testSteps: []commandTestStep{
+				step("RPUSH l a b c d", ":4\r\n"),
+				step("LPOP l 2", array("a", "b")),
+				step("RPOP l 0", "*0\r\n"),
+				step("RPOP l 5", array("d", "c")),
+				step("EXISTS l", ":0\r\n"),
+				step("LPOP l 1", "*-1\r\n"),
+				step("LPOP l -1", "-ERREUR : le nombre d'éléments doit être un entier positif\r\n"),
+			},
+			expectedPropagated: []string{"0 RPUSH l a b c d", "0 LPOP l 2", "0 RPOP l 0", "0 RPOP l 5", "0 LPOP l 1"},
+		},
+		{
+			name: "clé d'un autre type",
+			testSteps: []commandTestStep{
+				step("SET chaîne v", "+OK\r\n"),
+				step("LINDEX chaîne 0", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("LSET chaîne 0 v", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("LINSERT chaîne BEFORE a b", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("LREM chaîne 0 v", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("LTRIM chaîne 0 1", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("LPOS chaîne v", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("LPUSHX chaîne v", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("RPUSH l a", ":1\r\n"),
+				step("LMOVE l chaîne LEFT LEFT", "-ERREUR : cette clé ne contient pas une liste\r\n"),
+				step("LLEN l", ":1\r\n"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+		})
+	}
+}
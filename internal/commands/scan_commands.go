@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// defaultScanElementCount est le nombre d'éléments examinés par appel sans option COUNT (comme Redis)
+const defaultScanElementCount = 10
+
+// scanOptionSyntax décrit les options acceptées par chaque commande de la famille SCAN
+type scanOptionSyntax struct {
+	commandUsage   string
+	acceptsType    bool
+	acceptsNoValue bool
+}
+
+// parseScanArguments lit le curseur puis les options MATCH, COUNT, TYPE (SCAN) et NOVALUES (HSCAN)
+// Retourne les paramètres de parcours, NOVALUES, et un message d'erreur vide si la syntaxe est valide
+func parseScanArguments(cursorArgument string, optionArguments []string, optionSyntax scanOptionSyntax) (storage.ScanParameters, bool, string) {
+	scanParameters := storage.ScanParameters{ElementCount: defaultScanElementCount}
+	scanCursor, parseError := strconv.ParseUint(cursorArgument, 10, 64)
+	if parseError != nil {
+		return scanParameters, false, "ERREUR : curseur invalide"
+	}
+	scanParameters.ScanCursor = scanCursor
+
+	omitValues := false
+	for optionIndex := 0; optionIndex < len(optionArguments); optionIndex++ {
+		optionName := strings.ToUpper(optionArguments[optionIndex])
+		if optionName == "NOVALUES" && optionSyntax.acceptsNoValue {
+			omitValues = true
+			continue
+		}
+		if optionIndex+1 >= len(optionArguments) {
+			return scanParameters, false, fmt.Sprintf("ERREUR : erreur de syntaxe (attendu: %s)", optionSyntax.commandUsage)
+		}
+		optionValue := optionArguments[optionIndex+1]
+		optionIndex++
+
+		switch {
+		case optionName == "MATCH":
+			// Le motif * accepte tout : inutile de l'évaluer pour chaque élément
+			if optionValue != "*" {
+				scanParameters.MatchPattern = optionValue
+			}
+		case optionName == "COUNT":
+			elementCount, countError := strconv.Atoi(optionValue)
+			if countError != nil || elementCount < 1 {
+				return scanParameters, false, "ERREUR : COUNT doit être un entier positif"
+			}
+			scanParameters.ElementCount = elementCount
+		case optionName == "TYPE" && optionSyntax.acceptsType:
+			dataTypeFilter, typeKnown := parseDataTypeName(optionValue)
+			if !typeKnown {
+				return scanParameters, false, fmt.Sprintf("ERREUR : type inconnu '%s' (attendu: string, list, set, hash, zset)", optionValue)
+			}
+			scanParameters.DataTypeFilter = &dataTypeFilter
+		default:
+			return scanParameters, false, fmt.Sprintf("ERREUR : erreur de syntaxe (attendu: %s)", optionSyntax.commandUsage)
+		}
+	}
+	return scanParameters, omitValues, ""
+}
+
+// parseDataTypeName convertit un nom de type retourné par TYPE en type de stockage
+func parseDataTypeName(typeName string) (storage.RedisDataType, bool) {
+	switch strings.ToLower(typeName) {
+	case "string":
+		return storage.RedisStringType, true
+	case "list":
+		return storage.RedisListType, true
+	case "set":
+		return storage.RedisSetType, true
+	case "hash":
+		return storage.RedisHashType, true
+	case "zset":
+		return storage.RedisZSetType, true
+	}
+	return -1, false
+}
+
+// writeScanResponse écrit la réponse d'une commande SCAN : [curseur suivant, [éléments...]]
+func writeScanResponse(protocolEncoder *protocol.RedisSerializationProtocolEncoder, nextCursor uint64, scannedElements []string) error {
+	protocolEncoder.WriteArrayHeader(2)
+	protocolEncoder.WriteBulkStringResponse(strconv.FormatUint(nextCursor, 10))
+	return protocolEncoder.WriteArrayResponse(scannedElements)
+}
+
+// handleScanCommand implémente SCAN cursor [MATCH pattern] [COUNT count] [TYPE type]
+// Contrairement à KEYS, chaque appel n'examine qu'environ COUNT clés : la base n'est bloquée que brièvement
+func (commandRegistry *RedisCommandRegistry) handleScanCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	scanParameters, _, errorMessage := parseScanArguments(commandArguments[0], commandArguments[1:], scanOptionSyntax{
+		commandUsage: "SCAN curseur [MATCH motif] [COUNT nombre] [TYPE type]",
+		acceptsType:  true,
+	})
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	nextCursor, matchingKeys := redisStorage.ScanKeys(scanParameters)
+	return writeScanResponse(protocolEncoder, nextCursor, matchingKeys)
+}
+
+// handleSetScanCommand implémente SSCAN key cursor [MATCH pattern] [COUNT count]
+func (commandRegistry *RedisCommandRegistry) handleSetScanCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	scanParameters, _, errorMessage := parseScanArguments(commandArguments[1], commandArguments[2:], scanOptionSyntax{
+		commandUsage: "SSCAN clé curseur [MATCH motif] [COUNT nombre]",
+	})
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	nextCursor, setMembers, scanError := redisStorage.ScanSetMembers(commandArguments[0], scanParameters)
+	if scanError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+	return writeScanResponse(protocolEncoder, nextCursor, setMembers)
+}
+
+// handleHashScanCommand implémente HSCAN key cursor [MATCH pattern] [COUNT count] [NOVALUES]
+func (commandRegistry *RedisCommandRegistry) handleHashScanCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	scanParameters, omitValues, errorMessage := parseScanArguments(commandArguments[1], commandArguments[2:], scanOptionSyntax{
+		commandUsage:   "HSCAN clé curseur [MATCH motif] [COUNT nombre] [NOVALUES]",
+		acceptsNoValue: true,
+	})
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	nextCursor, fieldsAndValues, scanError := redisStorage.ScanHashFields(commandArguments[0], scanParameters)
+	if scanError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un hash")
+	}
+	if omitValues {
+		fieldNames := make([]string, 0, len(fieldsAndValues)/2)
+		for fieldIndex := 0; fieldIndex < len(fieldsAndValues); fieldIndex += 2 {
+			fieldNames = append(fieldNames, fieldsAndValues[fieldIndex])
+		}
+		return writeScanResponse(protocolEncoder, nextCursor, fieldNames)
+	}
+	return writeScanResponse(protocolEncoder, nextCursor, fieldsAndValues)
+}
+
+// handleSortedSetScanCommand implémente ZSCAN key cursor [MATCH pattern] [COUNT count]
+// Les membres sont suivis de leur score, en chaîne comme dans Redis (y compris en RESP3)
+func (commandRegistry *RedisCommandRegistry) handleSortedSetScanCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	scanParameters, _, errorMessage := parseScanArguments(commandArguments[1], commandArguments[2:], scanOptionSyntax{
+		commandUsage: "ZSCAN clé curseur [MATCH motif] [COUNT nombre]",
+	})
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	nextCursor, sortedSetMembers, scanError := redisStorage.ScanSortedSetMembers(commandArguments[0], scanParameters)
+	if scanError != nil {
+		return writeSortedSetStorageError(protocolEncoder, scanError)
+	}
+	membersAndScores := make([]string, 0, 2*len(sortedSetMembers))
+	for _, sortedSetMember := range sortedSetMembers {
+		membersAndScores = append(membersAndScores, sortedSetMember.MemberName, formatSortedSetScore(sortedSetMember.MemberScore))
+	}
+	return writeScanResponse(protocolEncoder, nextCursor, membersAndScores)
+}
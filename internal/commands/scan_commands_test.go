@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseScanReply retourne le curseur suivant puis les éléments d'une réponse SCAN encodée en RESP
+func parseScanReply(t *testing.T, scanReply string) []string {
+	t.Helper()
+	var replyValues []string
+	for _, replyLine := range strings.Split(strings.TrimSuffix(scanReply, "\r\n"), "\r\n") {
+		if !strings.HasPrefix(replyLine, "*") && !strings.HasPrefix(replyLine, "$") {
+			replyValues = append(replyValues, replyLine)
+		}
+	}
+	if len(replyValues) == 0 {
+		t.Fatalf("réponse SCAN invalide: %q", scanReply)
+	}
+	return replyValues
+}
+
+func TestScanCommands(t *testing.T) {
+	testCases := []struct {
+		name      string
+		testSteps []commandTestStep
+	}{
+		{
+			name: "SCAN avec MATCH, COUNT et TYPE",
+			testSteps: []commandTestStep{
+				step("SET user:1 v", "+OK\r\n"),
+				step("SADD user:set a", ":1\r\n"),
+				step("SCAN 0 MATCH user:? COUNT 100", "*2\r\n"+bulk("0")+array("user:1")),
+				step("SCAN 0 TYPE set COUNT 100", "*2\r\n"+bulk("0")+array("user:set")),
+				step("SCAN 0 TYPE hash", "*2\r\n"+bulk("0")+"*0\r\n"),
+				step("SCAN 0 MATCH absent:*", "*2\r\n"+bulk("0")+"*0\r\n"),
+			},
+		},
+		{
+			name: "SSCAN, HSCAN et ZSCAN sur de petites collections",
+			testSteps: []commandTestStep{
+				step("SADD s membre", ":1\r\n"),
+				step("SSCAN s 0", "*2\r\n"+bulk("0")+array("membre")),
+				step("HSET h champ valeur", ":1\r\n"),
+				step("HSCAN h 0", "*2\r\n"+bulk("0")+array("champ", "valeur")),
+				step("HSCAN h 0 NOVALUES", "*2\r\n"+bulk("0")+array("champ")),
+				step("HSCAN h 0 MATCH autre*", "*2\r\n"+bulk("0")+"*0\r\n"),
+				step("ZADD z 1.5 m", ":1\r\n"),
+				step("ZSCAN z 0", "*2\r\n"+bulk("0")+array("m", "1.5")),
+				step("SSCAN absente 0", "*2\r\n"+bulk("0")+"*0\r\n"),
+			},
+		},
+		{
+			name: "erreurs de syntaxe et de type",
+			testSteps: []commandTestStep{
+				step("SET chaîne v", "+OK\r\n"),
+				step("SCAN -1", "-ERREUR : curseur invalide\r\n"),
+				step("SCAN abc", "-ERREUR : curseur invalide\r\n"),
+				step("SCAN 0 COUNT 0", "-ERREUR : COUNT doit être un entier positif\r\n"),
+				step("SCAN 0 COUNT", "-ERREUR : erreur de syntaxe (attendu: SCAN curseur [MATCH motif] [COUNT nombre] [TYPE type])\r\n"),
+				step("SCAN 0 TYPE stream", "-ERREUR : type inconnu 'stream' (attendu: string, list, set, hash, zset)\r\n"),
+				step("SCAN 0 NOVALUES", "-ERREUR : erreur de syntaxe*"),
+				step("SSCAN s 0 TYPE set", "-ERREUR : erreur de syntaxe (attendu: SSCAN clé curseur [MATCH motif] [COUNT nombre])\r\n"),
+				step("SSCAN chaîne 0", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("HSCAN chaîne 0", "-ERREUR : cette clé ne contient pas un hash\r\n"),
+				step("ZSCAN chaîne 0", "-ERREUR : cette clé ne contient pas un sorted set\r\n"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			newCommandTestFixture().runSteps(t, testCase.testSteps)
+		})
+	}
+}
+
+func TestScanCursorCoversGrowingDatabase(t *testing.T) {
+	testFixture := newCommandTestFixture()
+	for keyIndex := 0; keyIndex < 200; keyIndex++ {
+		testFixture.execute(t, "SET", "stable:"+strconv.Itoa(keyIndex), "v")
+	}
+
+	returnedKeys := make(map[string]bool)
+	scanCursor := "0"
+	for callIndex := 0; ; callIndex++ {
+		scanReply := parseScanReply(t, testFixture.execute(t, "SCAN", scanCursor, "MATCH", "stable:*"))
+		scanCursor = scanReply[0]
+		for _, scannedKey := range scanReply[1:] {
+			returnedKeys[scannedKey] = true
+		}
+		if scanCursor == "0" {
+			break
+		}
+		testFixture.execute(t, "SET", "ajout:"+strconv.Itoa(callIndex), "v")
+	}
+	if len(returnedKeys) != 200 {
+		t.Fatalf("%d clés stables retournées, attendu 200", len(returnedKeys))
+	}
+}
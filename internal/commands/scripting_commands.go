@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// handleEvalCommand implémente EVAL script numkeys [key ...] [arg ...]
+func (commandRegistry *RedisCommandRegistry) handleEvalCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) < 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'EVAL' (attendu: EVAL script numkeys [key ...] [arg ...])")
+	}
+
+	scriptSha1, compileError := commandRegistry.scriptingEngine.loadScript(commandArguments[0])
+	if compileError != nil {
+		return protocolEncoder.WriteErrorResponse(formatScriptErrorMessage("ERREUR : erreur de compilation du script: " + compileError.Error()))
+	}
+
+	return commandRegistry.runCachedScript("EVAL", scriptSha1, commandArguments[1:], redisStorage, protocolEncoder)
+}
+
+// handleEvalShaCommand implémente EVALSHA sha1 numkeys [key ...] [arg ...]
+func (commandRegistry *RedisCommandRegistry) handleEvalShaCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) < 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'EVALSHA' (attendu: EVALSHA sha1 numkeys [key ...] [arg ...])")
+	}
+
+	return commandRegistry.runCachedScript("EVALSHA", commandArguments[0], commandArguments[1:], redisStorage, protocolEncoder)
+}
+
+// runCachedScript sépare KEYS et ARGV selon numkeys puis exécute le script du cache
+func (commandRegistry *RedisCommandRegistry) runCachedScript(commandName string, scriptSha1 string, scriptParameters []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	keyCount, parseError := strconv.Atoi(scriptParameters[0])
+	if parseError != nil || keyCount < 0 {
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : numkeys doit être un entier positif pour '%s'", commandName))
+	}
+	if keyCount > len(scriptParameters)-1 {
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : numkeys (%d) dépasse le nombre d'arguments pour '%s'", keyCount, commandName))
+	}
+
+	scriptKeys := scriptParameters[1 : 1+keyCount]
+	scriptArguments := scriptParameters[1+keyCount:]
+
+	scriptFound, executionError := commandRegistry.scriptingEngine.runScript(scriptSha1, scriptKeys, scriptArguments, redisStorage, protocolEncoder)
+	if executionError != nil {
+		return executionError
+	}
+	if !scriptFound {
+		return protocolEncoder.WriteErrorResponse("NOSCRIPT Aucun script correspondant. Utilisez EVAL.")
+	}
+	return nil
+}
+
+// handleScriptCommand implémente SCRIPT LOAD script | EXISTS sha1 [sha1 ...] | FLUSH [ASYNC|SYNC]
+func (commandRegistry *RedisCommandRegistry) handleScriptCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) == 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SCRIPT' (attendu: SCRIPT LOAD|EXISTS|FLUSH)")
+	}
+
+	switch strings.ToUpper(commandArguments[0]) {
+	case "LOAD":
+		if len(commandArguments) != 2 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SCRIPT LOAD' (attendu: SCRIPT LOAD script)")
+		}
+		scriptSha1, compileError := commandRegistry.scriptingEngine.loadScript(commandArguments[1])
+		if compileError != nil {
+			return protocolEncoder.WriteErrorResponse(formatScriptErrorMessage("ERREUR : erreur de compilation du script: " + compileError.Error()))
+		}
+		return protocolEncoder.WriteBulkStringResponse(scriptSha1)
+
+	case "EXISTS":
+		if len(commandArguments) < 2 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SCRIPT EXISTS' (attendu: SCRIPT EXISTS sha1 [sha1 ...])")
+		}
+		scriptSha1List := commandArguments[1:]
+		protocolEncoder.WriteArrayHeader(len(scriptSha1List))
+		for _, scriptSha1 := range scriptSha1List {
+			scriptExists := int64(0)
+			if commandRegistry.scriptingEngine.hasScript(scriptSha1) {
+				scriptExists = 1
+			}
+			if writeError := protocolEncoder.WriteIntegerResponse(scriptExists); writeError != nil {
+				return writeError
+			}
+		}
+		return nil
+
+	case "FLUSH":
+		if len(commandArguments) > 2 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SCRIPT FLUSH' (attendu: SCRIPT FLUSH [ASYNC|SYNC])")
+		}
+		if len(commandArguments) == 2 {
+			flushMode := strings.ToUpper(commandArguments[1])
+			if flushMode != "ASYNC" && flushMode != "SYNC" {
+				return protocolEncoder.WriteErrorResponse("ERREUR : SCRIPT FLUSH accepte seulement ASYNC ou SYNC")
+			}
+		}
+		commandRegistry.scriptingEngine.flushScripts()
+		return protocolEncoder.WriteSimpleStringResponse("OK")
+
+	default:
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : sous-commande inconnue '%s' pour SCRIPT (attendu: LOAD, EXISTS, FLUSH)", commandArguments[0]))
+	}
+}
@@ -0,0 +1,124 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+)
+
+// evalStep construit une étape EVAL dont le script peut contenir des espaces
+func evalStep(scriptSource string, scriptParameters []string, expectedReply string) commandTestStep {
+	return commandTestStep{commandArguments: append([]string{"EVAL", scriptSource}, scriptParameters...), expectedReply: expectedReply}
+}
+
+func TestEvalReplyConversion(t *testing.T) {
+	testCases := []struct {
+		name          string
+		scriptSource  string
+		expectedReply string
+	}{
+		{name: "nombre tronqué en entier", scriptSource: "return 3.99", expectedReply: ":3\r\n"},
+		{name: "chaîne", scriptSource: "return 'texte'", expectedReply: bulk("texte")},
+		{name: "vrai", scriptSource: "return true", expectedReply: ":1\r\n"},
+		{name: "faux", scriptSource: "return false", expectedReply: "$-1\r\n"},
+		{name: "nil", scriptSource: "return nil", expectedReply: "$-1\r\n"},
+		{name: "tableau imbriqué", scriptSource: "return {1, 'a', {2}}", expectedReply: "*3\r\n:1\r\n" + bulk("a") + "*1\r\n:2\r\n"},
+		{name: "tableau coupé au premier nil", scriptSource: "return {1, nil, 3}", expectedReply: "*1\r\n:1\r\n"},
+		{name: "status_reply", scriptSource: "return redis.status_reply('FINI')", expectedReply: "+FINI\r\n"},
+		{name: "error_reply", scriptSource: "return redis.error_reply('ERREUR : refusé')", expectedReply: "-ERREUR : refusé\r\n"},
+		{name: "table ok", scriptSource: "return {ok = 'OK'}", expectedReply: "+OK\r\n"},
+		{name: "sha1hex", scriptSource: "return redis.sha1hex('')", expectedReply: bulk("da39a3ee5e6b4b0d3255bfef95601890afd80709")},
+		{name: "erreur de compilation", scriptSource: "return (", expectedReply: "-ERREUR : erreur de compilation du script*"},
+		{name: "variable globale interdite", scriptSource: "x = 1", expectedReply: "-ERREUR : erreur d'exécution du script*"},
+		{name: "bibliothèques non sûres retirées", scriptSource: "return loadstring('return 1')", expectedReply: "-ERREUR : erreur d'exécution du script*"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, []commandTestStep{evalStep(testCase.scriptSource, []string{"0"}, testCase.expectedReply)})
+		})
+	}
+}
+
+func TestEvalRedisCalls(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "KEYS ARGV et conversion des réponses de redis.call",
+			testSteps: []commandTestStep{
+				evalStep("return {KEYS[1], KEYS[2], ARGV[1]}", []string{"2", "k1", "k2", "a1"}, array("k1", "k2", "a1")),
+				evalStep("return redis.call('SET', KEYS[1], ARGV[1])", []string{"1", "k", "v"}, "+OK\r\n"),
+				evalStep("return redis.call('GET', KEYS[1])", []string{"1", "k"}, bulk("v")),
+				evalStep("return redis.call('INCRBY', 'n', 5) + 1", []string{"0"}, ":6\r\n"),
+				evalStep("redis.call('RPUSH', 'l', 'a', 'b') return redis.call('LRANGE', 'l', 0, -1)", []string{"0"}, array("a", "b")),
+				evalStep("return type(redis.call('SET', 'k', 'w'))", []string{"0"}, bulk("table")),
+			},
+			expectedPropagated: []string{"0 SET k v", "0 INCRBY n 5", "0 RPUSH l a b", "0 SET k w"},
+		},
+		{
+			name: "les écritures d'un script sont propagées dans une transaction",
+			testSteps: []commandTestStep{
+				evalStep("redis.call('SET', 'a', 1) redis.call('SET', 'b', 2) return redis.call('MGET', 'a', 'b')", []string{"0"}, array("1", "2")),
+			},
+			expectedPropagated: []string{"0 MULTI", "0 SET a 1", "0 SET b 2", "0 EXEC"},
+		},
+		{
+			name: "redis.call lève l'erreur, redis.pcall la retourne",
+			testSteps: []commandTestStep{
+				step("RPUSH l a", ":1\r\n"),
+				evalStep("redis.call('INCR', 'l') return 'jamais'", []string{"0"}, "-ERREUR*"),
+				evalStep("local reply = redis.pcall('INCR', 'l') return type(reply.err)", []string{"0"}, bulk("string")),
+				evalStep("return redis.pcall('NOSUCHCOMMAND')", []string{"0"}, "-ERREUR : commande inconnue 'NOSUCHCOMMAND' appelée depuis un script\r\n"),
+				evalStep("return redis.pcall('EVAL', 'return 1', 0)", []string{"0"}, "-ERREUR : la commande 'EVAL' ne peut pas être appelée depuis un script\r\n"),
+				evalStep("return redis.pcall('GET')", []string{"0"}, "-ERREUR : nombre d'arguments incorrect pour 'GET' appelée depuis un script\r\n"),
+				evalStep("return redis.call({})", []string{"0"}, "-ERREUR : erreur d'exécution du script*"),
+			},
+			expectedPropagated: []string{"0 RPUSH l a"},
+		},
+		{
+			name: "numkeys invalide",
+			testSteps: []commandTestStep{
+				evalStep("return 1", []string{"-1"}, "-ERREUR : numkeys doit être un entier positif pour 'EVAL'\r\n"),
+				evalStep("return 1", []string{"deux"}, "-ERREUR : numkeys doit être un entier positif pour 'EVAL'\r\n"),
+				evalStep("return 1", []string{"2", "k"}, "-ERREUR : numkeys (2) dépasse le nombre d'arguments pour 'EVAL'\r\n"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+		})
+	}
+}
+
+func TestScriptCache(t *testing.T) {
+	scriptSource := "return ARGV[1]"
+	scriptSha1 := computeScriptSha1(scriptSource)
+	unknownSha1 := computeScriptSha1("return 0")
+
+	testFixture := newCommandTestFixture()
+	testFixture.runSteps(t, []commandTestStep{
+		step("EVALSHA "+scriptSha1+" 0 a", "-NOSCRIPT*"),
+		{commandArguments: []string{"SCRIPT", "LOAD", scriptSource}, expectedReply: bulk(scriptSha1)},
+		step("SCRIPT EXISTS "+scriptSha1+" "+unknownSha1, "*2\r\n:1\r\n:0\r\n"),
+		step("EVALSHA "+scriptSha1+" 0 a", bulk("a")),
+		// Le SHA1 est accepté quelle que soit sa casse
+		step("EVALSHA "+strings.ToUpper(scriptSha1)+" 0 b", bulk("b")),
+		step("SCRIPT FLUSH ASYNC", "+OK\r\n"),
+		step("SCRIPT EXISTS "+scriptSha1, "*1\r\n:0\r\n"),
+		step("EVALSHA "+scriptSha1+" 0 a", "-NOSCRIPT*"),
+		// EVAL met le script en cache
+		evalStep(scriptSource, []string{"0", "c"}, bulk("c")),
+		step("EVALSHA "+scriptSha1+" 0 d", bulk("d")),
+		step("SCRIPT FLUSH LATER", "-ERREUR : SCRIPT FLUSH accepte seulement ASYNC ou SYNC\r\n"),
+		step("SCRIPT KILL", "-ERREUR : sous-commande inconnue 'KILL' pour SCRIPT*"),
+		step("SCRIPT LOAD", "-ERREUR : nombre d'arguments incorrect pour 'SCRIPT LOAD'*"),
+		{commandArguments: []string{"SCRIPT", "LOAD", "return ("}, expectedReply: "-ERREUR : erreur de compilation du script*"},
+	})
+}
@@ -0,0 +1,359 @@
+package commands
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// scriptForbiddenCommandNames liste les commandes qui ne peuvent pas être appelées depuis un script
+var scriptForbiddenCommandNames = map[string]bool{
+	"EVAL": true, "EVALSHA": true, "SCRIPT": true,
+}
+
+// redisScriptingEngine exécute les scripts Lua (EVAL/EVALSHA) et conserve le cache des scripts
+// Un seul interpréteur est partagé : les scripts sont exécutés sous accès exclusif
+type redisScriptingEngine struct {
+	commandRegistry *RedisCommandRegistry
+	engineMutex     sync.Mutex
+	luaState        *lua.LState
+	compiledScripts map[string]*lua.FunctionProto
+	// activeStorage est le stockage ciblé par le script en cours (utilisé par redis.call)
+	activeStorage *storage.RedisInMemoryStorage
+	// activeUser est l'utilisateur ACL qui a lancé le script (nil pour le serveur)
+	activeUser *RedisAclUser
+}
+
+// newRedisScriptingEngine crée l'interpréteur Lua avec les bibliothèques autorisées et la table redis
+func newRedisScriptingEngine(commandRegistry *RedisCommandRegistry) *redisScriptingEngine {
+	scriptingEngine := &redisScriptingEngine{
+		commandRegistry: commandRegistry,
+		compiledScripts: make(map[string]*lua.FunctionProto),
+	}
+	scriptingEngine.luaState = scriptingEngine.createSandboxedLuaState()
+	return scriptingEngine
+}
+
+// createSandboxedLuaState prépare un interpréteur sans accès au système de fichiers
+func (scriptingEngine *redisScriptingEngine) createSandboxedLuaState() *lua.LState {
+	luaState := lua.NewState(lua.Options{SkipOpenLibs: true})
+
+	for _, luaLibrary := range []struct {
+		libraryName   string
+		libraryOpener lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		luaState.Push(luaState.NewFunction(luaLibrary.libraryOpener))
+		luaState.Push(lua.LString(luaLibrary.libraryName))
+		luaState.Call(1, 0)
+	}
+
+	for _, unsafeFunctionName := range []string{"dofile", "loadfile", "loadstring", "load", "require", "module", "print"} {
+		luaState.SetGlobal(unsafeFunctionName, lua.LNil)
+	}
+
+	redisTable := luaState.NewTable()
+	luaState.SetFuncs(redisTable, map[string]lua.LGFunction{
+		"call":         func(luaState *lua.LState) int { return scriptingEngine.callRedisCommand(luaState, true) },
+		"pcall":        func(luaState *lua.LState) int { return scriptingEngine.callRedisCommand(luaState, false) },
+		"sha1hex":      luaSha1Hex,
+		"status_reply": func(luaState *lua.LState) int { return luaSingleFieldReply(luaState, "ok") },
+		"error_reply":  func(luaState *lua.LState) int { return luaSingleFieldReply(luaState, "err") },
+		"log":          luaLog,
+	})
+	for logLevelName, logLevelValue := range map[string]int{"LOG_DEBUG": 0, "LOG_VERBOSE": 1, "LOG_NOTICE": 2, "LOG_WARNING": 3} {
+		redisTable.RawSetString(logLevelName, lua.LNumber(logLevelValue))
+	}
+	luaState.SetGlobal("redis", redisTable)
+
+	// Les scripts ne doivent ni créer ni lire de variables globales inconnues (comme Redis)
+	globalsMetatable := luaState.NewTable()
+	luaState.SetFuncs(globalsMetatable, map[string]lua.LGFunction{
+		"__newindex": func(luaState *lua.LState) int {
+			luaState.RaiseError("le script a tenté de créer la variable globale '%s'", luaState.CheckAny(2).String())
+			return 0
+		},
+		"__index": func(luaState *lua.LState) int {
+			luaState.RaiseError("le script a tenté d'accéder à la variable globale inexistante '%s'", luaState.CheckAny(2).String())
+			return 0
+		},
+	})
+	luaState.SetMetatable(luaState.G.Global, globalsMetatable)
+
+	return luaState
+}
+
+// computeScriptSha1 retourne l'identifiant SHA1 (hexadécimal) d'un script
+func computeScriptSha1(scriptSource string) string {
+	scriptHash := sha1.Sum([]byte(scriptSource))
+	return hex.EncodeToString(scriptHash[:])
+}
+
+// loadScript compile un script et le met en cache, retourne son SHA1
+func (scriptingEngine *redisScriptingEngine) loadScript(scriptSource string) (string, error) {
+	scriptSha1 := computeScriptSha1(scriptSource)
+
+	scriptingEngine.engineMutex.Lock()
+	defer scriptingEngine.engineMutex.Unlock()
+
+	if _, alreadyCompiled := scriptingEngine.compiledScripts[scriptSha1]; alreadyCompiled {
+		return scriptSha1, nil
+	}
+
+	scriptChunk, parseError := parse.Parse(strings.NewReader(scriptSource), "@user_script")
+	if parseError != nil {
+		return "", parseError
+	}
+	compiledScript, compileError := lua.Compile(scriptChunk, "@user_script")
+	if compileError != nil {
+		return "", compileError
+	}
+
+	scriptingEngine.compiledScripts[scriptSha1] = compiledScript
+	return scriptSha1, nil
+}
+
+// hasScript indique si un script est présent dans le cache
+func (scriptingEngine *redisScriptingEngine) hasScript(scriptSha1 string) bool {
+	scriptingEngine.engineMutex.Lock()
+	defer scriptingEngine.engineMutex.Unlock()
+
+	_, scriptExists := scriptingEngine.compiledScripts[strings.ToLower(scriptSha1)]
+	return scriptExists
+}
+
+// flushScripts vide le cache des scripts
+func (scriptingEngine *redisScriptingEngine) flushScripts() {
+	scriptingEngine.engineMutex.Lock()
+	defer scriptingEngine.engineMutex.Unlock()
+
+	scriptingEngine.compiledScripts = make(map[string]*lua.FunctionProto)
+}
+
+// runScript exécute un script du cache et écrit sa valeur de retour convertie en RESP
+// Retourne false si le script n'est pas dans le cache (NOSCRIPT)
+func (scriptingEngine *redisScriptingEngine) runScript(scriptSha1 string, scriptKeys []string, scriptArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) (bool, error) {
+	scriptingEngine.engineMutex.Lock()
+	defer scriptingEngine.engineMutex.Unlock()
+
+	compiledScript, scriptExists := scriptingEngine.compiledScripts[strings.ToLower(scriptSha1)]
+	if !scriptExists {
+		return false, nil
+	}
+
+	luaState := scriptingEngine.luaState
+	luaState.SetTop(0)
+	luaState.G.Global.RawSetString("KEYS", createLuaStringArray(luaState, scriptKeys))
+	luaState.G.Global.RawSetString("ARGV", createLuaStringArray(luaState, scriptArguments))
+
+	scriptingEngine.activeStorage = redisStorage
+	defer func() { scriptingEngine.activeStorage = nil }()
+
+	luaState.Push(luaState.NewFunctionFromProto(compiledScript))
+	if executionError := luaState.PCall(0, 1, nil); executionError != nil {
+		return true, writeScriptError(scriptSha1, executionError, protocolEncoder)
+	}
+
+	scriptResult := luaState.Get(-1)
+	luaState.Pop(1)
+	return true, writeLuaValueAsReply(scriptResult, protocolEncoder)
+}
+
+// callRedisCommand implémente redis.call (raiseErrors) et redis.pcall
+func (scriptingEngine *redisScriptingEngine) callRedisCommand(luaState *lua.LState, raiseErrors bool) int {
+	argumentCount := luaState.GetTop()
+	if argumentCount == 0 {
+		luaState.RaiseError("redis.call() attend au moins un argument (le nom de la commande)")
+		return 0
+	}
+
+	commandParts := make([]string, argumentCount)
+	for argumentIndex := 1; argumentIndex <= argumentCount; argumentIndex++ {
+		switch argumentValue := luaState.Get(argumentIndex).(type) {
+		case lua.LString, lua.LNumber:
+			commandParts[argumentIndex-1] = argumentValue.String()
+		default:
+			luaState.RaiseError("les arguments de redis.call() doivent être des chaînes ou des nombres")
+			return 0
+		}
+	}
+
+	commandReply := scriptingEngine.commandRegistry.executeScriptedCommand(scriptingEngine.activeUser, commandParts[0], commandParts[1:], scriptingEngine.activeStorage)
+	if raiseErrors && commandReply.ReplyType == protocol.RedisErrorType {
+		// L'erreur est levée sous forme de table {err=...} pour être renvoyée telle quelle au client
+		luaState.Error(convertReplyToLuaValue(luaState, commandReply), 1)
+		return 0
+	}
+
+	luaState.Push(convertReplyToLuaValue(luaState, commandReply))
+	return 1
+}
+
+// executeScriptedCommand exécute une commande appelée depuis un script et retourne sa réponse décodée
+// Le script détient déjà l'accès exclusif : la commande est exécutée sans reprendre de verrou
+func (commandRegistry *RedisCommandRegistry) executeScriptedCommand(commandUser *RedisAclUser, commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage) protocol.RedisReplyValue {
+	upperCommandName := strings.ToUpper(commandName)
+	commandHandler, commandExists := commandRegistry.registeredCommands[upperCommandName]
+
+	switch {
+	case !commandExists:
+		return protocol.RedisReplyValue{ReplyType: protocol.RedisErrorType, StringValue: fmt.Sprintf("ERREUR : commande inconnue '%s' appelée depuis un script", commandName)}
+	case scriptForbiddenCommandNames[upperCommandName]:
+		return protocol.RedisReplyValue{ReplyType: protocol.RedisErrorType, StringValue: fmt.Sprintf("ERREUR : la commande '%s' ne peut pas être appelée depuis un script", upperCommandName)}
+	case !hasValidArity(upperCommandName, len(commandArguments)):
+		return protocol.RedisReplyValue{ReplyType: protocol.RedisErrorType, StringValue: fmt.Sprintf("ERREUR : nombre d'arguments incorrect pour '%s' appelée depuis un script", upperCommandName)}
+	}
+	if authorizationError := commandRegistry.checkCommandAuthorization(commandUser, upperCommandName, commandArguments); authorizationError != "" {
+		return protocol.RedisReplyValue{ReplyType: protocol.RedisErrorType, StringValue: authorizationError}
+	}
+
+	var replyBuffer bytes.Buffer
+	if executionError := commandRegistry.executeRegisteredCommand(upperCommandName, commandHandler, commandArguments, redisStorage, protocol.NewRedisSerializationProtocolEncoder(&replyBuffer)); executionError != nil {
+		return protocol.RedisReplyValue{ReplyType: protocol.RedisErrorType, StringValue: fmt.Sprintf("ERREUR : %v", executionError)}
+	}
+
+	commandReply, parseError := protocol.NewRedisSerializationProtocolParser(&replyBuffer).ParseReply()
+	if parseError != nil {
+		return protocol.RedisReplyValue{ReplyType: protocol.RedisErrorType, StringValue: fmt.Sprintf("ERREUR : réponse illisible pour '%s': %v", upperCommandName, parseError)}
+	}
+	return commandReply
+}
+
+// convertReplyToLuaValue convertit une réponse RESP en valeur Lua (règles Redis)
+func convertReplyToLuaValue(luaState *lua.LState, commandReply protocol.RedisReplyValue) lua.LValue {
+	switch commandReply.ReplyType {
+	case protocol.RedisIntegerType:
+		return lua.LNumber(commandReply.IntegerValue)
+	case protocol.RedisSimpleStringType:
+		statusTable := luaState.NewTable()
+		statusTable.RawSetString("ok", lua.LString(commandReply.StringValue))
+		return statusTable
+	case protocol.RedisErrorType:
+		errorTable := luaState.NewTable()
+		errorTable.RawSetString("err", lua.LString(commandReply.StringValue))
+		return errorTable
+	case protocol.RedisArrayType:
+		if commandReply.IsNull {
+			return lua.LFalse
+		}
+		arrayTable := luaState.CreateTable(len(commandReply.ArrayElements), 0)
+		for _, arrayElement := range commandReply.ArrayElements {
+			arrayTable.Append(convertReplyToLuaValue(luaState, arrayElement))
+		}
+		return arrayTable
+	default:
+		if commandReply.IsNull {
+			return lua.LFalse
+		}
+		return lua.LString(commandReply.StringValue)
+	}
+}
+
+// writeLuaValueAsReply convertit la valeur retournée par un script en réponse RESP (règles Redis)
+func writeLuaValueAsReply(luaValue lua.LValue, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	switch typedValue := luaValue.(type) {
+	case lua.LNumber:
+		// Les nombres Lua sont tronqués en entiers
+		return protocolEncoder.WriteIntegerResponse(int64(typedValue))
+	case lua.LString:
+		return protocolEncoder.WriteBulkStringResponse(string(typedValue))
+	case lua.LBool:
+		if typedValue {
+			return protocolEncoder.WriteIntegerResponse(1)
+		}
+		return protocolEncoder.WriteNullBulkStringResponse()
+	case *lua.LTable:
+		if errorValue, isErrorReply := typedValue.RawGetString("err").(lua.LString); isErrorReply {
+			return protocolEncoder.WriteErrorResponse(string(errorValue))
+		}
+		if statusValue, isStatusReply := typedValue.RawGetString("ok").(lua.LString); isStatusReply {
+			return protocolEncoder.WriteSimpleStringResponse(string(statusValue))
+		}
+		// Seule la partie tableau est convertie, jusqu'au premier nil
+		arrayElements := make([]lua.LValue, 0, typedValue.Len())
+		for elementIndex := 1; ; elementIndex++ {
+			arrayElement := typedValue.RawGetInt(elementIndex)
+			if arrayElement == lua.LNil {
+				break
+			}
+			arrayElements = append(arrayElements, arrayElement)
+		}
+		if writeError := protocolEncoder.WriteArrayHeader(len(arrayElements)); writeError != nil {
+			return writeError
+		}
+		for _, arrayElement := range arrayElements {
+			if writeError := writeLuaValueAsReply(arrayElement, protocolEncoder); writeError != nil {
+				return writeError
+			}
+		}
+		return nil
+	default:
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+}
+
+// writeScriptError écrit l'erreur d'un script : erreur Redis levée par redis.call ou erreur Lua
+func writeScriptError(scriptSha1 string, executionError error, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if luaApiError, isApiError := executionError.(*lua.ApiError); isApiError {
+		if errorTable, isTable := luaApiError.Object.(*lua.LTable); isTable {
+			if errorValue, isErrorReply := errorTable.RawGetString("err").(lua.LString); isErrorReply {
+				return protocolEncoder.WriteErrorResponse(string(errorValue))
+			}
+		}
+		return protocolEncoder.WriteErrorResponse(formatScriptErrorMessage(fmt.Sprintf("ERREUR : erreur d'exécution du script f_%s: %s", scriptSha1, luaApiError.Object.String())))
+	}
+	return protocolEncoder.WriteErrorResponse(formatScriptErrorMessage(fmt.Sprintf("ERREUR : erreur d'exécution du script f_%s: %v", scriptSha1, executionError)))
+}
+
+// formatScriptErrorMessage met un message d'erreur Lua sur une seule ligne (contrainte RESP)
+func formatScriptErrorMessage(errorMessage string) string {
+	return strings.Join(strings.Fields(errorMessage), " ")
+}
+
+// createLuaStringArray construit une table Lua (KEYS, ARGV) à partir de chaînes
+func createLuaStringArray(luaState *lua.LState, stringValues []string) *lua.LTable {
+	luaTable := luaState.CreateTable(len(stringValues), 0)
+	for _, stringValue := range stringValues {
+		luaTable.Append(lua.LString(stringValue))
+	}
+	return luaTable
+}
+
+// luaSha1Hex implémente redis.sha1hex(chaîne)
+func luaSha1Hex(luaState *lua.LState) int {
+	luaState.Push(lua.LString(computeScriptSha1(luaState.CheckString(1))))
+	return 1
+}
+
+// luaSingleFieldReply implémente redis.status_reply et redis.error_reply
+func luaSingleFieldReply(luaState *lua.LState, fieldName string) int {
+	replyTable := luaState.NewTable()
+	replyTable.RawSetString(fieldName, lua.LString(luaState.CheckString(1)))
+	luaState.Push(replyTable)
+	return 1
+}
+
+// luaLog implémente redis.log(niveau, message ...)
+func luaLog(luaState *lua.LState) int {
+	logLevel := luaState.CheckInt(1)
+	messageParts := make([]string, 0, luaState.GetTop()-1)
+	for argumentIndex := 2; argumentIndex <= luaState.GetTop(); argumentIndex++ {
+		messageParts = append(messageParts, luaState.Get(argumentIndex).String())
+	}
+	log.Printf("📜 Script Lua (niveau %d): %s", logLevel, strings.Join(messageParts, " "))
+	return 0
+}
@@ -1,6 +1,9 @@
 package commands
 
 import (
+	"strconv"
+	"strings"
+
 	"redis-go/internal/protocol"
 	"redis-go/internal/storage"
 )
@@ -34,7 +37,7 @@ func (commandRegistry *RedisCommandRegistry) handleSetMembersCommand(commandArgu
 		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
 	}
 
-	return protocolEncoder.WriteArrayResponse(setMembers)
+	return protocolEncoder.WriteSetResponse(setMembers)
 }
 
 // handleSetIsMemberCommand implémente SISMEMBER key member
@@ -52,3 +55,200 @@ func (commandRegistry *RedisCommandRegistry) handleSetIsMemberCommand(commandArg
 	}
 	return protocolEncoder.WriteIntegerResponse(0)
 }
+
+// handleSetRemoveCommand implémente SREM key member [member ...]
+func (commandRegistry *RedisCommandRegistry) handleSetRemoveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	removedMemberCount, removeError := redisStorage.RemoveMembersFromSet(commandArguments[0], commandArguments[1:])
+	if removeError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(removedMemberCount))
+}
+
+// handleSetCardinalityCommand implémente SCARD key
+func (commandRegistry *RedisCommandRegistry) handleSetCardinalityCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	setCardinality, cardinalityError := redisStorage.GetSetCardinality(commandArguments[0])
+	if cardinalityError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(setCardinality))
+}
+
+// handleSetMultipleIsMemberCommand implémente SMISMEMBER key member [member ...] : 1 ou 0 pour chaque membre
+func (commandRegistry *RedisCommandRegistry) handleSetMultipleIsMemberCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	membershipResults, membershipError := redisStorage.CheckSetMembersExist(commandArguments[0], commandArguments[1:])
+	if membershipError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+
+	protocolEncoder.WriteArrayHeader(len(membershipResults))
+	for _, isMember := range membershipResults {
+		if isMember {
+			protocolEncoder.WriteIntegerResponse(1)
+		} else {
+			protocolEncoder.WriteIntegerResponse(0)
+		}
+	}
+	return nil
+}
+
+// handleSetPopCommand implémente SPOP key [count] : retire des membres tirés au hasard
+// Sans count, retourne un membre ou null ; avec count, un ensemble d'au plus count membres
+// SPOP étant aléatoire, il est propagé sous la forme SREM des membres effectivement retirés
+func (commandRegistry *RedisCommandRegistry) handleSetPopCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	if len(commandArguments) > 2 {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe (attendu: SPOP clé [nombre])")
+	}
+
+	memberCount := 1
+	if len(commandArguments) == 2 {
+		parsedCount, parseError := strconv.Atoi(commandArguments[1])
+		if parseError != nil || parsedCount < 0 {
+			return nil, protocolEncoder.WriteErrorResponse("ERREUR : le nombre de membres doit être un entier positif")
+		}
+		memberCount = parsedCount
+	}
+
+	poppedMembers, popError := redisStorage.PopRandomSetMembers(commandArguments[0], memberCount)
+	if popError != nil {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+
+	var setPopPropagation []propagatedCommand
+	if len(poppedMembers) > 0 {
+		setPopPropagation = []propagatedCommand{{commandName: "SREM", commandArguments: append([]string{commandArguments[0]}, poppedMembers...)}}
+	}
+	if len(commandArguments) == 2 {
+		return setPopPropagation, protocolEncoder.WriteSetResponse(poppedMembers)
+	}
+	if len(poppedMembers) == 0 {
+		return nil, protocolEncoder.WriteNullBulkStringResponse()
+	}
+	return setPopPropagation, protocolEncoder.WriteBulkStringResponse(poppedMembers[0])
+}
+
+// handleSetRandomMemberCommand implémente SRANDMEMBER key [count] sans modifier le set
+// count positif : au plus count membres distincts ; count négatif : |count| membres pouvant se répéter
+func (commandRegistry *RedisCommandRegistry) handleSetRandomMemberCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) > 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe (attendu: SRANDMEMBER clé [nombre])")
+	}
+
+	memberCount, allowRepetitions := 1, false
+	if len(commandArguments) == 2 {
+		parsedCount, parseError := strconv.Atoi(commandArguments[1])
+		if parseError != nil {
+			return protocolEncoder.WriteErrorResponse("ERREUR : le nombre de membres doit être un nombre entier")
+		}
+		memberCount, allowRepetitions = parsedCount, parsedCount < 0
+		if allowRepetitions {
+			memberCount = -parsedCount
+		}
+	}
+
+	randomMembers, randomError := redisStorage.GetRandomSetMembers(commandArguments[0], memberCount, allowRepetitions)
+	if randomError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+	if len(commandArguments) == 2 {
+		return protocolEncoder.WriteArrayResponse(randomMembers)
+	}
+	if len(randomMembers) == 0 {
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+	return protocolEncoder.WriteBulkStringResponse(randomMembers[0])
+}
+
+// handleSetMoveCommand implémente SMOVE source destination member (1 si le membre a été déplacé, 0 sinon)
+// SMOVE n'est propagé que si le membre a changé de set (pas quand il est absent ou que source et destination sont identiques)
+func (commandRegistry *RedisCommandRegistry) handleSetMoveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	memberMoved, moveError := redisStorage.MoveSetMember(commandArguments[0], commandArguments[1], commandArguments[2])
+	if moveError != nil {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+	if !memberMoved {
+		return nil, protocolEncoder.WriteIntegerResponse(0)
+	}
+	if commandArguments[0] == commandArguments[1] {
+		return nil, protocolEncoder.WriteIntegerResponse(1)
+	}
+	return []propagatedCommand{{commandName: "SMOVE", commandArguments: commandArguments}}, protocolEncoder.WriteIntegerResponse(1)
+}
+
+// handleSetIntersectionCardinalityCommand implémente SINTERCARD numkeys key [key ...] [LIMIT limit]
+func (commandRegistry *RedisCommandRegistry) handleSetIntersectionCardinalityCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	keyCount, parseError := strconv.Atoi(commandArguments[0])
+	if parseError != nil || keyCount < 1 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : numkeys doit être un entier strictement positif")
+	}
+	if keyCount > len(commandArguments)-1 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : numkeys est supérieur au nombre de clés fournies")
+	}
+
+	resultLimit := 0
+	optionArguments := commandArguments[1+keyCount:]
+	if len(optionArguments) > 0 {
+		if len(optionArguments) != 2 || !strings.EqualFold(optionArguments[0], "LIMIT") {
+			return protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe (attendu: SINTERCARD numkeys clé [clé ...] [LIMIT limite])")
+		}
+		parsedLimit, limitError := strconv.Atoi(optionArguments[1])
+		if limitError != nil || parsedLimit < 0 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : LIMIT doit être un entier positif")
+		}
+		resultLimit = parsedLimit
+	}
+
+	intersectionSize, intersectionError := redisStorage.CountSetIntersection(commandArguments[1:1+keyCount], resultLimit)
+	if intersectionError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(intersectionSize))
+}
+
+// handleSetIntersectionCommand implémente SINTER key [key ...]
+func (commandRegistry *RedisCommandRegistry) handleSetIntersectionCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeSetAlgebraResult(storage.SetIntersection, commandArguments, redisStorage, protocolEncoder)
+}
+
+// handleSetUnionCommand implémente SUNION key [key ...]
+func (commandRegistry *RedisCommandRegistry) handleSetUnionCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeSetAlgebraResult(storage.SetUnion, commandArguments, redisStorage, protocolEncoder)
+}
+
+// handleSetDifferenceCommand implémente SDIFF key [key ...] : membres du premier set absents des suivants
+func (commandRegistry *RedisCommandRegistry) handleSetDifferenceCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return writeSetAlgebraResult(storage.SetDifference, commandArguments, redisStorage, protocolEncoder)
+}
+
+// writeSetAlgebraResult calcule une opération ensembliste et écrit les membres du résultat
+func writeSetAlgebraResult(setOperation storage.SetAlgebraOperation, setKeys []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	resultMembers, algebraError := redisStorage.ComputeSetAlgebra(setOperation, setKeys)
+	if algebraError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+	return protocolEncoder.WriteSetResponse(resultMembers)
+}
+
+// handleSetIntersectionStoreCommand implémente SINTERSTORE destination key [key ...]
+func (commandRegistry *RedisCommandRegistry) handleSetIntersectionStoreCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return storeSetAlgebraResult(storage.SetIntersection, commandArguments, redisStorage, protocolEncoder)
+}
+
+// handleSetUnionStoreCommand implémente SUNIONSTORE destination key [key ...]
+func (commandRegistry *RedisCommandRegistry) handleSetUnionStoreCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return storeSetAlgebraResult(storage.SetUnion, commandArguments, redisStorage, protocolEncoder)
+}
+
+// handleSetDifferenceStoreCommand implémente SDIFFSTORE destination key [key ...]
+func (commandRegistry *RedisCommandRegistry) handleSetDifferenceStoreCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return storeSetAlgebraResult(storage.SetDifference, commandArguments, redisStorage, protocolEncoder)
+}
+
+// storeSetAlgebraResult enregistre le résultat d'une opération ensembliste et écrit sa taille
+func storeSetAlgebraResult(setOperation storage.SetAlgebraOperation, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	resultSize, algebraError := redisStorage.StoreSetAlgebra(setOperation, commandArguments[0], commandArguments[1:])
+	if algebraError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un ensemble")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(resultSize))
+}
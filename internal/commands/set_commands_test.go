@@ -0,0 +1,144 @@
+package commands
+
+import "testing"
+
+func TestSetCommands(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+		// expectedWriteCount est le nombre d'écritures comptées, vérifié s'il n'est pas nul
+		expectedWriteCount int64
+	}{
+		{
+			name: "SREM, SCARD et SMISMEMBER",
+			testSteps: []commandTestStep{
+				step("SADD s a b c", ":3\r\n"),
+				step("SREM s a z", ":1\r\n"),
+				step("SCARD s", ":2\r\n"),
+				step("SMISMEMBER s a b z", "*3\r\n:0\r\n:1\r\n:0\r\n"),
+				step("SMISMEMBER absente a", "*1\r\n:0\r\n"),
+				step("SCARD absente", ":0\r\n"),
+				step("SREM s b c", ":2\r\n"),
+				step("EXISTS s", ":0\r\n"),
+			},
+			expectedPropagated: []string{"0 SADD s a b c", "0 SREM s a z", "0 SREM s b c"},
+		},
+		{
+			name: "SPOP propagé sous forme de SREM",
+			testSteps: []commandTestStep{
+				step("SADD s a", ":1\r\n"),
+				step("SPOP s", bulk("a")),
+				step("SPOP s", "$-1\r\n"),
+				step("SPOP s 2", "*0\r\n"),
+				step("SADD s b", ":1\r\n"),
+				step("SPOP s 5", array("b")),
+				step("EXISTS s", ":0\r\n"),
+				step("SPOP s -1", "-ERREUR : le nombre de membres doit être un entier positif\r\n"),
+				step("SPOP s 1 2", "-ERREUR : erreur de syntaxe*"),
+			},
+			expectedPropagated: []string{"0 SADD s a", "0 SREM s a", "0 SADD s b", "0 SREM s b"},
+		},
+		{
+			name: "SRANDMEMBER sans modifier le set",
+			testSteps: []commandTestStep{
+				step("SADD s a", ":1\r\n"),
+				step("SRANDMEMBER s", bulk("a")),
+				step("SRANDMEMBER s 3", array("a")),
+				step("SRANDMEMBER s -3", array("a", "a", "a")),
+				step("SRANDMEMBER s 0", "*0\r\n"),
+				step("SRANDMEMBER absente", "$-1\r\n"),
+				step("SRANDMEMBER absente -2", "*0\r\n"),
+				step("SRANDMEMBER s un", "-ERREUR : le nombre de membres doit être un nombre entier\r\n"),
+				step("SCARD s", ":1\r\n"),
+			},
+			expectedPropagated: []string{"0 SADD s a"},
+		},
+		{
+			name: "SMOVE entre deux sets",
+			testSteps: []commandTestStep{
+				step("SADD source a b", ":2\r\n"),
+				step("SMOVE source dest a", ":1\r\n"),
+				step("SMOVE source dest z", ":0\r\n"),
+				step("SMOVE absente dest a", ":0\r\n"),
+				step("SMEMBERS dest", array("a")),
+				step("SMOVE dest dest a", ":1\r\n"),
+				step("SMOVE source dest b", ":1\r\n"),
+				step("EXISTS source", ":0\r\n"),
+				step("SCARD dest", ":2\r\n"),
+			},
+			expectedPropagated: []string{"0 SADD source a b", "0 SMOVE source dest a", "0 SMOVE source dest b"},
+			expectedWriteCount: 3,
+		},
+		{
+			name: "SINTER, SUNION et SDIFF",
+			testSteps: []commandTestStep{
+				step("SADD s1 a b c", ":3\r\n"),
+				step("SADD s2 b c d", ":3\r\n"),
+				step("SADD s3 c d e", ":3\r\n"),
+				step("SINTER s1 s2 s3", array("c")),
+				step("SINTER s1 absente", "*0\r\n"),
+				step("SDIFF s1 s2", array("a")),
+				step("SDIFF absente s1", "*0\r\n"),
+				step("SUNION absente", "*0\r\n"),
+				step("SINTERCARD 2 s1 s2", ":2\r\n"),
+				step("SINTERCARD 2 s1 s2 LIMIT 1", ":1\r\n"),
+				step("SINTERCARD 3 s1 s2 s3 limit 0", ":1\r\n"),
+				step("SINTERCARD 0 s1", "-ERREUR : numkeys doit être un entier strictement positif\r\n"),
+				step("SINTERCARD 3 s1 s2", "-ERREUR : numkeys est supérieur au nombre de clés fournies\r\n"),
+				step("SINTERCARD 1 s1 LIMIT", "-ERREUR : erreur de syntaxe*"),
+				step("SINTERCARD 1 s1 LIMIT -1", "-ERREUR : LIMIT doit être un entier positif\r\n"),
+			},
+		},
+		{
+			name: "variantes STORE",
+			testSteps: []commandTestStep{
+				step("SADD s1 a b c", ":3\r\n"),
+				step("SADD s2 b c d", ":3\r\n"),
+				step("SET dest v", "+OK\r\n"),
+				step("SINTERSTORE dest s1 s2", ":2\r\n"),
+				step("TYPE dest", "+set\r\n"),
+				step("SUNIONSTORE dest s1 s2", ":4\r\n"),
+				step("SDIFFSTORE dest s1 s2", ":1\r\n"),
+				step("SMEMBERS dest", array("a")),
+				step("SDIFFSTORE s1 s1 s2", ":1\r\n"),
+				step("SCARD s1", ":1\r\n"),
+				step("SINTERSTORE dest s1 absente", ":0\r\n"),
+				step("EXISTS dest", ":0\r\n"),
+			},
+			expectedPropagated: []string{"0 SADD s1 a b c", "0 SADD s2 b c d", "0 SET dest v", "0 SINTERSTORE dest s1 s2", "0 SUNIONSTORE dest s1 s2", "0 SDIFFSTORE dest s1 s2", "0 SDIFFSTORE s1 s1 s2", "0 SINTERSTORE dest s1 absente"},
+		},
+		{
+			name: "clé d'un autre type",
+			testSteps: []commandTestStep{
+				step("SET chaîne v", "+OK\r\n"),
+				step("SADD s a", ":1\r\n"),
+				step("SREM chaîne a", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("SCARD chaîne", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("SPOP chaîne", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("SRANDMEMBER chaîne", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("SMISMEMBER chaîne a", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("SMOVE s chaîne a", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("SINTER s chaîne", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("SINTERCARD 2 s chaîne", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("SUNIONSTORE dest s chaîne", "-ERREUR : cette clé ne contient pas un ensemble\r\n"),
+				step("EXISTS dest", ":0\r\n"),
+				step("SCARD s", ":1\r\n"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+			if testCase.expectedWriteCount != 0 {
+				if writeCount := testFixture.commandRegistry.GetWriteCommandCount(); writeCount != testCase.expectedWriteCount {
+					t.Fatalf("%d écritures comptées, attendu %d", writeCount, testCase.expectedWriteCount)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,441 @@
+package commands
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// handleSortedSetAddCommand implémente ZADD key [NX|XX] [GT|LT] [CH] [INCR] score member [score member ...]
+func (commandRegistry *RedisCommandRegistry) handleSortedSetAddCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) < 3 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZADD' (attendu: ZADD clé [NX|XX] [GT|LT] [CH] [INCR] score membre [score membre ...])")
+	}
+
+	sortedSetKey := commandArguments[0]
+	var addOptions storage.SortedSetAddOptions
+	incrementMode := false
+
+	// Parsing des options placées avant les paires score/membre
+	argumentIndex := 1
+	for parsingOptions := true; parsingOptions && argumentIndex < len(commandArguments); {
+		switch strings.ToUpper(commandArguments[argumentIndex]) {
+		case "NX":
+			addOptions.OnlyIfNotExists = true
+		case "XX":
+			addOptions.OnlyIfExists = true
+		case "GT":
+			addOptions.OnlyIfGreater = true
+		case "LT":
+			addOptions.OnlyIfLess = true
+		case "CH":
+			addOptions.CountChangedMembers = true
+		case "INCR":
+			incrementMode = true
+		default:
+			parsingOptions = false
+			continue
+		}
+		argumentIndex++
+	}
+
+	scoreMemberArguments := commandArguments[argumentIndex:]
+	if len(scoreMemberArguments) == 0 || len(scoreMemberArguments)%2 != 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : erreur de syntaxe, les scores et membres doivent aller par paires")
+	}
+	if addOptions.OnlyIfNotExists && addOptions.OnlyIfExists {
+		return protocolEncoder.WriteErrorResponse("ERREUR : les options XX et NX sont incompatibles")
+	}
+	if (addOptions.OnlyIfGreater && addOptions.OnlyIfLess) || (addOptions.OnlyIfNotExists && (addOptions.OnlyIfGreater || addOptions.OnlyIfLess)) {
+		return protocolEncoder.WriteErrorResponse("ERREUR : les options GT, LT et NX sont incompatibles")
+	}
+	if incrementMode && len(scoreMemberArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : INCR n'accepte qu'une seule paire score/membre")
+	}
+
+	// Validation de tous les scores avant toute modification
+	newMembers := make([]storage.SortedSetMember, 0, len(scoreMemberArguments)/2)
+	for pairIndex := 0; pairIndex < len(scoreMemberArguments); pairIndex += 2 {
+		memberScore, parseError := parseSortedSetScore(scoreMemberArguments[pairIndex])
+		if parseError != nil {
+			return protocolEncoder.WriteErrorResponse("ERREUR : le score doit être un nombre décimal valide")
+		}
+		newMembers = append(newMembers, storage.SortedSetMember{MemberName: scoreMemberArguments[pairIndex+1], MemberScore: memberScore})
+	}
+
+	if incrementMode {
+		newScore, scoreUpdated, incrementError := redisStorage.IncrementSortedSetMemberScore(sortedSetKey, newMembers[0].MemberName, newMembers[0].MemberScore, addOptions)
+		if incrementError != nil {
+			return writeSortedSetStorageError(protocolEncoder, incrementError)
+		}
+		if !scoreUpdated {
+			return protocolEncoder.WriteNullBulkStringResponse()
+		}
+		return protocolEncoder.WriteDoubleResponse(newScore)
+	}
+
+	affectedMemberCount, addError := redisStorage.AddMembersToSortedSet(sortedSetKey, newMembers, addOptions)
+	if addError != nil {
+		return writeSortedSetStorageError(protocolEncoder, addError)
+	}
+
+	return protocolEncoder.WriteIntegerResponse(int64(affectedMemberCount))
+}
+
+// handleSortedSetIncrementByCommand implémente ZINCRBY key increment member
+func (commandRegistry *RedisCommandRegistry) handleSortedSetIncrementByCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 3 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZINCRBY' (attendu: ZINCRBY clé incrément membre)")
+	}
+
+	scoreIncrement, parseError := parseSortedSetScore(commandArguments[1])
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'incrément doit être un nombre décimal valide")
+	}
+
+	newScore, _, incrementError := redisStorage.IncrementSortedSetMemberScore(commandArguments[0], commandArguments[2], scoreIncrement, storage.SortedSetAddOptions{})
+	if incrementError != nil {
+		return writeSortedSetStorageError(protocolEncoder, incrementError)
+	}
+
+	return protocolEncoder.WriteDoubleResponse(newScore)
+}
+
+// handleSortedSetRemoveCommand implémente ZREM key member [member ...]
+func (commandRegistry *RedisCommandRegistry) handleSortedSetRemoveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) < 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZREM' (attendu: ZREM clé membre [membre ...])")
+	}
+
+	removedMemberCount, removeError := redisStorage.RemoveMembersFromSortedSet(commandArguments[0], commandArguments[1:])
+	if removeError != nil {
+		return writeSortedSetStorageError(protocolEncoder, removeError)
+	}
+
+	return protocolEncoder.WriteIntegerResponse(int64(removedMemberCount))
+}
+
+// handleSortedSetScoreCommand implémente ZSCORE key member
+func (commandRegistry *RedisCommandRegistry) handleSortedSetScoreCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZSCORE' (attendu: ZSCORE clé membre)")
+	}
+
+	memberScore, memberExists, scoreError := redisStorage.GetSortedSetMemberScore(commandArguments[0], commandArguments[1])
+	if scoreError != nil {
+		return writeSortedSetStorageError(protocolEncoder, scoreError)
+	}
+	if !memberExists {
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+
+	return protocolEncoder.WriteDoubleResponse(memberScore)
+}
+
+// handleSortedSetCardinalityCommand implémente ZCARD key
+func (commandRegistry *RedisCommandRegistry) handleSortedSetCardinalityCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 1 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZCARD' (attendu: ZCARD clé)")
+	}
+
+	sortedSetCardinality, cardinalityError := redisStorage.GetSortedSetCardinality(commandArguments[0])
+	if cardinalityError != nil {
+		return writeSortedSetStorageError(protocolEncoder, cardinalityError)
+	}
+
+	return protocolEncoder.WriteIntegerResponse(int64(sortedSetCardinality))
+}
+
+// handleSortedSetRankCommand implémente ZRANK key member
+func (commandRegistry *RedisCommandRegistry) handleSortedSetRankCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return commandRegistry.writeSortedSetRank("ZRANK", commandArguments, redisStorage, protocolEncoder, false)
+}
+
+// handleSortedSetReverseRankCommand implémente ZREVRANK key member
+func (commandRegistry *RedisCommandRegistry) handleSortedSetReverseRankCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	return commandRegistry.writeSortedSetRank("ZREVRANK", commandArguments, redisStorage, protocolEncoder, true)
+}
+
+// writeSortedSetRank factorise ZRANK et ZREVRANK (avec l'option WITHSCORE)
+func (commandRegistry *RedisCommandRegistry) writeSortedSetRank(commandName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, reverseOrder bool) error {
+	if len(commandArguments) != 2 && len(commandArguments) != 3 {
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : nombre d'arguments incorrect pour '%s' (attendu: %s clé membre [WITHSCORE])", commandName, commandName))
+	}
+
+	withScore := false
+	if len(commandArguments) == 3 {
+		if strings.ToUpper(commandArguments[2]) != "WITHSCORE" {
+			return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : option inconnue '%s' pour %s", commandArguments[2], commandName))
+		}
+		withScore = true
+	}
+
+	memberRank, memberScore, memberExists, rankError := redisStorage.GetSortedSetMemberRank(commandArguments[0], commandArguments[1], reverseOrder)
+	if rankError != nil {
+		return writeSortedSetStorageError(protocolEncoder, rankError)
+	}
+	if !memberExists {
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+
+	if !withScore {
+		return protocolEncoder.WriteIntegerResponse(int64(memberRank))
+	}
+
+	if writeError := protocolEncoder.WriteArrayHeader(2); writeError != nil {
+		return writeError
+	}
+	if writeError := protocolEncoder.WriteIntegerResponse(int64(memberRank)); writeError != nil {
+		return writeError
+	}
+	return protocolEncoder.WriteDoubleResponse(memberScore)
+}
+
+// handleSortedSetRangeCommand implémente ZRANGE key start stop [BYSCORE] [REV] [LIMIT offset count] [WITHSCORES]
+func (commandRegistry *RedisCommandRegistry) handleSortedSetRangeCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) < 3 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZRANGE' (attendu: ZRANGE clé début fin [BYSCORE] [REV] [LIMIT offset nombre] [WITHSCORES])")
+	}
+
+	byScore := false
+	reverseOrder := false
+	for _, rangeOption := range commandArguments[3:] {
+		switch strings.ToUpper(rangeOption) {
+		case "BYSCORE":
+			byScore = true
+		case "REV":
+			reverseOrder = true
+		}
+	}
+
+	if byScore {
+		// En mode REV, Redis attend les bornes dans l'ordre max puis min
+		return commandRegistry.writeSortedSetRangeByScore(commandArguments, 3, redisStorage, protocolEncoder, reverseOrder)
+	}
+
+	withScores, _, _, optionError := parseSortedSetRangeOptions(commandArguments[3:], false)
+	if optionError != "" {
+		return protocolEncoder.WriteErrorResponse(optionError)
+	}
+
+	return commandRegistry.writeSortedSetRangeByRank(commandArguments[:3], withScores, redisStorage, protocolEncoder, reverseOrder)
+}
+
+// handleSortedSetReverseRangeCommand implémente ZREVRANGE key start stop [WITHSCORES]
+func (commandRegistry *RedisCommandRegistry) handleSortedSetReverseRangeCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 3 && len(commandArguments) != 4 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZREVRANGE' (attendu: ZREVRANGE clé début fin [WITHSCORES])")
+	}
+
+	withScores, _, _, optionError := parseSortedSetRangeOptions(commandArguments[3:], false)
+	if optionError != "" {
+		return protocolEncoder.WriteErrorResponse(optionError)
+	}
+
+	return commandRegistry.writeSortedSetRangeByRank(commandArguments[:3], withScores, redisStorage, protocolEncoder, true)
+}
+
+// writeSortedSetRangeByRank factorise les lectures par rang (ZRANGE, ZREVRANGE)
+func (commandRegistry *RedisCommandRegistry) writeSortedSetRangeByRank(commandArguments []string, withScores bool, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, reverseOrder bool) error {
+	startIndex, parseError := strconv.Atoi(commandArguments[1])
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'index de début doit être un nombre entier")
+	}
+
+	stopIndex, parseError := strconv.Atoi(commandArguments[2])
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'index de fin doit être un nombre entier")
+	}
+
+	rangeMembers, rangeError := redisStorage.GetSortedSetRangeByRank(commandArguments[0], startIndex, stopIndex, reverseOrder)
+	if rangeError != nil {
+		return writeSortedSetStorageError(protocolEncoder, rangeError)
+	}
+
+	return writeSortedSetMembers(protocolEncoder, rangeMembers, withScores)
+}
+
+// handleSortedSetRangeByScoreCommand implémente ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count]
+func (commandRegistry *RedisCommandRegistry) handleSortedSetRangeByScoreCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) < 3 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZRANGEBYSCORE' (attendu: ZRANGEBYSCORE clé min max [WITHSCORES] [LIMIT offset nombre])")
+	}
+
+	return commandRegistry.writeSortedSetRangeByScore(commandArguments, 3, redisStorage, protocolEncoder, false)
+}
+
+// handleSortedSetReverseRangeByScoreCommand implémente ZREVRANGEBYSCORE key max min [WITHSCORES] [LIMIT offset count]
+func (commandRegistry *RedisCommandRegistry) handleSortedSetReverseRangeByScoreCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) < 3 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZREVRANGEBYSCORE' (attendu: ZREVRANGEBYSCORE clé max min [WITHSCORES] [LIMIT offset nombre])")
+	}
+
+	return commandRegistry.writeSortedSetRangeByScore(commandArguments, 3, redisStorage, protocolEncoder, true)
+}
+
+// writeSortedSetRangeByScore factorise les lectures par score (bornes max puis min en ordre inverse)
+func (commandRegistry *RedisCommandRegistry) writeSortedSetRangeByScore(commandArguments []string, optionsStartIndex int, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder, reverseOrder bool) error {
+	minimumArgument, maximumArgument := commandArguments[1], commandArguments[2]
+	if reverseOrder {
+		minimumArgument, maximumArgument = maximumArgument, minimumArgument
+	}
+
+	scoreRange, rangeValid := parseSortedSetScoreRange(minimumArgument, maximumArgument)
+	if !rangeValid {
+		return protocolEncoder.WriteErrorResponse("ERREUR : les bornes min et max doivent être des nombres décimaux")
+	}
+
+	withScores, resultOffset, resultCount, optionError := parseSortedSetRangeOptions(commandArguments[optionsStartIndex:], true)
+	if optionError != "" {
+		return protocolEncoder.WriteErrorResponse(optionError)
+	}
+
+	rangeMembers, rangeError := redisStorage.GetSortedSetRangeByScore(commandArguments[0], scoreRange, reverseOrder, resultOffset, resultCount)
+	if rangeError != nil {
+		return writeSortedSetStorageError(protocolEncoder, rangeError)
+	}
+
+	return writeSortedSetMembers(protocolEncoder, rangeMembers, withScores)
+}
+
+// handleSortedSetCountCommand implémente ZCOUNT key min max
+func (commandRegistry *RedisCommandRegistry) handleSortedSetCountCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 3 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ZCOUNT' (attendu: ZCOUNT clé min max)")
+	}
+
+	scoreRange, rangeValid := parseSortedSetScoreRange(commandArguments[1], commandArguments[2])
+	if !rangeValid {
+		return protocolEncoder.WriteErrorResponse("ERREUR : les bornes min et max doivent être des nombres décimaux")
+	}
+
+	memberCount, countError := redisStorage.CountSortedSetMembersInScoreRange(commandArguments[0], scoreRange)
+	if countError != nil {
+		return writeSortedSetStorageError(protocolEncoder, countError)
+	}
+
+	return protocolEncoder.WriteIntegerResponse(int64(memberCount))
+}
+
+// parseSortedSetRangeOptions parse WITHSCORES, LIMIT offset count (et ignore BYSCORE/REV déjà traités)
+func parseSortedSetRangeOptions(rangeOptions []string, limitAllowed bool) (bool, int, int, string) {
+	withScores := false
+	resultOffset, resultCount := 0, -1
+
+	for optionIndex := 0; optionIndex < len(rangeOptions); optionIndex++ {
+		switch strings.ToUpper(rangeOptions[optionIndex]) {
+		case "WITHSCORES":
+			withScores = true
+		case "BYSCORE", "REV":
+			// Déjà pris en compte par ZRANGE
+		case "LIMIT":
+			if !limitAllowed {
+				return false, 0, 0, "ERREUR : LIMIT n'est utilisable qu'avec BYSCORE"
+			}
+			if optionIndex+2 >= len(rangeOptions) {
+				return false, 0, 0, "ERREUR : LIMIT attend un offset et un nombre"
+			}
+			var offsetError, countError error
+			resultOffset, offsetError = strconv.Atoi(rangeOptions[optionIndex+1])
+			resultCount, countError = strconv.Atoi(rangeOptions[optionIndex+2])
+			if offsetError != nil || countError != nil {
+				return false, 0, 0, "ERREUR : l'offset et le nombre de LIMIT doivent être des entiers"
+			}
+			optionIndex += 2
+		default:
+			return false, 0, 0, fmt.Sprintf("ERREUR : option inconnue '%s'", rangeOptions[optionIndex])
+		}
+	}
+
+	return withScores, resultOffset, resultCount, ""
+}
+
+// parseSortedSetScore parse un score (accepte inf, +inf, -inf, refuse NaN)
+func parseSortedSetScore(scoreArgument string) (float64, error) {
+	parsedScore, parseError := strconv.ParseFloat(scoreArgument, 64)
+	if parseError != nil {
+		return 0, parseError
+	}
+	if math.IsNaN(parsedScore) {
+		return 0, fmt.Errorf("score NaN")
+	}
+	return parsedScore, nil
+}
+
+// parseSortedSetScoreRange parse deux bornes de score, '(' indique une borne exclusive
+func parseSortedSetScoreRange(minimumArgument, maximumArgument string) (storage.SortedSetScoreRange, bool) {
+	var scoreRange storage.SortedSetScoreRange
+	var parseError error
+
+	if strings.HasPrefix(minimumArgument, "(") {
+		scoreRange.MinimumExclusive = true
+		minimumArgument = minimumArgument[1:]
+	}
+	if strings.HasPrefix(maximumArgument, "(") {
+		scoreRange.MaximumExclusive = true
+		maximumArgument = maximumArgument[1:]
+	}
+
+	if scoreRange.MinimumScore, parseError = parseSortedSetScore(minimumArgument); parseError != nil {
+		return scoreRange, false
+	}
+	if scoreRange.MaximumScore, parseError = parseSortedSetScore(maximumArgument); parseError != nil {
+		return scoreRange, false
+	}
+
+	return scoreRange, true
+}
+
+// formatSortedSetScore formate un score comme Redis (représentation la plus courte, inf/-inf)
+func formatSortedSetScore(memberScore float64) string {
+	switch {
+	case math.IsInf(memberScore, 1):
+		return "inf"
+	case math.IsInf(memberScore, -1):
+		return "-inf"
+	default:
+		return strconv.FormatFloat(memberScore, 'g', -1, 64)
+	}
+}
+
+// writeSortedSetMembers écrit une liste de membres, avec leurs scores si demandé
+// En RESP3, WITHSCORES retourne des paires [membre, score décimal] comme Redis
+func writeSortedSetMembers(protocolEncoder *protocol.RedisSerializationProtocolEncoder, sortedSetMembers []storage.SortedSetMember, withScores bool) error {
+	if withScores && protocolEncoder.IsResp3() {
+		protocolEncoder.WriteArrayHeader(len(sortedSetMembers))
+		for _, sortedSetMember := range sortedSetMembers {
+			protocolEncoder.WriteArrayHeader(2)
+			protocolEncoder.WriteBulkStringResponse(sortedSetMember.MemberName)
+			if writeError := protocolEncoder.WriteDoubleResponse(sortedSetMember.MemberScore); writeError != nil {
+				return writeError
+			}
+		}
+		return nil
+	}
+
+	responseArray := make([]string, 0, len(sortedSetMembers)*2)
+	for _, sortedSetMember := range sortedSetMembers {
+		responseArray = append(responseArray, sortedSetMember.MemberName)
+		if withScores {
+			responseArray = append(responseArray, formatSortedSetScore(sortedSetMember.MemberScore))
+		}
+	}
+
+	return protocolEncoder.WriteArrayResponse(responseArray)
+}
+
+// writeSortedSetStorageError traduit les erreurs de stockage des sorted sets en réponse RESP
+func writeSortedSetStorageError(protocolEncoder *protocol.RedisSerializationProtocolEncoder, storageError error) error {
+	switch storageError {
+	case storage.ErrWrongDataType:
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas un sorted set")
+	case storage.ErrScoreIsNotANumber:
+		return protocolEncoder.WriteErrorResponse("ERREUR : le score résultant n'est pas un nombre (NaN)")
+	default:
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : %v", storageError))
+	}
+}
@@ -0,0 +1,111 @@
+package commands
+
+import "testing"
+
+func TestSortedSetCommands(t *testing.T) {
+	testCases := []struct {
+		name      string
+		testSteps []commandTestStep
+	}{
+		{
+			name: "ZADD ajoute, met à jour et trie par score puis par membre",
+			testSteps: []commandTestStep{
+				step("ZADD z 1 a 2 b 2 c", ":3\r\n"),
+				step("ZADD z 3 a", ":0\r\n"),
+				step("ZRANGE z 0 -1", array("b", "c", "a")),
+				step("ZCARD z", ":3\r\n"),
+				step("ZSCORE z a", bulk("3")),
+				step("ZSCORE z absent", "$-1\r\n"),
+			},
+		},
+		{
+			name: "ZADD NX XX GT LT CH",
+			testSteps: []commandTestStep{
+				step("ZADD z 5 a", ":1\r\n"),
+				step("ZADD z NX 1 a 1 b", ":1\r\n"),
+				step("ZSCORE z a", bulk("5")),
+				step("ZADD z XX 7 a 1 c", ":0\r\n"),
+				step("ZSCORE z a", bulk("7")),
+				step("ZSCORE z c", "$-1\r\n"),
+				step("ZADD z GT CH 6 a 2 b", ":1\r\n"),
+				step("ZSCORE z a", bulk("7")),
+				step("ZADD z LT CH 4 a", ":1\r\n"),
+				step("ZSCORE z a", bulk("4")),
+				step("ZADD z NX XX 1 a", "-ERREUR : les options XX et NX sont incompatibles\r\n"),
+				step("ZADD z NX GT 1 a", "-ERREUR : les options GT, LT et NX sont incompatibles\r\n"),
+				step("ZADD z 1 a 2", "-ERREUR : erreur de syntaxe, les scores et membres doivent aller par paires\r\n"),
+				step("ZADD z notanumber a", "-ERREUR : le score doit être un nombre décimal valide\r\n"),
+				step("ZADD z nan a", "-ERREUR : le score doit être un nombre décimal valide\r\n"),
+			},
+		},
+		{
+			name: "ZADD INCR et ZINCRBY",
+			testSteps: []commandTestStep{
+				step("ZADD z INCR 2.5 a", bulk("2.5")),
+				step("ZINCRBY z 0.5 a", bulk("3")),
+				step("ZADD z NX INCR 1 a", "$-1\r\n"),
+				step("ZADD z INCR 1 a 2 b", "-ERREUR : INCR n'accepte qu'une seule paire score/membre\r\n"),
+				step("ZINCRBY z x a", "-ERREUR : l'incrément doit être un nombre décimal valide\r\n"),
+			},
+		},
+		{
+			name: "ZRANK ZREVRANK WITHSCORE et ZREM",
+			testSteps: []commandTestStep{
+				step("ZADD z 10 a 20 b 30 c", ":3\r\n"),
+				step("ZRANK z b", ":1\r\n"),
+				step("ZREVRANK z a", ":2\r\n"),
+				step("ZRANK z c WITHSCORE", "*2\r\n:2\r\n"+bulk("30")),
+				step("ZRANK z absent", "$-1\r\n"),
+				step("ZRANK z a WITHSCORES", "-ERREUR : option inconnue*"),
+				step("ZREM z a absent", ":1\r\n"),
+				step("ZRANK z b", ":0\r\n"),
+				step("ZREM z b c", ":2\r\n"),
+				step("EXISTS z", ":0\r\n"),
+			},
+		},
+		{
+			name: "ZRANGE par rang, inverse et avec les scores",
+			testSteps: []commandTestStep{
+				step("ZADD z 1 a 2 b 3 c 4 d", ":4\r\n"),
+				step("ZRANGE z 1 2", array("b", "c")),
+				step("ZRANGE z -2 -1 WITHSCORES", array("c", "3", "d", "4")),
+				step("ZRANGE z 0 0 REV", array("d")),
+				step("ZREVRANGE z 0 1 WITHSCORES", array("d", "4", "c", "3")),
+				step("ZRANGE z 5 10", "*0\r\n"),
+				step("ZRANGE z 0 1 LIMIT 0 1", "-ERREUR : LIMIT n'est utilisable qu'avec BYSCORE\r\n"),
+				step("ZRANGE z a 1", "-ERREUR : l'index de début doit être un nombre entier\r\n"),
+			},
+		},
+		{
+			name: "lectures par score, bornes exclusives, infinis et LIMIT",
+			testSteps: []commandTestStep{
+				step("ZADD z -inf min 1 a 2 b 3 c +inf max", ":5\r\n"),
+				step("ZRANGEBYSCORE z 1 3", array("a", "b", "c")),
+				step("ZRANGEBYSCORE z (1 3", array("b", "c")),
+				step("ZRANGEBYSCORE z (1 (3", array("b")),
+				step("ZRANGEBYSCORE z -inf +inf WITHSCORES", array("min", "-inf", "a", "1", "b", "2", "c", "3", "max", "inf")),
+				step("ZRANGEBYSCORE z 1 3 LIMIT 1 1", array("b")),
+				step("ZREVRANGEBYSCORE z 3 1 LIMIT 0 2", array("c", "b")),
+				step("ZRANGE z (3 1 BYSCORE REV", array("b", "a")),
+				step("ZRANGE z 2 +inf BYSCORE LIMIT 1 -1", array("c", "max")),
+				step("ZCOUNT z (1 +inf", ":3\r\n"),
+				step("ZCOUNT z 3 1", ":0\r\n"),
+				step("ZCOUNT z x 1", "-ERREUR : les bornes min et max doivent être des nombres décimaux\r\n"),
+			},
+		},
+		{
+			name: "mauvais type",
+			testSteps: []commandTestStep{
+				step("SET s v", "+OK\r\n"),
+				step("ZADD s 1 a", "-ERREUR : cette clé ne contient pas un sorted set\r\n"),
+				step("ZRANGE s 0 -1", "-ERREUR : cette clé ne contient pas un sorted set\r\n"),
+				step("ZSCORE s a", "-ERREUR : cette clé ne contient pas un sorted set\r\n"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			newCommandTestFixture().runSteps(t, testCase.testSteps)
+		})
+	}
+}
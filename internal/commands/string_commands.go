@@ -10,40 +10,242 @@ import (
 	"redis-go/internal/storage"
 )
 
-// handleSetCommand implémente SET key value [EX seconds]
-func (commandRegistry *RedisCommandRegistry) handleSetCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+// handleSetCommand implémente SET key value [NX|XX] [GET] [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|KEEPTTL]
+func (commandRegistry *RedisCommandRegistry) handleSetCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
 	if len(commandArguments) < 2 {
-		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SET' (attendu: SET clé valeur [EX secondes])")
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SET' (attendu: SET clé valeur [NX|XX] [GET] [EX|PX|EXAT|PXAT durée|KEEPTTL])")
 	}
 
 	storageKey := commandArguments[0]
 	storageValue := commandArguments[1]
 
-	// Parsing des options (EX pour TTL)
+	// Parsing des options dans n'importe quel ordre
+	var setOptions storage.StringSetOptions
+	expirationOptionName := ""
 	for argumentIndex := 2; argumentIndex < len(commandArguments); argumentIndex++ {
-		switch strings.ToUpper(commandArguments[argumentIndex]) {
-		case "EX":
-			if argumentIndex+1 >= len(commandArguments) {
-				return protocolEncoder.WriteErrorResponse("ERREUR : valeur manquante après 'EX'")
+		upperOption := strings.ToUpper(commandArguments[argumentIndex])
+		switch upperOption {
+		case "NX":
+			setOptions.OnlyIfNotExists = true
+		case "XX":
+			setOptions.OnlyIfExists = true
+		case "GET":
+			setOptions.ReturnPreviousValue = true
+		case "KEEPTTL":
+			if expirationOptionName != "" {
+				return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : les options %s et KEEPTTL sont incompatibles", expirationOptionName))
+			}
+			expirationOptionName = upperOption
+			setOptions.KeepExistingExpiration = true
+		case "EX", "PX", "EXAT", "PXAT":
+			if expirationOptionName != "" {
+				return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : les options %s et %s sont incompatibles", expirationOptionName, upperOption))
 			}
-			expirationSeconds, parseError := strconv.Atoi(commandArguments[argumentIndex+1])
-			if parseError != nil {
-				return protocolEncoder.WriteErrorResponse("ERREUR : la valeur après 'EX' doit être un nombre entier")
+			if argumentIndex+1 >= len(commandArguments) {
+				return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : valeur manquante après '%s'", upperOption))
 			}
-			if expirationSeconds <= 0 {
-				return protocolEncoder.WriteErrorResponse("ERREUR : le délai d'expiration doit être positif")
+			expirationTime, errorMessage := parseStringExpirationOption("SET", upperOption, commandArguments[argumentIndex+1])
+			if errorMessage != "" {
+				return nil, protocolEncoder.WriteErrorResponse(errorMessage)
 			}
-			timeToLive := time.Duration(expirationSeconds) * time.Second
-			redisStorage.SetKeyValue(storageKey, storageValue, storage.RedisStringType, &timeToLive)
-			return protocolEncoder.WriteSimpleStringResponse("OK")
+			expirationOptionName = upperOption
+			setOptions.ExpirationTime = &expirationTime
+			argumentIndex++
 		default:
-			return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : option inconnue '%s' pour SET", commandArguments[argumentIndex]))
+			return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : option inconnue '%s' pour SET", commandArguments[argumentIndex]))
 		}
 	}
 
-	// SET sans TTL
-	redisStorage.SetKeyValue(storageKey, storageValue, storage.RedisStringType, nil)
-	return protocolEncoder.WriteSimpleStringResponse("OK")
+	if setOptions.OnlyIfNotExists && setOptions.OnlyIfExists {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : les options NX et XX sont incompatibles")
+	}
+
+	setResult, setError := redisStorage.SetStringValue(storageKey, storageValue, setOptions)
+	if setError != nil {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une chaîne de caractères")
+	}
+
+	// Avec GET, on retourne l'ancienne valeur que l'écriture ait eu lieu ou non
+	if setOptions.ReturnPreviousValue {
+		var propagatedCommands []propagatedCommand
+		if setResult.ValueWasSet {
+			propagatedCommands = propagateStringSet(storageKey, storageValue, setOptions)
+		}
+		if !setResult.PreviousKeyExists {
+			return propagatedCommands, protocolEncoder.WriteNullBulkStringResponse()
+		}
+		return propagatedCommands, protocolEncoder.WriteBulkStringResponse(setResult.PreviousValue)
+	}
+
+	if !setResult.ValueWasSet {
+		return nil, protocolEncoder.WriteNullBulkStringResponse()
+	}
+	return propagateStringSet(storageKey, storageValue, setOptions), protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// propagateStringSet retourne la forme propagée d'un SET appliqué : l'expiration relative (EX/PX, SETEX, PSETEX)
+// devient la date absolue PXAT calculée à l'exécution, pour un rejeu indépendant de l'heure ; NX, XX et GET,
+// déjà satisfaits, ne sont pas propagés
+func propagateStringSet(storageKey string, storageValue string, setOptions storage.StringSetOptions) []propagatedCommand {
+	propagatedArguments := []string{storageKey, storageValue}
+	switch {
+	case setOptions.KeepExistingExpiration:
+		propagatedArguments = append(propagatedArguments, "KEEPTTL")
+	case setOptions.ExpirationTime != nil:
+		propagatedArguments = append(propagatedArguments, "PXAT", strconv.FormatInt(setOptions.ExpirationTime.UnixMilli(), 10))
+	}
+	return []propagatedCommand{{commandName: "SET", commandArguments: propagatedArguments}}
+}
+
+// parseStringExpirationOption convertit une option EX/PX/EXAT/PXAT en date d'expiration absolue
+// Retourne un message d'erreur non vide si la valeur est invalide ou hors de la plage représentable
+func parseStringExpirationOption(commandName string, upperOptionName string, optionValue string) (time.Time, string) {
+	expirationValue, parseError := strconv.ParseInt(optionValue, 10, 64)
+	if parseError != nil {
+		return time.Time{}, fmt.Sprintf("ERREUR : la valeur après '%s' doit être un nombre entier", upperOptionName)
+	}
+	if expirationValue <= 0 {
+		return time.Time{}, "ERREUR : le délai d'expiration doit être positif"
+	}
+
+	var expirationTime time.Time
+	var expirationValid bool
+	switch upperOptionName {
+	case "EX":
+		expirationTime, expirationValid = relativeExpirationTime(expirationValue, 1000)
+	case "PX":
+		expirationTime, expirationValid = relativeExpirationTime(expirationValue, 1)
+	case "EXAT":
+		expirationTime, expirationValid = absoluteExpirationTime(expirationValue, 1000)
+	default:
+		expirationTime, expirationValid = absoluteExpirationTime(expirationValue, 1)
+	}
+	if !expirationValid {
+		return time.Time{}, fmt.Sprintf("ERREUR : valeur d'expiration invalide pour '%s'", commandName)
+	}
+	return expirationTime, ""
+}
+
+// handleSetIfNotExistsCommand implémente SETNX key value
+func (commandRegistry *RedisCommandRegistry) handleSetIfNotExistsCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SETNX' (attendu: SETNX clé valeur)")
+	}
+
+	setResult, _ := redisStorage.SetStringValue(commandArguments[0], commandArguments[1], storage.StringSetOptions{OnlyIfNotExists: true})
+	if setResult.ValueWasSet {
+		return protocolEncoder.WriteIntegerResponse(1)
+	}
+	return protocolEncoder.WriteIntegerResponse(0)
+}
+
+// handleSetWithExpirationCommand implémente SETEX key seconds value
+func (commandRegistry *RedisCommandRegistry) handleSetWithExpirationCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return setStringWithRelativeExpiration("SETEX", "EX", commandArguments, redisStorage, protocolEncoder)
+}
+
+// handlePreciseSetWithExpirationCommand implémente PSETEX key milliseconds value
+func (commandRegistry *RedisCommandRegistry) handlePreciseSetWithExpirationCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	return setStringWithRelativeExpiration("PSETEX", "PX", commandArguments, redisStorage, protocolEncoder)
+}
+
+// setStringWithRelativeExpiration factorise SETEX et PSETEX
+// La commande est propagée en SET ... PXAT
+func setStringWithRelativeExpiration(commandName string, expirationOptionName string, commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	if len(commandArguments) != 3 {
+		return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : nombre d'arguments incorrect pour '%s' (attendu: %s clé durée valeur)", commandName, commandName))
+	}
+
+	expirationTime, errorMessage := parseStringExpirationOption(commandName, expirationOptionName, commandArguments[1])
+	if errorMessage != "" {
+		return nil, protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	setOptions := storage.StringSetOptions{ExpirationTime: &expirationTime}
+	redisStorage.SetStringValue(commandArguments[0], commandArguments[2], setOptions)
+	return propagateStringSet(commandArguments[0], commandArguments[2], setOptions), protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handleGetSetCommand implémente GETSET key value
+func (commandRegistry *RedisCommandRegistry) handleGetSetCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'GETSET' (attendu: GETSET clé valeur)")
+	}
+
+	setResult, setError := redisStorage.SetStringValue(commandArguments[0], commandArguments[1], storage.StringSetOptions{ReturnPreviousValue: true})
+	if setError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une chaîne de caractères")
+	}
+
+	if !setResult.PreviousKeyExists {
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+	return protocolEncoder.WriteBulkStringResponse(setResult.PreviousValue)
+}
+
+// handleGetDeleteCommand implémente GETDEL key
+func (commandRegistry *RedisCommandRegistry) handleGetDeleteCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	if len(commandArguments) != 1 {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'GETDEL' (attendu: GETDEL clé)")
+	}
+
+	// La commande est propagée en DEL si elle a supprimé la clé
+	stringValue, keyExists, getError := redisStorage.GetAndDeleteStringValue(commandArguments[0])
+	if !keyExists || getError != nil {
+		return nil, writeStringLookupResult(stringValue, keyExists, getError, protocolEncoder)
+	}
+	return []propagatedCommand{{commandName: "DEL", commandArguments: commandArguments[:1]}}, writeStringLookupResult(stringValue, keyExists, getError, protocolEncoder)
+}
+
+// handleGetExpirationCommand implémente GETEX key [EX seconds|PX milliseconds|EXAT unix-time-seconds|PXAT unix-time-milliseconds|PERSIST]
+func (commandRegistry *RedisCommandRegistry) handleGetExpirationCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	if len(commandArguments) < 1 {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'GETEX' (attendu: GETEX clé [EX|PX|EXAT|PXAT durée|PERSIST])")
+	}
+
+	var expirationTime *time.Time
+	removeExpiration := false
+	optionArguments := commandArguments[1:]
+
+	switch {
+	case len(optionArguments) == 0:
+	case len(optionArguments) == 1 && strings.ToUpper(optionArguments[0]) == "PERSIST":
+		removeExpiration = true
+	case len(optionArguments) == 2:
+		upperOption := strings.ToUpper(optionArguments[0])
+		if upperOption != "EX" && upperOption != "PX" && upperOption != "EXAT" && upperOption != "PXAT" {
+			return nil, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : option inconnue '%s' pour GETEX", optionArguments[0]))
+		}
+		parsedExpiration, errorMessage := parseStringExpirationOption("GETEX", upperOption, optionArguments[1])
+		if errorMessage != "" {
+			return nil, protocolEncoder.WriteErrorResponse(errorMessage)
+		}
+		expirationTime = &parsedExpiration
+	default:
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : syntaxe invalide pour GETEX (attendu: GETEX clé [EX|PX|EXAT|PXAT durée|PERSIST])")
+	}
+
+	stringValue, keyExists, keyDeleted, getError := redisStorage.GetStringValueAndUpdateExpiration(commandArguments[0], expirationTime, removeExpiration)
+	var propagatedCommands []propagatedCommand
+	switch {
+	case !keyExists || getError != nil:
+	case removeExpiration:
+		propagatedCommands = []propagatedCommand{{commandName: "PERSIST", commandArguments: commandArguments[:1]}}
+	case expirationTime != nil:
+		propagatedCommands = propagateKeyExpiration(commandArguments[0], *expirationTime, keyDeleted)
+	}
+	return propagatedCommands, writeStringLookupResult(stringValue, keyExists, getError, protocolEncoder)
+}
+
+// writeStringLookupResult écrit le résultat d'une lecture de chaîne (null si absente)
+func writeStringLookupResult(stringValue string, keyExists bool, lookupError error, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if lookupError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une chaîne de caractères")
+	}
+	if !keyExists {
+		return protocolEncoder.WriteNullBulkStringResponse()
+	}
+	return protocolEncoder.WriteBulkStringResponse(stringValue)
 }
 
 // handleGetCommand implémente GET key
@@ -56,7 +258,7 @@ func (commandRegistry *RedisCommandRegistry) handleGetCommand(commandArguments [
 	storageValue := redisStorage.GetKeyValue(storageKey)
 
 	if storageValue == nil {
-		return protocolEncoder.WriteBulkStringResponse("(nil)")
+		return protocolEncoder.WriteNullBulkStringResponse()
 	}
 
 	if storageValue.DataType != storage.RedisStringType {
@@ -66,6 +268,103 @@ func (commandRegistry *RedisCommandRegistry) handleGetCommand(commandArguments [
 	return protocolEncoder.WriteBulkStringResponse(storageValue.StoredData.(string))
 }
 
+// handleAppendCommand implémente APPEND key value (retourne la nouvelle longueur)
+func (commandRegistry *RedisCommandRegistry) handleAppendCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	stringLength, appendError := redisStorage.AppendStringValue(commandArguments[0], commandArguments[1])
+	if appendError != nil {
+		return writeStringStorageError(protocolEncoder, appendError)
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(stringLength))
+}
+
+// handleStringLengthCommand implémente STRLEN key (0 si la clé n'existe pas)
+func (commandRegistry *RedisCommandRegistry) handleStringLengthCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	stringLength, lengthError := redisStorage.GetStringLength(commandArguments[0])
+	if lengthError != nil {
+		return writeStringStorageError(protocolEncoder, lengthError)
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(stringLength))
+}
+
+// handleGetRangeCommand implémente GETRANGE key start end (positions incluses, négatives depuis la fin)
+func (commandRegistry *RedisCommandRegistry) handleGetRangeCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	startIndex, startError := strconv.Atoi(commandArguments[1])
+	endIndex, endError := strconv.Atoi(commandArguments[2])
+	if startError != nil || endError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : les positions doivent être des nombres entiers")
+	}
+
+	stringRange, rangeError := redisStorage.GetStringRange(commandArguments[0], startIndex, endIndex)
+	if rangeError != nil {
+		return writeStringStorageError(protocolEncoder, rangeError)
+	}
+	return protocolEncoder.WriteBulkStringResponse(stringRange)
+}
+
+// handleSetRangeCommand implémente SETRANGE key offset value (complète par des octets nuls, retourne la nouvelle longueur)
+func (commandRegistry *RedisCommandRegistry) handleSetRangeCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	writeOffset, parseError := strconv.Atoi(commandArguments[1])
+	if parseError != nil || writeOffset < 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'offset doit être un entier positif ou nul")
+	}
+
+	stringLength, setError := redisStorage.SetStringRange(commandArguments[0], writeOffset, commandArguments[2])
+	if setError != nil {
+		return writeStringStorageError(protocolEncoder, setError)
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(stringLength))
+}
+
+// handleMultipleGetCommand implémente MGET key [key ...] (null pour une clé absente ou qui n'est pas une chaîne)
+func (commandRegistry *RedisCommandRegistry) handleMultipleGetCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	stringValues, valuesFound := redisStorage.GetMultipleStringValues(commandArguments)
+
+	protocolEncoder.WriteArrayHeader(len(stringValues))
+	for keyIndex, stringValue := range stringValues {
+		if valuesFound[keyIndex] {
+			protocolEncoder.WriteBulkStringResponse(stringValue)
+		} else {
+			protocolEncoder.WriteNullBulkStringResponse()
+		}
+	}
+	return nil
+}
+
+// handleMultipleSetCommand implémente MSET key value [key value ...] (toutes les clés sont écrites atomiquement)
+func (commandRegistry *RedisCommandRegistry) handleMultipleSetCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments)%2 != 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'MSET' (attendu: MSET clé valeur [clé valeur ...])")
+	}
+
+	redisStorage.SetMultipleStringValues(commandArguments, false)
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handleMultipleSetIfNotExistsCommand implémente MSETNX key value [key value ...]
+// Retourne 1 si toutes les clés ont été écrites, 0 (et rien n'est écrit ni propagé) si l'une d'elles existe déjà
+func (commandRegistry *RedisCommandRegistry) handleMultipleSetIfNotExistsCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) ([]propagatedCommand, error) {
+	if len(commandArguments)%2 != 0 {
+		return nil, protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'MSETNX' (attendu: MSETNX clé valeur [clé valeur ...])")
+	}
+
+	if !redisStorage.SetMultipleStringValues(commandArguments, true) {
+		return nil, protocolEncoder.WriteIntegerResponse(0)
+	}
+	return []propagatedCommand{{commandName: "MSETNX", commandArguments: commandArguments}}, protocolEncoder.WriteIntegerResponse(1)
+}
+
+// writeStringStorageError traduit les erreurs de stockage des chaînes en réponse RESP
+func writeStringStorageError(protocolEncoder *protocol.RedisSerializationProtocolEncoder, storageError error) error {
+	switch storageError {
+	case storage.ErrWrongDataType:
+		return protocolEncoder.WriteErrorResponse("ERREUR : cette clé ne contient pas une chaîne de caractères")
+	case storage.ErrStringTooLong:
+		return protocolEncoder.WriteErrorResponse("ERREUR : la chaîne dépasserait la taille maximale autorisée (512 Mo)")
+	default:
+		return storageError
+	}
+}
+
 // handleDeleteCommand implémente DEL key [key ...]
 func (commandRegistry *RedisCommandRegistry) handleDeleteCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
 	if len(commandArguments) == 0 {
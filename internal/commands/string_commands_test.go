@@ -0,0 +1,255 @@
+package commands
+
+import "testing"
+
+func TestSetCommandOptions(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "NX et XX",
+			testSteps: []commandTestStep{
+				step("SET k v1 XX", "$-1\r\n"),
+				step("SET k v1 NX", "+OK\r\n"),
+				step("SET k v2 NX", "$-1\r\n"),
+				step("SET k v3 xx", "+OK\r\n"),
+				step("GET k", bulk("v3")),
+				step("SET k v NX XX", "-ERREUR : les options NX et XX sont incompatibles\r\n"),
+			},
+			expectedPropagated: []string{"0 SET k v1", "0 SET k v3"},
+		},
+		{
+			name: "options dans n'importe quel ordre, expiration propagée en PXAT",
+			testSteps: []commandTestStep{
+				step("SET k v PXAT 4102444800123 NX", "+OK\r\n"),
+				step("PEXPIRETIME k", ":4102444800123\r\n"),
+				step("SET k w EXAT 4102444800", "+OK\r\n"),
+				step("PEXPIRETIME k", ":4102444800000\r\n"),
+				step("SET k x EX 100", "+OK\r\n"),
+				step("TTL k", ":100\r\n"),
+				step("SET k y KEEPTTL", "+OK\r\n"),
+				step("TTL k", ":100\r\n"),
+				step("SET k z", "+OK\r\n"),
+				step("TTL k", ":-1\r\n"),
+			},
+		},
+		{
+			name: "GET retourne l'ancienne valeur même si l'écriture n'a pas lieu",
+			testSteps: []commandTestStep{
+				step("SET k v GET", "$-1\r\n"),
+				step("SET k w GET", bulk("v")),
+				step("SET k x NX GET", bulk("w")),
+				step("GET k", bulk("w")),
+				step("SET absent x XX GET", "$-1\r\n"),
+				step("EXISTS absent", ":0\r\n"),
+				step("RPUSH l a", ":1\r\n"),
+				step("SET l v GET", "-ERREUR : cette clé ne contient pas une chaîne de caractères\r\n"),
+			},
+			expectedPropagated: []string{"0 SET k v", "0 SET k w", "0 RPUSH l a"},
+		},
+		{
+			name: "options invalides",
+			testSteps: []commandTestStep{
+				step("SET k v EX", "-ERREUR : valeur manquante après 'EX'\r\n"),
+				step("SET k v EX 0", "-ERREUR : le délai d'expiration doit être positif\r\n"),
+				step("SET k v PX dix", "-ERREUR : la valeur après 'PX' doit être un nombre entier\r\n"),
+				step("SET k v EX 10 PX 10", "-ERREUR : les options EX et PX sont incompatibles\r\n"),
+				step("SET k v EX 10 KEEPTTL", "-ERREUR : les options EX et KEEPTTL sont incompatibles\r\n"),
+				step("SET k v FOREVER", "-ERREUR : option inconnue 'FOREVER' pour SET\r\n"),
+				step("EXISTS k", ":0\r\n"),
+			},
+			expectedPropagated: []string{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+		})
+	}
+}
+
+func TestSetVariantCommands(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+	}{
+		{
+			name: "SETNX SETEX PSETEX",
+			testSteps: []commandTestStep{
+				step("SETNX k v", ":1\r\n"),
+				step("SETNX k w", ":0\r\n"),
+				step("SETEX k 100 w", "+OK\r\n"),
+				step("TTL k", ":100\r\n"),
+				step("GET k", bulk("w")),
+				step("PSETEX k 100000 x", "+OK\r\n"),
+				step("TTL k", ":100\r\n"),
+				step("SETEX k 0 v", "-ERREUR : le délai d'expiration doit être positif\r\n"),
+				step("SETEX k 10", "-ERREUR : nombre d'arguments incorrect pour 'SETEX' (attendu: SETEX clé durée valeur)\r\n"),
+			},
+		},
+		{
+			name: "GETSET GETDEL",
+			testSteps: []commandTestStep{
+				step("GETSET k v", "$-1\r\n"),
+				step("GETSET k w", bulk("v")),
+				step("GETDEL k", bulk("w")),
+				step("GETDEL k", "$-1\r\n"),
+				step("EXISTS k", ":0\r\n"),
+			},
+			expectedPropagated: []string{"0 GETSET k v", "0 GETSET k w", "0 DEL k"},
+		},
+		{
+			name: "GETEX",
+			testSteps: []commandTestStep{
+				step("SET k v", "+OK\r\n"),
+				step("GETEX k", bulk("v")),
+				step("GETEX k PXAT 4102444800123", bulk("v")),
+				step("PEXPIRETIME k", ":4102444800123\r\n"),
+				step("GETEX k PERSIST", bulk("v")),
+				step("TTL k", ":-1\r\n"),
+				step("GETEX absent EX 100", "$-1\r\n"),
+				step("GETEX k EX", "-ERREUR : syntaxe invalide pour GETEX*"),
+				step("GETEX k KEEPTTL 1", "-ERREUR : option inconnue 'KEEPTTL' pour GETEX\r\n"),
+			},
+			expectedPropagated: []string{"0 SET k v", "0 PEXPIREAT k 4102444800123", "0 PERSIST k"},
+		},
+		{
+			name: "délais hors de la plage représentable refusés sans toucher la clé",
+			testSteps: []commandTestStep{
+				step("SET k v", "+OK\r\n"),
+				step("SET k w EX 9223372036854776", "-ERREUR : valeur d'expiration invalide pour 'SET'\r\n"),
+				step("SET k w EX 9223372036854775", "-ERREUR : valeur d'expiration invalide pour 'SET'\r\n"),
+				step("SET k w PX 9223372036854775807", "-ERREUR : valeur d'expiration invalide pour 'SET'\r\n"),
+				step("SET k w EXAT 9223372036854776", "-ERREUR : valeur d'expiration invalide pour 'SET'\r\n"),
+				step("SETEX k 9223372036854775807 w", "-ERREUR : valeur d'expiration invalide pour 'SETEX'\r\n"),
+				step("PSETEX k 9223372036854775807 w", "-ERREUR : valeur d'expiration invalide pour 'PSETEX'\r\n"),
+				step("GETEX k EX 9223372036854775807", "-ERREUR : valeur d'expiration invalide pour 'GETEX'\r\n"),
+				step("GETEX k PX 9223372036854775807", "-ERREUR : valeur d'expiration invalide pour 'GETEX'\r\n"),
+				step("GET k", bulk("v")),
+				step("TTL k", ":-1\r\n"),
+				step("SETEX k 10000000000 x", "+OK\r\n"),
+				step("TTL k", ":10000000000\r\n"),
+			},
+		},
+		{
+			name: "limites absolues propagées telles quelles",
+			testSteps: []commandTestStep{
+				step("SET k w EXAT 9223372036854775", "+OK\r\n"),
+				step("GETEX k PXAT 9223372036854775807", bulk("w")),
+				step("PEXPIRETIME k", ":9223372036854775807\r\n"),
+			},
+			expectedPropagated: []string{"0 SET k w PXAT 9223372036854775000", "0 PEXPIREAT k 9223372036854775807"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+		})
+	}
+}
+
+func TestStringManipulationCommands(t *testing.T) {
+	testCases := []struct {
+		name               string
+		testSteps          []commandTestStep
+		expectedPropagated []string
+		// expectedWriteCount est le nombre d'écritures comptées, vérifié s'il n'est pas nul
+		expectedWriteCount int64
+	}{
+		{
+			name: "APPEND et STRLEN conservent l'expiration",
+			testSteps: []commandTestStep{
+				step("APPEND k Hello", ":5\r\n"),
+				step("EXPIRE k 100", ":1\r\n"),
+				{commandArguments: []string{"APPEND", "k", " World"}, expectedReply: ":11\r\n"},
+				step("STRLEN k", ":11\r\n"),
+				step("TTL k", ":100\r\n"),
+				step("STRLEN absente", ":0\r\n"),
+			},
+		},
+		{
+			name: "GETRANGE avec positions négatives",
+			testSteps: []commandTestStep{
+				step("SET k Hello", "+OK\r\n"),
+				step("GETRANGE k 1 3", bulk("ell")),
+				step("GETRANGE k -3 -1", bulk("llo")),
+				step("GETRANGE k 0 100", bulk("Hello")),
+				step("GETRANGE k 0 -100", bulk("H")),
+				step("GETRANGE k 3 1", bulk("")),
+				step("GETRANGE absente 0 -1", bulk("")),
+				step("GETRANGE k un 2", "-ERREUR : les positions doivent être des nombres entiers\r\n"),
+			},
+		},
+		{
+			name: "SETRANGE complète par des octets nuls",
+			testSteps: []commandTestStep{
+				step("SETRANGE k 3 ab", ":5\r\n"),
+				step("GET k", bulk("\x00\x00\x00ab")),
+				step("SETRANGE k 1 XY", ":5\r\n"),
+				step("GET k", bulk("\x00XYab")),
+				{commandArguments: []string{"SETRANGE", "k", "10", ""}, expectedReply: ":5\r\n"},
+				{commandArguments: []string{"SETRANGE", "absente", "5", ""}, expectedReply: ":0\r\n"},
+				step("EXISTS absente", ":0\r\n"),
+				step("SETRANGE k -1 a", "-ERREUR : l'offset doit être un entier positif ou nul\r\n"),
+				step("SETRANGE k 536870912 a", "-ERREUR : la chaîne dépasserait la taille maximale autorisée (512 Mo)\r\n"),
+			},
+			expectedPropagated: []string{"0 SETRANGE k 3 ab", "0 SETRANGE k 1 XY", "0 SETRANGE k 10 ", "0 SETRANGE absente 5 "},
+		},
+		{
+			name: "MGET MSET MSETNX",
+			testSteps: []commandTestStep{
+				step("MSET a 1 b 2", "+OK\r\n"),
+				step("RPUSH liste x", ":1\r\n"),
+				step("MGET a absente liste b", "*4\r\n"+bulk("1")+"$-1\r\n$-1\r\n"+bulk("2")),
+				step("EXPIREAT a 4102444800", ":1\r\n"),
+				step("MSET a 3 liste 4", "+OK\r\n"),
+				step("TTL a", ":-1\r\n"),
+				step("TYPE liste", "+string\r\n"),
+				step("MSETNX c 5 a 6", ":0\r\n"),
+				step("EXISTS c", ":0\r\n"),
+				step("MSETNX c 5 d 6", ":1\r\n"),
+				step("MGET a c d", array("3", "5", "6")),
+				step("MSET a", "-ERREUR : nombre d'arguments incorrect pour 'MSET'*"),
+				step("MSETNX a 1 b", "-ERREUR : nombre d'arguments incorrect pour 'MSETNX'*"),
+			},
+			expectedPropagated: []string{"0 MSET a 1 b 2", "0 RPUSH liste x", "0 PEXPIREAT a 4102444800000", "0 MSET a 3 liste 4", "0 MSETNX c 5 d 6"},
+			expectedWriteCount: 5,
+		},
+		{
+			name: "clé d'un autre type",
+			testSteps: []commandTestStep{
+				step("RPUSH liste x", ":1\r\n"),
+				step("APPEND liste a", "-ERREUR : cette clé ne contient pas une chaîne de caractères\r\n"),
+				step("STRLEN liste", "-ERREUR : cette clé ne contient pas une chaîne de caractères\r\n"),
+				step("GETRANGE liste 0 -1", "-ERREUR : cette clé ne contient pas une chaîne de caractères\r\n"),
+				step("SETRANGE liste 0 a", "-ERREUR : cette clé ne contient pas une chaîne de caractères\r\n"),
+				step("LLEN liste", ":1\r\n"),
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testFixture := newCommandTestFixture()
+			testFixture.runSteps(t, testCase.testSteps)
+			if testCase.expectedPropagated != nil {
+				testFixture.expectPropagated(t, testCase.expectedPropagated...)
+			}
+			if testCase.expectedWriteCount != 0 {
+				if writeCount := testFixture.commandRegistry.GetWriteCommandCount(); writeCount != testCase.expectedWriteCount {
+					t.Fatalf("%d écritures comptées, attendu %d", writeCount, testCase.expectedWriteCount)
+				}
+			}
+		})
+	}
+}
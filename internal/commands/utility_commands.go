@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"fmt"
 	"strings"
 
 	"redis-go/internal/protocol"
@@ -34,21 +35,33 @@ func (commandRegistry *RedisCommandRegistry) handleDatabaseSizeCommand(commandAr
 	return protocolEncoder.WriteIntegerResponse(int64(redisStorage.GetStorageSize()))
 }
 
-// handleFlushAllCommand implémente FLUSHALL
-func (commandRegistry *RedisCommandRegistry) handleFlushAllCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
-	if len(commandArguments) != 0 {
-		return protocolEncoder.WriteErrorResponse("ERREUR : FLUSHALL ne prend aucun argument")
+// handleFlushDatabaseCommand implémente FLUSHDB [ASYNC|SYNC] (base courante uniquement)
+func (commandRegistry *RedisCommandRegistry) handleFlushDatabaseCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if errorMessage := ValidateFlushArguments("FLUSHDB", commandArguments); errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
 	}
 
 	redisStorage.FlushAllKeys()
 	return protocolEncoder.WriteSimpleStringResponse("OK")
 }
 
+// ValidateFlushArguments vérifie l'option ASYNC|SYNC de FLUSHDB et FLUSHALL
+// (le vidage est toujours synchrone), retourne un message d'erreur vide si les arguments sont valides
+func ValidateFlushArguments(commandName string, commandArguments []string) string {
+	if len(commandArguments) > 1 {
+		return fmt.Sprintf("ERREUR : nombre d'arguments incorrect pour '%s' (attendu: %s [ASYNC|SYNC])", commandName, commandName)
+	}
+	if len(commandArguments) == 1 && !strings.EqualFold(commandArguments[0], "ASYNC") && !strings.EqualFold(commandArguments[0], "SYNC") {
+		return fmt.Sprintf("ERREUR : %s accepte seulement ASYNC ou SYNC", commandName)
+	}
+	return ""
+}
+
 // handleHelpCommand implémente ALAIDE [commande] - Version simple et efficace
 func (commandRegistry *RedisCommandRegistry) handleHelpCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
 	if len(commandArguments) == 0 {
 		// Liste toutes les commandes séparées par des virgules
-		return protocolEncoder.WriteSimpleStringResponse("ALAIDE Redis-Go: SET, GET, DEL, EXISTS, TYPE, INCR, DECR, INCRBY, DECRBY, LPUSH, RPUSH, LPOP, RPOP, LLEN, LRANGE, SADD, SMEMBERS, SISMEMBER, HSET, HGET, HGETALL, PING, ECHO, KEYS, DBSIZE, FLUSHALL - Tapez ALAIDE <commande> pour details")
+		return protocolEncoder.WriteSimpleStringResponse("ALAIDE Redis-Go: SET, SETNX, SETEX, PSETEX, GET, GETSET, GETDEL, GETEX, APPEND, STRLEN, GETRANGE, SETRANGE, MGET, MSET, MSETNX, DEL, EXISTS, TYPE, INCR, DECR, INCRBY, DECRBY, INCRBYFLOAT, LPUSH, RPUSH, LPOP, RPOP, LLEN, LRANGE, LPUSHX, RPUSHX, LINDEX, LSET, LINSERT, LREM, LTRIM, LPOS, LMOVE, RPOPLPUSH, BLPOP, BRPOP, BLMOVE, BRPOPLPUSH, SADD, SMEMBERS, SISMEMBER, SSCAN, SREM, SCARD, SPOP, SRANDMEMBER, SMOVE, SMISMEMBER, SINTERCARD, SINTER, SUNION, SDIFF, SINTERSTORE, SUNIONSTORE, SDIFFSTORE, HSET, HGET, HGETALL, HSCAN, HDEL, HEXISTS, HLEN, HKEYS, HVALS, HMGET, HINCRBY, HINCRBYFLOAT, HSETNX, HSTRLEN, HRANDFIELD, HEXPIRE, HPEXPIRE, HEXPIREAT, HPEXPIREAT, HTTL, HPTTL, HPERSIST, ZADD, ZREM, ZSCORE, ZINCRBY, ZCARD, ZRANK, ZREVRANK, ZRANGE, ZREVRANGE, ZRANGEBYSCORE, ZREVRANGEBYSCORE, ZCOUNT, ZSCAN, EXPIRE, PEXPIRE, EXPIREAT, PEXPIREAT, TTL, PTTL, EXPIRETIME, PEXPIRETIME, PERSIST, MULTI, EXEC, DISCARD, WATCH, UNWATCH, SUBSCRIBE, UNSUBSCRIBE, PSUBSCRIBE, PUNSUBSCRIBE, PUBLISH, PUBSUB, EVAL, EVALSHA, SCRIPT, PING, HELLO, AUTH, ACL, ECHO, SELECT, MOVE, SWAPDB, KEYS, SCAN, DBSIZE, FLUSHDB, FLUSHALL, REPLICAOF, ROLE, INFO, CLIENT - Tapez ALAIDE <commande> pour details")
 	}
 
 	// Aide détaillée pour une commande spécifique
@@ -56,15 +69,41 @@ func (commandRegistry *RedisCommandRegistry) handleHelpCommand(commandArguments
 
 	switch requestedCommand {
 	case "SET":
-		return protocolEncoder.WriteSimpleStringResponse("SET key value [EX seconds] - Stocke une valeur avec TTL optionnel en secondes")
+		return protocolEncoder.WriteSimpleStringResponse("SET key value [NX|XX] [GET] [EX seconds|PX milliseconds|EXAT timestamp|PXAT timestamp-ms|KEEPTTL] - Stocke une valeur avec conditions et TTL optionnels")
+	case "SETNX":
+		return protocolEncoder.WriteSimpleStringResponse("SETNX key value - Stocke une valeur seulement si la cle n'existe pas (1 si ecrite, 0 sinon)")
+	case "SETEX":
+		return protocolEncoder.WriteSimpleStringResponse("SETEX key seconds value - Stocke une valeur avec un TTL en secondes")
+	case "PSETEX":
+		return protocolEncoder.WriteSimpleStringResponse("PSETEX key milliseconds value - Stocke une valeur avec un TTL en millisecondes")
+	case "GETSET":
+		return protocolEncoder.WriteSimpleStringResponse("GETSET key value - Remplace la valeur et retourne l'ancienne")
+	case "GETDEL":
+		return protocolEncoder.WriteSimpleStringResponse("GETDEL key - Retourne la valeur puis supprime la cle")
+	case "GETEX":
+		return protocolEncoder.WriteSimpleStringResponse("GETEX key [EX seconds|PX milliseconds|EXAT timestamp|PXAT timestamp-ms|PERSIST] - Retourne la valeur et modifie son expiration")
 	case "GET":
 		return protocolEncoder.WriteSimpleStringResponse("GET key - Recupere une valeur. Retourne (nil) si la cle n'existe pas")
+	case "APPEND":
+		return protocolEncoder.WriteSimpleStringResponse("APPEND key value - Ajoute a la fin de la chaine et retourne sa nouvelle longueur")
+	case "STRLEN":
+		return protocolEncoder.WriteSimpleStringResponse("STRLEN key - Retourne la longueur de la chaine (0 si la cle n'existe pas)")
+	case "GETRANGE":
+		return protocolEncoder.WriteSimpleStringResponse("GETRANGE key start end - Retourne une sous-chaine (positions incluses, negatives depuis la fin)")
+	case "SETRANGE":
+		return protocolEncoder.WriteSimpleStringResponse("SETRANGE key offset value - Ecrit a partir de offset (complete par des octets nuls) et retourne la nouvelle longueur")
+	case "MGET":
+		return protocolEncoder.WriteSimpleStringResponse("MGET key [key ...] - Recupere plusieurs valeurs (nil si absente)")
+	case "MSET":
+		return protocolEncoder.WriteSimpleStringResponse("MSET key value [key value ...] - Stocke plusieurs valeurs atomiquement")
+	case "MSETNX":
+		return protocolEncoder.WriteSimpleStringResponse("MSETNX key value [key value ...] - Stocke plusieurs valeurs seulement si aucune cle n'existe")
 	case "DEL":
 		return protocolEncoder.WriteSimpleStringResponse("DEL key [key ...] - Supprime une ou plusieurs cles")
 	case "EXISTS":
 		return protocolEncoder.WriteSimpleStringResponse("EXISTS key [key ...] - Verifie l'existence de cles")
 	case "TYPE":
-		return protocolEncoder.WriteSimpleStringResponse("TYPE key - Retourne le type de donnees (string, list, set, hash, none)")
+		return protocolEncoder.WriteSimpleStringResponse("TYPE key - Retourne le type de donnees (string, list, set, hash, zset, none)")
 	case "INCR":
 		return protocolEncoder.WriteSimpleStringResponse("INCR key - Incremente un compteur de 1")
 	case "DECR":
@@ -73,40 +112,220 @@ func (commandRegistry *RedisCommandRegistry) handleHelpCommand(commandArguments
 		return protocolEncoder.WriteSimpleStringResponse("INCRBY key increment - Incremente un compteur par la valeur donnee")
 	case "DECRBY":
 		return protocolEncoder.WriteSimpleStringResponse("DECRBY key decrement - Decremente un compteur par la valeur donnee")
+	case "INCRBYFLOAT":
+		return protocolEncoder.WriteSimpleStringResponse("INCRBYFLOAT key increment - Incremente d'un nombre decimal (resultat sans exposant ni zeros superflus)")
 	case "LPUSH":
 		return protocolEncoder.WriteSimpleStringResponse("LPUSH key element [element ...] - Ajoute des elements au debut de la liste")
 	case "RPUSH":
 		return protocolEncoder.WriteSimpleStringResponse("RPUSH key element [element ...] - Ajoute des elements a la fin de la liste")
 	case "LPOP":
-		return protocolEncoder.WriteSimpleStringResponse("LPOP key - Retire et retourne le premier element de la liste")
+		return protocolEncoder.WriteSimpleStringResponse("LPOP key [count] - Retire et retourne le premier element (ou count elements) de la liste")
 	case "RPOP":
-		return protocolEncoder.WriteSimpleStringResponse("RPOP key - Retire et retourne le dernier element de la liste")
+		return protocolEncoder.WriteSimpleStringResponse("RPOP key [count] - Retire et retourne le dernier element (ou count elements) de la liste")
 	case "LLEN":
 		return protocolEncoder.WriteSimpleStringResponse("LLEN key - Retourne la longueur de la liste")
 	case "LRANGE":
 		return protocolEncoder.WriteSimpleStringResponse("LRANGE key start stop - Retourne une partie de la liste (indices, -1 = dernier)")
+	case "LPUSHX":
+		return protocolEncoder.WriteSimpleStringResponse("LPUSHX key element [element ...] - Ajoute au debut seulement si la liste existe")
+	case "RPUSHX":
+		return protocolEncoder.WriteSimpleStringResponse("RPUSHX key element [element ...] - Ajoute a la fin seulement si la liste existe")
+	case "LINDEX":
+		return protocolEncoder.WriteSimpleStringResponse("LINDEX key index - Retourne l'element a l'index donne (-1 = dernier)")
+	case "LSET":
+		return protocolEncoder.WriteSimpleStringResponse("LSET key index element - Remplace l'element a l'index donne")
+	case "LINSERT":
+		return protocolEncoder.WriteSimpleStringResponse("LINSERT key BEFORE|AFTER pivot element - Insere un element avant ou apres le pivot (-1 si pivot absent)")
+	case "LREM":
+		return protocolEncoder.WriteSimpleStringResponse("LREM key count element - Supprime count occurrences (>0 depuis le debut, <0 depuis la fin, 0 toutes)")
+	case "LTRIM":
+		return protocolEncoder.WriteSimpleStringResponse("LTRIM key start stop - Ne conserve que les elements entre start et stop")
+	case "LPOS":
+		return protocolEncoder.WriteSimpleStringResponse("LPOS key element [RANK rank] [COUNT num] [MAXLEN len] - Retourne la position d'un element")
+	case "LMOVE":
+		return protocolEncoder.WriteSimpleStringResponse("LMOVE source destination LEFT|RIGHT LEFT|RIGHT - Deplace un element d'une liste a une autre de maniere atomique")
+	case "RPOPLPUSH":
+		return protocolEncoder.WriteSimpleStringResponse("RPOPLPUSH source destination - Deplace le dernier element de source au debut de destination")
+	case "BLPOP":
+		return protocolEncoder.WriteSimpleStringResponse("BLPOP key [key ...] timeout - Retire le premier element de la premiere liste non vide, attend jusqu'a timeout secondes (0 = indefiniment)")
+	case "BRPOP":
+		return protocolEncoder.WriteSimpleStringResponse("BRPOP key [key ...] timeout - Retire le dernier element de la premiere liste non vide, attend jusqu'a timeout secondes (0 = indefiniment)")
+	case "BLMOVE":
+		return protocolEncoder.WriteSimpleStringResponse("BLMOVE source destination LEFT|RIGHT LEFT|RIGHT timeout - Deplace un element vers une autre liste, attend si la source est vide")
+	case "BRPOPLPUSH":
+		return protocolEncoder.WriteSimpleStringResponse("BRPOPLPUSH source destination timeout - Deplace le dernier element de source au debut de destination, attend si la source est vide")
 	case "SADD":
 		return protocolEncoder.WriteSimpleStringResponse("SADD key member [member ...] - Ajoute des membres uniques a un set")
 	case "SMEMBERS":
 		return protocolEncoder.WriteSimpleStringResponse("SMEMBERS key - Retourne tous les membres d'un set")
 	case "SISMEMBER":
 		return protocolEncoder.WriteSimpleStringResponse("SISMEMBER key member - Teste si un membre appartient au set (retourne 1 ou 0)")
+	case "SMISMEMBER":
+		return protocolEncoder.WriteSimpleStringResponse("SMISMEMBER key member [member ...] - Teste l'appartenance de plusieurs membres (1 ou 0 pour chacun)")
+	case "SREM":
+		return protocolEncoder.WriteSimpleStringResponse("SREM key member [member ...] - Supprime des membres d'un set")
+	case "SCARD":
+		return protocolEncoder.WriteSimpleStringResponse("SCARD key - Retourne le nombre de membres d'un set")
+	case "SPOP":
+		return protocolEncoder.WriteSimpleStringResponse("SPOP key [count] - Retire et retourne des membres tires au hasard")
+	case "SRANDMEMBER":
+		return protocolEncoder.WriteSimpleStringResponse("SRANDMEMBER key [count] - Retourne des membres au hasard (count negatif : repetitions possibles)")
+	case "SMOVE":
+		return protocolEncoder.WriteSimpleStringResponse("SMOVE source destination member - Deplace un membre d'un set a un autre")
+	case "SINTER":
+		return protocolEncoder.WriteSimpleStringResponse("SINTER key [key ...] - Retourne l'intersection des sets")
+	case "SINTERCARD":
+		return protocolEncoder.WriteSimpleStringResponse("SINTERCARD numkeys key [key ...] [LIMIT limit] - Retourne la taille de l'intersection des sets")
+	case "SUNION":
+		return protocolEncoder.WriteSimpleStringResponse("SUNION key [key ...] - Retourne l'union des sets")
+	case "SDIFF":
+		return protocolEncoder.WriteSimpleStringResponse("SDIFF key [key ...] - Retourne les membres du premier set absents des suivants")
+	case "SINTERSTORE", "SUNIONSTORE", "SDIFFSTORE":
+		return protocolEncoder.WriteSimpleStringResponse(requestedCommand + " destination key [key ...] - Enregistre le resultat de l'operation dans destination et retourne sa taille")
 	case "HSET":
 		return protocolEncoder.WriteSimpleStringResponse("HSET key field value [field value ...] - Definit des champs dans un hash")
 	case "HGET":
 		return protocolEncoder.WriteSimpleStringResponse("HGET key field - Recupere la valeur d'un champ dans un hash")
 	case "HGETALL":
 		return protocolEncoder.WriteSimpleStringResponse("HGETALL key - Retourne tous les champs et valeurs d'un hash")
+	case "HDEL":
+		return protocolEncoder.WriteSimpleStringResponse("HDEL key field [field ...] - Supprime des champs d'un hash (la cle disparait avec son dernier champ)")
+	case "HEXISTS":
+		return protocolEncoder.WriteSimpleStringResponse("HEXISTS key field - Retourne 1 si le champ existe, 0 sinon")
+	case "HLEN":
+		return protocolEncoder.WriteSimpleStringResponse("HLEN key - Retourne le nombre de champs d'un hash")
+	case "HKEYS":
+		return protocolEncoder.WriteSimpleStringResponse("HKEYS key - Retourne les noms des champs d'un hash")
+	case "HVALS":
+		return protocolEncoder.WriteSimpleStringResponse("HVALS key - Retourne les valeurs des champs d'un hash")
+	case "HMGET":
+		return protocolEncoder.WriteSimpleStringResponse("HMGET key field [field ...] - Retourne la valeur de plusieurs champs (nil si absent)")
+	case "HINCRBY":
+		return protocolEncoder.WriteSimpleStringResponse("HINCRBY key field increment - Incremente un champ entier")
+	case "HINCRBYFLOAT":
+		return protocolEncoder.WriteSimpleStringResponse("HINCRBYFLOAT key field increment - Incremente un champ d'un nombre decimal")
+	case "HSETNX":
+		return protocolEncoder.WriteSimpleStringResponse("HSETNX key field value - Definit un champ seulement s'il n'existe pas")
+	case "HSTRLEN":
+		return protocolEncoder.WriteSimpleStringResponse("HSTRLEN key field - Retourne la longueur de la valeur d'un champ")
+	case "HRANDFIELD":
+		return protocolEncoder.WriteSimpleStringResponse("HRANDFIELD key [count [WITHVALUES]] - Retourne des champs au hasard (count negatif : repetitions)")
+	case "HEXPIRE", "HPEXPIRE", "HEXPIREAT", "HPEXPIREAT":
+		return protocolEncoder.WriteSimpleStringResponse(requestedCommand + " key valeur [NX|XX|GT|LT] FIELDS numfields field [field ...] - Definit l'expiration de champs d'un hash (-2 absent, 0 condition non remplie, 1 applique, 2 supprime)")
+	case "HTTL", "HPTTL":
+		return protocolEncoder.WriteSimpleStringResponse(requestedCommand + " key FIELDS numfields field [field ...] - Retourne le temps restant de champs d'un hash (-2 absent, -1 sans expiration)")
+	case "HPERSIST":
+		return protocolEncoder.WriteSimpleStringResponse("HPERSIST key FIELDS numfields field [field ...] - Supprime l'expiration de champs d'un hash (-2 absent, -1 sans expiration, 1 retiree)")
+	case "ZADD":
+		return protocolEncoder.WriteSimpleStringResponse("ZADD key [NX|XX] [GT|LT] [CH] [INCR] score member [score member ...] - Ajoute des membres avec score a un sorted set")
+	case "ZREM":
+		return protocolEncoder.WriteSimpleStringResponse("ZREM key member [member ...] - Supprime des membres d'un sorted set")
+	case "ZSCORE":
+		return protocolEncoder.WriteSimpleStringResponse("ZSCORE key member - Retourne le score d'un membre")
+	case "ZINCRBY":
+		return protocolEncoder.WriteSimpleStringResponse("ZINCRBY key increment member - Incremente le score d'un membre")
+	case "ZCARD":
+		return protocolEncoder.WriteSimpleStringResponse("ZCARD key - Retourne le nombre de membres d'un sorted set")
+	case "ZRANK":
+		return protocolEncoder.WriteSimpleStringResponse("ZRANK key member [WITHSCORE] - Retourne le rang d'un membre (score croissant)")
+	case "ZREVRANK":
+		return protocolEncoder.WriteSimpleStringResponse("ZREVRANK key member [WITHSCORE] - Retourne le rang d'un membre (score decroissant)")
+	case "ZRANGE":
+		return protocolEncoder.WriteSimpleStringResponse("ZRANGE key start stop [BYSCORE] [REV] [LIMIT offset count] [WITHSCORES] - Retourne des membres par rang ou par score")
+	case "ZREVRANGE":
+		return protocolEncoder.WriteSimpleStringResponse("ZREVRANGE key start stop [WITHSCORES] - Retourne des membres par rang decroissant")
+	case "ZRANGEBYSCORE":
+		return protocolEncoder.WriteSimpleStringResponse("ZRANGEBYSCORE key min max [WITHSCORES] [LIMIT offset count] - Retourne les membres dans un intervalle de scores ('(' = exclusif)")
+	case "ZREVRANGEBYSCORE":
+		return protocolEncoder.WriteSimpleStringResponse("ZREVRANGEBYSCORE key max min [WITHSCORES] [LIMIT offset count] - Comme ZRANGEBYSCORE en ordre decroissant")
+	case "ZCOUNT":
+		return protocolEncoder.WriteSimpleStringResponse("ZCOUNT key min max - Compte les membres dans un intervalle de scores")
+	case "EXPIRE":
+		return protocolEncoder.WriteSimpleStringResponse("EXPIRE key seconds [NX|XX|GT|LT] - Definit une expiration en secondes sur une cle de tout type")
+	case "PEXPIRE":
+		return protocolEncoder.WriteSimpleStringResponse("PEXPIRE key milliseconds [NX|XX|GT|LT] - Definit une expiration en millisecondes")
+	case "EXPIREAT":
+		return protocolEncoder.WriteSimpleStringResponse("EXPIREAT key unix-time-seconds [NX|XX|GT|LT] - Definit une date d'expiration absolue en secondes")
+	case "PEXPIREAT":
+		return protocolEncoder.WriteSimpleStringResponse("PEXPIREAT key unix-time-milliseconds [NX|XX|GT|LT] - Definit une date d'expiration absolue en millisecondes")
+	case "TTL":
+		return protocolEncoder.WriteSimpleStringResponse("TTL key - Retourne le temps restant en secondes (-1 sans expiration, -2 si absente)")
+	case "PTTL":
+		return protocolEncoder.WriteSimpleStringResponse("PTTL key - Retourne le temps restant en millisecondes (-1 sans expiration, -2 si absente)")
+	case "EXPIRETIME":
+		return protocolEncoder.WriteSimpleStringResponse("EXPIRETIME key - Retourne la date d'expiration unix en secondes (-1 sans expiration, -2 si absente)")
+	case "PEXPIRETIME":
+		return protocolEncoder.WriteSimpleStringResponse("PEXPIRETIME key - Retourne la date d'expiration unix en millisecondes (-1 sans expiration, -2 si absente)")
+	case "PERSIST":
+		return protocolEncoder.WriteSimpleStringResponse("PERSIST key - Supprime l'expiration d'une cle")
+	case "HELLO":
+		return protocolEncoder.WriteSimpleStringResponse("HELLO [2|3] - Negocie la version du protocole (RESP3: maps, sets, doubles, messages push) et decrit le serveur")
 	case "PING":
 		return protocolEncoder.WriteSimpleStringResponse("PING [message] - Test de connexion. Retourne PONG ou le message")
 	case "ECHO":
 		return protocolEncoder.WriteSimpleStringResponse("ECHO message - Retourne le message tel quel")
 	case "KEYS":
 		return protocolEncoder.WriteSimpleStringResponse("KEYS pattern - Recherche des cles par motif (* = tout, ? = 1 char, [abc] = choix)")
+	case "SCAN":
+		return protocolEncoder.WriteSimpleStringResponse("SCAN cursor [MATCH pattern] [COUNT count] [TYPE type] - Parcourt les cles par curseur sans bloquer le serveur (curseur 0 = fin)")
+	case "SSCAN":
+		return protocolEncoder.WriteSimpleStringResponse("SSCAN key cursor [MATCH pattern] [COUNT count] - Parcourt les membres d'un set par curseur")
+	case "HSCAN":
+		return protocolEncoder.WriteSimpleStringResponse("HSCAN key cursor [MATCH pattern] [COUNT count] [NOVALUES] - Parcourt les fields (et valeurs) d'un hash par curseur")
+	case "ZSCAN":
+		return protocolEncoder.WriteSimpleStringResponse("ZSCAN key cursor [MATCH pattern] [COUNT count] - Parcourt les membres et scores d'un sorted set par curseur")
 	case "DBSIZE":
 		return protocolEncoder.WriteSimpleStringResponse("DBSIZE - Retourne le nombre total de cles dans la base")
+	case "AUTH":
+		return protocolEncoder.WriteSimpleStringResponse("AUTH [username] password - Authentifie la connexion (utilisateur default si seul le mot de passe est donne)")
+	case "ACL":
+		return protocolEncoder.WriteSimpleStringResponse("ACL SETUSER|GETUSER|LIST|WHOAMI|CAT - Gere les utilisateurs (regles: on/off, >motdepasse, nopass, ~motif, allkeys, +commande, -commande, +@categorie, -@categorie, reset)")
+	case "SELECT":
+		return protocolEncoder.WriteSimpleStringResponse("SELECT index - Selectionne la base logique de la connexion (0 par defaut)")
+	case "MOVE":
+		return protocolEncoder.WriteSimpleStringResponse("MOVE key db - Deplace une cle vers une autre base (1 si deplacee, 0 sinon)")
+	case "SWAPDB":
+		return protocolEncoder.WriteSimpleStringResponse("SWAPDB index1 index2 - Echange le contenu de deux bases")
+	case "FLUSHDB":
+		return protocolEncoder.WriteSimpleStringResponse("FLUSHDB [ASYNC|SYNC] - Vide la base courante")
 	case "FLUSHALL":
-		return protocolEncoder.WriteSimpleStringResponse("FLUSHALL - Vide completement la base de donnees")
+		return protocolEncoder.WriteSimpleStringResponse("FLUSHALL [ASYNC|SYNC] - Vide toutes les bases")
+	case "REPLICAOF", "SLAVEOF":
+		return protocolEncoder.WriteSimpleStringResponse("REPLICAOF host port | REPLICAOF NO ONE - Replique un primaire (synchronisation complete puis flux des ecritures) ou redevient primaire")
+	case "ROLE":
+		return protocolEncoder.WriteSimpleStringResponse("ROLE - Role du serveur (master avec ses replicas, ou slave avec l'etat du lien) et offset de replication")
+	case "INFO":
+		return protocolEncoder.WriteSimpleStringResponse("INFO [section ...] - Informations sur le serveur (sections server, clients, memory, persistence, stats, replication, keyspace)")
+	case "CLIENT":
+		return protocolEncoder.WriteSimpleStringResponse("CLIENT LIST|INFO|ID|SETNAME|GETNAME|SETINFO|KILL|PAUSE|UNPAUSE - Gestion des connexions clientes (liste, nom, deconnexion, suspension)")
+	case "MULTI":
+		return protocolEncoder.WriteSimpleStringResponse("MULTI - Demarre une transaction, les commandes suivantes sont mises en file")
+	case "EXEC":
+		return protocolEncoder.WriteSimpleStringResponse("EXEC - Execute atomiquement les commandes en file (nil si une cle surveillee a change)")
+	case "DISCARD":
+		return protocolEncoder.WriteSimpleStringResponse("DISCARD - Abandonne la transaction en cours")
+	case "WATCH":
+		return protocolEncoder.WriteSimpleStringResponse("WATCH key [key ...] - Surveille des cles, EXEC echoue si elles sont modifiees")
+	case "UNWATCH":
+		return protocolEncoder.WriteSimpleStringResponse("UNWATCH - Arrete la surveillance de toutes les cles")
+	case "SUBSCRIBE":
+		return protocolEncoder.WriteSimpleStringResponse("SUBSCRIBE channel [channel ...] - S'abonne a des channels (seules les commandes pub/sub restent autorisees)")
+	case "UNSUBSCRIBE":
+		return protocolEncoder.WriteSimpleStringResponse("UNSUBSCRIBE [channel ...] - Se desabonne des channels indiques (ou de tous)")
+	case "PSUBSCRIBE":
+		return protocolEncoder.WriteSimpleStringResponse("PSUBSCRIBE pattern [pattern ...] - S'abonne aux channels correspondant a des motifs (* ? [abc])")
+	case "PUNSUBSCRIBE":
+		return protocolEncoder.WriteSimpleStringResponse("PUNSUBSCRIBE [pattern ...] - Se desabonne des motifs indiques (ou de tous)")
+	case "PUBLISH":
+		return protocolEncoder.WriteSimpleStringResponse("PUBLISH channel message - Publie un message, retourne le nombre de destinataires")
+	case "PUBSUB":
+		return protocolEncoder.WriteSimpleStringResponse("PUBSUB CHANNELS [pattern] | NUMSUB [channel ...] | NUMPAT - Inspecte les abonnements actifs")
+	case "EVAL":
+		return protocolEncoder.WriteSimpleStringResponse("EVAL script numkeys [key ...] [arg ...] - Execute un script Lua de maniere atomique (KEYS, ARGV, redis.call, redis.pcall)")
+	case "EVALSHA":
+		return protocolEncoder.WriteSimpleStringResponse("EVALSHA sha1 numkeys [key ...] [arg ...] - Execute un script deja charge a partir de son SHA1")
+	case "SCRIPT":
+		return protocolEncoder.WriteSimpleStringResponse("SCRIPT LOAD script | EXISTS sha1 [sha1 ...] | FLUSH [ASYNC|SYNC] - Gere le cache des scripts Lua")
 	default:
 		return protocolEncoder.WriteSimpleStringResponse("Commande inconnue. Tapez ALAIDE pour voir toutes les commandes disponibles")
 	}
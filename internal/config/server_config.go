@@ -1,8 +1,12 @@
 package config
 
 import (
+	"log"
+	"net"
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -11,12 +15,27 @@ type ServerConfiguration struct {
 	NetworkConfiguration     NetworkConfiguration
 	PerformanceConfiguration PerformanceConfiguration
 	MaintenanceConfiguration MaintenanceConfiguration
+	PersistenceConfiguration PersistenceConfiguration
+	StorageConfiguration     StorageConfiguration
+	SecurityConfiguration    SecurityConfiguration
+	ReplicationConfiguration ReplicationConfiguration
 }
 
 // NetworkConfiguration gère les paramètres réseau
 type NetworkConfiguration struct {
 	HostAddress string
-	PortNumber  int
+	// PortNumber est le port TCP en clair (0 = désactivé, par exemple pour n'accepter que TLS)
+	PortNumber int
+	// TlsPortNumber est le port TLS (0 = désactivé), il peut être ouvert en même temps que PortNumber
+	TlsPortNumber        int
+	TlsCertificateFile   string
+	TlsKeyFile           string
+	TlsCaCertificateFile string
+	// TlsAuthenticateClients exige un certificat client signé par TlsCaCertificateFile (mTLS)
+	TlsAuthenticateClients bool
+	// UnixSocketPath est le chemin du socket Unix (vide = désactivé), ouvert en plus des ports TCP
+	UnixSocketPath        string
+	UnixSocketPermissions os.FileMode
 }
 
 // PerformanceConfiguration gère les paramètres de performance
@@ -29,6 +48,55 @@ type MaintenanceConfiguration struct {
 	ExpirationCheckInterval time.Duration
 }
 
+// StorageConfiguration gère l'organisation des données en mémoire
+type StorageConfiguration struct {
+	// DatabaseCount est le nombre de bases logiques accessibles avec SELECT (0 à DatabaseCount-1)
+	DatabaseCount int
+}
+
+// SecurityConfiguration gère l'authentification des clients
+type SecurityConfiguration struct {
+	// RequirePassword est le mot de passe de l'utilisateur default (requirepass), vide = pas d'authentification
+	RequirePassword string
+}
+
+// ReplicationConfiguration gère la réplication primaire/réplica
+type ReplicationConfiguration struct {
+	// ReplicaOfAddress est l'adresse host:port du primaire à répliquer au démarrage (vide = primaire)
+	ReplicaOfAddress string
+	// MasterUserName et MasterPassword authentifient le réplica auprès du primaire (AUTH)
+	MasterUserName string
+	MasterPassword string
+	// ReplicaReadOnly refuse les commandes d'écriture des clients sur un réplica
+	ReplicaReadOnly bool
+	// BacklogSize est la taille en octets du backlog utilisé pour les resynchronisations partielles
+	BacklogSize int
+}
+
+// PersistenceConfiguration gère les paramètres de persistance sur disque
+type PersistenceConfiguration struct {
+	SnapshotFilePath     string
+	SnapshotSavePolicies []SnapshotSavePolicy
+	AppendOnlyEnabled    bool
+	AppendOnlyFilePath   string
+	AppendFsyncPolicy    string
+	// AppendOnlyLoadTruncated autorise le chargement d'un AOF tronqué (la fin incomplète est supprimée)
+	AppendOnlyLoadTruncated bool
+}
+
+// Politiques de fsync de l'AOF
+const (
+	AppendFsyncAlways      = "always"
+	AppendFsyncEverySecond = "everysec"
+	AppendFsyncNever       = "no"
+)
+
+// SnapshotSavePolicy déclenche un snapshot après Interval si au moins MinimumChanges écritures ont eu lieu
+type SnapshotSavePolicy struct {
+	Interval       time.Duration
+	MinimumChanges int64
+}
+
 // LoadServerConfiguration charge la configuration depuis les variables d'environnement
 // avec des valeurs par défaut raisonnables
 func LoadServerConfiguration() *ServerConfiguration {
@@ -36,6 +104,15 @@ func LoadServerConfiguration() *ServerConfiguration {
 		NetworkConfiguration: NetworkConfiguration{
 			HostAddress: getEnvironmentString("REDIS_HOST", "localhost"),
 			PortNumber:  getEnvironmentInteger("REDIS_PORT", 6379),
+
+			TlsPortNumber:          getEnvironmentInteger("REDIS_TLS_PORT", 0),
+			TlsCertificateFile:     os.Getenv("REDIS_TLS_CERT_FILE"),
+			TlsKeyFile:             os.Getenv("REDIS_TLS_KEY_FILE"),
+			TlsCaCertificateFile:   os.Getenv("REDIS_TLS_CA_CERT_FILE"),
+			TlsAuthenticateClients: getEnvironmentBoolean("REDIS_TLS_AUTH_CLIENTS", false),
+
+			UnixSocketPath:        os.Getenv("REDIS_UNIX_SOCKET"),
+			UnixSocketPermissions: parseUnixSocketPermissions(getEnvironmentString("REDIS_UNIX_SOCKET_PERMISSIONS", "0700")),
 		},
 		PerformanceConfiguration: PerformanceConfiguration{
 			MaximumConnections: getEnvironmentInteger("REDIS_MAX_CONNECTIONS", 1000),
@@ -43,6 +120,31 @@ func LoadServerConfiguration() *ServerConfiguration {
 		MaintenanceConfiguration: MaintenanceConfiguration{
 			ExpirationCheckInterval: time.Duration(getEnvironmentInteger("REDIS_EXPIRATION_CHECK_INTERVAL", 1)) * time.Second,
 		},
+		PersistenceConfiguration: PersistenceConfiguration{
+			SnapshotFilePath: filepath.Join(
+				getEnvironmentString("REDIS_DATA_DIRECTORY", "."),
+				getEnvironmentString("REDIS_SNAPSHOT_FILENAME", "dump.rdb")),
+			SnapshotSavePolicies: parseSnapshotSavePolicies(getEnvironmentString("REDIS_SNAPSHOT_SAVE_POLICY", "3600 1 300 100 60 10000")),
+			AppendOnlyEnabled:    getEnvironmentBoolean("REDIS_APPEND_ONLY", false),
+			AppendOnlyFilePath: filepath.Join(
+				getEnvironmentString("REDIS_DATA_DIRECTORY", "."),
+				getEnvironmentString("REDIS_APPEND_ONLY_FILENAME", "appendonly.aof")),
+			AppendFsyncPolicy:       parseAppendFsyncPolicy(getEnvironmentString("REDIS_APPEND_FSYNC", AppendFsyncEverySecond)),
+			AppendOnlyLoadTruncated: getEnvironmentBoolean("REDIS_APPEND_ONLY_LOAD_TRUNCATED", true),
+		},
+		StorageConfiguration: StorageConfiguration{
+			DatabaseCount: parseDatabaseCount(getEnvironmentInteger("REDIS_DATABASES", 16)),
+		},
+		SecurityConfiguration: SecurityConfiguration{
+			RequirePassword: os.Getenv("REDIS_REQUIREPASS"),
+		},
+		ReplicationConfiguration: ReplicationConfiguration{
+			ReplicaOfAddress: parseReplicaOfAddress(os.Getenv("REDIS_REPLICAOF")),
+			MasterUserName:   os.Getenv("REDIS_MASTERUSER"),
+			MasterPassword:   os.Getenv("REDIS_MASTERAUTH"),
+			ReplicaReadOnly:  getEnvironmentBoolean("REDIS_REPLICA_READ_ONLY", true),
+			BacklogSize:      parseReplicationBacklogSize(getEnvironmentInteger("REDIS_REPLICATION_BACKLOG_SIZE", 1024*1024)),
+		},
 	}
 
 	return configuration
@@ -65,3 +167,101 @@ func getEnvironmentInteger(environmentKey string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+// getEnvironmentBoolean récupère une variable d'environnement booléenne (yes/no, true/false, 1/0)
+func getEnvironmentBoolean(environmentKey string, defaultValue bool) bool {
+	switch strings.ToLower(os.Getenv(environmentKey)) {
+	case "yes", "true", "1":
+		return true
+	case "no", "false", "0":
+		return false
+	default:
+		return defaultValue
+	}
+}
+
+// parseUnixSocketPermissions convertit les permissions octales du socket Unix (0700 par défaut)
+func parseUnixSocketPermissions(octalPermissions string) os.FileMode {
+	parsedPermissions, parseError := strconv.ParseUint(octalPermissions, 8, 32)
+	if parseError != nil || parsedPermissions > 0o777 {
+		log.Printf("⚠️  Permissions du socket Unix invalides (%s), utilisation de 0700", octalPermissions)
+		return 0o700
+	}
+	return os.FileMode(parsedPermissions)
+}
+
+// parseReplicaOfAddress convertit "host port" (syntaxe de REPLICAOF) en adresse host:port
+func parseReplicaOfAddress(replicaOfValue string) string {
+	replicaOfFields := strings.Fields(replicaOfValue)
+	if len(replicaOfFields) == 0 {
+		return ""
+	}
+	if len(replicaOfFields) != 2 {
+		log.Printf("⚠️  REDIS_REPLICAOF invalide (%s), attendu \"host port\" : réplication désactivée", replicaOfValue)
+		return ""
+	}
+	if _, parseError := strconv.Atoi(replicaOfFields[1]); parseError != nil {
+		log.Printf("⚠️  Port de REDIS_REPLICAOF invalide (%s) : réplication désactivée", replicaOfFields[1])
+		return ""
+	}
+	return net.JoinHostPort(replicaOfFields[0], replicaOfFields[1])
+}
+
+// parseReplicationBacklogSize valide la taille du backlog de réplication (16 Ko minimum)
+func parseReplicationBacklogSize(backlogSize int) int {
+	const minimumBacklogSize = 16 * 1024
+	if backlogSize < minimumBacklogSize {
+		log.Printf("⚠️  Taille de backlog invalide (%d), utilisation de %d octets", backlogSize, minimumBacklogSize)
+		return minimumBacklogSize
+	}
+	return backlogSize
+}
+
+// parseDatabaseCount valide le nombre de bases logiques (au moins une)
+func parseDatabaseCount(databaseCount int) int {
+	if databaseCount < 1 {
+		log.Printf("⚠️  Nombre de bases invalide (%d), utilisation de 16", databaseCount)
+		return 16
+	}
+	return databaseCount
+}
+
+// parseAppendFsyncPolicy valide la politique de fsync de l'AOF (everysec par défaut)
+func parseAppendFsyncPolicy(fsyncPolicy string) string {
+	switch strings.ToLower(fsyncPolicy) {
+	case AppendFsyncAlways, AppendFsyncEverySecond, AppendFsyncNever:
+		return strings.ToLower(fsyncPolicy)
+	default:
+		log.Printf("⚠️  Politique de fsync inconnue '%s', utilisation de '%s'", fsyncPolicy, AppendFsyncEverySecond)
+		return AppendFsyncEverySecond
+	}
+}
+
+// parseSnapshotSavePolicies parse une politique au format Redis "secondes changements [secondes changements ...]"
+// La valeur "none" désactive les snapshots automatiques
+func parseSnapshotSavePolicies(policyDefinition string) []SnapshotSavePolicy {
+	policyTokens := strings.Fields(policyDefinition)
+	if len(policyTokens) == 1 && strings.EqualFold(policyTokens[0], "none") {
+		return nil
+	}
+	if len(policyTokens)%2 != 0 {
+		log.Printf("⚠️  Politique de sauvegarde invalide '%s', snapshots automatiques désactivés", policyDefinition)
+		return nil
+	}
+
+	savePolicies := make([]SnapshotSavePolicy, 0, len(policyTokens)/2)
+	for tokenIndex := 0; tokenIndex < len(policyTokens); tokenIndex += 2 {
+		intervalSeconds, intervalError := strconv.Atoi(policyTokens[tokenIndex])
+		minimumChanges, changesError := strconv.ParseInt(policyTokens[tokenIndex+1], 10, 64)
+		if intervalError != nil || changesError != nil || intervalSeconds <= 0 || minimumChanges <= 0 {
+			log.Printf("⚠️  Politique de sauvegarde invalide '%s', snapshots automatiques désactivés", policyDefinition)
+			return nil
+		}
+		savePolicies = append(savePolicies, SnapshotSavePolicy{
+			Interval:       time.Duration(intervalSeconds) * time.Second,
+			MinimumChanges: minimumChanges,
+		})
+	}
+
+	return savePolicies
+}
@@ -0,0 +1,203 @@
+package persistence
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+
+	"redis-go/internal/config"
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// ErrAppendOnlyRewriteInProgress indique qu'une réécriture de l'AOF est déjà en cours
+var ErrAppendOnlyRewriteInProgress = errors.New("une réécriture de l'AOF est déjà en cours")
+
+// RedisAppendOnlyFile journalise chaque commande d'écriture au format RESP
+type RedisAppendOnlyFile struct {
+	appendOnlyFilePath string
+	fsyncPolicy        string
+	fileMutex          sync.Mutex
+	appendOnlyFile     *os.File
+	fsyncPending       bool
+	rewriteInProgress  bool
+	rewriteBuffer      bytes.Buffer
+	// Base sélectionnée par le dernier SELECT écrit dans le fichier et dans le tampon de réécriture
+	// (-1 : inconnue, le prochain ajout commence par un SELECT)
+	fileSelectedDatabase   int
+	bufferSelectedDatabase int
+}
+
+// OpenAppendOnlyFile ouvre (ou crée) le fichier AOF en mode ajout
+func OpenAppendOnlyFile(appendOnlyFilePath string, fsyncPolicy string) (*RedisAppendOnlyFile, error) {
+	if mkdirError := os.MkdirAll(filepath.Dir(appendOnlyFilePath), 0o755); mkdirError != nil {
+		return nil, fmt.Errorf("création du dossier de l'AOF impossible: %v", mkdirError)
+	}
+
+	appendOnlyFile, openError := os.OpenFile(appendOnlyFilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if openError != nil {
+		return nil, fmt.Errorf("ouverture de l'AOF %s impossible: %v", appendOnlyFilePath, openError)
+	}
+
+	return &RedisAppendOnlyFile{
+		appendOnlyFilePath:     appendOnlyFilePath,
+		fsyncPolicy:            fsyncPolicy,
+		appendOnlyFile:         appendOnlyFile,
+		fileSelectedDatabase:   -1,
+		bufferSelectedDatabase: -1,
+	}, nil
+}
+
+// encodeCommandAsRESP encode une commande sous forme d'array RESP de bulk strings
+func encodeCommandAsRESP(commandName string, commandArguments []string) []byte {
+	var encodedCommand bytes.Buffer
+	protocol.NewRedisSerializationProtocolEncoder(&encodedCommand).WriteArrayResponse(append([]string{commandName}, commandArguments...))
+	return encodedCommand.Bytes()
+}
+
+// encodeSelectIfNeeded préfixe la commande d'un SELECT si la base diffère de la dernière écrite
+func encodeSelectIfNeeded(selectedDatabase *int, databaseIndex int, encodedCommand []byte) []byte {
+	if *selectedDatabase == databaseIndex {
+		return encodedCommand
+	}
+	*selectedDatabase = databaseIndex
+	return append(encodeCommandAsRESP("SELECT", []string{strconv.Itoa(databaseIndex)}), encodedCommand...)
+}
+
+// AppendCommand ajoute une commande d'écriture exécutée sur la base databaseIndex à l'AOF selon la politique de fsync
+func (appendOnlyFile *RedisAppendOnlyFile) AppendCommand(databaseIndex int, commandName string, commandArguments []string) error {
+	encodedCommand := encodeCommandAsRESP(commandName, commandArguments)
+
+	appendOnlyFile.fileMutex.Lock()
+	defer appendOnlyFile.fileMutex.Unlock()
+
+	// Pendant une réécriture, les nouvelles commandes sont aussi conservées pour le nouveau fichier
+	if appendOnlyFile.rewriteInProgress {
+		appendOnlyFile.rewriteBuffer.Write(encodeSelectIfNeeded(&appendOnlyFile.bufferSelectedDatabase, databaseIndex, encodedCommand))
+	}
+
+	if _, writeError := appendOnlyFile.appendOnlyFile.Write(encodeSelectIfNeeded(&appendOnlyFile.fileSelectedDatabase, databaseIndex, encodedCommand)); writeError != nil {
+		// L'état de la fin du fichier est inconnu : forcer un SELECT au prochain ajout
+		appendOnlyFile.fileSelectedDatabase = -1
+		return fmt.Errorf("écriture dans l'AOF impossible: %v", writeError)
+	}
+
+	switch appendOnlyFile.fsyncPolicy {
+	case config.AppendFsyncAlways:
+		return appendOnlyFile.appendOnlyFile.Sync()
+	case config.AppendFsyncEverySecond:
+		appendOnlyFile.fsyncPending = true
+	}
+
+	return nil
+}
+
+// FsyncIfPending force l'écriture sur disque des commandes ajoutées depuis le dernier fsync (everysec)
+func (appendOnlyFile *RedisAppendOnlyFile) FsyncIfPending() error {
+	appendOnlyFile.fileMutex.Lock()
+	defer appendOnlyFile.fileMutex.Unlock()
+
+	if !appendOnlyFile.fsyncPending {
+		return nil
+	}
+	appendOnlyFile.fsyncPending = false
+	return appendOnlyFile.appendOnlyFile.Sync()
+}
+
+// BeginRewrite démarre la mise en tampon des nouvelles commandes pour une réécriture
+// Doit être appelé au moment exact où le dataset est copié (aucune commande en cours)
+func (appendOnlyFile *RedisAppendOnlyFile) BeginRewrite() error {
+	appendOnlyFile.fileMutex.Lock()
+	defer appendOnlyFile.fileMutex.Unlock()
+
+	if appendOnlyFile.rewriteInProgress {
+		return ErrAppendOnlyRewriteInProgress
+	}
+	appendOnlyFile.rewriteInProgress = true
+	appendOnlyFile.rewriteBuffer.Reset()
+	// Le préambule ne laisse aucune base sélectionnée : le tampon commence par un SELECT
+	appendOnlyFile.bufferSelectedDatabase = -1
+	return nil
+}
+
+// FinishRewrite écrit le dataset copié comme préambule snapshot, ajoute les commandes reçues
+// entre-temps puis remplace atomiquement l'ancien AOF
+func (appendOnlyFile *RedisAppendOnlyFile) FinishRewrite(databaseEntries []map[string]*storage.RedisStorageValue) error {
+	rewriteError := appendOnlyFile.rewriteFromDataset(databaseEntries)
+
+	if rewriteError != nil {
+		appendOnlyFile.fileMutex.Lock()
+		appendOnlyFile.rewriteInProgress = false
+		appendOnlyFile.rewriteBuffer.Reset()
+		appendOnlyFile.fileMutex.Unlock()
+	}
+
+	return rewriteError
+}
+
+// rewriteFromDataset réalise la réécriture, le verrou n'est pris que pour la bascule finale
+func (appendOnlyFile *RedisAppendOnlyFile) rewriteFromDataset(databaseEntries []map[string]*storage.RedisStorageValue) error {
+	appendOnlyDirectory := filepath.Dir(appendOnlyFile.appendOnlyFilePath)
+	temporaryFile, createError := os.CreateTemp(appendOnlyDirectory, "temp-rewriteaof-*.aof")
+	if createError != nil {
+		return fmt.Errorf("création du fichier temporaire impossible: %v", createError)
+	}
+	temporaryFilePath := temporaryFile.Name()
+	defer os.Remove(temporaryFilePath)
+	defer temporaryFile.Close()
+
+	// L'écriture du dataset (la partie longue) se fait sans bloquer les écritures
+	if writeError := WriteSnapshot(temporaryFile, databaseEntries); writeError != nil {
+		return fmt.Errorf("écriture du préambule impossible: %v", writeError)
+	}
+
+	appendOnlyFile.fileMutex.Lock()
+	defer appendOnlyFile.fileMutex.Unlock()
+
+	if _, writeError := temporaryFile.Write(appendOnlyFile.rewriteBuffer.Bytes()); writeError != nil {
+		return fmt.Errorf("écriture des commandes récentes impossible: %v", writeError)
+	}
+	if syncError := temporaryFile.Sync(); syncError != nil {
+		return fmt.Errorf("fsync du nouvel AOF impossible: %v", syncError)
+	}
+	if renameError := os.Rename(temporaryFilePath, appendOnlyFile.appendOnlyFilePath); renameError != nil {
+		return fmt.Errorf("remplacement de l'AOF impossible: %v", renameError)
+	}
+
+	// Bascule des écritures vers le nouveau fichier
+	newAppendOnlyFile, openError := os.OpenFile(appendOnlyFile.appendOnlyFilePath, os.O_WRONLY|os.O_APPEND, 0o644)
+	if openError != nil {
+		return fmt.Errorf("réouverture de l'AOF impossible: %v", openError)
+	}
+	appendOnlyFile.appendOnlyFile.Close()
+	appendOnlyFile.appendOnlyFile = newAppendOnlyFile
+	appendOnlyFile.fsyncPending = false
+	appendOnlyFile.fileSelectedDatabase = appendOnlyFile.bufferSelectedDatabase
+	appendOnlyFile.rewriteInProgress = false
+	appendOnlyFile.rewriteBuffer.Reset()
+
+	return nil
+}
+
+// IsRewriteInProgress indique si une réécriture est en cours
+func (appendOnlyFile *RedisAppendOnlyFile) IsRewriteInProgress() bool {
+	appendOnlyFile.fileMutex.Lock()
+	defer appendOnlyFile.fileMutex.Unlock()
+	return appendOnlyFile.rewriteInProgress
+}
+
+// Close force l'écriture sur disque et ferme l'AOF
+func (appendOnlyFile *RedisAppendOnlyFile) Close() error {
+	appendOnlyFile.fileMutex.Lock()
+	defer appendOnlyFile.fileMutex.Unlock()
+
+	if syncError := appendOnlyFile.appendOnlyFile.Sync(); syncError != nil {
+		appendOnlyFile.appendOnlyFile.Close()
+		return syncError
+	}
+	return appendOnlyFile.appendOnlyFile.Close()
+}
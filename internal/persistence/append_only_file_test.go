@@ -0,0 +1,212 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"redis-go/internal/config"
+	"redis-go/internal/storage"
+)
+
+// appendOnlyCommandRecorder retourne un exécuteur qui note chaque commande rejouée sous la forme "COMMANDE args..."
+func appendOnlyCommandRecorder(replayedCommands *[]string) AppendOnlyCommandExecutor {
+	return func(commandName string, commandArguments []string) error {
+		*replayedCommands = append(*replayedCommands, strings.Join(append([]string{commandName}, commandArguments...), " "))
+		return nil
+	}
+}
+
+// encodeTestCommands encode des commandes écrites "COMMANDE args..." comme dans l'AOF
+func encodeTestCommands(commandLines ...string) string {
+	var encodedCommands strings.Builder
+	for _, commandLine := range commandLines {
+		commandFields := strings.Fields(commandLine)
+		encodedCommands.Write(encodeCommandAsRESP(commandFields[0], commandFields[1:]))
+	}
+	return encodedCommands.String()
+}
+
+func TestAppendOnlyFileReplaysCommandsInTheirDatabase(t *testing.T) {
+	appendOnlyFilePath := filepath.Join(t.TempDir(), "appendonly.aof")
+	appendOnlyFile, openError := OpenAppendOnlyFile(appendOnlyFilePath, config.AppendFsyncAlways)
+	if openError != nil {
+		t.Fatalf("ouverture de l'AOF: %v", openError)
+	}
+	appendedCommands := []struct {
+		databaseIndex    int
+		commandName      string
+		commandArguments []string
+	}{
+		{databaseIndex: 0, commandName: "SET", commandArguments: []string{"a", "1"}},
+		{databaseIndex: 0, commandName: "SET", commandArguments: []string{"b", "valeur avec espaces\r\n"}},
+		{databaseIndex: 3, commandName: "RPUSH", commandArguments: []string{"l", "x"}},
+		{databaseIndex: 0, commandName: "DEL", commandArguments: []string{"a"}},
+	}
+	for _, appendedCommand := range appendedCommands {
+		if appendError := appendOnlyFile.AppendCommand(appendedCommand.databaseIndex, appendedCommand.commandName, appendedCommand.commandArguments); appendError != nil {
+			t.Fatalf("ajout de %s: %v", appendedCommand.commandName, appendError)
+		}
+	}
+	if closeError := appendOnlyFile.Close(); closeError != nil {
+		t.Fatalf("fermeture de l'AOF: %v", closeError)
+	}
+
+	var replayedCommands []string
+	loadResult, loadError := LoadAppendOnlyFile(appendOnlyFilePath, false, storage.NewRedisDatabaseStorages(16), appendOnlyCommandRecorder(&replayedCommands))
+	if loadError != nil {
+		t.Fatalf("chargement de l'AOF: %v", loadError)
+	}
+	expectedCommands := []string{"SELECT 0", "SET a 1", "SET b valeur avec espaces\r\n", "SELECT 3", "RPUSH l x", "SELECT 0", "DEL a"}
+	if !reflect.DeepEqual(replayedCommands, expectedCommands) {
+		t.Fatalf("commandes rejouées %q, attendu %q", replayedCommands, expectedCommands)
+	}
+	if !loadResult.FileExists || loadResult.ReplayedCommandCount != len(expectedCommands) || loadResult.TruncatedByteCount != 0 {
+		t.Fatalf("résultat du chargement %+v", loadResult)
+	}
+}
+
+func TestLoadAppendOnlyFileRepairsTruncatedTail(t *testing.T) {
+	completeCommands := encodeTestCommands("SET a 1", "SET b 2")
+	completeTransaction := encodeTestCommands("MULTI", "INCR c", "INCR c", "EXEC")
+
+	testCases := []struct {
+		name               string
+		fileContent        string
+		allowTruncatedTail bool
+		expectedCommands   []string
+		expectedTruncated  int
+		expectedError      bool
+	}{
+		{
+			name:             "fichier complet",
+			fileContent:      completeCommands + completeTransaction,
+			expectedCommands: []string{"SET a 1", "SET b 2", "INCR c", "INCR c"},
+		},
+		{
+			name:               "commande coupée réparée",
+			fileContent:        completeCommands + "*3\r\n$3\r\nSET\r\n$1\r\nc",
+			allowTruncatedTail: true,
+			expectedCommands:   []string{"SET a 1", "SET b 2"},
+			expectedTruncated:  len("*3\r\n$3\r\nSET\r\n$1\r\nc"),
+		},
+		{
+			name:          "commande coupée refusée sans réparation",
+			fileContent:   completeCommands + "*3\r\n$3\r\nSET",
+			expectedError: true,
+		},
+		{
+			name:               "transaction sans EXEC retirée entièrement",
+			fileContent:        completeCommands + encodeTestCommands("MULTI", "INCR c"),
+			allowTruncatedTail: true,
+			expectedCommands:   []string{"SET a 1", "SET b 2"},
+			expectedTruncated:  len(encodeTestCommands("MULTI", "INCR c")),
+		},
+		{
+			name:               "transaction complète conservée avant une commande coupée",
+			fileContent:        completeTransaction + "*2\r\n$3\r\nDEL",
+			allowTruncatedTail: true,
+			expectedCommands:   []string{"INCR c", "INCR c"},
+			expectedTruncated:  len("*2\r\n$3\r\nDEL"),
+		},
+		{
+			name:          "EXEC sans MULTI",
+			fileContent:   completeCommands + encodeTestCommands("EXEC"),
+			expectedError: true,
+		},
+		{
+			name:          "MULTI imbriqué",
+			fileContent:   encodeTestCommands("MULTI", "MULTI", "EXEC"),
+			expectedError: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			appendOnlyFilePath := filepath.Join(t.TempDir(), "appendonly.aof")
+			if writeError := os.WriteFile(appendOnlyFilePath, []byte(testCase.fileContent), 0o644); writeError != nil {
+				t.Fatalf("écriture de l'AOF: %v", writeError)
+			}
+
+			var replayedCommands []string
+			loadResult, loadError := LoadAppendOnlyFile(appendOnlyFilePath, testCase.allowTruncatedTail, storage.NewRedisDatabaseStorages(16), appendOnlyCommandRecorder(&replayedCommands))
+			if testCase.expectedError {
+				if loadError == nil {
+					t.Fatalf("chargement accepté, attendu une erreur")
+				}
+				return
+			}
+			if loadError != nil {
+				t.Fatalf("chargement de l'AOF: %v", loadError)
+			}
+			if !reflect.DeepEqual(replayedCommands, testCase.expectedCommands) {
+				t.Fatalf("commandes rejouées %q, attendu %q", replayedCommands, testCase.expectedCommands)
+			}
+			if loadResult.TruncatedByteCount != testCase.expectedTruncated {
+				t.Fatalf("%d octets tronqués, attendu %d", loadResult.TruncatedByteCount, testCase.expectedTruncated)
+			}
+
+			// Après réparation le fichier se termine à la dernière commande complète et se recharge sans perte
+			repairedContent, _ := os.ReadFile(appendOnlyFilePath)
+			if expectedLength := len(testCase.fileContent) - testCase.expectedTruncated; len(repairedContent) != expectedLength {
+				t.Fatalf("fichier réparé de %d octets, attendu %d", len(repairedContent), expectedLength)
+			}
+			var reloadedCommands []string
+			if _, reloadError := LoadAppendOnlyFile(appendOnlyFilePath, false, storage.NewRedisDatabaseStorages(16), appendOnlyCommandRecorder(&reloadedCommands)); reloadError != nil || !reflect.DeepEqual(reloadedCommands, testCase.expectedCommands) {
+				t.Fatalf("rechargement: commandes %q, erreur %v", reloadedCommands, reloadError)
+			}
+		})
+	}
+}
+
+func TestAppendOnlyRewriteKeepsCommandsReceivedDuringRewrite(t *testing.T) {
+	appendOnlyFilePath := filepath.Join(t.TempDir(), "appendonly.aof")
+	appendOnlyFile, openError := OpenAppendOnlyFile(appendOnlyFilePath, config.AppendFsyncNever)
+	if openError != nil {
+		t.Fatalf("ouverture de l'AOF: %v", openError)
+	}
+	defer appendOnlyFile.Close()
+	appendOnlyFile.AppendCommand(0, "SET", []string{"old", "1"})
+	appendOnlyFile.AppendCommand(0, "DEL", []string{"old"})
+
+	// Le dataset copié au début de la réécriture devient le préambule
+	rewrittenDataset := []map[string]*storage.RedisStorageValue{
+		{"kept": {StoredData: "v", DataType: storage.RedisStringType}},
+		{},
+		{"other": {StoredData: "w", DataType: storage.RedisStringType}},
+	}
+	if beginError := appendOnlyFile.BeginRewrite(); beginError != nil {
+		t.Fatalf("début de réécriture: %v", beginError)
+	}
+	if beginError := appendOnlyFile.BeginRewrite(); beginError != ErrAppendOnlyRewriteInProgress {
+		t.Fatalf("seconde réécriture: erreur %v, attendu ErrAppendOnlyRewriteInProgress", beginError)
+	}
+	appendOnlyFile.AppendCommand(2, "SET", []string{"during", "1"})
+	if finishError := appendOnlyFile.FinishRewrite(rewrittenDataset); finishError != nil {
+		t.Fatalf("fin de réécriture: %v", finishError)
+	}
+	appendOnlyFile.AppendCommand(2, "SET", []string{"after", "1"})
+	appendOnlyFile.AppendCommand(0, "SET", []string{"after", "0"})
+	if appendOnlyFile.IsRewriteInProgress() {
+		t.Fatalf("la réécriture doit être terminée")
+	}
+
+	var replayedCommands []string
+	loadedStorages := storage.NewRedisDatabaseStorages(16)
+	loadResult, loadError := LoadAppendOnlyFile(appendOnlyFilePath, false, loadedStorages, appendOnlyCommandRecorder(&replayedCommands))
+	if loadError != nil {
+		t.Fatalf("chargement de l'AOF réécrit: %v", loadError)
+	}
+	if loadResult.PreambleKeyCount != 2 {
+		t.Fatalf("%d clés dans le préambule, attendu 2", loadResult.PreambleKeyCount)
+	}
+	// Le SELECT 2 n'est pas répété après la bascule : le fichier réécrit reprend la base du tampon
+	expectedCommands := []string{"SELECT 2", "SET during 1", "SET after 1", "SELECT 0", "SET after 0"}
+	if !reflect.DeepEqual(replayedCommands, expectedCommands) {
+		t.Fatalf("commandes rejouées %q, attendu %q", replayedCommands, expectedCommands)
+	}
+	if keyCount := len(loadedStorages[0].CloneStorageEntries()) + len(loadedStorages[2].CloneStorageEntries()); keyCount != 2 {
+		t.Fatalf("%d clés restaurées depuis le préambule, attendu 2", keyCount)
+	}
+}
@@ -0,0 +1,139 @@
+package persistence
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// AppendOnlyLoadResult résume le chargement d'un AOF
+type AppendOnlyLoadResult struct {
+	FileExists           bool
+	PreambleKeyCount     int
+	ReplayedCommandCount int
+	TruncatedByteCount   int
+}
+
+// AppendOnlyCommandExecutor rejoue une commande lue depuis l'AOF (SELECT compris)
+type AppendOnlyCommandExecutor func(commandName string, commandArguments []string) error
+
+// countingReader compte les octets lus pour connaître la position dans le fichier
+type countingReader struct {
+	sourceReader io.Reader
+	readCount    int
+}
+
+func (reader *countingReader) Read(readBuffer []byte) (int, error) {
+	readBytes, readError := reader.sourceReader.Read(readBuffer)
+	reader.readCount += readBytes
+	return readBytes, readError
+}
+
+// LoadAppendOnlyFile recharge l'AOF : préambule snapshot éventuel puis rejeu des commandes
+// Une fin de fichier incomplète (crash pendant une écriture) est tronquée si allowTruncatedTail est vrai
+func LoadAppendOnlyFile(appendOnlyFilePath string, allowTruncatedTail bool, databaseStorages []*storage.RedisInMemoryStorage, commandExecutor AppendOnlyCommandExecutor) (AppendOnlyLoadResult, error) {
+	var loadResult AppendOnlyLoadResult
+
+	fileContent, readError := os.ReadFile(appendOnlyFilePath)
+	if errors.Is(readError, os.ErrNotExist) {
+		return loadResult, nil
+	}
+	if readError != nil {
+		return loadResult, fmt.Errorf("lecture de l'AOF %s impossible: %v", appendOnlyFilePath, readError)
+	}
+	loadResult.FileExists = true
+
+	// Préambule snapshot produit par BGREWRITEAOF
+	commandsOffset := 0
+	if hasSnapshotHeader(fileContent) {
+		databaseEntries, snapshotLength, decodeError := decodeSnapshot(fileContent)
+		if decodeError != nil {
+			return loadResult, fmt.Errorf("préambule de l'AOF %s illisible: %w", appendOnlyFilePath, decodeError)
+		}
+		if loadResult.PreambleKeyCount, decodeError = RestoreAllDatabases(databaseEntries, databaseStorages); decodeError != nil {
+			return loadResult, decodeError
+		}
+		commandsOffset = snapshotLength
+	}
+
+	// Rejeu des commandes en suivant la position de la dernière commande complète
+	// Les commandes d'une transaction (MULTI ... EXEC) ne sont rejouées qu'à son EXEC : une transaction
+	// incomplète en fin de fichier est tronquée entièrement, comme une commande incomplète
+	contentReader := &countingReader{sourceReader: bytes.NewReader(fileContent[commandsOffset:])}
+	protocolParser := protocol.NewRedisSerializationProtocolParser(contentReader)
+	lastValidOffset := commandsOffset
+	var transactionCommands [][]string
+	isInsideTransaction := false
+
+	for {
+		parsedCommand, parseError := protocolParser.ParseMultibulkCommand()
+		if parseError == io.EOF && contentReader.readCount-protocolParser.BufferedLength() == len(fileContent)-commandsOffset && !isInsideTransaction {
+			return loadResult, nil
+		}
+
+		if parseError != nil && !errors.Is(parseError, io.EOF) && !errors.Is(parseError, io.ErrUnexpectedEOF) {
+			return loadResult, fmt.Errorf("AOF %s corrompu à l'octet %d: %v", appendOnlyFilePath, lastValidOffset, parseError)
+		}
+		if parseError != nil {
+			return loadResult, truncateAppendOnlyTail(appendOnlyFilePath, allowTruncatedTail, lastValidOffset, len(fileContent), &loadResult)
+		}
+
+		if len(parsedCommand) == 0 {
+			if !isInsideTransaction {
+				lastValidOffset = commandsOffset + contentReader.readCount - protocolParser.BufferedLength()
+			}
+			continue
+		}
+
+		upperCommandName := strings.ToUpper(parsedCommand[0])
+		switch {
+		case upperCommandName == "MULTI":
+			if isInsideTransaction {
+				return loadResult, fmt.Errorf("AOF %s corrompu à l'octet %d: MULTI imbriqué", appendOnlyFilePath, lastValidOffset)
+			}
+			isInsideTransaction = true
+			continue
+		case upperCommandName == "EXEC":
+			if !isInsideTransaction {
+				return loadResult, fmt.Errorf("AOF %s corrompu à l'octet %d: EXEC sans MULTI", appendOnlyFilePath, lastValidOffset)
+			}
+			isInsideTransaction = false
+		case isInsideTransaction:
+			transactionCommands = append(transactionCommands, parsedCommand)
+			continue
+		default:
+			transactionCommands = [][]string{parsedCommand}
+		}
+
+		lastValidOffset = commandsOffset + contentReader.readCount - protocolParser.BufferedLength()
+		for _, replayedCommand := range transactionCommands {
+			if executionError := commandExecutor(replayedCommand[0], replayedCommand[1:]); executionError != nil {
+				return loadResult, fmt.Errorf("rejeu de la commande %s impossible: %v", replayedCommand[0], executionError)
+			}
+			loadResult.ReplayedCommandCount++
+		}
+		transactionCommands = nil
+	}
+}
+
+// truncateAppendOnlyTail supprime la dernière commande incomplète de l'AOF
+func truncateAppendOnlyTail(appendOnlyFilePath string, allowTruncatedTail bool, lastValidOffset int, fileSize int, loadResult *AppendOnlyLoadResult) error {
+	if !allowTruncatedTail {
+		return fmt.Errorf("AOF %s tronqué à l'octet %d (activez REDIS_APPEND_ONLY_LOAD_TRUNCATED pour le réparer)", appendOnlyFilePath, lastValidOffset)
+	}
+
+	if truncateError := os.Truncate(appendOnlyFilePath, int64(lastValidOffset)); truncateError != nil {
+		return fmt.Errorf("réparation de l'AOF %s impossible: %v", appendOnlyFilePath, truncateError)
+	}
+
+	loadResult.TruncatedByteCount = fileSize - lastValidOffset
+	log.Printf("⚠️  AOF tronqué: %d octets incomplets supprimés en fin de fichier", loadResult.TruncatedByteCount)
+	return nil
+}
@@ -0,0 +1,422 @@
+package persistence
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc64"
+	"io"
+	"math"
+	"time"
+
+	"redis-go/internal/storage"
+)
+
+// Format binaire des snapshots :
+//
+//	"REDISGO" | version (1 octet)
+//	[SELECTDB index] puis pour chaque clé : [EXPIRETIME_MS ms] type clé valeur
+//	(un hash dont des fields expirent utilise un type dédié : field, valeur, expiration en ms ou 0)
+//	EOF | CRC64 (8 octets, little endian) de tout ce qui précède
+const (
+	snapshotMagicHeader   = "REDISGO"
+	snapshotFormatVersion = 1
+
+	snapshotOpcodeExpireTimeMilliseconds = 0xFC
+	snapshotOpcodeSelectDatabase         = 0xFE
+	snapshotOpcodeEndOfFile              = 0xFF
+
+	snapshotValueTypeString    = 0
+	snapshotValueTypeList      = 1
+	snapshotValueTypeSet       = 2
+	snapshotValueTypeHash      = 3
+	snapshotValueTypeSortedSet = 4
+
+	snapshotValueTypeHashWithFieldExpirations = 5
+)
+
+// ErrSnapshotCorrupted indique un fichier snapshot illisible ou tronqué
+var ErrSnapshotCorrupted = errors.New("snapshot corrompu")
+
+// snapshotChecksumTable table CRC64 utilisée pour le checksum final
+var snapshotChecksumTable = crc64.MakeTable(crc64.ECMA)
+
+// snapshotWriter sérialise des bases de données dans le format snapshot
+type snapshotWriter struct {
+	bufferedWriter *bufio.Writer
+	checksumHash   hash.Hash64
+	scratchBuffer  [binary.MaxVarintLen64]byte
+}
+
+// newSnapshotWriter crée un writer qui calcule le checksum au fil de l'eau
+func newSnapshotWriter(outputWriter io.Writer) *snapshotWriter {
+	checksumHash := crc64.New(snapshotChecksumTable)
+	return &snapshotWriter{
+		bufferedWriter: bufio.NewWriter(io.MultiWriter(outputWriter, checksumHash)),
+		checksumHash:   checksumHash,
+	}
+}
+
+// WriteSnapshot écrit un snapshot complet (une entrée de la slice par base de données)
+func WriteSnapshot(outputWriter io.Writer, databaseEntries []map[string]*storage.RedisStorageValue) error {
+	writer := newSnapshotWriter(outputWriter)
+
+	writer.bufferedWriter.WriteString(snapshotMagicHeader)
+	writer.bufferedWriter.WriteByte(snapshotFormatVersion)
+
+	for databaseIndex, storageEntries := range databaseEntries {
+		if len(storageEntries) == 0 {
+			continue
+		}
+
+		writer.bufferedWriter.WriteByte(snapshotOpcodeSelectDatabase)
+		writer.writeLength(uint64(databaseIndex))
+
+		for storageKey, storageValue := range storageEntries {
+			if writeError := writer.writeEntry(storageKey, storageValue); writeError != nil {
+				return writeError
+			}
+		}
+	}
+
+	writer.bufferedWriter.WriteByte(snapshotOpcodeEndOfFile)
+	if flushError := writer.bufferedWriter.Flush(); flushError != nil {
+		return flushError
+	}
+
+	// Le checksum est écrit hors du hash
+	var checksumBytes [8]byte
+	binary.LittleEndian.PutUint64(checksumBytes[:], writer.checksumHash.Sum64())
+	_, writeError := outputWriter.Write(checksumBytes[:])
+	return writeError
+}
+
+// writeEntry écrit une clé avec son éventuelle expiration et sa valeur
+func (writer *snapshotWriter) writeEntry(storageKey string, storageValue *storage.RedisStorageValue) error {
+	if storageValue.ExpirationTime != nil {
+		writer.bufferedWriter.WriteByte(snapshotOpcodeExpireTimeMilliseconds)
+		writer.writeInt64(storageValue.ExpirationTime.UnixMilli())
+	}
+
+	switch storedData := storageValue.StoredData.(type) {
+	case string:
+		writer.bufferedWriter.WriteByte(snapshotValueTypeString)
+		writer.writeString(storageKey)
+		writer.writeString(storedData)
+	case *storage.RedisListStructure:
+		writer.bufferedWriter.WriteByte(snapshotValueTypeList)
+		writer.writeString(storageKey)
+		writer.writeLength(uint64(len(storedData.ListElements)))
+		for _, listElement := range storedData.ListElements {
+			writer.writeString(listElement)
+		}
+	case *storage.RedisSetStructure:
+		writer.bufferedWriter.WriteByte(snapshotValueTypeSet)
+		writer.writeString(storageKey)
+		writer.writeLength(uint64(len(storedData.SetElements)))
+		for setMember := range storedData.SetElements {
+			writer.writeString(setMember)
+		}
+	case *storage.RedisHashStructure:
+		if len(storedData.FieldExpirations) > 0 {
+			writer.writeHashWithFieldExpirations(storageKey, storedData)
+			break
+		}
+		writer.bufferedWriter.WriteByte(snapshotValueTypeHash)
+		writer.writeString(storageKey)
+		writer.writeLength(uint64(len(storedData.HashFields)))
+		for fieldName, fieldValue := range storedData.HashFields {
+			writer.writeString(fieldName)
+			writer.writeString(fieldValue)
+		}
+	case *storage.RedisSortedSetStructure:
+		writer.bufferedWriter.WriteByte(snapshotValueTypeSortedSet)
+		writer.writeString(storageKey)
+		writer.writeLength(uint64(len(storedData.MemberScores)))
+		for memberName, memberScore := range storedData.MemberScores {
+			writer.writeString(memberName)
+			writer.writeInt64(int64(math.Float64bits(memberScore)))
+		}
+	default:
+		return fmt.Errorf("type de valeur non sérialisable pour la clé '%s'", storageKey)
+	}
+
+	return nil
+}
+
+// writeHashWithFieldExpirations écrit un hash dont des fields ont un TTL (HEXPIRE)
+func (writer *snapshotWriter) writeHashWithFieldExpirations(storageKey string, redisHashStructure *storage.RedisHashStructure) {
+	writer.bufferedWriter.WriteByte(snapshotValueTypeHashWithFieldExpirations)
+	writer.writeString(storageKey)
+	writer.writeLength(uint64(len(redisHashStructure.HashFields)))
+	for fieldName, fieldValue := range redisHashStructure.HashFields {
+		writer.writeString(fieldName)
+		writer.writeString(fieldValue)
+		fieldExpirationMilliseconds := int64(0)
+		if expirationTime, hasExpiration := redisHashStructure.FieldExpirations[fieldName]; hasExpiration {
+			fieldExpirationMilliseconds = expirationTime.UnixMilli()
+		}
+		writer.writeInt64(fieldExpirationMilliseconds)
+	}
+}
+
+// writeLength écrit un entier non signé au format varint
+func (writer *snapshotWriter) writeLength(lengthValue uint64) {
+	encodedLength := binary.PutUvarint(writer.scratchBuffer[:], lengthValue)
+	writer.bufferedWriter.Write(writer.scratchBuffer[:encodedLength])
+}
+
+// writeInt64 écrit un entier 64 bits little endian
+func (writer *snapshotWriter) writeInt64(integerValue int64) {
+	binary.LittleEndian.PutUint64(writer.scratchBuffer[:8], uint64(integerValue))
+	writer.bufferedWriter.Write(writer.scratchBuffer[:8])
+}
+
+// writeString écrit une chaîne préfixée par sa longueur
+func (writer *snapshotWriter) writeString(stringValue string) {
+	writer.writeLength(uint64(len(stringValue)))
+	writer.bufferedWriter.WriteString(stringValue)
+}
+
+// ReadSnapshot lit un snapshot complet et retourne les entrées indexées par base de données
+// Les clés déjà expirées au moment du chargement sont ignorées
+func ReadSnapshot(snapshotContent []byte) (map[int]map[string]*storage.RedisStorageValue, error) {
+	databaseEntries, snapshotLength, decodeError := decodeSnapshot(snapshotContent)
+	if decodeError != nil {
+		return nil, decodeError
+	}
+	if snapshotLength != len(snapshotContent) {
+		return nil, fmt.Errorf("%w: données inattendues après la fin du snapshot", ErrSnapshotCorrupted)
+	}
+	return databaseEntries, nil
+}
+
+// hasSnapshotHeader indique si un contenu commence par l'en-tête d'un snapshot
+func hasSnapshotHeader(fileContent []byte) bool {
+	return bytes.HasPrefix(fileContent, []byte(snapshotMagicHeader))
+}
+
+// decodeSnapshot décode un snapshot placé en début de contenu et retourne sa longueur en octets
+// (permet d'utiliser un snapshot comme préambule d'un autre fichier, ex: AOF réécrit)
+func decodeSnapshot(snapshotContent []byte) (map[int]map[string]*storage.RedisStorageValue, int, error) {
+	contentReader := bytes.NewReader(snapshotContent)
+	headerBytes := make([]byte, len(snapshotMagicHeader)+1)
+	if _, readError := io.ReadFull(contentReader, headerBytes); readError != nil || string(headerBytes[:len(snapshotMagicHeader)]) != snapshotMagicHeader {
+		return nil, 0, fmt.Errorf("%w: en-tête invalide", ErrSnapshotCorrupted)
+	}
+	if headerBytes[len(snapshotMagicHeader)] != snapshotFormatVersion {
+		return nil, 0, fmt.Errorf("version de snapshot non supportée: %d", headerBytes[len(snapshotMagicHeader)])
+	}
+
+	databaseEntries := make(map[int]map[string]*storage.RedisStorageValue)
+	currentDatabase := 0
+	currentTime := time.Now()
+	var pendingExpiration *time.Time
+
+	for {
+		opcodeByte, readError := contentReader.ReadByte()
+		if readError != nil {
+			return nil, 0, fmt.Errorf("%w: fin de fichier inattendue", ErrSnapshotCorrupted)
+		}
+
+		switch opcodeByte {
+		case snapshotOpcodeEndOfFile:
+			checksummedLength := len(snapshotContent) - contentReader.Len()
+			expectedChecksum, readError := readSnapshotInt64(contentReader)
+			if readError != nil {
+				return nil, 0, fmt.Errorf("%w: checksum manquant", ErrSnapshotCorrupted)
+			}
+			if crc64.Checksum(snapshotContent[:checksummedLength], snapshotChecksumTable) != uint64(expectedChecksum) {
+				return nil, 0, fmt.Errorf("%w: checksum invalide", ErrSnapshotCorrupted)
+			}
+			return databaseEntries, checksummedLength + 8, nil
+
+		case snapshotOpcodeSelectDatabase:
+			databaseIndex, readError := binary.ReadUvarint(contentReader)
+			if readError != nil {
+				return nil, 0, fmt.Errorf("%w: index de base invalide", ErrSnapshotCorrupted)
+			}
+			currentDatabase = int(databaseIndex)
+
+		case snapshotOpcodeExpireTimeMilliseconds:
+			expirationMilliseconds, readError := readSnapshotInt64(contentReader)
+			if readError != nil {
+				return nil, 0, fmt.Errorf("%w: expiration invalide", ErrSnapshotCorrupted)
+			}
+			expirationTime := time.UnixMilli(expirationMilliseconds)
+			pendingExpiration = &expirationTime
+
+		default:
+			storageKey, storageValue, readError := readSnapshotEntry(contentReader, opcodeByte)
+			if readError != nil {
+				return nil, 0, readError
+			}
+			storageValue.ExpirationTime = pendingExpiration
+			pendingExpiration = nil
+
+			if storageValue.ExpirationTime != nil && currentTime.After(*storageValue.ExpirationTime) {
+				continue
+			}
+			// Hash dont tous les fields ont expiré
+			if redisHashStructure, isHash := storageValue.StoredData.(*storage.RedisHashStructure); isHash && len(redisHashStructure.HashFields) == 0 {
+				continue
+			}
+			if databaseEntries[currentDatabase] == nil {
+				databaseEntries[currentDatabase] = make(map[string]*storage.RedisStorageValue)
+			}
+			databaseEntries[currentDatabase][storageKey] = storageValue
+		}
+	}
+}
+
+// readSnapshotEntry lit une clé et sa valeur selon le type indiqué
+func readSnapshotEntry(contentReader *bytes.Reader, valueType byte) (string, *storage.RedisStorageValue, error) {
+	storageKey, readError := readSnapshotString(contentReader)
+	if readError != nil {
+		return "", nil, fmt.Errorf("%w: clé illisible", ErrSnapshotCorrupted)
+	}
+
+	corruptedValueError := fmt.Errorf("%w: valeur illisible pour la clé '%s'", ErrSnapshotCorrupted, storageKey)
+
+	switch valueType {
+	case snapshotValueTypeString:
+		stringValue, readError := readSnapshotString(contentReader)
+		if readError != nil {
+			return "", nil, corruptedValueError
+		}
+		return storageKey, &storage.RedisStorageValue{StoredData: stringValue, DataType: storage.RedisStringType}, nil
+
+	case snapshotValueTypeList:
+		listElements, readError := readSnapshotStringSequence(contentReader, 1)
+		if readError != nil {
+			return "", nil, corruptedValueError
+		}
+		return storageKey, &storage.RedisStorageValue{StoredData: &storage.RedisListStructure{ListElements: listElements}, DataType: storage.RedisListType}, nil
+
+	case snapshotValueTypeSet:
+		setMembers, readError := readSnapshotStringSequence(contentReader, 1)
+		if readError != nil {
+			return "", nil, corruptedValueError
+		}
+		setElements := make(map[string]bool, len(setMembers))
+		for _, setMember := range setMembers {
+			setElements[setMember] = true
+		}
+		return storageKey, &storage.RedisStorageValue{StoredData: &storage.RedisSetStructure{SetElements: setElements}, DataType: storage.RedisSetType}, nil
+
+	case snapshotValueTypeHash:
+		fieldsAndValues, readError := readSnapshotStringSequence(contentReader, 2)
+		if readError != nil {
+			return "", nil, corruptedValueError
+		}
+		hashFields := make(map[string]string, len(fieldsAndValues)/2)
+		for pairIndex := 0; pairIndex < len(fieldsAndValues); pairIndex += 2 {
+			hashFields[fieldsAndValues[pairIndex]] = fieldsAndValues[pairIndex+1]
+		}
+		return storageKey, &storage.RedisStorageValue{StoredData: &storage.RedisHashStructure{HashFields: hashFields}, DataType: storage.RedisHashType}, nil
+
+	case snapshotValueTypeHashWithFieldExpirations:
+		redisHashStructure, readError := readSnapshotHashWithFieldExpirations(contentReader)
+		if readError != nil {
+			return "", nil, corruptedValueError
+		}
+		return storageKey, &storage.RedisStorageValue{StoredData: redisHashStructure, DataType: storage.RedisHashType}, nil
+
+	case snapshotValueTypeSortedSet:
+		memberCount, readError := binary.ReadUvarint(contentReader)
+		if readError != nil || memberCount > uint64(contentReader.Len()) {
+			return "", nil, corruptedValueError
+		}
+		sortedSetMembers := make([]storage.SortedSetMember, 0, memberCount)
+		for memberIndex := uint64(0); memberIndex < memberCount; memberIndex++ {
+			memberName, nameError := readSnapshotString(contentReader)
+			scoreBits, scoreError := readSnapshotInt64(contentReader)
+			if nameError != nil || scoreError != nil {
+				return "", nil, corruptedValueError
+			}
+			sortedSetMembers = append(sortedSetMembers, storage.SortedSetMember{MemberName: memberName, MemberScore: math.Float64frombits(uint64(scoreBits))})
+		}
+		return storageKey, &storage.RedisStorageValue{StoredData: storage.NewRedisSortedSetFromMembers(sortedSetMembers), DataType: storage.RedisZSetType}, nil
+
+	default:
+		return "", nil, fmt.Errorf("%w: type de valeur inconnu %d", ErrSnapshotCorrupted, valueType)
+	}
+}
+
+// readSnapshotHashWithFieldExpirations lit un hash dont des fields ont un TTL, les fields déjà expirés sont ignorés
+func readSnapshotHashWithFieldExpirations(contentReader *bytes.Reader) (*storage.RedisHashStructure, error) {
+	fieldCount, readError := binary.ReadUvarint(contentReader)
+	if readError != nil || fieldCount > uint64(contentReader.Len()) {
+		return nil, ErrSnapshotCorrupted
+	}
+
+	currentTime := time.Now()
+	redisHashStructure := &storage.RedisHashStructure{HashFields: make(map[string]string, fieldCount)}
+	for fieldIndex := uint64(0); fieldIndex < fieldCount; fieldIndex++ {
+		fieldName, nameError := readSnapshotString(contentReader)
+		fieldValue, valueError := readSnapshotString(contentReader)
+		fieldExpirationMilliseconds, expirationError := readSnapshotInt64(contentReader)
+		if nameError != nil || valueError != nil || expirationError != nil {
+			return nil, ErrSnapshotCorrupted
+		}
+		if fieldExpirationMilliseconds == 0 {
+			redisHashStructure.HashFields[fieldName] = fieldValue
+			continue
+		}
+		expirationTime := time.UnixMilli(fieldExpirationMilliseconds)
+		if !currentTime.Before(expirationTime) {
+			continue
+		}
+		if redisHashStructure.FieldExpirations == nil {
+			redisHashStructure.FieldExpirations = make(map[string]time.Time)
+		}
+		redisHashStructure.HashFields[fieldName] = fieldValue
+		redisHashStructure.FieldExpirations[fieldName] = expirationTime
+	}
+	return redisHashStructure, nil
+}
+
+// readSnapshotStringSequence lit un compteur puis compteur*itemsPerEntry chaînes
+func readSnapshotStringSequence(contentReader *bytes.Reader, itemsPerEntry int) ([]string, error) {
+	entryCount, readError := binary.ReadUvarint(contentReader)
+	if readError != nil || entryCount > uint64(contentReader.Len()) {
+		return nil, ErrSnapshotCorrupted
+	}
+
+	sequenceItems := make([]string, 0, int(entryCount)*itemsPerEntry)
+	for itemIndex := 0; itemIndex < int(entryCount)*itemsPerEntry; itemIndex++ {
+		sequenceItem, readError := readSnapshotString(contentReader)
+		if readError != nil {
+			return nil, readError
+		}
+		sequenceItems = append(sequenceItems, sequenceItem)
+	}
+
+	return sequenceItems, nil
+}
+
+// readSnapshotString lit une chaîne préfixée par sa longueur
+func readSnapshotString(contentReader *bytes.Reader) (string, error) {
+	stringLength, readError := binary.ReadUvarint(contentReader)
+	if readError != nil || stringLength > uint64(contentReader.Len()) {
+		return "", ErrSnapshotCorrupted
+	}
+
+	stringBytes := make([]byte, stringLength)
+	if _, readError := io.ReadFull(contentReader, stringBytes); readError != nil {
+		return "", ErrSnapshotCorrupted
+	}
+	return string(stringBytes), nil
+}
+
+// readSnapshotInt64 lit un entier 64 bits little endian
+func readSnapshotInt64(contentReader *bytes.Reader) (int64, error) {
+	var integerBytes [8]byte
+	if _, readError := io.ReadFull(contentReader, integerBytes[:]); readError != nil {
+		return 0, ErrSnapshotCorrupted
+	}
+	return int64(binary.LittleEndian.Uint64(integerBytes[:])), nil
+}
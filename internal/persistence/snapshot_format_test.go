@@ -0,0 +1,218 @@
+package persistence
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"redis-go/internal/storage"
+)
+
+// comparableSnapshotValue réduit une valeur stockée à une forme comparable avec reflect.DeepEqual
+// (la skiplist d'un sorted set dépend du tirage aléatoire des niveaux, seuls les scores sont comparés)
+func comparableSnapshotValue(storageValue *storage.RedisStorageValue) map[string]interface{} {
+	comparableValue := map[string]interface{}{"type": storageValue.DataType}
+	if storageValue.ExpirationTime != nil {
+		comparableValue["expiration"] = storageValue.ExpirationTime.UnixMilli()
+	}
+	switch storedData := storageValue.StoredData.(type) {
+	case string:
+		comparableValue["string"] = storedData
+	case *storage.RedisListStructure:
+		comparableValue["list"] = storedData.ListElements
+	case *storage.RedisSetStructure:
+		comparableValue["set"] = storedData.SetElements
+	case *storage.RedisHashStructure:
+		comparableValue["hash"] = storedData.HashFields
+		fieldExpirations := make(map[string]int64)
+		for fieldName, expirationTime := range storedData.FieldExpirations {
+			fieldExpirations[fieldName] = expirationTime.UnixMilli()
+		}
+		comparableValue["fieldExpirations"] = fieldExpirations
+	case *storage.RedisSortedSetStructure:
+		comparableValue["zset"] = storedData.MemberScores
+	}
+	return comparableValue
+}
+
+// comparableDatabases applique comparableSnapshotValue à toutes les clés de toutes les bases non vides
+func comparableDatabases(databaseEntries map[int]map[string]*storage.RedisStorageValue) map[int]map[string]map[string]interface{} {
+	comparableEntries := make(map[int]map[string]map[string]interface{})
+	for databaseIndex, storageEntries := range databaseEntries {
+		if len(storageEntries) == 0 {
+			continue
+		}
+		comparableEntries[databaseIndex] = make(map[string]map[string]interface{})
+		for storageKey, storageValue := range storageEntries {
+			comparableEntries[databaseIndex][storageKey] = comparableSnapshotValue(storageValue)
+		}
+	}
+	return comparableEntries
+}
+
+// snapshotTestDatabases retourne un jeu de données couvrant chaque type, avec et sans expiration, sur deux bases
+// Les expirations sont calculées à partir de referenceTime pour que deux appels produisent les mêmes données
+func snapshotTestDatabases(referenceTime time.Time) []map[string]*storage.RedisStorageValue {
+	futureExpiration := referenceTime.Add(time.Hour).Truncate(time.Millisecond)
+	pastExpiration := referenceTime.Add(-time.Hour)
+	return []map[string]*storage.RedisStorageValue{
+		{
+			"string":           {StoredData: "valeur\x00binaire", DataType: storage.RedisStringType},
+			"empty":            {StoredData: "", DataType: storage.RedisStringType},
+			"volatile":         {StoredData: "v", DataType: storage.RedisStringType, ExpirationTime: &futureExpiration},
+			"expired":          {StoredData: "v", DataType: storage.RedisStringType, ExpirationTime: &pastExpiration},
+			"list":             {StoredData: &storage.RedisListStructure{ListElements: []string{"a", "b", "a"}}, DataType: storage.RedisListType},
+			"set":              {StoredData: &storage.RedisSetStructure{SetElements: map[string]bool{"x": true, "y": true}}, DataType: storage.RedisSetType},
+			"hash":             {StoredData: &storage.RedisHashStructure{HashFields: map[string]string{"f": "1", "g": "2"}}, DataType: storage.RedisHashType},
+			"hashWithTtl":      {StoredData: &storage.RedisHashStructure{HashFields: map[string]string{"f": "1", "g": "2"}, FieldExpirations: map[string]time.Time{"g": futureExpiration}}, DataType: storage.RedisHashType},
+			"hashExpiredField": {StoredData: &storage.RedisHashStructure{HashFields: map[string]string{"f": "1", "g": "2"}, FieldExpirations: map[string]time.Time{"g": pastExpiration}}, DataType: storage.RedisHashType},
+			"zset": {StoredData: storage.NewRedisSortedSetFromMembers([]storage.SortedSetMember{
+				{MemberName: "low", MemberScore: math.Inf(-1)}, {MemberName: "mid", MemberScore: 0.1}, {MemberName: "high", MemberScore: math.Inf(1)},
+			}), DataType: storage.RedisZSetType},
+		},
+		{},
+		{
+			"string": {StoredData: "base 2", DataType: storage.RedisStringType, ExpirationTime: &futureExpiration},
+		},
+	}
+}
+
+// expectedSnapshotDatabases retourne snapshotTestDatabases tel qu'il doit être relu : clés et fields expirés retirés
+func expectedSnapshotDatabases(referenceTime time.Time) map[int]map[string]*storage.RedisStorageValue {
+	expectedEntries := make(map[int]map[string]*storage.RedisStorageValue)
+	for databaseIndex, storageEntries := range snapshotTestDatabases(referenceTime) {
+		expectedEntries[databaseIndex] = storageEntries
+	}
+	delete(expectedEntries[0], "expired")
+	expectedEntries[0]["hashExpiredField"] = &storage.RedisStorageValue{StoredData: &storage.RedisHashStructure{HashFields: map[string]string{"f": "1"}}, DataType: storage.RedisHashType}
+	return expectedEntries
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	referenceTime := time.Now()
+	var snapshotBuffer bytes.Buffer
+	if writeError := WriteSnapshot(&snapshotBuffer, snapshotTestDatabases(referenceTime)); writeError != nil {
+		t.Fatalf("écriture du snapshot: %v", writeError)
+	}
+
+	loadedEntries, readError := ReadSnapshot(snapshotBuffer.Bytes())
+	if readError != nil {
+		t.Fatalf("lecture du snapshot: %v", readError)
+	}
+	if actual, expected := comparableDatabases(loadedEntries), comparableDatabases(expectedSnapshotDatabases(referenceTime)); !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("snapshot relu:\n%v\nattendu:\n%v", actual, expected)
+	}
+
+	// Le sorted set relu doit être utilisable (skiplist reconstruite)
+	loadedStorage := storage.NewRedisInMemoryStorage()
+	loadedStorage.ReplaceStorageEntries(loadedEntries[0])
+	rangeMembers, rangeError := loadedStorage.GetSortedSetRangeByRank("zset", 0, -1, false)
+	if rangeError != nil || len(rangeMembers) != 3 || rangeMembers[0].MemberName != "low" || rangeMembers[2].MemberName != "high" {
+		t.Fatalf("sorted set relu: %v, erreur %v", rangeMembers, rangeError)
+	}
+}
+
+func TestReadSnapshotRejectsCorruptedContent(t *testing.T) {
+	referenceTime := time.Now()
+	var snapshotBuffer bytes.Buffer
+	if writeError := WriteSnapshot(&snapshotBuffer, snapshotTestDatabases(referenceTime)); writeError != nil {
+		t.Fatalf("écriture du snapshot: %v", writeError)
+	}
+	validSnapshot := snapshotBuffer.Bytes()
+
+	withFlippedByte := func(byteIndex int) []byte {
+		corruptedSnapshot := bytes.Clone(validSnapshot)
+		corruptedSnapshot[byteIndex] ^= 0xFF
+		return corruptedSnapshot
+	}
+	testCases := []struct {
+		name            string
+		snapshotContent []byte
+	}{
+		{name: "fichier vide", snapshotContent: nil},
+		{name: "en-tête inconnu", snapshotContent: []byte("NOTREDIS\x01\xFF")},
+		{name: "tronqué au milieu", snapshotContent: validSnapshot[:len(validSnapshot)/2]},
+		{name: "checksum absent", snapshotContent: validSnapshot[:len(validSnapshot)-8]},
+		{name: "donnée altérée", snapshotContent: withFlippedByte(len(snapshotMagicHeader) + 4)},
+		{name: "checksum altéré", snapshotContent: withFlippedByte(len(validSnapshot) - 1)},
+		{name: "données après la fin", snapshotContent: append(bytes.Clone(validSnapshot), 0)},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if _, readError := ReadSnapshot(testCase.snapshotContent); !errors.Is(readError, ErrSnapshotCorrupted) {
+				t.Fatalf("erreur %v, attendu ErrSnapshotCorrupted", readError)
+			}
+		})
+	}
+
+	unsupportedVersion := bytes.Clone(validSnapshot)
+	unsupportedVersion[len(snapshotMagicHeader)] = snapshotFormatVersion + 1
+	if _, readError := ReadSnapshot(unsupportedVersion); readError == nil {
+		t.Fatalf("une version inconnue doit être refusée")
+	}
+}
+
+func TestSnapshotManagerSavesAndReloadsAllDatabases(t *testing.T) {
+	referenceTime := time.Now()
+	snapshotFilePath := filepath.Join(t.TempDir(), "dump.rdb")
+	changeCount := int64(7)
+	changeCounter := func() int64 { return changeCount }
+
+	sourceStorages := storage.NewRedisDatabaseStorages(16)
+	for databaseIndex, storageEntries := range snapshotTestDatabases(referenceTime) {
+		sourceStorages[databaseIndex].ReplaceStorageEntries(storageEntries)
+	}
+	sourceManager := NewRedisSnapshotManager(snapshotFilePath, sourceStorages, changeCounter)
+	if saveError := sourceManager.SaveSnapshot(); saveError != nil {
+		t.Fatalf("SAVE: %v", saveError)
+	}
+	if !sourceManager.HasLastSaveSucceeded() || sourceManager.GetChangesSinceLastSave() != 0 {
+		t.Fatalf("après SAVE: succès %v, modifications %d", sourceManager.HasLastSaveSucceeded(), sourceManager.GetChangesSinceLastSave())
+	}
+
+	testCases := []struct {
+		name          string
+		databaseCount int
+		expectedError bool
+	}{
+		{name: "même nombre de bases", databaseCount: 16},
+		{name: "bases supplémentaires", databaseCount: 32},
+		{name: "base du fichier absente de la configuration", databaseCount: 2, expectedError: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			loadedStorages := storage.NewRedisDatabaseStorages(testCase.databaseCount)
+			loadedKeyCount, loadError := NewRedisSnapshotManager(snapshotFilePath, loadedStorages, changeCounter).LoadSnapshotFromDisk()
+			if testCase.expectedError {
+				if loadError == nil {
+					t.Fatalf("chargement accepté, attendu une erreur")
+				}
+				return
+			}
+			if loadError != nil {
+				t.Fatalf("chargement: %v", loadError)
+			}
+
+			expectedEntries := expectedSnapshotDatabases(referenceTime)
+			if expectedKeyCount := len(expectedEntries[0]) + len(expectedEntries[2]); loadedKeyCount != expectedKeyCount {
+				t.Fatalf("%d clés chargées, attendu %d", loadedKeyCount, expectedKeyCount)
+			}
+			loadedEntries := make(map[int]map[string]*storage.RedisStorageValue)
+			for databaseIndex, storageEntries := range CloneAllDatabases(loadedStorages) {
+				loadedEntries[databaseIndex] = storageEntries
+			}
+			if actual, expected := comparableDatabases(loadedEntries), comparableDatabases(expectedEntries); !reflect.DeepEqual(actual, expected) {
+				t.Fatalf("bases rechargées:\n%v\nattendu:\n%v", actual, expected)
+			}
+		})
+	}
+
+	missingFileStorages := storage.NewRedisDatabaseStorages(16)
+	if loadedKeyCount, loadError := NewRedisSnapshotManager(filepath.Join(t.TempDir(), "absent.rdb"), missingFileStorages, changeCounter).LoadSnapshotFromDisk(); loadedKeyCount != 0 || loadError != nil {
+		t.Fatalf("snapshot absent: %d clés, erreur %v, attendu un démarrage à vide", loadedKeyCount, loadError)
+	}
+}
@@ -0,0 +1,211 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"redis-go/internal/storage"
+)
+
+// ErrBackgroundSaveInProgress indique qu'une sauvegarde est déjà en cours
+var ErrBackgroundSaveInProgress = errors.New("une sauvegarde en arrière-plan est déjà en cours")
+
+// RedisSnapshotManager gère l'écriture et le chargement des snapshots sur disque
+type RedisSnapshotManager struct {
+	snapshotFilePath      string
+	databaseStorages      []*storage.RedisInMemoryStorage
+	changeCounter         func() int64
+	saveMutex             sync.Mutex
+	saveInProgress        bool
+	lastSaveTime          time.Time
+	lastSaveSucceeded     bool
+	changeCountAtLastSave int64
+}
+
+// NewRedisSnapshotManager crée un gestionnaire de snapshots
+// databaseStorages contient toutes les bases logiques, changeCounter retourne le nombre total
+// de modifications (pour la politique de sauvegarde)
+func NewRedisSnapshotManager(snapshotFilePath string, databaseStorages []*storage.RedisInMemoryStorage, changeCounter func() int64) *RedisSnapshotManager {
+	return &RedisSnapshotManager{
+		snapshotFilePath:  snapshotFilePath,
+		databaseStorages:  databaseStorages,
+		changeCounter:     changeCounter,
+		lastSaveTime:      time.Now(),
+		lastSaveSucceeded: true,
+	}
+}
+
+// LoadSnapshotFromDisk charge le snapshot s'il existe et retourne le nombre de clés chargées
+func (snapshotManager *RedisSnapshotManager) LoadSnapshotFromDisk() (int, error) {
+	snapshotContent, readError := os.ReadFile(snapshotManager.snapshotFilePath)
+	if errors.Is(readError, os.ErrNotExist) {
+		return 0, nil
+	}
+	if readError != nil {
+		return 0, fmt.Errorf("lecture du snapshot %s impossible: %v", snapshotManager.snapshotFilePath, readError)
+	}
+
+	databaseEntries, parseError := ReadSnapshot(snapshotContent)
+	if parseError != nil {
+		return 0, fmt.Errorf("chargement du snapshot %s impossible: %w", snapshotManager.snapshotFilePath, parseError)
+	}
+
+	return RestoreAllDatabases(databaseEntries, snapshotManager.databaseStorages)
+}
+
+// RestoreAllDatabases remplace le contenu de chaque base par les entrées chargées (snapshot, synchronisation complète)
+// et retourne le nombre total de clés chargées
+func RestoreAllDatabases(databaseEntries map[int]map[string]*storage.RedisStorageValue, databaseStorages []*storage.RedisInMemoryStorage) (int, error) {
+	for databaseIndex := range databaseEntries {
+		if databaseIndex >= len(databaseStorages) {
+			return 0, fmt.Errorf("le fichier contient la base %d mais seules %d bases sont configurées (REDIS_DATABASES)", databaseIndex, len(databaseStorages))
+		}
+	}
+
+	loadedKeyCount := 0
+	for databaseIndex, databaseStorage := range databaseStorages {
+		loadedEntries := databaseEntries[databaseIndex]
+		if loadedEntries == nil {
+			loadedEntries = make(map[string]*storage.RedisStorageValue)
+		}
+		databaseStorage.ReplaceStorageEntries(loadedEntries)
+		loadedKeyCount += len(loadedEntries)
+	}
+
+	return loadedKeyCount, nil
+}
+
+// CloneAllDatabases copie les clés non expirées de chaque base (une entrée par index de base)
+func CloneAllDatabases(databaseStorages []*storage.RedisInMemoryStorage) []map[string]*storage.RedisStorageValue {
+	clonedDatabases := make([]map[string]*storage.RedisStorageValue, len(databaseStorages))
+	for databaseIndex, databaseStorage := range databaseStorages {
+		clonedDatabases[databaseIndex] = databaseStorage.CloneStorageEntries()
+	}
+	return clonedDatabases
+}
+
+// SaveSnapshot écrit un snapshot de manière synchrone (SAVE)
+func (snapshotManager *RedisSnapshotManager) SaveSnapshot() error {
+	if beginError := snapshotManager.beginSave(); beginError != nil {
+		return beginError
+	}
+
+	changeCountAtStart := snapshotManager.changeCounter()
+	saveError := snapshotManager.writeSnapshotFile(CloneAllDatabases(snapshotManager.databaseStorages))
+	snapshotManager.finishSave(saveError, changeCountAtStart)
+	return saveError
+}
+
+// StartBackgroundSave lance un snapshot en arrière-plan (BGSAVE)
+// Seule la copie des données bloque les clients, l'écriture disque se fait dans une goroutine
+func (snapshotManager *RedisSnapshotManager) StartBackgroundSave(saveCompleted func(error)) error {
+	if beginError := snapshotManager.beginSave(); beginError != nil {
+		return beginError
+	}
+
+	changeCountAtStart := snapshotManager.changeCounter()
+	clonedDatabases := CloneAllDatabases(snapshotManager.databaseStorages)
+
+	go func() {
+		saveError := snapshotManager.writeSnapshotFile(clonedDatabases)
+		snapshotManager.finishSave(saveError, changeCountAtStart)
+		if saveCompleted != nil {
+			saveCompleted(saveError)
+		}
+	}()
+
+	return nil
+}
+
+// beginSave réserve le droit d'écrire un snapshot (une seule sauvegarde à la fois)
+func (snapshotManager *RedisSnapshotManager) beginSave() error {
+	snapshotManager.saveMutex.Lock()
+	defer snapshotManager.saveMutex.Unlock()
+
+	if snapshotManager.saveInProgress {
+		return ErrBackgroundSaveInProgress
+	}
+	snapshotManager.saveInProgress = true
+	return nil
+}
+
+// finishSave enregistre le résultat d'une sauvegarde
+func (snapshotManager *RedisSnapshotManager) finishSave(saveError error, changeCountAtStart int64) {
+	snapshotManager.saveMutex.Lock()
+	defer snapshotManager.saveMutex.Unlock()
+
+	snapshotManager.saveInProgress = false
+	snapshotManager.lastSaveSucceeded = saveError == nil
+	if saveError == nil {
+		snapshotManager.lastSaveTime = time.Now()
+		snapshotManager.changeCountAtLastSave = changeCountAtStart
+	}
+}
+
+// writeSnapshotFile écrit le snapshot dans un fichier temporaire puis le renomme (écriture atomique)
+func (snapshotManager *RedisSnapshotManager) writeSnapshotFile(databaseEntries []map[string]*storage.RedisStorageValue) error {
+	snapshotDirectory := filepath.Dir(snapshotManager.snapshotFilePath)
+	if mkdirError := os.MkdirAll(snapshotDirectory, 0o755); mkdirError != nil {
+		return fmt.Errorf("création du dossier %s impossible: %v", snapshotDirectory, mkdirError)
+	}
+
+	temporaryFile, createError := os.CreateTemp(snapshotDirectory, "temp-snapshot-*.rdb")
+	if createError != nil {
+		return fmt.Errorf("création du fichier temporaire impossible: %v", createError)
+	}
+	temporaryFilePath := temporaryFile.Name()
+	defer os.Remove(temporaryFilePath)
+
+	if writeError := WriteSnapshot(temporaryFile, databaseEntries); writeError != nil {
+		temporaryFile.Close()
+		return fmt.Errorf("écriture du snapshot impossible: %v", writeError)
+	}
+	if syncError := temporaryFile.Sync(); syncError != nil {
+		temporaryFile.Close()
+		return fmt.Errorf("fsync du snapshot impossible: %v", syncError)
+	}
+	if closeError := temporaryFile.Close(); closeError != nil {
+		return fmt.Errorf("fermeture du snapshot impossible: %v", closeError)
+	}
+
+	return os.Rename(temporaryFilePath, snapshotManager.snapshotFilePath)
+}
+
+// ResetChangesSinceLastSave considère le dataset courant comme sauvegardé (après un chargement)
+func (snapshotManager *RedisSnapshotManager) ResetChangesSinceLastSave() {
+	snapshotManager.saveMutex.Lock()
+	defer snapshotManager.saveMutex.Unlock()
+	snapshotManager.changeCountAtLastSave = snapshotManager.changeCounter()
+}
+
+// GetLastSaveTime retourne la date de la dernière sauvegarde réussie
+func (snapshotManager *RedisSnapshotManager) GetLastSaveTime() time.Time {
+	snapshotManager.saveMutex.Lock()
+	defer snapshotManager.saveMutex.Unlock()
+	return snapshotManager.lastSaveTime
+}
+
+// GetChangesSinceLastSave retourne le nombre de modifications depuis la dernière sauvegarde réussie
+func (snapshotManager *RedisSnapshotManager) GetChangesSinceLastSave() int64 {
+	snapshotManager.saveMutex.Lock()
+	defer snapshotManager.saveMutex.Unlock()
+	return snapshotManager.changeCounter() - snapshotManager.changeCountAtLastSave
+}
+
+// IsSaveInProgress indique si une sauvegarde est en cours
+func (snapshotManager *RedisSnapshotManager) IsSaveInProgress() bool {
+	snapshotManager.saveMutex.Lock()
+	defer snapshotManager.saveMutex.Unlock()
+	return snapshotManager.saveInProgress
+}
+
+// HasLastSaveSucceeded indique si la dernière tentative de sauvegarde a réussi
+func (snapshotManager *RedisSnapshotManager) HasLastSaveSucceeded() bool {
+	snapshotManager.saveMutex.Lock()
+	defer snapshotManager.saveMutex.Unlock()
+	return snapshotManager.lastSaveSucceeded
+}
@@ -7,4 +7,20 @@ const (
 	RedisIntegerType      = ':'
 	RedisBulkStringType   = '$'
 	RedisArrayType        = '*'
+
+	// Types ajoutés par RESP3 (négocié avec HELLO 3)
+	RedisNullType           = '_'
+	RedisBooleanType        = '#'
+	RedisDoubleType         = ','
+	RedisBigNumberType      = '('
+	RedisVerbatimStringType = '='
+	RedisMapType            = '%'
+	RedisSetType            = '~'
+	RedisPushType           = '>'
+)
+
+// Versions du protocole négociables avec HELLO
+const (
+	RedisProtocolVersion2 = 2
+	RedisProtocolVersion3 = 3
 )
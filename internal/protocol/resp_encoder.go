@@ -3,16 +3,36 @@ package protocol
 import (
 	"fmt"
 	"io"
+	"math"
+	"strconv"
 )
 
 // RedisSerializationProtocolEncoder pour l'encodage des réponses RESP
+// Les types RESP3 sont dégradés vers leur équivalent RESP2 tant que le client n'a pas négocié HELLO 3
 type RedisSerializationProtocolEncoder struct {
-	outputWriter io.Writer
+	outputWriter      io.Writer
+	writtenErrorCount int
+	protocolVersion   int
 }
 
-// NewRedisSerializationProtocolEncoder crée un nouveau encoder RESP
+// NewRedisSerializationProtocolEncoder crée un nouveau encoder RESP (RESP2 par défaut)
 func NewRedisSerializationProtocolEncoder(outputWriter io.Writer) *RedisSerializationProtocolEncoder {
-	return &RedisSerializationProtocolEncoder{outputWriter: outputWriter}
+	return &RedisSerializationProtocolEncoder{outputWriter: outputWriter, protocolVersion: RedisProtocolVersion2}
+}
+
+// SetProtocolVersion change la version du protocole utilisée pour les réponses suivantes (HELLO)
+func (redisEncoder *RedisSerializationProtocolEncoder) SetProtocolVersion(protocolVersion int) {
+	redisEncoder.protocolVersion = protocolVersion
+}
+
+// GetProtocolVersion retourne la version du protocole négociée (2 ou 3)
+func (redisEncoder *RedisSerializationProtocolEncoder) GetProtocolVersion() int {
+	return redisEncoder.protocolVersion
+}
+
+// IsResp3 indique si le client a négocié RESP3
+func (redisEncoder *RedisSerializationProtocolEncoder) IsResp3() bool {
+	return redisEncoder.protocolVersion >= RedisProtocolVersion3
 }
 
 // WriteSimpleStringResponse écrit une simple string (+OK)
@@ -23,6 +43,7 @@ func (redisEncoder *RedisSerializationProtocolEncoder) WriteSimpleStringResponse
 
 // WriteErrorResponse écrit une erreur (-ERR message)
 func (redisEncoder *RedisSerializationProtocolEncoder) WriteErrorResponse(errorMessage string) error {
+	redisEncoder.writtenErrorCount++
 	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "-%s\r\n", errorMessage)
 	return writeError
 }
@@ -39,12 +60,78 @@ func (redisEncoder *RedisSerializationProtocolEncoder) WriteBulkStringResponse(b
 	return writeError
 }
 
-// WriteNullBulkStringResponse écrit une bulk string null ($-1\r\n)
+// WriteNullBulkStringResponse écrit une bulk string null ($-1\r\n, ou _\r\n en RESP3)
 func (redisEncoder *RedisSerializationProtocolEncoder) WriteNullBulkStringResponse() error {
+	if redisEncoder.IsResp3() {
+		return redisEncoder.WriteNullResponse()
+	}
 	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "$-1\r\n")
 	return writeError
 }
 
+// WriteNullArrayResponse écrit un array null (*-1\r\n, ou _\r\n en RESP3), ex: EXEC annulé par WATCH
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteNullArrayResponse() error {
+	if redisEncoder.IsResp3() {
+		return redisEncoder.WriteNullResponse()
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "*-1\r\n")
+	return writeError
+}
+
+// WriteNullResponse écrit le null RESP3 (_\r\n), ou une bulk string null en RESP2
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteNullResponse() error {
+	if !redisEncoder.IsResp3() {
+		return redisEncoder.WriteNullBulkStringResponse()
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "_\r\n")
+	return writeError
+}
+
+// WriteBooleanResponse écrit un booléen (#t / #f), ou l'entier 1 / 0 en RESP2
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteBooleanResponse(booleanValue bool) error {
+	if !redisEncoder.IsResp3() {
+		if booleanValue {
+			return redisEncoder.WriteIntegerResponse(1)
+		}
+		return redisEncoder.WriteIntegerResponse(0)
+	}
+	booleanCode := "f"
+	if booleanValue {
+		booleanCode = "t"
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "#%s\r\n", booleanCode)
+	return writeError
+}
+
+// WriteDoubleResponse écrit un nombre décimal (,3.14), ou une bulk string en RESP2
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteDoubleResponse(doubleValue float64) error {
+	formattedDouble := formatDoubleValue(doubleValue)
+	if !redisEncoder.IsResp3() {
+		return redisEncoder.WriteBulkStringResponse(formattedDouble)
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, ",%s\r\n", formattedDouble)
+	return writeError
+}
+
+// WriteBigNumberResponse écrit un grand entier déjà formaté ((1234...), ou une bulk string en RESP2
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteBigNumberResponse(bigNumber string) error {
+	if !redisEncoder.IsResp3() {
+		return redisEncoder.WriteBulkStringResponse(bigNumber)
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "(%s\r\n", bigNumber)
+	return writeError
+}
+
+// WriteVerbatimStringResponse écrit une chaîne verbatim (=15\r\ntxt:Some string), ou une bulk string en RESP2
+// textFormat fait exactement 3 caractères (txt, mkd)
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteVerbatimStringResponse(textFormat string, verbatimString string) error {
+	if !redisEncoder.IsResp3() {
+		return redisEncoder.WriteBulkStringResponse(verbatimString)
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "=%d\r\n%s:%s\r\n", len(textFormat)+1+len(verbatimString), textFormat, verbatimString)
+	return writeError
+}
+
 // WriteArrayResponse écrit un array (*2\r\n$3\r\nfoo\r\n$3\r\nbar\r\n)
 func (redisEncoder *RedisSerializationProtocolEncoder) WriteArrayResponse(arrayElements []string) error {
 	if _, writeError := fmt.Fprintf(redisEncoder.outputWriter, "*%d\r\n", len(arrayElements)); writeError != nil {
@@ -59,3 +146,95 @@ func (redisEncoder *RedisSerializationProtocolEncoder) WriteArrayResponse(arrayE
 
 	return nil
 }
+
+// WriteArrayHeader écrit l'en-tête d'un array dont les éléments seront écrits ensuite (*3\r\n)
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteArrayHeader(arrayLength int) error {
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "*%d\r\n", arrayLength)
+	return writeError
+}
+
+// WriteMapHeader écrit l'en-tête d'une map de mapLength paires (%2\r\n)
+// En RESP2 la map devient un array alternant clés et valeurs
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteMapHeader(mapLength int) error {
+	if !redisEncoder.IsResp3() {
+		return redisEncoder.WriteArrayHeader(mapLength * 2)
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "%%%d\r\n", mapLength)
+	return writeError
+}
+
+// WriteSetHeader écrit l'en-tête d'un ensemble (~3\r\n), un array en RESP2
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteSetHeader(setLength int) error {
+	if !redisEncoder.IsResp3() {
+		return redisEncoder.WriteArrayHeader(setLength)
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, "~%d\r\n", setLength)
+	return writeError
+}
+
+// WritePushHeader écrit l'en-tête d'un message poussé hors requête (>3\r\n), un array en RESP2
+func (redisEncoder *RedisSerializationProtocolEncoder) WritePushHeader(pushLength int) error {
+	if !redisEncoder.IsResp3() {
+		return redisEncoder.WriteArrayHeader(pushLength)
+	}
+	_, writeError := fmt.Fprintf(redisEncoder.outputWriter, ">%d\r\n", pushLength)
+	return writeError
+}
+
+// WriteMapResponse écrit une map de chaînes (l'ordre des paires est celui du slice fourni)
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteMapResponse(alternatingKeysAndValues []string) error {
+	if writeError := redisEncoder.WriteMapHeader(len(alternatingKeysAndValues) / 2); writeError != nil {
+		return writeError
+	}
+	for _, mapElement := range alternatingKeysAndValues {
+		if writeError := redisEncoder.WriteBulkStringResponse(mapElement); writeError != nil {
+			return writeError
+		}
+	}
+	return nil
+}
+
+// WriteSetResponse écrit un ensemble de chaînes
+func (redisEncoder *RedisSerializationProtocolEncoder) WriteSetResponse(setMembers []string) error {
+	if writeError := redisEncoder.WriteSetHeader(len(setMembers)); writeError != nil {
+		return writeError
+	}
+	for _, setMember := range setMembers {
+		if writeError := redisEncoder.WriteBulkStringResponse(setMember); writeError != nil {
+			return writeError
+		}
+	}
+	return nil
+}
+
+// WritePushResponse écrit un message poussé composé de chaînes (pub/sub)
+func (redisEncoder *RedisSerializationProtocolEncoder) WritePushResponse(pushElements []string) error {
+	if writeError := redisEncoder.WritePushHeader(len(pushElements)); writeError != nil {
+		return writeError
+	}
+	for _, pushElement := range pushElements {
+		if writeError := redisEncoder.WriteBulkStringResponse(pushElement); writeError != nil {
+			return writeError
+		}
+	}
+	return nil
+}
+
+// formatDoubleValue formate un décimal comme Redis (représentation la plus courte, inf/-inf/nan)
+func formatDoubleValue(doubleValue float64) string {
+	switch {
+	case math.IsInf(doubleValue, 1):
+		return "inf"
+	case math.IsInf(doubleValue, -1):
+		return "-inf"
+	case math.IsNaN(doubleValue):
+		return "nan"
+	default:
+		return strconv.FormatFloat(doubleValue, 'g', -1, 64)
+	}
+}
+
+// GetWrittenErrorCount retourne le nombre d'erreurs écrites (permet de savoir si une commande a échoué)
+func (redisEncoder *RedisSerializationProtocolEncoder) GetWrittenErrorCount() int {
+	return redisEncoder.writtenErrorCount
+}
@@ -0,0 +1,147 @@
+package protocol
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestEncoderDegradesResp3TypesForResp2(t *testing.T) {
+	testCases := []struct {
+		name          string
+		writeReply    func(protocolEncoder *RedisSerializationProtocolEncoder) error
+		expectedResp2 string
+		expectedResp3 string
+	}{
+		{
+			name:          "null",
+			writeReply:    (*RedisSerializationProtocolEncoder).WriteNullResponse,
+			expectedResp2: "$-1\r\n",
+			expectedResp3: "_\r\n",
+		},
+		{
+			name:          "bulk string null",
+			writeReply:    (*RedisSerializationProtocolEncoder).WriteNullBulkStringResponse,
+			expectedResp2: "$-1\r\n",
+			expectedResp3: "_\r\n",
+		},
+		{
+			name:          "array null",
+			writeReply:    (*RedisSerializationProtocolEncoder).WriteNullArrayResponse,
+			expectedResp2: "*-1\r\n",
+			expectedResp3: "_\r\n",
+		},
+		{
+			name: "booléen vrai",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteBooleanResponse(true)
+			},
+			expectedResp2: ":1\r\n",
+			expectedResp3: "#t\r\n",
+		},
+		{
+			name: "booléen faux",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteBooleanResponse(false)
+			},
+			expectedResp2: ":0\r\n",
+			expectedResp3: "#f\r\n",
+		},
+		{
+			name: "double",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteDoubleResponse(3.14)
+			},
+			expectedResp2: "$4\r\n3.14\r\n",
+			expectedResp3: ",3.14\r\n",
+		},
+		{
+			name: "double infini négatif",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteDoubleResponse(math.Inf(-1))
+			},
+			expectedResp2: "$4\r\n-inf\r\n",
+			expectedResp3: ",-inf\r\n",
+		},
+		{
+			name: "grand nombre",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteBigNumberResponse("3492890328409238509324850943850943825024385")
+			},
+			expectedResp2: "$43\r\n3492890328409238509324850943850943825024385\r\n",
+			expectedResp3: "(3492890328409238509324850943850943825024385\r\n",
+		},
+		{
+			name: "chaîne verbatim",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteVerbatimStringResponse("txt", "Some string")
+			},
+			expectedResp2: "$11\r\nSome string\r\n",
+			expectedResp3: "=15\r\ntxt:Some string\r\n",
+		},
+		{
+			name: "map",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteMapResponse([]string{"f", "v"})
+			},
+			expectedResp2: "*2\r\n$1\r\nf\r\n$1\r\nv\r\n",
+			expectedResp3: "%1\r\n$1\r\nf\r\n$1\r\nv\r\n",
+		},
+		{
+			name: "ensemble",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteSetResponse([]string{"a", "b"})
+			},
+			expectedResp2: "*2\r\n$1\r\na\r\n$1\r\nb\r\n",
+			expectedResp3: "~2\r\n$1\r\na\r\n$1\r\nb\r\n",
+		},
+		{
+			name: "message poussé",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WritePushResponse([]string{"message", "ch"})
+			},
+			expectedResp2: "*2\r\n$7\r\nmessage\r\n$2\r\nch\r\n",
+			expectedResp3: ">2\r\n$7\r\nmessage\r\n$2\r\nch\r\n",
+		},
+		{
+			name: "les types RESP2 sont inchangés",
+			writeReply: func(protocolEncoder *RedisSerializationProtocolEncoder) error {
+				return protocolEncoder.WriteArrayResponse([]string{"", "ok"})
+			},
+			expectedResp2: "*2\r\n$0\r\n\r\n$2\r\nok\r\n",
+			expectedResp3: "*2\r\n$0\r\n\r\n$2\r\nok\r\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			for protocolVersion, expectedReply := range map[int]string{
+				RedisProtocolVersion2: testCase.expectedResp2,
+				RedisProtocolVersion3: testCase.expectedResp3,
+			} {
+				var replyBuffer bytes.Buffer
+				protocolEncoder := NewRedisSerializationProtocolEncoder(&replyBuffer)
+				protocolEncoder.SetProtocolVersion(protocolVersion)
+				if writeError := testCase.writeReply(protocolEncoder); writeError != nil {
+					t.Fatalf("RESP%d: erreur d'écriture %v", protocolVersion, writeError)
+				}
+				if replyBuffer.String() != expectedReply {
+					t.Fatalf("RESP%d: réponse %q, attendu %q", protocolVersion, replyBuffer.String(), expectedReply)
+				}
+			}
+		})
+	}
+}
+
+func TestEncoderCountsWrittenErrors(t *testing.T) {
+	var replyBuffer bytes.Buffer
+	protocolEncoder := NewRedisSerializationProtocolEncoder(&replyBuffer)
+	protocolEncoder.WriteSimpleStringResponse("OK")
+	protocolEncoder.WriteErrorResponse("ERREUR : première")
+	protocolEncoder.WriteErrorResponse("ERREUR : seconde")
+	if protocolEncoder.GetWrittenErrorCount() != 2 {
+		t.Fatalf("%d erreurs comptées, attendu 2", protocolEncoder.GetWrittenErrorCount())
+	}
+	if replyBuffer.String() != "+OK\r\n-ERREUR : première\r\n-ERREUR : seconde\r\n" {
+		t.Fatalf("réponses %q", replyBuffer.String())
+	}
+}
@@ -5,21 +5,51 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 )
 
+// maximumInlineCommandLength limite la taille d'une commande inline (comme Redis)
+const maximumInlineCommandLength = 64 * 1024
+
+// maximumReadAheadLength limite les données lues en avance pendant une attente (client-query-buffer-limit de Redis)
+const maximumReadAheadLength = 1024 * 1024 * 1024
+
 // RedisSerializationProtocolParser pour le parsing des commandes RESP
 type RedisSerializationProtocolParser struct {
 	bufferedReader *bufio.Reader
+	parserInput    *readAheadInput
+}
+
+// readAheadInput alimente le buffer du parser avec les données lues en avance (WatchForClosedInput)
+// avant de lire à nouveau la source
+type readAheadInput struct {
+	inputSource   io.Reader
+	readAheadData []byte
+}
+
+// Read implémente io.Reader
+func (parserInput *readAheadInput) Read(readBuffer []byte) (int, error) {
+	if len(parserInput.readAheadData) > 0 {
+		copiedLength := copy(readBuffer, parserInput.readAheadData)
+		parserInput.readAheadData = parserInput.readAheadData[copiedLength:]
+		if len(parserInput.readAheadData) == 0 {
+			parserInput.readAheadData = nil
+		}
+		return copiedLength, nil
+	}
+	return parserInput.inputSource.Read(readBuffer)
 }
 
 // NewRedisSerializationProtocolParser crée un nouveau parser RESP
 func NewRedisSerializationProtocolParser(inputReader io.Reader) *RedisSerializationProtocolParser {
+	parserInput := &readAheadInput{inputSource: inputReader}
 	return &RedisSerializationProtocolParser{
-		bufferedReader: bufio.NewReader(inputReader),
+		bufferedReader: bufio.NewReader(parserInput),
+		parserInput:    parserInput,
 	}
 }
 
-// ParseIncomingCommand parse une commande RESP complète
+// ParseIncomingCommand parse une commande RESP complète envoyée par un client (multibulk ou inline)
 func (redisParser *RedisSerializationProtocolParser) ParseIncomingCommand() ([]string, error) {
 	// Lecture du premier caractère pour déterminer le type
 	protocolTypeByte, readError := redisParser.bufferedReader.ReadByte()
@@ -31,16 +61,181 @@ func (redisParser *RedisSerializationProtocolParser) ParseIncomingCommand() ([]s
 	case RedisArrayType:
 		return redisParser.parseRedisArray()
 	default:
-		return nil, fmt.Errorf("expected array type, got %c", protocolTypeByte)
+		// Format inline (telnet, nc) : une ligne d'arguments séparés par des espaces
+		redisParser.bufferedReader.UnreadByte()
+		return redisParser.parseInlineCommand()
+	}
+}
+
+// ParseMultibulkCommand parse une commande au seul format multibulk (AOF, flux de réplication)
+// Le format inline est réservé aux clients : ailleurs, un autre octet signale un flux corrompu
+func (redisParser *RedisSerializationProtocolParser) ParseMultibulkCommand() ([]string, error) {
+	protocolTypeByte, readError := redisParser.bufferedReader.ReadByte()
+	if readError != nil {
+		return nil, readError
+	}
+	if protocolTypeByte != RedisArrayType {
+		return nil, fmt.Errorf("expected multibulk command, got %q", protocolTypeByte)
+	}
+	return redisParser.parseRedisArray()
+}
+
+// BufferedLength retourne le nombre d'octets lus depuis la source mais pas encore parsés
+// (position de la dernière commande parsée = octets lus depuis la source - BufferedLength)
+func (redisParser *RedisSerializationProtocolParser) BufferedLength() int {
+	return redisParser.bufferedReader.Buffered() + len(redisParser.parserInput.readAheadData)
+}
+
+// WatchForClosedInput lit la source en avance jusqu'à sa première erreur (connexion fermée, délai de lecture
+// dépassé...) et la retourne ; les données lues restent à parser, après celles déjà bufferisées
+// La fermeture est ainsi détectée même si des commandes envoyées à la suite (pipeline) attendent dans le buffer
+// Ne doit pas être appelée pendant un parsing : l'appelant attend son retour avant de parser à nouveau
+func (redisParser *RedisSerializationProtocolParser) WatchForClosedInput() error {
+	parserInput := redisParser.parserInput
+	readBuffer := make([]byte, 4096)
+	for {
+		readLength, readError := parserInput.inputSource.Read(readBuffer)
+		parserInput.readAheadData = append(parserInput.readAheadData, readBuffer[:readLength]...)
+		if readError != nil {
+			return readError
+		}
+		if len(parserInput.readAheadData) > maximumReadAheadLength {
+			return fmt.Errorf("too big read-ahead buffer")
+		}
+	}
+}
+
+// parseInlineCommand parse une commande inline terminée par \n (le \r final est optionnel)
+func (redisParser *RedisSerializationProtocolParser) parseInlineCommand() ([]string, error) {
+	var inlineLine []byte
+	for {
+		lineFragment, readError := redisParser.bufferedReader.ReadSlice('\n')
+		inlineLine = append(inlineLine, lineFragment...)
+		if len(inlineLine) > maximumInlineCommandLength {
+			return nil, fmt.Errorf("too big inline request")
+		}
+		if readError == nil {
+			break
+		}
+		if readError != bufio.ErrBufferFull {
+			return nil, readError
+		}
+	}
+
+	return splitInlineArguments(strings.TrimRight(string(inlineLine), "\r\n"))
+}
+
+// splitInlineArguments découpe une ligne inline en arguments
+// Gère les chaînes entre guillemets doubles (avec \n, \r, \t, \b, \a, \\, \" et \xHH)
+// et entre guillemets simples (avec \'), comme redis-cli
+func splitInlineArguments(inlineLine string) ([]string, error) {
+	var inlineArguments []string
+	linePosition := 0
+
+	for {
+		for linePosition < len(inlineLine) && isInlineWhitespace(inlineLine[linePosition]) {
+			linePosition++
+		}
+		if linePosition >= len(inlineLine) {
+			return inlineArguments, nil
+		}
+
+		var currentArgument strings.Builder
+		insideDoubleQuotes, insideSingleQuotes := false, false
+		for argumentComplete := false; !argumentComplete; {
+			if linePosition >= len(inlineLine) {
+				if insideDoubleQuotes || insideSingleQuotes {
+					return nil, fmt.Errorf("unbalanced quotes in request")
+				}
+				break
+			}
+			currentByte := inlineLine[linePosition]
+
+			switch {
+			case insideDoubleQuotes:
+				if currentByte == '\\' && linePosition+3 < len(inlineLine) && inlineLine[linePosition+1] == 'x' && isHexDigit(inlineLine[linePosition+2]) && isHexDigit(inlineLine[linePosition+3]) {
+					hexValue, _ := strconv.ParseUint(inlineLine[linePosition+2:linePosition+4], 16, 8)
+					currentArgument.WriteByte(byte(hexValue))
+					linePosition += 3
+				} else if currentByte == '\\' && linePosition+1 < len(inlineLine) {
+					linePosition++
+					currentArgument.WriteByte(unescapeInlineByte(inlineLine[linePosition]))
+				} else if currentByte == '"' {
+					// Le guillemet fermant doit être suivi d'un espace ou de la fin de ligne
+					if linePosition+1 < len(inlineLine) && !isInlineWhitespace(inlineLine[linePosition+1]) {
+						return nil, fmt.Errorf("unbalanced quotes in request")
+					}
+					argumentComplete = true
+				} else {
+					currentArgument.WriteByte(currentByte)
+				}
+
+			case insideSingleQuotes:
+				if currentByte == '\\' && linePosition+1 < len(inlineLine) && inlineLine[linePosition+1] == '\'' {
+					linePosition++
+					currentArgument.WriteByte('\'')
+				} else if currentByte == '\'' {
+					if linePosition+1 < len(inlineLine) && !isInlineWhitespace(inlineLine[linePosition+1]) {
+						return nil, fmt.Errorf("unbalanced quotes in request")
+					}
+					argumentComplete = true
+				} else {
+					currentArgument.WriteByte(currentByte)
+				}
+
+			default:
+				switch currentByte {
+				case ' ', '\t', '\n', '\r', 0:
+					argumentComplete = true
+				case '"':
+					insideDoubleQuotes = true
+				case '\'':
+					insideSingleQuotes = true
+				default:
+					currentArgument.WriteByte(currentByte)
+				}
+			}
+			linePosition++
+		}
+
+		inlineArguments = append(inlineArguments, currentArgument.String())
+	}
+}
+
+// unescapeInlineByte traduit le caractère qui suit un antislash dans une chaîne entre guillemets doubles
+func unescapeInlineByte(escapedByte byte) byte {
+	switch escapedByte {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'a':
+		return '\a'
+	default:
+		return escapedByte
 	}
 }
 
+// isInlineWhitespace indique si un caractère sépare deux arguments inline
+func isInlineWhitespace(candidateByte byte) bool {
+	return candidateByte == ' ' || candidateByte == '\t' || candidateByte == '\n' || candidateByte == '\r' || candidateByte == 0
+}
+
+// isHexDigit indique si un caractère est un chiffre hexadécimal
+func isHexDigit(candidateByte byte) bool {
+	return (candidateByte >= '0' && candidateByte <= '9') || (candidateByte >= 'a' && candidateByte <= 'f') || (candidateByte >= 'A' && candidateByte <= 'F')
+}
+
 // parseRedisArray parse un array RESP (format des commandes)
 func (redisParser *RedisSerializationProtocolParser) parseRedisArray() ([]string, error) {
 	// Lecture du nombre d'éléments
 	arrayLengthString, readError := redisParser.readProtocolLine()
 	if readError != nil {
-		return nil, fmt.Errorf("failed to read array length: %v", readError)
+		return nil, fmt.Errorf("failed to read array length: %w", readError)
 	}
 
 	arrayLength, parseError := strconv.Atoi(arrayLengthString)
@@ -57,7 +252,7 @@ func (redisParser *RedisSerializationProtocolParser) parseRedisArray() ([]string
 	for elementIndex := 0; elementIndex < arrayLength; elementIndex++ {
 		elementValue, parseError := redisParser.parseRedisBulkString()
 		if parseError != nil {
-			return nil, fmt.Errorf("failed to parse element %d: %v", elementIndex, parseError)
+			return nil, fmt.Errorf("failed to parse element %d: %w", elementIndex, parseError)
 		}
 		arrayElements[elementIndex] = elementValue
 	}
@@ -70,7 +265,7 @@ func (redisParser *RedisSerializationProtocolParser) parseRedisBulkString() (str
 	// Lecture du type (doit être $)
 	protocolTypeByte, readError := redisParser.bufferedReader.ReadByte()
 	if readError != nil {
-		return "", fmt.Errorf("failed to read bulk string type: %v", readError)
+		return "", fmt.Errorf("failed to read bulk string type: %w", readError)
 	}
 
 	if protocolTypeByte != RedisBulkStringType {
@@ -80,7 +275,7 @@ func (redisParser *RedisSerializationProtocolParser) parseRedisBulkString() (str
 	// Lecture de la longueur
 	stringLengthString, readError := redisParser.readProtocolLine()
 	if readError != nil {
-		return "", fmt.Errorf("failed to read bulk string length: %v", readError)
+		return "", fmt.Errorf("failed to read bulk string length: %w", readError)
 	}
 
 	stringLength, parseError := strconv.Atoi(stringLengthString)
@@ -101,14 +296,14 @@ func (redisParser *RedisSerializationProtocolParser) parseRedisBulkString() (str
 	stringContent := make([]byte, stringLength)
 	_, readError = io.ReadFull(redisParser.bufferedReader, stringContent)
 	if readError != nil {
-		return "", fmt.Errorf("failed to read bulk string content: %v", readError)
+		return "", fmt.Errorf("failed to read bulk string content: %w", readError)
 	}
 
 	// Lecture du CRLF final
 	carriageReturnLineFeed := make([]byte, 2)
 	_, readError = io.ReadFull(redisParser.bufferedReader, carriageReturnLineFeed)
 	if readError != nil {
-		return "", fmt.Errorf("failed to read CRLF after bulk string: %v", readError)
+		return "", fmt.Errorf("failed to read CRLF after bulk string: %w", readError)
 	}
 
 	if carriageReturnLineFeed[0] != '\r' || carriageReturnLineFeed[1] != '\n' {
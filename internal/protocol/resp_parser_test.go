@@ -0,0 +1,71 @@
+package protocol
+
+import (
+	"io"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseIncomingInlineCommand(t *testing.T) {
+	testCases := []struct {
+		name              string
+		inlineLine        string
+		expectedArguments []string
+		expectedError     string
+	}{
+		{name: "mots séparés par des espaces", inlineLine: "SET  clé\tvaleur\r\n", expectedArguments: []string{"SET", "clé", "valeur"}},
+		{name: "sans retour chariot", inlineLine: "PING\n", expectedArguments: []string{"PING"}},
+		{name: "ligne vide", inlineLine: "\r\n", expectedArguments: nil},
+		{name: "guillemets doubles", inlineLine: "SET k \"deux mots\"\r\n", expectedArguments: []string{"SET", "k", "deux mots"}},
+		{name: "échappements", inlineLine: `ECHO "a\tb\n\"c\"\\"` + "\r\n", expectedArguments: []string{"ECHO", "a\tb\n\"c\"\\"}},
+		{name: "octet hexadécimal", inlineLine: `ECHO "\x41\x7a\xZZ"` + "\r\n", expectedArguments: []string{"ECHO", "AzxZZ"}},
+		{name: "guillemets simples", inlineLine: `ECHO 'l\'été \n'` + "\r\n", expectedArguments: []string{"ECHO", "l'été \\n"}},
+		{name: "chaîne vide entre guillemets", inlineLine: "SET k \"\"\r\n", expectedArguments: []string{"SET", "k", ""}},
+		{name: "guillemet non fermé", inlineLine: "SET k \"valeur\r\n", expectedError: "unbalanced quotes in request"},
+		{name: "guillemet fermant suivi d'un caractère", inlineLine: "SET k \"a\"b\r\n", expectedError: "unbalanced quotes in request"},
+		{name: "guillemet simple non fermé", inlineLine: "SET k 'a\r\n", expectedError: "unbalanced quotes in request"},
+		{name: "ligne trop longue", inlineLine: "SET k " + strings.Repeat("v", maximumInlineCommandLength) + "\r\n", expectedError: "too big inline request"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parsedArguments, parseError := NewRedisSerializationProtocolParser(strings.NewReader(testCase.inlineLine)).ParseIncomingCommand()
+			if testCase.expectedError != "" {
+				if parseError == nil || parseError.Error() != testCase.expectedError {
+					t.Fatalf("erreur %v, attendu %q", parseError, testCase.expectedError)
+				}
+				return
+			}
+			if parseError != nil {
+				t.Fatalf("erreur inattendue %v", parseError)
+			}
+			if !slices.Equal(parsedArguments, testCase.expectedArguments) {
+				t.Fatalf("arguments %q, attendu %q", parsedArguments, testCase.expectedArguments)
+			}
+		})
+	}
+}
+
+func TestParseIncomingCommandMixesInlineAndMultibulk(t *testing.T) {
+	protocolParser := NewRedisSerializationProtocolParser(strings.NewReader("PING\r\n*2\r\n$4\r\nECHO\r\n$3\r\na b\r\nGET k\nQUIT"))
+	for _, expectedArguments := range [][]string{{"PING"}, {"ECHO", "a b"}, {"GET", "k"}} {
+		parsedArguments, parseError := protocolParser.ParseIncomingCommand()
+		if parseError != nil || !slices.Equal(parsedArguments, expectedArguments) {
+			t.Fatalf("arguments %q (erreur %v), attendu %q", parsedArguments, parseError, expectedArguments)
+		}
+	}
+	// Une ligne inline sans fin de ligne est incomplète
+	if _, parseError := protocolParser.ParseIncomingCommand(); parseError != io.EOF {
+		t.Fatalf("erreur %v, attendu EOF", parseError)
+	}
+}
+
+func TestParseMultibulkCommandRejectsInline(t *testing.T) {
+	protocolParser := NewRedisSerializationProtocolParser(strings.NewReader("*1\r\n$4\r\nPING\r\nPING\r\n"))
+	if parsedArguments, parseError := protocolParser.ParseMultibulkCommand(); parseError != nil || !slices.Equal(parsedArguments, []string{"PING"}) {
+		t.Fatalf("arguments %q (erreur %v), attendu [PING]", parsedArguments, parseError)
+	}
+	if _, parseError := protocolParser.ParseMultibulkCommand(); parseError == nil {
+		t.Fatalf("une commande inline doit être refusée hors connexion client")
+	}
+}
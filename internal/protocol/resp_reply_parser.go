@@ -0,0 +1,80 @@
+package protocol
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RedisReplyValue représente une réponse RESP décodée (utilisée par les scripts Lua)
+type RedisReplyValue struct {
+	ReplyType     byte
+	StringValue   string // simple string, erreur ou bulk string
+	IntegerValue  int64
+	ArrayElements []RedisReplyValue
+	IsNull        bool // bulk string ou array null
+}
+
+// ParseReply parse une réponse RESP complète de n'importe quel type
+func (redisParser *RedisSerializationProtocolParser) ParseReply() (RedisReplyValue, error) {
+	protocolTypeByte, readError := redisParser.bufferedReader.ReadByte()
+	if readError != nil {
+		return RedisReplyValue{}, readError
+	}
+
+	replyValue := RedisReplyValue{ReplyType: protocolTypeByte}
+	protocolLine, readError := redisParser.readProtocolLine()
+	if readError != nil {
+		return replyValue, fmt.Errorf("failed to read reply line: %w", readError)
+	}
+
+	switch protocolTypeByte {
+	case RedisSimpleStringType, RedisErrorType:
+		replyValue.StringValue = protocolLine
+		return replyValue, nil
+
+	case RedisIntegerType:
+		integerValue, parseError := strconv.ParseInt(protocolLine, 10, 64)
+		if parseError != nil {
+			return replyValue, fmt.Errorf("invalid integer reply: %s", protocolLine)
+		}
+		replyValue.IntegerValue = integerValue
+		return replyValue, nil
+
+	case RedisBulkStringType:
+		stringLength, parseError := strconv.Atoi(protocolLine)
+		if parseError != nil || stringLength < -1 {
+			return replyValue, fmt.Errorf("invalid bulk string length: %s", protocolLine)
+		}
+		if stringLength == -1 {
+			replyValue.IsNull = true
+			return replyValue, nil
+		}
+		stringContent := make([]byte, stringLength+2)
+		if _, readError := io.ReadFull(redisParser.bufferedReader, stringContent); readError != nil {
+			return replyValue, fmt.Errorf("failed to read bulk string content: %w", readError)
+		}
+		replyValue.StringValue = string(stringContent[:stringLength])
+		return replyValue, nil
+
+	case RedisArrayType:
+		arrayLength, parseError := strconv.Atoi(protocolLine)
+		if parseError != nil || arrayLength < -1 {
+			return replyValue, fmt.Errorf("invalid array length: %s", protocolLine)
+		}
+		if arrayLength == -1 {
+			replyValue.IsNull = true
+			return replyValue, nil
+		}
+		replyValue.ArrayElements = make([]RedisReplyValue, arrayLength)
+		for elementIndex := range replyValue.ArrayElements {
+			if replyValue.ArrayElements[elementIndex], readError = redisParser.ParseReply(); readError != nil {
+				return replyValue, fmt.Errorf("failed to parse element %d: %w", elementIndex, readError)
+			}
+		}
+		return replyValue, nil
+
+	default:
+		return replyValue, fmt.Errorf("unknown reply type %c", protocolTypeByte)
+	}
+}
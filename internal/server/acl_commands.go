@@ -0,0 +1,113 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"redis-go/internal/commands"
+	"redis-go/internal/protocol"
+)
+
+// wrongPasswordMessage est renvoyé quand AUTH échoue (sans préciser si l'utilisateur existe)
+const wrongPasswordMessage = "WRONGPASS nom d'utilisateur ou mot de passe invalide, ou utilisateur désactivé"
+
+// handleAuthCommand implémente AUTH [username] password
+func (redisServerInstance *RedisServerInstance) handleAuthCommand(connectionState *clientConnectionState, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	accessControlList := redisServerInstance.commandRegistry.GetAccessControlList()
+
+	var userName, password string
+	switch len(commandArguments) {
+	case 1:
+		if accessControlList.GetDefaultUserWithoutPassword() != nil {
+			return protocolEncoder.WriteErrorResponse("ERREUR : AUTH <password> appelé alors que l'utilisateur default n'a pas de mot de passe (voir REDIS_REQUIREPASS)")
+		}
+		userName, password = commands.DefaultAclUserName, commandArguments[0]
+	case 2:
+		userName, password = commandArguments[0], commandArguments[1]
+	default:
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'AUTH' (attendu: AUTH [username] password)")
+	}
+
+	authenticatedUser, isAuthenticated := accessControlList.AuthenticateUser(userName, password)
+	if !isAuthenticated {
+		return protocolEncoder.WriteErrorResponse(wrongPasswordMessage)
+	}
+
+	connectionState.setAuthenticatedUser(authenticatedUser)
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handleAclCommand implémente ACL SETUSER|GETUSER|LIST|WHOAMI|CAT
+func (redisServerInstance *RedisServerInstance) handleAclCommand(connectionState *clientConnectionState, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) == 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ACL' (attendu: ACL SETUSER|GETUSER|LIST|WHOAMI|CAT)")
+	}
+
+	accessControlList := redisServerInstance.commandRegistry.GetAccessControlList()
+
+	switch strings.ToUpper(commandArguments[0]) {
+	case "SETUSER":
+		if len(commandArguments) < 2 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ACL SETUSER' (attendu: ACL SETUSER username [rule ...])")
+		}
+		if errorMessage := accessControlList.SetUserRules(commandArguments[1], commandArguments[2:]); errorMessage != "" {
+			return protocolEncoder.WriteErrorResponse(errorMessage)
+		}
+		return protocolEncoder.WriteSimpleStringResponse("OK")
+
+	case "GETUSER":
+		if len(commandArguments) != 2 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ACL GETUSER' (attendu: ACL GETUSER username)")
+		}
+		userDescription, userExists := accessControlList.GetUserDescription(commandArguments[1])
+		if !userExists {
+			return protocolEncoder.WriteNullArrayResponse()
+		}
+		protocolEncoder.WriteMapHeader(4)
+		protocolEncoder.WriteBulkStringResponse("flags")
+		protocolEncoder.WriteArrayResponse(userDescription.Flags)
+		protocolEncoder.WriteBulkStringResponse("passwords")
+		protocolEncoder.WriteArrayResponse(userDescription.PasswordHashes)
+		protocolEncoder.WriteBulkStringResponse("commands")
+		protocolEncoder.WriteBulkStringResponse(userDescription.CommandRules)
+		protocolEncoder.WriteBulkStringResponse("keys")
+		return protocolEncoder.WriteBulkStringResponse(userDescription.KeyPatterns)
+
+	case "LIST":
+		if len(commandArguments) != 1 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : ACL LIST ne prend aucun argument")
+		}
+		return protocolEncoder.WriteArrayResponse(accessControlList.ListUserRules())
+
+	case "WHOAMI":
+		if len(commandArguments) != 1 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : ACL WHOAMI ne prend aucun argument")
+		}
+		return protocolEncoder.WriteBulkStringResponse(connectionState.authenticatedUser.GetUserName())
+
+	case "CAT":
+		switch len(commandArguments) {
+		case 1:
+			return protocolEncoder.WriteArrayResponse(accessControlList.GetCategoryNames())
+		case 2:
+			categoryCommands, categoryExists := accessControlList.GetCategoryCommands(commandArguments[1])
+			if !categoryExists {
+				return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : catégorie ACL inconnue '%s'", commandArguments[1]))
+			}
+			return protocolEncoder.WriteArrayResponse(categoryCommands)
+		default:
+			return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'ACL CAT' (attendu: ACL CAT [category])")
+		}
+	}
+
+	return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : sous-commande inconnue '%s' pour ACL (attendu: SETUSER, GETUSER, LIST, WHOAMI, CAT)", commandArguments[0]))
+}
+
+// checkConnectionCommandPermission vérifie les permissions ACL des commandes traitées par le serveur
+// (MULTI, SUBSCRIBE, SELECT, ACL...) ; les commandes du registre sont vérifiées par ExecuteCommand
+func (redisServerInstance *RedisServerInstance) checkConnectionCommandPermission(connectionState *clientConnectionState, commandName string, commandArguments []string) string {
+	if redisServerInstance.commandRegistry.IsRegisteredCommand(commandName) {
+		return ""
+	}
+	return redisServerInstance.commandRegistry.GetAccessControlList().CheckCommandPermission(connectionState.authenticatedUser, commandName, commandArguments)
+}
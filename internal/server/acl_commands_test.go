@@ -0,0 +1,87 @@
+package server
+
+import "testing"
+
+func TestAuthenticationAndAcl(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	serverConfiguration.SecurityConfiguration.RequirePassword = "motdepasse"
+	startTestServer(t, serverConfiguration)
+
+	testCases := []struct {
+		name      string
+		testSteps []clientTestStep
+	}{
+		{
+			name: "NOAUTH pour tout sauf AUTH, HELLO et QUIT",
+			testSteps: []clientTestStep{
+				{0, "PING", "-NOAUTH Authentification requise.\r\n"},
+				{0, "SELECT 1", "-NOAUTH Authentification requise.\r\n"},
+				{0, "MULTI", "-NOAUTH Authentification requise.\r\n"},
+				{0, "SUBSCRIBE ch", "-NOAUTH Authentification requise.\r\n"},
+				{0, "HELLO 3", "-NOAUTH HELLO doit être appelé après AUTH*"},
+				{0, "AUTH mauvais", "-WRONGPASS*"},
+				{0, "AUTH default mauvais", "-WRONGPASS*"},
+				{0, "AUTH motdepasse", "+OK\r\n"},
+				{0, "PING", "+PONG\r\n"},
+				{0, "ACL WHOAMI", bulk("default")},
+				{1, "HELLO 2 AUTH default motdepasse", "*14\r\n*"},
+				{1, "PING", "+PONG\r\n"},
+			},
+		},
+		{
+			name: "utilisateur restreint par catégorie et motif de clés",
+			testSteps: []clientTestStep{
+				{0, "AUTH motdepasse", "+OK\r\n"},
+				{0, "ACL SETUSER lecteur on >lecture ~public:* +@read", "+OK\r\n"},
+				{0, "SET public:1 v", "+OK\r\n"},
+				{1, "AUTH lecteur lecture", "+OK\r\n"},
+				{1, "ACL WHOAMI", "-NOPERM*"},
+				{1, "GET public:1", bulk("v")},
+				{1, "GET prive", "-NOPERM l'utilisateur 'lecteur' n'a pas accès à une des clés utilisées par la commande 'get'\r\n"},
+				{1, "SET public:1 w", "-NOPERM l'utilisateur 'lecteur' n'a pas la permission d'exécuter la commande 'set'\r\n"},
+				{1, "MULTI", "-NOPERM*"},
+				// Les nouveaux droits s'appliquent immédiatement aux connexions authentifiées
+				{0, "ACL SETUSER lecteur +set", "+OK\r\n"},
+				{1, "SET public:1 w", "+OK\r\n"},
+				{0, "ACL SETUSER lecteur off", "+OK\r\n"},
+				{1, "AUTH lecteur lecture", "-WRONGPASS*"},
+			},
+		},
+		{
+			name: "ACL GETUSER, LIST et erreurs",
+			testSteps: []clientTestStep{
+				{0, "AUTH motdepasse", "+OK\r\n"},
+				{0, "ACL SETUSER invite on nopass ~cache:* +get", "+OK\r\n"},
+				{0, "ACL GETUSER invite", "*8\r\n" + bulk("flags") + encodedArray(bulk("on"), bulk("nopass")) + bulk("passwords") + "*0\r\n" + bulk("commands") + bulk("-@all +get") + bulk("keys") + bulk("~cache:*")},
+				{0, "ACL GETUSER absent", "*-1\r\n"},
+				{0, "ACL LIST", "*3\r\n*"},
+				{0, "ACL SETUSER invite +nosuchcommand", "-ERREUR : erreur dans la règle ACL SETUSER '+nosuchcommand'*"},
+				{0, "ACL DELUSER invite", "-ERREUR : sous-commande inconnue 'DELUSER' pour ACL*"},
+				{1, "AUTH invite n'importe", "+OK\r\n"},
+				{1, "AUTH un deux trois", "-ERREUR : nombre d'arguments incorrect pour 'AUTH'*"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testClients := []*testClient{
+				dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber),
+				dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber),
+			}
+			runClientSteps(t, testClients, testCase.testSteps)
+		})
+	}
+}
+
+func TestAuthWithoutRequirePassword(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	defaultClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	runClientSteps(t, []*testClient{defaultClient}, []clientTestStep{
+		{0, "ACL WHOAMI", bulk("default")},
+		{0, "AUTH secret", "-ERREUR : AUTH <password> appelé alors que l'utilisateur default n'a pas de mot de passe*"},
+		{0, "AUTH default secret", "+OK\r\n"},
+		{0, "PING", "+PONG\r\n"},
+	})
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"redis-go/internal/commands"
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// waitForServedListElement bloque le client jusqu'à ce qu'une liste surveillée lui attribue un élément,
+// que le timeout expire (0 : attente illimitée), que le client se déconnecte ou que le serveur s'arrête
+// Retourne l'élément servi (nil au timeout) et false si le client s'est déconnecté pendant l'attente
+// L'appelant ne doit détenir aucun verrou : les autres clients doivent pouvoir remplir la liste
+func (redisServerInstance *RedisServerInstance) waitForServedListElement(connectionState *clientConnectionState, protocolParser *protocol.RedisSerializationProtocolParser, listWaiter *storage.ListWaiter, blockingTimeout time.Duration, redisStorage *storage.RedisInMemoryStorage) (*storage.ServedListElement, bool) {
+	connectionState.setClientBlocked(true)
+	redisServerInstance.statistics.blockedClientCount.Add(1)
+	defer func() {
+		redisServerInstance.statistics.blockedClientCount.Add(-1)
+		connectionState.setClientBlocked(false)
+	}()
+
+	var timeoutSignal <-chan time.Time
+	if blockingTimeout > 0 {
+		blockingTimer := time.NewTimer(blockingTimeout)
+		defer blockingTimer.Stop()
+		timeoutSignal = blockingTimer.C
+	}
+
+	// Surveiller la connexion pendant l'attente : une déconnexion retire le client des files d'attente
+	// Les commandes envoyées entre-temps sont lues en avance et seront traitées après la réponse
+	clientConnection := connectionState.clientConnection
+	clientConnection.SetReadDeadline(time.Time{})
+	disconnectionSignal := make(chan struct{})
+	watcherStopped := make(chan struct{})
+	go func() {
+		defer close(watcherStopped)
+		readError := protocolParser.WatchForClosedInput()
+		var networkError net.Error
+		if readError != nil && !(errors.As(readError, &networkError) && networkError.Timeout()) {
+			close(disconnectionSignal)
+		}
+	}()
+	defer func() {
+		// Débloquer la lecture en cours pour arrêter la surveillance avant de rendre la main
+		clientConnection.SetReadDeadline(time.Now())
+		<-watcherStopped
+	}()
+
+	select {
+	case servedElement := <-listWaiter.ServedElement():
+		return &servedElement, true
+	case <-timeoutSignal:
+	case <-disconnectionSignal:
+	case <-redisServerInstance.shutdownSignal:
+	}
+
+	// Un élément a pu être attribué juste avant le retrait : il est alors renvoyé au client
+	// ou, s'il s'est déconnecté, remis dans sa liste
+	redisStorage.RemoveListWaiter(listWaiter)
+	var servedElement *storage.ServedListElement
+	select {
+	case lateServedElement := <-listWaiter.ServedElement():
+		servedElement = &lateServedElement
+	default:
+	}
+
+	select {
+	case <-disconnectionSignal:
+		if servedElement != nil {
+			redisServerInstance.restoreServedListElement(servedElement, redisStorage)
+		}
+		return nil, false
+	default:
+		return servedElement, true
+	}
+}
+
+// restoreServedListElement remet dans sa liste l'élément d'un client bloqué qui n'a pas pu le recevoir
+func (redisServerInstance *RedisServerInstance) restoreServedListElement(servedElement *storage.ServedListElement, redisStorage *storage.RedisInMemoryStorage) {
+	if restoreError := redisServerInstance.commandRegistry.RestoreServedListElement(servedElement, redisStorage); restoreError != nil {
+		log.Printf("⚠️  Impossible de remettre l'élément servi dans la liste %s: %v", servedElement.SourceListKey, restoreError)
+	}
+}
+
+// executeBlockingListCommand exécute BLPOP, BRPOP, BLMOVE ou BRPOPLPUSH hors transaction
+// Si aucune liste ne contient d'élément, responseMutex est relâché pendant l'attente (messages pub/sub,
+// CLIENT KILL...) puis repris pour écrire la réponse ; retourne false si le client s'est déconnecté
+// La réponse d'un client servi est envoyée aussitôt : si elle ne peut pas l'être, l'élément est remis dans sa liste
+func (redisServerInstance *RedisServerInstance) executeBlockingListCommand(connectionState *clientConnectionState, protocolParser *protocol.RedisSerializationProtocolParser, responseMutex *sync.Mutex, responseWriter *bufio.Writer, commandName string, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) (bool, error) {
+	redisStorage := redisServerInstance.selectedStorage(connectionState)
+	listWaiter, blockingTimeout, executionError := redisServerInstance.commandRegistry.ExecuteBlockingListCommand(connectionState.authenticatedUser, commandName, commandArguments, redisStorage, protocolEncoder)
+	if listWaiter == nil || executionError != nil {
+		return true, executionError
+	}
+
+	responseMutex.Unlock()
+	servedElement, clientConnected := redisServerInstance.waitForServedListElement(connectionState, protocolParser, listWaiter, blockingTimeout, redisStorage)
+	responseMutex.Lock()
+	if !clientConnected {
+		return false, nil
+	}
+	replyError := commands.WriteBlockingListReply(protocolEncoder, listWaiter, servedElement)
+	if replyError == nil {
+		replyError = responseWriter.Flush()
+	}
+	if replyError != nil && servedElement != nil {
+		log.Printf("⚠️  Impossible d'envoyer l'élément servi à %s: %v", connectionState.clientConnection.RemoteAddr(), replyError)
+		redisServerInstance.restoreServedListElement(servedElement, redisStorage)
+		return false, nil
+	}
+	return true, replyError
+}
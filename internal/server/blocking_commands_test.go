@@ -0,0 +1,175 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sendWithoutReply envoie une commande sans attendre sa réponse (commande bloquante)
+func (client *testClient) sendWithoutReply(commandLine string) {
+	client.t.Helper()
+	if _, writeError := client.clientConnection.Write([]byte(encodedCommand(commandLine))); writeError != nil {
+		client.t.Fatalf("%s: envoi impossible: %v", commandLine, writeError)
+	}
+}
+
+// waitForBlockedClients attend que le serveur compte blockedClientCount clients bloqués (INFO clients)
+func waitForBlockedClients(t *testing.T, observerClient *testClient, blockedClientCount int) {
+	t.Helper()
+	expectedLine := "blocked_clients:" + strconv.Itoa(blockedClientCount) + "\r\n"
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if strings.Contains(observerClient.execute("INFO", "clients"), expectedLine) {
+			return
+		}
+	}
+	t.Fatalf("le serveur ne compte pas %d clients bloqués", blockedClientCount)
+}
+
+func TestBlockedClientsServedInArrivalOrder(t *testing.T) {
+	testCases := []struct {
+		name            string
+		blockedCommands []string
+		pushCommand     string
+		expectedReplies []string
+		expectedLeft    string
+	}{
+		{
+			name:            "BRPOP sur la même liste",
+			blockedCommands: []string{"BRPOP q 0", "BRPOP q 0"},
+			pushCommand:     "RPUSH q b a",
+			expectedReplies: []string{encodedArray(bulk("q"), bulk("a")), encodedArray(bulk("q"), bulk("b"))},
+			expectedLeft:    "*0\r\n",
+		},
+		{
+			name:            "BLPOP sur plusieurs listes",
+			blockedCommands: []string{"BLPOP autre q 5", "BLPOP q autre 5"},
+			pushCommand:     "RPUSH q a b c",
+			expectedReplies: []string{encodedArray(bulk("q"), bulk("a")), encodedArray(bulk("q"), bulk("b"))},
+			expectedLeft:    encodedArray(bulk("c")),
+		},
+		{
+			name:            "BLMOVE alimentant un BLPOP sur sa destination",
+			blockedCommands: []string{"BLMOVE q dest RIGHT LEFT 0", "BLPOP dest 0"},
+			pushCommand:     "RPUSH q a",
+			expectedReplies: []string{bulk("a"), encodedArray(bulk("dest"), bulk("a"))},
+			expectedLeft:    "*0\r\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			serverConfiguration := newTestServerConfiguration(t)
+			startTestServer(t, serverConfiguration)
+			pusherClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+			var blockedClients []*testClient
+			for blockedIndex, blockedCommand := range testCase.blockedCommands {
+				blockedClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+				blockedClient.sendWithoutReply(blockedCommand)
+				waitForBlockedClients(t, pusherClient, blockedIndex+1)
+				blockedClients = append(blockedClients, blockedClient)
+			}
+			if clientList := pusherClient.execute("CLIENT", "LIST"); strings.Count(clientList, "flags=b ") != len(blockedClients) {
+				t.Fatalf("CLIENT LIST doit marquer les clients bloqués: %q", clientList)
+			}
+
+			pusherClient.execute(strings.Fields(testCase.pushCommand)...)
+			for blockedIndex, blockedClient := range blockedClients {
+				if blockedReply := blockedClient.readReply(); blockedReply != testCase.expectedReplies[blockedIndex] {
+					t.Fatalf("client bloqué %d: %q, attendu %q", blockedIndex, blockedReply, testCase.expectedReplies[blockedIndex])
+				}
+			}
+			runClientSteps(t, []*testClient{pusherClient}, []clientTestStep{
+				{0, "LRANGE q 0 -1", testCase.expectedLeft},
+			})
+			waitForBlockedClients(t, pusherClient, 0)
+		})
+	}
+}
+
+func TestBlockingCommandReplies(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+
+	testCases := []struct {
+		name        string
+		testSteps   []clientTestStep
+		minimumWait time.Duration
+	}{
+		{
+			name:        "timeout de BLPOP",
+			testSteps:   []clientTestStep{{0, "BLPOP vide 0.1", "*-1\r\n"}},
+			minimumWait: 100 * time.Millisecond,
+		},
+		{
+			name:        "timeout de BLMOVE",
+			testSteps:   []clientTestStep{{0, "BLMOVE vide dest LEFT LEFT 0.05", "$-1\r\n"}},
+			minimumWait: 50 * time.Millisecond,
+		},
+		{
+			name: "élément déjà disponible",
+			testSteps: []clientTestStep{
+				{0, "RPUSH l a", ":1\r\n"},
+				{0, "BRPOPLPUSH l dest 0", bulk("a")},
+			},
+		},
+		{
+			name: "pas d'attente dans une transaction",
+			testSteps: []clientTestStep{
+				{0, "MULTI", "+OK\r\n"},
+				{0, "BLPOP vide 0", "+QUEUED\r\n"},
+				{0, "EXEC", "*1\r\n*-1\r\n"},
+			},
+		},
+		{
+			name: "erreurs",
+			testSteps: []clientTestStep{
+				{0, "BLPOP", "-ERREUR : nombre d'arguments incorrect pour 'BLPOP'\r\n"},
+				{0, "BLMOVE a b LEFT", "-ERREUR : nombre d'arguments incorrect pour 'BLMOVE'\r\n"},
+				{0, "BRPOP l -2", "-ERREUR : le timeout est négatif\r\n"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			commandClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			startTime := time.Now()
+			runClientSteps(t, []*testClient{commandClient}, testCase.testSteps)
+			if elapsedTime := time.Since(startTime); elapsedTime < testCase.minimumWait {
+				t.Fatalf("réponse après %v, attendu au moins %v", elapsedTime, testCase.minimumWait)
+			}
+		})
+	}
+}
+
+func TestBlockedClientDisconnection(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	pusherClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	// Le client déconnecté est retiré de la file : l'élément poussé ensuite reste dans la liste
+	departedClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	departedClient.sendWithoutReply("BLPOP q 0")
+	waitForBlockedClients(t, pusherClient, 1)
+	departedClient.clientConnection.Close()
+	waitForBlockedClients(t, pusherClient, 0)
+
+	// Une commande envoyée pendant l'attente est traitée après la réponse de la commande bloquante
+	pipelinedClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	pipelinedClient.sendWithoutReply("BLPOP autre 0")
+	waitForBlockedClients(t, pusherClient, 1)
+	pipelinedClient.sendWithoutReply("PING")
+
+	runClientSteps(t, []*testClient{pusherClient}, []clientTestStep{
+		{0, "RPUSH q x", ":1\r\n"},
+		{0, "LRANGE q 0 -1", encodedArray(bulk("x"))},
+		{0, "RPUSH autre y", ":1\r\n"},
+	})
+	if blockedReply := pipelinedClient.readReply(); blockedReply != encodedArray(bulk("autre"), bulk("y")) {
+		t.Fatalf("réponse de BLPOP %q", blockedReply)
+	}
+	if pingReply := pipelinedClient.readReply(); pingReply != "+PONG\r\n" {
+		t.Fatalf("réponse de PING %q", pingReply)
+	}
+}
@@ -0,0 +1,406 @@
+package server
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"redis-go/internal/commands"
+	"redis-go/internal/protocol"
+)
+
+// clientPauseState mémorise une pause des clients en cours (CLIENT PAUSE)
+type clientPauseState struct {
+	pauseMutex    sync.Mutex
+	pauseDeadline time.Time
+	// pauseWritesOnly : seules les commandes d'écriture sont suspendues (CLIENT PAUSE ... WRITE)
+	pauseWritesOnly bool
+	// pauseEnded est fermé par CLIENT UNPAUSE pour réveiller les commandes en attente
+	pauseEnded chan struct{}
+}
+
+// newClientPauseState crée un état sans pause active
+func newClientPauseState() *clientPauseState {
+	return &clientPauseState{pauseEnded: make(chan struct{})}
+}
+
+// pauseClients suspend les clients jusqu'à pauseDeadline
+// Une pause déjà active est prolongée et reste ALL si l'une des deux l'est
+func (pause *clientPauseState) pauseClients(pauseDeadline time.Time, pauseWritesOnly bool) {
+	pause.pauseMutex.Lock()
+	defer pause.pauseMutex.Unlock()
+
+	if time.Now().Before(pause.pauseDeadline) {
+		pauseWritesOnly = pauseWritesOnly && pause.pauseWritesOnly
+		if pause.pauseDeadline.After(pauseDeadline) {
+			pauseDeadline = pause.pauseDeadline
+		}
+	}
+	pause.pauseDeadline = pauseDeadline
+	pause.pauseWritesOnly = pauseWritesOnly
+}
+
+// unpauseClients met fin à la pause et réveille les commandes en attente
+func (pause *clientPauseState) unpauseClients() {
+	pause.pauseMutex.Lock()
+	defer pause.pauseMutex.Unlock()
+
+	pause.pauseDeadline = time.Time{}
+	close(pause.pauseEnded)
+	pause.pauseEnded = make(chan struct{})
+}
+
+// waitWhileClientsPaused bloque la commande tant qu'une pause la concerne
+func (redisServerInstance *RedisServerInstance) waitWhileClientsPaused(isWriteCommand bool) {
+	pause := redisServerInstance.clientPause
+	for {
+		pause.pauseMutex.Lock()
+		remainingPause := time.Until(pause.pauseDeadline)
+		isConcerned := remainingPause > 0 && (isWriteCommand || !pause.pauseWritesOnly)
+		pauseEnded := pause.pauseEnded
+		pause.pauseMutex.Unlock()
+
+		if !isConcerned {
+			return
+		}
+		pauseTimer := time.NewTimer(remainingPause)
+		select {
+		case <-pauseTimer.C:
+		case <-pauseEnded:
+		case <-redisServerInstance.shutdownSignal:
+			pauseTimer.Stop()
+			return
+		}
+		pauseTimer.Stop()
+	}
+}
+
+// isPausableWriteCommand indique si CLIENT PAUSE WRITE suspend la commande : écritures, scripts, PUBLISH,
+// et EXEC d'une transaction contenant l'une d'elles (les commandes mises en file ne sont pas suspendues)
+func isPausableWriteCommand(transactionState *clientTransactionState, commandName string) bool {
+	upperCommandName := strings.ToUpper(commandName)
+	if upperCommandName == "EXEC" {
+		for _, queuedCommand := range transactionState.queuedCommands {
+			if isPausableOutsideTransaction(strings.ToUpper(queuedCommand.CommandName)) {
+				return true
+			}
+		}
+		return false
+	}
+	if transactionState.isInsideTransaction {
+		return false
+	}
+	return isPausableOutsideTransaction(upperCommandName)
+}
+
+// isPausableOutsideTransaction indique si CLIENT PAUSE WRITE suspend une commande exécutée directement ou par EXEC
+func isPausableOutsideTransaction(upperCommandName string) bool {
+	switch upperCommandName {
+	case "EVAL", "EVALSHA", "PUBLISH":
+		return true
+	}
+	return commands.IsWriteCommand(upperCommandName)
+}
+
+// getConnectedClientsById retourne les connexions ouvertes triées par identifiant
+func (redisServerInstance *RedisServerInstance) getConnectedClientsById() []*clientConnectionState {
+	redisServerInstance.clientsMutex.RLock()
+	connectedClients := make([]*clientConnectionState, 0, len(redisServerInstance.connectedClients))
+	for _, connectionState := range redisServerInstance.connectedClients {
+		connectedClients = append(connectedClients, connectionState)
+	}
+	redisServerInstance.clientsMutex.RUnlock()
+
+	sort.Slice(connectedClients, func(firstIndex, secondIndex int) bool {
+		return connectedClients[firstIndex].clientId < connectedClients[secondIndex].clientId
+	})
+	return connectedClients
+}
+
+// handleClientCommand implémente CLIENT LIST|INFO|ID|SETNAME|GETNAME|SETINFO|KILL|PAUSE|UNPAUSE
+func (redisServerInstance *RedisServerInstance) handleClientCommand(connectionState *clientConnectionState, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) == 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'CLIENT' (attendu: CLIENT LIST|INFO|ID|SETNAME|GETNAME|SETINFO|KILL|PAUSE|UNPAUSE)")
+	}
+
+	subcommandArguments := commandArguments[1:]
+	switch strings.ToUpper(commandArguments[0]) {
+	case "LIST":
+		return redisServerInstance.handleClientListCommand(subcommandArguments, protocolEncoder)
+
+	case "INFO":
+		if len(subcommandArguments) != 0 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : CLIENT INFO ne prend aucun argument")
+		}
+		return protocolEncoder.WriteVerbatimStringResponse("txt", connectionState.describeClient()+"\n")
+
+	case "ID":
+		if len(subcommandArguments) != 0 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : CLIENT ID ne prend aucun argument")
+		}
+		return protocolEncoder.WriteIntegerResponse(connectionState.clientId)
+
+	case "SETNAME":
+		if len(subcommandArguments) != 1 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'CLIENT SETNAME' (attendu: CLIENT SETNAME name)")
+		}
+		if errorMessage := validateClientName(subcommandArguments[0]); errorMessage != "" {
+			return protocolEncoder.WriteErrorResponse(errorMessage)
+		}
+		connectionState.setClientName(subcommandArguments[0])
+		return protocolEncoder.WriteSimpleStringResponse("OK")
+
+	case "GETNAME":
+		if len(subcommandArguments) != 0 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : CLIENT GETNAME ne prend aucun argument")
+		}
+		if connectionState.clientName == "" {
+			return protocolEncoder.WriteNullBulkStringResponse()
+		}
+		return protocolEncoder.WriteBulkStringResponse(connectionState.clientName)
+
+	case "SETINFO":
+		return redisServerInstance.handleClientSetInfoCommand(connectionState, subcommandArguments, protocolEncoder)
+
+	case "KILL":
+		return redisServerInstance.handleClientKillCommand(connectionState, subcommandArguments, protocolEncoder)
+
+	case "PAUSE":
+		return redisServerInstance.handleClientPauseCommand(subcommandArguments, protocolEncoder)
+
+	case "UNPAUSE":
+		if len(subcommandArguments) != 0 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : CLIENT UNPAUSE ne prend aucun argument")
+		}
+		redisServerInstance.clientPause.unpauseClients()
+		return protocolEncoder.WriteSimpleStringResponse("OK")
+	}
+
+	return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : sous-commande inconnue '%s' pour CLIENT (attendu: LIST, INFO, ID, SETNAME, GETNAME, SETINFO, KILL, PAUSE, UNPAUSE)", commandArguments[0]))
+}
+
+// validateClientName vérifie qu'un nom de client ne contient ni espace ni caractère spécial (CLIENT LIST reste lisible)
+func validateClientName(clientName string) string {
+	for _, nameCharacter := range clientName {
+		if nameCharacter <= ' ' || nameCharacter > '~' {
+			return "ERREUR : le nom du client ne peut contenir ni espace, ni retour à la ligne, ni caractère spécial"
+		}
+	}
+	return ""
+}
+
+// handleClientListCommand implémente CLIENT LIST [TYPE normal|replica|pubsub] [ID client-id ...]
+func (redisServerInstance *RedisServerInstance) handleClientListCommand(commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	clientTypeFilter := ""
+	var clientIdFilter map[int64]bool
+	if len(commandArguments) == 2 && strings.EqualFold(commandArguments[0], "TYPE") {
+		clientTypeFilter = strings.ToLower(commandArguments[1])
+		if clientTypeFilter == "slave" {
+			clientTypeFilter = "replica"
+		}
+		if clientTypeFilter != "normal" && clientTypeFilter != "replica" && clientTypeFilter != "pubsub" {
+			return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : type de client inconnu '%s' (attendu: normal, replica, pubsub)", commandArguments[1]))
+		}
+	} else if len(commandArguments) >= 2 && strings.EqualFold(commandArguments[0], "ID") {
+		clientIdFilter = make(map[int64]bool)
+		for _, clientIdArgument := range commandArguments[1:] {
+			clientId, parseError := strconv.ParseInt(clientIdArgument, 10, 64)
+			if parseError != nil || clientId <= 0 {
+				return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : identifiant de client invalide '%s'", clientIdArgument))
+			}
+			clientIdFilter[clientId] = true
+		}
+	} else if len(commandArguments) != 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : syntaxe invalide (attendu: CLIENT LIST [TYPE normal|replica|pubsub] [ID client-id ...])")
+	}
+
+	var clientListBuilder strings.Builder
+	for _, connectionState := range redisServerInstance.getConnectedClientsById() {
+		if clientTypeFilter != "" && connectionState.getClientType() != clientTypeFilter {
+			continue
+		}
+		if clientIdFilter != nil && !clientIdFilter[connectionState.clientId] {
+			continue
+		}
+		clientListBuilder.WriteString(connectionState.describeClient())
+		clientListBuilder.WriteString("\n")
+	}
+	return protocolEncoder.WriteVerbatimStringResponse("txt", clientListBuilder.String())
+}
+
+// handleClientSetInfoCommand implémente CLIENT SETINFO LIB-NAME|LIB-VER value (envoyé par les bibliothèques clientes)
+func (redisServerInstance *RedisServerInstance) handleClientSetInfoCommand(connectionState *clientConnectionState, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'CLIENT SETINFO' (attendu: CLIENT SETINFO LIB-NAME|LIB-VER value)")
+	}
+	if errorMessage := validateClientName(commandArguments[1]); errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	switch strings.ToUpper(commandArguments[0]) {
+	case "LIB-NAME":
+		connectionState.setLibraryInformation(&commandArguments[1], nil)
+	case "LIB-VER":
+		connectionState.setLibraryInformation(nil, &commandArguments[1])
+	default:
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : attribut CLIENT SETINFO inconnu '%s' (attendu: LIB-NAME, LIB-VER)", commandArguments[0]))
+	}
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// clientKillFilter regroupe les critères de CLIENT KILL (un critère vide n'est pas appliqué)
+type clientKillFilter struct {
+	clientId        int64
+	clientAddress   string
+	localAddress    string
+	userName        string
+	clientType      string
+	skipOwnClient   bool
+	isLegacySyntax  bool
+	hasAnyCriterion bool
+}
+
+// matchesClient indique si une connexion satisfait tous les critères du filtre
+func (killFilter *clientKillFilter) matchesClient(connectionState *clientConnectionState, currentClient *clientConnectionState) bool {
+	if killFilter.skipOwnClient && connectionState == currentClient {
+		return false
+	}
+	if killFilter.clientId != 0 && connectionState.clientId != killFilter.clientId {
+		return false
+	}
+	if killFilter.clientAddress != "" && connectionState.getClientAddress() != killFilter.clientAddress {
+		return false
+	}
+	if killFilter.localAddress != "" && connectionState.getLocalAddress() != killFilter.localAddress {
+		return false
+	}
+	if killFilter.clientType != "" && connectionState.getClientType() != killFilter.clientType {
+		return false
+	}
+	if killFilter.userName != "" {
+		connectionState.metadataMutex.Lock()
+		userName := connectionState.getUserName()
+		connectionState.metadataMutex.Unlock()
+		if userName != killFilter.userName {
+			return false
+		}
+	}
+	return true
+}
+
+// parseClientKillFilter lit CLIENT KILL addr:port ou CLIENT KILL [ID id] [ADDR addr] [LADDR addr] [USER name] [TYPE type] [SKIPME yes|no]
+func parseClientKillFilter(commandArguments []string) (*clientKillFilter, string) {
+	if len(commandArguments) == 1 {
+		return &clientKillFilter{clientAddress: commandArguments[0], isLegacySyntax: true, hasAnyCriterion: true}, ""
+	}
+	if len(commandArguments) == 0 || len(commandArguments)%2 != 0 {
+		return nil, "ERREUR : syntaxe invalide (attendu: CLIENT KILL addr:port | CLIENT KILL [ID id] [ADDR addr] [LADDR addr] [USER username] [TYPE type] [SKIPME yes|no])"
+	}
+
+	killFilter := &clientKillFilter{skipOwnClient: true}
+	for filterIndex := 0; filterIndex < len(commandArguments); filterIndex += 2 {
+		filterValue := commandArguments[filterIndex+1]
+		switch strings.ToUpper(commandArguments[filterIndex]) {
+		case "ID":
+			clientId, parseError := strconv.ParseInt(filterValue, 10, 64)
+			if parseError != nil || clientId <= 0 {
+				return nil, fmt.Sprintf("ERREUR : identifiant de client invalide '%s'", filterValue)
+			}
+			killFilter.clientId = clientId
+		case "ADDR":
+			killFilter.clientAddress = filterValue
+		case "LADDR":
+			killFilter.localAddress = filterValue
+		case "USER":
+			killFilter.userName = filterValue
+		case "TYPE":
+			killFilter.clientType = strings.ToLower(filterValue)
+			if killFilter.clientType == "slave" {
+				killFilter.clientType = "replica"
+			}
+			if killFilter.clientType != "normal" && killFilter.clientType != "replica" && killFilter.clientType != "pubsub" {
+				return nil, fmt.Sprintf("ERREUR : type de client inconnu '%s' (attendu: normal, replica, pubsub)", filterValue)
+			}
+		case "SKIPME":
+			switch strings.ToLower(filterValue) {
+			case "yes":
+				killFilter.skipOwnClient = true
+			case "no":
+				killFilter.skipOwnClient = false
+			default:
+				return nil, "ERREUR : SKIPME attend yes ou no"
+			}
+			continue
+		default:
+			return nil, fmt.Sprintf("ERREUR : filtre CLIENT KILL inconnu '%s' (attendu: ID, ADDR, LADDR, USER, TYPE, SKIPME)", commandArguments[filterIndex])
+		}
+		killFilter.hasAnyCriterion = true
+	}
+	return killFilter, ""
+}
+
+// handleClientKillCommand implémente CLIENT KILL : ferme les connexions correspondantes
+// Syntaxe historique (addr:port) : OK ou erreur ; syntaxe par filtres : nombre de connexions fermées
+// La connexion courante, si elle est visée, est fermée après l'envoi de la réponse
+func (redisServerInstance *RedisServerInstance) handleClientKillCommand(connectionState *clientConnectionState, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	killFilter, errorMessage := parseClientKillFilter(commandArguments)
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	killedClientCount := 0
+	if killFilter.hasAnyCriterion {
+		for _, connectedClient := range redisServerInstance.getConnectedClientsById() {
+			if !killFilter.matchesClient(connectedClient, connectionState) {
+				continue
+			}
+			if connectedClient == connectionState {
+				connectionState.closeAfterReply = true
+			} else {
+				connectedClient.clientConnection.Close()
+			}
+			killedClientCount++
+		}
+	}
+
+	if killFilter.isLegacySyntax {
+		if killedClientCount == 0 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : aucun client avec cette adresse")
+		}
+		return protocolEncoder.WriteSimpleStringResponse("OK")
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(killedClientCount))
+}
+
+// handleClientPauseCommand implémente CLIENT PAUSE timeout [WRITE|ALL] (timeout en millisecondes)
+func (redisServerInstance *RedisServerInstance) handleClientPauseCommand(commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 1 && len(commandArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'CLIENT PAUSE' (attendu: CLIENT PAUSE timeout [WRITE|ALL])")
+	}
+	pauseMilliseconds, parseError := strconv.ParseInt(commandArguments[0], 10, 64)
+	if parseError != nil || pauseMilliseconds < 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : le timeout de CLIENT PAUSE doit être un entier positif (millisecondes)")
+	}
+	// Au-delà, la durée en nanosecondes ne tient plus dans un time.Duration
+	if pauseMilliseconds > math.MaxInt64/int64(time.Millisecond) {
+		return protocolEncoder.WriteErrorResponse("ERREUR : le timeout de CLIENT PAUSE est trop grand")
+	}
+
+	pauseWritesOnly := false
+	if len(commandArguments) == 2 {
+		switch strings.ToUpper(commandArguments[1]) {
+		case "WRITE":
+			pauseWritesOnly = true
+		case "ALL":
+		default:
+			return protocolEncoder.WriteErrorResponse("ERREUR : le mode de CLIENT PAUSE doit être WRITE ou ALL")
+		}
+	}
+
+	redisServerInstance.clientPause.pauseClients(time.Now().Add(time.Duration(pauseMilliseconds)*time.Millisecond), pauseWritesOnly)
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
@@ -0,0 +1,266 @@
+package server
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clientInfoFields découpe la ligne de CLIENT INFO en champs champ=valeur
+func clientInfoFields(t *testing.T, client *testClient) map[string]string {
+	t.Helper()
+	infoReply := client.execute("CLIENT", "INFO")
+	_, infoLine, _ := strings.Cut(infoReply, "\r\n")
+	infoFields := make(map[string]string)
+	for _, infoField := range strings.Fields(infoLine) {
+		fieldName, fieldValue, _ := strings.Cut(infoField, "=")
+		infoFields[fieldName] = fieldValue
+	}
+	return infoFields
+}
+
+// expectConnectionClosed vérifie que le serveur a fermé la connexion du client
+func expectConnectionClosed(t *testing.T, client *testClient) {
+	t.Helper()
+	client.clientConnection.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, readError := client.replyReader.ReadByte(); readError != io.EOF {
+		t.Fatalf("la connexion devait être fermée par le serveur (%v)", readError)
+	}
+}
+
+func TestClientIdentityAndList(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	firstClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	secondClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	testClients := []*testClient{firstClient, secondClient}
+
+	runClientSteps(t, testClients, []clientTestStep{
+		{0, "CLIENT GETNAME", "$-1\r\n"},
+		{0, "CLIENT SETNAME worker-1", "+OK\r\n"},
+		{0, "CLIENT GETNAME", bulk("worker-1")},
+		{0, "CLIENT SETNAME nom-é", "-ERREUR : le nom du client ne peut contenir ni espace*"},
+		{0, "CLIENT SETINFO LIB-NAME redis-go-test", "+OK\r\n"},
+		{0, "CLIENT SETINFO LIB-AUTRE x", "-ERREUR : attribut CLIENT SETINFO inconnu 'LIB-AUTRE'*"},
+		{0, "SELECT 3", "+OK\r\n"},
+		{1, "SUBSCRIBE a b", "*3\r\n*"},
+	})
+	secondClient.readReply()
+
+	firstClientId, _ := strconv.ParseInt(strings.Trim(firstClient.execute("CLIENT", "ID"), ":\r\n"), 10, 64)
+	infoFields := clientInfoFields(t, firstClient)
+	testCases := []struct {
+		fieldName     string
+		expectedValue string
+	}{
+		{fieldName: "id", expectedValue: strconv.FormatInt(firstClientId, 10)},
+		{fieldName: "addr", expectedValue: firstClient.clientConnection.LocalAddr().String()},
+		{fieldName: "laddr", expectedValue: firstClient.clientConnection.RemoteAddr().String()},
+		{fieldName: "name", expectedValue: "worker-1"},
+		{fieldName: "db", expectedValue: "3"},
+		{fieldName: "flags", expectedValue: "N"},
+		{fieldName: "cmd", expectedValue: "client|info"},
+		{fieldName: "user", expectedValue: "default"},
+		{fieldName: "lib-name", expectedValue: "redis-go-test"},
+		{fieldName: "resp", expectedValue: "2"},
+	}
+	for _, testCase := range testCases {
+		t.Run("CLIENT INFO "+testCase.fieldName, func(t *testing.T) {
+			if infoFields[testCase.fieldName] != testCase.expectedValue {
+				t.Fatalf("%s=%s, attendu %s", testCase.fieldName, infoFields[testCase.fieldName], testCase.expectedValue)
+			}
+		})
+	}
+
+	listTestCases := []struct {
+		name                 string
+		listArguments        []string
+		expectedClientCount  int
+		expectedLineFragment string
+		expectedError        string
+	}{
+		{name: "toutes les connexions", expectedClientCount: 2},
+		{name: "abonnés pub/sub", listArguments: []string{"TYPE", "pubsub"}, expectedClientCount: 1, expectedLineFragment: "flags=P db=0 sub=2 psub=0 cmd=subscribe"},
+		{name: "connexions normales", listArguments: []string{"TYPE", "normal"}, expectedClientCount: 1, expectedLineFragment: "name=worker-1"},
+		{name: "réplicas", listArguments: []string{"TYPE", "replica"}},
+		{name: "par identifiant", listArguments: []string{"ID", strconv.FormatInt(firstClientId, 10), "999999"}, expectedClientCount: 1, expectedLineFragment: "name=worker-1"},
+		{name: "type inconnu", listArguments: []string{"TYPE", "master"}, expectedError: "-ERREUR : type de client inconnu 'master'"},
+		{name: "identifiant invalide", listArguments: []string{"ID", "0"}, expectedError: "-ERREUR : identifiant de client invalide '0'"},
+	}
+	for _, testCase := range listTestCases {
+		t.Run("CLIENT LIST "+testCase.name, func(t *testing.T) {
+			listReply := firstClient.execute(append([]string{"CLIENT", "LIST"}, testCase.listArguments...)...)
+			if testCase.expectedError != "" {
+				if !strings.HasPrefix(listReply, testCase.expectedError) {
+					t.Fatalf("réponse %q, attendu %q", listReply, testCase.expectedError)
+				}
+				return
+			}
+			if clientCount := strings.Count(listReply, "id="); clientCount != testCase.expectedClientCount {
+				t.Fatalf("%d connexions listées, attendu %d: %q", clientCount, testCase.expectedClientCount, listReply)
+			}
+			if !strings.Contains(listReply, testCase.expectedLineFragment) {
+				t.Fatalf("CLIENT LIST %v: %q ne contient pas %q", testCase.listArguments, listReply, testCase.expectedLineFragment)
+			}
+		})
+	}
+}
+
+func TestClientKill(t *testing.T) {
+	testCases := []struct {
+		name string
+		// killArguments reçoit le client visé et retourne les arguments de CLIENT KILL
+		killArguments func(t *testing.T, targetClient *testClient) []string
+		expectedReply string
+		expectKilled  bool
+	}{
+		{
+			name: "par identifiant",
+			killArguments: func(t *testing.T, targetClient *testClient) []string {
+				return []string{"ID", strings.Trim(targetClient.execute("CLIENT", "ID"), ":\r\n")}
+			},
+			expectedReply: ":1\r\n",
+			expectKilled:  true,
+		},
+		{
+			name: "par adresse, syntaxe historique",
+			killArguments: func(t *testing.T, targetClient *testClient) []string {
+				return []string{targetClient.clientConnection.LocalAddr().String()}
+			},
+			expectedReply: "+OK\r\n",
+			expectKilled:  true,
+		},
+		{
+			name: "par utilisateur sans toucher à la connexion courante",
+			killArguments: func(t *testing.T, targetClient *testClient) []string {
+				return []string{"USER", "default"}
+			},
+			expectedReply: ":1\r\n",
+			expectKilled:  true,
+		},
+		{
+			name: "par type pubsub",
+			killArguments: func(t *testing.T, targetClient *testClient) []string {
+				return []string{"TYPE", "pubsub"}
+			},
+			expectedReply: ":0\r\n",
+		},
+		{
+			name: "adresse inconnue, syntaxe historique",
+			killArguments: func(t *testing.T, targetClient *testClient) []string {
+				return []string{"127.0.0.1:1"}
+			},
+			expectedReply: "-ERREUR : aucun client avec cette adresse\r\n",
+		},
+		{
+			name: "filtre inconnu",
+			killArguments: func(t *testing.T, targetClient *testClient) []string {
+				return []string{"NAME", "x"}
+			},
+			expectedReply: "-ERREUR : filtre CLIENT KILL inconnu 'NAME'*",
+		},
+		{
+			name: "SKIPME invalide",
+			killArguments: func(t *testing.T, targetClient *testClient) []string {
+				return []string{"USER", "default", "SKIPME", "peut-être"}
+			},
+			expectedReply: "-ERREUR : SKIPME attend yes ou no\r\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			serverConfiguration := newTestServerConfiguration(t)
+			startTestServer(t, serverConfiguration)
+			adminClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			targetClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			targetClient.execute("PING")
+
+			runClientSteps(t, []*testClient{adminClient}, []clientTestStep{
+				{0, "CLIENT KILL " + strings.Join(testCase.killArguments(t, targetClient), " "), testCase.expectedReply},
+				{0, "PING", "+PONG\r\n"},
+			})
+			if testCase.expectKilled {
+				expectConnectionClosed(t, targetClient)
+			} else if pingReply := targetClient.execute("PING"); pingReply != "+PONG\r\n" {
+				t.Fatalf("la connexion visée doit rester ouverte: %q", pingReply)
+			}
+		})
+	}
+}
+
+func TestClientKillOwnConnection(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	ownClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	ownClientId := strings.Trim(ownClient.execute("CLIENT", "ID"), ":\r\n")
+	if killReply := ownClient.execute("CLIENT", "KILL", "ID", ownClientId, "SKIPME", "no"); killReply != ":1\r\n" {
+		t.Fatalf("CLIENT KILL de sa propre connexion: %q", killReply)
+	}
+	expectConnectionClosed(t, ownClient)
+}
+
+func TestClientPause(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+
+	testCases := []struct {
+		name          string
+		pauseMode     string
+		queuedLines   []string
+		commandLine   string
+		expectedReply string
+		expectPaused  bool
+	}{
+		{name: "écriture suspendue par PAUSE WRITE", pauseMode: "WRITE", commandLine: "SET k v", expectedReply: "+OK\r\n", expectPaused: true},
+		{name: "lecture servie pendant PAUSE WRITE", pauseMode: "WRITE", commandLine: "EXISTS k", expectedReply: ":*", expectPaused: false},
+		{name: "script suspendu par PAUSE WRITE", pauseMode: "WRITE", commandLine: "EVAL return(1) 0", expectedReply: ":1\r\n", expectPaused: true},
+		{name: "EXEC d'une écriture suspendu par PAUSE WRITE", pauseMode: "WRITE", queuedLines: []string{"SET k v"}, commandLine: "EXEC", expectedReply: "*1\r\n+OK\r\n", expectPaused: true},
+		{name: "EXEC d'un script suspendu par PAUSE WRITE", pauseMode: "WRITE", queuedLines: []string{"EVAL return(1) 0"}, commandLine: "EXEC", expectedReply: "*1\r\n:1\r\n", expectPaused: true},
+		{name: "EXEC d'une lecture servi pendant PAUSE WRITE", pauseMode: "WRITE", queuedLines: []string{"EXISTS k"}, commandLine: "EXEC", expectedReply: "*1\r\n:*", expectPaused: false},
+		{name: "lecture suspendue par PAUSE ALL", pauseMode: "ALL", commandLine: "EXISTS k", expectedReply: ":*", expectPaused: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			adminClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			pausedClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			// La transaction est ouverte avant la pause : seul EXEC peut être suspendu
+			if testCase.queuedLines != nil {
+				transactionSteps := []clientTestStep{{0, "MULTI", "+OK\r\n"}}
+				for _, queuedLine := range testCase.queuedLines {
+					transactionSteps = append(transactionSteps, clientTestStep{0, queuedLine, "+QUEUED\r\n"})
+				}
+				runClientSteps(t, []*testClient{pausedClient}, transactionSteps)
+			}
+			runClientSteps(t, []*testClient{adminClient}, []clientTestStep{
+				{0, "CLIENT PAUSE 60000 " + testCase.pauseMode, "+OK\r\n"},
+			})
+
+			pausedClient.clientConnection.Write([]byte(encodedCommand(testCase.commandLine)))
+			pausedClient.clientConnection.SetReadDeadline(time.Now().Add(200 * time.Millisecond))
+			_, peekError := pausedClient.replyReader.Peek(1)
+			if isPaused := peekError != nil; isPaused != testCase.expectPaused {
+				t.Fatalf("%s suspendue=%v, attendu %v", testCase.commandLine, isPaused, testCase.expectPaused)
+			}
+
+			runClientSteps(t, []*testClient{adminClient}, []clientTestStep{
+				{0, "CLIENT UNPAUSE", "+OK\r\n"},
+			})
+			expectedPrefix, isPrefix := strings.CutSuffix(testCase.expectedReply, "*")
+			if commandReply := pausedClient.readReply(); commandReply != testCase.expectedReply && !(isPrefix && strings.HasPrefix(commandReply, expectedPrefix)) {
+				t.Fatalf("%s après UNPAUSE: %q, attendu %q", testCase.commandLine, commandReply, testCase.expectedReply)
+			}
+		})
+	}
+
+	errorClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	runClientSteps(t, []*testClient{errorClient}, []clientTestStep{
+		{0, "CLIENT PAUSE -1", "-ERREUR : le timeout de CLIENT PAUSE doit être un entier positif (millisecondes)\r\n"},
+		{0, "CLIENT PAUSE 9223372036854775807", "-ERREUR : le timeout de CLIENT PAUSE est trop grand\r\n"},
+		{0, "CLIENT PAUSE 10 READ", "-ERREUR : le mode de CLIENT PAUSE doit être WRITE ou ALL\r\n"},
+		{0, "CLIENT UNPAUSE maintenant", "-ERREUR : CLIENT UNPAUSE ne prend aucun argument\r\n"},
+		{0, "CLIENT NOPE", "-ERREUR : sous-commande inconnue 'NOPE' pour CLIENT*"},
+	})
+}
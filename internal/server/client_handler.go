@@ -1,15 +1,24 @@
 package server
 
 import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
 	"log"
 	"net"
+	"strings"
+	"sync"
 	"time"
 
+	"redis-go/internal/commands"
 	"redis-go/internal/protocol"
 )
 
 // handleClientConnection gère une connexion client
-func (redisServerInstance *RedisServerInstance) handleClientConnection(clientConnection net.Conn) {
+func (redisServerInstance *RedisServerInstance) handleClientConnection(connectionState *clientConnectionState) {
+	clientConnection := connectionState.clientConnection
 	defer redisServerInstance.activeGoroutines.Done()
 	defer func() {
 		log.Printf("🔌 Connexion fermée depuis %s", clientConnection.RemoteAddr())
@@ -19,8 +28,33 @@ func (redisServerInstance *RedisServerInstance) handleClientConnection(clientCon
 		redisServerInstance.clientsMutex.Unlock()
 	}()
 
+	// Connexion TLS : la négociation (et la vérification du certificat client en mTLS) précède toute commande
+	if tlsConnection, isTlsConnection := clientConnection.(*tls.Conn); isTlsConnection {
+		tlsConnection.SetDeadline(time.Now().Add(30 * time.Second))
+		if handshakeError := tlsConnection.Handshake(); handshakeError != nil {
+			log.Printf("🔒 Échec de la négociation TLS avec %s: %v", clientConnection.RemoteAddr(), handshakeError)
+			return
+		}
+		tlsConnection.SetDeadline(time.Time{})
+	}
+
 	protocolParser := protocol.NewRedisSerializationProtocolParser(clientConnection)
-	protocolEncoder := protocol.NewRedisSerializationProtocolEncoder(clientConnection)
+	// Les réponses sont bufferisées puis envoyées une fois la commande entièrement traitée
+	// (ex: après le fsync de l'AOF en mode always)
+	responseWriter := bufio.NewWriter(clientConnection)
+	protocolEncoder := protocol.NewRedisSerializationProtocolEncoder(responseWriter)
+	// Les messages pub/sub sont poussés par une autre goroutine sur le même writer
+	var responseMutex sync.Mutex
+
+	// État MULTI/WATCH propre à la connexion
+	transactionState := newClientTransactionState()
+	defer transactionState.discardTransaction()
+	defer redisServerInstance.removeReplicaLink(connectionState)
+
+	// Abonnements pub/sub de la connexion
+	subscriber := newPubSubSubscriber(clientConnection, &responseMutex, responseWriter, protocolEncoder)
+	defer subscriber.stopMessageDelivery()
+	defer redisServerInstance.pubSubHub.removeSubscriber(subscriber)
 
 	// Boucle de traitement des commandes
 	for {
@@ -28,8 +62,12 @@ func (redisServerInstance *RedisServerInstance) handleClientConnection(clientCon
 		case <-redisServerInstance.shutdownSignal:
 			return
 		default:
-			// Définir un timeout pour éviter les blocages
-			clientConnection.SetReadDeadline(time.Now().Add(30 * time.Second))
+			// Définir un timeout pour éviter les blocages (un abonné pub/sub peut rester inactif indéfiniment)
+			if subscriber.isInSubscribedMode() {
+				clientConnection.SetReadDeadline(time.Time{})
+			} else {
+				clientConnection.SetReadDeadline(time.Now().Add(30 * time.Second))
+			}
 
 			// Parsing de la commande
 			parsedCommandArguments, parseError := protocolParser.ParseIncomingCommand()
@@ -37,8 +75,13 @@ func (redisServerInstance *RedisServerInstance) handleClientConnection(clientCon
 				// Log différencié selon le type d'erreur
 				if networkError, isNetworkError := parseError.(net.Error); isNetworkError && networkError.Timeout() {
 					log.Printf("⏰ Timeout de connexion pour %s", clientConnection.RemoteAddr())
-				} else {
+				} else if !errors.Is(parseError, io.EOF) && !errors.Is(parseError, net.ErrClosed) {
 					log.Printf("⚠️  Erreur de parsing depuis %s: %v", clientConnection.RemoteAddr(), parseError)
+					// Comme Redis : signaler l'erreur de protocole avant de fermer (utile en telnet / nc)
+					responseMutex.Lock()
+					protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : erreur de protocole: %v", parseError))
+					responseWriter.Flush()
+					responseMutex.Unlock()
 				}
 				return
 			}
@@ -46,6 +89,8 @@ func (redisServerInstance *RedisServerInstance) handleClientConnection(clientCon
 			if len(parsedCommandArguments) == 0 {
 				continue
 			}
+			redisServerInstance.statistics.processedCommandCount.Add(1)
+			connectionState.recordCommandReceived(parsedCommandArguments[0], parsedCommandArguments[1:])
 
 			// Extraction de la commande et des arguments
 			receivedCommandName := parsedCommandArguments[0]
@@ -54,11 +99,70 @@ func (redisServerInstance *RedisServerInstance) handleClientConnection(clientCon
 			// Log des commandes (optionnel, peut être verbeux)
 			// log.Printf("📝 Commande reçue de %s: %s %v", clientConnection.RemoteAddr(), receivedCommandName, receivedCommandArguments)
 
-			// Exécution de la commande
-			if executionError := redisServerInstance.commandRegistry.ExecuteCommand(receivedCommandName, receivedCommandArguments, redisServerInstance.redisStorage, protocolEncoder); executionError != nil {
+			// CLIENT PAUSE : la commande attend la fin de la pause (les réplicas et CLIENT ne sont jamais suspendus)
+			if connectionState.replicaLink == nil && !strings.EqualFold(receivedCommandName, "CLIENT") {
+				redisServerInstance.waitWhileClientsPaused(isPausableWriteCommand(transactionState, receivedCommandName))
+			}
+
+			responseMutex.Lock()
+
+			// QUIT : confirmer puis fermer la connexion
+			if strings.EqualFold(receivedCommandName, "QUIT") {
+				protocolEncoder.WriteSimpleStringResponse("OK")
+				responseWriter.Flush()
+				responseMutex.Unlock()
+				return
+			}
+
+			// Authentification et permissions ACL des commandes traitées hors du registre
+			commandHandled, executionError := false, error(nil)
+			if connectionState.authenticatedUser == nil && !commands.IsAuthenticationExemptCommand(receivedCommandName) {
+				commandHandled, executionError = true, protocolEncoder.WriteErrorResponse("NOAUTH Authentification requise.")
+			} else if permissionError := redisServerInstance.checkConnectionCommandPermission(connectionState, receivedCommandName, receivedCommandArguments); permissionError != "" {
+				commandHandled, executionError = true, protocolEncoder.WriteErrorResponse(permissionError)
+			}
+
+			// Exécution de la commande : réplication, connexion, pub/sub, mise en file si une transaction est ouverte,
+			// commande bloquante (BLPOP...) ou exécution directe
+			if !commandHandled && !transactionState.isInsideTransaction {
+				commandHandled, executionError = redisServerInstance.processReplicationCommand(connectionState, clientConnection, receivedCommandName, receivedCommandArguments, protocolEncoder)
+			}
+			if !commandHandled && !transactionState.isInsideTransaction {
+				commandHandled, executionError = redisServerInstance.processConnectionCommand(connectionState, receivedCommandName, receivedCommandArguments, protocolEncoder)
+			}
+			if !commandHandled && !transactionState.isInsideTransaction {
+				commandHandled, executionError = redisServerInstance.processPubSubCommand(subscriber, receivedCommandName, receivedCommandArguments, protocolEncoder)
+			}
+			if !commandHandled {
+				commandHandled, executionError = redisServerInstance.processTransactionCommand(connectionState, transactionState, receivedCommandName, receivedCommandArguments, protocolEncoder)
+			}
+			if !commandHandled && commands.IsBlockingListCommand(receivedCommandName) {
+				commandHandled = true
+				var clientConnected bool
+				clientConnected, executionError = redisServerInstance.executeBlockingListCommand(connectionState, protocolParser, &responseMutex, responseWriter, receivedCommandName, receivedCommandArguments, protocolEncoder)
+				if !clientConnected {
+					responseMutex.Unlock()
+					return
+				}
+			}
+			if !commandHandled {
+				executionError = redisServerInstance.commandRegistry.ExecuteCommand(connectionState.authenticatedUser, receivedCommandName, receivedCommandArguments, redisServerInstance.selectedStorage(connectionState), protocolEncoder)
+			}
+			if executionError != nil {
 				log.Printf("❌ Erreur d'exécution de commande pour %s: %v", clientConnection.RemoteAddr(), executionError)
 				protocolEncoder.WriteErrorResponse("ERREUR : erreur interne du serveur")
 			}
+
+			flushError := responseWriter.Flush()
+			connectionState.updateClientFlags(subscriber, transactionState, protocolEncoder.GetProtocolVersion())
+			responseMutex.Unlock()
+			if flushError != nil {
+				log.Printf("⚠️  Impossible d'envoyer la réponse à %s: %v", clientConnection.RemoteAddr(), flushError)
+				return
+			}
+			if connectionState.closeAfterReply {
+				return
+			}
 		}
 	}
 }
@@ -0,0 +1,49 @@
+package server
+
+import (
+	"io"
+	"testing"
+	"time"
+)
+
+func TestInlineCommandsFromTelnetClient(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+
+	testCases := []struct {
+		name                 string
+		inlineInput          string
+		expectedReplies      []string
+		expectClosedAfterAll bool
+	}{
+		{name: "PING", inlineInput: "PING\r\n", expectedReplies: []string{"+PONG\r\n"}},
+		{name: "guillemets et fin de ligne sans retour chariot", inlineInput: "SET k \"a b\"\r\nGET k\n", expectedReplies: []string{"+OK\r\n", bulk("a b")}},
+		{name: "ligne vide ignorée", inlineInput: "\r\n  \r\nECHO bonjour\r\n", expectedReplies: []string{bulk("bonjour")}},
+		{name: "inline et multibulk sur la même connexion", inlineInput: "ECHO a\r\n*2\r\n$4\r\nECHO\r\n$1\r\nb\r\n", expectedReplies: []string{bulk("a"), bulk("b")}},
+		{
+			name:                 "guillemets non fermés : erreur de protocole puis fermeture",
+			inlineInput:          "SET k \"valeur\r\n",
+			expectedReplies:      []string{"-ERREUR : erreur de protocole: unbalanced quotes in request\r\n"},
+			expectClosedAfterAll: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			telnetClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			if _, writeError := telnetClient.clientConnection.Write([]byte(testCase.inlineInput)); writeError != nil {
+				t.Fatalf("envoi impossible: %v", writeError)
+			}
+			for _, expectedReply := range testCase.expectedReplies {
+				if actualReply := telnetClient.readReply(); actualReply != expectedReply {
+					t.Fatalf("%q: réponse %q, attendu %q", testCase.inlineInput, actualReply, expectedReply)
+				}
+			}
+			if testCase.expectClosedAfterAll {
+				telnetClient.clientConnection.SetDeadline(time.Now().Add(5 * time.Second))
+				if _, readError := telnetClient.replyReader.ReadByte(); readError != io.EOF {
+					t.Fatalf("connexion toujours ouverte après une erreur de protocole (%v)", readError)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"redis-go/internal/commands"
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// transactionForbiddenCommands liste les commandes propres à la connexion qui ne peuvent pas être mises en file
+// SELECT est mis en file : EXEC l'applique à son tour pour les commandes qui le suivent
+var transactionForbiddenCommands = map[string]bool{
+	"SUBSCRIBE": true, "UNSUBSCRIBE": true, "PSUBSCRIBE": true, "PUNSUBSCRIBE": true,
+	"HELLO": true, "AUTH": true, "ACL": true, "CLIENT": true,
+	"PSYNC": true, "SYNC": true, "REPLCONF": true,
+}
+
+// watchedKeyReference identifie une clé surveillée dans la base où WATCH a été exécuté
+type watchedKeyReference struct {
+	watchedStorage *storage.RedisInMemoryStorage
+	watchedKey     string
+}
+
+// clientTransactionState contient l'état MULTI/WATCH propre à une connexion
+type clientTransactionState struct {
+	isInsideTransaction bool
+	// hasQueueingError est positionné par une erreur de syntaxe pendant la mise en file (EXECABORT)
+	hasQueueingError   bool
+	queuedCommands     []commands.RedisQueuedCommand
+	watchedKeyVersions map[watchedKeyReference]uint64
+}
+
+// newClientTransactionState crée l'état de transaction d'une nouvelle connexion
+func newClientTransactionState() *clientTransactionState {
+	return &clientTransactionState{
+		watchedKeyVersions: make(map[watchedKeyReference]uint64),
+	}
+}
+
+// discardTransaction abandonne la transaction en cours et les clés surveillées
+func (transactionState *clientTransactionState) discardTransaction() {
+	transactionState.isInsideTransaction = false
+	transactionState.hasQueueingError = false
+	transactionState.queuedCommands = nil
+	transactionState.unwatchAllKeys()
+}
+
+// unwatchAllKeys arrête la surveillance de toutes les clés de la connexion
+func (transactionState *clientTransactionState) unwatchAllKeys() {
+	for watchedReference := range transactionState.watchedKeyVersions {
+		watchedReference.watchedStorage.UnwatchKey(watchedReference.watchedKey)
+	}
+	transactionState.watchedKeyVersions = make(map[watchedKeyReference]uint64)
+}
+
+// haveWatchedKeysChanged indique si une clé surveillée a été modifiée depuis WATCH
+func (transactionState *clientTransactionState) haveWatchedKeysChanged() bool {
+	for watchedReference, watchedVersion := range transactionState.watchedKeyVersions {
+		if watchedReference.watchedStorage.HasWatchedKeyChanged(watchedReference.watchedKey, watchedVersion) {
+			return true
+		}
+	}
+	return false
+}
+
+// processTransactionCommand gère MULTI/EXEC/DISCARD/WATCH/UNWATCH et la mise en file des commandes
+// La base sélectionnée et l'utilisateur de la connexion sont utilisés par WATCH, la mise en file et EXEC
+// Retourne false si la commande doit être exécutée normalement
+func (redisServerInstance *RedisServerInstance) processTransactionCommand(connectionState *clientConnectionState, transactionState *clientTransactionState, commandName string, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) (bool, error) {
+	selectedStorage := redisServerInstance.selectedStorage(connectionState)
+
+	switch strings.ToUpper(commandName) {
+	case "MULTI":
+		if len(commandArguments) != 0 {
+			return true, redisServerInstance.rejectTransactionCommand(transactionState, "ERREUR : MULTI ne prend aucun argument", protocolEncoder)
+		}
+		if transactionState.isInsideTransaction {
+			return true, protocolEncoder.WriteErrorResponse("ERREUR : MULTI ne peut pas être imbriqué")
+		}
+		transactionState.isInsideTransaction = true
+		return true, protocolEncoder.WriteSimpleStringResponse("OK")
+
+	case "EXEC":
+		if len(commandArguments) != 0 {
+			return true, redisServerInstance.rejectTransactionCommand(transactionState, "ERREUR : EXEC ne prend aucun argument", protocolEncoder)
+		}
+		if !transactionState.isInsideTransaction {
+			return true, protocolEncoder.WriteErrorResponse("ERREUR : EXEC sans MULTI")
+		}
+		return true, redisServerInstance.executeQueuedTransaction(connectionState, transactionState, protocolEncoder)
+
+	case "DISCARD":
+		if len(commandArguments) != 0 {
+			return true, redisServerInstance.rejectTransactionCommand(transactionState, "ERREUR : DISCARD ne prend aucun argument", protocolEncoder)
+		}
+		if !transactionState.isInsideTransaction {
+			return true, protocolEncoder.WriteErrorResponse("ERREUR : DISCARD sans MULTI")
+		}
+		transactionState.discardTransaction()
+		return true, protocolEncoder.WriteSimpleStringResponse("OK")
+
+	case "WATCH":
+		if len(commandArguments) == 0 {
+			return true, redisServerInstance.rejectTransactionCommand(transactionState, "ERREUR : nombre d'arguments incorrect pour 'WATCH' (attendu: WATCH clé [clé ...])", protocolEncoder)
+		}
+		if transactionState.isInsideTransaction {
+			return true, protocolEncoder.WriteErrorResponse("ERREUR : WATCH n'est pas autorisé dans MULTI")
+		}
+		for _, keyToWatch := range commandArguments {
+			watchedReference := watchedKeyReference{watchedStorage: selectedStorage, watchedKey: keyToWatch}
+			if _, alreadyWatched := transactionState.watchedKeyVersions[watchedReference]; !alreadyWatched {
+				transactionState.watchedKeyVersions[watchedReference] = selectedStorage.WatchKey(keyToWatch)
+			}
+		}
+		return true, protocolEncoder.WriteSimpleStringResponse("OK")
+
+	case "UNWATCH":
+		if len(commandArguments) != 0 {
+			return true, redisServerInstance.rejectTransactionCommand(transactionState, "ERREUR : UNWATCH ne prend aucun argument", protocolEncoder)
+		}
+		if !transactionState.isInsideTransaction {
+			transactionState.unwatchAllKeys()
+		}
+		return true, protocolEncoder.WriteSimpleStringResponse("OK")
+	}
+
+	if !transactionState.isInsideTransaction {
+		return false, nil
+	}
+
+	// Dans MULTI : les commandes liées à la connexion et les erreurs de syntaxe sont refusées dès la mise en file
+	if transactionForbiddenCommands[strings.ToUpper(commandName)] {
+		return true, redisServerInstance.rejectTransactionCommand(transactionState, fmt.Sprintf("ERREUR : la commande '%s' n'est pas autorisée dans MULTI", commandName), protocolEncoder)
+	}
+	if strings.EqualFold(commandName, "SELECT") {
+		if len(commandArguments) != 1 {
+			return true, redisServerInstance.rejectTransactionCommand(transactionState, "ERREUR : nombre d'arguments incorrect pour 'SELECT'", protocolEncoder)
+		}
+	} else if syntaxErrorMessage := redisServerInstance.commandRegistry.ValidateCommandSyntax(connectionState.authenticatedUser, commandName, commandArguments); syntaxErrorMessage != "" {
+		return true, redisServerInstance.rejectTransactionCommand(transactionState, syntaxErrorMessage, protocolEncoder)
+	}
+
+	transactionState.queuedCommands = append(transactionState.queuedCommands, commands.RedisQueuedCommand{
+		CommandName:      commandName,
+		CommandArguments: commandArguments,
+	})
+	return true, protocolEncoder.WriteSimpleStringResponse("QUEUED")
+}
+
+// rejectTransactionCommand répond une erreur et, dans MULTI, fera échouer EXEC avec EXECABORT
+func (redisServerInstance *RedisServerInstance) rejectTransactionCommand(transactionState *clientTransactionState, errorMessage string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if transactionState.isInsideTransaction {
+		transactionState.hasQueueingError = true
+	}
+	return protocolEncoder.WriteErrorResponse(errorMessage)
+}
+
+// executeQueuedTransaction exécute EXEC : EXECABORT, annulation par WATCH ou exécution atomique
+func (redisServerInstance *RedisServerInstance) executeQueuedTransaction(connectionState *clientConnectionState, transactionState *clientTransactionState, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	defer transactionState.discardTransaction()
+
+	if transactionState.hasQueueingError {
+		return protocolEncoder.WriteErrorResponse("EXECABORT Transaction annulée à cause d'erreurs précédentes")
+	}
+
+	transactionExecuted, executionError := redisServerInstance.commandRegistry.ExecuteTransaction(
+		connectionState.authenticatedUser,
+		transactionState.queuedCommands,
+		redisServerInstance.selectedStorage(connectionState),
+		protocolEncoder,
+		func() bool { return !transactionState.haveWatchedKeysChanged() },
+		func(commandArguments []string) (*storage.RedisInMemoryStorage, error) {
+			selectError := redisServerInstance.handleSelectCommand(connectionState, commandArguments, protocolEncoder)
+			return redisServerInstance.selectedStorage(connectionState), selectError
+		})
+	if executionError != nil {
+		return executionError
+	}
+
+	// Une clé surveillée a été modifiée : la transaction n'est pas exécutée
+	if !transactionExecuted {
+		return protocolEncoder.WriteNullArrayResponse()
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+// clientTestStep est une commande envoyée par l'un des clients d'un test et sa réponse RESP attendue
+// Une réponse attendue se terminant par "*" n'est comparée que sur son préfixe
+type clientTestStep struct {
+	clientIndex   int
+	commandLine   string
+	expectedReply string
+}
+
+// runClientSteps exécute les étapes dans l'ordre, chacune sur le client indiqué
+func runClientSteps(t *testing.T, testClients []*testClient, testSteps []clientTestStep) {
+	t.Helper()
+	for _, testStep := range testSteps {
+		actualReply := testClients[testStep.clientIndex].execute(strings.Fields(testStep.commandLine)...)
+		if expectedPrefix, isPrefix := strings.CutSuffix(testStep.expectedReply, "*"); isPrefix {
+			if !strings.HasPrefix(actualReply, expectedPrefix) {
+				t.Fatalf("client %d, %s: réponse %q, attendu le préfixe %q", testStep.clientIndex, testStep.commandLine, actualReply, expectedPrefix)
+			}
+			continue
+		}
+		if actualReply != testStep.expectedReply {
+			t.Fatalf("client %d, %s: réponse %q, attendu %q", testStep.clientIndex, testStep.commandLine, actualReply, testStep.expectedReply)
+		}
+	}
+}
+
+func TestTransactions(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+
+	testCases := []struct {
+		name      string
+		testSteps []clientTestStep
+	}{
+		{
+			name: "EXEC retourne les réponses de chaque commande",
+			testSteps: []clientTestStep{
+				{0, "MULTI", "+OK\r\n"},
+				{0, "SET k 1", "+QUEUED\r\n"},
+				{0, "INCR k", "+QUEUED\r\n"},
+				{0, "GET k", "+QUEUED\r\n"},
+				{1, "EXISTS k", ":0\r\n"},
+				{0, "EXEC", encodedArray("+OK\r\n", ":2\r\n", bulk("2"))},
+				{1, "GET k", bulk("2")},
+			},
+		},
+		{
+			name: "une erreur d'exécution n'interrompt pas la transaction",
+			testSteps: []clientTestStep{
+				{0, "SET k texte", "+OK\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "INCR k", "+QUEUED\r\n"},
+				{0, "SET k 5", "+QUEUED\r\n"},
+				{0, "EXEC", "*2\r\n-ERREUR*"},
+				{0, "GET k", bulk("5")},
+			},
+		},
+		{
+			name: "une erreur de syntaxe à la mise en file annule EXEC",
+			testSteps: []clientTestStep{
+				{0, "MULTI", "+OK\r\n"},
+				{0, "SET k 1", "+QUEUED\r\n"},
+				{0, "SET k", "-ERREUR*"},
+				{0, "NOSUCHCOMMAND", "-ERREUR*"},
+				{0, "EXEC", "-EXECABORT*"},
+				{0, "EXISTS k", ":0\r\n"},
+				{0, "EXEC", "-ERREUR : EXEC sans MULTI\r\n"},
+			},
+		},
+		{
+			name: "DISCARD et commandes de connexion refusées dans MULTI",
+			testSteps: []clientTestStep{
+				{0, "DISCARD", "-ERREUR : DISCARD sans MULTI\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "MULTI", "-ERREUR : MULTI ne peut pas être imbriqué\r\n"},
+				{0, "WATCH k", "-ERREUR : WATCH n'est pas autorisé dans MULTI\r\n"},
+				{0, "SET k 1", "+QUEUED\r\n"},
+				{0, "DISCARD", "+OK\r\n"},
+				{0, "EXISTS k", ":0\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "HELLO 3", "-ERREUR : la commande 'HELLO' n'est pas autorisée dans MULTI\r\n"},
+				{0, "EXEC", "-EXECABORT*"},
+			},
+		},
+		{
+			name: "SELECT mis en file change la base des commandes suivantes",
+			testSteps: []clientTestStep{
+				{0, "MULTI", "+OK\r\n"},
+				{0, "SET k base-0", "+QUEUED\r\n"},
+				{0, "SELECT 1", "+QUEUED\r\n"},
+				{0, "SET k base-1", "+QUEUED\r\n"},
+				{0, "SELECT 99", "+QUEUED\r\n"},
+				{0, "GET k", "+QUEUED\r\n"},
+				{0, "EXEC", "*5\r\n+OK\r\n+OK\r\n+OK\r\n-ERREUR : index de base hors limites (0 à 15)\r\n" + bulk("base-1")},
+				{0, "GET k", bulk("base-1")},
+				{1, "GET k", bulk("base-0")},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "SELECT", "-ERREUR : nombre d'arguments incorrect pour 'SELECT'\r\n"},
+				{0, "EXEC", "-EXECABORT*"},
+			},
+		},
+		{
+			name: "WATCH annule EXEC si un autre client modifie la clé",
+			testSteps: []clientTestStep{
+				{0, "SET k 1", "+OK\r\n"},
+				{0, "WATCH k", "+OK\r\n"},
+				{1, "SET k 2", "+OK\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "SET k 3", "+QUEUED\r\n"},
+				{0, "EXEC", "*-1\r\n"},
+				{0, "GET k", bulk("2")},
+				// EXEC a retiré la surveillance : la transaction suivante s'exécute
+				{1, "SET k 4", "+OK\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "SET k 3", "+QUEUED\r\n"},
+				{0, "EXEC", encodedArray("+OK\r\n")},
+			},
+		},
+		{
+			name: "WATCH détecte la suppression et l'expiration, pas les autres clés",
+			testSteps: []clientTestStep{
+				{0, "SET k 1", "+OK\r\n"},
+				{0, "WATCH k", "+OK\r\n"},
+				{1, "SET other 1", "+OK\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "INCR k", "+QUEUED\r\n"},
+				{0, "EXEC", encodedArray(":2\r\n")},
+				{0, "WATCH k", "+OK\r\n"},
+				{1, "DEL k", ":1\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "INCR k", "+QUEUED\r\n"},
+				{0, "EXEC", "*-1\r\n"},
+				{0, "SET k 1", "+OK\r\n"},
+				{0, "WATCH k", "+OK\r\n"},
+				{1, "PEXPIRE k 1", ":1\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "INCR k", "+QUEUED\r\n"},
+				{0, "EXEC", "*-1\r\n"},
+			},
+		},
+		{
+			name: "UNWATCH et WATCH par base",
+			testSteps: []clientTestStep{
+				{0, "WATCH k", "+OK\r\n"},
+				{0, "UNWATCH", "+OK\r\n"},
+				{1, "SET k 1", "+OK\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "GET k", "+QUEUED\r\n"},
+				{0, "EXEC", encodedArray(bulk("1"))},
+				{0, "WATCH k", "+OK\r\n"},
+				{1, "SELECT 1", "+OK\r\n"},
+				{1, "SET k autre-base", "+OK\r\n"},
+				{0, "MULTI", "+OK\r\n"},
+				{0, "GET k", "+QUEUED\r\n"},
+				{0, "EXEC", encodedArray(bulk("1"))},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testClients := []*testClient{
+				dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber),
+				dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber),
+			}
+			testClients[0].execute("FLUSHALL")
+			runClientSteps(t, testClients, testCase.testSteps)
+		})
+	}
+}
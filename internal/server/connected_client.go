@@ -0,0 +1,220 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"redis-go/internal/commands"
+)
+
+// clientConnectionState contient l'état propre à une connexion (base sélectionnée, utilisateur ACL, nom...)
+// et les informations affichées par CLIENT LIST
+// Les champs protégés par metadataMutex ne sont modifiés que par la goroutine de la connexion,
+// qui peut donc les lire sans verrou ; les autres connexions les lisent sous verrou
+type clientConnectionState struct {
+	clientId         int64
+	clientConnection net.Conn
+	creationTime     time.Time
+
+	metadataMutex         sync.Mutex
+	selectedDatabaseIndex int
+	// authenticatedUser vaut nil tant que la connexion ne s'est pas authentifiée (NOAUTH)
+	authenticatedUser *commands.RedisAclUser
+	// clientName est défini par CLIENT SETNAME ou HELLO SETNAME
+	clientName     string
+	libraryName    string
+	libraryVersion string
+	// lastCommandName et lastInteractionTime décrivent la dernière commande reçue (cmd, idle)
+	lastCommandName     string
+	lastInteractionTime time.Time
+	// clientFlags, subscribedChannelCount, subscribedPatternCount et protocolVersion sont mis à jour après chaque commande
+	clientFlags            string
+	subscribedChannelCount int
+	subscribedPatternCount int
+	protocolVersion        int
+
+	// replicaListeningPort est annoncé par un réplica avant PSYNC (REPLCONF listening-port)
+	replicaListeningPort int
+	// replicaLink est défini une fois PSYNC accepté : la connexion reçoit alors le flux de réplication
+	replicaLink *replicaLink
+	// closeAfterReply ferme la connexion une fois la réponse envoyée (CLIENT KILL de sa propre connexion)
+	closeAfterReply bool
+}
+
+// newClientConnectionState crée l'état d'une connexion acceptée et lui attribue un identifiant unique
+// Sans mot de passe sur l'utilisateur default, la connexion est authentifiée d'office
+func (redisServerInstance *RedisServerInstance) newClientConnectionState(clientConnection net.Conn) *clientConnectionState {
+	connectionTime := time.Now()
+	return &clientConnectionState{
+		clientId:            redisServerInstance.nextClientId.Add(1),
+		clientConnection:    clientConnection,
+		creationTime:        connectionTime,
+		authenticatedUser:   redisServerInstance.commandRegistry.GetAccessControlList().GetDefaultUserWithoutPassword(),
+		lastInteractionTime: connectionTime,
+		clientFlags:         "N",
+		protocolVersion:     2,
+	}
+}
+
+// setSelectedDatabase change la base de la connexion (SELECT)
+func (connectionState *clientConnectionState) setSelectedDatabase(databaseIndex int) {
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+	connectionState.selectedDatabaseIndex = databaseIndex
+}
+
+// setAuthenticatedUser change l'utilisateur ACL de la connexion (AUTH, HELLO AUTH)
+func (connectionState *clientConnectionState) setAuthenticatedUser(authenticatedUser *commands.RedisAclUser) {
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+	connectionState.authenticatedUser = authenticatedUser
+}
+
+// setClientName change le nom de la connexion (CLIENT SETNAME, HELLO SETNAME)
+func (connectionState *clientConnectionState) setClientName(clientName string) {
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+	connectionState.clientName = clientName
+}
+
+// setLibraryInformation enregistre le nom ou la version de la bibliothèque cliente (CLIENT SETINFO)
+func (connectionState *clientConnectionState) setLibraryInformation(libraryName *string, libraryVersion *string) {
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+	if libraryName != nil {
+		connectionState.libraryName = *libraryName
+	}
+	if libraryVersion != nil {
+		connectionState.libraryVersion = *libraryVersion
+	}
+}
+
+// recordCommandReceived enregistre la commande reçue (cmd et idle de CLIENT LIST)
+func (connectionState *clientConnectionState) recordCommandReceived(commandName string, commandArguments []string) {
+	lastCommandName := strings.ToLower(commandName)
+	// Comme Redis, les commandes à sous-commande sont affichées sous la forme client|list
+	if len(commandArguments) > 0 && isContainerCommand(lastCommandName) {
+		lastCommandName += "|" + strings.ToLower(commandArguments[0])
+	}
+
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+	connectionState.lastCommandName = lastCommandName
+	connectionState.lastInteractionTime = time.Now()
+}
+
+// isContainerCommand indique si une commande (en minuscules) regroupe des sous-commandes
+func isContainerCommand(lowerCommandName string) bool {
+	switch lowerCommandName {
+	case "client", "acl", "pubsub", "script":
+		return true
+	}
+	return false
+}
+
+// updateClientFlags met à jour les informations de CLIENT LIST qui dépendent de l'état de la connexion
+// Flags : S réplica, P abonné pub/sub, x transaction MULTI ouverte, N aucun (b est géré par setClientBlocked)
+func (connectionState *clientConnectionState) updateClientFlags(subscriber *pubSubSubscriber, transactionState *clientTransactionState, protocolVersion int) {
+	var flagsBuilder strings.Builder
+	if connectionState.replicaLink != nil {
+		flagsBuilder.WriteByte('S')
+	}
+	if subscriber.isInSubscribedMode() {
+		flagsBuilder.WriteByte('P')
+	}
+	if transactionState.isInsideTransaction {
+		flagsBuilder.WriteByte('x')
+	}
+	if flagsBuilder.Len() == 0 {
+		flagsBuilder.WriteByte('N')
+	}
+
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+	connectionState.clientFlags = flagsBuilder.String()
+	connectionState.subscribedChannelCount = len(subscriber.subscribedChannels)
+	connectionState.subscribedPatternCount = len(subscriber.subscribedPatterns)
+	connectionState.protocolVersion = protocolVersion
+}
+
+// setClientBlocked ajoute ou retire le flag b (client bloqué par BLPOP, BRPOP, BLMOVE ou BRPOPLPUSH)
+func (connectionState *clientConnectionState) setClientBlocked(isBlocked bool) {
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+	clientFlags := strings.ReplaceAll(connectionState.clientFlags, "b", "")
+	if isBlocked {
+		clientFlags = strings.TrimPrefix(clientFlags, "N") + "b"
+	} else if clientFlags == "" {
+		clientFlags = "N"
+	}
+	connectionState.clientFlags = clientFlags
+}
+
+// getClientAddress retourne l'adresse du client (pour un socket Unix : chemin du socket suivi de :0, comme Redis)
+func (connectionState *clientConnectionState) getClientAddress() string {
+	if connectionState.clientConnection.LocalAddr().Network() == "unix" {
+		return connectionState.getLocalAddress()
+	}
+	return connectionState.clientConnection.RemoteAddr().String()
+}
+
+// getLocalAddress retourne l'adresse locale de la connexion (listener qui l'a acceptée)
+func (connectionState *clientConnectionState) getLocalAddress() string {
+	localAddress := connectionState.clientConnection.LocalAddr()
+	if localAddress.Network() == "unix" {
+		return localAddress.String() + ":0"
+	}
+	return localAddress.String()
+}
+
+// getUserName retourne le nom de l'utilisateur ACL de la connexion (vide si elle n'est pas authentifiée)
+// Appelé avec metadataMutex détenu
+func (connectionState *clientConnectionState) getUserName() string {
+	if connectionState.authenticatedUser == nil {
+		return ""
+	}
+	return connectionState.authenticatedUser.GetUserName()
+}
+
+// describeClient retourne la ligne de CLIENT LIST / CLIENT INFO décrivant la connexion
+func (connectionState *clientConnectionState) describeClient() string {
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+
+	currentTime := time.Now()
+	return fmt.Sprintf("id=%d addr=%s laddr=%s name=%s age=%d idle=%d flags=%s db=%d sub=%d psub=%d cmd=%s user=%s lib-name=%s lib-ver=%s resp=%d",
+		connectionState.clientId,
+		connectionState.getClientAddress(),
+		connectionState.getLocalAddress(),
+		connectionState.clientName,
+		int64(currentTime.Sub(connectionState.creationTime).Seconds()),
+		int64(currentTime.Sub(connectionState.lastInteractionTime).Seconds()),
+		connectionState.clientFlags,
+		connectionState.selectedDatabaseIndex,
+		connectionState.subscribedChannelCount,
+		connectionState.subscribedPatternCount,
+		connectionState.lastCommandName,
+		connectionState.getUserName(),
+		connectionState.libraryName,
+		connectionState.libraryVersion,
+		connectionState.protocolVersion,
+	)
+}
+
+// getClientType retourne le type de la connexion pour CLIENT LIST TYPE / CLIENT KILL TYPE
+func (connectionState *clientConnectionState) getClientType() string {
+	connectionState.metadataMutex.Lock()
+	defer connectionState.metadataMutex.Unlock()
+
+	switch {
+	case strings.Contains(connectionState.clientFlags, "S"):
+		return "replica"
+	case strings.Contains(connectionState.clientFlags, "P"):
+		return "pubsub"
+	default:
+		return "normal"
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"redis-go/internal/protocol"
+)
+
+// redisCompatibleVersion est la version de Redis annoncée aux clients (HELLO)
+// Les clients s'en servent pour activer les fonctionnalités disponibles
+const redisCompatibleVersion = "7.2.0"
+
+// processConnectionCommand gère les commandes qui modifient l'état de la connexion (HELLO, SELECT, AUTH, ACL, CLIENT)
+// Retourne false si la commande doit être traitée normalement
+func (redisServerInstance *RedisServerInstance) processConnectionCommand(connectionState *clientConnectionState, commandName string, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) (bool, error) {
+	switch strings.ToUpper(commandName) {
+	case "HELLO":
+		return true, redisServerInstance.handleHelloCommand(connectionState, commandArguments, protocolEncoder)
+	case "SELECT":
+		return true, redisServerInstance.handleSelectCommand(connectionState, commandArguments, protocolEncoder)
+	case "AUTH":
+		return true, redisServerInstance.handleAuthCommand(connectionState, commandArguments, protocolEncoder)
+	case "ACL":
+		return true, redisServerInstance.handleAclCommand(connectionState, commandArguments, protocolEncoder)
+	case "CLIENT":
+		return true, redisServerInstance.handleClientCommand(connectionState, commandArguments, protocolEncoder)
+	}
+
+	return false, nil
+}
+
+// handleHelloCommand implémente HELLO [protover [AUTH username password] [SETNAME clientname]] :
+// authentifie et nomme la connexion si demandé, négocie RESP2/RESP3 et décrit le serveur
+func (redisServerInstance *RedisServerInstance) handleHelloCommand(connectionState *clientConnectionState, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	requestedVersion := protocolEncoder.GetProtocolVersion()
+	if len(commandArguments) > 0 {
+		parsedVersion, parseError := strconv.Atoi(commandArguments[0])
+		if parseError != nil {
+			return protocolEncoder.WriteErrorResponse("ERREUR : la version du protocole doit être un entier")
+		}
+		if parsedVersion != protocol.RedisProtocolVersion2 && parsedVersion != protocol.RedisProtocolVersion3 {
+			return protocolEncoder.WriteErrorResponse("NOPROTO version du protocole non supportée")
+		}
+		requestedVersion = parsedVersion
+	}
+
+	// Les options sont validées avant de modifier l'état de la connexion
+	var authenticationArguments []string
+	var requestedClientName *string
+	for optionIndex := 1; optionIndex < len(commandArguments); optionIndex++ {
+		if strings.EqualFold(commandArguments[optionIndex], "AUTH") && optionIndex+2 < len(commandArguments) {
+			authenticationArguments = commandArguments[optionIndex+1 : optionIndex+3]
+			optionIndex += 2
+			continue
+		}
+		if strings.EqualFold(commandArguments[optionIndex], "SETNAME") && optionIndex+1 < len(commandArguments) {
+			if errorMessage := validateClientName(commandArguments[optionIndex+1]); errorMessage != "" {
+				return protocolEncoder.WriteErrorResponse(errorMessage)
+			}
+			requestedClientName = &commandArguments[optionIndex+1]
+			optionIndex++
+			continue
+		}
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : option HELLO non supportée '%s' (attendu: HELLO [protover [AUTH username password] [SETNAME clientname]])", commandArguments[optionIndex]))
+	}
+
+	if authenticationArguments != nil {
+		authenticatedUser, isAuthenticated := redisServerInstance.commandRegistry.GetAccessControlList().AuthenticateUser(authenticationArguments[0], authenticationArguments[1])
+		if !isAuthenticated {
+			return protocolEncoder.WriteErrorResponse(wrongPasswordMessage)
+		}
+		connectionState.setAuthenticatedUser(authenticatedUser)
+	}
+	if connectionState.authenticatedUser == nil {
+		return protocolEncoder.WriteErrorResponse("NOAUTH HELLO doit être appelé après AUTH, ou avec l'option AUTH <username> <password>")
+	}
+	if requestedClientName != nil {
+		connectionState.setClientName(*requestedClientName)
+	}
+	protocolEncoder.SetProtocolVersion(requestedVersion)
+
+	// La réponse est encodée avec la version qui vient d'être négociée
+	protocolEncoder.WriteMapHeader(7)
+	protocolEncoder.WriteBulkStringResponse("server")
+	protocolEncoder.WriteBulkStringResponse("redis")
+	protocolEncoder.WriteBulkStringResponse("version")
+	protocolEncoder.WriteBulkStringResponse(redisCompatibleVersion)
+	protocolEncoder.WriteBulkStringResponse("proto")
+	protocolEncoder.WriteIntegerResponse(int64(protocolEncoder.GetProtocolVersion()))
+	protocolEncoder.WriteBulkStringResponse("id")
+	protocolEncoder.WriteIntegerResponse(connectionState.clientId)
+	protocolEncoder.WriteBulkStringResponse("mode")
+	protocolEncoder.WriteBulkStringResponse("standalone")
+	protocolEncoder.WriteBulkStringResponse("role")
+	protocolEncoder.WriteBulkStringResponse(redisServerInstance.getReplicationRole())
+	protocolEncoder.WriteBulkStringResponse("modules")
+	return protocolEncoder.WriteArrayHeader(0)
+}
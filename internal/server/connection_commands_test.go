@@ -0,0 +1,104 @@
+package server
+
+import "testing"
+
+func TestHelloProtocolNegotiation(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+
+	helloResp3Prefix := "%7\r\n" + bulk("server") + bulk("redis") + bulk("version") + bulk(redisCompatibleVersion) + bulk("proto") + ":3\r\n"
+	helloResp2Prefix := "*14\r\n" + bulk("server") + bulk("redis") + bulk("version") + bulk(redisCompatibleVersion) + bulk("proto") + ":2\r\n"
+
+	testCases := []struct {
+		name      string
+		testSteps []clientTestStep
+	}{
+		{
+			name: "HELLO 3 renvoie des maps, ensembles et doubles",
+			testSteps: []clientTestStep{
+				{0, "HSET h f v", ":1\r\n"},
+				{0, "SADD s a", ":1\r\n"},
+				{0, "ZADD z 1.5 a", ":1\r\n"},
+				{0, "HELLO 3", helloResp3Prefix + "*"},
+				{0, "HGETALL h", "%1\r\n" + bulk("f") + bulk("v")},
+				{0, "SMEMBERS s", "~1\r\n" + bulk("a")},
+				{0, "ZSCORE z a", ",1.5\r\n"},
+				{0, "ZADD z INCR 1 a", ",2.5\r\n"},
+				{0, "SET k v", "+OK\r\n"},
+				{0, "SET k w NX", "_\r\n"},
+				{0, "GET absente", "_\r\n"},
+				{0, "HGET h absent", "_\r\n"},
+				{0, "LPOP absente", "_\r\n"},
+				{0, "RPOP absente", "_\r\n"},
+				{0, "HELLO", helloResp3Prefix + "*"},
+			},
+		},
+		{
+			name: "HELLO 2 revient aux types RESP2",
+			testSteps: []clientTestStep{
+				{0, "HSET h f v", ":1\r\n"},
+				{0, "HELLO 3", helloResp3Prefix + "*"},
+				{0, "HELLO 2", helloResp2Prefix + "*"},
+				{0, "HGETALL h", "*2\r\n" + bulk("f") + bulk("v")},
+				{0, "SET k v NX", "+OK\r\n"},
+				{0, "SET k w NX", "$-1\r\n"},
+				{0, "GET absente", "$-1\r\n"},
+				{0, "HGET h absent", "$-1\r\n"},
+				{0, "LPOP absente", "$-1\r\n"},
+			},
+		},
+		{
+			name: "la version est propre à chaque connexion",
+			testSteps: []clientTestStep{
+				{0, "SADD s a", ":1\r\n"},
+				{0, "HELLO 3", helloResp3Prefix + "*"},
+				{1, "SMEMBERS s", "*1\r\n" + bulk("a")},
+				{0, "SMEMBERS s", "~1\r\n" + bulk("a")},
+			},
+		},
+		{
+			name: "SETNAME et arguments invalides",
+			testSteps: []clientTestStep{
+				{0, "HELLO 3 SETNAME application", helloResp3Prefix + "*"},
+				{0, "CLIENT GETNAME", bulk("application")},
+				{0, "HELLO 4", "-NOPROTO version du protocole non supportée\r\n"},
+				{0, "HELLO trois", "-ERREUR : la version du protocole doit être un entier\r\n"},
+				{0, "HELLO 2 COMPAT", "-ERREUR : option HELLO non supportée 'COMPAT'*"},
+				{0, "HELLO 2 SETNAME", "-ERREUR : option HELLO non supportée 'SETNAME'*"},
+				// Une négociation refusée ne change pas la version
+				{0, "ZADD z 1 a", ":1\r\n"},
+				{0, "ZSCORE z a", ",1\r\n"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testClients := []*testClient{
+				dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber),
+				dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber),
+			}
+			testClients[0].execute("FLUSHALL")
+			runClientSteps(t, testClients, testCase.testSteps)
+		})
+	}
+}
+
+func TestPubSubPushMessagesInResp3(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	subscriberClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	publisherClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	subscriberClient.execute("HELLO", "3")
+	if subscribeReply := subscriberClient.execute("SUBSCRIBE", "news"); subscribeReply != ">3\r\n"+bulk("subscribe")+bulk("news")+":1\r\n" {
+		t.Fatalf("SUBSCRIBE en RESP3: %q", subscribeReply)
+	}
+	// En RESP3 une connexion abonnée peut exécuter n'importe quelle commande
+	if setReply := subscriberClient.execute("SET", "k", "v"); setReply != "+OK\r\n" {
+		t.Fatalf("SET en mode abonné RESP3: %q", setReply)
+	}
+	publisherClient.execute("PUBLISH", "news", "payload")
+	if pushedMessage := subscriberClient.readReply(); pushedMessage != ">3\r\n"+bulk("message")+bulk("news")+bulk("payload") {
+		t.Fatalf("message poussé en RESP3: %q", pushedMessage)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"fmt"
+	"strconv"
+
+	"redis-go/internal/commands"
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// selectedStorage retourne la base actuellement sélectionnée par la connexion
+func (redisServerInstance *RedisServerInstance) selectedStorage(connectionState *clientConnectionState) *storage.RedisInMemoryStorage {
+	return redisServerInstance.databaseStorages[connectionState.selectedDatabaseIndex]
+}
+
+// parseDatabaseIndex convertit un index de base et vérifie qu'il existe
+// Retourne un message d'erreur vide si l'index est valide
+func (redisServerInstance *RedisServerInstance) parseDatabaseIndex(databaseIndexArgument string) (int, string) {
+	databaseIndex, parseError := strconv.Atoi(databaseIndexArgument)
+	if parseError != nil {
+		return 0, "ERREUR : l'index de base doit être un entier"
+	}
+	if databaseIndex < 0 || databaseIndex >= len(redisServerInstance.databaseStorages) {
+		return 0, fmt.Sprintf("ERREUR : index de base hors limites (0 à %d)", len(redisServerInstance.databaseStorages)-1)
+	}
+	return databaseIndex, ""
+}
+
+// handleSelectCommand implémente SELECT index : change la base de la connexion
+func (redisServerInstance *RedisServerInstance) handleSelectCommand(connectionState *clientConnectionState, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 1 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SELECT' (attendu: SELECT index)")
+	}
+
+	databaseIndex, errorMessage := redisServerInstance.parseDatabaseIndex(commandArguments[0])
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	connectionState.setSelectedDatabase(databaseIndex)
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handleMoveCommand implémente MOVE key db : déplace une clé vers une autre base
+func (redisServerInstance *RedisServerInstance) handleMoveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	destinationIndex, errorMessage := redisServerInstance.parseDatabaseIndex(commandArguments[1])
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+	if destinationIndex == redisStorage.GetDatabaseIndex() {
+		return protocolEncoder.WriteErrorResponse("ERREUR : les bases source et destination sont identiques")
+	}
+
+	if redisStorage.MoveKeyToStorage(commandArguments[0], redisServerInstance.databaseStorages[destinationIndex]) {
+		return protocolEncoder.WriteIntegerResponse(1)
+	}
+	return protocolEncoder.WriteIntegerResponse(0)
+}
+
+// handleSwapDatabaseCommand implémente SWAPDB index1 index2 : échange le contenu de deux bases
+// Les connexions qui ont sélectionné l'une des bases voient immédiatement l'autre contenu
+func (redisServerInstance *RedisServerInstance) handleSwapDatabaseCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	firstIndex, errorMessage := redisServerInstance.parseDatabaseIndex(commandArguments[0])
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+	secondIndex, errorMessage := redisServerInstance.parseDatabaseIndex(commandArguments[1])
+	if errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	redisServerInstance.databaseStorages[firstIndex].SwapStorageContents(redisServerInstance.databaseStorages[secondIndex])
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handleFlushAllCommand implémente FLUSHALL [ASYNC|SYNC] : vide toutes les bases
+func (redisServerInstance *RedisServerInstance) handleFlushAllCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if errorMessage := commands.ValidateFlushArguments("FLUSHALL", commandArguments); errorMessage != "" {
+		return protocolEncoder.WriteErrorResponse(errorMessage)
+	}
+
+	for _, databaseStorage := range redisServerInstance.databaseStorages {
+		databaseStorage.FlushAllKeys()
+	}
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
@@ -0,0 +1,105 @@
+package server
+
+import "testing"
+
+func TestLogicalDatabases(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+
+	testCases := []struct {
+		name      string
+		testSteps []clientTestStep
+	}{
+		{
+			name: "SELECT isole les clés par connexion",
+			testSteps: []clientTestStep{
+				{0, "SET k zero", "+OK\r\n"},
+				{0, "SELECT 1", "+OK\r\n"},
+				{0, "EXISTS k", ":0\r\n"},
+				{0, "SET k un", "+OK\r\n"},
+				{0, "DBSIZE", ":1\r\n"},
+				{1, "GET k", bulk("zero")},
+				{0, "GET k", bulk("un")},
+				{0, "SELECT 15", "+OK\r\n"},
+				{0, "SELECT 16", "-ERREUR : index de base hors limites (0 à 15)\r\n"},
+				{0, "SELECT -1", "-ERREUR : index de base hors limites (0 à 15)\r\n"},
+				{0, "SELECT un", "-ERREUR : l'index de base doit être un entier\r\n"},
+				// Une sélection refusée ne change pas la base
+				{0, "DBSIZE", ":0\r\n"},
+			},
+		},
+		{
+			name: "MOVE déplace la clé avec son expiration",
+			testSteps: []clientTestStep{
+				{0, "SET k v", "+OK\r\n"},
+				{0, "PEXPIREAT k 4102444800000", ":1\r\n"},
+				{0, "MOVE k 1", ":1\r\n"},
+				{0, "EXISTS k", ":0\r\n"},
+				{1, "SELECT 1", "+OK\r\n"},
+				{1, "GET k", bulk("v")},
+				{1, "PEXPIRETIME k", ":4102444800000\r\n"},
+				{0, "SET k autre", "+OK\r\n"},
+				{0, "MOVE k 1", ":0\r\n"},
+				{0, "GET k", bulk("autre")},
+				{0, "MOVE absent 1", ":0\r\n"},
+				{0, "MOVE k 0", "-ERREUR : les bases source et destination sont identiques\r\n"},
+				{0, "MOVE k 99", "-ERREUR : index de base hors limites*"},
+			},
+		},
+		{
+			name: "SWAPDB échange le contenu vu par les connexions",
+			testSteps: []clientTestStep{
+				{0, "SET a zero", "+OK\r\n"},
+				{1, "SELECT 1", "+OK\r\n"},
+				{1, "RPUSH b un", ":1\r\n"},
+				{0, "SWAPDB 0 1", "+OK\r\n"},
+				{0, "EXISTS a", ":0\r\n"},
+				{0, "LRANGE b 0 -1", "*1\r\n" + bulk("un")},
+				{1, "GET a", bulk("zero")},
+				{0, "SWAPDB 0 16", "-ERREUR : index de base hors limites*"},
+			},
+		},
+		{
+			name: "FLUSHDB et KEYS limités à la base, FLUSHALL vide tout",
+			testSteps: []clientTestStep{
+				{0, "SET a 0", "+OK\r\n"},
+				{1, "SELECT 2", "+OK\r\n"},
+				{1, "SET b 2", "+OK\r\n"},
+				{1, "KEYS *", "*1\r\n" + bulk("b")},
+				{0, "KEYS *", "*1\r\n" + bulk("a")},
+				{1, "FLUSHDB", "+OK\r\n"},
+				{1, "DBSIZE", ":0\r\n"},
+				{0, "DBSIZE", ":1\r\n"},
+				{1, "SET b 2", "+OK\r\n"},
+				{1, "FLUSHALL", "+OK\r\n"},
+				{1, "DBSIZE", ":0\r\n"},
+				{0, "DBSIZE", ":0\r\n"},
+				{0, "FLUSHDB LAZY", "-ERREUR*"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			testClients := []*testClient{
+				dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber),
+				dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber),
+			}
+			testClients[0].execute("FLUSHALL")
+			runClientSteps(t, testClients, testCase.testSteps)
+		})
+	}
+}
+
+func TestDatabaseCountFromConfiguration(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	serverConfiguration.StorageConfiguration.DatabaseCount = 4
+	startTestServer(t, serverConfiguration)
+	databaseClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	runClientSteps(t, []*testClient{databaseClient}, []clientTestStep{
+		{0, "SELECT 3", "+OK\r\n"},
+		{0, "SELECT 4", "-ERREUR : index de base hors limites (0 à 3)\r\n"},
+		{0, "SWAPDB 0 3", "+OK\r\n"},
+		{0, "MOVE k 4", "-ERREUR : index de base hors limites (0 à 3)\r\n"},
+	})
+}
@@ -22,8 +22,11 @@ func (redisServerInstance *RedisServerInstance) startExpirationGarbageCollector(
 				log.Printf("🧹 Arrêt du garbage collector")
 				return
 			case <-garbageCollectionTicker.C:
-				// Nettoyage des clés expirées
-				cleanedKeyCount := redisServerInstance.redisStorage.CleanupExpiredKeys()
+				// Nettoyage des clés expirées de toutes les bases
+				cleanedKeyCount := 0
+				for _, databaseStorage := range redisServerInstance.databaseStorages {
+					cleanedKeyCount += databaseStorage.CleanupExpiredKeys()
+				}
 				if cleanedKeyCount > 0 {
 					log.Printf("🧹 Nettoyage: %d clés expirées supprimées", cleanedKeyCount)
 				}
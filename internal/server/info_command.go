@@ -0,0 +1,213 @@
+package server
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// serverStatistics contient les compteurs globaux du serveur exposés par INFO
+type serverStatistics struct {
+	startTime time.Time
+	// runId identifie l'exécution du serveur (change à chaque démarrage)
+	runId                    string
+	totalConnectionsReceived atomic.Int64
+	rejectedConnections      atomic.Int64
+	processedCommandCount    atomic.Int64
+	// blockedClientCount est le nombre de clients en attente dans BLPOP, BRPOP, BLMOVE ou BRPOPLPUSH
+	blockedClientCount atomic.Int64
+}
+
+// newServerStatistics crée les compteurs d'un serveur qui démarre
+func newServerStatistics() *serverStatistics {
+	return &serverStatistics{
+		startTime: time.Now(),
+		runId:     generateReplicationId(),
+	}
+}
+
+// infoSection associe un nom de section INFO à la fonction qui produit ses lignes champ:valeur
+type infoSection struct {
+	sectionName  string
+	buildSection func() []string
+}
+
+// getInfoSections retourne les sections d'INFO dans l'ordre d'affichage
+func (redisServerInstance *RedisServerInstance) getInfoSections() []infoSection {
+	return []infoSection{
+		{sectionName: "server", buildSection: redisServerInstance.buildServerInfoSection},
+		{sectionName: "clients", buildSection: redisServerInstance.buildClientsInfoSection},
+		{sectionName: "memory", buildSection: redisServerInstance.buildMemoryInfoSection},
+		{sectionName: "persistence", buildSection: redisServerInstance.buildPersistenceInfoSection},
+		{sectionName: "stats", buildSection: redisServerInstance.buildStatsInfoSection},
+		{sectionName: "replication", buildSection: redisServerInstance.buildReplicationInfoSection},
+		{sectionName: "keyspace", buildSection: redisServerInstance.buildKeyspaceInfoSection},
+	}
+}
+
+// handleInfoCommand implémente INFO [section ...] au format texte de Redis (# Section puis champ:valeur)
+// Sans argument, ou avec all/everything/default, toutes les sections sont renvoyées
+func (redisServerInstance *RedisServerInstance) handleInfoCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	requestedSections := make(map[string]bool)
+	for _, sectionArgument := range commandArguments {
+		requestedSections[strings.ToLower(sectionArgument)] = true
+	}
+	includeAllSections := len(requestedSections) == 0 || requestedSections["all"] || requestedSections["everything"] || requestedSections["default"]
+
+	var infoBuilder strings.Builder
+	for _, section := range redisServerInstance.getInfoSections() {
+		if !includeAllSections && !requestedSections[section.sectionName] {
+			continue
+		}
+		if infoBuilder.Len() > 0 {
+			infoBuilder.WriteString("\r\n")
+		}
+		fmt.Fprintf(&infoBuilder, "# %s\r\n", strings.ToUpper(section.sectionName[:1])+section.sectionName[1:])
+		for _, infoLine := range section.buildSection() {
+			infoBuilder.WriteString(infoLine)
+			infoBuilder.WriteString("\r\n")
+		}
+	}
+
+	return protocolEncoder.WriteVerbatimStringResponse("txt", infoBuilder.String())
+}
+
+// buildServerInfoSection construit la section server d'INFO (version, processus, uptime)
+func (redisServerInstance *RedisServerInstance) buildServerInfoSection() []string {
+	uptime := time.Since(redisServerInstance.statistics.startTime)
+	executablePath, _ := os.Executable()
+
+	return []string{
+		"redis_version:" + redisCompatibleVersion,
+		"redis_mode:standalone",
+		fmt.Sprintf("os:%s %s", runtime.GOOS, runtime.GOARCH),
+		fmt.Sprintf("arch_bits:%d", strconv.IntSize),
+		"go_version:" + runtime.Version(),
+		fmt.Sprintf("process_id:%d", os.Getpid()),
+		"run_id:" + redisServerInstance.statistics.runId,
+		fmt.Sprintf("tcp_port:%d", redisServerInstance.serverConfiguration.NetworkConfiguration.PortNumber),
+		fmt.Sprintf("server_time_usec:%d", time.Now().UnixMicro()),
+		fmt.Sprintf("uptime_in_seconds:%d", int64(uptime.Seconds())),
+		fmt.Sprintf("uptime_in_days:%d", int64(uptime.Hours()/24)),
+		"executable:" + executablePath,
+	}
+}
+
+// buildClientsInfoSection construit la section clients d'INFO (les connexions des réplicas ne sont pas comptées)
+func (redisServerInstance *RedisServerInstance) buildClientsInfoSection() []string {
+	redisServerInstance.clientsMutex.RLock()
+	connectedClientCount := len(redisServerInstance.connectedClients)
+	redisServerInstance.clientsMutex.RUnlock()
+
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	connectedClientCount -= len(state.connectedReplicas)
+	state.stateMutex.Unlock()
+
+	return []string{
+		fmt.Sprintf("connected_clients:%d", max(connectedClientCount, 0)),
+		fmt.Sprintf("maxclients:%d", redisServerInstance.serverConfiguration.PerformanceConfiguration.MaximumConnections),
+		fmt.Sprintf("blocked_clients:%d", redisServerInstance.statistics.blockedClientCount.Load()),
+	}
+}
+
+// buildMemoryInfoSection construit la section memory d'INFO à partir des statistiques du runtime Go
+// used_memory est la mémoire allouée sur le tas, used_memory_rss celle obtenue du système et non rendue
+func (redisServerInstance *RedisServerInstance) buildMemoryInfoSection() []string {
+	var memoryStatistics runtime.MemStats
+	runtime.ReadMemStats(&memoryStatistics)
+
+	usedMemory := memoryStatistics.HeapAlloc
+	residentMemory := memoryStatistics.Sys - memoryStatistics.HeapReleased
+	fragmentationRatio := 0.0
+	if usedMemory > 0 {
+		fragmentationRatio = float64(residentMemory) / float64(usedMemory)
+	}
+
+	return []string{
+		fmt.Sprintf("used_memory:%d", usedMemory),
+		"used_memory_human:" + formatHumanReadableBytes(usedMemory),
+		fmt.Sprintf("used_memory_rss:%d", residentMemory),
+		"used_memory_rss_human:" + formatHumanReadableBytes(residentMemory),
+		fmt.Sprintf("mem_fragmentation_ratio:%.2f", fragmentationRatio),
+		"mem_allocator:go",
+		fmt.Sprintf("gc_cycles:%d", memoryStatistics.NumGC),
+	}
+}
+
+// formatHumanReadableBytes formate une taille comme Redis (1.50K, 12.34M...)
+func formatHumanReadableBytes(byteCount uint64) string {
+	const unitSymbols = "KMGTP"
+	if byteCount < 1024 {
+		return fmt.Sprintf("%dB", byteCount)
+	}
+	scaledValue := float64(byteCount) / 1024
+	unitIndex := 0
+	for scaledValue >= 1024 && unitIndex < len(unitSymbols)-1 {
+		scaledValue /= 1024
+		unitIndex++
+	}
+	return fmt.Sprintf("%.2f%c", scaledValue, unitSymbols[unitIndex])
+}
+
+// buildPersistenceInfoSection construit la section persistence d'INFO (snapshots et AOF)
+func (redisServerInstance *RedisServerInstance) buildPersistenceInfoSection() []string {
+	snapshotManager := redisServerInstance.snapshotManager
+	lastSaveStatus := "ok"
+	if !snapshotManager.HasLastSaveSucceeded() {
+		lastSaveStatus = "err"
+	}
+
+	infoLines := []string{
+		fmt.Sprintf("rdb_changes_since_last_save:%d", snapshotManager.GetChangesSinceLastSave()),
+		fmt.Sprintf("rdb_bgsave_in_progress:%d", boolToInfoFlag(snapshotManager.IsSaveInProgress())),
+		fmt.Sprintf("rdb_last_save_time:%d", snapshotManager.GetLastSaveTime().Unix()),
+		"rdb_last_bgsave_status:" + lastSaveStatus,
+		fmt.Sprintf("aof_enabled:%d", boolToInfoFlag(redisServerInstance.appendOnlyFile != nil)),
+	}
+	if redisServerInstance.appendOnlyFile != nil {
+		infoLines = append(infoLines, fmt.Sprintf("aof_rewrite_in_progress:%d", boolToInfoFlag(redisServerInstance.appendOnlyFile.IsRewriteInProgress())))
+	}
+	return infoLines
+}
+
+// buildStatsInfoSection construit la section stats d'INFO (connexions, commandes, expirations, hits/misses)
+func (redisServerInstance *RedisServerInstance) buildStatsInfoSection() []string {
+	expiredKeyCount := int64(0)
+	for _, databaseStorage := range redisServerInstance.databaseStorages {
+		expiredKeyCount += databaseStorage.GetExpiredKeyCount()
+	}
+	keyspaceHitCount, keyspaceMissCount := redisServerInstance.commandRegistry.GetKeyspaceLookupCounts()
+
+	return []string{
+		fmt.Sprintf("total_connections_received:%d", redisServerInstance.statistics.totalConnectionsReceived.Load()),
+		fmt.Sprintf("total_commands_processed:%d", redisServerInstance.statistics.processedCommandCount.Load()),
+		fmt.Sprintf("rejected_connections:%d", redisServerInstance.statistics.rejectedConnections.Load()),
+		fmt.Sprintf("expired_keys:%d", expiredKeyCount),
+		fmt.Sprintf("keyspace_hits:%d", keyspaceHitCount),
+		fmt.Sprintf("keyspace_misses:%d", keyspaceMissCount),
+		fmt.Sprintf("pubsub_channels:%d", len(redisServerInstance.pubSubHub.getActiveChannels(""))),
+		fmt.Sprintf("pubsub_patterns:%d", redisServerInstance.pubSubHub.getPatternCount()),
+	}
+}
+
+// buildKeyspaceInfoSection construit la section keyspace d'INFO (une ligne par base non vide)
+func (redisServerInstance *RedisServerInstance) buildKeyspaceInfoSection() []string {
+	var infoLines []string
+	for databaseIndex, databaseStorage := range redisServerInstance.databaseStorages {
+		keyspaceStatistics := databaseStorage.GetKeyspaceStatistics()
+		if keyspaceStatistics.KeyCount == 0 {
+			continue
+		}
+		infoLines = append(infoLines, fmt.Sprintf("db%d:keys=%d,expires=%d,avg_ttl=%d",
+			databaseIndex, keyspaceStatistics.KeyCount, keyspaceStatistics.ExpiringKeyCount, keyspaceStatistics.AverageTimeToLive.Milliseconds()))
+	}
+	return infoLines
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// parseInfoReply découpe la réponse bulk d'INFO en titres de sections et en champs champ:valeur
+func parseInfoReply(t *testing.T, infoReply string) ([]string, map[string]string) {
+	t.Helper()
+	headerLine, infoText, headerFound := strings.Cut(infoReply, "\r\n")
+	if !headerFound || !strings.HasPrefix(headerLine, "$") {
+		t.Fatalf("INFO doit répondre une bulk string: %q", infoReply)
+	}
+	var sectionTitles []string
+	infoFields := make(map[string]string)
+	for _, infoLine := range strings.Split(strings.TrimSuffix(infoText, "\r\n"), "\r\n") {
+		if sectionTitle, isTitle := strings.CutPrefix(infoLine, "# "); isTitle {
+			sectionTitles = append(sectionTitles, sectionTitle)
+			continue
+		}
+		if fieldName, fieldValue, isField := strings.Cut(infoLine, ":"); isField {
+			infoFields[fieldName] = fieldValue
+		}
+	}
+	return sectionTitles, infoFields
+}
+
+func TestInfoSections(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	infoClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	allSections := []string{"Server", "Clients", "Memory", "Persistence", "Stats", "Replication", "Keyspace"}
+	testCases := []struct {
+		name             string
+		infoArguments    []string
+		expectedSections []string
+		expectedFields   []string
+	}{
+		{name: "toutes les sections par défaut", expectedSections: allSections, expectedFields: []string{"redis_version", "uptime_in_seconds", "connected_clients", "used_memory", "total_commands_processed", "role"}},
+		{name: "all", infoArguments: []string{"all"}, expectedSections: allSections},
+		{name: "everything", infoArguments: []string{"everything"}, expectedSections: allSections},
+		{name: "une section", infoArguments: []string{"memory"}, expectedSections: []string{"Memory"}, expectedFields: []string{"used_memory", "used_memory_human", "used_memory_rss", "mem_fragmentation_ratio"}},
+		{name: "plusieurs sections dans l'ordre d'affichage", infoArguments: []string{"STATS", "clients"}, expectedSections: []string{"Clients", "Stats"}, expectedFields: []string{"maxclients", "blocked_clients", "keyspace_hits", "expired_keys"}},
+		{name: "section inconnue", infoArguments: []string{"inconnue"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			sectionTitles, infoFields := parseInfoReply(t, infoClient.execute(append([]string{"INFO"}, testCase.infoArguments...)...))
+			if strings.Join(sectionTitles, ",") != strings.Join(testCase.expectedSections, ",") {
+				t.Fatalf("sections %v, attendu %v", sectionTitles, testCase.expectedSections)
+			}
+			for _, expectedField := range testCase.expectedFields {
+				if _, fieldFound := infoFields[expectedField]; !fieldFound {
+					t.Fatalf("champ %s absent de INFO %v", expectedField, testCase.infoArguments)
+				}
+			}
+		})
+	}
+}
+
+func TestInfoCounters(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	firstClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	secondClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	runClientSteps(t, []*testClient{firstClient, secondClient}, []clientTestStep{
+		{0, "SET present v", "+OK\r\n"},
+		{0, "PEXPIRE present 100000", ":1\r\n"},
+		{0, "GET present", bulk("v")},
+		{0, "EXISTS absent", ":0\r\n"},
+		{0, "SELECT 4", "+OK\r\n"},
+		{0, "RPUSH l a", ":1\r\n"},
+		{1, "SUBSCRIBE canal", "*3\r\n*"},
+	})
+
+	_, infoFields := parseInfoReply(t, firstClient.execute("INFO"))
+	testCases := []struct {
+		fieldName     string
+		expectedValue string
+	}{
+		{fieldName: "connected_clients", expectedValue: "2"},
+		{fieldName: "keyspace_hits", expectedValue: "1"},
+		{fieldName: "keyspace_misses", expectedValue: "1"},
+		{fieldName: "total_commands_processed", expectedValue: "8"},
+		{fieldName: "pubsub_channels", expectedValue: "1"},
+		{fieldName: "db0", expectedValue: "keys=1,expires=1,avg_ttl=*"},
+		{fieldName: "db4", expectedValue: "keys=1,expires=0,avg_ttl=0"},
+		{fieldName: "tcp_port", expectedValue: strconv.Itoa(serverConfiguration.NetworkConfiguration.PortNumber)},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.fieldName, func(t *testing.T) {
+			expectedPrefix, isPrefix := strings.CutSuffix(testCase.expectedValue, "*")
+			actualValue := infoFields[testCase.fieldName]
+			if actualValue != testCase.expectedValue && !(isPrefix && strings.HasPrefix(actualValue, expectedPrefix)) {
+				t.Fatalf("%s:%s, attendu %s", testCase.fieldName, actualValue, testCase.expectedValue)
+			}
+		})
+	}
+}
+
+func TestFormatHumanReadableBytes(t *testing.T) {
+	testCases := []struct {
+		name           string
+		byteCount      uint64
+		expectedFormat string
+	}{
+		{name: "octets", byteCount: 1023, expectedFormat: "1023B"},
+		{name: "kilo-octets", byteCount: 1536, expectedFormat: "1.50K"},
+		{name: "méga-octets", byteCount: 12 * 1024 * 1024, expectedFormat: "12.00M"},
+		{name: "giga-octets", byteCount: 3 * 1024 * 1024 * 1024, expectedFormat: "3.00G"},
+		{name: "au-delà de la dernière unité", byteCount: 2048 * 1024 * 1024 * 1024 * 1024 * 1024, expectedFormat: "2048.00P"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if formattedSize := formatHumanReadableBytes(testCase.byteCount); formattedSize != testCase.expectedFormat {
+				t.Fatalf("%d octets formatés %q, attendu %q", testCase.byteCount, formattedSize, testCase.expectedFormat)
+			}
+		})
+	}
+}
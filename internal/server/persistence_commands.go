@@ -0,0 +1,64 @@
+package server
+
+import (
+	"fmt"
+
+	"redis-go/internal/persistence"
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// handleSaveCommand implémente SAVE
+func (redisServerInstance *RedisServerInstance) handleSaveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : SAVE ne prend aucun argument")
+	}
+
+	if saveError := redisServerInstance.snapshotManager.SaveSnapshot(); saveError != nil {
+		if saveError == persistence.ErrBackgroundSaveInProgress {
+			return protocolEncoder.WriteErrorResponse("ERREUR : une sauvegarde en arrière-plan est déjà en cours")
+		}
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : sauvegarde impossible: %v", saveError))
+	}
+
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handleBackgroundSaveCommand implémente BGSAVE
+func (redisServerInstance *RedisServerInstance) handleBackgroundSaveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) > 1 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'BGSAVE' (attendu: BGSAVE [SCHEDULE])")
+	}
+
+	if saveError := redisServerInstance.startBackgroundSnapshot(); saveError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : une sauvegarde en arrière-plan est déjà en cours")
+	}
+
+	return protocolEncoder.WriteSimpleStringResponse("Background saving started")
+}
+
+// handleLastSaveCommand implémente LASTSAVE
+func (redisServerInstance *RedisServerInstance) handleLastSaveCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : LASTSAVE ne prend aucun argument")
+	}
+
+	return protocolEncoder.WriteIntegerResponse(redisServerInstance.snapshotManager.GetLastSaveTime().Unix())
+}
+
+// handleBackgroundRewriteAppendOnlyCommand implémente BGREWRITEAOF
+func (redisServerInstance *RedisServerInstance) handleBackgroundRewriteAppendOnlyCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : BGREWRITEAOF ne prend aucun argument")
+	}
+
+	if redisServerInstance.appendOnlyFile == nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'AOF n'est pas activé (REDIS_APPEND_ONLY=yes)")
+	}
+
+	if rewriteError := redisServerInstance.startBackgroundAppendOnlyRewrite(); rewriteError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : une réécriture de l'AOF est déjà en cours")
+	}
+
+	return protocolEncoder.WriteSimpleStringResponse("Background append only file rewriting started")
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"redis-go/internal/persistence"
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// loadPersistedDataset recharge l'AOF s'il est activé et présent, sinon le dernier snapshot
+func (redisServerInstance *RedisServerInstance) loadPersistedDataset() error {
+	persistenceConfiguration := redisServerInstance.serverConfiguration.PersistenceConfiguration
+	defer redisServerInstance.snapshotManager.ResetChangesSinceLastSave()
+
+	if persistenceConfiguration.AppendOnlyEnabled {
+		loadResult, loadError := persistence.LoadAppendOnlyFile(
+			persistenceConfiguration.AppendOnlyFilePath,
+			persistenceConfiguration.AppendOnlyLoadTruncated,
+			redisServerInstance.databaseStorages,
+			redisServerInstance.newAppendOnlyCommandReplayer())
+		if loadError != nil {
+			return loadError
+		}
+
+		if loadResult.FileExists {
+			log.Printf("📜 AOF chargé: %d clés (préambule) + %d commandes rejouées depuis %s",
+				loadResult.PreambleKeyCount, loadResult.ReplayedCommandCount, persistenceConfiguration.AppendOnlyFilePath)
+			return redisServerInstance.openAppendOnlyFile(false)
+		}
+	}
+
+	loadedKeyCount, loadError := redisServerInstance.snapshotManager.LoadSnapshotFromDisk()
+	if loadError != nil {
+		return loadError
+	}
+	if loadedKeyCount > 0 {
+		log.Printf("💾 Snapshot chargé: %d clés depuis %s", loadedKeyCount, persistenceConfiguration.SnapshotFilePath)
+	}
+
+	if persistenceConfiguration.AppendOnlyEnabled {
+		// Premier démarrage avec AOF : le dataset issu du snapshot doit figurer dans l'AOF
+		return redisServerInstance.openAppendOnlyFile(loadedKeyCount > 0)
+	}
+	return nil
+}
+
+// newAppendOnlyCommandReplayer retourne la fonction qui rejoue les commandes de l'AOF (les réponses sont ignorées)
+// Les SELECT du fichier changent la base ciblée par les commandes suivantes
+func (redisServerInstance *RedisServerInstance) newAppendOnlyCommandReplayer() persistence.AppendOnlyCommandExecutor {
+	discardEncoder := protocol.NewRedisSerializationProtocolEncoder(io.Discard)
+	replayedDatabaseIndex := 0
+
+	return func(commandName string, commandArguments []string) error {
+		if strings.EqualFold(commandName, "SELECT") {
+			if len(commandArguments) != 1 {
+				return fmt.Errorf("SELECT invalide dans l'AOF")
+			}
+			databaseIndex, errorMessage := redisServerInstance.parseDatabaseIndex(commandArguments[0])
+			if errorMessage != "" {
+				return fmt.Errorf("%s", errorMessage)
+			}
+			replayedDatabaseIndex = databaseIndex
+			return nil
+		}
+		return redisServerInstance.commandRegistry.ExecuteCommand(nil, commandName, commandArguments, redisServerInstance.databaseStorages[replayedDatabaseIndex], discardEncoder)
+	}
+}
+
+// openAppendOnlyFile ouvre l'AOF et y branche la propagation des commandes d'écriture
+func (redisServerInstance *RedisServerInstance) openAppendOnlyFile(writeInitialDataset bool) error {
+	persistenceConfiguration := redisServerInstance.serverConfiguration.PersistenceConfiguration
+
+	appendOnlyFile, openError := persistence.OpenAppendOnlyFile(persistenceConfiguration.AppendOnlyFilePath, persistenceConfiguration.AppendFsyncPolicy)
+	if openError != nil {
+		return openError
+	}
+	redisServerInstance.appendOnlyFile = appendOnlyFile
+
+	if writeInitialDataset {
+		if rewriteError := appendOnlyFile.BeginRewrite(); rewriteError != nil {
+			return rewriteError
+		}
+		if rewriteError := appendOnlyFile.FinishRewrite(redisServerInstance.cloneDatasetForPersistence()); rewriteError != nil {
+			return rewriteError
+		}
+	}
+
+	redisServerInstance.commandRegistry.AddWriteCommandListener(func(databaseIndex int, commandName string, commandArguments []string) {
+		if appendError := appendOnlyFile.AppendCommand(databaseIndex, commandName, commandArguments); appendError != nil {
+			log.Printf("❌ %v", appendError)
+		}
+	})
+
+	log.Printf("📜 AOF activé (%s, fsync: %s)", persistenceConfiguration.AppendOnlyFilePath, persistenceConfiguration.AppendFsyncPolicy)
+	return nil
+}
+
+// cloneDatasetForPersistence copie le dataset pour une écriture sur disque hors verrou
+func (redisServerInstance *RedisServerInstance) cloneDatasetForPersistence() []map[string]*storage.RedisStorageValue {
+	return persistence.CloneAllDatabases(redisServerInstance.databaseStorages)
+}
+
+// startBackgroundAppendOnlyRewrite compacte l'AOF à partir du dataset courant (BGREWRITEAOF)
+// Appelé depuis une commande (verrou d'exécution partagé détenu) : la copie exclusive
+// du dataset est donc faite dans une goroutine, une fois la commande terminée
+func (redisServerInstance *RedisServerInstance) startBackgroundAppendOnlyRewrite() error {
+	if redisServerInstance.appendOnlyFile.IsRewriteInProgress() {
+		return persistence.ErrAppendOnlyRewriteInProgress
+	}
+
+	go func() {
+		var clonedDataset []map[string]*storage.RedisStorageValue
+		var beginError error
+
+		// Copie et début de mise en tampon au même instant : aucune commande n'est perdue ni dupliquée
+		redisServerInstance.commandRegistry.ExecuteWithExclusiveAccess(func() {
+			if beginError = redisServerInstance.appendOnlyFile.BeginRewrite(); beginError == nil {
+				clonedDataset = redisServerInstance.cloneDatasetForPersistence()
+			}
+		})
+		if beginError != nil {
+			log.Printf("⚠️  Réécriture de l'AOF ignorée: %v", beginError)
+			return
+		}
+
+		if rewriteError := redisServerInstance.appendOnlyFile.FinishRewrite(clonedDataset); rewriteError != nil {
+			log.Printf("❌ Échec de la réécriture de l'AOF: %v", rewriteError)
+			return
+		}
+		log.Printf("📜 Réécriture de l'AOF terminée")
+	}()
+
+	return nil
+}
+
+// startAppendOnlyFsyncScheduler déclenche le fsync de l'AOF chaque seconde (politique everysec)
+func (redisServerInstance *RedisServerInstance) startAppendOnlyFsyncScheduler() {
+	if redisServerInstance.appendOnlyFile == nil {
+		return
+	}
+
+	redisServerInstance.activeGoroutines.Add(1)
+	go func() {
+		defer redisServerInstance.activeGoroutines.Done()
+
+		fsyncTicker := time.NewTicker(time.Second)
+		defer fsyncTicker.Stop()
+
+		for {
+			select {
+			case <-redisServerInstance.shutdownSignal:
+				return
+			case <-fsyncTicker.C:
+				if fsyncError := redisServerInstance.appendOnlyFile.FsyncIfPending(); fsyncError != nil {
+					log.Printf("❌ fsync de l'AOF impossible: %v", fsyncError)
+				}
+			}
+		}
+	}()
+}
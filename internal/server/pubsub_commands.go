@@ -0,0 +1,163 @@
+package server
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// subscribedModeAllowedCommands liste les commandes acceptées quand la connexion est abonnée
+var subscribedModeAllowedCommands = map[string]bool{
+	"SUBSCRIBE": true, "UNSUBSCRIBE": true, "PSUBSCRIBE": true, "PUNSUBSCRIBE": true,
+	"PING": true, "QUIT": true,
+}
+
+// processPubSubCommand gère les commandes pub/sub propres à la connexion
+// Retourne false si la commande doit être traitée normalement
+func (redisServerInstance *RedisServerInstance) processPubSubCommand(subscriber *pubSubSubscriber, commandName string, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) (bool, error) {
+	upperCommandName := strings.ToUpper(commandName)
+
+	// En RESP3 les messages poussés sont distincts des réponses : toutes les commandes restent autorisées
+	if subscriber.isInSubscribedMode() && !protocolEncoder.IsResp3() && !subscribedModeAllowedCommands[upperCommandName] {
+		return true, protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : commande '%s' interdite en mode abonné, seules (P)SUBSCRIBE / (P)UNSUBSCRIBE / PING / QUIT sont autorisées", commandName))
+	}
+
+	switch upperCommandName {
+	case "SUBSCRIBE":
+		if len(commandArguments) == 0 {
+			return true, protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'SUBSCRIBE' (attendu: SUBSCRIBE channel [channel ...])")
+		}
+		for _, channelName := range commandArguments {
+			subscriptionCount := redisServerInstance.pubSubHub.subscribeChannel(subscriber, channelName)
+			writeSubscriptionReply(protocolEncoder, "subscribe", &channelName, subscriptionCount)
+		}
+		return true, nil
+
+	case "PSUBSCRIBE":
+		if len(commandArguments) == 0 {
+			return true, protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'PSUBSCRIBE' (attendu: PSUBSCRIBE pattern [pattern ...])")
+		}
+		for _, patternName := range commandArguments {
+			subscriptionCount := redisServerInstance.pubSubHub.subscribePattern(subscriber, patternName)
+			writeSubscriptionReply(protocolEncoder, "psubscribe", &patternName, subscriptionCount)
+		}
+		return true, nil
+
+	case "UNSUBSCRIBE":
+		channelNames := commandArguments
+		if len(channelNames) == 0 {
+			channelNames = sortedSubscriptionNames(subscriber.subscribedChannels)
+		}
+		return true, redisServerInstance.unsubscribeFromAll(subscriber, "unsubscribe", channelNames, redisServerInstance.pubSubHub.unsubscribeChannel, protocolEncoder)
+
+	case "PUNSUBSCRIBE":
+		patternNames := commandArguments
+		if len(patternNames) == 0 {
+			patternNames = sortedSubscriptionNames(subscriber.subscribedPatterns)
+		}
+		return true, redisServerInstance.unsubscribeFromAll(subscriber, "punsubscribe", patternNames, redisServerInstance.pubSubHub.unsubscribePattern, protocolEncoder)
+
+	case "PING":
+		// En mode abonné, PING répond sous forme de message pour ne pas être confondu avec un push
+		if !subscriber.isInSubscribedMode() || protocolEncoder.IsResp3() {
+			return false, nil
+		}
+		pingMessage := ""
+		if len(commandArguments) > 0 {
+			pingMessage = commandArguments[0]
+		}
+		return true, protocolEncoder.WriteArrayResponse([]string{"pong", pingMessage})
+	}
+
+	return false, nil
+}
+
+// unsubscribeFromAll désabonne des channels ou patterns et répond une confirmation par désabonnement
+func (redisServerInstance *RedisServerInstance) unsubscribeFromAll(subscriber *pubSubSubscriber, replyKind string, subscriptionNames []string, unsubscribe func(*pubSubSubscriber, string) int, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	// Sans abonnement, Redis confirme tout de même avec un nom null
+	if len(subscriptionNames) == 0 {
+		return writeSubscriptionReply(protocolEncoder, replyKind, nil, subscriber.getSubscriptionCount())
+	}
+
+	for _, subscriptionName := range subscriptionNames {
+		subscriptionCount := unsubscribe(subscriber, subscriptionName)
+		if writeError := writeSubscriptionReply(protocolEncoder, replyKind, &subscriptionName, subscriptionCount); writeError != nil {
+			return writeError
+		}
+	}
+	return nil
+}
+
+// writeSubscriptionReply écrit une confirmation [type, nom, nombre d'abonnements] (push en RESP3)
+func writeSubscriptionReply(protocolEncoder *protocol.RedisSerializationProtocolEncoder, replyKind string, subscriptionName *string, subscriptionCount int) error {
+	protocolEncoder.WritePushHeader(3)
+	protocolEncoder.WriteBulkStringResponse(replyKind)
+	if subscriptionName == nil {
+		protocolEncoder.WriteNullBulkStringResponse()
+	} else {
+		protocolEncoder.WriteBulkStringResponse(*subscriptionName)
+	}
+	return protocolEncoder.WriteIntegerResponse(int64(subscriptionCount))
+}
+
+// sortedSubscriptionNames retourne les noms d'abonnements dans un ordre stable
+func sortedSubscriptionNames(subscriptions map[string]bool) []string {
+	subscriptionNames := make([]string, 0, len(subscriptions))
+	for subscriptionName := range subscriptions {
+		subscriptionNames = append(subscriptionNames, subscriptionName)
+	}
+	sort.Strings(subscriptionNames)
+	return subscriptionNames
+}
+
+// handlePublishCommand implémente PUBLISH channel message
+func (redisServerInstance *RedisServerInstance) handlePublishCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) != 2 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'PUBLISH' (attendu: PUBLISH channel message)")
+	}
+
+	receiverCount := redisServerInstance.pubSubHub.publishMessage(commandArguments[0], commandArguments[1])
+	return protocolEncoder.WriteIntegerResponse(int64(receiverCount))
+}
+
+// handlePubSubCommand implémente PUBSUB CHANNELS [pattern] | NUMSUB [channel ...] | NUMPAT
+func (redisServerInstance *RedisServerInstance) handlePubSubCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) == 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'PUBSUB' (attendu: PUBSUB CHANNELS|NUMSUB|NUMPAT)")
+	}
+
+	switch strings.ToUpper(commandArguments[0]) {
+	case "CHANNELS":
+		if len(commandArguments) > 2 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'PUBSUB CHANNELS' (attendu: PUBSUB CHANNELS [pattern])")
+		}
+		channelPattern := ""
+		if len(commandArguments) == 2 {
+			channelPattern = commandArguments[1]
+		}
+		return protocolEncoder.WriteArrayResponse(redisServerInstance.pubSubHub.getActiveChannels(channelPattern))
+
+	case "NUMSUB":
+		channelNames := commandArguments[1:]
+		protocolEncoder.WriteArrayHeader(len(channelNames) * 2)
+		for _, channelName := range channelNames {
+			protocolEncoder.WriteBulkStringResponse(channelName)
+			if writeError := protocolEncoder.WriteIntegerResponse(int64(redisServerInstance.pubSubHub.getChannelSubscriberCount(channelName))); writeError != nil {
+				return writeError
+			}
+		}
+		return nil
+
+	case "NUMPAT":
+		if len(commandArguments) != 1 {
+			return protocolEncoder.WriteErrorResponse("ERREUR : PUBSUB NUMPAT ne prend aucun argument")
+		}
+		return protocolEncoder.WriteIntegerResponse(int64(redisServerInstance.pubSubHub.getPatternCount()))
+
+	default:
+		return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : sous-commande inconnue '%s' pour PUBSUB (attendu: CHANNELS, NUMSUB, NUMPAT)", commandArguments[0]))
+	}
+}
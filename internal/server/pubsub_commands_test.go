@@ -0,0 +1,134 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// subscriptionReply retourne l'encodage RESP2 d'une confirmation [type, nom, nombre d'abonnements]
+func subscriptionReply(replyKind string, subscriptionName string, subscriptionCount string) string {
+	return encodedArray(bulk(replyKind), bulk(subscriptionName), ":"+subscriptionCount+"\r\n")
+}
+
+// subscribeTestClient envoie une commande d'abonnement et vérifie une confirmation par channel ou pattern
+func subscribeTestClient(t *testing.T, subscriberClient *testClient, commandLine string, initialCount int) {
+	t.Helper()
+	commandFields := strings.Fields(commandLine)
+	replyKind := strings.ToLower(commandFields[0])
+	for subscriptionIndex, subscriptionName := range commandFields[1:] {
+		var actualReply string
+		if subscriptionIndex == 0 {
+			actualReply = subscriberClient.execute(commandFields...)
+		} else {
+			actualReply = subscriberClient.readReply()
+		}
+		expectedReply := subscriptionReply(replyKind, subscriptionName, strconv.Itoa(initialCount+subscriptionIndex+1))
+		if actualReply != expectedReply {
+			t.Fatalf("%s: confirmation %q, attendu %q", commandLine, actualReply, expectedReply)
+		}
+	}
+}
+
+func TestPubSubMessageDelivery(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+
+	testCases := []struct {
+		name              string
+		subscribeCommands []string
+		publishedChannel  string
+		expectedMessages  []string
+	}{
+		{
+			name:              "channel exact",
+			subscribeCommands: []string{"SUBSCRIBE news"},
+			publishedChannel:  "news",
+			expectedMessages:  []string{encodedArray(bulk("message"), bulk("news"), bulk("payload"))},
+		},
+		{
+			name:              "pattern glob",
+			subscribeCommands: []string{"PSUBSCRIBE n*s"},
+			publishedChannel:  "news",
+			expectedMessages:  []string{encodedArray(bulk("pmessage"), bulk("n*s"), bulk("news"), bulk("payload"))},
+		},
+		{
+			name:              "channel et pattern reçoivent chacun le message",
+			subscribeCommands: []string{"SUBSCRIBE news", "PSUBSCRIBE ne?s"},
+			publishedChannel:  "news",
+			expectedMessages: []string{
+				encodedArray(bulk("message"), bulk("news"), bulk("payload")),
+				encodedArray(bulk("pmessage"), bulk("ne?s"), bulk("news"), bulk("payload")),
+			},
+		},
+		{
+			name:              "pattern avec classe de caractères non satisfaite",
+			subscribeCommands: []string{"PSUBSCRIBE h[ae]llo", "SUBSCRIBE other"},
+			publishedChannel:  "hillo",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			subscriberClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			publisherClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			subscriptionCount := 0
+			for _, subscribeCommand := range testCase.subscribeCommands {
+				subscribeTestClient(t, subscriberClient, subscribeCommand, subscriptionCount)
+				subscriptionCount += len(strings.Fields(subscribeCommand)) - 1
+			}
+
+			expectedReceivers := ":" + strconv.Itoa(len(testCase.expectedMessages)) + "\r\n"
+			if receiverCount := publisherClient.execute("PUBLISH", testCase.publishedChannel, "payload"); receiverCount != expectedReceivers {
+				t.Fatalf("PUBLISH: %q destinataires, attendu %q", receiverCount, expectedReceivers)
+			}
+			receivedMessages := make(map[string]bool)
+			for range testCase.expectedMessages {
+				receivedMessages[subscriberClient.readReply()] = true
+			}
+			for _, expectedMessage := range testCase.expectedMessages {
+				if !receivedMessages[expectedMessage] {
+					t.Fatalf("message %q non reçu (reçus: %v)", expectedMessage, receivedMessages)
+				}
+			}
+			// En mode abonné PING répond [pong, message] ; aucun autre message ne doit le précéder
+			if pingReply := subscriberClient.execute("PING"); pingReply != encodedArray(bulk("pong"), bulk("")) {
+				t.Fatalf("PING en mode abonné: %q", pingReply)
+			}
+		})
+	}
+}
+
+func TestPubSubSubscribedModeAndIntrospection(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	subscriberClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	observerClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	subscribeTestClient(t, subscriberClient, "SUBSCRIBE b a", 0)
+	subscribeTestClient(t, subscriberClient, "PSUBSCRIBE x*", 2)
+	runClientSteps(t, []*testClient{subscriberClient, observerClient}, []clientTestStep{
+		{0, "GET k", "-ERREUR : commande 'GET' interdite en mode abonné*"},
+		{1, "PUBSUB CHANNELS", encodedArray(bulk("a"), bulk("b"))},
+		{1, "PUBSUB CHANNELS a*", encodedArray(bulk("a"))},
+		{1, "PUBSUB NUMSUB a c", encodedArray(bulk("a"), ":1\r\n", bulk("c"), ":0\r\n")},
+		{1, "PUBSUB NUMPAT", ":1\r\n"},
+		{1, "PUBSUB HELP", "-ERREUR : sous-commande inconnue*"},
+		{0, "PUNSUBSCRIBE", subscriptionReply("punsubscribe", "x*", "2")},
+		{1, "PUBSUB NUMPAT", ":0\r\n"},
+	})
+
+	// UNSUBSCRIBE sans argument confirme chaque channel dans l'ordre alphabétique puis quitte le mode abonné
+	if unsubscribeReply := subscriberClient.execute("UNSUBSCRIBE"); unsubscribeReply != subscriptionReply("unsubscribe", "a", "1") {
+		t.Fatalf("UNSUBSCRIBE: %q", unsubscribeReply)
+	}
+	if unsubscribeReply := subscriberClient.readReply(); unsubscribeReply != subscriptionReply("unsubscribe", "b", "0") {
+		t.Fatalf("UNSUBSCRIBE: %q", unsubscribeReply)
+	}
+	runClientSteps(t, []*testClient{subscriberClient, observerClient}, []clientTestStep{
+		{0, "UNSUBSCRIBE", encodedArray(bulk("unsubscribe"), "$-1\r\n", ":0\r\n")},
+		{0, "PING", "+PONG\r\n"},
+		{0, "SET k v", "+OK\r\n"},
+		{1, "PUBSUB CHANNELS", "*0\r\n"},
+		{1, "PUBLISH a personne", ":0\r\n"},
+	})
+}
@@ -0,0 +1,278 @@
+package server
+
+import (
+	"bufio"
+	"log"
+	"net"
+	"sort"
+	"sync"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// maximumPendingPubSubMessages limite les messages en attente d'envoi par abonné
+// Un abonné trop lent est déconnecté plutôt que de bloquer les publications
+const maximumPendingPubSubMessages = 4096
+
+// pubSubMessage est un message à pousser vers un abonné
+type pubSubMessage struct {
+	patternName    string // vide pour un message reçu via SUBSCRIBE
+	channelName    string
+	messagePayload string
+}
+
+// pubSubSubscriber représente une connexion abonnée à des channels ou des patterns
+type pubSubSubscriber struct {
+	rawConnection net.Conn
+	// Les abonnements ne sont modifiés que par la goroutine de la connexion
+	subscribedChannels map[string]bool
+	subscribedPatterns map[string]bool
+	// Les réponses aux commandes et les messages poussés partagent le même writer
+	responseMutex   *sync.Mutex
+	responseWriter  *bufio.Writer
+	protocolEncoder *protocol.RedisSerializationProtocolEncoder
+	pendingMessages chan pubSubMessage
+	deliveryStarted sync.Once
+	stopDelivery    chan struct{}
+}
+
+// newPubSubSubscriber crée l'abonné associé à une connexion
+func newPubSubSubscriber(clientConnection net.Conn, responseMutex *sync.Mutex, responseWriter *bufio.Writer, protocolEncoder *protocol.RedisSerializationProtocolEncoder) *pubSubSubscriber {
+	return &pubSubSubscriber{
+		rawConnection:      clientConnection,
+		subscribedChannels: make(map[string]bool),
+		subscribedPatterns: make(map[string]bool),
+		responseMutex:      responseMutex,
+		responseWriter:     responseWriter,
+		protocolEncoder:    protocolEncoder,
+		pendingMessages:    make(chan pubSubMessage, maximumPendingPubSubMessages),
+		stopDelivery:       make(chan struct{}),
+	}
+}
+
+// getSubscriptionCount retourne le nombre total d'abonnements (channels + patterns)
+func (subscriber *pubSubSubscriber) getSubscriptionCount() int {
+	return len(subscriber.subscribedChannels) + len(subscriber.subscribedPatterns)
+}
+
+// isInSubscribedMode indique si la connexion n'accepte plus que les commandes pub/sub
+func (subscriber *pubSubSubscriber) isInSubscribedMode() bool {
+	return subscriber.getSubscriptionCount() > 0
+}
+
+// startMessageDelivery démarre (une seule fois) la goroutine qui pousse les messages au client
+func (subscriber *pubSubSubscriber) startMessageDelivery() {
+	subscriber.deliveryStarted.Do(func() {
+		go subscriber.deliverPendingMessages()
+	})
+}
+
+// stopMessageDelivery arrête la goroutine d'envoi (fermeture de la connexion)
+func (subscriber *pubSubSubscriber) stopMessageDelivery() {
+	close(subscriber.stopDelivery)
+}
+
+// deliverPendingMessages écrit les messages publiés sur la connexion, de manière asynchrone
+func (subscriber *pubSubSubscriber) deliverPendingMessages() {
+	for {
+		select {
+		case <-subscriber.stopDelivery:
+			return
+		case pendingMessage := <-subscriber.pendingMessages:
+			subscriber.responseMutex.Lock()
+			writePubSubMessage(subscriber.protocolEncoder, pendingMessage)
+			// Regrouper les messages déjà en attente dans un seul envoi
+			for drained := false; !drained; {
+				select {
+				case nextMessage := <-subscriber.pendingMessages:
+					writePubSubMessage(subscriber.protocolEncoder, nextMessage)
+				default:
+					drained = true
+				}
+			}
+			flushError := subscriber.responseWriter.Flush()
+			subscriber.responseMutex.Unlock()
+
+			if flushError != nil {
+				return
+			}
+		}
+	}
+}
+
+// enqueueMessage ajoute un message sans bloquer l'éditeur, retourne false si l'abonné est saturé
+func (subscriber *pubSubSubscriber) enqueueMessage(publishedMessage pubSubMessage) bool {
+	select {
+	case subscriber.pendingMessages <- publishedMessage:
+		return true
+	default:
+		return false
+	}
+}
+
+// writePubSubMessage encode un message (message channel payload) ou (pmessage pattern channel payload)
+// En RESP3 le message est un push (>), distinct des réponses aux commandes
+func writePubSubMessage(protocolEncoder *protocol.RedisSerializationProtocolEncoder, publishedMessage pubSubMessage) {
+	if publishedMessage.patternName == "" {
+		protocolEncoder.WritePushResponse([]string{"message", publishedMessage.channelName, publishedMessage.messagePayload})
+		return
+	}
+	protocolEncoder.WritePushResponse([]string{"pmessage", publishedMessage.patternName, publishedMessage.channelName, publishedMessage.messagePayload})
+}
+
+// pubSubHub route les messages publiés vers les abonnés des channels et patterns
+type pubSubHub struct {
+	hubMutex           sync.RWMutex
+	channelSubscribers map[string]map[*pubSubSubscriber]bool
+	patternSubscribers map[string]map[*pubSubSubscriber]bool
+}
+
+// newPubSubHub crée un hub pub/sub vide
+func newPubSubHub() *pubSubHub {
+	return &pubSubHub{
+		channelSubscribers: make(map[string]map[*pubSubSubscriber]bool),
+		patternSubscribers: make(map[string]map[*pubSubSubscriber]bool),
+	}
+}
+
+// subscribeChannel abonne une connexion à un channel, retourne son nombre total d'abonnements
+func (hub *pubSubHub) subscribeChannel(subscriber *pubSubSubscriber, channelName string) int {
+	hub.hubMutex.Lock()
+	defer hub.hubMutex.Unlock()
+
+	addSubscription(hub.channelSubscribers, channelName, subscriber)
+	subscriber.subscribedChannels[channelName] = true
+	subscriber.startMessageDelivery()
+	return subscriber.getSubscriptionCount()
+}
+
+// unsubscribeChannel désabonne une connexion d'un channel, retourne son nombre total d'abonnements
+func (hub *pubSubHub) unsubscribeChannel(subscriber *pubSubSubscriber, channelName string) int {
+	hub.hubMutex.Lock()
+	defer hub.hubMutex.Unlock()
+
+	removeSubscription(hub.channelSubscribers, channelName, subscriber)
+	delete(subscriber.subscribedChannels, channelName)
+	return subscriber.getSubscriptionCount()
+}
+
+// subscribePattern abonne une connexion à un pattern glob, retourne son nombre total d'abonnements
+func (hub *pubSubHub) subscribePattern(subscriber *pubSubSubscriber, patternName string) int {
+	hub.hubMutex.Lock()
+	defer hub.hubMutex.Unlock()
+
+	addSubscription(hub.patternSubscribers, patternName, subscriber)
+	subscriber.subscribedPatterns[patternName] = true
+	subscriber.startMessageDelivery()
+	return subscriber.getSubscriptionCount()
+}
+
+// unsubscribePattern désabonne une connexion d'un pattern, retourne son nombre total d'abonnements
+func (hub *pubSubHub) unsubscribePattern(subscriber *pubSubSubscriber, patternName string) int {
+	hub.hubMutex.Lock()
+	defer hub.hubMutex.Unlock()
+
+	removeSubscription(hub.patternSubscribers, patternName, subscriber)
+	delete(subscriber.subscribedPatterns, patternName)
+	return subscriber.getSubscriptionCount()
+}
+
+// removeSubscriber supprime tous les abonnements d'une connexion fermée
+func (hub *pubSubHub) removeSubscriber(subscriber *pubSubSubscriber) {
+	hub.hubMutex.Lock()
+	defer hub.hubMutex.Unlock()
+
+	for channelName := range subscriber.subscribedChannels {
+		removeSubscription(hub.channelSubscribers, channelName, subscriber)
+	}
+	for patternName := range subscriber.subscribedPatterns {
+		removeSubscription(hub.patternSubscribers, patternName, subscriber)
+	}
+	subscriber.subscribedChannels = make(map[string]bool)
+	subscriber.subscribedPatterns = make(map[string]bool)
+}
+
+// publishMessage envoie un message aux abonnés du channel et des patterns correspondants
+// Retourne le nombre de destinataires
+func (hub *pubSubHub) publishMessage(channelName string, messagePayload string) int {
+	hub.hubMutex.RLock()
+	defer hub.hubMutex.RUnlock()
+
+	receiverCount := 0
+	for subscriber := range hub.channelSubscribers[channelName] {
+		hub.deliverToSubscriber(subscriber, pubSubMessage{channelName: channelName, messagePayload: messagePayload})
+		receiverCount++
+	}
+
+	for patternName, patternSubscribers := range hub.patternSubscribers {
+		if !storage.MatchesGlobPattern(patternName, channelName) {
+			continue
+		}
+		for subscriber := range patternSubscribers {
+			hub.deliverToSubscriber(subscriber, pubSubMessage{patternName: patternName, channelName: channelName, messagePayload: messagePayload})
+			receiverCount++
+		}
+	}
+
+	return receiverCount
+}
+
+// deliverToSubscriber met un message en file, un abonné saturé est déconnecté
+func (hub *pubSubHub) deliverToSubscriber(subscriber *pubSubSubscriber, publishedMessage pubSubMessage) {
+	if !subscriber.enqueueMessage(publishedMessage) {
+		log.Printf("🚫 Abonné %s trop lent (%d messages en attente), déconnexion", subscriber.rawConnection.RemoteAddr(), maximumPendingPubSubMessages)
+		subscriber.rawConnection.Close()
+	}
+}
+
+// getActiveChannels retourne les channels ayant au moins un abonné (filtrés par pattern si fourni)
+func (hub *pubSubHub) getActiveChannels(channelPattern string) []string {
+	hub.hubMutex.RLock()
+	defer hub.hubMutex.RUnlock()
+
+	activeChannels := make([]string, 0, len(hub.channelSubscribers))
+	for channelName := range hub.channelSubscribers {
+		if channelPattern == "" || storage.MatchesGlobPattern(channelPattern, channelName) {
+			activeChannels = append(activeChannels, channelName)
+		}
+	}
+	sort.Strings(activeChannels)
+	return activeChannels
+}
+
+// getChannelSubscriberCount retourne le nombre d'abonnés directs d'un channel
+func (hub *pubSubHub) getChannelSubscriberCount(channelName string) int {
+	hub.hubMutex.RLock()
+	defer hub.hubMutex.RUnlock()
+	return len(hub.channelSubscribers[channelName])
+}
+
+// getPatternCount retourne le nombre de patterns ayant au moins un abonné
+func (hub *pubSubHub) getPatternCount() int {
+	hub.hubMutex.RLock()
+	defer hub.hubMutex.RUnlock()
+	return len(hub.patternSubscribers)
+}
+
+// addSubscription ajoute un abonné à un channel ou un pattern (appelant doit détenir hubMutex)
+func addSubscription(subscriptions map[string]map[*pubSubSubscriber]bool, subscriptionName string, subscriber *pubSubSubscriber) {
+	subscribers, subscriptionExists := subscriptions[subscriptionName]
+	if !subscriptionExists {
+		subscribers = make(map[*pubSubSubscriber]bool)
+		subscriptions[subscriptionName] = subscribers
+	}
+	subscribers[subscriber] = true
+}
+
+// removeSubscription retire un abonné et supprime le channel ou pattern devenu vide (appelant doit détenir hubMutex)
+func removeSubscription(subscriptions map[string]map[*pubSubSubscriber]bool, subscriptionName string, subscriber *pubSubSubscriber) {
+	subscribers, subscriptionExists := subscriptions[subscriptionName]
+	if !subscriptionExists {
+		return
+	}
+	delete(subscribers, subscriber)
+	if len(subscribers) == 0 {
+		delete(subscriptions, subscriptionName)
+	}
+}
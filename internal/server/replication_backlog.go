@@ -0,0 +1,68 @@
+package server
+
+// replicationBacklog conserve la fin du flux de réplication dans un tampon circulaire
+// pour qu'un réplica brièvement déconnecté puisse reprendre sans resynchronisation complète
+// Les offsets sont des positions absolues dans le flux (0 = premier octet jamais produit)
+type replicationBacklog struct {
+	circularBuffer []byte
+	// writePosition est l'index du prochain octet écrit dans circularBuffer
+	writePosition int
+	// historyLength est le nombre d'octets valides (au plus len(circularBuffer))
+	historyLength int
+	// streamEndOffset est l'offset absolu qui suit le dernier octet conservé
+	streamEndOffset int64
+}
+
+// newReplicationBacklog crée un backlog vide de la taille indiquée, commençant à streamOffset
+func newReplicationBacklog(backlogSize int, streamOffset int64) *replicationBacklog {
+	return &replicationBacklog{
+		circularBuffer:  make([]byte, backlogSize),
+		streamEndOffset: streamOffset,
+	}
+}
+
+// appendStreamBytes ajoute des octets du flux, les plus anciens sont écrasés si le tampon est plein
+func (backlog *replicationBacklog) appendStreamBytes(streamBytes []byte) {
+	backlog.streamEndOffset += int64(len(streamBytes))
+
+	// Seule la fin d'un ajout plus grand que le tampon peut être conservée
+	if len(streamBytes) > len(backlog.circularBuffer) {
+		streamBytes = streamBytes[len(streamBytes)-len(backlog.circularBuffer):]
+	}
+	for len(streamBytes) > 0 {
+		copiedLength := copy(backlog.circularBuffer[backlog.writePosition:], streamBytes)
+		streamBytes = streamBytes[copiedLength:]
+		backlog.writePosition = (backlog.writePosition + copiedLength) % len(backlog.circularBuffer)
+		backlog.historyLength = min(backlog.historyLength+copiedLength, len(backlog.circularBuffer))
+	}
+}
+
+// getFirstOffset retourne l'offset absolu du plus ancien octet conservé
+func (backlog *replicationBacklog) getFirstOffset() int64 {
+	return backlog.streamEndOffset - int64(backlog.historyLength)
+}
+
+// readFromOffset retourne une copie des octets du flux à partir de streamOffset
+// false si ces octets ne sont plus (ou pas encore) dans le backlog
+func (backlog *replicationBacklog) readFromOffset(streamOffset int64) ([]byte, bool) {
+	if streamOffset < backlog.getFirstOffset() || streamOffset > backlog.streamEndOffset {
+		return nil, false
+	}
+
+	requestedLength := int(backlog.streamEndOffset - streamOffset)
+	streamBytes := make([]byte, 0, requestedLength)
+	readPosition := (backlog.writePosition - requestedLength + len(backlog.circularBuffer)) % len(backlog.circularBuffer)
+	for len(streamBytes) < requestedLength {
+		readEnd := min(readPosition+requestedLength-len(streamBytes), len(backlog.circularBuffer))
+		streamBytes = append(streamBytes, backlog.circularBuffer[readPosition:readEnd]...)
+		readPosition = readEnd % len(backlog.circularBuffer)
+	}
+	return streamBytes, true
+}
+
+// resetAtOffset vide le backlog, le flux reprend à streamOffset (après une synchronisation complète)
+func (backlog *replicationBacklog) resetAtOffset(streamOffset int64) {
+	backlog.writePosition = 0
+	backlog.historyLength = 0
+	backlog.streamEndOffset = streamOffset
+}
@@ -0,0 +1,110 @@
+package server
+
+import "testing"
+
+func TestReplicationBacklog(t *testing.T) {
+	testCases := []struct {
+		name              string
+		backlogSize       int
+		startOffset       int64
+		appendedChunks    []string
+		readOffset        int64
+		expectedFirst     int64
+		expectedEnd       int64
+		expectedBytes     string
+		expectedAvailable bool
+	}{
+		{
+			name:              "flux plus court que le tampon",
+			backlogSize:       8,
+			appendedChunks:    []string{"abc", "de"},
+			readOffset:        1,
+			expectedEnd:       5,
+			expectedBytes:     "bcde",
+			expectedAvailable: true,
+		},
+		{
+			name:              "lecture à la fin du flux",
+			backlogSize:       8,
+			appendedChunks:    []string{"abc"},
+			readOffset:        3,
+			expectedEnd:       3,
+			expectedBytes:     "",
+			expectedAvailable: true,
+		},
+		{
+			name:              "tampon circulaire après rotation",
+			backlogSize:       4,
+			appendedChunks:    []string{"abc", "def"},
+			readOffset:        2,
+			expectedFirst:     2,
+			expectedEnd:       6,
+			expectedBytes:     "cdef",
+			expectedAvailable: true,
+		},
+		{
+			name:           "octets écrasés par la rotation",
+			backlogSize:    4,
+			appendedChunks: []string{"abc", "def"},
+			readOffset:     1,
+			expectedFirst:  2,
+			expectedEnd:    6,
+		},
+		{
+			name:           "offset pas encore produit",
+			backlogSize:    4,
+			appendedChunks: []string{"ab"},
+			readOffset:     3,
+			expectedEnd:    2,
+		},
+		{
+			name:              "ajout plus grand que le tampon",
+			backlogSize:       4,
+			appendedChunks:    []string{"a", "bcdefgh"},
+			readOffset:        4,
+			expectedFirst:     4,
+			expectedEnd:       8,
+			expectedBytes:     "efgh",
+			expectedAvailable: true,
+		},
+		{
+			name:              "backlog démarrant après une synchronisation complète",
+			backlogSize:       4,
+			startOffset:       100,
+			appendedChunks:    []string{"xy"},
+			readOffset:        101,
+			expectedFirst:     100,
+			expectedEnd:       102,
+			expectedBytes:     "y",
+			expectedAvailable: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			backlog := newReplicationBacklog(testCase.backlogSize, testCase.startOffset)
+			for _, appendedChunk := range testCase.appendedChunks {
+				backlog.appendStreamBytes([]byte(appendedChunk))
+			}
+			if backlog.getFirstOffset() != testCase.expectedFirst || backlog.streamEndOffset != testCase.expectedEnd {
+				t.Fatalf("offsets [%d, %d), attendu [%d, %d)", backlog.getFirstOffset(), backlog.streamEndOffset, testCase.expectedFirst, testCase.expectedEnd)
+			}
+			streamBytes, isAvailable := backlog.readFromOffset(testCase.readOffset)
+			if isAvailable != testCase.expectedAvailable || string(streamBytes) != testCase.expectedBytes {
+				t.Fatalf("lecture depuis %d: %q (%v), attendu %q (%v)", testCase.readOffset, streamBytes, isAvailable, testCase.expectedBytes, testCase.expectedAvailable)
+			}
+		})
+	}
+}
+
+func TestReplicationBacklogResetKeepsNoHistory(t *testing.T) {
+	backlog := newReplicationBacklog(4, 0)
+	backlog.appendStreamBytes([]byte("abcdef"))
+	backlog.resetAtOffset(42)
+	if _, isAvailable := backlog.readFromOffset(5); isAvailable {
+		t.Fatalf("l'historique précédent doit être oublié")
+	}
+	backlog.appendStreamBytes([]byte("zz"))
+	if streamBytes, isAvailable := backlog.readFromOffset(42); !isAvailable || string(streamBytes) != "zz" {
+		t.Fatalf("lecture après reset: %q (%v)", streamBytes, isAvailable)
+	}
+}
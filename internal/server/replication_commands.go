@@ -0,0 +1,162 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// handleReplicaOfCommand implémente REPLICAOF host port | REPLICAOF NO ONE (alias SLAVEOF)
+func (redisServerInstance *RedisServerInstance) handleReplicaOfCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if strings.EqualFold(commandArguments[0], "NO") && strings.EqualFold(commandArguments[1], "ONE") {
+		redisServerInstance.stopReplicationFromPrimary()
+		return protocolEncoder.WriteSimpleStringResponse("OK")
+	}
+
+	primaryPort, parseError := strconv.Atoi(commandArguments[1])
+	if parseError != nil || primaryPort <= 0 || primaryPort > 65535 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : port du primaire invalide (attendu: REPLICAOF host port | REPLICAOF NO ONE)")
+	}
+	primaryAddress := net.JoinHostPort(commandArguments[0], strconv.Itoa(primaryPort))
+
+	// Déjà réplica de ce primaire : rien à faire
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	isSamePrimary := state.primaryAddress == primaryAddress
+	state.stateMutex.Unlock()
+	if isSamePrimary {
+		return protocolEncoder.WriteSimpleStringResponse("OK Déjà connecté au primaire indiqué")
+	}
+
+	redisServerInstance.startReplicationFromPrimary(primaryAddress)
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handleRoleCommand implémente ROLE
+// Primaire : [master, offset, [[ip, port, offset confirmé], ...]]
+// Réplica : [slave, host, port, état du lien, offset]
+func (redisServerInstance *RedisServerInstance) handleRoleCommand(commandArguments []string, redisStorage *storage.RedisInMemoryStorage, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	defer state.stateMutex.Unlock()
+
+	if state.primaryAddress != "" {
+		primaryHost, primaryPortText, _ := net.SplitHostPort(state.primaryAddress)
+		primaryPort, _ := strconv.Atoi(primaryPortText)
+		protocolEncoder.WriteArrayHeader(5)
+		protocolEncoder.WriteBulkStringResponse("slave")
+		protocolEncoder.WriteBulkStringResponse(primaryHost)
+		protocolEncoder.WriteIntegerResponse(int64(primaryPort))
+		protocolEncoder.WriteBulkStringResponse(state.primaryLinkStatus)
+		return protocolEncoder.WriteIntegerResponse(state.backlog.streamEndOffset)
+	}
+
+	connectedReplicas := state.getSortedReplicas()
+	protocolEncoder.WriteArrayHeader(3)
+	protocolEncoder.WriteBulkStringResponse("master")
+	protocolEncoder.WriteIntegerResponse(state.backlog.streamEndOffset)
+	protocolEncoder.WriteArrayHeader(len(connectedReplicas))
+	for _, connectedReplica := range connectedReplicas {
+		replicaHost, replicaPort := connectedReplica.getReplicaAddress()
+		if writeError := protocolEncoder.WriteArrayResponse([]string{
+			replicaHost,
+			strconv.Itoa(replicaPort),
+			strconv.FormatInt(connectedReplica.acknowledgedOffset.Load(), 10),
+		}); writeError != nil {
+			return writeError
+		}
+	}
+	return nil
+}
+
+// getSortedReplicas retourne les réplicas connectés dans un ordre stable (appelé avec stateMutex détenu)
+func (state *replicationState) getSortedReplicas() []*replicaLink {
+	connectedReplicas := make([]*replicaLink, 0, len(state.connectedReplicas))
+	for connectedReplica := range state.connectedReplicas {
+		connectedReplicas = append(connectedReplicas, connectedReplica)
+	}
+	sort.Slice(connectedReplicas, func(firstIndex, secondIndex int) bool {
+		return connectedReplicas[firstIndex].replicaConnection.RemoteAddr().String() < connectedReplicas[secondIndex].replicaConnection.RemoteAddr().String()
+	})
+	return connectedReplicas
+}
+
+// buildReplicationInfoSection construit la section replication d'INFO
+func (redisServerInstance *RedisServerInstance) buildReplicationInfoSection() []string {
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	defer state.stateMutex.Unlock()
+
+	var infoLines []string
+	if state.primaryAddress != "" {
+		primaryHost, primaryPort, _ := net.SplitHostPort(state.primaryAddress)
+		primaryLinkStatus := "down"
+		if state.primaryLinkStatus == "connected" {
+			primaryLinkStatus = "up"
+		}
+		lastInteractionSeconds := int64(-1)
+		if !state.primaryLastInteraction.IsZero() {
+			lastInteractionSeconds = int64(time.Since(state.primaryLastInteraction).Seconds())
+		}
+		infoLines = append(infoLines,
+			"role:slave",
+			"master_host:"+primaryHost,
+			"master_port:"+primaryPort,
+			"master_link_status:"+primaryLinkStatus,
+			fmt.Sprintf("master_last_io_seconds_ago:%d", lastInteractionSeconds),
+			fmt.Sprintf("master_sync_in_progress:%d", boolToInfoFlag(state.primaryLinkStatus == "sync")),
+			fmt.Sprintf("slave_repl_offset:%d", state.backlog.streamEndOffset),
+			fmt.Sprintf("slave_read_only:%d", boolToInfoFlag(redisServerInstance.serverConfiguration.ReplicationConfiguration.ReplicaReadOnly)),
+		)
+	} else {
+		infoLines = append(infoLines, "role:master")
+	}
+
+	connectedReplicas := state.getSortedReplicas()
+	infoLines = append(infoLines, fmt.Sprintf("connected_slaves:%d", len(connectedReplicas)))
+	for replicaIndex, connectedReplica := range connectedReplicas {
+		replicaHost, replicaPort := connectedReplica.getReplicaAddress()
+		replicaStatus := "online"
+		connectedReplica.outputMutex.Lock()
+		if connectedReplica.isWaitingForSnapshot {
+			replicaStatus = "wait_bgsave"
+		}
+		connectedReplica.outputMutex.Unlock()
+		infoLines = append(infoLines, fmt.Sprintf("slave%d:ip=%s,port=%d,state=%s,offset=%d",
+			replicaIndex, replicaHost, replicaPort, replicaStatus, connectedReplica.acknowledgedOffset.Load()))
+	}
+
+	return append(infoLines,
+		"master_replid:"+state.replicationId,
+		fmt.Sprintf("master_repl_offset:%d", state.backlog.streamEndOffset),
+		"repl_backlog_active:1",
+		fmt.Sprintf("repl_backlog_size:%d", len(state.backlog.circularBuffer)),
+		fmt.Sprintf("repl_backlog_first_byte_offset:%d", state.backlog.getFirstOffset()+1),
+		fmt.Sprintf("repl_backlog_histlen:%d", state.backlog.historyLength),
+	)
+}
+
+// boolToInfoFlag convertit un booléen en 0/1 pour INFO
+func boolToInfoFlag(flagValue bool) int {
+	if flagValue {
+		return 1
+	}
+	return 0
+}
+
+// getReplicationRole retourne le rôle du serveur tel qu'annoncé par HELLO (master ou replica)
+func (redisServerInstance *RedisServerInstance) getReplicationRole() string {
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	defer state.stateMutex.Unlock()
+	if state.primaryAddress != "" {
+		return "replica"
+	}
+	return "master"
+}
@@ -0,0 +1,381 @@
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"redis-go/internal/persistence"
+	"redis-go/internal/protocol"
+	"redis-go/internal/storage"
+)
+
+// replicaPendingOutputLimit est le volume de flux en attente au-delà duquel un réplica trop lent est déconnecté
+// (il se resynchronisera à sa reconnexion)
+const replicaPendingOutputLimit = 256 * 1024 * 1024
+
+// replicationHeartbeatInterval est l'intervalle des PING envoyés dans le flux pour que les réplicas détectent une coupure
+const replicationHeartbeatInterval = 10 * time.Second
+
+// replicationState contient l'état de réplication du serveur : identifiant et offset du flux,
+// backlog, réplicas connectés et, si le serveur est un réplica, l'état du lien avec son primaire
+type replicationState struct {
+	stateMutex sync.Mutex
+	// replicationId identifie l'historique du flux (40 caractères hexadécimaux)
+	replicationId string
+	// backlog conserve la fin du flux, backlog.streamEndOffset est l'offset de réplication courant
+	backlog *replicationBacklog
+	// streamSelectedDatabase est la base du dernier SELECT écrit dans le flux (-1 : SELECT obligatoire)
+	streamSelectedDatabase int
+	connectedReplicas      map[*replicaLink]bool
+
+	// primaryAddress est l'adresse host:port du primaire (vide si le serveur est primaire)
+	primaryAddress string
+	// primaryStreamDatabase est la base du dernier SELECT reçu dans le flux du primaire (0 après une synchronisation
+	// complète) : elle est conservée pour reprendre le flux sur la bonne base après une resynchronisation partielle
+	primaryStreamDatabase int
+	// primaryLinkStatus vaut connect, connecting, sync ou connected
+	primaryLinkStatus      string
+	primaryLastInteraction time.Time
+	// stopPrimaryLink interrompt le lien avec le primaire (REPLICAOF NO ONE ou changement de primaire)
+	stopPrimaryLink chan struct{}
+}
+
+// newReplicationState crée l'état d'un serveur primaire avec un nouvel identifiant de réplication
+func newReplicationState(backlogSize int) *replicationState {
+	return &replicationState{
+		replicationId:          generateReplicationId(),
+		backlog:                newReplicationBacklog(backlogSize, 0),
+		streamSelectedDatabase: -1,
+		connectedReplicas:      make(map[*replicaLink]bool),
+	}
+}
+
+// generateReplicationId génère un identifiant de réplication aléatoire
+func generateReplicationId() string {
+	randomBytes := make([]byte, 20)
+	rand.Read(randomBytes)
+	return hex.EncodeToString(randomBytes)
+}
+
+// encodeReplicationCommand encode une commande du flux de réplication (array RESP de bulk strings)
+func encodeReplicationCommand(commandName string, commandArguments []string) []byte {
+	var encodedCommand bytes.Buffer
+	protocol.NewRedisSerializationProtocolEncoder(&encodedCommand).WriteArrayResponse(append([]string{commandName}, commandArguments...))
+	return encodedCommand.Bytes()
+}
+
+// appendToReplicationStream ajoute des octets au flux : backlog puis envoi à chaque réplica
+// Appelé avec stateMutex détenu
+func (state *replicationState) appendToReplicationStream(streamBytes []byte) {
+	state.backlog.appendStreamBytes(streamBytes)
+	for connectedReplica := range state.connectedReplicas {
+		if !connectedReplica.enqueueStreamBytes(streamBytes) {
+			log.Printf("⚠️  Réplica %s déconnecté: plus de %d octets de flux en attente", connectedReplica.replicaConnection.RemoteAddr(), replicaPendingOutputLimit)
+			delete(state.connectedReplicas, connectedReplica)
+			connectedReplica.closeLink()
+		}
+	}
+}
+
+// disconnectAllReplicas ferme le lien de tous les réplicas (l'historique du flux a changé, ils devront se resynchroniser)
+// Appelé avec stateMutex détenu
+func (state *replicationState) disconnectAllReplicas() {
+	for connectedReplica := range state.connectedReplicas {
+		delete(state.connectedReplicas, connectedReplica)
+		connectedReplica.closeLink()
+	}
+}
+
+// propagateWriteToReplicationStream ajoute au flux une commande d'écriture exécutée sur databaseIndex
+// Sur un réplica, le flux reçu du primaire est relayé tel quel et les écritures locales ne sont pas propagées
+func (redisServerInstance *RedisServerInstance) propagateWriteToReplicationStream(databaseIndex int, commandName string, commandArguments []string) {
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	defer state.stateMutex.Unlock()
+
+	if state.primaryAddress != "" {
+		return
+	}
+
+	var streamBytes []byte
+	if databaseIndex != state.streamSelectedDatabase {
+		streamBytes = encodeReplicationCommand("SELECT", []string{strconv.Itoa(databaseIndex)})
+		state.streamSelectedDatabase = databaseIndex
+	}
+	streamBytes = append(streamBytes, encodeReplicationCommand(commandName, commandArguments)...)
+	state.appendToReplicationStream(streamBytes)
+}
+
+// startReplicationHeartbeat envoie régulièrement un PING dans le flux tant que des réplicas sont connectés
+func (redisServerInstance *RedisServerInstance) startReplicationHeartbeat() {
+	redisServerInstance.activeGoroutines.Add(1)
+	go func() {
+		defer redisServerInstance.activeGoroutines.Done()
+
+		heartbeatTicker := time.NewTicker(replicationHeartbeatInterval)
+		defer heartbeatTicker.Stop()
+
+		for {
+			select {
+			case <-redisServerInstance.shutdownSignal:
+				return
+			case <-heartbeatTicker.C:
+				state := redisServerInstance.replicationState
+				state.stateMutex.Lock()
+				if state.primaryAddress == "" && len(state.connectedReplicas) > 0 {
+					state.appendToReplicationStream(encodeReplicationCommand("PING", nil))
+				}
+				state.stateMutex.Unlock()
+			}
+		}
+	}()
+}
+
+// replicaLink est la connexion d'un réplica après PSYNC : le flux lui est envoyé par une goroutine dédiée
+type replicaLink struct {
+	replicaConnection net.Conn
+	// listeningPort est le port annoncé par le réplica (REPLCONF listening-port)
+	listeningPort int
+
+	outputMutex   sync.Mutex
+	pendingOutput []byte
+	// isWaitingForSnapshot : le flux est mis de côté dans deferredOutput jusqu'à l'envoi du snapshot
+	isWaitingForSnapshot bool
+	deferredOutput       []byte
+	outputReady          chan struct{}
+	linkClosed           chan struct{}
+	closeOnce            sync.Once
+
+	// acknowledgedOffset est le dernier offset confirmé par le réplica (REPLCONF ACK)
+	acknowledgedOffset atomic.Int64
+}
+
+// newReplicaLink crée le lien d'envoi du flux vers un réplica
+func newReplicaLink(replicaConnection net.Conn, listeningPort int) *replicaLink {
+	return &replicaLink{
+		replicaConnection: replicaConnection,
+		listeningPort:     listeningPort,
+		outputReady:       make(chan struct{}, 1),
+		linkClosed:        make(chan struct{}),
+	}
+}
+
+// enqueueStreamBytes met des octets du flux en attente d'envoi
+// Retourne false si le réplica a trop de retard
+func (link *replicaLink) enqueueStreamBytes(streamBytes []byte) bool {
+	link.outputMutex.Lock()
+	defer link.outputMutex.Unlock()
+
+	if link.isWaitingForSnapshot {
+		link.deferredOutput = append(link.deferredOutput, streamBytes...)
+		return len(link.deferredOutput) <= replicaPendingOutputLimit
+	}
+	link.pendingOutput = append(link.pendingOutput, streamBytes...)
+	link.signalOutputReady()
+	return len(link.pendingOutput) <= replicaPendingOutputLimit
+}
+
+// completeSnapshotTransfer envoie l'en-tête FULLRESYNC et le snapshot, suivis du flux mis de côté pendant sa création
+func (link *replicaLink) completeSnapshotTransfer(snapshotTransfer []byte) {
+	link.outputMutex.Lock()
+	defer link.outputMutex.Unlock()
+
+	link.pendingOutput = append(snapshotTransfer, link.deferredOutput...)
+	link.deferredOutput = nil
+	link.isWaitingForSnapshot = false
+	link.signalOutputReady()
+}
+
+// signalOutputReady réveille la goroutine d'envoi (appelé avec outputMutex détenu)
+func (link *replicaLink) signalOutputReady() {
+	select {
+	case link.outputReady <- struct{}{}:
+	default:
+	}
+}
+
+// runOutputLoop envoie le flux en attente jusqu'à la fermeture du lien
+func (link *replicaLink) runOutputLoop() {
+	for {
+		select {
+		case <-link.linkClosed:
+			return
+		case <-link.outputReady:
+			link.outputMutex.Lock()
+			outputBytes := link.pendingOutput
+			link.pendingOutput = nil
+			link.outputMutex.Unlock()
+
+			if len(outputBytes) == 0 {
+				continue
+			}
+			link.replicaConnection.SetWriteDeadline(time.Now().Add(60 * time.Second))
+			if _, writeError := link.replicaConnection.Write(outputBytes); writeError != nil {
+				log.Printf("⚠️  Envoi du flux de réplication à %s impossible: %v", link.replicaConnection.RemoteAddr(), writeError)
+				link.closeLink()
+				return
+			}
+		}
+	}
+}
+
+// closeLink ferme la connexion du réplica (la boucle de la connexion se termine)
+func (link *replicaLink) closeLink() {
+	link.closeOnce.Do(func() {
+		close(link.linkClosed)
+		link.replicaConnection.Close()
+	})
+}
+
+// getReplicaAddress retourne l'IP du réplica et le port sur lequel il écoute
+func (link *replicaLink) getReplicaAddress() (string, int) {
+	replicaHost, _, splitError := net.SplitHostPort(link.replicaConnection.RemoteAddr().String())
+	if splitError != nil {
+		replicaHost = link.replicaConnection.RemoteAddr().String()
+	}
+	return replicaHost, link.listeningPort
+}
+
+// processReplicationCommand gère REPLCONF et PSYNC/SYNC envoyés par un réplica
+// Une fois PSYNC accepté, la connexion ne reçoit plus que des REPLCONF ACK, sans réponse
+// Retourne false si la commande doit être traitée normalement
+func (redisServerInstance *RedisServerInstance) processReplicationCommand(connectionState *clientConnectionState, clientConnection net.Conn, commandName string, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) (bool, error) {
+	upperCommandName := strings.ToUpper(commandName)
+
+	if connectionState.replicaLink != nil {
+		if upperCommandName == "REPLCONF" && len(commandArguments) == 2 && strings.EqualFold(commandArguments[0], "ACK") {
+			if acknowledgedOffset, parseError := strconv.ParseInt(commandArguments[1], 10, 64); parseError == nil {
+				connectionState.replicaLink.acknowledgedOffset.Store(acknowledgedOffset)
+			}
+		}
+		return true, nil
+	}
+
+	switch upperCommandName {
+	case "REPLCONF":
+		return true, redisServerInstance.handleReplicationConfigCommand(connectionState, commandArguments, protocolEncoder)
+	case "PSYNC":
+		if len(commandArguments) != 2 {
+			return true, protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'PSYNC' (attendu: PSYNC replicationid offset)")
+		}
+		return true, redisServerInstance.handlePartialSyncCommand(connectionState, clientConnection, commandArguments[0], commandArguments[1], protocolEncoder)
+	case "SYNC":
+		return true, redisServerInstance.handlePartialSyncCommand(connectionState, clientConnection, "?", "-1", protocolEncoder)
+	}
+
+	return false, nil
+}
+
+// handleReplicationConfigCommand implémente REPLCONF listening-port|capa|ack|getack avant PSYNC
+func (redisServerInstance *RedisServerInstance) handleReplicationConfigCommand(connectionState *clientConnectionState, commandArguments []string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	if len(commandArguments) == 0 || len(commandArguments)%2 != 0 {
+		return protocolEncoder.WriteErrorResponse("ERREUR : nombre d'arguments incorrect pour 'REPLCONF' (attendu: REPLCONF option valeur [option valeur ...])")
+	}
+
+	for optionIndex := 0; optionIndex < len(commandArguments); optionIndex += 2 {
+		switch strings.ToLower(commandArguments[optionIndex]) {
+		case "listening-port":
+			listeningPort, parseError := strconv.Atoi(commandArguments[optionIndex+1])
+			if parseError != nil || listeningPort < 0 || listeningPort > 65535 {
+				return protocolEncoder.WriteErrorResponse("ERREUR : port d'écoute invalide pour 'REPLCONF listening-port'")
+			}
+			connectionState.replicaListeningPort = listeningPort
+		case "capa", "ip-address":
+			// Capacités annoncées par le réplica : le flux envoyé ne dépend d'aucune d'elles
+		case "ack", "getack":
+			// Sans objet avant PSYNC
+			return nil
+		default:
+			return protocolEncoder.WriteErrorResponse(fmt.Sprintf("ERREUR : option REPLCONF inconnue '%s'", commandArguments[optionIndex]))
+		}
+	}
+	return protocolEncoder.WriteSimpleStringResponse("OK")
+}
+
+// handlePartialSyncCommand implémente PSYNC replicationid offset
+// Reprend le flux depuis le backlog si possible (+CONTINUE), sinon envoie un snapshot (+FULLRESYNC)
+// L'offset demandé est celui du prochain octet attendu + 1, comme Redis
+func (redisServerInstance *RedisServerInstance) handlePartialSyncCommand(connectionState *clientConnectionState, clientConnection net.Conn, requestedReplicationId string, requestedOffsetArgument string, protocolEncoder *protocol.RedisSerializationProtocolEncoder) error {
+	requestedOffset, parseError := strconv.ParseInt(requestedOffsetArgument, 10, 64)
+	if parseError != nil {
+		return protocolEncoder.WriteErrorResponse("ERREUR : l'offset de PSYNC doit être un entier")
+	}
+
+	state := redisServerInstance.replicationState
+	link := newReplicaLink(clientConnection, connectionState.replicaListeningPort)
+
+	// Resynchronisation partielle : même historique et octets manquants encore dans le backlog
+	state.stateMutex.Lock()
+	if requestedReplicationId == state.replicationId {
+		if missingStreamBytes, isAvailable := state.backlog.readFromOffset(requestedOffset - 1); isAvailable {
+			link.pendingOutput = append([]byte(fmt.Sprintf("+CONTINUE %s\r\n", state.replicationId)), missingStreamBytes...)
+			link.signalOutputReady()
+			// Le réplica reprend sur la base du flux à son offset : la prochaine commande est précédée d'un SELECT
+			state.streamSelectedDatabase = -1
+			state.connectedReplicas[link] = true
+			state.stateMutex.Unlock()
+
+			connectionState.replicaLink = link
+			go link.runOutputLoop()
+			log.Printf("🔁 Réplica %s: resynchronisation partielle (%d octets depuis l'offset %d)", clientConnection.RemoteAddr(), len(missingStreamBytes), requestedOffset-1)
+			return nil
+		}
+	}
+	state.stateMutex.Unlock()
+
+	// Synchronisation complète : copie du dataset et position dans le flux prises au même instant
+	var clonedDataset []map[string]*storage.RedisStorageValue
+	var synchronizationId string
+	var synchronizationOffset int64
+	link.isWaitingForSnapshot = true
+	redisServerInstance.commandRegistry.ExecuteWithExclusiveAccess(func() {
+		clonedDataset = persistence.CloneAllDatabases(redisServerInstance.databaseStorages)
+
+		state.stateMutex.Lock()
+		synchronizationId, synchronizationOffset = state.replicationId, state.backlog.streamEndOffset
+		// Le réplica démarre sur la base 0 : la prochaine commande du flux doit être précédée d'un SELECT
+		state.streamSelectedDatabase = -1
+		state.connectedReplicas[link] = true
+		state.stateMutex.Unlock()
+	})
+
+	connectionState.replicaLink = link
+	go link.runOutputLoop()
+	log.Printf("🔁 Réplica %s: synchronisation complète (offset %d)", clientConnection.RemoteAddr(), synchronizationOffset)
+
+	// Le snapshot est encodé hors verrou, le flux produit entre-temps est envoyé à sa suite
+	go func() {
+		var snapshotBuffer bytes.Buffer
+		if writeError := persistence.WriteSnapshot(&snapshotBuffer, clonedDataset); writeError != nil {
+			log.Printf("❌ Snapshot de synchronisation impossible pour %s: %v", clientConnection.RemoteAddr(), writeError)
+			link.closeLink()
+			return
+		}
+		snapshotTransfer := []byte(fmt.Sprintf("+FULLRESYNC %s %d\r\n$%d\r\n", synchronizationId, synchronizationOffset, snapshotBuffer.Len()))
+		link.completeSnapshotTransfer(append(snapshotTransfer, snapshotBuffer.Bytes()...))
+	}()
+
+	return nil
+}
+
+// removeReplicaLink retire le réplica de la connexion qui se termine
+func (redisServerInstance *RedisServerInstance) removeReplicaLink(connectionState *clientConnectionState) {
+	if connectionState.replicaLink == nil {
+		return
+	}
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	delete(state.connectedReplicas, connectionState.replicaLink)
+	state.stateMutex.Unlock()
+	connectionState.replicaLink.closeLink()
+	log.Printf("🔁 Réplica %s déconnecté", connectionState.replicaLink.replicaConnection.RemoteAddr())
+}
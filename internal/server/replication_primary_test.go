@@ -0,0 +1,112 @@
+package server
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// encodedCommand retourne l'encodage d'une commande du flux de réplication
+func encodedCommand(commandLine string) string {
+	commandFields := strings.Fields(commandLine)
+	return string(encodeReplicationCommand(commandFields[0], commandFields[1:]))
+}
+
+// readStreamBytes lit exactement streamLength octets du flux de réplication
+func (client *testClient) readStreamBytes(streamLength int) string {
+	client.t.Helper()
+	client.clientConnection.SetDeadline(time.Now().Add(5 * time.Second))
+	streamBytes := make([]byte, streamLength)
+	if _, readError := io.ReadFull(client.replyReader, streamBytes); readError != nil {
+		client.t.Fatalf("lecture du flux impossible: %v", readError)
+	}
+	return string(streamBytes)
+}
+
+func TestPartialResynchronizationFromBacklog(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, serverConfiguration)
+	writerClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+
+	// Synchronisation complète d'un premier réplica : identifiant et offset du flux
+	firstReplica := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	fullResyncFields := strings.Fields(firstReplica.execute("PSYNC", "?", "-1"))
+	if len(fullResyncFields) != 3 || fullResyncFields[0] != "+FULLRESYNC" {
+		t.Fatalf("PSYNC ? -1: %q", fullResyncFields)
+	}
+	replicationId := fullResyncFields[1]
+	synchronizationOffset, _ := strconv.Atoi(fullResyncFields[2])
+	// Le snapshot est une bulk string sans CRLF final
+	snapshotHeader, readError := firstReplica.replyReader.ReadString('\n')
+	snapshotLength, parseError := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(snapshotHeader, "$"), "\r\n"))
+	if readError != nil || parseError != nil {
+		t.Fatalf("en-tête du snapshot illisible %q: %v", snapshotHeader, readError)
+	}
+	firstReplica.readStreamBytes(snapshotLength)
+
+	// Le flux commence par un SELECT de la base de la première écriture
+	writerClient.execute("SELECT", "2")
+	writerClient.execute("SET", "k", "v")
+	writerClient.execute("RPUSH", "l", "a")
+	expectedStream := encodedCommand("SELECT 2") + encodedCommand("SET k v") + encodedCommand("RPUSH l a")
+	if receivedStream := firstReplica.readStreamBytes(len(expectedStream)); receivedStream != expectedStream {
+		t.Fatalf("flux reçu %q, attendu %q", receivedStream, expectedStream)
+	}
+	firstReplica.clientConnection.Close()
+	streamEndOffset := synchronizationOffset + len(expectedStream)
+
+	testCases := []struct {
+		name           string
+		psyncArguments []string
+		expectedReply  string
+		expectedStream string
+	}{
+		{
+			name:           "reprise au début des écritures",
+			psyncArguments: []string{replicationId, strconv.Itoa(synchronizationOffset + 1)},
+			expectedReply:  "+CONTINUE " + replicationId + "\r\n",
+			expectedStream: expectedStream,
+		},
+		{
+			name:           "reprise au milieu du flux",
+			psyncArguments: []string{replicationId, strconv.Itoa(streamEndOffset - len(encodedCommand("RPUSH l a")) + 1)},
+			expectedReply:  "+CONTINUE " + replicationId + "\r\n",
+			expectedStream: encodedCommand("RPUSH l a"),
+		},
+		{
+			name:           "réplica déjà à jour",
+			psyncArguments: []string{replicationId, strconv.Itoa(streamEndOffset + 1)},
+			expectedReply:  "+CONTINUE " + replicationId + "\r\n",
+		},
+		{
+			name:           "autre historique",
+			psyncArguments: []string{strings.Repeat("0", 40), strconv.Itoa(synchronizationOffset + 1)},
+			expectedReply:  "+FULLRESYNC " + replicationId + " " + strconv.Itoa(streamEndOffset) + "\r\n",
+		},
+		{
+			name:           "offset au-delà du flux",
+			psyncArguments: []string{replicationId, strconv.Itoa(streamEndOffset + 2)},
+			expectedReply:  "+FULLRESYNC " + replicationId + " " + strconv.Itoa(streamEndOffset) + "\r\n",
+		},
+		{
+			name:           "offset invalide",
+			psyncArguments: []string{replicationId, "début"},
+			expectedReply:  "-ERREUR : l'offset de PSYNC doit être un entier\r\n",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			replicaClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+			if psyncReply := replicaClient.execute(append([]string{"PSYNC"}, testCase.psyncArguments...)...); psyncReply != testCase.expectedReply {
+				t.Fatalf("PSYNC %v: %q, attendu %q", testCase.psyncArguments, psyncReply, testCase.expectedReply)
+			}
+			if testCase.expectedStream != "" {
+				if receivedStream := replicaClient.readStreamBytes(len(testCase.expectedStream)); receivedStream != testCase.expectedStream {
+					t.Fatalf("flux repris %q, attendu %q", receivedStream, testCase.expectedStream)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"redis-go/internal/commands"
+	"redis-go/internal/persistence"
+	"redis-go/internal/protocol"
+)
+
+// replicationAcknowledgeInterval est l'intervalle des REPLCONF ACK envoyés au primaire
+const replicationAcknowledgeInterval = time.Second
+
+// replicationReconnectDelay est l'attente avant une nouvelle tentative de connexion au primaire
+const replicationReconnectDelay = time.Second
+
+// replicationStreamTimeout : sans données du primaire pendant ce délai (il envoie un PING toutes les 10 s), le lien est coupé
+const replicationStreamTimeout = 60 * time.Second
+
+// startReplicationFromPrimary fait du serveur un réplica de primaryAddress (host:port)
+// Le lien précédent éventuel est interrompu et les réplicas de ce serveur devront se resynchroniser
+func (redisServerInstance *RedisServerInstance) startReplicationFromPrimary(primaryAddress string) {
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	if state.stopPrimaryLink != nil {
+		close(state.stopPrimaryLink)
+	}
+	stopPrimaryLink := make(chan struct{})
+	state.primaryAddress = primaryAddress
+	state.primaryLinkStatus = "connect"
+	state.stopPrimaryLink = stopPrimaryLink
+	state.disconnectAllReplicas()
+	state.stateMutex.Unlock()
+
+	redisServerInstance.commandRegistry.SetReadOnlyReplica(redisServerInstance.serverConfiguration.ReplicationConfiguration.ReplicaReadOnly)
+	log.Printf("🔁 Réplication depuis le primaire %s", primaryAddress)
+
+	redisServerInstance.activeGoroutines.Add(1)
+	go redisServerInstance.runPrimaryLink(primaryAddress, stopPrimaryLink)
+}
+
+// stopReplicationFromPrimary refait du serveur un primaire (REPLICAOF NO ONE)
+// Le dataset et l'offset sont conservés, un nouvel identifiant de réplication démarre un nouvel historique
+func (redisServerInstance *RedisServerInstance) stopReplicationFromPrimary() {
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	if state.stopPrimaryLink != nil {
+		close(state.stopPrimaryLink)
+		state.stopPrimaryLink = nil
+		log.Printf("🔁 Fin de la réplication depuis %s, le serveur devient primaire", state.primaryAddress)
+	}
+	state.primaryAddress = ""
+	state.primaryLinkStatus = ""
+	state.replicationId = generateReplicationId()
+	state.streamSelectedDatabase = -1
+	state.disconnectAllReplicas()
+	state.stateMutex.Unlock()
+
+	redisServerInstance.commandRegistry.SetReadOnlyReplica(false)
+}
+
+// setPrimaryLinkStatus met à jour l'état du lien avec le primaire (INFO replication, ROLE)
+func (redisServerInstance *RedisServerInstance) setPrimaryLinkStatus(stopPrimaryLink chan struct{}, linkStatus string) {
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	if state.stopPrimaryLink == stopPrimaryLink {
+		state.primaryLinkStatus = linkStatus
+	}
+	state.stateMutex.Unlock()
+}
+
+// isPrimaryLinkStopped indique si le lien doit s'arrêter (changement de primaire ou arrêt du serveur)
+func (redisServerInstance *RedisServerInstance) isPrimaryLinkStopped(stopPrimaryLink chan struct{}) bool {
+	select {
+	case <-stopPrimaryLink:
+		return true
+	case <-redisServerInstance.shutdownSignal:
+		return true
+	default:
+		return false
+	}
+}
+
+// runPrimaryLink maintient la connexion au primaire et se reconnecte après chaque coupure
+func (redisServerInstance *RedisServerInstance) runPrimaryLink(primaryAddress string, stopPrimaryLink chan struct{}) {
+	defer redisServerInstance.activeGoroutines.Done()
+
+	for {
+		synchronizationError := redisServerInstance.synchronizeWithPrimary(primaryAddress, stopPrimaryLink)
+		if redisServerInstance.isPrimaryLinkStopped(stopPrimaryLink) {
+			return
+		}
+		log.Printf("⚠️  Lien de réplication avec %s interrompu: %v", primaryAddress, synchronizationError)
+		redisServerInstance.setPrimaryLinkStatus(stopPrimaryLink, "connect")
+
+		select {
+		case <-stopPrimaryLink:
+			return
+		case <-redisServerInstance.shutdownSignal:
+			return
+		case <-time.After(replicationReconnectDelay):
+		}
+	}
+}
+
+// synchronizeWithPrimary se connecte au primaire, se synchronise (PSYNC) puis applique le flux jusqu'à une coupure
+func (redisServerInstance *RedisServerInstance) synchronizeWithPrimary(primaryAddress string, stopPrimaryLink chan struct{}) error {
+	redisServerInstance.setPrimaryLinkStatus(stopPrimaryLink, "connecting")
+	primaryConnection, dialError := net.DialTimeout("tcp", primaryAddress, 5*time.Second)
+	if dialError != nil {
+		return dialError
+	}
+
+	// La connexion est fermée dès que le lien doit s'arrêter, ce qui débloque les lectures en cours
+	linkFinished := make(chan struct{})
+	defer close(linkFinished)
+	go func() {
+		select {
+		case <-stopPrimaryLink:
+		case <-redisServerInstance.shutdownSignal:
+		case <-linkFinished:
+		}
+		primaryConnection.Close()
+	}()
+
+	primaryReader := bufio.NewReader(primaryConnection)
+	primaryConnection.SetDeadline(time.Now().Add(10 * time.Second))
+	if handshakeError := redisServerInstance.performReplicationHandshake(primaryConnection, primaryReader); handshakeError != nil {
+		return handshakeError
+	}
+
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	knownReplicationId, knownOffset := state.replicationId, state.backlog.streamEndOffset
+	state.stateMutex.Unlock()
+
+	if _, writeError := primaryConnection.Write(encodeReplicationCommand("PSYNC", []string{knownReplicationId, strconv.FormatInt(knownOffset+1, 10)})); writeError != nil {
+		return writeError
+	}
+	synchronizationReply, readError := readReplicationLine(primaryReader)
+	if readError != nil {
+		return readError
+	}
+
+	replyFields := strings.Fields(synchronizationReply)
+	switch {
+	case len(replyFields) == 3 && replyFields[0] == "+FULLRESYNC":
+		redisServerInstance.setPrimaryLinkStatus(stopPrimaryLink, "sync")
+		primaryConnection.SetDeadline(time.Now().Add(replicationStreamTimeout))
+		if loadError := redisServerInstance.loadSnapshotFromPrimary(primaryReader, replyFields[1], replyFields[2]); loadError != nil {
+			return loadError
+		}
+	case len(replyFields) >= 1 && replyFields[0] == "+CONTINUE":
+		// Le primaire peut annoncer un nouvel identifiant (il a lui-même changé de primaire) : l'offset continue
+		state.stateMutex.Lock()
+		if len(replyFields) == 2 {
+			state.replicationId = replyFields[1]
+		}
+		state.stateMutex.Unlock()
+		log.Printf("🔁 Resynchronisation partielle avec %s depuis l'offset %d", primaryAddress, knownOffset)
+	default:
+		return fmt.Errorf("réponse inattendue à PSYNC: %s", synchronizationReply)
+	}
+
+	primaryConnection.SetDeadline(time.Time{})
+	redisServerInstance.setPrimaryLinkStatus(stopPrimaryLink, "connected")
+	return redisServerInstance.applyReplicationStream(primaryConnection, primaryReader)
+}
+
+// performReplicationHandshake authentifie le réplica puis lui annonce son port et ses capacités
+func (redisServerInstance *RedisServerInstance) performReplicationHandshake(primaryConnection net.Conn, primaryReader *bufio.Reader) error {
+	replicationConfiguration := redisServerInstance.serverConfiguration.ReplicationConfiguration
+
+	var handshakeCommands [][]string
+	if replicationConfiguration.MasterPassword != "" {
+		if replicationConfiguration.MasterUserName != "" {
+			handshakeCommands = append(handshakeCommands, []string{"AUTH", replicationConfiguration.MasterUserName, replicationConfiguration.MasterPassword})
+		} else {
+			handshakeCommands = append(handshakeCommands, []string{"AUTH", replicationConfiguration.MasterPassword})
+		}
+	}
+	handshakeCommands = append(handshakeCommands,
+		[]string{"PING"},
+		[]string{"REPLCONF", "listening-port", strconv.Itoa(redisServerInstance.serverConfiguration.NetworkConfiguration.PortNumber)},
+		[]string{"REPLCONF", "capa", "psync2"})
+
+	for _, handshakeCommand := range handshakeCommands {
+		if _, writeError := primaryConnection.Write(encodeReplicationCommand(handshakeCommand[0], handshakeCommand[1:])); writeError != nil {
+			return writeError
+		}
+		handshakeReply, readError := readReplicationLine(primaryReader)
+		if readError != nil {
+			return readError
+		}
+		if !strings.HasPrefix(handshakeReply, "+") {
+			return fmt.Errorf("le primaire a refusé %s: %s", handshakeCommand[0], strings.TrimPrefix(handshakeReply, "-"))
+		}
+	}
+	return nil
+}
+
+// readReplicationLine lit une ligne de réponse du primaire (sans CRLF)
+func readReplicationLine(primaryReader *bufio.Reader) (string, error) {
+	replyLine, readError := primaryReader.ReadString('\n')
+	if readError != nil {
+		return "", readError
+	}
+	return strings.TrimRight(replyLine, "\r\n"), nil
+}
+
+// loadSnapshotFromPrimary lit le snapshot de la synchronisation complète et remplace le dataset
+// Le flux reprend ensuite à l'offset annoncé par le primaire, avec son identifiant de réplication
+func (redisServerInstance *RedisServerInstance) loadSnapshotFromPrimary(primaryReader *bufio.Reader, replicationId string, offsetArgument string) error {
+	synchronizationOffset, parseError := strconv.ParseInt(offsetArgument, 10, 64)
+	if parseError != nil {
+		return fmt.Errorf("offset FULLRESYNC invalide: %s", offsetArgument)
+	}
+
+	lengthLine, readError := readReplicationLine(primaryReader)
+	if readError != nil {
+		return readError
+	}
+	snapshotLength, parseError := strconv.Atoi(strings.TrimPrefix(lengthLine, "$"))
+	if !strings.HasPrefix(lengthLine, "$") || parseError != nil || snapshotLength < 0 {
+		return fmt.Errorf("en-tête de snapshot invalide: %s", lengthLine)
+	}
+	snapshotContent := make([]byte, snapshotLength)
+	if _, readError := io.ReadFull(primaryReader, snapshotContent); readError != nil {
+		return fmt.Errorf("lecture du snapshot du primaire impossible: %v", readError)
+	}
+
+	databaseEntries, decodeError := persistence.ReadSnapshot(snapshotContent)
+	if decodeError != nil {
+		return fmt.Errorf("snapshot du primaire invalide: %w", decodeError)
+	}
+
+	var loadedKeyCount int
+	var restoreError error
+	redisServerInstance.commandRegistry.ExecuteWithExclusiveAccess(func() {
+		loadedKeyCount, restoreError = persistence.RestoreAllDatabases(databaseEntries, redisServerInstance.databaseStorages)
+	})
+	if restoreError != nil {
+		return restoreError
+	}
+
+	state := redisServerInstance.replicationState
+	state.stateMutex.Lock()
+	state.replicationId = replicationId
+	state.backlog.resetAtOffset(synchronizationOffset)
+	state.primaryStreamDatabase = 0
+	// Les réplicas de ce serveur suivaient l'ancien historique
+	state.disconnectAllReplicas()
+	state.stateMutex.Unlock()
+
+	log.Printf("🔁 Synchronisation complète terminée: %d clés chargées (offset %d)", loadedKeyCount, synchronizationOffset)
+
+	// L'AOF ne contient pas le dataset reçu : il est réécrit à partir de celui-ci
+	if redisServerInstance.appendOnlyFile != nil {
+		if rewriteError := redisServerInstance.startBackgroundAppendOnlyRewrite(); rewriteError != nil {
+			log.Printf("⚠️  Réécriture de l'AOF après synchronisation ignorée: %v", rewriteError)
+		}
+	}
+	return nil
+}
+
+// applyReplicationStream exécute les commandes reçues du primaire, les ajoute au backlog
+// et les relaie à ses propres réplicas, en confirmant régulièrement l'offset traité (REPLCONF ACK)
+func (redisServerInstance *RedisServerInstance) applyReplicationStream(primaryConnection net.Conn, primaryReader *bufio.Reader) error {
+	state := redisServerInstance.replicationState
+	var acknowledgeMutex sync.Mutex
+	sendAcknowledge := func() error {
+		state.stateMutex.Lock()
+		processedOffset := state.backlog.streamEndOffset
+		state.stateMutex.Unlock()
+
+		acknowledgeMutex.Lock()
+		defer acknowledgeMutex.Unlock()
+		_, writeError := primaryConnection.Write(encodeReplicationCommand("REPLCONF", []string{"ACK", strconv.FormatInt(processedOffset, 10)}))
+		return writeError
+	}
+
+	streamFinished := make(chan struct{})
+	defer close(streamFinished)
+	go func() {
+		acknowledgeTicker := time.NewTicker(replicationAcknowledgeInterval)
+		defer acknowledgeTicker.Stop()
+		for {
+			select {
+			case <-streamFinished:
+				return
+			case <-acknowledgeTicker.C:
+				if sendAcknowledge() != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	// Le parser réutilise le lecteur bufferisé : les octets déjà lus après le snapshot ne sont pas perdus
+	streamParser := protocol.NewRedisSerializationProtocolParser(primaryReader)
+	discardEncoder := protocol.NewRedisSerializationProtocolEncoder(io.Discard)
+	// Après une resynchronisation partielle, le flux reprend sur la base sélectionnée avant la coupure
+	state.stateMutex.Lock()
+	streamDatabaseIndex := state.primaryStreamDatabase
+	state.stateMutex.Unlock()
+
+	// Une transaction du primaire (MULTI ... EXEC) est appliquée et ajoutée au backlog en une fois à son EXEC :
+	// après une coupure en son milieu, l'offset confirmé précède son MULTI et elle est renvoyée entière
+	var transactionCommands []commands.RedisDatabaseCommand
+	var pendingStreamBytes []byte
+	isInsideTransaction := false
+
+	for {
+		primaryConnection.SetReadDeadline(time.Now().Add(replicationStreamTimeout))
+		streamCommand, parseError := streamParser.ParseMultibulkCommand()
+		if parseError != nil {
+			return parseError
+		}
+		if len(streamCommand) == 0 {
+			continue
+		}
+
+		switch strings.ToUpper(streamCommand[0]) {
+		case "SELECT":
+			if len(streamCommand) != 2 {
+				return fmt.Errorf("SELECT invalide dans le flux de réplication")
+			}
+			databaseIndex, errorMessage := redisServerInstance.parseDatabaseIndex(streamCommand[1])
+			if errorMessage != "" {
+				return fmt.Errorf("%s", errorMessage)
+			}
+			streamDatabaseIndex = databaseIndex
+		case "PING":
+			// Heartbeat du primaire
+		case "REPLCONF":
+			if len(streamCommand) >= 2 && strings.EqualFold(streamCommand[1], "GETACK") {
+				if writeError := sendAcknowledge(); writeError != nil {
+					return writeError
+				}
+			}
+		case "MULTI":
+			isInsideTransaction = true
+		case "EXEC":
+			if executionError := redisServerInstance.commandRegistry.ExecuteReplicatedTransaction(transactionCommands, discardEncoder); executionError != nil {
+				log.Printf("❌ Transaction du flux de réplication en échec: %v", executionError)
+			}
+			transactionCommands = nil
+			isInsideTransaction = false
+		default:
+			if isInsideTransaction {
+				transactionCommands = append(transactionCommands, commands.RedisDatabaseCommand{
+					DatabaseStorage:  redisServerInstance.databaseStorages[streamDatabaseIndex],
+					CommandName:      streamCommand[0],
+					CommandArguments: streamCommand[1:],
+				})
+			} else if executionError := redisServerInstance.commandRegistry.ExecuteCommand(nil, streamCommand[0], streamCommand[1:], redisServerInstance.databaseStorages[streamDatabaseIndex], discardEncoder); executionError != nil {
+				log.Printf("❌ Commande du flux de réplication en échec: %v", executionError)
+			}
+		}
+
+		pendingStreamBytes = append(pendingStreamBytes, encodeReplicationCommand(streamCommand[0], streamCommand[1:])...)
+		if isInsideTransaction {
+			continue
+		}
+
+		// Le flux est conservé et relayé tel que reçu : les offsets restent ceux du primaire
+		state.stateMutex.Lock()
+		state.appendToReplicationStream(pendingStreamBytes)
+		pendingStreamBytes = nil
+		state.primaryStreamDatabase = streamDatabaseIndex
+		state.primaryLastInteraction = time.Now()
+		state.stateMutex.Unlock()
+	}
+}
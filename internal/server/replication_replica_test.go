@@ -0,0 +1,100 @@
+package server
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// waitForReply répète une commande jusqu'à obtenir la réponse attendue (état répliqué de façon asynchrone)
+// Une réponse attendue se terminant par "*" n'est comparée que sur son préfixe
+func waitForReply(t *testing.T, client *testClient, commandLine string, expectedReply string) {
+	t.Helper()
+	expectedPrefix, isPrefix := strings.CutSuffix(expectedReply, "*")
+	var actualReply string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		actualReply = client.execute(strings.Fields(commandLine)...)
+		if actualReply == expectedReply || (isPrefix && strings.HasPrefix(actualReply, expectedPrefix)) {
+			return
+		}
+	}
+	t.Fatalf("%s: réponse %q, attendu %q", commandLine, actualReply, expectedReply)
+}
+
+func TestReplicaFollowsPrimary(t *testing.T) {
+	primaryConfiguration := newTestServerConfiguration(t)
+	startTestServer(t, primaryConfiguration)
+	replicaConfiguration := newTestServerConfiguration(t)
+	replicaConfiguration.ReplicationConfiguration.ReplicaReadOnly = true
+	startTestServer(t, replicaConfiguration)
+
+	primaryPort := strconv.Itoa(primaryConfiguration.NetworkConfiguration.PortNumber)
+	primaryClient := dialTestClient(t, primaryConfiguration.NetworkConfiguration.PortNumber)
+	replicaClient := dialTestClient(t, replicaConfiguration.NetworkConfiguration.PortNumber)
+	testClients := []*testClient{primaryClient, replicaClient}
+
+	// Données présentes avant la synchronisation complète, dans plusieurs bases
+	runClientSteps(t, testClients, []clientTestStep{
+		{0, "SET avant v", "+OK\r\n"},
+		{0, "PEXPIREAT avant 4102444800000", ":1\r\n"},
+		{0, "SELECT 3", "+OK\r\n"},
+		{0, "HSET h f v", ":1\r\n"},
+		{1, "SET local écrasé", "+OK\r\n"},
+		{1, "REPLICAOF 127.0.0.1 " + primaryPort, "+OK\r\n"},
+		{1, "REPLICAOF 127.0.0.1 " + primaryPort, "+OK Déjà connecté au primaire indiqué\r\n"},
+		{1, "REPLICAOF 127.0.0.1 port", "-ERREUR : port du primaire invalide*"},
+	})
+	waitForReply(t, replicaClient, "ROLE", "*5\r\n"+bulk("slave")+bulk("127.0.0.1")+":"+primaryPort+"\r\n"+bulk("connected")+"*")
+	runClientSteps(t, testClients, []clientTestStep{
+		{1, "EXISTS local", ":0\r\n"},
+		{1, "GET avant", bulk("v")},
+		{1, "PEXPIRETIME avant", ":4102444800000\r\n"},
+		{1, "SELECT 3", "+OK\r\n"},
+		{1, "HGET h f", bulk("v")},
+		{1, "SET k v", "-READONLY Vous ne pouvez pas écrire sur un réplica en lecture seule\r\n"},
+		{1, "GETDEL h", "-READONLY*"},
+	})
+
+	// Écritures reçues en continu : commandes simples, transactions et scripts
+	runClientSteps(t, testClients, []clientTestStep{
+		{0, "SELECT 5", "+OK\r\n"},
+		{0, "RPUSH l a b", ":2\r\n"},
+		{0, "MULTI", "+OK\r\n"},
+		{0, "INCR n", "+QUEUED\r\n"},
+		{0, "INCR n", "+QUEUED\r\n"},
+		{0, "EXEC", encodedArray(":1\r\n", ":2\r\n")},
+		{0, "EVAL return(redis.call('SET','script','1')) 0", "+OK\r\n"},
+		{0, "SELECT 3", "+OK\r\n"},
+		{0, "DEL h", ":1\r\n"},
+		{1, "SELECT 5", "+OK\r\n"},
+	})
+	waitForReply(t, replicaClient, "GET n", bulk("2"))
+	runClientSteps(t, testClients, []clientTestStep{
+		{1, "LRANGE l 0 -1", encodedArray(bulk("a"), bulk("b"))},
+		{1, "GET script", bulk("1")},
+		{1, "SELECT 3", "+OK\r\n"},
+		{1, "EXISTS h", ":0\r\n"},
+	})
+
+	// Les offsets se rejoignent une fois le flux appliqué
+	primaryOffset := strings.Split(primaryClient.execute("ROLE"), "\r\n")[3]
+	waitForReply(t, replicaClient, "ROLE", encodedArray(bulk("slave"), bulk("127.0.0.1"), ":"+primaryPort+"\r\n", bulk("connected"), primaryOffset+"\r\n"))
+	if replicationInfo := replicaClient.execute("INFO", "replication"); !strings.Contains(replicationInfo, "role:slave\r\n") || !strings.Contains(replicationInfo, "master_link_status:up\r\n") || !strings.Contains(replicationInfo, "slave_repl_offset:"+strings.TrimPrefix(primaryOffset, ":")+"\r\n") {
+		t.Fatalf("INFO replication du réplica: %q", replicationInfo)
+	}
+	replicaPort := strconv.Itoa(replicaConfiguration.NetworkConfiguration.PortNumber)
+	runClientSteps(t, testClients, []clientTestStep{
+		{0, "ROLE", "*3\r\n" + bulk("master") + primaryOffset + "\r\n*1\r\n*3\r\n" + bulk("127.0.0.1") + bulk(replicaPort) + "*"},
+	})
+
+	// REPLICAOF NO ONE conserve le dataset et rend le serveur accessible en écriture
+	runClientSteps(t, testClients, []clientTestStep{
+		{1, "REPLICAOF NO ONE", "+OK\r\n"},
+		{1, "SELECT 5", "+OK\r\n"},
+		{1, "GET n", bulk("2")},
+		{1, "SET k v", "+OK\r\n"},
+		{1, "ROLE", "*3\r\n" + bulk("master") + "*"},
+	})
+	waitForReply(t, primaryClient, "ROLE", "*3\r\n"+bulk("master")+primaryOffset+"\r\n*0\r\n")
+}
@@ -3,36 +3,94 @@ package server
 import (
 	"net"
 	"sync"
+	"sync/atomic"
 
 	"redis-go/internal/commands"
 	"redis-go/internal/config"
+	"redis-go/internal/persistence"
 	"redis-go/internal/storage"
 )
 
 // RedisServerInstance représente le serveur Redis
 type RedisServerInstance struct {
 	serverConfiguration *config.ServerConfiguration
-	redisStorage        *storage.RedisInMemoryStorage
+	databaseStorages    []*storage.RedisInMemoryStorage
 	commandRegistry     *commands.RedisCommandRegistry
-	networkListener     net.Listener
-	connectedClients    map[net.Conn]bool
+	snapshotManager     *persistence.RedisSnapshotManager
+	appendOnlyFile      *persistence.RedisAppendOnlyFile
+	pubSubHub           *pubSubHub
+	replicationState    *replicationState
+	statistics          *serverStatistics
+	networkListeners    []net.Listener
+	connectedClients    map[net.Conn]*clientConnectionState
 	clientsMutex        sync.RWMutex
+	nextClientId        atomic.Int64
+	clientPause         *clientPauseState
 	shutdownSignal      chan struct{}
 	activeGoroutines    sync.WaitGroup
 }
 
-// NewRedisServerInstance crée une nouvelle instance de serveur
-func NewRedisServerInstance(serverConfiguration *config.ServerConfiguration) *RedisServerInstance {
+// NewRedisServerInstance crée une nouvelle instance de serveur et recharge le dernier snapshot
+func NewRedisServerInstance(serverConfiguration *config.ServerConfiguration) (*RedisServerInstance, error) {
+	databaseStorages := storage.NewRedisDatabaseStorages(serverConfiguration.StorageConfiguration.DatabaseCount)
 	redisServerInstance := &RedisServerInstance{
 		serverConfiguration: serverConfiguration,
-		redisStorage:        storage.NewRedisInMemoryStorage(),
-		commandRegistry:     commands.NewRedisCommandRegistry(),
-		connectedClients:    make(map[net.Conn]bool),
+		databaseStorages:    databaseStorages,
+		commandRegistry:     commands.NewRedisCommandRegistry(databaseStorages),
+		pubSubHub:           newPubSubHub(),
+		replicationState:    newReplicationState(serverConfiguration.ReplicationConfiguration.BacklogSize),
+		statistics:          newServerStatistics(),
+		connectedClients:    make(map[net.Conn]*clientConnectionState),
+		clientPause:         newClientPauseState(),
 		shutdownSignal:      make(chan struct{}),
 	}
 
+	redisServerInstance.snapshotManager = persistence.NewRedisSnapshotManager(
+		serverConfiguration.PersistenceConfiguration.SnapshotFilePath,
+		redisServerInstance.databaseStorages,
+		redisServerInstance.commandRegistry.GetWriteCommandCount)
+	redisServerInstance.registerServerCommands()
+
+	// requirepass : les connexions doivent s'authentifier avec AUTH avant toute autre commande
+	if requirePassword := serverConfiguration.SecurityConfiguration.RequirePassword; requirePassword != "" {
+		redisServerInstance.commandRegistry.GetAccessControlList().SetDefaultUserPassword(requirePassword)
+	}
+
+	// Chargement des données persistées avant d'accepter des clients
+	if loadError := redisServerInstance.loadPersistedDataset(); loadError != nil {
+		return nil, loadError
+	}
+
+	// Réplication : chaque écriture alimente le flux envoyé aux réplicas, puis connexion au primaire si configuré
+	redisServerInstance.commandRegistry.AddWriteCommandListener(redisServerInstance.propagateWriteToReplicationStream)
+	redisServerInstance.startReplicationHeartbeat()
+	if primaryAddress := serverConfiguration.ReplicationConfiguration.ReplicaOfAddress; primaryAddress != "" {
+		redisServerInstance.startReplicationFromPrimary(primaryAddress)
+	}
+
 	// Démarrage du garbage collector pour les clés expirées
 	redisServerInstance.startExpirationGarbageCollector()
 
-	return redisServerInstance
+	// Démarrage des sauvegardes automatiques
+	redisServerInstance.startSnapshotScheduler()
+	redisServerInstance.startAppendOnlyFsyncScheduler()
+
+	return redisServerInstance, nil
+}
+
+// registerServerCommands enregistre les commandes qui dépendent de l'état du serveur
+func (redisServerInstance *RedisServerInstance) registerServerCommands() {
+	redisServerInstance.commandRegistry.RegisterCommand("SAVE", redisServerInstance.handleSaveCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("BGSAVE", redisServerInstance.handleBackgroundSaveCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("LASTSAVE", redisServerInstance.handleLastSaveCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("BGREWRITEAOF", redisServerInstance.handleBackgroundRewriteAppendOnlyCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("PUBLISH", redisServerInstance.handlePublishCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("PUBSUB", redisServerInstance.handlePubSubCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("MOVE", redisServerInstance.handleMoveCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("SWAPDB", redisServerInstance.handleSwapDatabaseCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("FLUSHALL", redisServerInstance.handleFlushAllCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("REPLICAOF", redisServerInstance.handleReplicaOfCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("SLAVEOF", redisServerInstance.handleReplicaOfCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("ROLE", redisServerInstance.handleRoleCommand)
+	redisServerInstance.commandRegistry.RegisterCommand("INFO", redisServerInstance.handleInfoCommand)
 }
@@ -1,33 +1,112 @@
 package server
 
 import (
+	"crypto/tls"
 	"fmt"
 	"log"
 	"net"
+	"strconv"
+	"sync"
 )
 
-// StartRedisServer démarre le serveur TCP
+// StartRedisServer démarre les listeners configurés (TCP en clair, TLS, socket Unix)
+// et bloque jusqu'à l'arrêt du serveur
 func (redisServerInstance *RedisServerInstance) StartRedisServer() error {
-	serverAddress := fmt.Sprintf("%s:%d",
-		redisServerInstance.serverConfiguration.NetworkConfiguration.HostAddress,
-		redisServerInstance.serverConfiguration.NetworkConfiguration.PortNumber)
-
-	networkListener, listenError := net.Listen("tcp", serverAddress)
+	networkListeners, listenError := redisServerInstance.openNetworkListeners()
 	if listenError != nil {
-		return fmt.Errorf("impossible d'écouter sur %s: %v", serverAddress, listenError)
+		return listenError
+	}
+
+	redisServerInstance.clientsMutex.Lock()
+	select {
+	case <-redisServerInstance.shutdownSignal:
+		// Arrêt demandé pendant l'ouverture des listeners
+		redisServerInstance.clientsMutex.Unlock()
+		for _, networkListener := range networkListeners {
+			networkListener.Close()
+		}
+		return nil
+	default:
+		redisServerInstance.networkListeners = networkListeners
+	}
+	redisServerInstance.clientsMutex.Unlock()
+
+	// Chaque listener a sa propre boucle d'acceptation, les clients sont comptés ensemble
+	var acceptLoops sync.WaitGroup
+	for _, networkListener := range networkListeners {
+		acceptLoops.Add(1)
+		go func(networkListener net.Listener) {
+			defer acceptLoops.Done()
+			redisServerInstance.acceptClientConnections(networkListener)
+		}(networkListener)
+	}
+	acceptLoops.Wait()
+
+	return nil
+}
+
+// openNetworkListeners ouvre le port en clair, le port TLS et le socket Unix selon la configuration
+// En cas d'erreur, les listeners déjà ouverts sont refermés
+func (redisServerInstance *RedisServerInstance) openNetworkListeners() ([]net.Listener, error) {
+	networkConfiguration := redisServerInstance.serverConfiguration.NetworkConfiguration
+	var networkListeners []net.Listener
+	closeOpenedListeners := func() {
+		for _, openedListener := range networkListeners {
+			openedListener.Close()
+		}
+	}
+
+	if networkConfiguration.PortNumber != 0 {
+		serverAddress := net.JoinHostPort(networkConfiguration.HostAddress, strconv.Itoa(networkConfiguration.PortNumber))
+		plainListener, listenError := net.Listen("tcp", serverAddress)
+		if listenError != nil {
+			return nil, fmt.Errorf("impossible d'écouter sur %s: %v", serverAddress, listenError)
+		}
+		networkListeners = append(networkListeners, plainListener)
+		log.Printf("🚀 Serveur Redis-Go en écoute sur %s", serverAddress)
 	}
 
-	redisServerInstance.networkListener = networkListener
-	log.Printf("🚀 Serveur Redis-Go en écoute sur %s", serverAddress)
+	if networkConfiguration.TlsPortNumber != 0 {
+		tlsConfiguration, configurationError := buildTlsConfiguration(networkConfiguration)
+		if configurationError != nil {
+			closeOpenedListeners()
+			return nil, configurationError
+		}
+		tlsAddress := net.JoinHostPort(networkConfiguration.HostAddress, strconv.Itoa(networkConfiguration.TlsPortNumber))
+		tlsListener, listenError := tls.Listen("tcp", tlsAddress, tlsConfiguration)
+		if listenError != nil {
+			closeOpenedListeners()
+			return nil, fmt.Errorf("impossible d'écouter en TLS sur %s: %v", tlsAddress, listenError)
+		}
+		networkListeners = append(networkListeners, tlsListener)
+		log.Printf("🔒 Serveur Redis-Go en écoute TLS sur %s (mTLS: %t)", tlsAddress, networkConfiguration.TlsAuthenticateClients)
+	}
+
+	if networkConfiguration.UnixSocketPath != "" {
+		unixListener, listenError := openUnixSocketListener(networkConfiguration.UnixSocketPath, networkConfiguration.UnixSocketPermissions)
+		if listenError != nil {
+			closeOpenedListeners()
+			return nil, listenError
+		}
+		networkListeners = append(networkListeners, unixListener)
+		log.Printf("🧦 Serveur Redis-Go en écoute sur le socket Unix %s (permissions %#o)", networkConfiguration.UnixSocketPath, networkConfiguration.UnixSocketPermissions)
+	}
+
+	if len(networkListeners) == 0 {
+		return nil, fmt.Errorf("aucun listener configuré (REDIS_PORT et REDIS_TLS_PORT valent 0, REDIS_UNIX_SOCKET est vide)")
+	}
+	return networkListeners, nil
+}
 
-	// Boucle d'acceptation des connexions
+// acceptClientConnections accepte les connexions d'un listener jusqu'à l'arrêt du serveur
+func (redisServerInstance *RedisServerInstance) acceptClientConnections(networkListener net.Listener) {
 	for {
 		clientConnection, acceptError := networkListener.Accept()
 		if acceptError != nil {
 			select {
 			case <-redisServerInstance.shutdownSignal:
 				// Arrêt normal du serveur
-				return nil
+				return
 			default:
 				log.Printf("❌ Erreur lors de l'acceptation de connexion: %v", acceptError)
 				continue
@@ -35,23 +114,26 @@ func (redisServerInstance *RedisServerInstance) StartRedisServer() error {
 		}
 
 		log.Printf("🔗 Nouvelle connexion depuis %s", clientConnection.RemoteAddr())
+		redisServerInstance.statistics.totalConnectionsReceived.Add(1)
 
-		// Vérification du nombre maximum de connexions
+		// Vérification du nombre maximum de connexions (tous listeners confondus)
 		redisServerInstance.clientsMutex.Lock()
 		if len(redisServerInstance.connectedClients) >= redisServerInstance.serverConfiguration.PerformanceConfiguration.MaximumConnections {
 			redisServerInstance.clientsMutex.Unlock()
 			clientConnection.Close()
+			redisServerInstance.statistics.rejectedConnections.Add(1)
 			log.Printf("🚫 Connexion refusée: limite atteinte (%d connexions max)",
 				redisServerInstance.serverConfiguration.PerformanceConfiguration.MaximumConnections)
 			continue
 		}
 
-		redisServerInstance.connectedClients[clientConnection] = true
+		connectionState := redisServerInstance.newClientConnectionState(clientConnection)
+		redisServerInstance.connectedClients[clientConnection] = connectionState
 		redisServerInstance.clientsMutex.Unlock()
 
 		// Gestion du client dans une goroutine séparée
 		redisServerInstance.activeGoroutines.Add(1)
-		go redisServerInstance.handleClientConnection(clientConnection)
+		go redisServerInstance.handleClientConnection(connectionState)
 	}
 }
 
@@ -60,12 +142,11 @@ func (redisServerInstance *RedisServerInstance) StopRedisServer() error {
 	log.Printf("⏹️  Arrêt du serveur en cours...")
 	close(redisServerInstance.shutdownSignal)
 
-	if redisServerInstance.networkListener != nil {
-		redisServerInstance.networkListener.Close()
-	}
-
-	// Fermeture de toutes les connexions clients
+	// Fermeture des listeners puis de toutes les connexions clients
 	redisServerInstance.clientsMutex.Lock()
+	for _, networkListener := range redisServerInstance.networkListeners {
+		networkListener.Close()
+	}
 	connectedClientCount := len(redisServerInstance.connectedClients)
 	for clientConnection := range redisServerInstance.connectedClients {
 		clientConnection.Close()
@@ -79,5 +160,20 @@ func (redisServerInstance *RedisServerInstance) StopRedisServer() error {
 	// Attente de la fin de toutes les goroutines
 	redisServerInstance.activeGoroutines.Wait()
 
+	// Fermeture de l'AOF (fsync final)
+	if redisServerInstance.appendOnlyFile != nil {
+		if closeError := redisServerInstance.appendOnlyFile.Close(); closeError != nil {
+			log.Printf("⚠️  Erreur lors de la fermeture de l'AOF: %v", closeError)
+		}
+	}
+
+	// Sauvegarde finale si les snapshots automatiques sont activés
+	if len(redisServerInstance.serverConfiguration.PersistenceConfiguration.SnapshotSavePolicies) > 0 {
+		if saveError := redisServerInstance.snapshotManager.SaveSnapshot(); saveError != nil {
+			return fmt.Errorf("sauvegarde finale impossible: %v", saveError)
+		}
+		log.Printf("💾 Snapshot final écrit dans %s", redisServerInstance.serverConfiguration.PersistenceConfiguration.SnapshotFilePath)
+	}
+
 	return nil
 }
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"redis-go/internal/config"
+)
+
+// newTestServerConfiguration retourne une configuration sans persistance automatique, données dans un répertoire temporaire
+func newTestServerConfiguration(t *testing.T) *config.ServerConfiguration {
+	t.Helper()
+	dataDirectory := t.TempDir()
+	return &config.ServerConfiguration{
+		NetworkConfiguration: config.NetworkConfiguration{
+			HostAddress: "127.0.0.1",
+			PortNumber:  reserveFreePort(t),
+		},
+		PerformanceConfiguration: config.PerformanceConfiguration{MaximumConnections: 100},
+		MaintenanceConfiguration: config.MaintenanceConfiguration{ExpirationCheckInterval: time.Second},
+		PersistenceConfiguration: config.PersistenceConfiguration{
+			SnapshotFilePath:   filepath.Join(dataDirectory, "dump.rdb"),
+			AppendOnlyFilePath: filepath.Join(dataDirectory, "appendonly.aof"),
+			AppendFsyncPolicy:  config.AppendFsyncEverySecond,
+		},
+		StorageConfiguration:     config.StorageConfiguration{DatabaseCount: 16},
+		ReplicationConfiguration: config.ReplicationConfiguration{BacklogSize: 1024 * 1024},
+	}
+}
+
+// reserveFreePort retourne un port TCP libre sur la boucle locale
+func reserveFreePort(t *testing.T) int {
+	t.Helper()
+	portListener, listenError := net.Listen("tcp", "127.0.0.1:0")
+	if listenError != nil {
+		t.Fatalf("aucun port libre: %v", listenError)
+	}
+	defer portListener.Close()
+	return portListener.Addr().(*net.TCPAddr).Port
+}
+
+// startTestServer démarre un serveur avec cette configuration et l'arrête à la fin du test
+// Retourne quand le port en clair (ou TLS) accepte les connexions
+func startTestServer(t *testing.T, serverConfiguration *config.ServerConfiguration) *RedisServerInstance {
+	t.Helper()
+	redisServerInstance, initializationError := NewRedisServerInstance(serverConfiguration)
+	if initializationError != nil {
+		t.Fatalf("initialisation du serveur impossible: %v", initializationError)
+	}
+
+	startupResult := make(chan error, 1)
+	go func() { startupResult <- redisServerInstance.StartRedisServer() }()
+	t.Cleanup(func() {
+		redisServerInstance.StopRedisServer()
+		<-startupResult
+	})
+
+	readyPort := serverConfiguration.NetworkConfiguration.PortNumber
+	if readyPort == 0 {
+		readyPort = serverConfiguration.NetworkConfiguration.TlsPortNumber
+	}
+	readyAddress := net.JoinHostPort("127.0.0.1", strconv.Itoa(readyPort))
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		select {
+		case startupError := <-startupResult:
+			t.Fatalf("démarrage du serveur impossible: %v", startupError)
+		default:
+		}
+		if probeConnection, dialError := net.Dial("tcp", readyAddress); dialError == nil {
+			probeConnection.Close()
+			return redisServerInstance
+		}
+	}
+	t.Fatalf("le serveur n'écoute pas sur %s", readyAddress)
+	return nil
+}
+
+// sendTestCommand envoie une commande multibulk et retourne la première ligne de la réponse (sans \r\n)
+func sendTestCommand(t *testing.T, clientConnection net.Conn, commandReader *bufio.Reader, commandArguments ...string) (string, error) {
+	t.Helper()
+	var encodedCommand strings.Builder
+	encodedCommand.WriteString("*" + strconv.Itoa(len(commandArguments)) + "\r\n")
+	for _, commandArgument := range commandArguments {
+		encodedCommand.WriteString("$" + strconv.Itoa(len(commandArgument)) + "\r\n" + commandArgument + "\r\n")
+	}
+
+	clientConnection.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, writeError := clientConnection.Write([]byte(encodedCommand.String())); writeError != nil {
+		return "", writeError
+	}
+	replyLine, readError := commandReader.ReadString('\n')
+	if readError != nil {
+		return "", readError
+	}
+	return strings.TrimSuffix(replyLine, "\r\n"), nil
+}
+
+// testClient est une connexion de test qui lit les réponses RESP complètes
+type testClient struct {
+	t                *testing.T
+	clientConnection net.Conn
+	replyReader      *bufio.Reader
+}
+
+// dialTestClient ouvre une connexion vers le port en clair du serveur, fermée à la fin du test
+func dialTestClient(t *testing.T, portNumber int) *testClient {
+	t.Helper()
+	clientConnection, dialError := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(portNumber)))
+	if dialError != nil {
+		t.Fatalf("connexion au serveur impossible: %v", dialError)
+	}
+	t.Cleanup(func() { clientConnection.Close() })
+	return &testClient{t: t, clientConnection: clientConnection, replyReader: bufio.NewReader(clientConnection)}
+}
+
+// execute envoie une commande et retourne sa réponse RESP brute complète
+func (client *testClient) execute(commandArguments ...string) string {
+	client.t.Helper()
+	firstLine, commandError := sendTestCommand(client.t, client.clientConnection, client.replyReader, commandArguments...)
+	if commandError != nil {
+		client.t.Fatalf("%v: %v", commandArguments, commandError)
+	}
+	return client.readRemainingReply(firstLine + "\r\n")
+}
+
+// readReply lit la prochaine réponse RESP complète (message poussé, réponse différée)
+func (client *testClient) readReply() string {
+	client.t.Helper()
+	client.clientConnection.SetDeadline(time.Now().Add(5 * time.Second))
+	firstLine, readError := client.replyReader.ReadString('\n')
+	if readError != nil {
+		client.t.Fatalf("lecture de la réponse impossible: %v", readError)
+	}
+	return client.readRemainingReply(firstLine)
+}
+
+// readRemainingReply complète une réponse RESP dont la première ligne (avec \r\n) est déjà lue
+func (client *testClient) readRemainingReply(firstLine string) string {
+	client.t.Helper()
+	encodedLength, _ := strconv.Atoi(strings.TrimSuffix(firstLine[1:], "\r\n"))
+	if encodedLength < 0 {
+		return firstLine
+	}
+	switch firstLine[0] {
+	case '$', '=', '!':
+		payload := make([]byte, encodedLength+2)
+		if _, readError := io.ReadFull(client.replyReader, payload); readError != nil {
+			client.t.Fatalf("lecture de la réponse impossible: %v", readError)
+		}
+		return firstLine + string(payload)
+	case '*', '~', '>', '%':
+		if firstLine[0] == '%' {
+			encodedLength *= 2
+		}
+		fullReply := firstLine
+		for elementIndex := 0; elementIndex < encodedLength; elementIndex++ {
+			fullReply += client.readReply()
+		}
+		return fullReply
+	}
+	return firstLine
+}
+
+// bulk retourne l'encodage RESP d'une bulk string
+func bulk(bulkString string) string {
+	return "$" + strconv.Itoa(len(bulkString)) + "\r\n" + bulkString + "\r\n"
+}
+
+// encodedArray retourne l'encodage RESP d'un tableau de réponses déjà encodées
+func encodedArray(encodedElements ...string) string {
+	return "*" + strconv.Itoa(len(encodedElements)) + "\r\n" + strings.Join(encodedElements, "")
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"log"
+	"time"
+)
+
+// startSnapshotScheduler démarre la vérification périodique des politiques de sauvegarde
+func (redisServerInstance *RedisServerInstance) startSnapshotScheduler() {
+	savePolicies := redisServerInstance.serverConfiguration.PersistenceConfiguration.SnapshotSavePolicies
+	if len(savePolicies) == 0 {
+		return
+	}
+
+	redisServerInstance.activeGoroutines.Add(1)
+	go func() {
+		defer redisServerInstance.activeGoroutines.Done()
+
+		policyCheckTicker := time.NewTicker(time.Second)
+		defer policyCheckTicker.Stop()
+
+		log.Printf("💾 Sauvegardes automatiques activées (%d règles)", len(savePolicies))
+
+		for {
+			select {
+			case <-redisServerInstance.shutdownSignal:
+				return
+			case <-policyCheckTicker.C:
+				redisServerInstance.triggerSnapshotIfPolicyMatches()
+			}
+		}
+	}()
+}
+
+// triggerSnapshotIfPolicyMatches lance un BGSAVE si une règle "intervalle + nombre de changements" est satisfaite
+func (redisServerInstance *RedisServerInstance) triggerSnapshotIfPolicyMatches() {
+	snapshotManager := redisServerInstance.snapshotManager
+	if snapshotManager.IsSaveInProgress() {
+		return
+	}
+
+	changesSinceLastSave := snapshotManager.GetChangesSinceLastSave()
+	elapsedSinceLastSave := time.Since(snapshotManager.GetLastSaveTime())
+
+	for _, savePolicy := range redisServerInstance.serverConfiguration.PersistenceConfiguration.SnapshotSavePolicies {
+		if elapsedSinceLastSave >= savePolicy.Interval && changesSinceLastSave >= savePolicy.MinimumChanges {
+			log.Printf("💾 %d changements en %v, sauvegarde en arrière-plan...", changesSinceLastSave, savePolicy.Interval)
+			redisServerInstance.startBackgroundSnapshot()
+			return
+		}
+	}
+}
+
+// startBackgroundSnapshot lance un BGSAVE et journalise son résultat
+func (redisServerInstance *RedisServerInstance) startBackgroundSnapshot() error {
+	return redisServerInstance.snapshotManager.StartBackgroundSave(func(saveError error) {
+		if saveError != nil {
+			log.Printf("❌ Échec de la sauvegarde en arrière-plan: %v", saveError)
+			return
+		}
+		log.Printf("💾 Sauvegarde en arrière-plan terminée")
+	})
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"redis-go/internal/config"
+)
+
+// buildTlsConfiguration charge le certificat du serveur et, si demandé, l'autorité des certificats clients (mTLS)
+func buildTlsConfiguration(networkConfiguration config.NetworkConfiguration) (*tls.Config, error) {
+	if networkConfiguration.TlsCertificateFile == "" || networkConfiguration.TlsKeyFile == "" {
+		return nil, fmt.Errorf("le port TLS exige un certificat et une clé (REDIS_TLS_CERT_FILE, REDIS_TLS_KEY_FILE)")
+	}
+
+	serverCertificate, loadError := tls.LoadX509KeyPair(networkConfiguration.TlsCertificateFile, networkConfiguration.TlsKeyFile)
+	if loadError != nil {
+		return nil, fmt.Errorf("chargement du certificat TLS impossible: %v", loadError)
+	}
+
+	tlsConfiguration := &tls.Config{
+		Certificates: []tls.Certificate{serverCertificate},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if networkConfiguration.TlsCaCertificateFile != "" {
+		certificateAuthorities, loadError := loadCertificateAuthorities(networkConfiguration.TlsCaCertificateFile)
+		if loadError != nil {
+			return nil, loadError
+		}
+		tlsConfiguration.ClientCAs = certificateAuthorities
+	}
+
+	if networkConfiguration.TlsAuthenticateClients {
+		if tlsConfiguration.ClientCAs == nil {
+			return nil, fmt.Errorf("l'authentification des clients TLS exige une autorité de certification (REDIS_TLS_CA_CERT_FILE)")
+		}
+		tlsConfiguration.ClientAuth = tls.RequireAndVerifyClientCert
+	} else if tlsConfiguration.ClientCAs != nil {
+		// Sans mTLS obligatoire, un certificat client présenté est tout de même vérifié
+		tlsConfiguration.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+
+	return tlsConfiguration, nil
+}
+
+// loadCertificateAuthorities lit un fichier PEM contenant un ou plusieurs certificats d'autorité
+func loadCertificateAuthorities(caCertificateFile string) (*x509.CertPool, error) {
+	caCertificateContent, readError := os.ReadFile(caCertificateFile)
+	if readError != nil {
+		return nil, fmt.Errorf("lecture de l'autorité de certification %s impossible: %v", caCertificateFile, readError)
+	}
+
+	certificateAuthorities := x509.NewCertPool()
+	if !certificateAuthorities.AppendCertsFromPEM(caCertificateContent) {
+		return nil, fmt.Errorf("aucun certificat PEM valide dans %s", caCertificateFile)
+	}
+	return certificateAuthorities, nil
+}
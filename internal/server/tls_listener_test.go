@@ -0,0 +1,197 @@
+package server
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// testCertificateAuthority est une autorité de certification générée pour un test
+type testCertificateAuthority struct {
+	certificate *x509.Certificate
+	privateKey  *ecdsa.PrivateKey
+	pemFile     string
+}
+
+// newTestCertificateAuthority crée une autorité auto-signée et écrit son certificat PEM dans un répertoire temporaire
+func newTestCertificateAuthority(t *testing.T, commonName string) *testCertificateAuthority {
+	t.Helper()
+	privateKey := generateTestPrivateKey(t)
+	certificateTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: commonName},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certificateContent, createError := x509.CreateCertificate(rand.Reader, certificateTemplate, certificateTemplate, &privateKey.PublicKey, privateKey)
+	if createError != nil {
+		t.Fatalf("création de l'autorité impossible: %v", createError)
+	}
+	certificate, _ := x509.ParseCertificate(certificateContent)
+
+	pemFile := filepath.Join(t.TempDir(), commonName+".pem")
+	writeTestPemFile(t, pemFile, "CERTIFICATE", certificateContent)
+	return &testCertificateAuthority{certificate: certificate, privateKey: privateKey, pemFile: pemFile}
+}
+
+// issueCertificate signe un certificat serveur (127.0.0.1, localhost) ou client et retourne ses fichiers PEM
+func (certificateAuthority *testCertificateAuthority) issueCertificate(t *testing.T, commonName string, extendedKeyUsage x509.ExtKeyUsage) (string, string) {
+	t.Helper()
+	privateKey := generateTestPrivateKey(t)
+	certificateTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{extendedKeyUsage},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	certificateContent, createError := x509.CreateCertificate(rand.Reader, certificateTemplate, certificateAuthority.certificate, &privateKey.PublicKey, certificateAuthority.privateKey)
+	if createError != nil {
+		t.Fatalf("création du certificat %s impossible: %v", commonName, createError)
+	}
+	privateKeyContent, marshalError := x509.MarshalECPrivateKey(privateKey)
+	if marshalError != nil {
+		t.Fatalf("encodage de la clé %s impossible: %v", commonName, marshalError)
+	}
+
+	certificateDirectory := t.TempDir()
+	certificateFile := filepath.Join(certificateDirectory, commonName+".crt")
+	keyFile := filepath.Join(certificateDirectory, commonName+".key")
+	writeTestPemFile(t, certificateFile, "CERTIFICATE", certificateContent)
+	writeTestPemFile(t, keyFile, "EC PRIVATE KEY", privateKeyContent)
+	return certificateFile, keyFile
+}
+
+// generateTestPrivateKey génère une clé ECDSA P-256
+func generateTestPrivateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	privateKey, generateError := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if generateError != nil {
+		t.Fatalf("génération de clé impossible: %v", generateError)
+	}
+	return privateKey
+}
+
+// writeTestPemFile écrit un bloc PEM dans un fichier
+func writeTestPemFile(t *testing.T, pemFile string, blockType string, blockContent []byte) {
+	t.Helper()
+	pemContent := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: blockContent})
+	if writeError := os.WriteFile(pemFile, pemContent, 0o600); writeError != nil {
+		t.Fatalf("écriture de %s impossible: %v", pemFile, writeError)
+	}
+}
+
+// dialTestTls ouvre une connexion TLS vers le serveur, avec un certificat client si certificateFile n'est pas vide
+func dialTestTls(t *testing.T, tlsPort int, certificateAuthority *testCertificateAuthority, certificateFile string, keyFile string) (*tls.Conn, error) {
+	t.Helper()
+	trustedAuthorities := x509.NewCertPool()
+	trustedAuthorities.AddCert(certificateAuthority.certificate)
+	tlsConfiguration := &tls.Config{RootCAs: trustedAuthorities, ServerName: "localhost"}
+	if certificateFile != "" {
+		clientCertificate, loadError := tls.LoadX509KeyPair(certificateFile, keyFile)
+		if loadError != nil {
+			t.Fatalf("chargement du certificat client impossible: %v", loadError)
+		}
+		tlsConfiguration.Certificates = []tls.Certificate{clientCertificate}
+	}
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return tls.DialWithDialer(dialer, "tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(tlsPort)), tlsConfiguration)
+}
+
+func TestTlsPortCoexistsWithPlainPort(t *testing.T) {
+	certificateAuthority := newTestCertificateAuthority(t, "redis-go-test-ca")
+	serverCertificateFile, serverKeyFile := certificateAuthority.issueCertificate(t, "redis-go-server", x509.ExtKeyUsageServerAuth)
+
+	serverConfiguration := newTestServerConfiguration(t)
+	serverConfiguration.NetworkConfiguration.TlsPortNumber = reserveFreePort(t)
+	serverConfiguration.NetworkConfiguration.TlsCertificateFile = serverCertificateFile
+	serverConfiguration.NetworkConfiguration.TlsKeyFile = serverKeyFile
+	startTestServer(t, serverConfiguration)
+
+	plainConnection, dialError := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(serverConfiguration.NetworkConfiguration.PortNumber)))
+	if dialError != nil {
+		t.Fatalf("connexion au port en clair impossible: %v", dialError)
+	}
+	defer plainConnection.Close()
+	plainReader := bufio.NewReader(plainConnection)
+	if reply, commandError := sendTestCommand(t, plainConnection, plainReader, "SET", "shared", "plain"); commandError != nil || reply != "+OK" {
+		t.Fatalf("SET sur le port en clair: réponse %q, erreur %v", reply, commandError)
+	}
+
+	tlsConnection, dialError := dialTestTls(t, serverConfiguration.NetworkConfiguration.TlsPortNumber, certificateAuthority, "", "")
+	if dialError != nil {
+		t.Fatalf("connexion TLS impossible: %v", dialError)
+	}
+	defer tlsConnection.Close()
+	tlsReader := bufio.NewReader(tlsConnection)
+	if reply, commandError := sendTestCommand(t, tlsConnection, tlsReader, "GET", "shared"); commandError != nil || reply != "$5" {
+		t.Fatalf("GET sur le port TLS: réponse %q, erreur %v", reply, commandError)
+	}
+	if value, _ := tlsReader.ReadString('\n'); value != "plain\r\n" {
+		t.Fatalf("GET sur le port TLS: valeur %q, attendu la valeur écrite sur le port en clair", value)
+	}
+}
+
+func TestMutualTlsClientAuthentication(t *testing.T) {
+	certificateAuthority := newTestCertificateAuthority(t, "redis-go-test-ca")
+	serverCertificateFile, serverKeyFile := certificateAuthority.issueCertificate(t, "redis-go-server", x509.ExtKeyUsageServerAuth)
+	clientCertificateFile, clientKeyFile := certificateAuthority.issueCertificate(t, "redis-go-client", x509.ExtKeyUsageClientAuth)
+	foreignAuthority := newTestCertificateAuthority(t, "redis-go-foreign-ca")
+	foreignCertificateFile, foreignKeyFile := foreignAuthority.issueCertificate(t, "redis-go-foreign-client", x509.ExtKeyUsageClientAuth)
+
+	serverConfiguration := newTestServerConfiguration(t)
+	serverConfiguration.NetworkConfiguration.TlsPortNumber = reserveFreePort(t)
+	serverConfiguration.NetworkConfiguration.TlsCertificateFile = serverCertificateFile
+	serverConfiguration.NetworkConfiguration.TlsKeyFile = serverKeyFile
+	serverConfiguration.NetworkConfiguration.TlsCaCertificateFile = certificateAuthority.pemFile
+	serverConfiguration.NetworkConfiguration.TlsAuthenticateClients = true
+	startTestServer(t, serverConfiguration)
+
+	testCases := []struct {
+		name             string
+		certificateFile  string
+		keyFile          string
+		expectedAccepted bool
+	}{
+		{name: "sans certificat client", expectedAccepted: false},
+		{name: "certificat d'une autre autorité", certificateFile: foreignCertificateFile, keyFile: foreignKeyFile, expectedAccepted: false},
+		{name: "certificat signé par l'autorité", certificateFile: clientCertificateFile, keyFile: clientKeyFile, expectedAccepted: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			tlsConnection, dialError := dialTestTls(t, serverConfiguration.NetworkConfiguration.TlsPortNumber, certificateAuthority, testCase.certificateFile, testCase.keyFile)
+			var reply string
+			commandError := dialError
+			if dialError == nil {
+				defer tlsConnection.Close()
+				// En TLS 1.3 le refus du certificat client n'apparaît qu'à la première lecture
+				reply, commandError = sendTestCommand(t, tlsConnection, bufio.NewReader(tlsConnection), "PING")
+			}
+
+			if testCase.expectedAccepted && (commandError != nil || reply != "+PONG") {
+				t.Fatalf("PING: réponse %q, erreur %v, attendu +PONG", reply, commandError)
+			}
+			if !testCase.expectedAccepted && commandError == nil {
+				t.Fatalf("PING: réponse %q, attendu le refus de la connexion", reply)
+			}
+		})
+	}
+}
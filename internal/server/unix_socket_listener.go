@@ -0,0 +1,35 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+)
+
+// openUnixSocketListener écoute sur un socket Unix et applique ses permissions
+// Un socket laissé par un arrêt brutal est supprimé ; tout autre fichier existant est conservé (erreur)
+// Le fichier du socket est supprimé à la fermeture du listener
+func openUnixSocketListener(socketPath string, socketPermissions os.FileMode) (net.Listener, error) {
+	if existingFileInfo, statError := os.Lstat(socketPath); statError == nil {
+		if existingFileInfo.Mode()&os.ModeSocket == 0 {
+			return nil, fmt.Errorf("%s existe déjà et n'est pas un socket Unix", socketPath)
+		}
+		if removeError := os.Remove(socketPath); removeError != nil {
+			return nil, fmt.Errorf("suppression de l'ancien socket %s impossible: %v", socketPath, removeError)
+		}
+	} else if !errors.Is(statError, os.ErrNotExist) {
+		return nil, fmt.Errorf("accès au socket %s impossible: %v", socketPath, statError)
+	}
+
+	unixListener, listenError := net.Listen("unix", socketPath)
+	if listenError != nil {
+		return nil, fmt.Errorf("impossible d'écouter sur le socket Unix %s: %v", socketPath, listenError)
+	}
+	if chmodError := os.Chmod(socketPath, socketPermissions); chmodError != nil {
+		unixListener.Close()
+		return nil, fmt.Errorf("application des permissions %#o au socket %s impossible: %v", socketPermissions, socketPath, chmodError)
+	}
+
+	return unixListener, nil
+}
@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// dialTestUnixClient ouvre une connexion vers le socket Unix du serveur, fermée à la fin du test
+func dialTestUnixClient(t *testing.T, socketPath string) *testClient {
+	t.Helper()
+	clientConnection, dialError := net.Dial("unix", socketPath)
+	if dialError != nil {
+		t.Fatalf("connexion au socket %s impossible: %v", socketPath, dialError)
+	}
+	t.Cleanup(func() { clientConnection.Close() })
+	return &testClient{t: t, clientConnection: clientConnection, replyReader: bufio.NewReader(clientConnection)}
+}
+
+func TestUnixSocketCoexistsWithTcpPort(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	serverConfiguration.NetworkConfiguration.UnixSocketPath = filepath.Join(t.TempDir(), "redis.sock")
+	serverConfiguration.NetworkConfiguration.UnixSocketPermissions = 0o660
+	startTestServer(t, serverConfiguration)
+
+	socketInfo, statError := os.Stat(serverConfiguration.NetworkConfiguration.UnixSocketPath)
+	if statError != nil || socketInfo.Mode()&os.ModeSocket == 0 {
+		t.Fatalf("socket Unix absent (%v)", statError)
+	}
+	if socketInfo.Mode().Perm() != 0o660 {
+		t.Fatalf("permissions du socket %#o, attendu 0660", socketInfo.Mode().Perm())
+	}
+
+	unixClient := dialTestUnixClient(t, serverConfiguration.NetworkConfiguration.UnixSocketPath)
+	tcpClient := dialTestClient(t, serverConfiguration.NetworkConfiguration.PortNumber)
+	runClientSteps(t, []*testClient{unixClient, tcpClient}, []clientTestStep{
+		{0, "SET k socket", "+OK\r\n"},
+		{1, "GET k", bulk("socket")},
+		{0, "CLIENT INFO", "$*"},
+		{1, "CLIENT LIST", "$*"},
+	})
+	if clientList := tcpClient.execute("CLIENT", "LIST"); strings.Count(clientList, "id=") != 2 {
+		t.Fatalf("CLIENT LIST doit compter les deux connexions: %q", clientList)
+	}
+}
+
+func TestMaximumConnectionsSharedAcrossListeners(t *testing.T) {
+	serverConfiguration := newTestServerConfiguration(t)
+	serverConfiguration.NetworkConfiguration.UnixSocketPath = filepath.Join(t.TempDir(), "redis.sock")
+	serverConfiguration.NetworkConfiguration.UnixSocketPermissions = 0o700
+	serverConfiguration.PerformanceConfiguration.MaximumConnections = 1
+	startTestServer(t, serverConfiguration)
+
+	// La connexion de sonde de startTestServer peut encore occuper la place : la connexion TCP est retentée
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(10 * time.Millisecond) {
+		tcpConnection, dialError := net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(serverConfiguration.NetworkConfiguration.PortNumber)))
+		if dialError != nil {
+			t.Fatalf("connexion TCP impossible: %v", dialError)
+		}
+		if pingReply, _ := sendTestCommand(t, tcpConnection, bufio.NewReader(tcpConnection), "PING"); pingReply == "+PONG" {
+			t.Cleanup(func() { tcpConnection.Close() })
+			break
+		}
+		tcpConnection.Close()
+		if time.Now().After(deadline) {
+			t.Fatalf("aucune connexion TCP acceptée")
+		}
+	}
+
+	// La connexion TCP occupe la seule place : le socket Unix est refusé
+	unixClient := dialTestUnixClient(t, serverConfiguration.NetworkConfiguration.UnixSocketPath)
+	unixClient.clientConnection.SetDeadline(time.Now().Add(5 * time.Second))
+	if _, readError := unixClient.replyReader.ReadByte(); readError != io.EOF {
+		t.Fatalf("connexion Unix acceptée au-delà de la limite (%v)", readError)
+	}
+}
+
+func TestOpenUnixSocketListener(t *testing.T) {
+	testCases := []struct {
+		name          string
+		prepareFile   func(t *testing.T, socketPath string)
+		expectedError string
+	}{
+		{name: "nouveau socket", prepareFile: func(t *testing.T, socketPath string) {}},
+		{
+			name: "socket laissé par un arrêt brutal",
+			prepareFile: func(t *testing.T, socketPath string) {
+				staleListener, listenError := net.Listen("unix", socketPath)
+				if listenError != nil {
+					t.Fatalf("création du socket impossible: %v", listenError)
+				}
+				staleListener.(*net.UnixListener).SetUnlinkOnClose(false)
+				staleListener.Close()
+			},
+		},
+		{
+			name: "fichier ordinaire conservé",
+			prepareFile: func(t *testing.T, socketPath string) {
+				if writeError := os.WriteFile(socketPath, []byte("données"), 0o600); writeError != nil {
+					t.Fatalf("création du fichier impossible: %v", writeError)
+				}
+			},
+			expectedError: "existe déjà et n'est pas un socket Unix",
+		},
+		{
+			name: "répertoire absent",
+			prepareFile: func(t *testing.T, socketPath string) {
+				os.Remove(filepath.Dir(socketPath))
+			},
+			expectedError: "impossible d'écouter sur le socket Unix",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			socketPath := filepath.Join(t.TempDir(), "redis.sock")
+			testCase.prepareFile(t, socketPath)
+
+			unixListener, listenError := openUnixSocketListener(socketPath, 0o700)
+			if testCase.expectedError != "" {
+				if listenError == nil || !strings.Contains(listenError.Error(), testCase.expectedError) {
+					t.Fatalf("erreur %v, attendu %q", listenError, testCase.expectedError)
+				}
+				return
+			}
+			if listenError != nil {
+				t.Fatalf("ouverture du socket impossible: %v", listenError)
+			}
+			unixListener.Close()
+			if _, statError := os.Lstat(socketPath); !os.IsNotExist(statError) {
+				t.Fatalf("le socket doit être supprimé à la fermeture du listener (%v)", statError)
+			}
+		})
+	}
+}
@@ -28,9 +28,29 @@ type RedisListStructure struct {
 // RedisSetStructure représente un set Redis
 type RedisSetStructure struct {
 	SetElements map[string]bool
+	// memberScanIndex répartit les membres en buckets pour SSCAN une fois le set devenu grand (nil avant)
+	memberScanIndex *keyspaceScanIndex
 }
 
 // RedisHashStructure représente un hash Redis
 type RedisHashStructure struct {
 	HashFields map[string]string
+	// FieldExpirations associe aux fields ayant un TTL leur date d'expiration (nil si aucun field n'expire)
+	FieldExpirations map[string]time.Time
+	// memberScanIndex répartit les fields en buckets pour HSCAN une fois le hash devenu grand (nil avant)
+	memberScanIndex *keyspaceScanIndex
+}
+
+// RedisSortedSetStructure représente un sorted set Redis (dictionnaire + skiplist ordonnée par score)
+type RedisSortedSetStructure struct {
+	MemberScores  map[string]float64
+	scoreSkipList *sortedSetSkipList
+	// memberScanIndex répartit les membres en buckets pour ZSCAN une fois le sorted set devenu grand (nil avant)
+	memberScanIndex *keyspaceScanIndex
+}
+
+// SortedSetMember représente un membre de sorted set avec son score
+type SortedSetMember struct {
+	MemberName  string
+	MemberScore float64
 }
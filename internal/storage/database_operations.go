@@ -0,0 +1,71 @@
+package storage
+
+// NewRedisDatabaseStorages crée les bases de données logiques d'un serveur (index 0 à databaseCount-1)
+func NewRedisDatabaseStorages(databaseCount int) []*RedisInMemoryStorage {
+	databaseStorages := make([]*RedisInMemoryStorage, databaseCount)
+	for databaseIndex := range databaseStorages {
+		databaseStorages[databaseIndex] = NewRedisDatabaseStorage(databaseIndex)
+	}
+	return databaseStorages
+}
+
+// lockStoragePair verrouille deux bases en écriture dans l'ordre de leur index (évite les interblocages)
+func lockStoragePair(firstStorage *RedisInMemoryStorage, secondStorage *RedisInMemoryStorage) func() {
+	if firstStorage.databaseIndex > secondStorage.databaseIndex {
+		firstStorage, secondStorage = secondStorage, firstStorage
+	}
+	firstStorage.storageMutex.Lock()
+	secondStorage.storageMutex.Lock()
+	return func() {
+		secondStorage.storageMutex.Unlock()
+		firstStorage.storageMutex.Unlock()
+	}
+}
+
+// MoveKeyToStorage déplace une clé (avec son TTL) vers une autre base (MOVE)
+// Retourne false si la clé n'existe pas ou existe déjà dans la base de destination
+func (redisStorage *RedisInMemoryStorage) MoveKeyToStorage(storageKey string, destinationStorage *RedisInMemoryStorage) bool {
+	if destinationStorage == redisStorage {
+		return false
+	}
+	unlockStorages := lockStoragePair(redisStorage, destinationStorage)
+	defer unlockStorages()
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	destinationStorage.removeKeyIfExpired(storageKey)
+
+	storageValue, sourceKeyExists := redisStorage.storageData[storageKey]
+	if !sourceKeyExists {
+		return false
+	}
+	if _, destinationKeyExists := destinationStorage.storageData[storageKey]; destinationKeyExists {
+		return false
+	}
+
+	redisStorage.removeEntry(storageKey)
+	destinationStorage.storeEntry(storageKey, storageValue)
+	redisStorage.markKeyModified(storageKey)
+	destinationStorage.markKeyModified(storageKey)
+	if storageValue.DataType == RedisListType {
+		destinationStorage.signalListReady(storageKey)
+	}
+	return true
+}
+
+// SwapStorageContents échange le contenu de deux bases (SWAPDB)
+// Les instances ne changent pas d'index : les connexions voient immédiatement le nouveau contenu
+func (redisStorage *RedisInMemoryStorage) SwapStorageContents(otherStorage *RedisInMemoryStorage) {
+	if otherStorage == redisStorage {
+		return
+	}
+	unlockStorages := lockStoragePair(redisStorage, otherStorage)
+	defer unlockStorages()
+
+	redisStorage.storageData, otherStorage.storageData = otherStorage.storageData, redisStorage.storageData
+	redisStorage.keyScanIndex, otherStorage.keyScanIndex = otherStorage.keyScanIndex, redisStorage.keyScanIndex
+	redisStorage.markAllWatchedKeysModified()
+	otherStorage.markAllWatchedKeysModified()
+	// Les clients bloqués restent sur leur base : les listes qu'ils attendent ont pu y apparaître
+	redisStorage.signalAllWaitedListsReady()
+	otherStorage.signalAllWaitedListsReady()
+}
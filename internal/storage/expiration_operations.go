@@ -0,0 +1,90 @@
+package storage
+
+import "time"
+
+// KeyExpirationOptions regroupe les conditions de EXPIRE (NX, XX, GT, LT)
+type KeyExpirationOptions struct {
+	OnlyIfNoExpiration  bool
+	OnlyIfHasExpiration bool
+	OnlyIfGreater       bool
+	OnlyIfLess          bool
+}
+
+// isSatisfiedBy vérifie si une nouvelle expiration peut remplacer l'expiration actuelle
+// Une clé sans expiration est considérée comme ayant un TTL infini (pour GT et LT)
+func (expirationOptions KeyExpirationOptions) isSatisfiedBy(currentExpiration *time.Time, newExpiration time.Time) bool {
+	if expirationOptions.OnlyIfNoExpiration && currentExpiration != nil {
+		return false
+	}
+	if expirationOptions.OnlyIfHasExpiration && currentExpiration == nil {
+		return false
+	}
+	if expirationOptions.OnlyIfGreater && (currentExpiration == nil || !newExpiration.After(*currentExpiration)) {
+		return false
+	}
+	if expirationOptions.OnlyIfLess && currentExpiration != nil && !newExpiration.Before(*currentExpiration) {
+		return false
+	}
+	return true
+}
+
+// SetKeyExpiration définit la date d'expiration d'une clé de n'importe quel type
+// expirationApplied est true si l'expiration a été appliquée, keyDeleted si sa date passée a supprimé la clé
+func (redisStorage *RedisInMemoryStorage) SetKeyExpiration(storageKey string, expirationTime time.Time, expirationOptions KeyExpirationOptions) (expirationApplied bool, keyDeleted bool) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	storageValue, keyExists := redisStorage.storageData[storageKey]
+	if !keyExists {
+		return false, false
+	}
+
+	if !expirationOptions.isSatisfiedBy(storageValue.ExpirationTime, expirationTime) {
+		return false, false
+	}
+
+	keyDeleted = !expirationTime.After(time.Now())
+	if keyDeleted {
+		redisStorage.removeEntry(storageKey)
+	} else {
+		storageValue.ExpirationTime = &expirationTime
+	}
+	redisStorage.markKeyModified(storageKey)
+	return true, keyDeleted
+}
+
+// GetKeyExpiration retourne la date d'expiration d'une clé (nil si pas d'expiration)
+// keyExists est false si la clé n'existe pas ou a expiré
+func (redisStorage *RedisInMemoryStorage) GetKeyExpiration(storageKey string) (expirationTime *time.Time, keyExists bool) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	storageValue := redisStorage.getLiveStorageValue(storageKey)
+	if storageValue == nil {
+		return nil, false
+	}
+
+	if storageValue.ExpirationTime == nil {
+		return nil, true
+	}
+
+	expirationCopy := *storageValue.ExpirationTime
+	return &expirationCopy, true
+}
+
+// PersistKey supprime l'expiration d'une clé, retourne true si une expiration a été retirée
+func (redisStorage *RedisInMemoryStorage) PersistKey(storageKey string) bool {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	storageValue, keyExists := redisStorage.storageData[storageKey]
+	if !keyExists || storageValue.ExpirationTime == nil {
+		return false
+	}
+
+	storageValue.ExpirationTime = nil
+	redisStorage.markKeyModified(storageKey)
+	return true
+}
@@ -0,0 +1,60 @@
+package storage
+
+import (
+	"math/big"
+	"strings"
+)
+
+// Les incréments décimaux (INCRBYFLOAT, HINCRBYFLOAT) reproduisent le long double de Redis :
+// mantisse de 64 bits, exposant binaire limité à 16384 et affichage avec 17 décimales sans zéros superflus
+const (
+	longDoubleMantissaBits    = 64
+	longDoubleMaximumExponent = 16384
+	longDoubleFractionDigits  = 17
+)
+
+// ParseFloatValue lit un nombre décimal stocké dans une chaîne ou un field, ou un incrément (INCRBYFLOAT, HINCRBYFLOAT)
+// Comme Redis, une chaîne vide, des espaces, NaN et les valeurs infinies sont refusés
+func ParseFloatValue(storedValue string) (*big.Float, error) {
+	if storedValue == "" || strings.TrimSpace(storedValue) != storedValue {
+		return nil, ErrValueNotFloat
+	}
+	parsedValue, _, parseError := big.ParseFloat(storedValue, 10, longDoubleMantissaBits, big.ToNearestEven)
+	if parseError != nil || parsedValue.IsInf() || parsedValue.MantExp(nil) > longDoubleMaximumExponent {
+		return nil, ErrValueNotFloat
+	}
+	return parsedValue, nil
+}
+
+// FormatFloatValue formate un nombre décimal comme Redis : 17 décimales au plus, sans exposant ni zéros superflus
+// (3.0 devient "3", 0.1 + 0.2 devient "0.3", 1e20 devient "100000000000000000000")
+func FormatFloatValue(floatValue *big.Float) string {
+	formattedValue := floatValue.Text('f', longDoubleFractionDigits)
+	if strings.Contains(formattedValue, ".") {
+		formattedValue = strings.TrimRight(strings.TrimRight(formattedValue, "0"), ".")
+	}
+	if formattedValue == "-0" {
+		return "0"
+	}
+	return formattedValue
+}
+
+// incrementFloatValue ajoute un incrément à une valeur stockée (absente : 0) et retourne le résultat formaté
+// Retourne ErrValueNotFloat si la valeur n'est pas un nombre, ErrIncrementNotFinite si le résultat dépasse le long double
+func incrementFloatValue(currentValue string, valueExists bool, floatIncrement *big.Float) (string, error) {
+	incrementedValue := new(big.Float).SetPrec(longDoubleMantissaBits).SetMode(big.ToNearestEven)
+	if valueExists {
+		parsedValue, parseError := ParseFloatValue(currentValue)
+		if parseError != nil {
+			return "", parseError
+		}
+		incrementedValue.Add(parsedValue, floatIncrement)
+	} else {
+		incrementedValue.Set(floatIncrement)
+	}
+
+	if incrementedValue.MantExp(nil) > longDoubleMaximumExponent {
+		return "", ErrIncrementNotFinite
+	}
+	return FormatFloatValue(incrementedValue), nil
+}
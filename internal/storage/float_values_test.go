@@ -0,0 +1,69 @@
+package storage
+
+import "testing"
+
+func TestParseFloatValue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		storedValue   string
+		expectedValue string
+		expectedError error
+	}{
+		{name: "entier", storedValue: "42", expectedValue: "42"},
+		{name: "signe explicite", storedValue: "+5", expectedValue: "5"},
+		{name: "sans partie entière", storedValue: ".5", expectedValue: "0.5"},
+		{name: "exposant", storedValue: "5.0e3", expectedValue: "5000"},
+		{name: "grande valeur sans exposant", storedValue: "1e20", expectedValue: "100000000000000000000"},
+		{name: "chaîne vide", storedValue: "", expectedError: ErrValueNotFloat},
+		{name: "espaces", storedValue: " 1.5", expectedError: ErrValueNotFloat},
+		{name: "texte", storedValue: "abc", expectedError: ErrValueNotFloat},
+		{name: "NaN", storedValue: "nan", expectedError: ErrValueNotFloat},
+		{name: "infini", storedValue: "-inf", expectedError: ErrValueNotFloat},
+		{name: "au-delà d'un long double", storedValue: "1e4933", expectedError: ErrValueNotFloat},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			parsedValue, parseError := ParseFloatValue(testCase.storedValue)
+			if parseError != testCase.expectedError {
+				t.Fatalf("%q: erreur %v, attendu %v", testCase.storedValue, parseError, testCase.expectedError)
+			}
+			if parseError == nil && FormatFloatValue(parsedValue) != testCase.expectedValue {
+				t.Fatalf("%q: valeur %s, attendu %s", testCase.storedValue, FormatFloatValue(parsedValue), testCase.expectedValue)
+			}
+		})
+	}
+}
+
+func TestIncrementFloatValue(t *testing.T) {
+	testCases := []struct {
+		name           string
+		currentValue   string
+		valueExists    bool
+		floatIncrement string
+		expectedValue  string
+		expectedError  error
+	}{
+		{name: "valeur absente", floatIncrement: "10.50", expectedValue: "10.5"},
+		{name: "exemple de la documentation Redis", currentValue: "10.50", valueExists: true, floatIncrement: "0.1", expectedValue: "10.6"},
+		{name: "notation exponentielle", currentValue: "5.0e3", valueExists: true, floatIncrement: "2.0e2", expectedValue: "5200"},
+		{name: "arrondi du long double", currentValue: "0.1", valueExists: true, floatIncrement: "0.2", expectedValue: "0.3"},
+		{name: "résultat négatif", currentValue: "1", valueExists: true, floatIncrement: "-1.5", expectedValue: "-0.5"},
+		{name: "zéro sans signe", currentValue: "-1e-18", valueExists: true, floatIncrement: "0", expectedValue: "0"},
+		{name: "plus de 17 décimales", currentValue: "0", valueExists: true, floatIncrement: "1e-18", expectedValue: "0"},
+		{name: "valeur stockée invalide", currentValue: "abc", valueExists: true, floatIncrement: "1", expectedError: ErrValueNotFloat},
+		{name: "valeur stockée vide", currentValue: "", valueExists: true, floatIncrement: "1", expectedError: ErrValueNotFloat},
+		{name: "dépassement du long double", currentValue: "1e4932", valueExists: true, floatIncrement: "1e4932", expectedError: ErrIncrementNotFinite},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			floatIncrement, parseError := ParseFloatValue(testCase.floatIncrement)
+			if parseError != nil {
+				t.Fatalf("incrément %q invalide: %v", testCase.floatIncrement, parseError)
+			}
+			incrementedValue, incrementError := incrementFloatValue(testCase.currentValue, testCase.valueExists, floatIncrement)
+			if incrementError != testCase.expectedError || incrementedValue != testCase.expectedValue {
+				t.Fatalf("%q + %q: %q (%v), attendu %q (%v)", testCase.currentValue, testCase.floatIncrement, incrementedValue, incrementError, testCase.expectedValue, testCase.expectedError)
+			}
+		})
+	}
+}
@@ -0,0 +1,184 @@
+package storage
+
+import "time"
+
+// Résultats par field de HEXPIRE et HPERSIST (codes retournés par Redis)
+const (
+	HashFieldNotFound          = -2 // le field (ou la clé) n'existe pas
+	HashFieldWithoutExpiration = -1 // HPERSIST : le field n'a pas de TTL
+	HashFieldConditionNotMet   = 0  // HEXPIRE : condition NX, XX, GT ou LT non satisfaite
+	HashFieldExpirationUpdated = 1  // TTL appliqué (HEXPIRE) ou retiré (HPERSIST)
+	HashFieldDeleted           = 2  // HEXPIRE : date passée, le field a été supprimé
+)
+
+// isFieldLive indique si un field existe et n'a pas expiré
+func (redisHashStructure *RedisHashStructure) isFieldLive(fieldName string, currentTime time.Time) bool {
+	if _, fieldExists := redisHashStructure.HashFields[fieldName]; !fieldExists {
+		return false
+	}
+	expirationTime, hasExpiration := redisHashStructure.FieldExpirations[fieldName]
+	return !hasExpiration || currentTime.Before(expirationTime)
+}
+
+// liveFieldCount retourne le nombre de fields non expirés
+func (redisHashStructure *RedisHashStructure) liveFieldCount(currentTime time.Time) int {
+	liveFieldCount := len(redisHashStructure.HashFields)
+	for _, expirationTime := range redisHashStructure.FieldExpirations {
+		if !currentTime.Before(expirationTime) {
+			liveFieldCount--
+		}
+	}
+	return liveFieldCount
+}
+
+// deleteField supprime un field, son éventuel TTL et sa place dans l'index de HSCAN
+func (redisHashStructure *RedisHashStructure) deleteField(fieldName string) {
+	if _, fieldExists := redisHashStructure.HashFields[fieldName]; fieldExists {
+		delete(redisHashStructure.HashFields, fieldName)
+		unindexRemovedMember(redisHashStructure.memberScanIndex, fieldName)
+	}
+	redisHashStructure.persistField(fieldName)
+}
+
+// persistField retire le TTL d'un field
+func (redisHashStructure *RedisHashStructure) persistField(fieldName string) {
+	delete(redisHashStructure.FieldExpirations, fieldName)
+	if len(redisHashStructure.FieldExpirations) == 0 {
+		redisHashStructure.FieldExpirations = nil
+	}
+}
+
+// removeExpiredHashFields supprime les fields expirés d'un hash, puis la clé si le hash est vide
+// (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) removeExpiredHashFields(hashKey string, redisHashStructure *RedisHashStructure, currentTime time.Time) {
+	expiredFieldCount := 0
+	for fieldName, expirationTime := range redisHashStructure.FieldExpirations {
+		if !currentTime.Before(expirationTime) {
+			redisHashStructure.deleteField(fieldName)
+			expiredFieldCount++
+		}
+	}
+	if expiredFieldCount == 0 {
+		return
+	}
+	if len(redisHashStructure.HashFields) == 0 {
+		redisStorage.removeEntry(hashKey)
+	}
+	redisStorage.markKeyModified(hashKey)
+}
+
+// SetHashFieldsExpiration applique une date d'expiration à des fields d'un hash (HEXPIRE, HPEXPIRE, HEXPIREAT, HPEXPIREAT)
+// Retourne un code par field (HashFieldNotFound, HashFieldConditionNotMet, HashFieldExpirationUpdated ou HashFieldDeleted) ;
+// une date passée supprime les fields, et la clé avec son dernier field
+func (redisStorage *RedisInMemoryStorage) SetHashFieldsExpiration(hashKey string, fieldNames []string, expirationTime time.Time, expirationOptions KeyExpirationOptions) ([]int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	expirationResults := make([]int, len(fieldNames))
+	redisHashStructure, hashError := redisStorage.getHashForUpdate(hashKey)
+	if hashError != nil {
+		return nil, hashError
+	}
+
+	fieldsModified := false
+	expirationIsPast := !expirationTime.After(time.Now())
+	for fieldIndex, fieldName := range fieldNames {
+		if redisHashStructure == nil {
+			expirationResults[fieldIndex] = HashFieldNotFound
+			continue
+		}
+		if _, fieldExists := redisHashStructure.HashFields[fieldName]; !fieldExists {
+			expirationResults[fieldIndex] = HashFieldNotFound
+			continue
+		}
+
+		var currentExpiration *time.Time
+		if fieldExpiration, hasExpiration := redisHashStructure.FieldExpirations[fieldName]; hasExpiration {
+			currentExpiration = &fieldExpiration
+		}
+		if !expirationOptions.isSatisfiedBy(currentExpiration, expirationTime) {
+			expirationResults[fieldIndex] = HashFieldConditionNotMet
+			continue
+		}
+
+		if expirationIsPast {
+			redisHashStructure.deleteField(fieldName)
+			expirationResults[fieldIndex] = HashFieldDeleted
+		} else {
+			if redisHashStructure.FieldExpirations == nil {
+				redisHashStructure.FieldExpirations = make(map[string]time.Time)
+			}
+			redisHashStructure.FieldExpirations[fieldName] = expirationTime
+			expirationResults[fieldIndex] = HashFieldExpirationUpdated
+		}
+		fieldsModified = true
+	}
+
+	if fieldsModified {
+		if len(redisHashStructure.HashFields) == 0 {
+			redisStorage.removeEntry(hashKey)
+		}
+		redisStorage.markKeyModified(hashKey)
+	}
+	return expirationResults, nil
+}
+
+// GetHashFieldsExpiration retourne la date d'expiration de fields d'un hash (HTTL, HPTTL)
+// Une date nulle indique un field sans TTL ; fieldsFound est false pour un field absent ou expiré
+func (redisStorage *RedisInMemoryStorage) GetHashFieldsExpiration(hashKey string, fieldNames []string) ([]time.Time, []bool, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisHashStructure, hashError := redisStorage.getLiveHash(hashKey)
+	if hashError != nil {
+		return nil, nil, hashError
+	}
+
+	currentTime := time.Now()
+	expirationTimes := make([]time.Time, len(fieldNames))
+	fieldsFound := make([]bool, len(fieldNames))
+	if redisHashStructure != nil {
+		for fieldIndex, fieldName := range fieldNames {
+			if redisHashStructure.isFieldLive(fieldName, currentTime) {
+				expirationTimes[fieldIndex] = redisHashStructure.FieldExpirations[fieldName]
+				fieldsFound[fieldIndex] = true
+			}
+		}
+	}
+	return expirationTimes, fieldsFound, nil
+}
+
+// PersistHashFields retire le TTL de fields d'un hash (HPERSIST)
+// Retourne un code par field : HashFieldNotFound, HashFieldWithoutExpiration ou HashFieldExpirationUpdated
+func (redisStorage *RedisInMemoryStorage) PersistHashFields(hashKey string, fieldNames []string) ([]int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisHashStructure, hashError := redisStorage.getHashForUpdate(hashKey)
+	if hashError != nil {
+		return nil, hashError
+	}
+
+	persistResults := make([]int, len(fieldNames))
+	persistedFieldCount := 0
+	for fieldIndex, fieldName := range fieldNames {
+		switch {
+		case redisHashStructure == nil:
+			persistResults[fieldIndex] = HashFieldNotFound
+		case !redisHashStructure.isFieldLive(fieldName, time.Now()):
+			persistResults[fieldIndex] = HashFieldNotFound
+		default:
+			if _, hasExpiration := redisHashStructure.FieldExpirations[fieldName]; !hasExpiration {
+				persistResults[fieldIndex] = HashFieldWithoutExpiration
+				continue
+			}
+			redisHashStructure.persistField(fieldName)
+			persistResults[fieldIndex] = HashFieldExpirationUpdated
+			persistedFieldCount++
+		}
+	}
+	if persistedFieldCount > 0 {
+		redisStorage.markKeyModified(hashKey)
+	}
+	return persistResults, nil
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestHashFieldExpiration(t *testing.T) {
+	testCases := []struct {
+		name string
+		// expiredFields reçoivent un TTL très court, rewrittenFields sont redéfinis par HSET avant leur expiration
+		expiredFields   []string
+		rewrittenFields []string
+		runCollector    bool
+		expectedFields  []string
+		expectedCleaned int
+		// expectedStored est le nombre de fields encore en mémoire, expirés compris (suppression paresseuse)
+		expectedStored int
+	}{
+		{
+			name:           "fields expirés invisibles avant leur suppression",
+			expiredFields:  []string{"a"},
+			expectedFields: []string{"b", "c"},
+			expectedStored: 3,
+		},
+		{
+			name:           "collecteur actif",
+			expiredFields:  []string{"a", "b"},
+			runCollector:   true,
+			expectedFields: []string{"c"},
+			expectedStored: 1,
+		},
+		{
+			name:            "la clé disparaît avec son dernier field",
+			expiredFields:   []string{"a", "b", "c"},
+			runCollector:    true,
+			expectedCleaned: 1,
+		},
+		{
+			name:            "HSET retire le TTL du field",
+			expiredFields:   []string{"a", "b"},
+			rewrittenFields: []string{"a"},
+			runCollector:    true,
+			expectedFields:  []string{"a", "c"},
+			expectedStored:  2,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			for _, fieldName := range []string{"a", "b", "c"} {
+				redisStorage.SetHashField("h", fieldName, "v")
+			}
+			expirationResults, expirationError := redisStorage.SetHashFieldsExpiration("h", testCase.expiredFields, time.Now().Add(10*time.Millisecond), KeyExpirationOptions{})
+			if expirationError != nil || slices.ContainsFunc(expirationResults, func(expirationResult int) bool { return expirationResult != HashFieldExpirationUpdated }) {
+				t.Fatalf("expiration non appliquée: %v (%v)", expirationResults, expirationError)
+			}
+			for _, fieldName := range testCase.rewrittenFields {
+				redisStorage.SetHashField("h", fieldName, "v")
+			}
+			time.Sleep(20 * time.Millisecond)
+
+			if testCase.runCollector {
+				if cleanedKeyCount := redisStorage.CleanupExpiredKeys(); cleanedKeyCount != testCase.expectedCleaned {
+					t.Fatalf("%d clés supprimées, attendu %d", cleanedKeyCount, testCase.expectedCleaned)
+				}
+			}
+			liveFields := slices.Sorted(maps.Keys(redisStorage.GetAllHashFields("h")))
+			if !slices.Equal(liveFields, testCase.expectedFields) {
+				t.Fatalf("fields %v, attendu %v", liveFields, testCase.expectedFields)
+			}
+			if hashLength, _ := redisStorage.GetHashLength("h"); hashLength != len(testCase.expectedFields) {
+				t.Fatalf("HLEN %d, attendu %d", hashLength, len(testCase.expectedFields))
+			}
+			if redisStorage.CheckKeyExists("h") != (len(testCase.expectedFields) > 0) {
+				t.Fatalf("la clé doit exister seulement s'il reste des fields")
+			}
+
+			storedFieldCount := 0
+			if storageValue, keyExists := redisStorage.storageData["h"]; keyExists {
+				storedFieldCount = len(storageValue.StoredData.(*RedisHashStructure).HashFields)
+			}
+			if storedFieldCount != testCase.expectedStored {
+				t.Fatalf("%d fields en mémoire, attendu %d", storedFieldCount, testCase.expectedStored)
+			}
+		})
+	}
+}
+
+func TestSetHashFieldsExpirationResults(t *testing.T) {
+	testCases := []struct {
+		name            string
+		fieldNames      []string
+		expirationDelay time.Duration
+		options         KeyExpirationOptions
+		expectedResults []int
+		expectedFields  int
+	}{
+		{name: "field absent", fieldNames: []string{"inconnu", "sansTTL"}, expirationDelay: time.Hour, expectedResults: []int{HashFieldNotFound, HashFieldExpirationUpdated}, expectedFields: 2},
+		{name: "NX", fieldNames: []string{"avecTTL", "sansTTL"}, expirationDelay: time.Hour, options: KeyExpirationOptions{OnlyIfNoExpiration: true}, expectedResults: []int{HashFieldConditionNotMet, HashFieldExpirationUpdated}, expectedFields: 2},
+		{name: "XX", fieldNames: []string{"avecTTL", "sansTTL"}, expirationDelay: time.Hour, options: KeyExpirationOptions{OnlyIfHasExpiration: true}, expectedResults: []int{HashFieldExpirationUpdated, HashFieldConditionNotMet}, expectedFields: 2},
+		{name: "GT sans TTL actuel", fieldNames: []string{"avecTTL", "sansTTL"}, expirationDelay: 2 * time.Hour, options: KeyExpirationOptions{OnlyIfGreater: true}, expectedResults: []int{HashFieldExpirationUpdated, HashFieldConditionNotMet}, expectedFields: 2},
+		{name: "LT sans TTL actuel", fieldNames: []string{"avecTTL", "sansTTL"}, expirationDelay: 2 * time.Hour, options: KeyExpirationOptions{OnlyIfLess: true}, expectedResults: []int{HashFieldConditionNotMet, HashFieldExpirationUpdated}, expectedFields: 2},
+		{name: "date passée", fieldNames: []string{"avecTTL"}, expirationDelay: -time.Second, expectedResults: []int{HashFieldDeleted}, expectedFields: 1},
+		{name: "date passée pour tous les fields", fieldNames: []string{"avecTTL", "sansTTL"}, expirationDelay: -time.Second, expectedResults: []int{HashFieldDeleted, HashFieldDeleted}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			redisStorage.SetHashField("h", "avecTTL", "v")
+			redisStorage.SetHashField("h", "sansTTL", "v")
+			redisStorage.SetHashFieldsExpiration("h", []string{"avecTTL"}, time.Now().Add(time.Hour+time.Minute), KeyExpirationOptions{})
+
+			expirationResults, expirationError := redisStorage.SetHashFieldsExpiration("h", testCase.fieldNames, time.Now().Add(testCase.expirationDelay), testCase.options)
+			if expirationError != nil || !slices.Equal(expirationResults, testCase.expectedResults) {
+				t.Fatalf("résultats %v (%v), attendu %v", expirationResults, expirationError, testCase.expectedResults)
+			}
+			if hashLength, _ := redisStorage.GetHashLength("h"); hashLength != testCase.expectedFields {
+				t.Fatalf("HLEN %d, attendu %d", hashLength, testCase.expectedFields)
+			}
+			if redisStorage.CheckKeyExists("h") != (testCase.expectedFields > 0) {
+				t.Fatalf("la clé doit disparaître avec son dernier field")
+			}
+		})
+	}
+}
+
+func TestPersistHashFields(t *testing.T) {
+	redisStorage := NewRedisInMemoryStorage()
+	redisStorage.SetHashField("h", "avecTTL", "v")
+	redisStorage.SetHashField("h", "sansTTL", "v")
+	redisStorage.SetHashFieldsExpiration("h", []string{"avecTTL"}, time.Now().Add(time.Hour), KeyExpirationOptions{})
+
+	persistResults, persistError := redisStorage.PersistHashFields("h", []string{"avecTTL", "sansTTL", "inconnu"})
+	expectedResults := []int{HashFieldExpirationUpdated, HashFieldWithoutExpiration, HashFieldNotFound}
+	if persistError != nil || !slices.Equal(persistResults, expectedResults) {
+		t.Fatalf("résultats %v (%v), attendu %v", persistResults, persistError, expectedResults)
+	}
+	expirationTimes, fieldsFound, _ := redisStorage.GetHashFieldsExpiration("h", []string{"avecTTL", "inconnu"})
+	if !expirationTimes[0].IsZero() || !fieldsFound[0] || fieldsFound[1] {
+		t.Fatalf("expirations %v, trouvés %v", expirationTimes, fieldsFound)
+	}
+
+	redisStorage.SetKeyValue("chaîne", "v", RedisStringType, nil)
+	if _, persistError := redisStorage.PersistHashFields("chaîne", []string{"f"}); persistError != ErrWrongDataType {
+		t.Fatalf("erreur %v, attendu %v", persistError, ErrWrongDataType)
+	}
+}
@@ -1,29 +1,38 @@
 package storage
 
-// SetHashField définit un field dans un hash
+import (
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+// SetHashField définit un field dans un hash (un éventuel TTL du field est retiré, comme Redis)
 func (redisStorage *RedisInMemoryStorage) SetHashField(hashKey string, fieldName string, fieldValue string) bool {
 	redisStorage.storageMutex.Lock()
 	defer redisStorage.storageMutex.Unlock()
 
-	storageValue, keyExists := redisStorage.storageData[hashKey]
-	var redisHashStructure *RedisHashStructure
-
-	if !keyExists {
-		redisHashStructure = &RedisHashStructure{HashFields: make(map[string]string)}
-		redisStorage.storageData[hashKey] = &RedisStorageValue{
-			StoredData: redisHashStructure,
-			DataType:   RedisHashType,
-		}
-	} else {
-		if storageValue.DataType != RedisHashType {
-			return false
-		}
-		redisHashStructure = storageValue.StoredData.(*RedisHashStructure)
+	redisHashStructure, hashError := redisStorage.getOrCreateHash(hashKey)
+	if hashError != nil {
+		return false
 	}
 
+	fieldAdded := redisHashStructure.setFieldValue(fieldName, fieldValue)
+	redisHashStructure.persistField(fieldName)
+	redisStorage.markKeyModified(hashKey)
+	return fieldAdded // true si nouveau field
+}
+
+// setFieldValue définit la valeur d'un field et range un nouveau field dans l'index de HSCAN
+// Retourne true si le field n'existait pas
+func (redisHashStructure *RedisHashStructure) setFieldValue(fieldName string, fieldValue string) bool {
 	_, fieldAlreadyExists := redisHashStructure.HashFields[fieldName]
 	redisHashStructure.HashFields[fieldName] = fieldValue
-	return !fieldAlreadyExists // true si nouveau field
+	if !fieldAlreadyExists {
+		redisHashStructure.memberScanIndex = indexAddedMember(redisHashStructure.memberScanIndex, redisHashStructure.HashFields, fieldName)
+	}
+	return !fieldAlreadyExists
 }
 
 // GetHashField récupère un field d'un hash
@@ -31,8 +40,8 @@ func (redisStorage *RedisInMemoryStorage) GetHashField(hashKey string, fieldName
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
-	storageValue, keyExists := redisStorage.storageData[hashKey]
-	if !keyExists {
+	storageValue := redisStorage.getLiveStorageValue(hashKey)
+	if storageValue == nil {
 		return "", false
 	}
 
@@ -41,8 +50,10 @@ func (redisStorage *RedisInMemoryStorage) GetHashField(hashKey string, fieldName
 	}
 
 	redisHashStructure := storageValue.StoredData.(*RedisHashStructure)
-	fieldValue, fieldExists := redisHashStructure.HashFields[fieldName]
-	return fieldValue, fieldExists
+	if !redisHashStructure.isFieldLive(fieldName, time.Now()) {
+		return "", false
+	}
+	return redisHashStructure.HashFields[fieldName], true
 }
 
 // GetAllHashFields retourne tous les fields et valeurs d'un hash
@@ -50,8 +61,8 @@ func (redisStorage *RedisInMemoryStorage) GetAllHashFields(hashKey string) map[s
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
-	storageValue, keyExists := redisStorage.storageData[hashKey]
-	if !keyExists {
+	storageValue := redisStorage.getLiveStorageValue(hashKey)
+	if storageValue == nil {
 		return map[string]string{}
 	}
 
@@ -60,9 +71,252 @@ func (redisStorage *RedisInMemoryStorage) GetAllHashFields(hashKey string) map[s
 	}
 
 	redisHashStructure := storageValue.StoredData.(*RedisHashStructure)
+	currentTime := time.Now()
 	hashFieldsCopy := make(map[string]string)
 	for fieldName, fieldValue := range redisHashStructure.HashFields {
-		hashFieldsCopy[fieldName] = fieldValue
+		if redisHashStructure.isFieldLive(fieldName, currentTime) {
+			hashFieldsCopy[fieldName] = fieldValue
+		}
 	}
 	return hashFieldsCopy
 }
+
+// getLiveHash retourne le hash non expiré d'une clé (appelant doit détenir le verrou)
+// Retourne nil si la clé n'existe pas, ErrWrongDataType si elle contient un autre type
+func (redisStorage *RedisInMemoryStorage) getLiveHash(hashKey string) (*RedisHashStructure, error) {
+	storageValue := redisStorage.getLiveStorageValue(hashKey)
+	if storageValue == nil {
+		return nil, nil
+	}
+	if storageValue.DataType != RedisHashType {
+		return nil, ErrWrongDataType
+	}
+	return storageValue.StoredData.(*RedisHashStructure), nil
+}
+
+// getHashForUpdate supprime la clé ou les fields expirés puis retourne le hash (appelant doit détenir le verrou en écriture)
+// Retourne nil si la clé n'existe pas (ou plus), ErrWrongDataType si elle contient un autre type
+func (redisStorage *RedisInMemoryStorage) getHashForUpdate(hashKey string) (*RedisHashStructure, error) {
+	redisStorage.removeKeyIfExpired(hashKey)
+	redisHashStructure, hashError := redisStorage.getLiveHash(hashKey)
+	if hashError != nil || redisHashStructure == nil {
+		return nil, hashError
+	}
+
+	redisStorage.removeExpiredHashFields(hashKey, redisHashStructure, time.Now())
+	if len(redisHashStructure.HashFields) == 0 {
+		return nil, nil
+	}
+	return redisHashStructure, nil
+}
+
+// getOrCreateHash retourne le hash d'une clé, créé vide si besoin (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) getOrCreateHash(hashKey string) (*RedisHashStructure, error) {
+	redisHashStructure, hashError := redisStorage.getHashForUpdate(hashKey)
+	if hashError != nil || redisHashStructure != nil {
+		return redisHashStructure, hashError
+	}
+
+	redisHashStructure = &RedisHashStructure{HashFields: make(map[string]string)}
+	redisStorage.storeEntry(hashKey, &RedisStorageValue{
+		StoredData: redisHashStructure,
+		DataType:   RedisHashType,
+	})
+	return redisHashStructure, nil
+}
+
+// DeleteHashFields supprime des fields d'un hash (HDEL), la clé est supprimée si le hash devient vide
+// Retourne le nombre de fields effectivement supprimés
+func (redisStorage *RedisInMemoryStorage) DeleteHashFields(hashKey string, deletedFields []string) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisHashStructure, hashError := redisStorage.getHashForUpdate(hashKey)
+	if hashError != nil || redisHashStructure == nil {
+		return 0, hashError
+	}
+
+	deletedFieldCount := 0
+	for _, deletedField := range deletedFields {
+		if _, fieldExists := redisHashStructure.HashFields[deletedField]; fieldExists {
+			redisHashStructure.deleteField(deletedField)
+			deletedFieldCount++
+		}
+	}
+	if deletedFieldCount > 0 {
+		if len(redisHashStructure.HashFields) == 0 {
+			redisStorage.removeEntry(hashKey)
+		}
+		redisStorage.markKeyModified(hashKey)
+	}
+	return deletedFieldCount, nil
+}
+
+// GetHashLength retourne le nombre de fields d'un hash (HLEN), 0 si la clé n'existe pas
+func (redisStorage *RedisInMemoryStorage) GetHashLength(hashKey string) (int, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisHashStructure, hashError := redisStorage.getLiveHash(hashKey)
+	if hashError != nil || redisHashStructure == nil {
+		return 0, hashError
+	}
+	return redisHashStructure.liveFieldCount(time.Now()), nil
+}
+
+// GetHashFieldValues retourne la valeur de plusieurs fields (HMGET, HEXISTS, HSTRLEN)
+// fieldsFound indique pour chaque field s'il existe
+func (redisStorage *RedisInMemoryStorage) GetHashFieldValues(hashKey string, fieldNames []string) ([]string, []bool, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisHashStructure, hashError := redisStorage.getLiveHash(hashKey)
+	if hashError != nil {
+		return nil, nil, hashError
+	}
+
+	fieldValues := make([]string, len(fieldNames))
+	fieldsFound := make([]bool, len(fieldNames))
+	if redisHashStructure != nil {
+		currentTime := time.Now()
+		for fieldIndex, fieldName := range fieldNames {
+			if redisHashStructure.isFieldLive(fieldName, currentTime) {
+				fieldValues[fieldIndex], fieldsFound[fieldIndex] = redisHashStructure.HashFields[fieldName], true
+			}
+		}
+	}
+	return fieldValues, fieldsFound, nil
+}
+
+// SetHashFieldIfNotExists définit un field seulement s'il n'existe pas encore (HSETNX)
+// Retourne true si le field a été créé
+func (redisStorage *RedisInMemoryStorage) SetHashFieldIfNotExists(hashKey string, fieldName string, fieldValue string) (bool, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisHashStructure, hashError := redisStorage.getOrCreateHash(hashKey)
+	if hashError != nil {
+		return false, hashError
+	}
+	if _, fieldExists := redisHashStructure.HashFields[fieldName]; fieldExists {
+		return false, nil
+	}
+
+	redisHashStructure.setFieldValue(fieldName, fieldValue)
+	redisStorage.markKeyModified(hashKey)
+	return true, nil
+}
+
+// IncrementHashFieldInteger ajoute un entier à un field (HINCRBY), un field absent valant 0
+// Retourne ErrValueNotInteger si le field n'est pas un entier, ErrIncrementOverflow en cas de dépassement
+func (redisStorage *RedisInMemoryStorage) IncrementHashFieldInteger(hashKey string, fieldName string, integerIncrement int64) (int64, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisHashStructure, hashError := redisStorage.getOrCreateHash(hashKey)
+	if hashError != nil {
+		return 0, hashError
+	}
+
+	currentValue := int64(0)
+	if currentField, fieldExists := redisHashStructure.HashFields[fieldName]; fieldExists {
+		parsedValue, parseError := strconv.ParseInt(currentField, 10, 64)
+		if parseError != nil {
+			redisStorage.removeHashIfEmpty(hashKey, redisHashStructure)
+			return 0, ErrValueNotInteger
+		}
+		currentValue = parsedValue
+	}
+	if (integerIncrement > 0 && currentValue > math.MaxInt64-integerIncrement) ||
+		(integerIncrement < 0 && currentValue < math.MinInt64-integerIncrement) {
+		redisStorage.removeHashIfEmpty(hashKey, redisHashStructure)
+		return 0, ErrIncrementOverflow
+	}
+
+	incrementedValue := currentValue + integerIncrement
+	redisHashStructure.setFieldValue(fieldName, strconv.FormatInt(incrementedValue, 10))
+	redisStorage.markKeyModified(hashKey)
+	return incrementedValue, nil
+}
+
+// IncrementHashFieldFloat ajoute un nombre décimal à un field (HINCRBYFLOAT), un field absent valant 0
+// Retourne la nouvelle valeur formatée comme par Redis et si le field garde un TTL ;
+// ErrValueNotFloat si le field n'est pas un nombre, ErrIncrementNotFinite si le résultat serait infini
+func (redisStorage *RedisInMemoryStorage) IncrementHashFieldFloat(hashKey string, fieldName string, floatIncrement *big.Float) (string, bool, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisHashStructure, hashError := redisStorage.getOrCreateHash(hashKey)
+	if hashError != nil {
+		return "", false, hashError
+	}
+
+	currentField, fieldExists := redisHashStructure.HashFields[fieldName]
+	formattedValue, incrementError := incrementFloatValue(currentField, fieldExists, floatIncrement)
+	if incrementError != nil {
+		redisStorage.removeHashIfEmpty(hashKey, redisHashStructure)
+		return "", false, incrementError
+	}
+
+	redisHashStructure.setFieldValue(fieldName, formattedValue)
+	redisStorage.markKeyModified(hashKey)
+	_, fieldHasExpiration := redisHashStructure.FieldExpirations[fieldName]
+	return formattedValue, fieldHasExpiration, nil
+}
+
+// removeHashIfEmpty supprime un hash resté vide après un échec (créé par getOrCreateHash)
+// (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) removeHashIfEmpty(hashKey string, redisHashStructure *RedisHashStructure) {
+	if len(redisHashStructure.HashFields) == 0 {
+		redisStorage.removeEntry(hashKey)
+	}
+}
+
+// GetRandomHashFields retourne des fields tirés au hasard avec leur valeur (HRANDFIELD)
+// Avec allowRepetitions, fieldCount fields sont tirés indépendamment, sinon au plus fieldCount fields distincts
+// Retourne des listes vides si la clé n'existe pas
+func (redisStorage *RedisInMemoryStorage) GetRandomHashFields(hashKey string, fieldCount int, allowRepetitions bool) ([]string, []string, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisHashStructure, hashError := redisStorage.getLiveHash(hashKey)
+	if hashError != nil {
+		return nil, nil, hashError
+	}
+	if redisHashStructure == nil {
+		return []string{}, []string{}, nil
+	}
+
+	currentTime := time.Now()
+	fieldNames := make([]string, 0, len(redisHashStructure.HashFields))
+	for fieldName := range redisHashStructure.HashFields {
+		if redisHashStructure.isFieldLive(fieldName, currentTime) {
+			fieldNames = append(fieldNames, fieldName)
+		}
+	}
+	if len(fieldNames) == 0 {
+		return []string{}, []string{}, nil
+	}
+
+	var selectedFields []string
+	if allowRepetitions {
+		selectedFields = make([]string, 0, fieldCount)
+		for range fieldCount {
+			selectedFields = append(selectedFields, fieldNames[rand.IntN(len(fieldNames))])
+		}
+	} else {
+		// Mélange de Fisher-Yates partiel : tirage uniforme sans remise
+		fieldCount = min(fieldCount, len(fieldNames))
+		for sampleIndex := range fieldCount {
+			swapIndex := sampleIndex + rand.IntN(len(fieldNames)-sampleIndex)
+			fieldNames[sampleIndex], fieldNames[swapIndex] = fieldNames[swapIndex], fieldNames[sampleIndex]
+		}
+		selectedFields = fieldNames[:fieldCount]
+	}
+
+	selectedValues := make([]string, len(selectedFields))
+	for fieldIndex, selectedField := range selectedFields {
+		selectedValues[fieldIndex] = redisHashStructure.HashFields[selectedField]
+	}
+	return selectedFields, selectedValues, nil
+}
@@ -0,0 +1,162 @@
+package storage
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+// minimumScanBucketCount est la taille minimale de la table de buckets (puissance de 2)
+const minimumScanBucketCount = 16
+
+// scanHashSeed est partagée par toutes les bases : un curseur reste valable après SWAPDB ou MOVE
+var scanHashSeed = maphash.MakeSeed()
+
+// keyspaceScanIndex répartit les clés d'une base dans une table de buckets (hash & masque) parcourue par SCAN
+// Les collections qui dépassent smallCollectionScanThreshold indexent de même leurs membres pour SSCAN, HSCAN et ZSCAN
+// La table double ou diminue de moitié selon le nombre de clés ; le curseur avance sur les bits inversés
+// de l'index de bucket (algorithme de Redis), si bien qu'une clé présente pendant toute l'itération
+// est retournée au moins une fois même si la table est redimensionnée entre deux appels
+type keyspaceScanIndex struct {
+	keyBuckets      [][]string
+	indexedKeyCount int
+}
+
+// newKeyspaceScanIndex construit l'index des clés d'un dictionnaire existant
+func newKeyspaceScanIndex[Value any](storageEntries map[string]Value) *keyspaceScanIndex {
+	bucketCount := minimumScanBucketCount
+	for bucketCount < len(storageEntries) {
+		bucketCount *= 2
+	}
+	scanIndex := &keyspaceScanIndex{keyBuckets: make([][]string, bucketCount)}
+	for storageKey := range storageEntries {
+		scanIndex.insertKeyInBucket(storageKey)
+	}
+	scanIndex.indexedKeyCount = len(storageEntries)
+	return scanIndex
+}
+
+// indexAddedMember range dans l'index de SSCAN, HSCAN ou ZSCAN le membre qui vient d'être ajouté à une collection
+// L'index est construit quand la collection dépasse smallCollectionScanThreshold puis tenu à jour à chaque ajout ;
+// retourne l'index à conserver dans la collection (nil tant qu'elle est restée petite)
+func indexAddedMember[Value any](memberScanIndex *keyspaceScanIndex, collectionMembers map[string]Value, memberName string) *keyspaceScanIndex {
+	if memberScanIndex != nil {
+		memberScanIndex.addKey(memberName)
+		return memberScanIndex
+	}
+	if len(collectionMembers) > smallCollectionScanThreshold {
+		return newKeyspaceScanIndex(collectionMembers)
+	}
+	return nil
+}
+
+// unindexRemovedMember retire de l'index d'une collection le membre qui vient d'en être supprimé
+func unindexRemovedMember(memberScanIndex *keyspaceScanIndex, memberName string) {
+	if memberScanIndex != nil {
+		memberScanIndex.removeKey(memberName)
+	}
+}
+
+// indexLargeCollection construit l'index d'une collection stockée déjà remplie (snapshot, STORE...)
+// si elle dépasse smallCollectionScanThreshold ; une collection remplie membre par membre l'a construit en grandissant
+func indexLargeCollection(storageValue *RedisStorageValue) {
+	switch storedData := storageValue.StoredData.(type) {
+	case *RedisSetStructure:
+		if storedData.memberScanIndex == nil && len(storedData.SetElements) > smallCollectionScanThreshold {
+			storedData.memberScanIndex = newKeyspaceScanIndex(storedData.SetElements)
+		}
+	case *RedisHashStructure:
+		if storedData.memberScanIndex == nil && len(storedData.HashFields) > smallCollectionScanThreshold {
+			storedData.memberScanIndex = newKeyspaceScanIndex(storedData.HashFields)
+		}
+	case *RedisSortedSetStructure:
+		if storedData.memberScanIndex == nil && len(storedData.MemberScores) > smallCollectionScanThreshold {
+			storedData.memberScanIndex = newKeyspaceScanIndex(storedData.MemberScores)
+		}
+	}
+}
+
+// computeScanHash retourne le hash d'une clé ou d'un membre utilisé pour le curseur de SCAN
+func computeScanHash(scannedElement string) uint64 {
+	return maphash.String(scanHashSeed, scannedElement)
+}
+
+// insertKeyInBucket range une clé dans son bucket sans mettre à jour le compteur
+func (scanIndex *keyspaceScanIndex) insertKeyInBucket(storageKey string) {
+	bucketIndex := computeScanHash(storageKey) & uint64(len(scanIndex.keyBuckets)-1)
+	scanIndex.keyBuckets[bucketIndex] = append(scanIndex.keyBuckets[bucketIndex], storageKey)
+}
+
+// addKey indexe une nouvelle clé (appelant doit détenir le verrou en écriture)
+func (scanIndex *keyspaceScanIndex) addKey(storageKey string) {
+	scanIndex.insertKeyInBucket(storageKey)
+	scanIndex.indexedKeyCount++
+	if scanIndex.indexedKeyCount > 2*len(scanIndex.keyBuckets) {
+		scanIndex.resizeBuckets(2 * len(scanIndex.keyBuckets))
+	}
+}
+
+// removeKey retire une clé de l'index (appelant doit détenir le verrou en écriture)
+func (scanIndex *keyspaceScanIndex) removeKey(storageKey string) {
+	bucketIndex := computeScanHash(storageKey) & uint64(len(scanIndex.keyBuckets)-1)
+	bucketKeys := scanIndex.keyBuckets[bucketIndex]
+	for keyPosition, indexedKey := range bucketKeys {
+		if indexedKey != storageKey {
+			continue
+		}
+		lastPosition := len(bucketKeys) - 1
+		bucketKeys[keyPosition] = bucketKeys[lastPosition]
+		bucketKeys[lastPosition] = ""
+		scanIndex.keyBuckets[bucketIndex] = bucketKeys[:lastPosition]
+		scanIndex.indexedKeyCount--
+		break
+	}
+
+	if len(scanIndex.keyBuckets) > minimumScanBucketCount && scanIndex.indexedKeyCount < len(scanIndex.keyBuckets)/8 {
+		scanIndex.resizeBuckets(len(scanIndex.keyBuckets) / 2)
+	}
+}
+
+// resizeBuckets redistribue les clés dans une table de taille donnée (puissance de 2)
+func (scanIndex *keyspaceScanIndex) resizeBuckets(bucketCount int) {
+	previousBuckets := scanIndex.keyBuckets
+	scanIndex.keyBuckets = make([][]string, bucketCount)
+	for _, bucketKeys := range previousBuckets {
+		for _, storageKey := range bucketKeys {
+			scanIndex.insertKeyInBucket(storageKey)
+		}
+	}
+}
+
+// scanBuckets parcourt les buckets à partir du curseur jusqu'à avoir examiné au moins elementCount clés
+// visitKey est appelée pour chaque clé examinée ; le curseur retourné vaut 0 à la fin de l'itération
+// Le nombre de buckets vides parcourus est borné pour ne pas bloquer la base sur une table clairsemée
+func (scanIndex *keyspaceScanIndex) scanBuckets(scanCursor uint64, elementCount int, visitKey func(storageKey string)) uint64 {
+	bucketMask := uint64(len(scanIndex.keyBuckets) - 1)
+	examinedKeyCount := 0
+	remainingEmptyBuckets := elementCount * 10
+
+	for {
+		bucketKeys := scanIndex.keyBuckets[scanCursor&bucketMask]
+		for _, storageKey := range bucketKeys {
+			visitKey(storageKey)
+		}
+		examinedKeyCount += len(bucketKeys)
+		if len(bucketKeys) == 0 {
+			remainingEmptyBuckets--
+		}
+
+		scanCursor = advanceScanCursor(scanCursor, bucketMask)
+		if scanCursor == 0 || examinedKeyCount >= elementCount || remainingEmptyBuckets <= 0 {
+			return scanCursor
+		}
+	}
+}
+
+// advanceScanCursor incrémente les bits de poids fort du curseur masqué (bits inversés)
+// Les buckets sont ainsi visités dans un ordre qui reste valable quand la table double ou rétrécit
+func advanceScanCursor(scanCursor uint64, bucketMask uint64) uint64 {
+	scanCursor |= ^bucketMask
+	scanCursor = bits.Reverse64(scanCursor)
+	scanCursor++
+	return bits.Reverse64(scanCursor)
+}
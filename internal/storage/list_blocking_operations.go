@@ -0,0 +1,208 @@
+package storage
+
+import (
+	"maps"
+	"slices"
+)
+
+// ListWaiter représente un client bloqué sur une ou plusieurs listes (BLPOP, BRPOP, BLMOVE, BRPOPLPUSH)
+// Il est inscrit dans la file d'attente de chaque liste surveillée ; la première liste qui reçoit un élément le sert
+type ListWaiter struct {
+	WatchedListKeys []string
+	PopFromLeft     bool
+	// DestinationKey reçoit l'élément retiré (BLMOVE, BRPOPLPUSH), vide pour BLPOP/BRPOP
+	DestinationKey string
+	PushToLeft     bool
+	// servedElement reçoit l'élément attribué au client (capacité 1 : un waiter n'est servi qu'une fois)
+	servedElement chan ServedListElement
+}
+
+// ServedListElement décrit un élément retiré d'une liste pour un client bloqué
+type ServedListElement struct {
+	ListWaiter    *ListWaiter
+	SourceListKey string
+	Element       string
+}
+
+// NewListWaiter crée la demande d'un client qui attend un élément sur des listes
+func NewListWaiter(watchedListKeys []string, popFromLeft bool, destinationKey string, pushToLeft bool) *ListWaiter {
+	return &ListWaiter{
+		WatchedListKeys: watchedListKeys,
+		PopFromLeft:     popFromLeft,
+		DestinationKey:  destinationKey,
+		PushToLeft:      pushToLeft,
+		servedElement:   make(chan ServedListElement, 1),
+	}
+}
+
+// IsMoveRequest indique si l'élément servi est déplacé vers une autre liste (BLMOVE, BRPOPLPUSH)
+func (listWaiter *ListWaiter) IsMoveRequest() bool {
+	return listWaiter.DestinationKey != ""
+}
+
+// ServedElement retourne le canal qui reçoit l'élément attribué au client
+func (listWaiter *ListWaiter) ServedElement() <-chan ServedListElement {
+	return listWaiter.servedElement
+}
+
+// PopOrRegisterListWaiter sert immédiatement le client si une des listes surveillées contient un élément
+// (dans l'ordre des clés), sinon l'inscrit en fin de file d'attente de chaque liste si registerIfEmpty
+// Retourne l'élément servi (nil si le client attend ou n'a rien obtenu) ou ErrWrongDataType
+func (redisStorage *RedisInMemoryStorage) PopOrRegisterListWaiter(listWaiter *ListWaiter, registerIfEmpty bool) (*ServedListElement, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	for _, watchedListKey := range listWaiter.WatchedListKeys {
+		redisStorage.removeKeyIfExpired(watchedListKey)
+		if storageValue, keyExists := redisStorage.storageData[watchedListKey]; keyExists && storageValue.DataType != RedisListType {
+			return nil, ErrWrongDataType
+		}
+	}
+	if listWaiter.IsMoveRequest() && !redisStorage.canReceiveListElement(listWaiter.DestinationKey) {
+		return nil, ErrWrongDataType
+	}
+
+	for _, watchedListKey := range listWaiter.WatchedListKeys {
+		if redisStorage.getNonEmptyList(watchedListKey) != nil {
+			servedElement := redisStorage.serveListWaiter(listWaiter, watchedListKey)
+			return &servedElement, nil
+		}
+	}
+
+	if registerIfEmpty {
+		for _, watchedListKey := range listWaiter.WatchedListKeys {
+			redisStorage.listWaiters[watchedListKey] = append(redisStorage.listWaiters[watchedListKey], listWaiter)
+		}
+	}
+	return nil, nil
+}
+
+// RemoveListWaiter retire un client des files d'attente (délai dépassé, déconnexion)
+// Un élément attribué juste avant reste disponible dans ServedElement
+func (redisStorage *RedisInMemoryStorage) RemoveListWaiter(listWaiter *ListWaiter) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+	redisStorage.unregisterListWaiter(listWaiter)
+}
+
+// ServeReadyListWaiters sert les clients bloqués sur les listes qui ont reçu des éléments, dans l'ordre où
+// elles ont été remplies ; appelé une fois la commande (ou la transaction, le script) entièrement exécutée,
+// pour que les clients ne soient servis qu'avec l'état final, comme dans Redis
+// Retourne les éléments servis, à propager après la commande (LPOP, RPOP, LMOVE)
+func (redisStorage *RedisInMemoryStorage) ServeReadyListWaiters() []ServedListElement {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	var servedElements []ServedListElement
+	for len(redisStorage.readyListKeys) > 0 {
+		readyListKey := redisStorage.readyListKeys[0]
+		redisStorage.readyListKeys = redisStorage.readyListKeys[1:]
+		servedElements = append(servedElements, redisStorage.serveListWaiters(readyListKey)...)
+	}
+	redisStorage.readyListKeys = nil
+	return servedElements
+}
+
+// signalListReady note qu'une liste attendue par des clients bloqués a reçu des éléments
+// (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) signalListReady(listKey string) {
+	if len(redisStorage.listWaiters[listKey]) == 0 || containsListKey(redisStorage.readyListKeys, listKey) {
+		return
+	}
+	redisStorage.readyListKeys = append(redisStorage.readyListKeys, listKey)
+}
+
+// signalAllWaitedListsReady signale toutes les listes attendues (contenu de la base remplacé, SWAPDB)
+// (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) signalAllWaitedListsReady() {
+	for _, waitedListKey := range slices.Sorted(maps.Keys(redisStorage.listWaiters)) {
+		redisStorage.signalListReady(waitedListKey)
+	}
+}
+
+// containsListKey indique si une clé fait partie d'une liste de clés
+func containsListKey(listKeys []string, searchedKey string) bool {
+	for _, listKey := range listKeys {
+		if listKey == searchedKey {
+			return true
+		}
+	}
+	return false
+}
+
+// getNonEmptyList retourne une liste non vide et non expirée (appelant doit détenir le verrou)
+func (redisStorage *RedisInMemoryStorage) getNonEmptyList(listKey string) *RedisListStructure {
+	storageValue := redisStorage.getLiveStorageValue(listKey)
+	if storageValue == nil || storageValue.DataType != RedisListType {
+		return nil
+	}
+	redisListStructure := storageValue.StoredData.(*RedisListStructure)
+	if len(redisListStructure.ListElements) == 0 {
+		return nil
+	}
+	return redisListStructure
+}
+
+// canReceiveListElement indique si une clé est absente ou contient une liste (destination de BLMOVE)
+func (redisStorage *RedisInMemoryStorage) canReceiveListElement(listKey string) bool {
+	storageValue := redisStorage.getLiveStorageValue(listKey)
+	return storageValue == nil || storageValue.DataType == RedisListType
+}
+
+// serveListWaiters attribue les éléments d'une liste qui a été remplie aux clients bloqués, dans l'ordre
+// d'arrivée, tant qu'elle contient des éléments (appelant doit détenir le verrou en écriture)
+// Un client dont la destination n'est pas une liste reste bloqué, comme dans Redis
+func (redisStorage *RedisInMemoryStorage) serveListWaiters(listKey string) []ServedListElement {
+	var servedElements []ServedListElement
+	for redisStorage.getNonEmptyList(listKey) != nil {
+		var servableWaiter *ListWaiter
+		for _, listWaiter := range redisStorage.listWaiters[listKey] {
+			if !listWaiter.IsMoveRequest() || redisStorage.canReceiveListElement(listWaiter.DestinationKey) {
+				servableWaiter = listWaiter
+				break
+			}
+		}
+		if servableWaiter == nil {
+			break
+		}
+		servedElements = append(servedElements, redisStorage.serveListWaiter(servableWaiter, listKey))
+	}
+	return servedElements
+}
+
+// serveListWaiter retire un élément de la liste pour un client, le déplace vers sa destination (BLMOVE)
+// et le lui transmet (appelant doit détenir le verrou en écriture, liste non vide)
+// Les clients bloqués sur la destination sont servis ensuite, la destination étant signalée par pushListElements
+func (redisStorage *RedisInMemoryStorage) serveListWaiter(listWaiter *ListWaiter, sourceListKey string) ServedListElement {
+	redisStorage.unregisterListWaiter(listWaiter)
+	poppedElement, _ := redisStorage.popListElement(sourceListKey, listWaiter.PopFromLeft)
+	servedElement := ServedListElement{
+		ListWaiter:    listWaiter,
+		SourceListKey: sourceListKey,
+		Element:       poppedElement,
+	}
+	listWaiter.servedElement <- servedElement
+
+	if listWaiter.IsMoveRequest() {
+		redisStorage.pushListElements(listWaiter.DestinationKey, []string{poppedElement}, listWaiter.PushToLeft)
+	}
+	return servedElement
+}
+
+// unregisterListWaiter retire un client de la file d'attente de chacune de ses listes (appelant doit détenir le verrou)
+func (redisStorage *RedisInMemoryStorage) unregisterListWaiter(listWaiter *ListWaiter) {
+	for _, watchedListKey := range listWaiter.WatchedListKeys {
+		waitingClients := redisStorage.listWaiters[watchedListKey]
+		for waiterPosition, waitingClient := range waitingClients {
+			if waitingClient == listWaiter {
+				waitingClients = append(waitingClients[:waiterPosition], waitingClients[waiterPosition+1:]...)
+				break
+			}
+		}
+		if len(waitingClients) == 0 {
+			delete(redisStorage.listWaiters, watchedListKey)
+		} else {
+			redisStorage.listWaiters[watchedListKey] = waitingClients
+		}
+	}
+}
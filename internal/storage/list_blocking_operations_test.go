@@ -0,0 +1,219 @@
+package storage
+
+import "testing"
+
+// receivedListElement retourne l'élément attribué à un waiter, ou false s'il attend encore
+func receivedListElement(listWaiter *ListWaiter) (ServedListElement, bool) {
+	select {
+	case servedElement := <-listWaiter.ServedElement():
+		return servedElement, true
+	default:
+		return ServedListElement{}, false
+	}
+}
+
+func TestListWaitersServedInArrivalOrder(t *testing.T) {
+	testCases := []struct {
+		name string
+		// waitedKeys contient les listes surveillées par chaque waiter, dans l'ordre d'inscription
+		waitedKeys     [][]string
+		pushedList     string
+		pushedElements []string
+		// expectedElements est l'élément reçu par chaque waiter ("" s'il reste bloqué)
+		expectedElements []string
+		expectedLeftover int
+	}{
+		{
+			name:             "un élément pour le premier arrivé",
+			waitedKeys:       [][]string{{"l"}, {"l"}},
+			pushedList:       "l",
+			pushedElements:   []string{"a"},
+			expectedElements: []string{"a", ""},
+		},
+		{
+			name:             "plusieurs éléments répartis dans l'ordre d'arrivée",
+			waitedKeys:       [][]string{{"l"}, {"l"}, {"l"}},
+			pushedList:       "l",
+			pushedElements:   []string{"a", "b"},
+			expectedElements: []string{"a", "b", ""},
+		},
+		{
+			name:             "plus d'éléments que de clients",
+			waitedKeys:       [][]string{{"l"}},
+			pushedList:       "l",
+			pushedElements:   []string{"a", "b", "c"},
+			expectedElements: []string{"a"},
+			expectedLeftover: 2,
+		},
+		{
+			name:             "attente sur plusieurs listes",
+			waitedKeys:       [][]string{{"autre", "l"}, {"l", "autre"}},
+			pushedList:       "l",
+			pushedElements:   []string{"a", "b"},
+			expectedElements: []string{"a", "b"},
+		},
+		{
+			name:             "liste non attendue",
+			waitedKeys:       [][]string{{"autre"}},
+			pushedList:       "l",
+			pushedElements:   []string{"a"},
+			expectedElements: []string{""},
+			expectedLeftover: 1,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			var listWaiters []*ListWaiter
+			for _, waitedKeys := range testCase.waitedKeys {
+				listWaiter := NewListWaiter(waitedKeys, true, "", false)
+				if servedElement, popError := redisStorage.PopOrRegisterListWaiter(listWaiter, true); servedElement != nil || popError != nil {
+					t.Fatalf("waiter servi sans élément: %v %v", servedElement, popError)
+				}
+				listWaiters = append(listWaiters, listWaiter)
+			}
+
+			redisStorage.PushElementsToList(testCase.pushedList, testCase.pushedElements, false)
+			// Les clients ne sont servis qu'une fois la commande terminée
+			for waiterIndex, listWaiter := range listWaiters {
+				if _, isServed := receivedListElement(listWaiter); isServed {
+					t.Fatalf("waiter %d servi avant ServeReadyListWaiters", waiterIndex)
+				}
+			}
+			redisStorage.ServeReadyListWaiters()
+
+			for waiterIndex, listWaiter := range listWaiters {
+				servedElement, isServed := receivedListElement(listWaiter)
+				if isServed != (testCase.expectedElements[waiterIndex] != "") || servedElement.Element != testCase.expectedElements[waiterIndex] {
+					t.Fatalf("waiter %d: %q (servi=%v), attendu %q", waiterIndex, servedElement.Element, isServed, testCase.expectedElements[waiterIndex])
+				}
+				if isServed && servedElement.SourceListKey != testCase.pushedList {
+					t.Fatalf("waiter %d servi depuis %q, attendu %q", waiterIndex, servedElement.SourceListKey, testCase.pushedList)
+				}
+			}
+			if listLength := redisStorage.GetListLength(testCase.pushedList); listLength != testCase.expectedLeftover {
+				t.Fatalf("%d éléments restants, attendu %d", listLength, testCase.expectedLeftover)
+			}
+		})
+	}
+}
+
+func TestPopOrRegisterListWaiter(t *testing.T) {
+	testCases := []struct {
+		name            string
+		listWaiter      *ListWaiter
+		expectedElement string
+		expectedSource  string
+		expectedError   error
+		expectedLists   map[string]int
+	}{
+		{
+			name:            "première liste non vide dans l'ordre des clés",
+			listWaiter:      NewListWaiter([]string{"vide", "droite", "gauche"}, false, "", false),
+			expectedElement: "d2",
+			expectedSource:  "droite",
+			expectedLists:   map[string]int{"droite": 1, "gauche": 2},
+		},
+		{
+			name:            "déplacement immédiat vers la destination",
+			listWaiter:      NewListWaiter([]string{"gauche"}, true, "destination", true),
+			expectedElement: "g1",
+			expectedSource:  "gauche",
+			expectedLists:   map[string]int{"gauche": 1, "destination": 1},
+		},
+		{
+			name:          "source d'un autre type",
+			listWaiter:    NewListWaiter([]string{"chaîne", "gauche"}, true, "", false),
+			expectedError: ErrWrongDataType,
+			expectedLists: map[string]int{"gauche": 2},
+		},
+		{
+			name:          "destination d'un autre type",
+			listWaiter:    NewListWaiter([]string{"gauche"}, true, "chaîne", true),
+			expectedError: ErrWrongDataType,
+			expectedLists: map[string]int{"gauche": 2},
+		},
+		{
+			name:          "listes vides sans inscription",
+			listWaiter:    NewListWaiter([]string{"vide"}, true, "", false),
+			expectedLists: map[string]int{"vide": 0},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			redisStorage.PushElementsToList("droite", []string{"d1", "d2"}, false)
+			redisStorage.PushElementsToList("gauche", []string{"g1", "g2"}, false)
+			redisStorage.SetKeyValue("chaîne", "v", RedisStringType, nil)
+
+			servedElement, popError := redisStorage.PopOrRegisterListWaiter(testCase.listWaiter, false)
+			if popError != testCase.expectedError {
+				t.Fatalf("erreur %v, attendu %v", popError, testCase.expectedError)
+			}
+			if testCase.expectedElement == "" && servedElement != nil {
+				t.Fatalf("élément %q servi, attendu aucun", servedElement.Element)
+			}
+			if testCase.expectedElement != "" && (servedElement == nil || servedElement.Element != testCase.expectedElement || servedElement.SourceListKey != testCase.expectedSource) {
+				t.Fatalf("élément servi %+v, attendu %q depuis %q", servedElement, testCase.expectedElement, testCase.expectedSource)
+			}
+			for listKey, expectedLength := range testCase.expectedLists {
+				if listLength := redisStorage.GetListLength(listKey); listLength != expectedLength {
+					t.Fatalf("liste %s: %d éléments, attendu %d", listKey, listLength, expectedLength)
+				}
+			}
+			if len(redisStorage.listWaiters) != 0 {
+				t.Fatalf("aucun waiter ne doit être inscrit: %v", redisStorage.listWaiters)
+			}
+		})
+	}
+}
+
+func TestBlockedMoveWaitsForListDestination(t *testing.T) {
+	redisStorage := NewRedisInMemoryStorage()
+	moveWaiter := NewListWaiter([]string{"source"}, true, "destination", false)
+	popWaiter := NewListWaiter([]string{"source"}, true, "", false)
+	redisStorage.PopOrRegisterListWaiter(moveWaiter, true)
+	redisStorage.PopOrRegisterListWaiter(popWaiter, true)
+
+	// Une destination qui n'est pas une liste laisse le déplacement bloqué : le client suivant est servi
+	redisStorage.SetKeyValue("destination", "v", RedisStringType, nil)
+	redisStorage.PushElementsToList("source", []string{"a"}, false)
+	redisStorage.ServeReadyListWaiters()
+	if _, isServed := receivedListElement(moveWaiter); isServed {
+		t.Fatalf("le déplacement vers une chaîne doit rester bloqué")
+	}
+	if servedElement, isServed := receivedListElement(popWaiter); !isServed || servedElement.Element != "a" {
+		t.Fatalf("le client suivant doit recevoir a: %+v", servedElement)
+	}
+
+	redisStorage.DeleteKeyValue("destination")
+	redisStorage.PushElementsToList("source", []string{"b"}, false)
+	servedElements := redisStorage.ServeReadyListWaiters()
+	if servedElement, isServed := receivedListElement(moveWaiter); !isServed || servedElement.Element != "b" || len(servedElements) != 1 {
+		t.Fatalf("le déplacement doit recevoir b: %+v (%d servis)", servedElement, len(servedElements))
+	}
+	if destinationElements := redisStorage.GetListElementsInRange("destination", 0, -1); len(destinationElements) != 1 || destinationElements[0] != "b" {
+		t.Fatalf("destination %v, attendu [b]", destinationElements)
+	}
+}
+
+func TestRemoveListWaiter(t *testing.T) {
+	redisStorage := NewRedisInMemoryStorage()
+	departedWaiter := NewListWaiter([]string{"l", "m"}, true, "", false)
+	remainingWaiter := NewListWaiter([]string{"l"}, true, "", false)
+	redisStorage.PopOrRegisterListWaiter(departedWaiter, true)
+	redisStorage.PopOrRegisterListWaiter(remainingWaiter, true)
+
+	redisStorage.RemoveListWaiter(departedWaiter)
+	if _, isWaitingOnM := redisStorage.listWaiters["m"]; isWaitingOnM {
+		t.Fatalf("la file de m doit être supprimée avec son dernier waiter")
+	}
+	redisStorage.PushElementsToList("l", []string{"a"}, false)
+	redisStorage.ServeReadyListWaiters()
+	if _, isServed := receivedListElement(departedWaiter); isServed {
+		t.Fatalf("un waiter retiré ne doit plus être servi")
+	}
+	if servedElement, isServed := receivedListElement(remainingWaiter); !isServed || servedElement.Element != "a" {
+		t.Fatalf("le waiter restant doit recevoir a: %+v", servedElement)
+	}
+}
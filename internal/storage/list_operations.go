@@ -1,20 +1,30 @@
 package storage
 
+import "slices"
+
 // PushElementsToList ajoute des éléments à une liste (gauche ou droite)
+// Les clients bloqués sur la liste (BLPOP...) seront servis après la commande (ServeReadyListWaiters)
 func (redisStorage *RedisInMemoryStorage) PushElementsToList(listKey string, newElements []string, pushToLeft bool) int {
 	redisStorage.storageMutex.Lock()
 	defer redisStorage.storageMutex.Unlock()
 
+	redisStorage.removeKeyIfExpired(listKey)
+	return redisStorage.pushListElements(listKey, newElements, pushToLeft)
+}
+
+// pushListElements ajoute des éléments à une liste, créée si besoin (appelant doit détenir le verrou en écriture)
+// et la signale aux clients bloqués qui l'attendent. Retourne la nouvelle longueur, ou -1 si la clé contient un autre type
+func (redisStorage *RedisInMemoryStorage) pushListElements(listKey string, newElements []string, pushToLeft bool) int {
 	storageValue, keyExists := redisStorage.storageData[listKey]
 	var redisListStructure *RedisListStructure
 
 	if !keyExists {
 		// Créer une nouvelle liste
 		redisListStructure = &RedisListStructure{ListElements: make([]string, 0)}
-		redisStorage.storageData[listKey] = &RedisStorageValue{
+		redisStorage.storeEntry(listKey, &RedisStorageValue{
 			StoredData: redisListStructure,
 			DataType:   RedisListType,
-		}
+		})
 	} else {
 		// Vérifier que c'est bien une liste
 		if storageValue.DataType != RedisListType {
@@ -25,15 +35,19 @@ func (redisStorage *RedisInMemoryStorage) PushElementsToList(listKey string, new
 
 	// Ajouter les éléments
 	if pushToLeft {
-		// LPUSH - ajouter à gauche (début)
+		// LPUSH - ajouter à gauche (début), un par un comme Redis : LPUSH l a b donne [b, a]
 		updatedElements := make([]string, len(newElements)+len(redisListStructure.ListElements))
-		copy(updatedElements, newElements)
+		for elementIndex, newElement := range newElements {
+			updatedElements[len(newElements)-1-elementIndex] = newElement
+		}
 		copy(updatedElements[len(newElements):], redisListStructure.ListElements)
 		redisListStructure.ListElements = updatedElements
 	} else {
 		// RPUSH - ajouter à droite (fin)
 		redisListStructure.ListElements = append(redisListStructure.ListElements, newElements...)
 	}
+	redisStorage.markKeyModified(listKey)
+	redisStorage.signalListReady(listKey)
 
 	return len(redisListStructure.ListElements)
 }
@@ -43,6 +57,12 @@ func (redisStorage *RedisInMemoryStorage) PopElementFromList(listKey string, pop
 	redisStorage.storageMutex.Lock()
 	defer redisStorage.storageMutex.Unlock()
 
+	redisStorage.removeKeyIfExpired(listKey)
+	return redisStorage.popListElement(listKey, popFromLeft)
+}
+
+// popListElement supprime et retourne un élément d'une liste (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) popListElement(listKey string, popFromLeft bool) (string, bool) {
 	storageValue, keyExists := redisStorage.storageData[listKey]
 	if !keyExists {
 		return "", false
@@ -70,8 +90,9 @@ func (redisStorage *RedisInMemoryStorage) PopElementFromList(listKey string, pop
 
 	// Supprimer la clé si la liste est vide
 	if len(redisListStructure.ListElements) == 0 {
-		delete(redisStorage.storageData, listKey)
+		redisStorage.removeEntry(listKey)
 	}
+	redisStorage.markKeyModified(listKey)
 
 	return poppedElement, true
 }
@@ -81,8 +102,8 @@ func (redisStorage *RedisInMemoryStorage) GetListLength(listKey string) int {
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
-	storageValue, keyExists := redisStorage.storageData[listKey]
-	if !keyExists {
+	storageValue := redisStorage.getLiveStorageValue(listKey)
+	if storageValue == nil {
 		return 0
 	}
 
@@ -99,8 +120,8 @@ func (redisStorage *RedisInMemoryStorage) GetListElementsInRange(listKey string,
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
-	storageValue, keyExists := redisStorage.storageData[listKey]
-	if !keyExists {
+	storageValue := redisStorage.getLiveStorageValue(listKey)
+	if storageValue == nil {
 		return []string{}
 	}
 
@@ -109,13 +130,17 @@ func (redisStorage *RedisInMemoryStorage) GetListElementsInRange(listKey string,
 	}
 
 	redisListStructure := storageValue.StoredData.(*RedisListStructure)
-	listLength := len(redisListStructure.ListElements)
-
-	if listLength == 0 {
+	startIndex, stopIndex, rangeIsEmpty := normalizeListRange(startIndex, stopIndex, len(redisListStructure.ListElements))
+	if rangeIsEmpty {
 		return []string{}
 	}
 
-	// Gérer les indices négatifs (comme Redis)
+	return redisListStructure.ListElements[startIndex : stopIndex+1]
+}
+
+// normalizeListRange convertit des indices de début et de fin (négatifs comptés depuis la fin, comme Redis)
+// en positions incluses dans les bornes de la liste ; rangeIsEmpty si l'intervalle ne contient aucun élément
+func normalizeListRange(startIndex, stopIndex, listLength int) (int, int, bool) {
 	if startIndex < 0 {
 		startIndex = listLength + startIndex
 	}
@@ -130,9 +155,295 @@ func (redisStorage *RedisInMemoryStorage) GetListElementsInRange(listKey string,
 	if stopIndex >= listLength {
 		stopIndex = listLength - 1
 	}
-	if startIndex > stopIndex {
-		return []string{}
+	return startIndex, stopIndex, startIndex > stopIndex
+}
+
+// ListPositionQuery regroupe les options de LPOS
+type ListPositionQuery struct {
+	SearchedElement string
+	// MatchRank est le rang de la première correspondance retournée (négatif : recherche depuis la fin, jamais 0)
+	MatchRank int
+	// MatchCount est le nombre maximal de positions retournées (0 : toutes)
+	MatchCount int
+	// MaximumComparisons limite le nombre d'éléments examinés (0 : toute la liste)
+	MaximumComparisons int
+}
+
+// getListForUpdate retourne la liste d'une clé après suppression si elle a expiré (appelant doit détenir le verrou en écriture)
+// Retourne nil si la clé n'existe pas, ErrWrongDataType si elle contient un autre type
+func (redisStorage *RedisInMemoryStorage) getListForUpdate(listKey string) (*RedisListStructure, error) {
+	redisStorage.removeKeyIfExpired(listKey)
+	return redisStorage.getLiveList(listKey)
+}
+
+// getLiveList retourne la liste non expirée d'une clé (appelant doit détenir le verrou)
+// Retourne nil si la clé n'existe pas, ErrWrongDataType si elle contient un autre type
+func (redisStorage *RedisInMemoryStorage) getLiveList(listKey string) (*RedisListStructure, error) {
+	storageValue := redisStorage.getLiveStorageValue(listKey)
+	if storageValue == nil {
+		return nil, nil
+	}
+	if storageValue.DataType != RedisListType {
+		return nil, ErrWrongDataType
 	}
+	return storageValue.StoredData.(*RedisListStructure), nil
+}
 
-	return redisListStructure.ListElements[startIndex : stopIndex+1]
+// removeListIfEmpty supprime la clé d'une liste vidée et signale la modification (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) removeListIfEmpty(listKey string, redisListStructure *RedisListStructure) {
+	if len(redisListStructure.ListElements) == 0 {
+		redisStorage.removeEntry(listKey)
+	}
+	redisStorage.markKeyModified(listKey)
+}
+
+// normalizeListIndex convertit un index (négatif compté depuis la fin) en position, false s'il est hors de la liste
+func normalizeListIndex(elementIndex, listLength int) (int, bool) {
+	if elementIndex < 0 {
+		elementIndex += listLength
+	}
+	return elementIndex, elementIndex >= 0 && elementIndex < listLength
+}
+
+// PushElementsToExistingList ajoute des éléments à une liste seulement si elle existe (LPUSHX, RPUSHX)
+// Retourne la nouvelle longueur, 0 si la clé n'existe pas, -1 si elle contient un autre type
+func (redisStorage *RedisInMemoryStorage) PushElementsToExistingList(listKey string, newElements []string, pushToLeft bool) int {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisListStructure, listError := redisStorage.getListForUpdate(listKey)
+	if listError != nil {
+		return -1
+	}
+	if redisListStructure == nil {
+		return 0
+	}
+	return redisStorage.pushListElements(listKey, newElements, pushToLeft)
+}
+
+// PopElementsFromList supprime et retourne jusqu'à elementCount éléments d'une liste (LPOP/RPOP key count)
+// Retourne nil si la clé n'existe pas, ErrWrongDataType si elle contient un autre type
+func (redisStorage *RedisInMemoryStorage) PopElementsFromList(listKey string, popFromLeft bool, elementCount int) ([]string, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisListStructure, listError := redisStorage.getListForUpdate(listKey)
+	if listError != nil || redisListStructure == nil {
+		return nil, listError
+	}
+
+	elementCount = min(elementCount, len(redisListStructure.ListElements))
+	poppedElements := make([]string, 0, elementCount)
+	for range elementCount {
+		poppedElement, _ := redisStorage.popListElement(listKey, popFromLeft)
+		poppedElements = append(poppedElements, poppedElement)
+	}
+	return poppedElements, nil
+}
+
+// GetListElementAtIndex retourne l'élément d'une liste à un index (LINDEX, négatif compté depuis la fin)
+// elementExists est false si la clé n'existe pas ou si l'index est hors de la liste
+func (redisStorage *RedisInMemoryStorage) GetListElementAtIndex(listKey string, elementIndex int) (string, bool, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisListStructure, listError := redisStorage.getLiveList(listKey)
+	if listError != nil || redisListStructure == nil {
+		return "", false, listError
+	}
+
+	elementPosition, indexInRange := normalizeListIndex(elementIndex, len(redisListStructure.ListElements))
+	if !indexInRange {
+		return "", false, nil
+	}
+	return redisListStructure.ListElements[elementPosition], true, nil
+}
+
+// SetListElementAtIndex remplace l'élément d'une liste à un index (LSET)
+// Retourne ErrKeyNotFound, ErrIndexOutOfRange ou ErrWrongDataType
+func (redisStorage *RedisInMemoryStorage) SetListElementAtIndex(listKey string, elementIndex int, newElement string) error {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisListStructure, listError := redisStorage.getListForUpdate(listKey)
+	if listError != nil {
+		return listError
+	}
+	if redisListStructure == nil {
+		return ErrKeyNotFound
+	}
+
+	elementPosition, indexInRange := normalizeListIndex(elementIndex, len(redisListStructure.ListElements))
+	if !indexInRange {
+		return ErrIndexOutOfRange
+	}
+	redisListStructure.ListElements[elementPosition] = newElement
+	redisStorage.markKeyModified(listKey)
+	return nil
+}
+
+// InsertListElement insère un élément avant ou après la première occurrence d'un pivot (LINSERT)
+// Retourne la nouvelle longueur, 0 si la clé n'existe pas, -1 si le pivot est absent
+func (redisStorage *RedisInMemoryStorage) InsertListElement(listKey string, insertBefore bool, pivotElement string, newElement string) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisListStructure, listError := redisStorage.getListForUpdate(listKey)
+	if listError != nil || redisListStructure == nil {
+		return 0, listError
+	}
+
+	for elementPosition, listElement := range redisListStructure.ListElements {
+		if listElement != pivotElement {
+			continue
+		}
+		if !insertBefore {
+			elementPosition++
+		}
+		redisListStructure.ListElements = slices.Insert(redisListStructure.ListElements, elementPosition, newElement)
+		redisStorage.markKeyModified(listKey)
+		return len(redisListStructure.ListElements), nil
+	}
+	return -1, nil
+}
+
+// RemoveListElements supprime les occurrences d'un élément (LREM) : les removalCount premières si positif,
+// les dernières si négatif, toutes si 0 ; la clé est supprimée si la liste devient vide
+// Retourne le nombre d'éléments supprimés
+func (redisStorage *RedisInMemoryStorage) RemoveListElements(listKey string, removalCount int, removedElement string) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisListStructure, listError := redisStorage.getListForUpdate(listKey)
+	if listError != nil || redisListStructure == nil {
+		return 0, listError
+	}
+
+	listElements := redisListStructure.ListElements
+	removeFromTail := removalCount < 0
+	if removeFromTail {
+		removalCount = -removalCount
+	}
+
+	// Marquer les positions à supprimer en partant de la tête ou de la queue
+	removedPositions := make(map[int]bool)
+	for scanIndex := range listElements {
+		if removalCount > 0 && len(removedPositions) == removalCount {
+			break
+		}
+		elementPosition := scanIndex
+		if removeFromTail {
+			elementPosition = len(listElements) - 1 - scanIndex
+		}
+		if listElements[elementPosition] == removedElement {
+			removedPositions[elementPosition] = true
+		}
+	}
+	if len(removedPositions) == 0 {
+		return 0, nil
+	}
+
+	remainingElements := make([]string, 0, len(listElements)-len(removedPositions))
+	for elementPosition, listElement := range listElements {
+		if !removedPositions[elementPosition] {
+			remainingElements = append(remainingElements, listElement)
+		}
+	}
+	redisListStructure.ListElements = remainingElements
+	redisStorage.removeListIfEmpty(listKey, redisListStructure)
+	return len(removedPositions), nil
+}
+
+// TrimList ne conserve que les éléments entre deux indices inclus (LTRIM, négatifs comptés depuis la fin)
+// La clé est supprimée si l'intervalle ne contient aucun élément
+func (redisStorage *RedisInMemoryStorage) TrimList(listKey string, startIndex, stopIndex int) error {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisListStructure, listError := redisStorage.getListForUpdate(listKey)
+	if listError != nil || redisListStructure == nil {
+		return listError
+	}
+
+	listLength := len(redisListStructure.ListElements)
+	startIndex, stopIndex, rangeIsEmpty := normalizeListRange(startIndex, stopIndex, listLength)
+	if !rangeIsEmpty && startIndex == 0 && stopIndex == listLength-1 {
+		return nil
+	}
+
+	if rangeIsEmpty {
+		redisListStructure.ListElements = nil
+	} else {
+		// Copier l'intervalle conservé pour libérer le tableau d'origine
+		redisListStructure.ListElements = slices.Clone(redisListStructure.ListElements[startIndex : stopIndex+1])
+	}
+	redisStorage.removeListIfEmpty(listKey, redisListStructure)
+	return nil
+}
+
+// FindListElementPositions retourne les positions d'un élément dans une liste (LPOS), dans l'ordre de recherche
+// Retourne une liste vide si la clé n'existe pas
+func (redisStorage *RedisInMemoryStorage) FindListElementPositions(listKey string, positionQuery ListPositionQuery) ([]int, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisListStructure, listError := redisStorage.getLiveList(listKey)
+	if listError != nil || redisListStructure == nil {
+		return []int{}, listError
+	}
+
+	listElements := redisListStructure.ListElements
+	searchFromTail := positionQuery.MatchRank < 0
+	skippedMatches := positionQuery.MatchRank - 1
+	if searchFromTail {
+		skippedMatches = -positionQuery.MatchRank - 1
+	}
+	comparisonCount := len(listElements)
+	if positionQuery.MaximumComparisons > 0 {
+		comparisonCount = min(comparisonCount, positionQuery.MaximumComparisons)
+	}
+
+	matchPositions := []int{}
+	for scanIndex := range comparisonCount {
+		elementPosition := scanIndex
+		if searchFromTail {
+			elementPosition = len(listElements) - 1 - scanIndex
+		}
+		if listElements[elementPosition] != positionQuery.SearchedElement {
+			continue
+		}
+		if skippedMatches > 0 {
+			skippedMatches--
+			continue
+		}
+		matchPositions = append(matchPositions, elementPosition)
+		if positionQuery.MatchCount > 0 && len(matchPositions) == positionQuery.MatchCount {
+			break
+		}
+	}
+	return matchPositions, nil
+}
+
+// MoveListElement retire un élément d'une liste et l'ajoute à une autre de manière atomique (LMOVE, RPOPLPUSH)
+// La source et la destination peuvent être la même liste (rotation) ; les clients bloqués sur la destination
+// seront servis après la commande
+// elementMoved est false si la source n'existe pas ; ErrWrongDataType si une des clés n'est pas une liste
+func (redisStorage *RedisInMemoryStorage) MoveListElement(sourceListKey string, destinationListKey string, popFromLeft bool, pushToLeft bool) (string, bool, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	sourceList, listError := redisStorage.getListForUpdate(sourceListKey)
+	if listError != nil {
+		return "", false, listError
+	}
+	if _, destinationError := redisStorage.getListForUpdate(destinationListKey); destinationError != nil {
+		return "", false, destinationError
+	}
+	if sourceList == nil {
+		return "", false, nil
+	}
+
+	movedElement, _ := redisStorage.popListElement(sourceListKey, popFromLeft)
+	redisStorage.pushListElements(destinationListKey, []string{movedElement}, pushToLeft)
+	return movedElement, true, nil
 }
@@ -25,6 +25,11 @@ func (redisStorage *RedisInMemoryStorage) FindKeysByPattern(searchPattern string
 	return matchingKeys
 }
 
+// MatchesGlobPattern expose le pattern matching glob aux autres composants (ex: PSUBSCRIBE)
+func MatchesGlobPattern(searchPattern, targetString string) bool {
+	return matchesGlobPattern(searchPattern, targetString)
+}
+
 // matchesGlobPattern implémente le pattern matching style Redis avec *, ?, et [...]
 func matchesGlobPattern(searchPattern, targetString string) bool {
 	return matchGlobRecursive(searchPattern, targetString, 0, 0)
@@ -0,0 +1,134 @@
+package storage
+
+import "time"
+
+// smallCollectionScanThreshold est la taille jusqu'à laquelle SSCAN/HSCAN/ZSCAN renvoient toute la collection
+// en un seul appel (comme Redis pour les encodages compacts)
+const smallCollectionScanThreshold = 128
+
+// ScanParameters regroupe les options de SCAN, SSCAN, HSCAN et ZSCAN
+type ScanParameters struct {
+	ScanCursor uint64
+	// ElementCount est le nombre indicatif d'éléments examinés par appel (COUNT)
+	ElementCount int
+	// MatchPattern filtre les éléments retournés (MATCH), vide pour tout accepter
+	MatchPattern string
+	// DataTypeFilter restreint SCAN aux clés d'un type (TYPE), nil pour tous les types
+	DataTypeFilter *RedisDataType
+}
+
+// matchesScanPattern indique si un élément examiné correspond au motif MATCH
+func (scanParameters ScanParameters) matchesScanPattern(scannedElement string) bool {
+	return scanParameters.MatchPattern == "" || matchesGlobPattern(scanParameters.MatchPattern, scannedElement)
+}
+
+// ScanKeys parcourt une partie des clés de la base à partir du curseur (SCAN)
+// Retourne le curseur suivant (0 à la fin de l'itération) et les clés non expirées retenues par les filtres
+func (redisStorage *RedisInMemoryStorage) ScanKeys(scanParameters ScanParameters) (uint64, []string) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	matchingKeys := []string{}
+	nextCursor := redisStorage.keyScanIndex.scanBuckets(scanParameters.ScanCursor, scanParameters.ElementCount, func(storageKey string) {
+		storageValue := redisStorage.getLiveStorageValue(storageKey)
+		if storageValue == nil {
+			return
+		}
+		if scanParameters.DataTypeFilter != nil && storageValue.DataType != *scanParameters.DataTypeFilter {
+			return
+		}
+		if scanParameters.matchesScanPattern(storageKey) {
+			matchingKeys = append(matchingKeys, storageKey)
+		}
+	})
+	return nextCursor, matchingKeys
+}
+
+// ScanSetMembers parcourt une partie des membres d'un set à partir du curseur (SSCAN)
+func (redisStorage *RedisInMemoryStorage) ScanSetMembers(setKey string, scanParameters ScanParameters) (uint64, []string, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	storageValue := redisStorage.getLiveStorageValue(setKey)
+	if storageValue == nil {
+		return 0, []string{}, nil
+	}
+	if storageValue.DataType != RedisSetType {
+		return 0, nil, ErrWrongDataType
+	}
+
+	redisSetStructure := storageValue.StoredData.(*RedisSetStructure)
+	nextCursor, setMembers := scanCollectionMembers(redisSetStructure.SetElements, redisSetStructure.memberScanIndex, scanParameters)
+	return nextCursor, setMembers, nil
+}
+
+// ScanHashFields parcourt une partie des fields d'un hash à partir du curseur (HSCAN)
+// Les fields sont retournés avec leur valeur, alternés : field1, valeur1, field2, valeur2...
+func (redisStorage *RedisInMemoryStorage) ScanHashFields(hashKey string, scanParameters ScanParameters) (uint64, []string, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	storageValue := redisStorage.getLiveStorageValue(hashKey)
+	if storageValue == nil {
+		return 0, []string{}, nil
+	}
+	if storageValue.DataType != RedisHashType {
+		return 0, nil, ErrWrongDataType
+	}
+
+	redisHashStructure := storageValue.StoredData.(*RedisHashStructure)
+	nextCursor, fieldNames := scanCollectionMembers(redisHashStructure.HashFields, redisHashStructure.memberScanIndex, scanParameters)
+	currentTime := time.Now()
+	fieldsAndValues := make([]string, 0, 2*len(fieldNames))
+	for _, fieldName := range fieldNames {
+		if redisHashStructure.isFieldLive(fieldName, currentTime) {
+			fieldsAndValues = append(fieldsAndValues, fieldName, redisHashStructure.HashFields[fieldName])
+		}
+	}
+	return nextCursor, fieldsAndValues, nil
+}
+
+// ScanSortedSetMembers parcourt une partie des membres d'un sorted set à partir du curseur (ZSCAN)
+func (redisStorage *RedisInMemoryStorage) ScanSortedSetMembers(sortedSetKey string, scanParameters ScanParameters) (uint64, []SortedSetMember, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	storageValue := redisStorage.getLiveStorageValue(sortedSetKey)
+	if storageValue == nil {
+		return 0, []SortedSetMember{}, nil
+	}
+	if storageValue.DataType != RedisZSetType {
+		return 0, nil, ErrWrongDataType
+	}
+
+	redisSortedSet := storageValue.StoredData.(*RedisSortedSetStructure)
+	memberScores := redisSortedSet.MemberScores
+	nextCursor, memberNames := scanCollectionMembers(memberScores, redisSortedSet.memberScanIndex, scanParameters)
+	sortedSetMembers := make([]SortedSetMember, 0, len(memberNames))
+	for _, memberName := range memberNames {
+		sortedSetMembers = append(sortedSetMembers, SortedSetMember{MemberName: memberName, MemberScore: memberScores[memberName]})
+	}
+	return nextCursor, sortedSetMembers, nil
+}
+
+// scanCollectionMembers parcourt une partie des membres d'une collection à partir du curseur (appelant doit détenir le verrou)
+// Une collection restée petite (sans index) est renvoyée en entier avec le curseur 0 ; au-delà, l'appel reprend
+// au bucket du curseur dans l'index de la collection, comme SCAN, et n'examine qu'environ ElementCount membres
+func scanCollectionMembers[Value any](collectionMembers map[string]Value, memberScanIndex *keyspaceScanIndex, scanParameters ScanParameters) (uint64, []string) {
+	selectedMembers := []string{}
+	if memberScanIndex == nil {
+		for memberName := range collectionMembers {
+			if scanParameters.matchesScanPattern(memberName) {
+				selectedMembers = append(selectedMembers, memberName)
+			}
+		}
+		return 0, selectedMembers
+	}
+
+	nextCursor := memberScanIndex.scanBuckets(scanParameters.ScanCursor, scanParameters.ElementCount, func(memberName string) {
+		if scanParameters.matchesScanPattern(memberName) {
+			selectedMembers = append(selectedMembers, memberName)
+		}
+	})
+	return nextCursor, selectedMembers
+}
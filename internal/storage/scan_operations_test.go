@@ -0,0 +1,306 @@
+package storage
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+// scanUntilComplete itère du curseur 0 jusqu'au retour à 0 et compte les occurrences de chaque élément retourné
+// mutateBetweenCalls est appelée entre deux appels avec le numéro de l'appel suivant
+func scanUntilComplete(t *testing.T, scanStep func(scanCursor uint64) (uint64, []string), mutateBetweenCalls func(callIndex int)) map[string]int {
+	t.Helper()
+	returnedCounts := make(map[string]int)
+	scanCursor := uint64(0)
+	for callIndex := 1; ; callIndex++ {
+		if callIndex > 100000 {
+			t.Fatalf("l'itération ne se termine pas")
+		}
+		var scannedElements []string
+		scanCursor, scannedElements = scanStep(scanCursor)
+		for _, scannedElement := range scannedElements {
+			returnedCounts[scannedElement]++
+		}
+		if scanCursor == 0 {
+			return returnedCounts
+		}
+		mutateBetweenCalls(callIndex)
+	}
+}
+
+func TestScanKeysIteration(t *testing.T) {
+	testCases := []struct {
+		name          string
+		stableKeys    int
+		temporaryKeys int
+		scanCount     int
+		mutateStorage func(redisStorage *RedisInMemoryStorage, callIndex int)
+		// expectExactlyOnce : sans redimensionnement de la table, aucune clé n'est retournée deux fois
+		expectExactlyOnce bool
+	}{
+		{
+			name:              "base stable",
+			stableKeys:        1000,
+			scanCount:         10,
+			mutateStorage:     func(redisStorage *RedisInMemoryStorage, callIndex int) {},
+			expectExactlyOnce: true,
+		},
+		{
+			name:              "COUNT plus grand que la base",
+			stableKeys:        20,
+			scanCount:         1000,
+			mutateStorage:     func(redisStorage *RedisInMemoryStorage, callIndex int) {},
+			expectExactlyOnce: true,
+		},
+		{
+			name:       "base qui grandit pendant l'itération",
+			stableKeys: 100,
+			scanCount:  5,
+			mutateStorage: func(redisStorage *RedisInMemoryStorage, callIndex int) {
+				for addedIndex := 0; addedIndex < 10; addedIndex++ {
+					redisStorage.SetKeyValue("ajout:"+strconv.Itoa(callIndex)+":"+strconv.Itoa(addedIndex), "v", RedisStringType, nil)
+				}
+			},
+		},
+		{
+			name:          "base qui rétrécit pendant l'itération",
+			stableKeys:    50,
+			temporaryKeys: 2000,
+			scanCount:     5,
+			mutateStorage: func(redisStorage *RedisInMemoryStorage, callIndex int) {
+				for removedIndex := callIndex * 100; removedIndex < (callIndex+1)*100; removedIndex++ {
+					redisStorage.DeleteKeyValue("temporaire:" + strconv.Itoa(removedIndex))
+				}
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			for keyIndex := 0; keyIndex < testCase.stableKeys; keyIndex++ {
+				redisStorage.SetKeyValue("stable:"+strconv.Itoa(keyIndex), "v", RedisStringType, nil)
+			}
+			for keyIndex := 0; keyIndex < testCase.temporaryKeys; keyIndex++ {
+				redisStorage.SetKeyValue("temporaire:"+strconv.Itoa(keyIndex), "v", RedisStringType, nil)
+			}
+
+			returnedCounts := scanUntilComplete(t, func(scanCursor uint64) (uint64, []string) {
+				return redisStorage.ScanKeys(ScanParameters{ScanCursor: scanCursor, ElementCount: testCase.scanCount, MatchPattern: "stable:*"})
+			}, func(callIndex int) { testCase.mutateStorage(redisStorage, callIndex) })
+
+			if len(returnedCounts) != testCase.stableKeys {
+				t.Fatalf("%d clés stables retournées, attendu %d", len(returnedCounts), testCase.stableKeys)
+			}
+			for storageKey, returnedCount := range returnedCounts {
+				if testCase.expectExactlyOnce && returnedCount != 1 {
+					t.Fatalf("clé %s retournée %d fois", storageKey, returnedCount)
+				}
+			}
+		})
+	}
+}
+
+func TestScanKeysFilters(t *testing.T) {
+	redisStorage := NewRedisInMemoryStorage()
+	redisStorage.SetKeyValue("user:1", "v", RedisStringType, nil)
+	redisStorage.SetKeyValue("user:2", "v", RedisStringType, nil)
+	redisStorage.AddMembersToSet("user:set", []string{"a"})
+	redisStorage.SetHashField("session:1", "f", "v")
+	expiredTimeToLive := -time.Second
+	redisStorage.SetKeyValue("user:expiré", "v", RedisStringType, &expiredTimeToLive)
+
+	stringType, setType := RedisStringType, RedisSetType
+	testCases := []struct {
+		name           string
+		scanParameters ScanParameters
+		expectedKeys   []string
+	}{
+		{name: "sans filtre", scanParameters: ScanParameters{ElementCount: 100}, expectedKeys: []string{"session:1", "user:1", "user:2", "user:set"}},
+		{name: "MATCH", scanParameters: ScanParameters{ElementCount: 100, MatchPattern: "user:?"}, expectedKeys: []string{"user:1", "user:2"}},
+		{name: "TYPE", scanParameters: ScanParameters{ElementCount: 100, DataTypeFilter: &setType}, expectedKeys: []string{"user:set"}},
+		{name: "MATCH et TYPE", scanParameters: ScanParameters{ElementCount: 100, MatchPattern: "session:*", DataTypeFilter: &stringType}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			returnedCounts := scanUntilComplete(t, func(scanCursor uint64) (uint64, []string) {
+				scanParameters := testCase.scanParameters
+				scanParameters.ScanCursor = scanCursor
+				return redisStorage.ScanKeys(scanParameters)
+			}, func(callIndex int) {})
+
+			if len(returnedCounts) != len(testCase.expectedKeys) {
+				t.Fatalf("clés retournées %v, attendu %v", returnedCounts, testCase.expectedKeys)
+			}
+			for _, expectedKey := range testCase.expectedKeys {
+				if returnedCounts[expectedKey] != 1 {
+					t.Fatalf("clés retournées %v, attendu %v", returnedCounts, testCase.expectedKeys)
+				}
+			}
+		})
+	}
+}
+
+func TestScanSetMembersIteration(t *testing.T) {
+	testCases := []struct {
+		name             string
+		stableMembers    int
+		scanCount        int
+		addedPerCall     int
+		removedPerCall   int
+		expectSingleCall bool
+		// expectExactlyOnce : sans réduction de l'index du set, aucun membre n'est retourné deux fois
+		expectExactlyOnce bool
+	}{
+		{name: "petit set renvoyé en un appel", stableMembers: smallCollectionScanThreshold, scanCount: 10, expectSingleCall: true, expectExactlyOnce: true},
+		{name: "grand set stable", stableMembers: 1000, scanCount: 10, expectExactlyOnce: true},
+		{name: "grand set qui grandit", stableMembers: 300, scanCount: 10, addedPerCall: 5, expectExactlyOnce: true},
+		{name: "grand set qui rétrécit", stableMembers: 200, scanCount: 7, removedPerCall: 40},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			for memberIndex := 0; memberIndex < testCase.stableMembers; memberIndex++ {
+				redisStorage.AddMembersToSet("s", []string{"stable:" + strconv.Itoa(memberIndex)})
+			}
+			if testCase.removedPerCall > 0 {
+				for memberIndex := 0; memberIndex < 2000; memberIndex++ {
+					redisStorage.AddMembersToSet("s", []string{"temporaire:" + strconv.Itoa(memberIndex)})
+				}
+			}
+
+			callCount := 0
+			returnedCounts := scanUntilComplete(t, func(scanCursor uint64) (uint64, []string) {
+				callCount++
+				nextCursor, setMembers, scanError := redisStorage.ScanSetMembers("s", ScanParameters{ScanCursor: scanCursor, ElementCount: testCase.scanCount, MatchPattern: "stable:*"})
+				if scanError != nil {
+					t.Fatalf("SSCAN: %v", scanError)
+				}
+				return nextCursor, setMembers
+			}, func(callIndex int) {
+				for mutationIndex := 0; mutationIndex < testCase.addedPerCall; mutationIndex++ {
+					redisStorage.AddMembersToSet("s", []string{"ajout:" + strconv.Itoa(callIndex*testCase.addedPerCall+mutationIndex)})
+				}
+				for mutationIndex := 0; mutationIndex < testCase.removedPerCall; mutationIndex++ {
+					redisStorage.RemoveMembersFromSet("s", []string{"temporaire:" + strconv.Itoa(callIndex*testCase.removedPerCall+mutationIndex)})
+				}
+			})
+
+			if testCase.expectSingleCall && callCount != 1 {
+				t.Fatalf("%d appels, attendu un seul", callCount)
+			}
+			if len(returnedCounts) != testCase.stableMembers {
+				t.Fatalf("%d membres stables retournés, attendu %d", len(returnedCounts), testCase.stableMembers)
+			}
+			// Le curseur sur les buckets garantit qu'un membre présent pendant toute l'itération est retourné au moins une fois
+			for setMember, returnedCount := range returnedCounts {
+				if testCase.expectExactlyOnce && returnedCount != 1 {
+					t.Fatalf("membre %s retourné %d fois", setMember, returnedCount)
+				}
+			}
+		})
+	}
+}
+
+func TestScanLargeCollectionsByBucket(t *testing.T) {
+	const memberCount = 1000
+	prefilledMembers := make(map[string]bool, memberCount)
+	for memberIndex := 0; memberIndex < memberCount; memberIndex++ {
+		prefilledMembers["m:"+strconv.Itoa(memberIndex)] = true
+	}
+
+	testCases := []struct {
+		name         string
+		fillStorage  func(redisStorage *RedisInMemoryStorage)
+		scanElements func(redisStorage *RedisInMemoryStorage, scanParameters ScanParameters) (uint64, []string)
+	}{
+		{
+			name: "set stocké déjà rempli",
+			fillStorage: func(redisStorage *RedisInMemoryStorage) {
+				redisStorage.SetKeyValue("c", &RedisSetStructure{SetElements: prefilledMembers}, RedisSetType, nil)
+			},
+			scanElements: func(redisStorage *RedisInMemoryStorage, scanParameters ScanParameters) (uint64, []string) {
+				nextCursor, setMembers, _ := redisStorage.ScanSetMembers("c", scanParameters)
+				return nextCursor, setMembers
+			},
+		},
+		{
+			name: "hash rempli field par field",
+			fillStorage: func(redisStorage *RedisInMemoryStorage) {
+				for memberName := range prefilledMembers {
+					redisStorage.SetHashField("c", memberName, "v")
+				}
+			},
+			scanElements: func(redisStorage *RedisInMemoryStorage, scanParameters ScanParameters) (uint64, []string) {
+				nextCursor, fieldsAndValues, _ := redisStorage.ScanHashFields("c", scanParameters)
+				var fieldNames []string
+				for fieldIndex := 0; fieldIndex < len(fieldsAndValues); fieldIndex += 2 {
+					fieldNames = append(fieldNames, fieldsAndValues[fieldIndex])
+				}
+				return nextCursor, fieldNames
+			},
+		},
+		{
+			name: "sorted set rempli membre par membre",
+			fillStorage: func(redisStorage *RedisInMemoryStorage) {
+				for memberName := range prefilledMembers {
+					redisStorage.AddMembersToSortedSet("c", []SortedSetMember{{MemberName: memberName, MemberScore: 1}}, SortedSetAddOptions{})
+				}
+			},
+			scanElements: func(redisStorage *RedisInMemoryStorage, scanParameters ScanParameters) (uint64, []string) {
+				nextCursor, sortedSetMembers, _ := redisStorage.ScanSortedSetMembers("c", scanParameters)
+				var memberNames []string
+				for _, sortedSetMember := range sortedSetMembers {
+					memberNames = append(memberNames, sortedSetMember.MemberName)
+				}
+				return nextCursor, memberNames
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			testCase.fillStorage(redisStorage)
+
+			// Un appel n'examine que les buckets nécessaires pour atteindre COUNT, pas toute la collection
+			callCount := 0
+			returnedCounts := scanUntilComplete(t, func(scanCursor uint64) (uint64, []string) {
+				callCount++
+				nextCursor, scannedElements := testCase.scanElements(redisStorage, ScanParameters{ScanCursor: scanCursor, ElementCount: 10})
+				if len(scannedElements) > memberCount/10 {
+					t.Fatalf("%d membres retournés en un appel avec COUNT 10", len(scannedElements))
+				}
+				return nextCursor, scannedElements
+			}, func(callIndex int) {})
+
+			if callCount < memberCount/100 {
+				t.Fatalf("%d appels seulement pour %d membres avec COUNT 10", callCount, memberCount)
+			}
+			if len(returnedCounts) != memberCount {
+				t.Fatalf("%d membres retournés, attendu %d", len(returnedCounts), memberCount)
+			}
+			for memberName, returnedCount := range returnedCounts {
+				if returnedCount != 1 {
+					t.Fatalf("membre %s retourné %d fois", memberName, returnedCount)
+				}
+			}
+		})
+	}
+}
+
+func TestScanCollectionsOfOtherTypes(t *testing.T) {
+	redisStorage := NewRedisInMemoryStorage()
+	redisStorage.SetKeyValue("chaîne", "v", RedisStringType, nil)
+	redisStorage.SetHashField("h", "f1", "v1")
+	redisStorage.SetHashField("h", "f2", "v2")
+
+	if _, _, scanError := redisStorage.ScanSetMembers("chaîne", ScanParameters{ElementCount: 10}); scanError != ErrWrongDataType {
+		t.Fatalf("SSCAN sur une chaîne: %v, attendu ErrWrongDataType", scanError)
+	}
+	if nextCursor, setMembers, scanError := redisStorage.ScanSetMembers("absente", ScanParameters{ElementCount: 10}); nextCursor != 0 || len(setMembers) != 0 || scanError != nil {
+		t.Fatalf("SSCAN sur une clé absente: %d %v %v", nextCursor, setMembers, scanError)
+	}
+	nextCursor, fieldsAndValues, scanError := redisStorage.ScanHashFields("h", ScanParameters{ElementCount: 10, MatchPattern: "f2"})
+	if nextCursor != 0 || scanError != nil || len(fieldsAndValues) != 2 || fieldsAndValues[0] != "f2" || fieldsAndValues[1] != "v2" {
+		t.Fatalf("HSCAN MATCH f2: %d %v %v", nextCursor, fieldsAndValues, scanError)
+	}
+}
@@ -0,0 +1,159 @@
+package storage
+
+import "sort"
+
+// SetAlgebraOperation désigne une opération ensembliste entre plusieurs sets (SINTER, SUNION, SDIFF)
+type SetAlgebraOperation int
+
+const (
+	SetIntersection SetAlgebraOperation = iota
+	SetUnion
+	SetDifference
+)
+
+// ComputeSetAlgebra retourne le résultat d'une opération ensembliste (SINTER, SUNION, SDIFF)
+// Une clé absente est traitée comme un set vide ; ErrWrongDataType si une clé contient un autre type
+func (redisStorage *RedisInMemoryStorage) ComputeSetAlgebra(setOperation SetAlgebraOperation, setKeys []string) ([]string, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	resultMembers, algebraError := redisStorage.computeSetAlgebra(setOperation, setKeys)
+	if algebraError != nil {
+		return nil, algebraError
+	}
+	setMembers := make([]string, 0, len(resultMembers))
+	for setMember := range resultMembers {
+		setMembers = append(setMembers, setMember)
+	}
+	return setMembers, nil
+}
+
+// StoreSetAlgebra enregistre le résultat d'une opération ensembliste dans destinationKey (SINTERSTORE, SUNIONSTORE, SDIFFSTORE)
+// La destination est remplacée quel que soit son type (TTL compris) et supprimée si le résultat est vide
+// Retourne le nombre de membres du résultat
+func (redisStorage *RedisInMemoryStorage) StoreSetAlgebra(setOperation SetAlgebraOperation, destinationKey string, setKeys []string) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	resultMembers, algebraError := redisStorage.computeSetAlgebra(setOperation, setKeys)
+	if algebraError != nil {
+		return 0, algebraError
+	}
+
+	redisStorage.removeEntry(destinationKey)
+	if len(resultMembers) > 0 {
+		redisStorage.storeEntry(destinationKey, &RedisStorageValue{
+			StoredData: &RedisSetStructure{SetElements: resultMembers},
+			DataType:   RedisSetType,
+		})
+	}
+	redisStorage.markKeyModified(destinationKey)
+	return len(resultMembers), nil
+}
+
+// CountSetIntersection retourne la taille de l'intersection de plusieurs sets (SINTERCARD)
+// Le calcul s'arrête dès que resultLimit membres sont trouvés (0 : pas de limite)
+func (redisStorage *RedisInMemoryStorage) CountSetIntersection(setKeys []string, resultLimit int) (int, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	inputSets, algebraError := redisStorage.collectInputSets(setKeys)
+	if algebraError != nil {
+		return 0, algebraError
+	}
+
+	intersectionSize := 0
+	iterateSetIntersection(inputSets, func(string) bool {
+		intersectionSize++
+		return resultLimit == 0 || intersectionSize < resultLimit
+	})
+	return intersectionSize, nil
+}
+
+// collectInputSets retourne les sets des clés dans l'ordre (nil pour une clé absente) (appelant doit détenir le verrou)
+func (redisStorage *RedisInMemoryStorage) collectInputSets(setKeys []string) ([]*RedisSetStructure, error) {
+	inputSets := make([]*RedisSetStructure, 0, len(setKeys))
+	for _, setKey := range setKeys {
+		redisSetStructure, setError := redisStorage.getLiveSet(setKey)
+		if setError != nil {
+			return nil, setError
+		}
+		inputSets = append(inputSets, redisSetStructure)
+	}
+	return inputSets, nil
+}
+
+// computeSetAlgebra calcule une opération ensembliste dans un nouveau dictionnaire (appelant doit détenir le verrou)
+func (redisStorage *RedisInMemoryStorage) computeSetAlgebra(setOperation SetAlgebraOperation, setKeys []string) (map[string]bool, error) {
+	inputSets, algebraError := redisStorage.collectInputSets(setKeys)
+	if algebraError != nil {
+		return nil, algebraError
+	}
+
+	resultMembers := make(map[string]bool)
+	switch setOperation {
+	case SetIntersection:
+		iterateSetIntersection(inputSets, func(setMember string) bool {
+			resultMembers[setMember] = true
+			return true
+		})
+	case SetUnion:
+		for _, inputSet := range inputSets {
+			if inputSet == nil {
+				continue
+			}
+			for setMember := range inputSet.SetElements {
+				resultMembers[setMember] = true
+			}
+		}
+	case SetDifference:
+		if inputSets[0] == nil {
+			return resultMembers, nil
+		}
+		for setMember := range inputSets[0].SetElements {
+			if !isMemberOfAnySet(inputSets[1:], setMember) {
+				resultMembers[setMember] = true
+			}
+		}
+	}
+	return resultMembers, nil
+}
+
+// iterateSetIntersection appelle visitMember pour chaque membre commun à tous les sets, jusqu'à ce qu'il retourne false
+// Le plus petit set est parcouru et les membres sont cherchés dans les autres, du plus petit au plus grand
+func iterateSetIntersection(inputSets []*RedisSetStructure, visitMember func(setMember string) bool) {
+	for _, inputSet := range inputSets {
+		if inputSet == nil {
+			return
+		}
+	}
+
+	sortedSets := make([]*RedisSetStructure, len(inputSets))
+	copy(sortedSets, inputSets)
+	sort.Slice(sortedSets, func(firstIndex, secondIndex int) bool {
+		return len(sortedSets[firstIndex].SetElements) < len(sortedSets[secondIndex].SetElements)
+	})
+
+	for setMember := range sortedSets[0].SetElements {
+		isCommonMember := true
+		for _, otherSet := range sortedSets[1:] {
+			if !otherSet.SetElements[setMember] {
+				isCommonMember = false
+				break
+			}
+		}
+		if isCommonMember && !visitMember(setMember) {
+			return
+		}
+	}
+}
+
+// isMemberOfAnySet indique si un membre appartient à au moins un des sets (les sets nil sont ignorés)
+func isMemberOfAnySet(inputSets []*RedisSetStructure, setMember string) bool {
+	for _, inputSet := range inputSets {
+		if inputSet != nil && inputSet.SetElements[setMember] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// newSetAlgebraTestStorage crée les sets utilisés par les tests d'algèbre ensembliste
+func newSetAlgebraTestStorage() *RedisInMemoryStorage {
+	redisStorage := NewRedisInMemoryStorage()
+	redisStorage.AddMembersToSet("tags:go", []string{"a", "b", "c", "d"})
+	redisStorage.AddMembersToSet("tags:redis", []string{"b", "c", "e"})
+	redisStorage.AddMembersToSet("tags:cache", []string{"c", "f"})
+	redisStorage.SetKeyValue("chaîne", "v", RedisStringType, nil)
+	return redisStorage
+}
+
+func TestComputeSetAlgebra(t *testing.T) {
+	testCases := []struct {
+		name            string
+		setOperation    SetAlgebraOperation
+		setKeys         []string
+		expectedMembers []string
+		expectedError   error
+	}{
+		{name: "intersection", setOperation: SetIntersection, setKeys: []string{"tags:go", "tags:redis"}, expectedMembers: []string{"b", "c"}},
+		{name: "intersection de trois sets", setOperation: SetIntersection, setKeys: []string{"tags:go", "tags:redis", "tags:cache"}, expectedMembers: []string{"c"}},
+		{name: "intersection avec une clé absente", setOperation: SetIntersection, setKeys: []string{"tags:go", "absente"}, expectedMembers: []string{}},
+		{name: "union", setOperation: SetUnion, setKeys: []string{"tags:redis", "absente", "tags:cache"}, expectedMembers: []string{"b", "c", "e", "f"}},
+		{name: "différence", setOperation: SetDifference, setKeys: []string{"tags:go", "tags:redis", "tags:cache"}, expectedMembers: []string{"a", "d"}},
+		{name: "différence depuis une clé absente", setOperation: SetDifference, setKeys: []string{"absente", "tags:go"}, expectedMembers: []string{}},
+		{name: "différence d'un seul set", setOperation: SetDifference, setKeys: []string{"tags:cache"}, expectedMembers: []string{"c", "f"}},
+		{name: "clé d'un autre type", setOperation: SetUnion, setKeys: []string{"tags:go", "chaîne"}, expectedError: ErrWrongDataType},
+		{name: "clé d'un autre type après une clé absente", setOperation: SetIntersection, setKeys: []string{"absente", "chaîne"}, expectedError: ErrWrongDataType},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := newSetAlgebraTestStorage()
+			resultMembers, algebraError := redisStorage.ComputeSetAlgebra(testCase.setOperation, testCase.setKeys)
+			if algebraError != testCase.expectedError {
+				t.Fatalf("erreur %v, attendu %v", algebraError, testCase.expectedError)
+			}
+			slices.Sort(resultMembers)
+			if testCase.expectedError == nil && !slices.Equal(resultMembers, testCase.expectedMembers) {
+				t.Fatalf("membres %v, attendu %v", resultMembers, testCase.expectedMembers)
+			}
+		})
+	}
+}
+
+func TestStoreSetAlgebra(t *testing.T) {
+	testCases := []struct {
+		name              string
+		setOperation      SetAlgebraOperation
+		destinationKey    string
+		setKeys           []string
+		expectedSize      int
+		expectedMembers   []string
+		expectedError     error
+		destinationExists bool
+	}{
+		{name: "intersection dans une nouvelle clé", setOperation: SetIntersection, destinationKey: "résultat", setKeys: []string{"tags:go", "tags:redis"}, expectedSize: 2, expectedMembers: []string{"b", "c"}, destinationExists: true},
+		{name: "destination source de l'opération", setOperation: SetUnion, destinationKey: "tags:cache", setKeys: []string{"tags:cache", "tags:redis"}, expectedSize: 4, expectedMembers: []string{"b", "c", "e", "f"}, destinationExists: true},
+		{name: "destination d'un autre type remplacée", setOperation: SetDifference, destinationKey: "chaîne", setKeys: []string{"tags:cache", "tags:go"}, expectedSize: 1, expectedMembers: []string{"f"}, destinationExists: true},
+		{name: "résultat vide : destination supprimée", setOperation: SetIntersection, destinationKey: "tags:go", setKeys: []string{"tags:cache", "absente"}},
+		{name: "source d'un autre type : destination inchangée", setOperation: SetUnion, destinationKey: "tags:go", setKeys: []string{"chaîne"}, expectedError: ErrWrongDataType, expectedMembers: []string{"a", "b", "c", "d"}, destinationExists: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := newSetAlgebraTestStorage()
+			redisStorage.SetKeyExpiration(testCase.destinationKey, time.Now().Add(time.Minute), KeyExpirationOptions{})
+			resultSize, algebraError := redisStorage.StoreSetAlgebra(testCase.setOperation, testCase.destinationKey, testCase.setKeys)
+			if algebraError != testCase.expectedError || resultSize != testCase.expectedSize {
+				t.Fatalf("taille %d (%v), attendu %d (%v)", resultSize, algebraError, testCase.expectedSize, testCase.expectedError)
+			}
+			if destinationExists := redisStorage.CheckKeyExists(testCase.destinationKey); destinationExists != testCase.destinationExists {
+				t.Fatalf("destination présente=%v, attendu %v", destinationExists, testCase.destinationExists)
+			}
+			if !testCase.destinationExists {
+				return
+			}
+			destinationMembers := redisStorage.GetAllSetMembers(testCase.destinationKey)
+			slices.Sort(destinationMembers)
+			if !slices.Equal(destinationMembers, testCase.expectedMembers) {
+				t.Fatalf("destination %v, attendu %v", destinationMembers, testCase.expectedMembers)
+			}
+			// Le résultat remplace la destination, TTL compris ; une erreur la laisse intacte
+			if expirationTime, _ := redisStorage.GetKeyExpiration(testCase.destinationKey); (expirationTime != nil) != (testCase.expectedError != nil) {
+				t.Fatalf("expiration de la destination %v", expirationTime)
+			}
+		})
+	}
+}
+
+func TestCountSetIntersection(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setKeys       []string
+		resultLimit   int
+		expectedCount int
+		expectedError error
+	}{
+		{name: "sans limite", setKeys: []string{"tags:go", "tags:redis"}, expectedCount: 2},
+		{name: "limite atteinte", setKeys: []string{"tags:go", "tags:redis"}, resultLimit: 1, expectedCount: 1},
+		{name: "limite supérieure au résultat", setKeys: []string{"tags:go", "tags:redis", "tags:cache"}, resultLimit: 5, expectedCount: 1},
+		{name: "un seul set", setKeys: []string{"tags:go"}, expectedCount: 4},
+		{name: "clé absente", setKeys: []string{"tags:go", "absente"}, expectedCount: 0},
+		{name: "clé d'un autre type", setKeys: []string{"tags:go", "chaîne"}, expectedError: ErrWrongDataType},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := newSetAlgebraTestStorage()
+			intersectionSize, intersectionError := redisStorage.CountSetIntersection(testCase.setKeys, testCase.resultLimit)
+			if intersectionError != testCase.expectedError || intersectionSize != testCase.expectedCount {
+				t.Fatalf("taille %d (%v), attendu %d (%v)", intersectionSize, intersectionError, testCase.expectedCount, testCase.expectedError)
+			}
+		})
+	}
+}
+
+func TestRandomSetMembers(t *testing.T) {
+	testCases := []struct {
+		name             string
+		memberCount      int
+		allowRepetitions bool
+		popMembers       bool
+		expectedCount    int
+		expectedLeft     int
+	}{
+		{name: "SRANDMEMBER distincts", memberCount: 3, expectedCount: 3, expectedLeft: 4},
+		{name: "SRANDMEMBER plus que le set", memberCount: 10, expectedCount: 4, expectedLeft: 4},
+		{name: "SRANDMEMBER avec répétitions", memberCount: 10, allowRepetitions: true, expectedCount: 10, expectedLeft: 4},
+		{name: "SRANDMEMBER zéro", memberCount: 0, expectedCount: 0, expectedLeft: 4},
+		{name: "SPOP partiel", memberCount: 3, popMembers: true, expectedCount: 3, expectedLeft: 1},
+		{name: "SPOP de tout le set", memberCount: 10, popMembers: true, expectedCount: 4, expectedLeft: 0},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := newSetAlgebraTestStorage()
+			var randomMembers []string
+			var randomError error
+			if testCase.popMembers {
+				randomMembers, randomError = redisStorage.PopRandomSetMembers("tags:go", testCase.memberCount)
+			} else {
+				randomMembers, randomError = redisStorage.GetRandomSetMembers("tags:go", testCase.memberCount, testCase.allowRepetitions)
+			}
+			if randomError != nil || len(randomMembers) != testCase.expectedCount {
+				t.Fatalf("membres %v (%v), attendu %d membres", randomMembers, randomError, testCase.expectedCount)
+			}
+
+			distinctMembers := make(map[string]bool)
+			for _, randomMember := range randomMembers {
+				if !slices.Contains([]string{"a", "b", "c", "d"}, randomMember) {
+					t.Fatalf("membre %q absent du set", randomMember)
+				}
+				if distinctMembers[randomMember] && !testCase.allowRepetitions {
+					t.Fatalf("membre %q tiré deux fois", randomMember)
+				}
+				distinctMembers[randomMember] = true
+				if testCase.popMembers && redisStorage.CheckSetMemberExists("tags:go", randomMember) {
+					t.Fatalf("membre %q retiré mais encore présent", randomMember)
+				}
+			}
+			if setCardinality, _ := redisStorage.GetSetCardinality("tags:go"); setCardinality != testCase.expectedLeft {
+				t.Fatalf("%d membres restants, attendu %d", setCardinality, testCase.expectedLeft)
+			}
+			if redisStorage.CheckKeyExists("tags:go") != (testCase.expectedLeft > 0) {
+				t.Fatalf("un set vidé doit être supprimé")
+			}
+		})
+	}
+}
+
+func TestMoveSetMember(t *testing.T) {
+	testCases := []struct {
+		name                string
+		sourceKey           string
+		destinationKey      string
+		movedMember         string
+		expectedMoved       bool
+		expectedError       error
+		expectedSource      int
+		expectedDestination int
+	}{
+		{name: "vers un set existant", sourceKey: "tags:cache", destinationKey: "tags:redis", movedMember: "f", expectedMoved: true, expectedSource: 1, expectedDestination: 4},
+		{name: "membre déjà présent dans la destination", sourceKey: "tags:cache", destinationKey: "tags:redis", movedMember: "c", expectedMoved: true, expectedSource: 1, expectedDestination: 3},
+		{name: "vers une nouvelle clé", sourceKey: "tags:cache", destinationKey: "nouveau", movedMember: "f", expectedMoved: true, expectedSource: 1, expectedDestination: 1},
+		{name: "vers le même set", sourceKey: "tags:cache", destinationKey: "tags:cache", movedMember: "f", expectedMoved: true, expectedSource: 2, expectedDestination: 2},
+		{name: "membre absent", sourceKey: "tags:cache", destinationKey: "tags:redis", movedMember: "z", expectedSource: 2, expectedDestination: 3},
+		{name: "source absente", sourceKey: "absente", destinationKey: "tags:redis", movedMember: "b", expectedDestination: 3},
+		{name: "destination d'un autre type", sourceKey: "tags:cache", destinationKey: "chaîne", movedMember: "f", expectedError: ErrWrongDataType, expectedSource: 2},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := newSetAlgebraTestStorage()
+			memberMoved, moveError := redisStorage.MoveSetMember(testCase.sourceKey, testCase.destinationKey, testCase.movedMember)
+			if memberMoved != testCase.expectedMoved || moveError != testCase.expectedError {
+				t.Fatalf("déplacé=%v (%v), attendu %v (%v)", memberMoved, moveError, testCase.expectedMoved, testCase.expectedError)
+			}
+			if sourceCardinality, _ := redisStorage.GetSetCardinality(testCase.sourceKey); sourceCardinality != testCase.expectedSource {
+				t.Fatalf("source: %d membres, attendu %d", sourceCardinality, testCase.expectedSource)
+			}
+			if destinationCardinality, _ := redisStorage.GetSetCardinality(testCase.destinationKey); destinationCardinality != testCase.expectedDestination {
+				t.Fatalf("destination: %d membres, attendu %d", destinationCardinality, testCase.expectedDestination)
+			}
+		})
+	}
+}
@@ -1,19 +1,42 @@
 package storage
 
+import "math/rand/v2"
+
+// addMember ajoute un membre au set et à son index de SSCAN, retourne false s'il était déjà présent
+func (redisSetStructure *RedisSetStructure) addMember(memberName string) bool {
+	if redisSetStructure.SetElements[memberName] {
+		return false
+	}
+	redisSetStructure.SetElements[memberName] = true
+	redisSetStructure.memberScanIndex = indexAddedMember(redisSetStructure.memberScanIndex, redisSetStructure.SetElements, memberName)
+	return true
+}
+
+// removeMember retire un membre du set et de son index de SSCAN, retourne false s'il était absent
+func (redisSetStructure *RedisSetStructure) removeMember(memberName string) bool {
+	if !redisSetStructure.SetElements[memberName] {
+		return false
+	}
+	delete(redisSetStructure.SetElements, memberName)
+	unindexRemovedMember(redisSetStructure.memberScanIndex, memberName)
+	return true
+}
+
 // AddMembersToSet ajoute des membres à un set
 func (redisStorage *RedisInMemoryStorage) AddMembersToSet(setKey string, newMembers []string) int {
 	redisStorage.storageMutex.Lock()
 	defer redisStorage.storageMutex.Unlock()
 
+	redisStorage.removeKeyIfExpired(setKey)
 	storageValue, keyExists := redisStorage.storageData[setKey]
 	var redisSetStructure *RedisSetStructure
 
 	if !keyExists {
 		redisSetStructure = &RedisSetStructure{SetElements: make(map[string]bool)}
-		redisStorage.storageData[setKey] = &RedisStorageValue{
+		redisStorage.storeEntry(setKey, &RedisStorageValue{
 			StoredData: redisSetStructure,
 			DataType:   RedisSetType,
-		}
+		})
 	} else {
 		if storageValue.DataType != RedisSetType {
 			return -1
@@ -23,11 +46,13 @@ func (redisStorage *RedisInMemoryStorage) AddMembersToSet(setKey string, newMemb
 
 	addedMemberCount := 0
 	for _, newMember := range newMembers {
-		if !redisSetStructure.SetElements[newMember] {
-			redisSetStructure.SetElements[newMember] = true
+		if redisSetStructure.addMember(newMember) {
 			addedMemberCount++
 		}
 	}
+	if addedMemberCount > 0 {
+		redisStorage.markKeyModified(setKey)
+	}
 
 	return addedMemberCount
 }
@@ -37,8 +62,8 @@ func (redisStorage *RedisInMemoryStorage) GetAllSetMembers(setKey string) []stri
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
-	storageValue, keyExists := redisStorage.storageData[setKey]
-	if !keyExists {
+	storageValue := redisStorage.getLiveStorageValue(setKey)
+	if storageValue == nil {
 		return []string{}
 	}
 
@@ -60,8 +85,8 @@ func (redisStorage *RedisInMemoryStorage) CheckSetMemberExists(setKey string, me
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
-	storageValue, keyExists := redisStorage.storageData[setKey]
-	if !keyExists {
+	storageValue := redisStorage.getLiveStorageValue(setKey)
+	if storageValue == nil {
 		return false
 	}
 
@@ -72,3 +97,186 @@ func (redisStorage *RedisInMemoryStorage) CheckSetMemberExists(setKey string, me
 	redisSetStructure := storageValue.StoredData.(*RedisSetStructure)
 	return redisSetStructure.SetElements[memberToCheck]
 }
+
+// getLiveSet retourne le set non expiré d'une clé (appelant doit détenir le verrou)
+// Retourne nil si la clé n'existe pas, ErrWrongDataType si elle contient un autre type
+func (redisStorage *RedisInMemoryStorage) getLiveSet(setKey string) (*RedisSetStructure, error) {
+	storageValue := redisStorage.getLiveStorageValue(setKey)
+	if storageValue == nil {
+		return nil, nil
+	}
+	if storageValue.DataType != RedisSetType {
+		return nil, ErrWrongDataType
+	}
+	return storageValue.StoredData.(*RedisSetStructure), nil
+}
+
+// getSetForUpdate retourne le set d'une clé après suppression si elle a expiré (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) getSetForUpdate(setKey string) (*RedisSetStructure, error) {
+	redisStorage.removeKeyIfExpired(setKey)
+	return redisStorage.getLiveSet(setKey)
+}
+
+// removeSetIfEmpty supprime la clé d'un set vidé et signale la modification (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) removeSetIfEmpty(setKey string, redisSetStructure *RedisSetStructure) {
+	if len(redisSetStructure.SetElements) == 0 {
+		redisStorage.removeEntry(setKey)
+	}
+	redisStorage.markKeyModified(setKey)
+}
+
+// RemoveMembersFromSet supprime des membres d'un set (SREM), la clé est supprimée si le set devient vide
+// Retourne le nombre de membres effectivement supprimés
+func (redisStorage *RedisInMemoryStorage) RemoveMembersFromSet(setKey string, removedMembers []string) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisSetStructure, setError := redisStorage.getSetForUpdate(setKey)
+	if setError != nil || redisSetStructure == nil {
+		return 0, setError
+	}
+
+	removedMemberCount := 0
+	for _, removedMember := range removedMembers {
+		if redisSetStructure.removeMember(removedMember) {
+			removedMemberCount++
+		}
+	}
+	if removedMemberCount > 0 {
+		redisStorage.removeSetIfEmpty(setKey, redisSetStructure)
+	}
+	return removedMemberCount, nil
+}
+
+// GetSetCardinality retourne le nombre de membres d'un set (SCARD), 0 si la clé n'existe pas
+func (redisStorage *RedisInMemoryStorage) GetSetCardinality(setKey string) (int, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSetStructure, setError := redisStorage.getLiveSet(setKey)
+	if setError != nil || redisSetStructure == nil {
+		return 0, setError
+	}
+	return len(redisSetStructure.SetElements), nil
+}
+
+// CheckSetMembersExist indique pour chaque membre s'il appartient au set (SMISMEMBER)
+func (redisStorage *RedisInMemoryStorage) CheckSetMembersExist(setKey string, checkedMembers []string) ([]bool, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSetStructure, setError := redisStorage.getLiveSet(setKey)
+	if setError != nil {
+		return nil, setError
+	}
+	membershipResults := make([]bool, len(checkedMembers))
+	if redisSetStructure != nil {
+		for memberIndex, checkedMember := range checkedMembers {
+			membershipResults[memberIndex] = redisSetStructure.SetElements[checkedMember]
+		}
+	}
+	return membershipResults, nil
+}
+
+// PopRandomSetMembers supprime et retourne jusqu'à memberCount membres tirés au hasard (SPOP)
+// Retourne nil si la clé n'existe pas
+func (redisStorage *RedisInMemoryStorage) PopRandomSetMembers(setKey string, memberCount int) ([]string, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisSetStructure, setError := redisStorage.getSetForUpdate(setKey)
+	if setError != nil || redisSetStructure == nil {
+		return nil, setError
+	}
+
+	poppedMembers := sampleDistinctSetMembers(redisSetStructure, memberCount)
+	if len(poppedMembers) == 0 {
+		return poppedMembers, nil
+	}
+	for _, poppedMember := range poppedMembers {
+		redisSetStructure.removeMember(poppedMember)
+	}
+	redisStorage.removeSetIfEmpty(setKey, redisSetStructure)
+	return poppedMembers, nil
+}
+
+// GetRandomSetMembers retourne des membres tirés au hasard sans modifier le set (SRANDMEMBER)
+// Avec allowRepetitions, memberCount membres sont tirés indépendamment (un membre peut revenir plusieurs fois),
+// sinon au plus memberCount membres distincts ; retourne une liste vide si la clé n'existe pas
+func (redisStorage *RedisInMemoryStorage) GetRandomSetMembers(setKey string, memberCount int, allowRepetitions bool) ([]string, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSetStructure, setError := redisStorage.getLiveSet(setKey)
+	if setError != nil {
+		return nil, setError
+	}
+	if redisSetStructure == nil {
+		return []string{}, nil
+	}
+	if !allowRepetitions {
+		return sampleDistinctSetMembers(redisSetStructure, memberCount), nil
+	}
+
+	setMembers := collectSetMembers(redisSetStructure)
+	randomMembers := make([]string, 0, memberCount)
+	for range memberCount {
+		randomMembers = append(randomMembers, setMembers[rand.IntN(len(setMembers))])
+	}
+	return randomMembers, nil
+}
+
+// collectSetMembers retourne les membres d'un set dans une slice (appelant doit détenir le verrou)
+func collectSetMembers(redisSetStructure *RedisSetStructure) []string {
+	setMembers := make([]string, 0, len(redisSetStructure.SetElements))
+	for setMember := range redisSetStructure.SetElements {
+		setMembers = append(setMembers, setMember)
+	}
+	return setMembers
+}
+
+// sampleDistinctSetMembers tire au plus memberCount membres distincts uniformément (mélange de Fisher-Yates partiel)
+func sampleDistinctSetMembers(redisSetStructure *RedisSetStructure, memberCount int) []string {
+	setMembers := collectSetMembers(redisSetStructure)
+	memberCount = min(memberCount, len(setMembers))
+	for sampleIndex := range memberCount {
+		swapIndex := sampleIndex + rand.IntN(len(setMembers)-sampleIndex)
+		setMembers[sampleIndex], setMembers[swapIndex] = setMembers[swapIndex], setMembers[sampleIndex]
+	}
+	return setMembers[:memberCount]
+}
+
+// MoveSetMember déplace un membre d'un set vers un autre de manière atomique (SMOVE)
+// Retourne false si la source n'existe pas ou ne contient pas le membre ; ErrWrongDataType si une des clés n'est pas un set
+func (redisStorage *RedisInMemoryStorage) MoveSetMember(sourceSetKey string, destinationSetKey string, movedMember string) (bool, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	sourceSet, setError := redisStorage.getSetForUpdate(sourceSetKey)
+	if setError != nil {
+		return false, setError
+	}
+	destinationSet, destinationError := redisStorage.getSetForUpdate(destinationSetKey)
+	if destinationError != nil {
+		return false, destinationError
+	}
+	if sourceSet == nil || !sourceSet.SetElements[movedMember] {
+		return false, nil
+	}
+	if sourceSetKey == destinationSetKey {
+		return true, nil
+	}
+
+	sourceSet.removeMember(movedMember)
+	redisStorage.removeSetIfEmpty(sourceSetKey, sourceSet)
+	if destinationSet == nil {
+		destinationSet = &RedisSetStructure{SetElements: make(map[string]bool)}
+		redisStorage.storeEntry(destinationSetKey, &RedisStorageValue{
+			StoredData: destinationSet,
+			DataType:   RedisSetType,
+		})
+	}
+	destinationSet.addMember(movedMember)
+	redisStorage.markKeyModified(destinationSetKey)
+	return true, nil
+}
@@ -0,0 +1,396 @@
+package storage
+
+import "math"
+
+// SortedSetAddOptions regroupe les options de ZADD (NX, XX, GT, LT, CH)
+type SortedSetAddOptions struct {
+	OnlyIfNotExists     bool
+	OnlyIfExists        bool
+	OnlyIfGreater       bool
+	OnlyIfLess          bool
+	CountChangedMembers bool
+}
+
+// SortedSetScoreRange représente un intervalle de scores avec bornes inclusives ou exclusives
+type SortedSetScoreRange struct {
+	MinimumScore     float64
+	MaximumScore     float64
+	MinimumExclusive bool
+	MaximumExclusive bool
+}
+
+// isAboveMinimum vérifie qu'un score respecte la borne minimale
+func (scoreRange SortedSetScoreRange) isAboveMinimum(memberScore float64) bool {
+	if scoreRange.MinimumExclusive {
+		return memberScore > scoreRange.MinimumScore
+	}
+	return memberScore >= scoreRange.MinimumScore
+}
+
+// isBelowMaximum vérifie qu'un score respecte la borne maximale
+func (scoreRange SortedSetScoreRange) isBelowMaximum(memberScore float64) bool {
+	if scoreRange.MaximumExclusive {
+		return memberScore < scoreRange.MaximumScore
+	}
+	return memberScore <= scoreRange.MaximumScore
+}
+
+// isEmptyRange vérifie si aucun score ne peut appartenir à l'intervalle
+func (scoreRange SortedSetScoreRange) isEmptyRange() bool {
+	if scoreRange.MinimumScore > scoreRange.MaximumScore {
+		return true
+	}
+	return scoreRange.MinimumScore == scoreRange.MaximumScore && (scoreRange.MinimumExclusive || scoreRange.MaximumExclusive)
+}
+
+// newRedisSortedSetStructure crée un sorted set vide
+func newRedisSortedSetStructure() *RedisSortedSetStructure {
+	return &RedisSortedSetStructure{
+		MemberScores:  make(map[string]float64),
+		scoreSkipList: newSortedSetSkipList(),
+	}
+}
+
+// updateMemberScore insère ou déplace un membre dans le sorted set
+func (redisSortedSet *RedisSortedSetStructure) updateMemberScore(memberName string, memberScore float64) {
+	if previousScore, memberExists := redisSortedSet.MemberScores[memberName]; memberExists {
+		if previousScore == memberScore {
+			return
+		}
+		redisSortedSet.scoreSkipList.deleteNode(memberName, previousScore)
+	}
+
+	redisSortedSet.MemberScores[memberName] = memberScore
+	redisSortedSet.scoreSkipList.insertNode(memberName, memberScore)
+	redisSortedSet.memberScanIndex = indexAddedMember(redisSortedSet.memberScanIndex, redisSortedSet.MemberScores, memberName)
+}
+
+// removeMember supprime un membre, retourne true s'il existait
+func (redisSortedSet *RedisSortedSetStructure) removeMember(memberName string) bool {
+	memberScore, memberExists := redisSortedSet.MemberScores[memberName]
+	if !memberExists {
+		return false
+	}
+
+	delete(redisSortedSet.MemberScores, memberName)
+	redisSortedSet.scoreSkipList.deleteNode(memberName, memberScore)
+	unindexRemovedMember(redisSortedSet.memberScanIndex, memberName)
+	return true
+}
+
+// applyAddOptions calcule le score final d'un membre selon les options de ZADD
+func (redisSortedSet *RedisSortedSetStructure) applyAddOptions(memberName string, newScore float64, addOptions SortedSetAddOptions) (float64, bool) {
+	currentScore, memberExists := redisSortedSet.MemberScores[memberName]
+
+	if memberExists && addOptions.OnlyIfNotExists {
+		return currentScore, false
+	}
+	if !memberExists && addOptions.OnlyIfExists {
+		return 0, false
+	}
+	if memberExists && addOptions.OnlyIfGreater && newScore <= currentScore {
+		return currentScore, false
+	}
+	if memberExists && addOptions.OnlyIfLess && newScore >= currentScore {
+		return currentScore, false
+	}
+
+	return newScore, true
+}
+
+// getSortedSetForRead retourne le sorted set d'une clé (appelant doit détenir le verrou)
+func (redisStorage *RedisInMemoryStorage) getSortedSetForRead(sortedSetKey string) (*RedisSortedSetStructure, error) {
+	storageValue := redisStorage.getLiveStorageValue(sortedSetKey)
+	if storageValue == nil {
+		return nil, nil
+	}
+
+	if storageValue.DataType != RedisZSetType {
+		return nil, ErrWrongDataType
+	}
+
+	return storageValue.StoredData.(*RedisSortedSetStructure), nil
+}
+
+// getOrCreateSortedSet retourne le sorted set d'une clé en le créant si besoin (verrou en écriture requis)
+func (redisStorage *RedisInMemoryStorage) getOrCreateSortedSet(sortedSetKey string) (*RedisSortedSetStructure, error) {
+	redisStorage.removeKeyIfExpired(sortedSetKey)
+
+	storageValue, keyExists := redisStorage.storageData[sortedSetKey]
+	if !keyExists {
+		redisSortedSet := newRedisSortedSetStructure()
+		redisStorage.storeEntry(sortedSetKey, &RedisStorageValue{
+			StoredData: redisSortedSet,
+			DataType:   RedisZSetType,
+		})
+		return redisSortedSet, nil
+	}
+
+	if storageValue.DataType != RedisZSetType {
+		return nil, ErrWrongDataType
+	}
+
+	return storageValue.StoredData.(*RedisSortedSetStructure), nil
+}
+
+// deleteSortedSetIfEmpty supprime la clé quand le sorted set n'a plus de membres
+func (redisStorage *RedisInMemoryStorage) deleteSortedSetIfEmpty(sortedSetKey string, redisSortedSet *RedisSortedSetStructure) {
+	if len(redisSortedSet.MemberScores) == 0 {
+		redisStorage.removeEntry(sortedSetKey)
+	}
+}
+
+// AddMembersToSortedSet ajoute ou met à jour des membres, retourne le nombre de membres ajoutés (ou modifiés avec CH)
+func (redisStorage *RedisInMemoryStorage) AddMembersToSortedSet(sortedSetKey string, newMembers []SortedSetMember, addOptions SortedSetAddOptions) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisSortedSet, typeError := redisStorage.getOrCreateSortedSet(sortedSetKey)
+	if typeError != nil {
+		return 0, typeError
+	}
+
+	affectedMemberCount := 0
+	membersUpdated := false
+	for _, newMember := range newMembers {
+		_, memberExisted := redisSortedSet.MemberScores[newMember.MemberName]
+		previousScore := redisSortedSet.MemberScores[newMember.MemberName]
+
+		finalScore, shouldUpdate := redisSortedSet.applyAddOptions(newMember.MemberName, newMember.MemberScore, addOptions)
+		if !shouldUpdate {
+			continue
+		}
+
+		redisSortedSet.updateMemberScore(newMember.MemberName, finalScore)
+		membersUpdated = true
+		if !memberExisted {
+			affectedMemberCount++
+		} else if addOptions.CountChangedMembers && previousScore != finalScore {
+			affectedMemberCount++
+		}
+	}
+
+	if membersUpdated {
+		redisStorage.markKeyModified(sortedSetKey)
+	}
+	redisStorage.deleteSortedSetIfEmpty(sortedSetKey, redisSortedSet)
+	return affectedMemberCount, nil
+}
+
+// IncrementSortedSetMemberScore incrémente le score d'un membre (ZINCRBY / ZADD INCR)
+// Retourne false si les options NX/XX/GT/LT ont empêché la mise à jour
+func (redisStorage *RedisInMemoryStorage) IncrementSortedSetMemberScore(sortedSetKey string, memberName string, scoreIncrement float64, addOptions SortedSetAddOptions) (float64, bool, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisSortedSet, typeError := redisStorage.getOrCreateSortedSet(sortedSetKey)
+	if typeError != nil {
+		return 0, false, typeError
+	}
+	defer redisStorage.deleteSortedSetIfEmpty(sortedSetKey, redisSortedSet)
+
+	incrementedScore := redisSortedSet.MemberScores[memberName] + scoreIncrement
+	if math.IsNaN(incrementedScore) {
+		return 0, false, ErrScoreIsNotANumber
+	}
+
+	finalScore, shouldUpdate := redisSortedSet.applyAddOptions(memberName, incrementedScore, addOptions)
+	if !shouldUpdate {
+		return 0, false, nil
+	}
+
+	redisSortedSet.updateMemberScore(memberName, finalScore)
+	redisStorage.markKeyModified(sortedSetKey)
+	return finalScore, true, nil
+}
+
+// RemoveMembersFromSortedSet supprime des membres et retourne le nombre de membres supprimés
+func (redisStorage *RedisInMemoryStorage) RemoveMembersFromSortedSet(sortedSetKey string, membersToRemove []string) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisSortedSet, typeError := redisStorage.getSortedSetForRead(sortedSetKey)
+	if typeError != nil || redisSortedSet == nil {
+		return 0, typeError
+	}
+
+	removedMemberCount := 0
+	for _, memberToRemove := range membersToRemove {
+		if redisSortedSet.removeMember(memberToRemove) {
+			removedMemberCount++
+		}
+	}
+
+	if removedMemberCount > 0 {
+		redisStorage.markKeyModified(sortedSetKey)
+	}
+	redisStorage.deleteSortedSetIfEmpty(sortedSetKey, redisSortedSet)
+	return removedMemberCount, nil
+}
+
+// GetSortedSetMemberScore retourne le score d'un membre
+func (redisStorage *RedisInMemoryStorage) GetSortedSetMemberScore(sortedSetKey string, memberName string) (float64, bool, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSortedSet, typeError := redisStorage.getSortedSetForRead(sortedSetKey)
+	if typeError != nil || redisSortedSet == nil {
+		return 0, false, typeError
+	}
+
+	memberScore, memberExists := redisSortedSet.MemberScores[memberName]
+	return memberScore, memberExists, nil
+}
+
+// GetSortedSetCardinality retourne le nombre de membres d'un sorted set
+func (redisStorage *RedisInMemoryStorage) GetSortedSetCardinality(sortedSetKey string) (int, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSortedSet, typeError := redisStorage.getSortedSetForRead(sortedSetKey)
+	if typeError != nil || redisSortedSet == nil {
+		return 0, typeError
+	}
+
+	return len(redisSortedSet.MemberScores), nil
+}
+
+// GetSortedSetMemberRank retourne le rang d'un membre (croissant ou décroissant) et son score
+// Les deux sont lus sous le même verrou : ZRANK WITHSCORE ne peut pas mélanger deux états du sorted set
+func (redisStorage *RedisInMemoryStorage) GetSortedSetMemberRank(sortedSetKey string, memberName string, reverseOrder bool) (int, float64, bool, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSortedSet, typeError := redisStorage.getSortedSetForRead(sortedSetKey)
+	if typeError != nil || redisSortedSet == nil {
+		return 0, 0, false, typeError
+	}
+
+	memberScore, memberExists := redisSortedSet.MemberScores[memberName]
+	if !memberExists {
+		return 0, 0, false, nil
+	}
+
+	memberRank := redisSortedSet.scoreSkipList.getMemberRank(memberName, memberScore)
+	if reverseOrder {
+		memberRank = redisSortedSet.scoreSkipList.nodeCount - 1 - memberRank
+	}
+	return memberRank, memberScore, true, nil
+}
+
+// GetSortedSetRangeByRank retourne les membres entre deux rangs (indices négatifs acceptés)
+func (redisStorage *RedisInMemoryStorage) GetSortedSetRangeByRank(sortedSetKey string, startIndex, stopIndex int, reverseOrder bool) ([]SortedSetMember, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSortedSet, typeError := redisStorage.getSortedSetForRead(sortedSetKey)
+	if typeError != nil || redisSortedSet == nil {
+		return []SortedSetMember{}, typeError
+	}
+
+	sortedSetLength := redisSortedSet.scoreSkipList.nodeCount
+
+	// Gérer les indices négatifs (comme Redis)
+	if startIndex < 0 {
+		startIndex = sortedSetLength + startIndex
+	}
+	if stopIndex < 0 {
+		stopIndex = sortedSetLength + stopIndex
+	}
+	if startIndex < 0 {
+		startIndex = 0
+	}
+	if stopIndex >= sortedSetLength {
+		stopIndex = sortedSetLength - 1
+	}
+	if startIndex > stopIndex || startIndex >= sortedSetLength {
+		return []SortedSetMember{}, nil
+	}
+
+	rangeMembers := make([]SortedSetMember, 0, stopIndex-startIndex+1)
+	if reverseOrder {
+		currentNode := redisSortedSet.scoreSkipList.getNodeByRank(sortedSetLength - 1 - startIndex)
+		for remaining := stopIndex - startIndex + 1; remaining > 0 && currentNode != nil; remaining-- {
+			rangeMembers = append(rangeMembers, SortedSetMember{MemberName: currentNode.memberName, MemberScore: currentNode.memberScore})
+			currentNode = currentNode.backwardNode
+		}
+	} else {
+		currentNode := redisSortedSet.scoreSkipList.getNodeByRank(startIndex)
+		for remaining := stopIndex - startIndex + 1; remaining > 0 && currentNode != nil; remaining-- {
+			rangeMembers = append(rangeMembers, SortedSetMember{MemberName: currentNode.memberName, MemberScore: currentNode.memberScore})
+			currentNode = currentNode.nodeLevels[0].forwardNode
+		}
+	}
+
+	return rangeMembers, nil
+}
+
+// GetSortedSetRangeByScore retourne les membres dont le score est dans l'intervalle
+// resultOffset/resultCount implémentent LIMIT (resultCount < 0 = pas de limite)
+func (redisStorage *RedisInMemoryStorage) GetSortedSetRangeByScore(sortedSetKey string, scoreRange SortedSetScoreRange, reverseOrder bool, resultOffset, resultCount int) ([]SortedSetMember, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSortedSet, typeError := redisStorage.getSortedSetForRead(sortedSetKey)
+	if typeError != nil || redisSortedSet == nil || scoreRange.isEmptyRange() || resultOffset < 0 {
+		return []SortedSetMember{}, typeError
+	}
+
+	var currentNode *sortedSetSkipListNode
+	if reverseOrder {
+		currentNode = redisSortedSet.scoreSkipList.getLastNodeInScoreRange(scoreRange)
+	} else {
+		currentNode = redisSortedSet.scoreSkipList.getFirstNodeInScoreRange(scoreRange)
+	}
+
+	// Avancer jusqu'à l'offset demandé
+	for ; currentNode != nil && resultOffset > 0; resultOffset-- {
+		if reverseOrder {
+			currentNode = currentNode.backwardNode
+		} else {
+			currentNode = currentNode.nodeLevels[0].forwardNode
+		}
+	}
+
+	rangeMembers := []SortedSetMember{}
+	for currentNode != nil && resultCount != 0 {
+		if reverseOrder && !scoreRange.isAboveMinimum(currentNode.memberScore) {
+			break
+		}
+		if !reverseOrder && !scoreRange.isBelowMaximum(currentNode.memberScore) {
+			break
+		}
+
+		rangeMembers = append(rangeMembers, SortedSetMember{MemberName: currentNode.memberName, MemberScore: currentNode.memberScore})
+		resultCount--
+
+		if reverseOrder {
+			currentNode = currentNode.backwardNode
+		} else {
+			currentNode = currentNode.nodeLevels[0].forwardNode
+		}
+	}
+
+	return rangeMembers, nil
+}
+
+// CountSortedSetMembersInScoreRange compte les membres dont le score est dans l'intervalle en O(log n)
+func (redisStorage *RedisInMemoryStorage) CountSortedSetMembersInScoreRange(sortedSetKey string, scoreRange SortedSetScoreRange) (int, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	redisSortedSet, typeError := redisStorage.getSortedSetForRead(sortedSetKey)
+	if typeError != nil || redisSortedSet == nil || scoreRange.isEmptyRange() {
+		return 0, typeError
+	}
+
+	firstNode := redisSortedSet.scoreSkipList.getFirstNodeInScoreRange(scoreRange)
+	if firstNode == nil {
+		return 0, nil
+	}
+	lastNode := redisSortedSet.scoreSkipList.getLastNodeInScoreRange(scoreRange)
+
+	firstRank := redisSortedSet.scoreSkipList.getMemberRank(firstNode.memberName, firstNode.memberScore)
+	lastRank := redisSortedSet.scoreSkipList.getMemberRank(lastNode.memberName, lastNode.memberScore)
+	return lastRank - firstRank + 1, nil
+}
@@ -0,0 +1,240 @@
+package storage
+
+import "math/rand"
+
+const (
+	// sortedSetSkipListMaximumLevel limite la hauteur des noeuds (suffisant pour 2^64 éléments)
+	sortedSetSkipListMaximumLevel = 32
+	// sortedSetSkipListLevelProbability probabilité de monter d'un niveau (comme Redis)
+	sortedSetSkipListLevelProbability = 0.25
+)
+
+// sortedSetSkipListLevel représente un pointeur avant d'un noeud et la distance parcourue
+type sortedSetSkipListLevel struct {
+	forwardNode *sortedSetSkipListNode
+	levelSpan   int
+}
+
+// sortedSetSkipListNode représente un membre du sorted set dans la skiplist
+type sortedSetSkipListNode struct {
+	memberName   string
+	memberScore  float64
+	backwardNode *sortedSetSkipListNode
+	nodeLevels   []sortedSetSkipListLevel
+}
+
+// sortedSetSkipList est une skiplist ordonnée par (score, membre) avec spans pour les rangs en O(log n)
+type sortedSetSkipList struct {
+	headerNode   *sortedSetSkipListNode
+	tailNode     *sortedSetSkipListNode
+	nodeCount    int
+	currentLevel int
+}
+
+// newSortedSetSkipList crée une skiplist vide
+func newSortedSetSkipList() *sortedSetSkipList {
+	return &sortedSetSkipList{
+		headerNode: &sortedSetSkipListNode{
+			nodeLevels: make([]sortedSetSkipListLevel, sortedSetSkipListMaximumLevel),
+		},
+		currentLevel: 1,
+	}
+}
+
+// randomSkipListLevel tire aléatoirement la hauteur d'un nouveau noeud
+func randomSkipListLevel() int {
+	nodeLevel := 1
+	for nodeLevel < sortedSetSkipListMaximumLevel && rand.Float64() < sortedSetSkipListLevelProbability {
+		nodeLevel++
+	}
+	return nodeLevel
+}
+
+// isOrderedBefore indique si (score, membre) du noeud précède (score, membre) donnés
+func (skipListNode *sortedSetSkipListNode) isOrderedBefore(memberScore float64, memberName string) bool {
+	if skipListNode.memberScore != memberScore {
+		return skipListNode.memberScore < memberScore
+	}
+	return skipListNode.memberName < memberName
+}
+
+// insertNode insère un membre (supposé absent) dans la skiplist
+func (skipList *sortedSetSkipList) insertNode(memberName string, memberScore float64) *sortedSetSkipListNode {
+	var updateNodes [sortedSetSkipListMaximumLevel]*sortedSetSkipListNode
+	var traversedRanks [sortedSetSkipListMaximumLevel]int
+
+	currentNode := skipList.headerNode
+	for levelIndex := skipList.currentLevel - 1; levelIndex >= 0; levelIndex-- {
+		if levelIndex != skipList.currentLevel-1 {
+			traversedRanks[levelIndex] = traversedRanks[levelIndex+1]
+		}
+		for currentNode.nodeLevels[levelIndex].forwardNode != nil &&
+			currentNode.nodeLevels[levelIndex].forwardNode.isOrderedBefore(memberScore, memberName) {
+			traversedRanks[levelIndex] += currentNode.nodeLevels[levelIndex].levelSpan
+			currentNode = currentNode.nodeLevels[levelIndex].forwardNode
+		}
+		updateNodes[levelIndex] = currentNode
+	}
+
+	newNodeLevel := randomSkipListLevel()
+	if newNodeLevel > skipList.currentLevel {
+		for levelIndex := skipList.currentLevel; levelIndex < newNodeLevel; levelIndex++ {
+			traversedRanks[levelIndex] = 0
+			updateNodes[levelIndex] = skipList.headerNode
+			updateNodes[levelIndex].nodeLevels[levelIndex].levelSpan = skipList.nodeCount
+		}
+		skipList.currentLevel = newNodeLevel
+	}
+
+	newNode := &sortedSetSkipListNode{
+		memberName:  memberName,
+		memberScore: memberScore,
+		nodeLevels:  make([]sortedSetSkipListLevel, newNodeLevel),
+	}
+
+	for levelIndex := 0; levelIndex < newNodeLevel; levelIndex++ {
+		newNode.nodeLevels[levelIndex].forwardNode = updateNodes[levelIndex].nodeLevels[levelIndex].forwardNode
+		updateNodes[levelIndex].nodeLevels[levelIndex].forwardNode = newNode
+
+		// Mise à jour des spans couverts par le nouveau noeud
+		newNode.nodeLevels[levelIndex].levelSpan = updateNodes[levelIndex].nodeLevels[levelIndex].levelSpan - (traversedRanks[0] - traversedRanks[levelIndex])
+		updateNodes[levelIndex].nodeLevels[levelIndex].levelSpan = (traversedRanks[0] - traversedRanks[levelIndex]) + 1
+	}
+
+	// Les niveaux non touchés enjambent désormais un noeud de plus
+	for levelIndex := newNodeLevel; levelIndex < skipList.currentLevel; levelIndex++ {
+		updateNodes[levelIndex].nodeLevels[levelIndex].levelSpan++
+	}
+
+	if updateNodes[0] != skipList.headerNode {
+		newNode.backwardNode = updateNodes[0]
+	}
+	if newNode.nodeLevels[0].forwardNode != nil {
+		newNode.nodeLevels[0].forwardNode.backwardNode = newNode
+	} else {
+		skipList.tailNode = newNode
+	}
+
+	skipList.nodeCount++
+	return newNode
+}
+
+// deleteNode supprime le membre (score, nom) de la skiplist, retourne true s'il existait
+func (skipList *sortedSetSkipList) deleteNode(memberName string, memberScore float64) bool {
+	var updateNodes [sortedSetSkipListMaximumLevel]*sortedSetSkipListNode
+
+	currentNode := skipList.headerNode
+	for levelIndex := skipList.currentLevel - 1; levelIndex >= 0; levelIndex-- {
+		for currentNode.nodeLevels[levelIndex].forwardNode != nil &&
+			currentNode.nodeLevels[levelIndex].forwardNode.isOrderedBefore(memberScore, memberName) {
+			currentNode = currentNode.nodeLevels[levelIndex].forwardNode
+		}
+		updateNodes[levelIndex] = currentNode
+	}
+
+	targetNode := currentNode.nodeLevels[0].forwardNode
+	if targetNode == nil || targetNode.memberScore != memberScore || targetNode.memberName != memberName {
+		return false
+	}
+
+	for levelIndex := 0; levelIndex < skipList.currentLevel; levelIndex++ {
+		if updateNodes[levelIndex].nodeLevels[levelIndex].forwardNode == targetNode {
+			updateNodes[levelIndex].nodeLevels[levelIndex].levelSpan += targetNode.nodeLevels[levelIndex].levelSpan - 1
+			updateNodes[levelIndex].nodeLevels[levelIndex].forwardNode = targetNode.nodeLevels[levelIndex].forwardNode
+		} else {
+			updateNodes[levelIndex].nodeLevels[levelIndex].levelSpan--
+		}
+	}
+
+	if targetNode.nodeLevels[0].forwardNode != nil {
+		targetNode.nodeLevels[0].forwardNode.backwardNode = targetNode.backwardNode
+	} else {
+		skipList.tailNode = targetNode.backwardNode
+	}
+
+	for skipList.currentLevel > 1 && skipList.headerNode.nodeLevels[skipList.currentLevel-1].forwardNode == nil {
+		skipList.currentLevel--
+	}
+
+	skipList.nodeCount--
+	return true
+}
+
+// getMemberRank retourne le rang (base 0) d'un membre, ou -1 s'il est absent
+func (skipList *sortedSetSkipList) getMemberRank(memberName string, memberScore float64) int {
+	traversedRank := 0
+	currentNode := skipList.headerNode
+
+	for levelIndex := skipList.currentLevel - 1; levelIndex >= 0; levelIndex-- {
+		for currentNode.nodeLevels[levelIndex].forwardNode != nil {
+			forwardNode := currentNode.nodeLevels[levelIndex].forwardNode
+			if !forwardNode.isOrderedBefore(memberScore, memberName) &&
+				!(forwardNode.memberScore == memberScore && forwardNode.memberName == memberName) {
+				break
+			}
+			traversedRank += currentNode.nodeLevels[levelIndex].levelSpan
+			currentNode = forwardNode
+		}
+
+		if currentNode != skipList.headerNode && currentNode.memberName == memberName && currentNode.memberScore == memberScore {
+			return traversedRank - 1
+		}
+	}
+
+	return -1
+}
+
+// getNodeByRank retourne le noeud situé au rang donné (base 0), ou nil
+func (skipList *sortedSetSkipList) getNodeByRank(targetRank int) *sortedSetSkipListNode {
+	if targetRank < 0 || targetRank >= skipList.nodeCount {
+		return nil
+	}
+
+	traversedRank := 0
+	currentNode := skipList.headerNode
+	for levelIndex := skipList.currentLevel - 1; levelIndex >= 0; levelIndex-- {
+		for currentNode.nodeLevels[levelIndex].forwardNode != nil &&
+			traversedRank+currentNode.nodeLevels[levelIndex].levelSpan <= targetRank+1 {
+			traversedRank += currentNode.nodeLevels[levelIndex].levelSpan
+			currentNode = currentNode.nodeLevels[levelIndex].forwardNode
+		}
+		if traversedRank == targetRank+1 {
+			return currentNode
+		}
+	}
+
+	return nil
+}
+
+// getFirstNodeInScoreRange retourne le premier noeud dont le score est dans l'intervalle
+func (skipList *sortedSetSkipList) getFirstNodeInScoreRange(scoreRange SortedSetScoreRange) *sortedSetSkipListNode {
+	currentNode := skipList.headerNode
+	for levelIndex := skipList.currentLevel - 1; levelIndex >= 0; levelIndex-- {
+		for currentNode.nodeLevels[levelIndex].forwardNode != nil &&
+			!scoreRange.isAboveMinimum(currentNode.nodeLevels[levelIndex].forwardNode.memberScore) {
+			currentNode = currentNode.nodeLevels[levelIndex].forwardNode
+		}
+	}
+
+	candidateNode := currentNode.nodeLevels[0].forwardNode
+	if candidateNode == nil || !scoreRange.isBelowMaximum(candidateNode.memberScore) {
+		return nil
+	}
+	return candidateNode
+}
+
+// getLastNodeInScoreRange retourne le dernier noeud dont le score est dans l'intervalle
+func (skipList *sortedSetSkipList) getLastNodeInScoreRange(scoreRange SortedSetScoreRange) *sortedSetSkipListNode {
+	currentNode := skipList.headerNode
+	for levelIndex := skipList.currentLevel - 1; levelIndex >= 0; levelIndex-- {
+		for currentNode.nodeLevels[levelIndex].forwardNode != nil &&
+			scoreRange.isBelowMaximum(currentNode.nodeLevels[levelIndex].forwardNode.memberScore) {
+			currentNode = currentNode.nodeLevels[levelIndex].forwardNode
+		}
+	}
+
+	if currentNode == skipList.headerNode || !scoreRange.isAboveMinimum(currentNode.memberScore) {
+		return nil
+	}
+	return currentNode
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+// expectedSkipListOrder retourne les membres triés par (score, membre), l'ordre que la skiplist doit maintenir
+func expectedSkipListOrder(memberScores map[string]float64) []string {
+	orderedMembers := make([]string, 0, len(memberScores))
+	for memberName := range memberScores {
+		orderedMembers = append(orderedMembers, memberName)
+	}
+	slices.SortFunc(orderedMembers, func(firstMember, secondMember string) int {
+		if memberScores[firstMember] != memberScores[secondMember] {
+			if memberScores[firstMember] < memberScores[secondMember] {
+				return -1
+			}
+			return 1
+		}
+		if firstMember < secondMember {
+			return -1
+		}
+		return 1
+	})
+	return orderedMembers
+}
+
+func TestSortedSetSkipListRanks(t *testing.T) {
+	testCases := []struct {
+		name           string
+		insertedCount  int
+		deletedEvery   int
+		distinctScores int
+	}{
+		{name: "scores distincts", insertedCount: 200, deletedEvery: 0, distinctScores: 1000},
+		{name: "scores égaux départagés par le membre", insertedCount: 200, deletedEvery: 0, distinctScores: 3},
+		{name: "suppressions intercalées", insertedCount: 500, deletedEvery: 3, distinctScores: 50},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			randomSource := rand.New(rand.NewSource(1))
+			skipList := newSortedSetSkipList()
+			memberScores := make(map[string]float64)
+
+			for insertionIndex := 0; insertionIndex < testCase.insertedCount; insertionIndex++ {
+				memberName := "m" + strconv.Itoa(randomSource.Intn(testCase.insertedCount*4))
+				if _, alreadyInserted := memberScores[memberName]; alreadyInserted {
+					continue
+				}
+				memberScore := float64(randomSource.Intn(testCase.distinctScores))
+				skipList.insertNode(memberName, memberScore)
+				memberScores[memberName] = memberScore
+
+				if testCase.deletedEvery > 0 && insertionIndex%testCase.deletedEvery == 0 {
+					for deletedName, deletedScore := range memberScores {
+						if !skipList.deleteNode(deletedName, deletedScore) {
+							t.Fatalf("suppression de %s impossible", deletedName)
+						}
+						delete(memberScores, deletedName)
+						break
+					}
+				}
+			}
+
+			orderedMembers := expectedSkipListOrder(memberScores)
+			if skipList.nodeCount != len(orderedMembers) {
+				t.Fatalf("nodeCount %d, attendu %d", skipList.nodeCount, len(orderedMembers))
+			}
+			for expectedRank, memberName := range orderedMembers {
+				if memberRank := skipList.getMemberRank(memberName, memberScores[memberName]); memberRank != expectedRank {
+					t.Fatalf("rang de %s: %d, attendu %d", memberName, memberRank, expectedRank)
+				}
+				if rankNode := skipList.getNodeByRank(expectedRank); rankNode == nil || rankNode.memberName != memberName {
+					t.Fatalf("noeud au rang %d: %v, attendu %s", expectedRank, rankNode, memberName)
+				}
+			}
+			if skipList.getNodeByRank(len(orderedMembers)) != nil || skipList.getMemberRank("absent", 0) != -1 {
+				t.Fatalf("un rang hors limites ou un membre absent doit être introuvable")
+			}
+
+			// Le chaînage arrière doit parcourir les membres dans l'ordre inverse
+			reverseIndex := len(orderedMembers) - 1
+			for currentNode := skipList.tailNode; currentNode != nil; currentNode = currentNode.backwardNode {
+				if reverseIndex < 0 || currentNode.memberName != orderedMembers[reverseIndex] {
+					t.Fatalf("parcours inverse désordonné à l'index %d", reverseIndex)
+				}
+				reverseIndex--
+			}
+			if reverseIndex != -1 {
+				t.Fatalf("parcours inverse incomplet: %d membres non visités", reverseIndex+1)
+			}
+		})
+	}
+}
@@ -2,20 +2,57 @@ package storage
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// RedisInMemoryStorage est le stockage principal en mémoire avec gestion de la concurrence
+// RedisInMemoryStorage est une base de données logique en mémoire avec gestion de la concurrence
 type RedisInMemoryStorage struct {
-	storageData  map[string]*RedisStorageValue
+	databaseIndex int
+	storageData   map[string]*RedisStorageValue
+	// keyScanIndex répartit les clés de storageData en buckets pour SCAN (mis à jour par storeEntry/removeEntry)
+	keyScanIndex *keyspaceScanIndex
 	storageMutex sync.RWMutex
+	// writeCommandMutex sérialise les commandes d'écriture de la base avec leur propagation (LockWriteCommands)
+	writeCommandMutex sync.Mutex
+	// watchedKeys contient les clés surveillées par WATCH et leur version de modification
+	watchedKeys map[string]*watchedKeyState
+	// expiredKeyCount compte les clés supprimées à leur expiration (garbage collector ou accès)
+	expiredKeyCount atomic.Int64
+	// listWaiters contient, par liste, les clients bloqués (BLPOP...) dans leur ordre d'arrivée
+	listWaiters map[string][]*ListWaiter
+	// readyListKeys contient, dans l'ordre, les listes attendues qui ont reçu des éléments depuis le dernier
+	// service des clients bloqués (ServeReadyListWaiters, appelé après la commande)
+	readyListKeys []string
 }
 
-// NewRedisInMemoryStorage crée une nouvelle instance de stockage
+// NewRedisInMemoryStorage crée une nouvelle instance de stockage (base 0)
 func NewRedisInMemoryStorage() *RedisInMemoryStorage {
-	return &RedisInMemoryStorage{
-		storageData: make(map[string]*RedisStorageValue),
+	return NewRedisDatabaseStorage(0)
+}
+
+// NewRedisDatabaseStorage crée le stockage de la base de données logique d'index donné (SELECT)
+func NewRedisDatabaseStorage(databaseIndex int) *RedisInMemoryStorage {
+	redisStorage := &RedisInMemoryStorage{
+		databaseIndex: databaseIndex,
+		watchedKeys:   make(map[string]*watchedKeyState),
+		listWaiters:   make(map[string][]*ListWaiter),
 	}
+	redisStorage.replaceAllEntries(make(map[string]*RedisStorageValue))
+	return redisStorage
+}
+
+// GetDatabaseIndex retourne l'index de la base de données logique
+func (redisStorage *RedisInMemoryStorage) GetDatabaseIndex() int {
+	return redisStorage.databaseIndex
+}
+
+// LockWriteCommands réserve la base à une commande d'écriture jusqu'à la fin de sa propagation (AOF, réplicas),
+// qui reçoivent ainsi les écritures dans l'ordre où elles ont été appliquées ; retourne la fonction de libération
+// Les lectures ne sont pas concernées : elles restent protégées par le seul verrou du stockage
+func (redisStorage *RedisInMemoryStorage) LockWriteCommands() func() {
+	redisStorage.writeCommandMutex.Lock()
+	return redisStorage.writeCommandMutex.Unlock
 }
 
 // SetKeyValue stocke une valeur avec type et TTL optionnel
@@ -29,11 +66,12 @@ func (redisStorage *RedisInMemoryStorage) SetKeyValue(storageKey string, keyData
 		expirationTime = &calculatedExpiry
 	}
 
-	redisStorage.storageData[storageKey] = &RedisStorageValue{
+	redisStorage.storeEntry(storageKey, &RedisStorageValue{
 		StoredData:     keyData,
 		DataType:       dataType,
 		ExpirationTime: expirationTime,
-	}
+	})
+	redisStorage.markKeyModified(storageKey)
 }
 
 // GetKeyValue récupère une valeur, retourne nil si la clé n'existe pas ou a expiré
@@ -41,19 +79,8 @@ func (redisStorage *RedisInMemoryStorage) GetKeyValue(storageKey string) *RedisS
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
-	storageValue, keyExists := redisStorage.storageData[storageKey]
-	if !keyExists {
-		return nil
-	}
-
-	// Vérifier l'expiration
-	if storageValue.ExpirationTime != nil && time.Now().After(*storageValue.ExpirationTime) {
-		// Clé expirée - suppression lazy
-		delete(redisStorage.storageData, storageKey)
-		return nil
-	}
-
-	return storageValue
+	// Une clé expirée est ignorée ici, elle sera supprimée par la prochaine écriture ou le garbage collector
+	return redisStorage.getLiveStorageValue(storageKey)
 }
 
 // DeleteKeyValue supprime une clé et retourne true si elle existait
@@ -61,9 +88,11 @@ func (redisStorage *RedisInMemoryStorage) DeleteKeyValue(storageKey string) bool
 	redisStorage.storageMutex.Lock()
 	defer redisStorage.storageMutex.Unlock()
 
+	redisStorage.removeKeyIfExpired(storageKey)
 	_, keyExists := redisStorage.storageData[storageKey]
 	if keyExists {
-		delete(redisStorage.storageData, storageKey)
+		redisStorage.removeEntry(storageKey)
+		redisStorage.markKeyModified(storageKey)
 	}
 	return keyExists
 }
@@ -73,18 +102,7 @@ func (redisStorage *RedisInMemoryStorage) CheckKeyExists(storageKey string) bool
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
-	storageValue, keyExists := redisStorage.storageData[storageKey]
-	if !keyExists {
-		return false
-	}
-
-	// Vérifier l'expiration
-	if storageValue.ExpirationTime != nil && time.Now().After(*storageValue.ExpirationTime) {
-		delete(redisStorage.storageData, storageKey)
-		return false
-	}
-
-	return true
+	return redisStorage.getLiveStorageValue(storageKey) != nil
 }
 
 // GetStorageSize retourne le nombre de clés valides (non expirées)
@@ -114,19 +132,30 @@ func (redisStorage *RedisInMemoryStorage) CleanupExpiredKeys() int {
 
 	for storageKey, storageValue := range redisStorage.storageData {
 		if storageValue.ExpirationTime != nil && currentTime.After(*storageValue.ExpirationTime) {
-			delete(redisStorage.storageData, storageKey)
+			redisStorage.removeEntry(storageKey)
+			redisStorage.markKeyModified(storageKey)
 			cleanedKeyCount++
+			continue
+		}
+		// Fields expirés des hashes (HEXPIRE), la clé disparaît avec son dernier field
+		if redisHashStructure, isHash := storageValue.StoredData.(*RedisHashStructure); isHash && len(redisHashStructure.FieldExpirations) > 0 {
+			redisStorage.removeExpiredHashFields(storageKey, redisHashStructure, currentTime)
+			if len(redisHashStructure.HashFields) == 0 {
+				cleanedKeyCount++
+			}
 		}
 	}
+	redisStorage.expiredKeyCount.Add(int64(cleanedKeyCount))
 
 	return cleanedKeyCount
 }
 
-// FlushAllKeys vide tout le stockage
+// FlushAllKeys vide toute la base de données (FLUSHDB)
 func (redisStorage *RedisInMemoryStorage) FlushAllKeys() {
 	redisStorage.storageMutex.Lock()
 	defer redisStorage.storageMutex.Unlock()
-	redisStorage.storageData = make(map[string]*RedisStorageValue)
+	redisStorage.replaceAllEntries(make(map[string]*RedisStorageValue))
+	redisStorage.markAllWatchedKeysModified()
 }
 
 // GetKeyDataType retourne le type d'une clé
@@ -134,16 +163,61 @@ func (redisStorage *RedisInMemoryStorage) GetKeyDataType(storageKey string) Redi
 	redisStorage.storageMutex.RLock()
 	defer redisStorage.storageMutex.RUnlock()
 
+	storageValue := redisStorage.getLiveStorageValue(storageKey)
+	if storageValue == nil {
+		return -1 // Clé inexistante ou expirée
+	}
+
+	return storageValue.DataType
+}
+
+// getLiveStorageValue retourne la valeur d'une clé non expirée (appelant doit détenir le verrou)
+func (redisStorage *RedisInMemoryStorage) getLiveStorageValue(storageKey string) *RedisStorageValue {
 	storageValue, keyExists := redisStorage.storageData[storageKey]
 	if !keyExists {
-		return -1 // Clé inexistante
+		return nil
 	}
 
-	// Vérifier l'expiration
 	if storageValue.ExpirationTime != nil && time.Now().After(*storageValue.ExpirationTime) {
-		delete(redisStorage.storageData, storageKey)
-		return -1
+		return nil
 	}
 
-	return storageValue.DataType
+	return storageValue
+}
+
+// removeKeyIfExpired supprime une clé expirée (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) removeKeyIfExpired(storageKey string) {
+	storageValue, keyExists := redisStorage.storageData[storageKey]
+	if keyExists && storageValue.ExpirationTime != nil && time.Now().After(*storageValue.ExpirationTime) {
+		redisStorage.removeEntry(storageKey)
+		redisStorage.markKeyModified(storageKey)
+		redisStorage.expiredKeyCount.Add(1)
+	}
+}
+
+// storeEntry ajoute ou remplace une clé et l'indexe pour SCAN (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) storeEntry(storageKey string, storageValue *RedisStorageValue) {
+	if _, keyExists := redisStorage.storageData[storageKey]; !keyExists {
+		redisStorage.keyScanIndex.addKey(storageKey)
+	}
+	indexLargeCollection(storageValue)
+	redisStorage.storageData[storageKey] = storageValue
+}
+
+// removeEntry supprime une clé et la retire de l'index de SCAN (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) removeEntry(storageKey string) {
+	if _, keyExists := redisStorage.storageData[storageKey]; !keyExists {
+		return
+	}
+	delete(redisStorage.storageData, storageKey)
+	redisStorage.keyScanIndex.removeKey(storageKey)
+}
+
+// replaceAllEntries remplace tout le dictionnaire et reconstruit l'index de SCAN (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) replaceAllEntries(newEntries map[string]*RedisStorageValue) {
+	redisStorage.storageData = newEntries
+	redisStorage.keyScanIndex = newKeyspaceScanIndex(newEntries)
+	for _, storageValue := range newEntries {
+		indexLargeCollection(storageValue)
+	}
 }
@@ -0,0 +1,25 @@
+package storage
+
+import "errors"
+
+// Erreurs retournées par les opérations de stockage lorsque les valeurs sentinelles ne suffisent pas
+var (
+	// ErrWrongDataType indique que la clé existe mais contient un autre type de données
+	ErrWrongDataType = errors.New("la clé contient un autre type de données")
+	// ErrScoreIsNotANumber indique qu'une opération produirait un score NaN
+	ErrScoreIsNotANumber = errors.New("le score résultant n'est pas un nombre (NaN)")
+	// ErrKeyNotFound indique qu'une opération exige une clé existante (LSET)
+	ErrKeyNotFound = errors.New("la clé n'existe pas")
+	// ErrValueNotInteger indique que la valeur à incrémenter n'est pas un entier 64 bits (HINCRBY)
+	ErrValueNotInteger = errors.New("la valeur n'est pas un nombre entier")
+	// ErrValueNotFloat indique que la valeur à incrémenter n'est pas un nombre décimal valide (HINCRBYFLOAT)
+	ErrValueNotFloat = errors.New("la valeur n'est pas un nombre décimal valide")
+	// ErrIncrementOverflow indique qu'un incrément dépasserait les bornes d'un entier 64 bits
+	ErrIncrementOverflow = errors.New("incrément ou décrément hors limites")
+	// ErrIncrementNotFinite indique qu'un incrément décimal produirait NaN ou l'infini
+	ErrIncrementNotFinite = errors.New("l'incrément produirait NaN ou l'infini")
+	// ErrStringTooLong indique qu'une écriture dépasserait la taille maximale d'une chaîne (SETRANGE)
+	ErrStringTooLong = errors.New("la chaîne dépasserait la taille maximale autorisée (512 Mo)")
+	// ErrIndexOutOfRange indique qu'un index ne désigne aucun élément de la liste (LSET)
+	ErrIndexOutOfRange = errors.New("index hors limites")
+)
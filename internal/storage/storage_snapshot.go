@@ -0,0 +1,86 @@
+package storage
+
+import "time"
+
+// CloneStorageEntries retourne une copie profonde des clés non expirées
+// Le verrou n'est tenu que le temps de la copie, la sérialisation se fait ensuite sans bloquer les clients
+func (redisStorage *RedisInMemoryStorage) CloneStorageEntries() map[string]*RedisStorageValue {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	currentTime := time.Now()
+	clonedEntries := make(map[string]*RedisStorageValue, len(redisStorage.storageData))
+
+	for storageKey, storageValue := range redisStorage.storageData {
+		if storageValue.ExpirationTime != nil && currentTime.After(*storageValue.ExpirationTime) {
+			continue
+		}
+		clonedEntries[storageKey] = storageValue.cloneStorageValue()
+	}
+
+	return clonedEntries
+}
+
+// ReplaceStorageEntries remplace tout le contenu du stockage (chargement d'un snapshot)
+func (redisStorage *RedisInMemoryStorage) ReplaceStorageEntries(newEntries map[string]*RedisStorageValue) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+	redisStorage.replaceAllEntries(newEntries)
+	redisStorage.markAllWatchedKeysModified()
+}
+
+// NewRedisSortedSetFromMembers construit un sorted set à partir d'une liste de membres
+func NewRedisSortedSetFromMembers(sortedSetMembers []SortedSetMember) *RedisSortedSetStructure {
+	redisSortedSet := newRedisSortedSetStructure()
+	for _, sortedSetMember := range sortedSetMembers {
+		redisSortedSet.updateMemberScore(sortedSetMember.MemberName, sortedSetMember.MemberScore)
+	}
+	return redisSortedSet
+}
+
+// cloneStorageValue réalise une copie profonde d'une valeur stockée
+func (storageValue *RedisStorageValue) cloneStorageValue() *RedisStorageValue {
+	clonedValue := &RedisStorageValue{DataType: storageValue.DataType}
+
+	if storageValue.ExpirationTime != nil {
+		clonedExpiration := *storageValue.ExpirationTime
+		clonedValue.ExpirationTime = &clonedExpiration
+	}
+
+	switch storedData := storageValue.StoredData.(type) {
+	case *RedisListStructure:
+		clonedElements := make([]string, len(storedData.ListElements))
+		copy(clonedElements, storedData.ListElements)
+		clonedValue.StoredData = &RedisListStructure{ListElements: clonedElements}
+	case *RedisSetStructure:
+		clonedElements := make(map[string]bool, len(storedData.SetElements))
+		for setMember := range storedData.SetElements {
+			clonedElements[setMember] = true
+		}
+		clonedValue.StoredData = &RedisSetStructure{SetElements: clonedElements}
+	case *RedisHashStructure:
+		clonedFields := make(map[string]string, len(storedData.HashFields))
+		for fieldName, fieldValue := range storedData.HashFields {
+			clonedFields[fieldName] = fieldValue
+		}
+		clonedHash := &RedisHashStructure{HashFields: clonedFields}
+		if len(storedData.FieldExpirations) > 0 {
+			clonedHash.FieldExpirations = make(map[string]time.Time, len(storedData.FieldExpirations))
+			for fieldName, expirationTime := range storedData.FieldExpirations {
+				clonedHash.FieldExpirations[fieldName] = expirationTime
+			}
+		}
+		clonedValue.StoredData = clonedHash
+	case *RedisSortedSetStructure:
+		clonedSortedSet := newRedisSortedSetStructure()
+		for memberName, memberScore := range storedData.MemberScores {
+			clonedSortedSet.updateMemberScore(memberName, memberScore)
+		}
+		clonedValue.StoredData = clonedSortedSet
+	default:
+		// Les strings sont immuables en Go, pas besoin de copie
+		clonedValue.StoredData = storageValue.StoredData
+	}
+
+	return clonedValue
+}
@@ -0,0 +1,43 @@
+package storage
+
+import "time"
+
+// KeyspaceStatistics résume le contenu d'une base (INFO keyspace)
+type KeyspaceStatistics struct {
+	KeyCount         int
+	ExpiringKeyCount int
+	// AverageTimeToLive est la durée de vie restante moyenne des clés avec expiration
+	AverageTimeToLive time.Duration
+}
+
+// GetKeyspaceStatistics compte les clés valides, celles qui ont une expiration et leur TTL moyen
+func (redisStorage *RedisInMemoryStorage) GetKeyspaceStatistics() KeyspaceStatistics {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	var keyspaceStatistics KeyspaceStatistics
+	var totalTimeToLive time.Duration
+	currentTime := time.Now()
+
+	for _, storageValue := range redisStorage.storageData {
+		if storageValue.ExpirationTime == nil {
+			keyspaceStatistics.KeyCount++
+			continue
+		}
+		if remainingTime := storageValue.ExpirationTime.Sub(currentTime); remainingTime > 0 {
+			keyspaceStatistics.KeyCount++
+			keyspaceStatistics.ExpiringKeyCount++
+			totalTimeToLive += remainingTime
+		}
+	}
+
+	if keyspaceStatistics.ExpiringKeyCount > 0 {
+		keyspaceStatistics.AverageTimeToLive = totalTimeToLive / time.Duration(keyspaceStatistics.ExpiringKeyCount)
+	}
+	return keyspaceStatistics
+}
+
+// GetExpiredKeyCount retourne le nombre de clés supprimées à leur expiration depuis le démarrage
+func (redisStorage *RedisInMemoryStorage) GetExpiredKeyCount() int64 {
+	return redisStorage.expiredKeyCount.Load()
+}
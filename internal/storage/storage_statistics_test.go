@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyspaceStatisticsAndExpiredKeyCount(t *testing.T) {
+	durationPointer := func(timeToLive time.Duration) *time.Duration { return &timeToLive }
+
+	testCases := []struct {
+		name                  string
+		populateStorage       func(redisStorage *RedisInMemoryStorage)
+		expectedKeyCount      int
+		expectedExpiringCount int
+		// expectedAverageTtl est comparé à la seconde près (le temps s'écoule pendant le test)
+		expectedAverageTtl   time.Duration
+		expectedExpiredCount int64
+	}{
+		{
+			name:            "base vide",
+			populateStorage: func(redisStorage *RedisInMemoryStorage) {},
+		},
+		{
+			name: "clés persistantes et clés avec expiration",
+			populateStorage: func(redisStorage *RedisInMemoryStorage) {
+				redisStorage.SetKeyValue("persistante", "v", RedisStringType, nil)
+				redisStorage.SetKeyValue("courte", "v", RedisStringType, durationPointer(100*time.Second))
+				redisStorage.SetKeyValue("longue", "v", RedisStringType, durationPointer(300*time.Second))
+			},
+			expectedKeyCount:      3,
+			expectedExpiringCount: 2,
+			expectedAverageTtl:    200 * time.Second,
+		},
+		{
+			name: "clés expirées ignorées puis supprimées par le garbage collector",
+			populateStorage: func(redisStorage *RedisInMemoryStorage) {
+				redisStorage.SetKeyValue("valide", "v", RedisStringType, nil)
+				redisStorage.SetKeyValue("expirée1", "v", RedisStringType, durationPointer(-time.Second))
+				redisStorage.SetKeyValue("expirée2", "v", RedisStringType, durationPointer(-time.Second))
+			},
+			expectedKeyCount:     1,
+			expectedExpiredCount: 2,
+		},
+		{
+			name: "clé expirée supprimée lors d'une écriture",
+			populateStorage: func(redisStorage *RedisInMemoryStorage) {
+				redisStorage.SetKeyValue("expirée", "v", RedisStringType, durationPointer(-time.Second))
+				redisStorage.DeleteKeyValue("expirée")
+			},
+			expectedExpiredCount: 1,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			testCase.populateStorage(redisStorage)
+
+			keyspaceStatistics := redisStorage.GetKeyspaceStatistics()
+			if keyspaceStatistics.KeyCount != testCase.expectedKeyCount || keyspaceStatistics.ExpiringKeyCount != testCase.expectedExpiringCount {
+				t.Fatalf("keys=%d expires=%d, attendu keys=%d expires=%d", keyspaceStatistics.KeyCount, keyspaceStatistics.ExpiringKeyCount, testCase.expectedKeyCount, testCase.expectedExpiringCount)
+			}
+			if keyspaceStatistics.AverageTimeToLive.Round(time.Second) != testCase.expectedAverageTtl {
+				t.Fatalf("avg_ttl=%v, attendu %v", keyspaceStatistics.AverageTimeToLive, testCase.expectedAverageTtl)
+			}
+
+			redisStorage.CleanupExpiredKeys()
+			if expiredKeyCount := redisStorage.GetExpiredKeyCount(); expiredKeyCount != testCase.expectedExpiredCount {
+				t.Fatalf("expired_keys=%d, attendu %d", expiredKeyCount, testCase.expectedExpiredCount)
+			}
+		})
+	}
+}
@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"math/big"
+	"time"
+)
+
+// StringSetOptions regroupe les options de SET (EX/PX/EXAT/PXAT, KEEPTTL, NX, XX, GET)
+type StringSetOptions struct {
+	ExpirationTime         *time.Time
+	KeepExistingExpiration bool
+	OnlyIfNotExists        bool
+	OnlyIfExists           bool
+	// ReturnPreviousValue exige que l'ancienne valeur soit une chaîne (SET ... GET, GETSET)
+	ReturnPreviousValue bool
+}
+
+// StringSetResult décrit l'effet d'un SET conditionnel
+type StringSetResult struct {
+	ValueWasSet       bool
+	PreviousValue     string
+	PreviousKeyExists bool
+}
+
+// SetStringValue stocke une chaîne en appliquant atomiquement les conditions NX/XX
+// Retourne ErrWrongDataType si GET est demandé sur une clé qui n'est pas une chaîne
+func (redisStorage *RedisInMemoryStorage) SetStringValue(storageKey string, stringValue string, setOptions StringSetOptions) (StringSetResult, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	var setResult StringSetResult
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	existingValue, keyExists := redisStorage.storageData[storageKey]
+	if keyExists {
+		setResult.PreviousKeyExists = true
+		if setOptions.ReturnPreviousValue {
+			if existingValue.DataType != RedisStringType {
+				return setResult, ErrWrongDataType
+			}
+			setResult.PreviousValue = existingValue.StoredData.(string)
+		}
+	}
+
+	if (setOptions.OnlyIfNotExists && keyExists) || (setOptions.OnlyIfExists && !keyExists) {
+		return setResult, nil
+	}
+
+	expirationTime := setOptions.ExpirationTime
+	if setOptions.KeepExistingExpiration && keyExists {
+		expirationTime = existingValue.ExpirationTime
+	}
+
+	redisStorage.storeEntry(storageKey, &RedisStorageValue{
+		StoredData:     stringValue,
+		DataType:       RedisStringType,
+		ExpirationTime: expirationTime,
+	})
+	redisStorage.markKeyModified(storageKey)
+	setResult.ValueWasSet = true
+	return setResult, nil
+}
+
+// GetAndDeleteStringValue retourne la chaîne stockée puis supprime la clé (GETDEL)
+// keyExists est false si la clé n'existe pas, ErrWrongDataType si ce n'est pas une chaîne
+func (redisStorage *RedisInMemoryStorage) GetAndDeleteStringValue(storageKey string) (stringValue string, keyExists bool, operationError error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	storageValue, keyExists := redisStorage.storageData[storageKey]
+	if !keyExists {
+		return "", false, nil
+	}
+	if storageValue.DataType != RedisStringType {
+		return "", true, ErrWrongDataType
+	}
+
+	redisStorage.removeEntry(storageKey)
+	redisStorage.markKeyModified(storageKey)
+	return storageValue.StoredData.(string), true, nil
+}
+
+// GetStringValueAndUpdateExpiration retourne la chaîne stockée et modifie son expiration (GETEX)
+// Une expiration nil conserve l'expiration actuelle sauf si removeExpiration est vrai (PERSIST)
+// keyDeleted est true si une date d'expiration passée a supprimé la clé
+func (redisStorage *RedisInMemoryStorage) GetStringValueAndUpdateExpiration(storageKey string, expirationTime *time.Time, removeExpiration bool) (stringValue string, keyExists bool, keyDeleted bool, operationError error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	storageValue, keyExists := redisStorage.storageData[storageKey]
+	if !keyExists {
+		return "", false, false, nil
+	}
+	if storageValue.DataType != RedisStringType {
+		return "", true, false, ErrWrongDataType
+	}
+
+	switch {
+	case removeExpiration:
+		storageValue.ExpirationTime = nil
+		redisStorage.markKeyModified(storageKey)
+	case expirationTime != nil && !expirationTime.After(time.Now()):
+		redisStorage.removeEntry(storageKey)
+		redisStorage.markKeyModified(storageKey)
+		keyDeleted = true
+	case expirationTime != nil:
+		storageValue.ExpirationTime = expirationTime
+		redisStorage.markKeyModified(storageKey)
+	}
+
+	return storageValue.StoredData.(string), true, keyDeleted, nil
+}
+
+// maximumStringLength est la taille maximale d'une chaîne, comme la limite proto-max-bulk-len par défaut de Redis
+const maximumStringLength = 512 * 1024 * 1024
+
+// getLiveString retourne la chaîne non expirée d'une clé (appelant doit détenir le verrou)
+// keyExists est false si la clé n'existe pas, ErrWrongDataType si elle contient un autre type
+func (redisStorage *RedisInMemoryStorage) getLiveString(storageKey string) (stringValue string, keyExists bool, operationError error) {
+	storageValue := redisStorage.getLiveStorageValue(storageKey)
+	if storageValue == nil {
+		return "", false, nil
+	}
+	if storageValue.DataType != RedisStringType {
+		return "", true, ErrWrongDataType
+	}
+	return storageValue.StoredData.(string), true, nil
+}
+
+// storeStringKeepingExpiration remplace la chaîne d'une clé en conservant son expiration, ou crée la clé
+// (appelant doit détenir le verrou en écriture et avoir supprimé la clé si elle a expiré)
+func (redisStorage *RedisInMemoryStorage) storeStringKeepingExpiration(storageKey string, stringValue string) {
+	if storageValue, keyExists := redisStorage.storageData[storageKey]; keyExists {
+		storageValue.StoredData = stringValue
+	} else {
+		redisStorage.storeEntry(storageKey, &RedisStorageValue{StoredData: stringValue, DataType: RedisStringType})
+	}
+	redisStorage.markKeyModified(storageKey)
+}
+
+// AppendStringValue ajoute un suffixe à la chaîne d'une clé, créée si besoin (APPEND)
+// Retourne la nouvelle longueur ; l'expiration de la clé est conservée
+func (redisStorage *RedisInMemoryStorage) AppendStringValue(storageKey string, appendedValue string) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	currentValue, _, lookupError := redisStorage.getLiveString(storageKey)
+	if lookupError != nil {
+		return 0, lookupError
+	}
+	if len(currentValue)+len(appendedValue) > maximumStringLength {
+		return 0, ErrStringTooLong
+	}
+
+	redisStorage.storeStringKeepingExpiration(storageKey, currentValue+appendedValue)
+	return len(currentValue) + len(appendedValue), nil
+}
+
+// GetStringLength retourne la longueur de la chaîne d'une clé, 0 si la clé n'existe pas (STRLEN)
+func (redisStorage *RedisInMemoryStorage) GetStringLength(storageKey string) (int, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	stringValue, _, lookupError := redisStorage.getLiveString(storageKey)
+	return len(stringValue), lookupError
+}
+
+// GetStringRange retourne la sous-chaîne entre deux positions incluses (GETRANGE)
+// Les positions négatives sont comptées depuis la fin ; une clé absente donne une chaîne vide
+func (redisStorage *RedisInMemoryStorage) GetStringRange(storageKey string, startIndex int, endIndex int) (string, error) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	stringValue, _, lookupError := redisStorage.getLiveString(storageKey)
+	if lookupError != nil {
+		return "", lookupError
+	}
+
+	// Comme Redis : deux positions négatives inversées donnent une chaîne vide, mais contrairement à LRANGE
+	// une fin placée avant le début de la chaîne est ramenée au premier octet (GETRANGE k 0 -100 retourne un octet)
+	if startIndex < 0 && endIndex < 0 && startIndex > endIndex {
+		return "", nil
+	}
+	if endIndex < 0 {
+		endIndex = max(len(stringValue)+endIndex, 0)
+	}
+	rangeStart, rangeEnd, rangeIsEmpty := normalizeListRange(startIndex, endIndex, len(stringValue))
+	if rangeIsEmpty {
+		return "", nil
+	}
+	return stringValue[rangeStart : rangeEnd+1], nil
+}
+
+// SetStringRange écrit une valeur à partir d'une position de la chaîne d'une clé (SETRANGE)
+// La chaîne est complétée par des octets nuls si la position dépasse sa fin ; retourne la nouvelle longueur
+// Une valeur vide ne crée pas la clé ; l'expiration de la clé est conservée
+func (redisStorage *RedisInMemoryStorage) SetStringRange(storageKey string, writeOffset int, writtenValue string) (int, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	currentValue, _, lookupError := redisStorage.getLiveString(storageKey)
+	if lookupError != nil {
+		return 0, lookupError
+	}
+	if len(writtenValue) == 0 {
+		return len(currentValue), nil
+	}
+	if writeOffset+len(writtenValue) > maximumStringLength {
+		return 0, ErrStringTooLong
+	}
+
+	updatedValue := []byte(currentValue)
+	if requiredLength := writeOffset + len(writtenValue); requiredLength > len(updatedValue) {
+		updatedValue = append(updatedValue, make([]byte, requiredLength-len(updatedValue))...)
+	}
+	copy(updatedValue[writeOffset:], writtenValue)
+
+	redisStorage.storeStringKeepingExpiration(storageKey, string(updatedValue))
+	return len(updatedValue), nil
+}
+
+// GetMultipleStringValues retourne la chaîne de plusieurs clés (MGET)
+// valuesFound est false pour une clé absente ou qui ne contient pas une chaîne
+func (redisStorage *RedisInMemoryStorage) GetMultipleStringValues(storageKeys []string) (stringValues []string, valuesFound []bool) {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	stringValues = make([]string, len(storageKeys))
+	valuesFound = make([]bool, len(storageKeys))
+	for keyIndex, storageKey := range storageKeys {
+		stringValue, keyExists, lookupError := redisStorage.getLiveString(storageKey)
+		if keyExists && lookupError == nil {
+			stringValues[keyIndex], valuesFound[keyIndex] = stringValue, true
+		}
+	}
+	return stringValues, valuesFound
+}
+
+// SetMultipleStringValues stocke plusieurs paires clé/valeur sous un seul verrou (MSET, MSETNX)
+// Les clés remplacées perdent leur expiration ; avec onlyIfNoneExists, rien n'est écrit si une des clés existe
+// Retourne true si les valeurs ont été écrites
+func (redisStorage *RedisInMemoryStorage) SetMultipleStringValues(keysAndValues []string, onlyIfNoneExists bool) bool {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	if onlyIfNoneExists {
+		for pairIndex := 0; pairIndex < len(keysAndValues); pairIndex += 2 {
+			if redisStorage.getLiveStorageValue(keysAndValues[pairIndex]) != nil {
+				return false
+			}
+		}
+	}
+
+	for pairIndex := 0; pairIndex < len(keysAndValues); pairIndex += 2 {
+		redisStorage.storeEntry(keysAndValues[pairIndex], &RedisStorageValue{
+			StoredData: keysAndValues[pairIndex+1],
+			DataType:   RedisStringType,
+		})
+		redisStorage.markKeyModified(keysAndValues[pairIndex])
+	}
+	return true
+}
+
+// IncrementStringFloat ajoute un nombre décimal à la chaîne d'une clé (INCRBYFLOAT), une clé absente valant 0
+// Retourne la nouvelle valeur formatée comme par Redis ; ErrValueNotFloat si la chaîne n'est pas un nombre,
+// ErrIncrementNotFinite si le résultat serait infini. L'expiration de la clé est conservée
+func (redisStorage *RedisInMemoryStorage) IncrementStringFloat(storageKey string, floatIncrement *big.Float) (string, error) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	redisStorage.removeKeyIfExpired(storageKey)
+	currentValue, keyExists, lookupError := redisStorage.getLiveString(storageKey)
+	if lookupError != nil {
+		return "", lookupError
+	}
+
+	formattedValue, incrementError := incrementFloatValue(currentValue, keyExists, floatIncrement)
+	if incrementError != nil {
+		return "", incrementError
+	}
+	redisStorage.storeStringKeepingExpiration(storageKey, formattedValue)
+	return formattedValue, nil
+}
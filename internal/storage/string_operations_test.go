@@ -0,0 +1,67 @@
+package storage
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestGetStringRange(t *testing.T) {
+	testCases := []struct {
+		name          string
+		storageKey    string
+		startIndex    int
+		endIndex      int
+		expectedRange string
+	}{
+		{name: "positions positives", storageKey: "k", startIndex: 0, endIndex: 3, expectedRange: "Hell"},
+		{name: "positions négatives", storageKey: "k", startIndex: -3, endIndex: -1, expectedRange: "rld"},
+		{name: "chaîne entière", storageKey: "k", startIndex: 0, endIndex: -1, expectedRange: "Hello World"},
+		{name: "fin au-delà de la chaîne", storageKey: "k", startIndex: 10, endIndex: 100, expectedRange: "d"},
+		{name: "début au-delà de la chaîne", storageKey: "k", startIndex: 11, endIndex: 20, expectedRange: ""},
+		{name: "début avant le début de la chaîne", storageKey: "k", startIndex: -100, endIndex: 2, expectedRange: "Hel"},
+		{name: "fin avant le début de la chaîne", storageKey: "k", startIndex: 0, endIndex: -100, expectedRange: "H"},
+		{name: "deux positions avant le début de la chaîne", storageKey: "k", startIndex: -100, endIndex: -50, expectedRange: "H"},
+		{name: "positions négatives inversées", storageKey: "k", startIndex: -1, endIndex: -5, expectedRange: ""},
+		{name: "positions inversées", storageKey: "k", startIndex: 5, endIndex: 3, expectedRange: ""},
+		{name: "chaîne vide", storageKey: "vide", startIndex: 0, endIndex: -100, expectedRange: ""},
+		{name: "clé absente", storageKey: "absente", startIndex: 0, endIndex: -1, expectedRange: ""},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			redisStorage := NewRedisInMemoryStorage()
+			redisStorage.SetKeyValue("k", "Hello World", RedisStringType, nil)
+			redisStorage.SetKeyValue("vide", "", RedisStringType, nil)
+			stringRange, rangeError := redisStorage.GetStringRange(testCase.storageKey, testCase.startIndex, testCase.endIndex)
+			if rangeError != nil || stringRange != testCase.expectedRange {
+				t.Fatalf("GETRANGE %d %d: %q (%v), attendu %q", testCase.startIndex, testCase.endIndex, stringRange, rangeError, testCase.expectedRange)
+			}
+		})
+	}
+}
+
+func TestSetMultipleStringValuesIsAtomic(t *testing.T) {
+	redisStorage := NewRedisInMemoryStorage()
+	redisStorage.SetMultipleStringValues([]string{"a", "0", "b", "0"}, false)
+
+	// Un lecteur concurrent ne doit jamais voir une partie seulement des clés d'un MSET
+	var writerGroup sync.WaitGroup
+	writerGroup.Add(1)
+	go func() {
+		defer writerGroup.Done()
+		for writeIndex := 1; writeIndex <= 2000; writeIndex++ {
+			writtenValue := strconv.Itoa(writeIndex)
+			redisStorage.SetMultipleStringValues([]string{"a", writtenValue, "b", writtenValue}, false)
+		}
+	}()
+	for readIndex := 0; readIndex < 2000; readIndex++ {
+		if stringValues, _ := redisStorage.GetMultipleStringValues([]string{"a", "b"}); stringValues[0] != stringValues[1] {
+			t.Fatalf("MSET observé à moitié: a=%s b=%s", stringValues[0], stringValues[1])
+		}
+	}
+	writerGroup.Wait()
+
+	if valuesWritten := redisStorage.SetMultipleStringValues([]string{"c", "1", "a", "1"}, true); valuesWritten || redisStorage.CheckKeyExists("c") {
+		t.Fatalf("MSETNX ne doit rien écrire si une des clés existe")
+	}
+}
@@ -0,0 +1,61 @@
+package storage
+
+// watchedKeyState suit les modifications d'une clé surveillée par WATCH
+type watchedKeyState struct {
+	watcherCount        int
+	modificationVersion uint64
+}
+
+// WatchKey enregistre un observateur sur une clé et retourne sa version de modification actuelle
+func (redisStorage *RedisInMemoryStorage) WatchKey(storageKey string) uint64 {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	keyState, isWatched := redisStorage.watchedKeys[storageKey]
+	if !isWatched {
+		keyState = &watchedKeyState{}
+		redisStorage.watchedKeys[storageKey] = keyState
+	}
+	keyState.watcherCount++
+	return keyState.modificationVersion
+}
+
+// UnwatchKey retire un observateur d'une clé (le suivi s'arrête avec le dernier observateur)
+func (redisStorage *RedisInMemoryStorage) UnwatchKey(storageKey string) {
+	redisStorage.storageMutex.Lock()
+	defer redisStorage.storageMutex.Unlock()
+
+	keyState, isWatched := redisStorage.watchedKeys[storageKey]
+	if !isWatched {
+		return
+	}
+	keyState.watcherCount--
+	if keyState.watcherCount <= 0 {
+		delete(redisStorage.watchedKeys, storageKey)
+	}
+}
+
+// HasWatchedKeyChanged indique si une clé a été modifiée depuis la version observée par WATCH
+func (redisStorage *RedisInMemoryStorage) HasWatchedKeyChanged(storageKey string, watchedVersion uint64) bool {
+	redisStorage.storageMutex.RLock()
+	defer redisStorage.storageMutex.RUnlock()
+
+	keyState, isWatched := redisStorage.watchedKeys[storageKey]
+	return !isWatched || keyState.modificationVersion != watchedVersion
+}
+
+// markKeyModified signale la modification d'une clé aux transactions qui la surveillent
+// (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) markKeyModified(storageKey string) {
+	if keyState, isWatched := redisStorage.watchedKeys[storageKey]; isWatched {
+		keyState.modificationVersion++
+	}
+}
+
+// markAllWatchedKeysModified signale une modification globale (FLUSHALL, chargement d'un snapshot)
+// (appelant doit détenir le verrou en écriture)
+func (redisStorage *RedisInMemoryStorage) markAllWatchedKeysModified() {
+	for _, keyState := range redisStorage.watchedKeys {
+		keyState.modificationVersion++
+	}
+}
@@ -16,7 +16,10 @@ func main() {
 	serverConfiguration := config.LoadServerConfiguration()
 
 	// Création du serveur Redis
-	redisServerInstance := server.NewRedisServerInstance(serverConfiguration)
+	redisServerInstance, initializationError := server.NewRedisServerInstance(serverConfiguration)
+	if initializationError != nil {
+		log.Fatalf("❌ Impossible d'initialiser le serveur: %v", initializationError)
+	}
 
 	// Canal pour intercepter les signaux système (CTRL+C, SIGTERM)
 	systemInterruptSignal := make(chan os.Signal, 1)